@@ -3,8 +3,15 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
@@ -13,29 +20,519 @@ import (
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
-	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
 )
 
+const (
+	MODEL_NAME = "gemini-2.0-flash"
+
+	// Set MODEL_RECORDING to "record" or "replay" to record model calls to, or replay them
+	// from, MODEL_RECORDING_FILE
+	MODEL_RECORDING_ENV      = "MODEL_RECORDING"
+	MODEL_RECORDING_FILE_ENV = "MODEL_RECORDING_FILE"
+	DEFAULT_RECORDING_FILE   = "testdata/greeting_recording.json"
+
+	// GREETING_STYLE picks the style new sessions start with; set_style changes it per session
+	GREETING_STYLE_ENV     = "GREETING_STYLE"
+	GREETING_STYLE_KEY     = "greeting_style"
+	DEFAULT_GREETING_STYLE = "casual"
+
+	USER_NAME_KEY = "user_name" // set by extract_name
+
+	// -benchmark runs each prompt in a new session of this app
+	BENCHMARK_APP_NAME     = "greeting_benchmark"
+	BENCHMARK_USER_ID      = "benchmark_user"
+	DEFAULT_BENCHMARK_RUNS = 3
+)
+
+// greetingStyles holds the instruction template of each greeting style
+var greetingStyles = map[string]string{
+	"casual": `Be relaxed and friendly, like greeting a friend: "Hey there! What's your name?"`,
+	"formal": `Be polite and professional, with no slang or exclamations: "Good day. May I have your name, please?"`,
+	"pirate": `Talk like a pirate, with plenty of "ahoy", "matey" and "arr": "Ahoy, matey! What be yer name?"`,
+}
+
+// greetingStyleNames returns the known styles, sorted
+func greetingStyleNames() []string {
+	names := make([]string, 0, len(greetingStyles))
+	for name := range greetingStyles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// loadGreetingStyle returns GREETING_STYLE, or DEFAULT_GREETING_STYLE when it is unset.
+// getenv is usually os.Getenv.
+func loadGreetingStyle(getenv func(string) string) (string, error) {
+	style := strings.ToLower(strings.TrimSpace(getenv(GREETING_STYLE_ENV)))
+	if style == "" {
+		return DEFAULT_GREETING_STYLE, nil
+	}
+	if _, ok := greetingStyles[style]; !ok {
+		return "", fmt.Errorf("unknown %s %q (use %s)", GREETING_STYLE_ENV, style, strings.Join(greetingStyleNames(), ", "))
+	}
+	return style, nil
+}
+
+// greetingInstruction lists every style template; {greeting_style} picks the one in use
+func greetingInstruction() string {
+	var templates strings.Builder
+	for _, name := range greetingStyleNames() {
+		fmt.Fprintf(&templates, "- %s: %s\n", name, greetingStyles[name])
+	}
+	return `You are a helpful assistant that greets the user by name.
+
+The user's name, if already known: {user_name?}
+
+Time-of-day greeting for the start of this conversation: {greeting_prefix?}
+If it is set, open your reply with it, worded in your greeting style. If it is empty, don't add one.
+
+When a message might introduce the user (e.g. "Hi, I'm Alex" or "my name is Alex"), call
+extract_name with the message text. If it returns found, greet them by that name and don't ask for it.
+If you still don't know their name, ask for it.
+
+Your greeting style is: {greeting_style}
+Speak in that style, following its description below:
+` + templates.String() + `
+If the user asks you to speak differently (e.g. "talk like a pirate" or "be more formal"), call
+set_style with the style, then continue in the new style. If it returns unknown_style, tell the user
+which styles are available.`
+}
+
+// ===== Greeting Style Tool =====
+
+type setStyleArgs struct {
+	Style string `json:"style"`
+}
+
+type setStyleResults struct {
+	Status   string   `json:"status"` // success, unchanged or unknown_style
+	OldStyle string   `json:"old_style,omitempty"`
+	Style    string   `json:"style,omitempty"`
+	Styles   []string `json:"styles,omitempty"` // the known styles, when the style is unknown
+	Message  string   `json:"message"`
+}
+
+// setStyle changes the session's greeting style
+func setStyle(ctx tool.Context, input setStyleArgs) (setStyleResults, error) {
+	fmt.Printf("--- Tool: set_style called with '%s' ---\n", input.Style)
+
+	style := strings.ToLower(strings.TrimSpace(input.Style))
+	if _, ok := greetingStyles[style]; !ok {
+		return setStyleResults{
+			Status:  "unknown_style",
+			Styles:  greetingStyleNames(),
+			Message: fmt.Sprintf("Unknown style %q. Available styles: %s", input.Style, strings.Join(greetingStyleNames(), ", ")),
+		}, nil
+	}
+
+	oldStyle, _ := ctx.State().Get(GREETING_STYLE_KEY)
+	if oldStyle == style {
+		return setStyleResults{
+			Status:   "unchanged",
+			OldStyle: style,
+			Style:    style,
+			Message:  fmt.Sprintf("The greeting style is already %s", style),
+		}, nil
+	}
+
+	if err := ctx.State().Set(GREETING_STYLE_KEY, style); err != nil {
+		return setStyleResults{}, fmt.Errorf("failed to save %s: %w", GREETING_STYLE_KEY, err)
+	}
+	results := setStyleResults{
+		Status:  "success",
+		Style:   style,
+		Message: fmt.Sprintf("Greeting style changed to %s", style),
+	}
+	results.OldStyle, _ = oldStyle.(string)
+	return results, nil
+}
+
+// ensureGreetingStyle returns a before-agent callback that stores defaultStyle in sessions
+// without a known greeting style, so {greeting_style} always resolves
+func ensureGreetingStyle(defaultStyle string) agent.BeforeAgentCallback {
+	return func(ctx agent.CallbackContext) (*genai.Content, error) {
+		if val, err := ctx.State().Get(GREETING_STYLE_KEY); err == nil {
+			if style, ok := val.(string); ok {
+				if _, known := greetingStyles[style]; known {
+					return nil, nil
+				}
+			}
+		}
+		if err := ctx.State().Set(GREETING_STYLE_KEY, defaultStyle); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", GREETING_STYLE_KEY, err)
+		}
+		return nil, nil
+	}
+}
+
+// ===== Name Extraction Tool =====
+//
+// extract_name looks for an introduction ("I'm Alex", "my name is Alex Smith", "call me Al")
+// followed by up to three capitalized words. It is deliberately conservative: the name must be
+// capitalized, and common words that follow "I'm" ("I'm Fine", "I'm Not sure", "I'm English")
+// are not taken as names.
+
+// introRe matches an introduction phrase, in any case, and the capitalized words after it
+var introRe = regexp.MustCompile(`(?:^|[^\p{L}])(?i:my name is|my name's|name's|i am|i'm|i’m|call me)\s+(\p{Lu}[\p{L}'’-]*(?:\s+\p{Lu}[\p{L}'’-]*){0,2})`)
+
+// notNames are capitalized words that follow an introduction phrase without being a name
+var notNames = map[string]bool{
+	"a": true, "an": true, "the": true, "not": true, "so": true, "very": true, "really": true,
+	"just": true, "also": true, "still": true, "here": true, "back": true, "fine": true, "good": true,
+	"great": true, "ok": true, "okay": true, "well": true, "sorry": true, "sure": true, "happy": true,
+	"glad": true, "ready": true, "tired": true, "busy": true, "new": true, "looking": true,
+	"trying": true, "interested": true, "going": true, "doing": true, "feeling": true, "from": true,
+	"in": true, "at": true, "on": true, "with": true, "and": true, "but": true, "your": true,
+	"this": true, "that": true, "it": true, "english": true, "american": true, "british": true,
+	"indonesian": true, "indian": true, "chinese": true, "japanese": true, "french": true,
+	"german": true, "spanish": true, "italian": true, "canadian": true, "australian": true,
+}
+
+type extractNameArgs struct {
+	Text string `json:"text"`
+}
+
+type extractNameResults struct {
+	Status  string `json:"status"` // found or not_found
+	Name    string `json:"name"`   // empty when not found
+	Message string `json:"message"`
+}
+
+// extractName detects the user's name in text and stores it under user_name
+func extractName(ctx tool.Context, input extractNameArgs) (extractNameResults, error) {
+	fmt.Printf("--- Tool: extract_name called with '%s' ---\n", input.Text)
+
+	name := detectName(input.Text)
+	if name == "" {
+		return extractNameResults{
+			Status:  "not_found",
+			Message: "No name found in the text",
+		}, nil
+	}
+
+	if err := ctx.State().Set(USER_NAME_KEY, name); err != nil {
+		return extractNameResults{}, fmt.Errorf("failed to save %s: %w", USER_NAME_KEY, err)
+	}
+	return extractNameResults{
+		Status:  "found",
+		Name:    name,
+		Message: fmt.Sprintf("The user's name is %s", name),
+	}, nil
+}
+
+// detectName returns the name introduced in text, or "" if there is none. Words after the
+// first one that isn't a name (e.g. "I'm Alex And ...") are dropped; if that is the first
+// word, nothing is found.
+func detectName(text string) string {
+	for _, match := range introRe.FindAllStringSubmatch(text, -1) {
+		var words []string
+		for _, word := range strings.Fields(match[1]) {
+			word = strings.TrimRight(word, "'’-")
+			if len([]rune(word)) < 2 || notNames[strings.ToLower(word)] {
+				break
+			}
+			words = append(words, word)
+		}
+		if len(words) > 0 {
+			return strings.Join(words, " ")
+		}
+	}
+	return ""
+}
+
+// newGreetingAgent creates the greeting agent on the given model. Sessions start with
+// defaultStyle, one of greetingStyles; now picks the time-of-day greeting of their first turn.
+func newGreetingAgent(mdl model.LLM, defaultStyle string, now func() time.Time) (agent.Agent, error) {
+	setStyleTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "set_style",
+			Description: "Change the greeting style for the rest of the conversation: " + strings.Join(greetingStyleNames(), ", "),
+		},
+		setStyle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create set_style tool: %w", err)
+	}
+
+	extractNameTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "extract_name",
+			Description: "Detect the user's name in a message that introduces them (e.g. \"Hi, I'm Alex\") and remember it. Returns the name, or not_found",
+		},
+		extractName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extract_name tool: %w", err)
+	}
+
+	return llmagent.New(llmagent.Config{
+		Name:                 "greeting_agent",
+		Model:                mdl,
+		Description:          "Greeting agent",
+		Instruction:          greetingInstruction(),
+		Tools:                []tool.Tool{setStyleTool, extractNameTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{ensureGreetingStyle(defaultStyle), agentutil.FirstTurnGreeting(now)},
+	})
+}
+
+// ===== Model Benchmark =====
+//
+// -benchmark sends benchmarkPrompts through the greeting agent -benchmark-runs times per model
+// and prints the latency and token usage of each prompt. Every sample runs in a fresh session,
+// so earlier samples don't grow the conversation history being sent. The prompts are plain
+// greetings that need no tool call, so the numbers are the model's own. A failed sample is
+// counted as an error and the benchmark goes on.
+
+// benchmarkPrompts are representative greeting agent messages
+var benchmarkPrompts = []string{
+	"Hi there!",
+	"Good morning! How is your day going?",
+	"Hello, I just wanted to say thanks for the help yesterday.",
+}
+
+// benchmarkSample is the outcome of one prompt run
+type benchmarkSample struct {
+	Latency          time.Duration
+	PromptTokens     int64
+	CandidatesTokens int64
+	Err              error
+}
+
+// benchmarkStats summarizes the samples of one prompt, or of all prompts
+type benchmarkStats struct {
+	Prompt  string
+	Samples []benchmarkSample
+}
+
+// runBenchmark runs every prompt runs times through a, each in a new session
+func runBenchmark(ctx context.Context, a agent.Agent, prompts []string, runs int) ([]benchmarkStats, error) {
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        BENCHMARK_APP_NAME,
+		Agent:          a,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	stats := make([]benchmarkStats, 0, len(prompts))
+	for _, prompt := range prompts {
+		s := benchmarkStats{Prompt: prompt}
+		for range runs {
+			s.Samples = append(s.Samples, runSample(ctx, r, sessionService, prompt))
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// runSample sends prompt in a new session and measures the whole run
+func runSample(ctx context.Context, r *runner.Runner, sessionService session.Service, prompt string) benchmarkSample {
+	resp, err := sessionService.Create(ctx, &session.CreateRequest{AppName: BENCHMARK_APP_NAME, UserID: BENCHMARK_USER_ID})
+	if err != nil {
+		return benchmarkSample{Err: fmt.Errorf("failed to create session: %w", err)}
+	}
+
+	var sample benchmarkSample
+	start := time.Now()
+	for event, err := range r.Run(ctx, BENCHMARK_USER_ID, resp.Session.ID(), genai.NewContentFromText(prompt, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			sample.Err = err
+			break
+		}
+		if usage := event.UsageMetadata; usage != nil && !event.Partial {
+			sample.PromptTokens += int64(usage.PromptTokenCount)
+			sample.CandidatesTokens += int64(usage.CandidatesTokenCount)
+		}
+	}
+	sample.Latency = time.Since(start)
+	return sample
+}
+
+// succeeded returns the samples without an error
+func (s benchmarkStats) succeeded() []benchmarkSample {
+	var ok []benchmarkSample
+	for _, sample := range s.Samples {
+		if sample.Err == nil {
+			ok = append(ok, sample)
+		}
+	}
+	return ok
+}
+
+// latencies returns the successful samples' latencies, shortest first
+func (s benchmarkStats) latencies() []time.Duration {
+	var latencies []time.Duration
+	for _, sample := range s.succeeded() {
+		latencies = append(latencies, sample.Latency)
+	}
+	slices.Sort(latencies)
+	return latencies
+}
+
+// mergeBenchmarkStats combines the samples of all prompts
+func mergeBenchmarkStats(stats []benchmarkStats) benchmarkStats {
+	all := benchmarkStats{Prompt: "All prompts"}
+	for _, s := range stats {
+		all.Samples = append(all.Samples, s.Samples...)
+	}
+	return all
+}
+
+// printBenchmark writes one row per prompt and a row for all prompts, then the errors. Latency
+// and token columns (average input and output tokens per sample) only count successful samples.
+func printBenchmark(w io.Writer, modelName string, runs int, stats []benchmarkStats) {
+	fmt.Fprintf(w, "\n===== Benchmark: %s, %d run(s) per prompt =====\n", modelName, runs)
+	fmt.Fprintf(w, "%-40s  %5s  %6s  %8s  %8s  %8s  %8s  %8s  %8s\n",
+		"Prompt", "OK", "Errors", "Min", "Avg", "P50", "Max", "Avg in", "Avg out")
+	fmt.Fprintln(w, strings.Repeat("-", 115))
+	for _, s := range stats {
+		printBenchmarkRow(w, s)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 115))
+	all := mergeBenchmarkStats(stats)
+	printBenchmarkRow(w, all)
+
+	for _, s := range stats {
+		for _, sample := range s.Samples {
+			if sample.Err != nil {
+				fmt.Fprintf(w, "error in %q: %v\n", s.Prompt, sample.Err)
+			}
+		}
+	}
+}
+
+func printBenchmarkRow(w io.Writer, s benchmarkStats) {
+	ok := s.succeeded()
+	failed := len(s.Samples) - len(ok)
+	prompt := s.Prompt
+	if len([]rune(prompt)) > 40 {
+		prompt = string([]rune(prompt)[:37]) + "..."
+	}
+
+	latencies := s.latencies()
+	if len(latencies) == 0 {
+		fmt.Fprintf(w, "%-40s  %5d  %6d  %8s  %8s  %8s  %8s  %8s  %8s\n", prompt, 0, failed, "-", "-", "-", "-", "-", "-")
+		return
+	}
+
+	var total time.Duration
+	var promptTokens, candidatesTokens int64
+	for _, sample := range ok {
+		total += sample.Latency
+		promptTokens += sample.PromptTokens
+		candidatesTokens += sample.CandidatesTokens
+	}
+	n := int64(len(ok))
+	fmt.Fprintf(w, "%-40s  %5d  %6d  %8s  %8s  %8s  %8s  %8d  %8d\n",
+		prompt, len(ok), failed,
+		formatLatency(latencies[0]),
+		formatLatency(total/time.Duration(n)),
+		formatLatency(latencies[(len(latencies)-1)/2]),
+		formatLatency(latencies[len(latencies)-1]),
+		promptTokens/n, candidatesTokens/n)
+}
+
+// formatLatency shows a latency in milliseconds, e.g. "812ms"
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// newModel creates the Gemini model with the configured safety settings, wrapped in a recording model when MODEL_RECORDING is set
+func newModel(ctx context.Context, modelName string) (model.LLM, error) {
+	mode := modelutil.RecordMode(os.Getenv(MODEL_RECORDING_ENV))
+	path := os.Getenv(MODEL_RECORDING_FILE_ENV)
+	if path == "" {
+		path = DEFAULT_RECORDING_FILE
+	}
+
+	// Replaying needs no API key or network
+	if mode == modelutil.RECORD_MODE_REPLAY {
+		return modelutil.NewRecordingModel(nil, mode, path)
+	}
+
+	// Create the Gemini model with API key and SAFETY_LEVEL from environment
+	mdl, err := modelutil.NewGeminiModel(ctx, modelName, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		return mdl, nil
+	}
+	fmt.Printf("📼 Model calls: %s (%s)\n", mode, path)
+	return modelutil.NewRecordingModel(mdl, mode, path)
+}
+
+// benchmarkModels runs the benchmark on each model in turn and prints a table per model
+func benchmarkModels(ctx context.Context, modelNames []string, style string, runs int) error {
+	for _, modelName := range modelNames {
+		mdl, err := newModel(ctx, modelName)
+		if err != nil {
+			return fmt.Errorf("failed to create model %s: %w", modelName, err)
+		}
+		a, err := newGreetingAgent(mdl, style, time.Now)
+		if err != nil {
+			return fmt.Errorf("failed to create agent: %w", err)
+		}
+
+		fmt.Printf("⏱️  Benchmarking %s: %d prompt(s) x %d run(s)...\n", modelName, len(benchmarkPrompts), runs)
+		stats, err := runBenchmark(ctx, a, benchmarkPrompts, runs)
+		if err != nil {
+			return err
+		}
+		printBenchmark(os.Stdout, modelName, runs, stats)
+	}
+	return nil
+}
+
 func main() {
+	benchmark := flag.Bool("benchmark", false, "measure latency and token usage of a fixed set of prompts and exit")
+	benchmarkRuns := flag.Int("benchmark-runs", DEFAULT_BENCHMARK_RUNS, "number of times -benchmark sends each prompt")
+	benchmarkModelList := flag.String("benchmark-models", MODEL_NAME, "comma-separated models compared by -benchmark, e.g. gemini-2.0-flash,gemini-2.5-pro")
+	flag.Parse()
+
 	godotenv.Load()
 	ctx := context.Background()
 
-	// Create the Gemini model with API key from environment
-	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
+	style, err := loadGreetingStyle(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to load greeting style: %v", err)
+	}
+
+	if *benchmark {
+		if *benchmarkRuns < 1 {
+			log.Fatalf("-benchmark-runs must be at least 1, got %d", *benchmarkRuns)
+		}
+		var modelNames []string
+		for _, name := range strings.Split(*benchmarkModelList, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				modelNames = append(modelNames, name)
+			}
+		}
+		if len(modelNames) == 0 {
+			log.Fatalf("-benchmark-models is empty")
+		}
+		if err := benchmarkModels(ctx, modelNames, style, *benchmarkRuns); err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		return
+	}
+
+	model, err := newModel(ctx, MODEL_NAME)
 	if err != nil {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
 	// Create the greeting agent
-	a, err := llmagent.New(llmagent.Config{
-		Name:        "greeting_agent",
-		Model:       model,
-		Description: "Greeting agent",
-		Instruction: `You are a helpful assistant that greets the user.
-Ask for the user's name and greet them by name.`,
-	})
+	a, err := newGreetingAgent(model, style, time.Now)
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
@@ -46,7 +543,7 @@ Ask for the user's name and greet them by name.`,
 	}
 
 	l := full.NewLauncher()
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err = l.Execute(ctx, config, flag.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
+)
+
+// Re-record testdata/greeting_recording.json against Gemini with:
+//
+//	GOOGLE_API_KEY=... go test ./1-basic-agent/greeting_agent -record
+
+const (
+	TEST_APP_NAME   = "greeting_app"
+	TEST_USER_ID    = "test_user"
+	TEST_SESSION_ID = "test_session"
+)
+
+// testMorning fixes the clock at 9:00, so the recorded first turn is greeted with "Good morning"
+func testMorning() time.Time {
+	return time.Date(2024, 12, 3, 9, 0, 0, 0, time.Local)
+}
+
+// newTestRunner creates a runner for the greeting agent with a fresh in-memory session
+func newTestRunner(t *testing.T, mdl model.LLM) *runner.Runner {
+	t.Helper()
+	ctx := context.Background()
+
+	greetingAgent, err := newGreetingAgent(mdl, DEFAULT_GREETING_STYLE, testMorning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   TEST_APP_NAME,
+		UserID:    TEST_USER_ID,
+		SessionID: TEST_SESSION_ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := runner.New(runner.Config{
+		AppName:        TEST_APP_NAME,
+		Agent:          greetingAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// ask sends one user message and returns the agent's final text
+func ask(r *runner.Runner, message string) (string, error) {
+	var finalResponse string
+	for event, err := range r.Run(context.Background(), TEST_USER_ID, TEST_SESSION_ID, genai.NewContentFromText(message, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return "", err
+		}
+		if event.Content != nil && len(event.Content.Parts) > 0 && event.Content.Parts[0].Text != "" {
+			finalResponse = event.Content.Parts[0].Text
+		}
+	}
+	return finalResponse, nil
+}
+
+func TestGreetingAgentGreetsByName(t *testing.T) {
+	r := newTestRunner(t, agenttest.ReplayModel(t, DEFAULT_RECORDING_FILE, MODEL_NAME))
+
+	replies := agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{
+		"Hi there!",
+		"My name is Muchlis.",
+	})
+
+	if !strings.Contains(strings.ToLower(replies[0]), "name") {
+		t.Errorf("first reply = %q, want it to ask for the user's name", replies[0])
+	}
+	if !strings.Contains(replies[1], "Muchlis") {
+		t.Errorf("second reply = %q, want it to greet Muchlis by name", replies[1])
+	}
+}
+
+func TestGreetingAgentUnrecordedRequest(t *testing.T) {
+	if agenttest.Recording() {
+		t.Skip("only meaningful when replaying")
+	}
+	r := newTestRunner(t, agenttest.ReplayModel(t, DEFAULT_RECORDING_FILE, MODEL_NAME))
+
+	if _, err := ask(r, "A message that was never recorded"); !errors.Is(err, modelutil.ErrNoRecording) {
+		t.Errorf("got error %v, want ErrNoRecording", err)
+	}
+}
+
+func TestLoadGreetingStyle(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{"", DEFAULT_GREETING_STYLE, false},
+		{"formal", "formal", false},
+		{" Pirate ", "pirate", false},
+		{"shakespearean", "", true},
+	}
+	for _, tt := range tests {
+		got, err := loadGreetingStyle(func(string) string { return tt.env })
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("loadGreetingStyle(%q) = %q, %v; want %q, error %v", tt.env, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDetectName(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hi, I'm Alex", "Alex"},
+		{"Hi, I’m Alex!", "Alex"},
+		{"My name is Muchlis.", "Muchlis"},
+		{"hello there, my name is Alex Smith and I like Go", "Alex Smith"},
+		{"I am Mary-Jane O'Brien", "Mary-Jane O'Brien"},
+		{"Just call me Al", "Al"},
+		{"I'm Siti. Nice to meet you", "Siti"},
+		{"Hi there!", ""},
+		{"my name is alex", ""},
+		{"I'm fine, thanks", ""},
+		{"I'm Not sure what to ask", ""},
+		{"I am English and live in London", ""},
+		{"I am A student", ""},
+		{"Sam I am", ""},
+		{"Dream big", ""},
+	}
+	for _, tt := range tests {
+		if got := detectName(tt.text); got != tt.want {
+			t.Errorf("detectName(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// benchmarkModel answers every request with a fixed greeting and token counts, and fails
+// requests whose last message contains "fail"
+type benchmarkModel struct{}
+
+func (benchmarkModel) Name() string { return MODEL_NAME }
+
+func (benchmarkModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		last := req.Contents[len(req.Contents)-1]
+		if strings.Contains(last.Parts[0].Text, "fail") {
+			yield(nil, errors.New("503 model overloaded"))
+			return
+		}
+		yield(&model.LLMResponse{
+			Content: genai.NewContentFromText("Hey there! What's your name?", genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     120,
+				CandidatesTokenCount: 8,
+				TotalTokenCount:      128,
+			},
+			TurnComplete: true,
+		}, nil)
+	}
+}
+
+func TestRunBenchmark(t *testing.T) {
+	a, err := newGreetingAgent(benchmarkModel{}, DEFAULT_GREETING_STYLE, testMorning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := runBenchmark(context.Background(), a, []string{"Hi there!", "please fail"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats) != 2 || len(stats[0].Samples) != 3 || len(stats[1].Samples) != 3 {
+		t.Fatalf("stats = %+v, want 3 samples for each of 2 prompts", stats)
+	}
+	for _, sample := range stats[0].Samples {
+		if sample.Err != nil || sample.PromptTokens != 120 || sample.CandidatesTokens != 8 || sample.Latency <= 0 {
+			t.Errorf("sample = %+v, want a successful sample with the model's token counts", sample)
+		}
+	}
+	// A failing prompt doesn't abort the benchmark; each of its samples records the error
+	if ok := stats[1].succeeded(); len(ok) != 0 {
+		t.Errorf("failing prompt succeeded %d time(s), want 0", len(ok))
+	}
+
+	var out bytes.Buffer
+	printBenchmark(&out, MODEL_NAME, 3, stats)
+	table := out.String()
+	for _, want := range []string{"Benchmark: " + MODEL_NAME, "Hi there!", "All prompts", "503 model overloaded"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table doesn't contain %q:\n%s", want, table)
+		}
+	}
+	if all := mergeBenchmarkStats(stats); len(all.Samples) != 6 || len(all.succeeded()) != 3 {
+		t.Errorf("all prompts = %d samples, %d succeeded, want 6 and 3", len(all.Samples), len(all.succeeded()))
+	}
+}
@@ -0,0 +1,12 @@
+// Package agents implements the sub-agents for the lead qualification sequential pipeline.
+package agents
+
+import (
+	"google.golang.org/adk/agent"
+)
+
+// AgentCallbacks holds the agent callbacks attached to every sub-agent, such as the stage timer
+type AgentCallbacks struct {
+	Before []agent.BeforeAgentCallback
+	After  []agent.AfterAgentCallback
+}
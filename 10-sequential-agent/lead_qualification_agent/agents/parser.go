@@ -0,0 +1,53 @@
+// Package agents implements the sub-agents for the lead qualification sequential pipeline.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/tools"
+)
+
+// NewLeadParser creates an agent that turns the user's free-text lead into structured fields.
+// It runs first so the validator and scorer work on clean fields rather than raw prose.
+func NewLeadParser(ctx context.Context, model model.LLM, callbacks AgentCallbacks) (agent.Agent, error) {
+	// Create parse_lead tool so labeled fields are extracted deterministically
+	parseLeadTool, err := tools.NewParseLeadTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parse_lead tool: %w", err)
+	}
+
+	parser, err := llmagent.New(llmagent.Config{
+		Name:        "LeadParserAgent",
+		Model:       model,
+		Description: "Extracts structured lead fields from unstructured lead text",
+		Instruction: `You are a Lead Parsing AI.
+
+Always call the parse_lead tool exactly once:
+- Pass the user's complete lead message, unchanged, as text
+- If the lead is written as prose rather than "Label: value" lines, also pass any of name, email,
+  phone, company, position, budget and timeline that are stated explicitly
+- Never guess or invent a value; leave out any field the lead does not mention
+
+Output ONLY the fields the tool found and the fields it reported missing.
+
+Example output: 'found: name, email, phone, company, position, budget, timeline; missing: none'
+Example output: 'found: name, email; missing: phone, company, position, budget, timeline'
+
+Store your parsing summary in state with the key "parse_summary".`,
+		OutputKey:            "parse_summary",
+		Tools:                []tool.Tool{parseLeadTool},
+		BeforeAgentCallbacks: callbacks.Before,
+		AfterAgentCallbacks:  callbacks.After,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lead parser agent: %w", err)
+	}
+
+	return parser, nil
+}
@@ -12,7 +12,7 @@ import (
 
 // NewActionRecommender creates an agent that recommends next actions based on lead qualification.
 // This agent uses the validation and scoring results to suggest appropriate follow-up actions.
-func NewActionRecommender(ctx context.Context, model model.LLM) (agent.Agent, error) {
+func NewActionRecommender(ctx context.Context, model model.LLM, callbacks AgentCallbacks) (agent.Agent, error) {
 	recommender, err := llmagent.New(llmagent.Config{
 		Name:        "ActionRecommenderAgent",
 		Model:       model,
@@ -22,22 +22,28 @@ func NewActionRecommender(ctx context.Context, model model.LLM) (agent.Agent, er
 Based on the lead information and scoring:
 
 - For invalid leads: Suggest what additional information is needed
+- If contact_validation shows an invalid email or phone, ask the team to confirm the correct contact details
 - For leads scored 1-3: Suggest nurturing actions (educational content, etc.)
 - For leads scored 4-7: Suggest qualifying actions (discovery call, needs assessment)
 - For leads scored 8-10: Suggest sales actions (demo, proposal, etc.)
 
 Format your response as a complete recommendation to the sales team.
+Explain the score using score_breakdown: mention which criteria raised or lowered it.
 
 You can access previous results from state:
 - validation_status: Lead validation result
+- contact_validation: Per-field email/phone validity from the validator's contact check
 - lead_score: Lead scoring result
+- score_breakdown: Per-criterion sub-scores, weights and contributions behind the lead score
 
 Store your recommendation in state with the key "action_recommendation".`,
-		OutputKey: "action_recommendation",
+		OutputKey:            "action_recommendation",
+		BeforeAgentCallbacks: callbacks.Before,
+		AfterAgentCallbacks:  callbacks.After,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create action recommender agent: %w", err)
 	}
 
 	return recommender, nil
-}
\ No newline at end of file
+}
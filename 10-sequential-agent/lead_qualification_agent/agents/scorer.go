@@ -8,35 +8,57 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/tools"
 )
 
 // NewLeadScorer creates an agent that scores qualified leads on a scale of 1-10.
-// This agent analyzes various criteria to determine lead qualification level.
-func NewLeadScorer(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// The agent rates each criterion and the score_lead tool combines the ratings using the configured weights.
+func NewLeadScorer(ctx context.Context, model model.LLM, weights tools.ScoringWeights, callbacks AgentCallbacks) (agent.Agent, error) {
+	// Create score_lead tool so the final score follows the configured weights instead of the model's judgement
+	scoreLeadTool, err := tools.NewScoreLeadTool(weights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create score_lead tool: %w", err)
+	}
+
 	scorer, err := llmagent.New(llmagent.Config{
 		Name:        "LeadScorerAgent",
 		Model:       model,
 		Description: "Scores qualified leads on a scale of 1-10 based on qualification criteria",
 		Instruction: `You are a Lead Scoring AI.
 
-Analyze the lead information and assign a qualification score from 1-10 based on:
-- Expressed need (urgency/clarity of problem)
-- Decision-making authority
-- Budget indicators
-- Timeline indicators
+Analyze the structured lead fields extracted by the parser:
+{parsed_lead?}
+
+Use the user's original message for the expressed need, which has no structured field.
+Rate each criterion from 1-10:
+- need: Expressed need (urgency/clarity of problem)
+- authority: Decision-making authority (use the parsed position)
+- budget: Budget indicators (use the parsed budget)
+- timeline: Timeline indicators (use the parsed timeline)
+
+A missing field is no evidence for that criterion, so rate it low.
+
+Always call the score_lead tool with these four sub-scores. It combines them into the final
+score using weights configured by the sales team. Use the score it returns; never compute or
+adjust the final score yourself.
 
-Output ONLY a numeric score and ONE sentence justification.
+Output ONLY the numeric score from the tool and ONE sentence justification.
 
 Example output: '8: Decision maker with clear budget and immediate need'
 Example output: '3: Vague interest with no timeline or budget mentioned'
 
 You can access the validation status from previous step using state if needed.
 Store your scoring result in state with the key "lead_score".`,
-		OutputKey: "lead_score",
+		OutputKey:            "lead_score",
+		Tools:                []tool.Tool{scoreLeadTool},
+		BeforeAgentCallbacks: callbacks.Before,
+		AfterAgentCallbacks:  callbacks.After,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create lead scorer agent: %w", err)
 	}
 
 	return scorer, nil
-}
\ No newline at end of file
+}
@@ -8,34 +8,55 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/tools"
 )
 
 // NewLeadValidator creates an agent that validates lead information for completeness.
 // This agent checks if a lead has sufficient information to proceed with qualification.
-func NewLeadValidator(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// The contact check is done by a tool, so a fast, cheap model is enough.
+func NewLeadValidator(ctx context.Context, model model.LLM, callbacks AgentCallbacks) (agent.Agent, error) {
+	// Create validate_contact tool so the contact check doesn't depend on the model's judgement
+	validateContactTool, err := tools.NewValidateContactTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validate_contact tool: %w", err)
+	}
+
 	validator, err := llmagent.New(llmagent.Config{
 		Name:        "LeadValidatorAgent",
 		Model:       model,
 		Description: "Validates lead information for completeness",
 		Instruction: `You are a Lead Validation AI.
 
-Examine the lead information provided by the user and determine if it's complete enough for qualification.
+Examine the structured lead fields extracted by the parser and determine if they're complete enough for qualification:
+{parsed_lead?}
+
+Use the user's original message only for information that has no structured field, such as the lead's interest or need.
 A complete lead should include:
 - Contact information (name, email or phone)
 - Some indication of interest or need
 - Company or context information if applicable
 
+Always call the validate_contact tool with the parsed email and phone (leave out any that are missing).
+Use its result for the contact information check: the lead has valid contact information only if
+has_valid_contact is true. Do not override the tool's verdict on email or phone format.
+
 Output ONLY 'valid' or 'invalid' with a single reason if invalid.
 
 Example valid output: 'valid'
 Example invalid output: 'invalid: missing contact information'
+Example invalid output: 'invalid: email address is not in a valid format'
 
 Store your validation result in state with the key "validation_status".`,
-		OutputKey: "validation_status",
+		OutputKey:            "validation_status",
+		Tools:                []tool.Tool{validateContactTool},
+		BeforeAgentCallbacks: callbacks.Before,
+		AfterAgentCallbacks:  callbacks.After,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create lead validator agent: %w", err)
 	}
 
 	return validator, nil
-}
\ No newline at end of file
+}
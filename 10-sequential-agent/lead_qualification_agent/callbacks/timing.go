@@ -0,0 +1,177 @@
+// Package callbacks implements reusable agent callbacks for the lead qualification pipeline.
+package callbacks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	// AGENT_TIMINGS_KEY is the state key holding accumulated durations per agent name
+	AGENT_TIMINGS_KEY = "agent_timings"
+)
+
+// AgentTimer is a before/after agent callback pair that measures how long each agent takes.
+// Attach BeforeAgent and AfterAgent to every agent you want timed, including workflow agents.
+//
+// Start times are kept in memory as a stack per invocation and agent name, so nested agents
+// (a pipeline and its sub-agents) and an agent re-entered within the same invocation are each
+// matched with their own start. Durations accumulate per agent name in the "agent_timings"
+// state map, and a summary is printed once the outermost timed agent finishes.
+type AgentTimer struct {
+	mu          sync.Mutex
+	starts      map[string][]time.Time         // invocation ID + agent name -> start time stack
+	depth       map[string]int                 // invocation ID -> number of running timed agents
+	invocations map[string][]completedDuration // invocation ID -> durations in completion order
+}
+
+type completedDuration struct {
+	agentName string
+	duration  time.Duration
+}
+
+// NewAgentTimer creates an AgentTimer
+func NewAgentTimer() *AgentTimer {
+	return &AgentTimer{
+		starts:      make(map[string][]time.Time),
+		depth:       make(map[string]int),
+		invocations: make(map[string][]completedDuration),
+	}
+}
+
+// BeforeAgent records the start time of the agent. It never changes the agent's behavior.
+func (t *AgentTimer) BeforeAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	key := startKey(ctx)
+
+	t.mu.Lock()
+	t.starts[key] = append(t.starts[key], time.Now())
+	t.depth[ctx.InvocationID()]++
+	t.mu.Unlock()
+
+	return nil, nil
+}
+
+// AfterAgent computes the agent's duration, adds it to "agent_timings" in state and,
+// when the outermost timed agent of the invocation finishes, prints a timing summary.
+func (t *AgentTimer) AfterAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	key := startKey(ctx)
+	invocationID := ctx.InvocationID()
+
+	t.mu.Lock()
+	stack := t.starts[key]
+	if len(stack) == 0 {
+		// BeforeAgent was not attached or was skipped by an earlier callback
+		t.mu.Unlock()
+		return nil, nil
+	}
+	duration := time.Since(stack[len(stack)-1])
+	if len(stack) == 1 {
+		delete(t.starts, key)
+	} else {
+		t.starts[key] = stack[:len(stack)-1]
+	}
+
+	t.invocations[invocationID] = append(t.invocations[invocationID], completedDuration{ctx.AgentName(), duration})
+	t.depth[invocationID]--
+	var completed []completedDuration
+	if t.depth[invocationID] <= 0 {
+		completed = t.invocations[invocationID]
+		delete(t.invocations, invocationID)
+		delete(t.depth, invocationID)
+	}
+	t.mu.Unlock()
+
+	state := ctx.State()
+	timings := agentTimings(state)
+	timings[ctx.AgentName()] = addDuration(timings[ctx.AgentName()], duration)
+	if err := state.Set(AGENT_TIMINGS_KEY, timings); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", AGENT_TIMINGS_KEY, err)
+	}
+
+	fmt.Printf("--- Callback: %s took %s ---\n", ctx.AgentName(), duration.Round(time.Millisecond))
+
+	if completed != nil {
+		printSummary(completed, timings)
+	}
+
+	return nil, nil
+}
+
+// ===== Utility Functions =====
+
+func startKey(ctx agent.CallbackContext) string {
+	return ctx.InvocationID() + "/" + ctx.AgentName()
+}
+
+// agentTimings returns a copy of the stored timings map, or an empty one if none exists yet
+func agentTimings(state session.ReadonlyState) map[string]any {
+	timings := map[string]any{}
+	if val, err := state.Get(AGENT_TIMINGS_KEY); err == nil {
+		if stored, ok := val.(map[string]any); ok {
+			for name, entry := range stored {
+				if entry, ok := entry.(map[string]any); ok {
+					timings[name] = entry
+				}
+			}
+		}
+	}
+	return timings
+}
+
+// addDuration folds one more run into an agent's accumulated timing entry
+func addDuration(entry any, duration time.Duration) map[string]any {
+	stored, _ := entry.(map[string]any)
+	calls := number(stored["calls"]) + 1
+	totalMs := number(stored["total_ms"]) + float64(duration.Microseconds())/1000
+
+	return map[string]any{
+		"calls":    int(calls),
+		"total_ms": totalMs,
+		"avg_ms":   totalMs / calls,
+		"last_ms":  float64(duration.Microseconds()) / 1000,
+	}
+}
+
+// number reads a numeric state value, which comes back as float64 after a JSON round trip
+func number(val any) float64 {
+	switch n := val.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func printSummary(completed []completedDuration, timings map[string]any) {
+	fmt.Println("\n========== AGENT TIMINGS ==========")
+	fmt.Println("This run:")
+	for _, c := range completed {
+		fmt.Printf("  %-30s %10s\n", c.agentName, c.duration.Round(time.Millisecond))
+	}
+
+	names := make([]string, 0, len(timings))
+	for name := range timings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return number(timings[names[i]].(map[string]any)["total_ms"]) > number(timings[names[j]].(map[string]any)["total_ms"])
+	})
+
+	fmt.Println("Session totals (slowest first):")
+	for _, name := range names {
+		entry := timings[name].(map[string]any)
+		fmt.Printf("  %-30s calls=%-4d total=%8.0fms avg=%8.0fms\n",
+			name, int(number(entry["calls"])), number(entry["total_ms"]), number(entry["avg_ms"]))
+	}
+	fmt.Println(strings.Repeat("=", 35))
+}
@@ -1,13 +1,23 @@
 // Package main implements a lead qualification sequential agent in Go.
 // This example demonstrates how to create a SequentialAgent using Google's ADK framework.
 //
-// The lead qualification pipeline orchestrates three sub-agents in sequence:
-// 1. Lead Validator Agent: Validates lead information completeness
-// 2. Lead Scorer Agent: Scores the lead from 1-10 based on qualification criteria
-// 3. Action Recommender Agent: Recommends next actions based on validation and scoring
+// The lead qualification pipeline orchestrates four sub-agents in sequence:
+// 1. Lead Parser Agent: Extracts structured fields from the unstructured lead text
+// 2. Lead Validator Agent: Validates lead information completeness
+// 3. Lead Scorer Agent: Scores the lead from 1-10 using configurable criterion weights
+// 4. Action Recommender Agent: Recommends next actions based on validation and scoring
 //
 // Each agent stores its output in session state using output keys, allowing the next
 // agent in the sequence to access the results of previous agents.
+//
+// Qualified leads are remembered in app-level state backed by a SQLite session database,
+// so a lead submitted again (matched by normalized email) returns its previous result.
+//
+// The parse, validate and score stages run on a fast, cheap model; the action recommender,
+// which synthesizes the earlier results, runs on a stronger model.
+//
+// Every stage is timed by a shared before/after agent callback pair, which accumulates
+// per-agent durations in the "agent_timings" state map and prints a summary after each run.
 package main
 
 import (
@@ -18,44 +28,107 @@ import (
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/workflowagents/sequentialagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/session/database"
 
 	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/agents"
+	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/callbacks"
+	"github.com/muchlist/agent-dev-kit/10-sequential-agent/lead_qualification_agent/tools"
 )
 
 const (
-	MODEL_NAME = "gemini-2.0-flash"
+	// FAST_MODEL_NAME runs the extraction, validation and scoring stages, which mostly call tools;
+	// STRONG_MODEL_NAME writes the recommendation, where reasoning quality matters most
+	FAST_MODEL_NAME   = "gemini-2.0-flash"
+	STRONG_MODEL_NAME = "gemini-2.5-pro"
+	DB_FILE           = "./lead_qualification_data.db"
+	// WEIGHTS_FILE holds the scoring weights; override the path with LEAD_SCORING_WEIGHTS_FILE
+	WEIGHTS_FILE = "./scoring_weights.json"
 )
 
 func main() {
 	godotenv.Load()
 	ctx := context.Background()
 
-	// Create the Gemini model
-	model, err := gemini.NewModel(ctx, MODEL_NAME, &genai.ClientConfig{
+	// Create a cheap model for the mechanical stages and a stronger one for the recommendation
+	fastModel, err := gemini.NewModel(ctx, FAST_MODEL_NAME, &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model %s: %v", FAST_MODEL_NAME, err)
+	}
+
+	strongModel, err := gemini.NewModel(ctx, STRONG_MODEL_NAME, &genai.ClientConfig{
 		APIKey: os.Getenv("GOOGLE_API_KEY"),
 	})
 	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
+		log.Fatalf("Failed to create model %s: %v", STRONG_MODEL_NAME, err)
+	}
+	fmt.Printf("🧠 Models: %s (parse, validate, score), %s (recommend)\n", FAST_MODEL_NAME, STRONG_MODEL_NAME)
+
+	// Create database session service so qualified leads survive restarts
+	sessionService, err := database.NewSessionService(
+		sqlite.Open(DB_FILE),
+		&gorm.Config{
+			PrepareStmt: true,
+			Logger:      logger.Default.LogMode(logger.Silent),
+		},
+	)
+	if err != nil {
+		log.Fatalf("Failed to create database session service: %v", err)
+	}
+
+	// Initialize database schema
+	if err := database.AutoMigrate(sessionService); err != nil {
+		log.Fatalf("Failed to auto-migrate database: %v", err)
+	}
+
+	fmt.Println("✅ Connected to database:", DB_FILE)
+
+	// Time every stage so slow steps in the pipeline are easy to spot
+	timer := callbacks.NewAgentTimer()
+	stageCallbacks := agents.AgentCallbacks{
+		Before: []agent.BeforeAgentCallback{timer.BeforeAgent},
+		After:  []agent.AfterAgentCallback{timer.AfterAgent},
 	}
 
 	// Create sub-agents for the sequential workflow
-	validator, err := agents.NewLeadValidator(ctx, model)
+	parser, err := agents.NewLeadParser(ctx, fastModel, stageCallbacks)
+	if err != nil {
+		log.Fatalf("Failed to create lead parser agent: %v", err)
+	}
+
+	validator, err := agents.NewLeadValidator(ctx, fastModel, stageCallbacks)
 	if err != nil {
 		log.Fatalf("Failed to create lead validator agent: %v", err)
 	}
 
-	scorer, err := agents.NewLeadScorer(ctx, model)
+	// Load scoring weights so sales teams can tune emphasis without editing prompts
+	weightsFile := os.Getenv("LEAD_SCORING_WEIGHTS_FILE")
+	if weightsFile == "" {
+		weightsFile = WEIGHTS_FILE
+	}
+	weights, err := tools.LoadScoringWeights(weightsFile)
+	if err != nil {
+		log.Fatalf("Failed to load scoring weights: %v", err)
+	}
+	fmt.Printf("⚖️  Scoring weights: need=%.2f authority=%.2f budget=%.2f timeline=%.2f\n",
+		weights.Need, weights.Authority, weights.Budget, weights.Timeline)
+
+	scorer, err := agents.NewLeadScorer(ctx, fastModel, weights, stageCallbacks)
 	if err != nil {
 		log.Fatalf("Failed to create lead scorer agent: %v", err)
 	}
 
-	recommender, err := agents.NewActionRecommender(ctx, model)
+	recommender, err := agents.NewActionRecommender(ctx, strongModel, stageCallbacks)
 	if err != nil {
 		log.Fatalf("Failed to create action recommender agent: %v", err)
 	}
@@ -65,8 +138,12 @@ func main() {
 	sequentialAgent, err := sequentialagent.New(sequentialagent.Config{
 		AgentConfig: agent.Config{
 			Name:        "LeadQualificationPipeline",
-			Description: "A sequential pipeline that validates, scores, and recommends actions for sales leads",
-			SubAgents:   []agent.Agent{validator, scorer, recommender},
+			Description: "A sequential pipeline that parses, validates, scores, and recommends actions for sales leads",
+			SubAgents:   []agent.Agent{parser, validator, scorer, recommender},
+			// Short-circuit leads that were already qualified, and remember new ones once scored.
+			// The timer starts after the duplicate check so short-circuited runs are not timed.
+			BeforeAgentCallbacks: []agent.BeforeAgentCallback{tools.CheckDuplicateLead, timer.BeforeAgent},
+			AfterAgentCallbacks:  []agent.AfterAgentCallback{timer.AfterAgent, tools.RecordQualifiedLead},
 		},
 	})
 	if err != nil {
@@ -88,7 +165,8 @@ func main() {
 
 	// Configure and launch the agent
 	config := &launcher.Config{
-		AgentLoader: agent.NewSingleLoader(sequentialAgent),
+		AgentLoader:    agent.NewSingleLoader(sequentialAgent),
+		SessionService: sessionService,
 	}
 
 	l := full.NewLauncher()
@@ -0,0 +1,207 @@
+// Package tools implements deterministic helper tools for the lead qualification pipeline.
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	// PARSED_LEAD_KEY is the state key holding the structured lead fields extracted by parse_lead
+	PARSED_LEAD_KEY = "parsed_lead"
+)
+
+// leadFields lists the structured fields in the order they are reported
+var leadFields = []string{"name", "email", "phone", "company", "position", "budget", "timeline"}
+
+// leadFieldLabels maps lowercase labels found in "Label: value" lines to the field they fill
+var leadFieldLabels = map[string]string{
+	"name":          "name",
+	"full name":     "name",
+	"contact":       "name",
+	"contact name":  "name",
+	"email":         "email",
+	"e-mail":        "email",
+	"email address": "email",
+	"phone":         "phone",
+	"phone number":  "phone",
+	"mobile":        "phone",
+	"tel":           "phone",
+	"telephone":     "phone",
+	"company":       "company",
+	"company name":  "company",
+	"organization":  "company",
+	"organisation":  "company",
+	"position":      "position",
+	"title":         "position",
+	"job title":     "position",
+	"role":          "position",
+	"budget":        "budget",
+	"timeline":      "timeline",
+	"timeframe":     "timeline",
+	"time frame":    "timeline",
+}
+
+var (
+	labeledLineRe = regexp.MustCompile(`^\s*[-*•]?\s*([A-Za-z][A-Za-z \-]*?)\s*:\s*(.+?)\s*$`)
+	phoneInTextRe = regexp.MustCompile(`\+?[0-9][0-9 ()./\-]{5,}[0-9]`)
+)
+
+// ===== Lead Parsing Structures =====
+
+// parseLeadArgs carries the raw lead text plus any fields the model read from prose.
+// Model-supplied fields are only used when the text has no labeled value for that field.
+type parseLeadArgs struct {
+	Text     string `json:"text"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Company  string `json:"company,omitempty"`
+	Position string `json:"position,omitempty"`
+	Budget   string `json:"budget,omitempty"`
+	Timeline string `json:"timeline,omitempty"`
+}
+
+// ParsedLead is the structured form of a lead; fields not found are left empty
+type ParsedLead struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Company  string `json:"company"`
+	Position string `json:"position"`
+	Budget   string `json:"budget"`
+	Timeline string `json:"timeline"`
+}
+
+type parseLeadResults struct {
+	Lead          ParsedLead        `json:"lead"`
+	FieldsFound   []string          `json:"fields_found"`
+	FieldsMissing []string          `json:"fields_missing"`
+	Sources       map[string]string `json:"sources"`
+}
+
+// ===== Tool Implementation =====
+
+// parseLead extracts structured lead fields and stores them in state. Values come from, in order of
+// preference: "Label: value" lines in the text, fields the model read from prose, and finally
+// email/phone patterns found anywhere in the text.
+func parseLead(ctx tool.Context, input parseLeadArgs) (parseLeadResults, error) {
+	fmt.Printf("--- Tool: parse_lead called with %d characters of text ---\n", len(input.Text))
+
+	values := parseLabeledLines(input.Text)
+	sources := make(map[string]string, len(leadFields))
+	for field := range values {
+		sources[field] = "label"
+	}
+
+	hints := map[string]string{
+		"name":     input.Name,
+		"email":    input.Email,
+		"phone":    input.Phone,
+		"company":  input.Company,
+		"position": input.Position,
+		"budget":   input.Budget,
+		"timeline": input.Timeline,
+	}
+	for _, field := range leadFields {
+		if _, ok := values[field]; ok {
+			continue
+		}
+		if hint := strings.TrimSpace(hints[field]); hint != "" {
+			values[field] = hint
+			sources[field] = "model"
+		}
+	}
+
+	if _, ok := values["email"]; !ok {
+		if email := emailInTextRe.FindString(input.Text); email != "" {
+			values["email"] = email
+			sources["email"] = "pattern"
+		}
+	}
+	if _, ok := values["phone"]; !ok {
+		for _, candidate := range phoneInTextRe.FindAllString(input.Text, -1) {
+			if validatePhone(candidate).Valid {
+				values["phone"] = strings.TrimSpace(candidate)
+				sources["phone"] = "pattern"
+				break
+			}
+		}
+	}
+
+	if email, ok := values["email"]; ok {
+		values["email"] = NormalizeEmail(email)
+	}
+
+	results := parseLeadResults{
+		Lead: ParsedLead{
+			Name:     values["name"],
+			Email:    values["email"],
+			Phone:    values["phone"],
+			Company:  values["company"],
+			Position: values["position"],
+			Budget:   values["budget"],
+			Timeline: values["timeline"],
+		},
+		FieldsFound:   []string{},
+		FieldsMissing: []string{},
+		Sources:       sources,
+	}
+
+	stateLead := make(map[string]any, len(leadFields)+2)
+	for _, field := range leadFields {
+		stateLead[field] = values[field]
+		if values[field] != "" {
+			results.FieldsFound = append(results.FieldsFound, field)
+		} else {
+			results.FieldsMissing = append(results.FieldsMissing, field)
+		}
+	}
+	stateLead["fields_found"] = results.FieldsFound
+	stateLead["fields_missing"] = results.FieldsMissing
+
+	ctx.State().Set(PARSED_LEAD_KEY, stateLead)
+
+	return results, nil
+}
+
+// parseLabeledLines reads "Label: value" lines, keeping the first value seen for each known field
+func parseLabeledLines(text string) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		match := labeledLineRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		field, ok := leadFieldLabels[strings.ToLower(match[1])]
+		if !ok {
+			continue
+		}
+		if _, seen := values[field]; !seen {
+			values[field] = match[2]
+		}
+	}
+	return values
+}
+
+// ===== Tool Creation =====
+
+// NewParseLeadTool creates a tool that turns free-text lead information into structured fields
+func NewParseLeadTool() (tool.Tool, error) {
+	parseLeadTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "parse_lead",
+			Description: "Extracts name, email, phone, company, position, budget and timeline from raw lead text and reports which fields were found",
+		},
+		parseLead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parse_lead tool: %w", err)
+	}
+
+	return parseLeadTool, nil
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+// stateToolContext is a tool.Context with session state; only State works
+type stateToolContext struct {
+	tool.Context
+	state *agenttest.State
+}
+
+func (c stateToolContext) State() session.State { return c.state }
+
+func newStateToolContext() stateToolContext {
+	return stateToolContext{state: agenttest.NewState(nil, false)}
+}
+
+func TestParseLead(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       parseLeadArgs
+		want        ParsedLead
+		wantMissing []string
+		wantSources map[string]string
+	}{
+		{
+			name: "labeled lines",
+			input: parseLeadArgs{Text: "Name: Jane Doe\nEmail: <Jane.Doe@Acme.com>\n- Phone: +1 (555) 123-4567\n" +
+				"Company: Acme Corp\nJob Title: VP of Sales\nBudget: $50,000\nTimeframe: Q3"},
+			want: ParsedLead{
+				Name: "Jane Doe", Email: "jane.doe@acme.com", Phone: "+1 (555) 123-4567", Company: "Acme Corp",
+				Position: "VP of Sales", Budget: "$50,000", Timeline: "Q3",
+			},
+			wantMissing: []string{},
+			wantSources: map[string]string{
+				"name": "label", "email": "label", "phone": "label", "company": "label",
+				"position": "label", "budget": "label", "timeline": "label",
+			},
+		},
+		{
+			name: "labels win over model fields",
+			input: parseLeadArgs{
+				Text: "Company: Acme Corp\nCompany: Other Inc",
+				Name: " Jane Doe ", Company: "Wrong Co",
+			},
+			want:        ParsedLead{Name: "Jane Doe", Company: "Acme Corp"},
+			wantMissing: []string{"email", "phone", "position", "budget", "timeline"},
+			wantSources: map[string]string{"name": "model", "company": "label"},
+		},
+		{
+			name:        "contact details in prose",
+			input:       parseLeadArgs{Text: "Reach me at JOHN@example.org (ticket 1234-56). Call 020 7946 0958 after 5pm."},
+			want:        ParsedLead{Email: "john@example.org", Phone: "020 7946 0958"},
+			wantMissing: []string{"name", "company", "position", "budget", "timeline"},
+			wantSources: map[string]string{"email": "pattern", "phone": "pattern"},
+		},
+		{
+			name:        "missing fields",
+			input:       parseLeadArgs{Text: "Name: Sam\nNotes: interested in a demo"},
+			want:        ParsedLead{Name: "Sam"},
+			wantMissing: []string{"email", "phone", "company", "position", "budget", "timeline"},
+			wantSources: map[string]string{"name": "label"},
+		},
+		{
+			name:        "garbage",
+			input:       parseLeadArgs{Text: ":::\n@@@ ### \x00 ----\nname:\n: value"},
+			want:        ParsedLead{},
+			wantMissing: leadFields,
+			wantSources: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newStateToolContext()
+			results, err := parseLead(ctx, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results.Lead != tt.want {
+				t.Errorf("lead = %+v, want %+v", results.Lead, tt.want)
+			}
+			if !reflect.DeepEqual(results.FieldsMissing, tt.wantMissing) {
+				t.Errorf("fields missing = %v, want %v", results.FieldsMissing, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(results.Sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", results.Sources, tt.wantSources)
+			}
+
+			stored, _ := ctx.state.Value(PARSED_LEAD_KEY).(map[string]any)
+			if stored["name"] != tt.want.Name || stored["email"] != tt.want.Email {
+				t.Errorf("%s = %v, want the parsed lead", PARSED_LEAD_KEY, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package tools implements deterministic helper tools for the lead qualification pipeline.
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	// SCORE_BREAKDOWN_KEY is the state key holding the weighted sub-scores behind the lead score
+	SCORE_BREAKDOWN_KEY = "score_breakdown"
+
+	minSubScore = 1
+	maxSubScore = 10
+)
+
+// ===== Scoring Weights Configuration =====
+
+// ScoringWeights sets how much each qualification criterion counts towards the final 1-10 score.
+// Weights are relative: they are normalized by their sum, so {2, 1, 1, 1} and {0.4, 0.2, 0.2, 0.2}
+// score identically.
+type ScoringWeights struct {
+	Need      float64 `json:"need"`
+	Authority float64 `json:"authority"`
+	Budget    float64 `json:"budget"`
+	Timeline  float64 `json:"timeline"`
+}
+
+// DefaultScoringWeights weighs all four criteria equally
+func DefaultScoringWeights() ScoringWeights {
+	return ScoringWeights{Need: 1, Authority: 1, Budget: 1, Timeline: 1}
+}
+
+// LoadScoringWeights reads weights from a JSON file such as scoring_weights.json.
+// A missing file is not an error and yields DefaultScoringWeights; criteria left out
+// of the file keep their default weight.
+func LoadScoringWeights(path string) (ScoringWeights, error) {
+	weights := DefaultScoringWeights()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return weights, nil
+	}
+	if err != nil {
+		return weights, fmt.Errorf("failed to read scoring weights %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return DefaultScoringWeights(), fmt.Errorf("failed to parse scoring weights %s: %w", path, err)
+	}
+	if err := weights.validate(); err != nil {
+		return DefaultScoringWeights(), fmt.Errorf("invalid scoring weights %s: %w", path, err)
+	}
+
+	return weights, nil
+}
+
+func (w ScoringWeights) validate() error {
+	if w.Need < 0 || w.Authority < 0 || w.Budget < 0 || w.Timeline < 0 {
+		return fmt.Errorf("weights must not be negative")
+	}
+	if w.total() == 0 {
+		return fmt.Errorf("at least one weight must be greater than zero")
+	}
+	return nil
+}
+
+func (w ScoringWeights) total() float64 {
+	return w.Need + w.Authority + w.Budget + w.Timeline
+}
+
+// ===== Lead Scoring Structures =====
+
+type scoreLeadArgs struct {
+	Need      int `json:"need"`
+	Authority int `json:"authority"`
+	Budget    int `json:"budget"`
+	Timeline  int `json:"timeline"`
+}
+
+// CriterionScore is one criterion's sub-score and its share of the final score
+type CriterionScore struct {
+	SubScore     int     `json:"sub_score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+type scoreLeadResults struct {
+	Status    string                    `json:"status"`
+	Score     int                       `json:"score,omitempty"`
+	Breakdown map[string]CriterionScore `json:"breakdown,omitempty"`
+	Message   string                    `json:"message,omitempty"`
+}
+
+// ===== Tool Implementation =====
+
+// newScoreLead binds the configured weights to the score_lead handler
+func newScoreLead(weights ScoringWeights) func(tool.Context, scoreLeadArgs) (scoreLeadResults, error) {
+	return func(ctx tool.Context, input scoreLeadArgs) (scoreLeadResults, error) {
+		fmt.Printf("--- Tool: score_lead called with need=%d authority=%d budget=%d timeline=%d ---\n",
+			input.Need, input.Authority, input.Budget, input.Timeline)
+
+		criteria := []struct {
+			name     string
+			subScore int
+			weight   float64
+		}{
+			{"need", input.Need, weights.Need},
+			{"authority", input.Authority, weights.Authority},
+			{"budget", input.Budget, weights.Budget},
+			{"timeline", input.Timeline, weights.Timeline},
+		}
+
+		for _, c := range criteria {
+			if c.subScore < minSubScore || c.subScore > maxSubScore {
+				return scoreLeadResults{
+					Status:  "error",
+					Message: fmt.Sprintf("%s sub-score must be between %d and %d, got %d", c.name, minSubScore, maxSubScore, c.subScore),
+				}, nil
+			}
+		}
+
+		total := weights.total()
+		breakdown := make(map[string]CriterionScore, len(criteria))
+		stateBreakdown := make(map[string]any, len(criteria)+1)
+		var weighted float64
+		for _, c := range criteria {
+			share := c.weight / total
+			contribution := math.Round(float64(c.subScore)*share*100) / 100
+			weighted += float64(c.subScore) * share
+
+			breakdown[c.name] = CriterionScore{SubScore: c.subScore, Weight: share, Contribution: contribution}
+			stateBreakdown[c.name] = map[string]any{
+				"sub_score":    c.subScore,
+				"weight":       share,
+				"contribution": contribution,
+			}
+		}
+
+		score := int(math.Round(weighted))
+		score = max(minSubScore, min(maxSubScore, score))
+		stateBreakdown["score"] = score
+
+		ctx.State().Set(SCORE_BREAKDOWN_KEY, stateBreakdown)
+
+		return scoreLeadResults{
+			Status:    "success",
+			Score:     score,
+			Breakdown: breakdown,
+		}, nil
+	}
+}
+
+// ===== Tool Creation =====
+
+// NewScoreLeadTool creates a tool that combines per-criterion sub-scores into the final 1-10 lead score
+// using the given weights, so sales teams can change emphasis without editing prompts
+func NewScoreLeadTool(weights ScoringWeights) (tool.Tool, error) {
+	if err := weights.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scoring weights: %w", err)
+	}
+
+	scoreLeadTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "score_lead",
+			Description: "Combines 1-10 sub-scores for need, authority, budget and timeline into the final weighted 1-10 lead score",
+		},
+		newScoreLead(weights))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create score_lead tool: %w", err)
+	}
+
+	return scoreLeadTool, nil
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestScoreLead(t *testing.T) {
+	equal := DefaultScoringWeights()
+	tests := []struct {
+		name    string
+		weights ScoringWeights
+		input   scoreLeadArgs
+		want    int
+	}{
+		{"lowest", equal, scoreLeadArgs{1, 1, 1, 1}, 1},
+		{"highest", equal, scoreLeadArgs{10, 10, 10, 10}, 10},
+		{"rounds down", equal, scoreLeadArgs{10, 1, 1, 1}, 3},     // 3.25
+		{"rounds half up", equal, scoreLeadArgs{10, 10, 1, 1}, 6}, // 5.5
+		{"need weighted double", ScoringWeights{Need: 2, Authority: 1, Budget: 1, Timeline: 1}, scoreLeadArgs{10, 5, 5, 5}, 7},
+		{"same weights scaled", ScoringWeights{Need: 0.4, Authority: 0.2, Budget: 0.2, Timeline: 0.2}, scoreLeadArgs{10, 5, 5, 5}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := newScoreLead(tt.weights)(newStateToolContext(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results.Status != "success" || results.Score != tt.want {
+				t.Errorf("score_lead(%+v) = %+v, want %d", tt.input, results, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreLeadFactorWeights(t *testing.T) {
+	// With all the weight on one criterion the score is that criterion's sub-score
+	input := scoreLeadArgs{Need: 9, Authority: 2, Budget: 6, Timeline: 4}
+	for name, tt := range map[string]struct {
+		weights ScoringWeights
+		want    int
+	}{
+		"need":      {ScoringWeights{Need: 1}, 9},
+		"authority": {ScoringWeights{Authority: 1}, 2},
+		"budget":    {ScoringWeights{Budget: 1}, 6},
+		"timeline":  {ScoringWeights{Timeline: 1}, 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := newStateToolContext()
+			results, _ := newScoreLead(tt.weights)(ctx, input)
+			if results.Score != tt.want {
+				t.Errorf("score = %d, want %d", results.Score, tt.want)
+			}
+			if got := results.Breakdown[name]; got.Weight != 1 || got.Contribution != float64(tt.want) {
+				t.Errorf("breakdown[%s] = %+v, want the full weight", name, got)
+			}
+			stored, _ := ctx.state.Value(SCORE_BREAKDOWN_KEY).(map[string]any)
+			if stored["score"] != tt.want {
+				t.Errorf("%s = %v, want score %d", SCORE_BREAKDOWN_KEY, stored, tt.want)
+			}
+		})
+	}
+
+	// Contributions add up to the unrounded score
+	results, _ := newScoreLead(ScoringWeights{Need: 2, Authority: 1, Budget: 1, Timeline: 1})(newStateToolContext(), input)
+	var sum float64
+	for _, c := range results.Breakdown {
+		sum += c.Contribution
+	}
+	if math.Abs(sum-6.0) > 0.02 || results.Breakdown["need"].Weight != 0.4 {
+		t.Errorf("breakdown = %+v, want contributions summing to 6 and need weighted 0.4", results.Breakdown)
+	}
+}
+
+func TestScoreLeadRejectsOutOfRangeSubScores(t *testing.T) {
+	for _, input := range []scoreLeadArgs{{0, 5, 5, 5}, {5, 11, 5, 5}, {5, 5, -1, 5}, {5, 5, 5, 100}} {
+		results, _ := newScoreLead(DefaultScoringWeights())(newStateToolContext(), input)
+		if results.Status != "error" || !strings.Contains(results.Message, "between 1 and 10") {
+			t.Errorf("score_lead(%+v) = %+v, want an out-of-range error", input, results)
+		}
+	}
+}
+
+func TestNewScoreLeadToolRejectsInvalidWeights(t *testing.T) {
+	for _, weights := range []ScoringWeights{{}, {Need: -1, Authority: 2}} {
+		if _, err := NewScoreLeadTool(weights); err == nil {
+			t.Errorf("NewScoreLeadTool(%+v) succeeded", weights)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package tools implements deterministic helper tools for the lead qualification pipeline.
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	// CONTACT_VALIDATION_KEY is the state key holding the structured contact validation details
+	CONTACT_VALIDATION_KEY = "contact_validation"
+
+	minPhoneDigits = 7
+	maxPhoneDigits = 15 // E.164 maximum
+)
+
+var (
+	emailRe = regexp.MustCompile(`^[A-Za-z0-9._%+\-]+@[A-Za-z0-9](?:[A-Za-z0-9\-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9\-]*[A-Za-z0-9])?)*\.[A-Za-z]{2,}$`)
+	// Digits with an optional leading +, allowing common separators such as spaces, dashes, dots and parentheses
+	phoneRe = regexp.MustCompile(`^\+?[0-9 ()./\-]+$`)
+)
+
+// ===== Contact Validation Structures =====
+
+type validateContactArgs struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// FieldValidation is the validity of a single contact field
+type FieldValidation struct {
+	Provided bool   `json:"provided"`
+	Valid    bool   `json:"valid"`
+	Value    string `json:"value,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type validateContactResults struct {
+	Email           FieldValidation `json:"email"`
+	Phone           FieldValidation `json:"phone"`
+	HasValidContact bool            `json:"has_valid_contact"`
+	Summary         string          `json:"summary"`
+}
+
+// ===== Tool Implementation =====
+
+// validateContact checks email and phone formats and stores the per-field result in state
+func validateContact(ctx tool.Context, input validateContactArgs) (validateContactResults, error) {
+	fmt.Printf("--- Tool: validate_contact called for email=%q phone=%q ---\n", input.Email, input.Phone)
+
+	email := validateEmail(input.Email)
+	phone := validatePhone(input.Phone)
+
+	results := validateContactResults{
+		Email:           email,
+		Phone:           phone,
+		HasValidContact: email.Valid || phone.Valid,
+	}
+
+	switch {
+	case !email.Provided && !phone.Provided:
+		results.Summary = "invalid: no email or phone provided"
+	case !results.HasValidContact:
+		results.Summary = "invalid: no valid email or phone"
+	default:
+		results.Summary = "valid"
+	}
+
+	ctx.State().Set(CONTACT_VALIDATION_KEY, map[string]any{
+		"email_provided":    email.Provided,
+		"email_valid":       email.Valid,
+		"email_reason":      email.Reason,
+		"phone_provided":    phone.Provided,
+		"phone_valid":       phone.Valid,
+		"phone_reason":      phone.Reason,
+		"has_valid_contact": results.HasValidContact,
+		"summary":           results.Summary,
+	})
+
+	return results, nil
+}
+
+// validateEmail checks an email address against a practical (not full RFC 5322) pattern
+func validateEmail(raw string) FieldValidation {
+	email := strings.TrimSpace(raw)
+	if email == "" {
+		return FieldValidation{Reason: "not provided"}
+	}
+
+	result := FieldValidation{Provided: true, Value: email}
+	if len(email) > 254 || !emailRe.MatchString(email) {
+		result.Reason = "not a valid email address format"
+		return result
+	}
+	result.Valid = true
+	return result
+}
+
+// validatePhone loosely accepts international formats: an optional leading +,
+// digits and common separators, with 7 to 15 digits in total
+func validatePhone(raw string) FieldValidation {
+	phone := strings.TrimSpace(raw)
+	if phone == "" {
+		return FieldValidation{Reason: "not provided"}
+	}
+
+	result := FieldValidation{Provided: true, Value: phone}
+	if !phoneRe.MatchString(phone) || strings.LastIndex(phone, "+") > 0 {
+		result.Reason = "phone may only contain digits, spaces, dashes, dots, parentheses and a leading +"
+		return result
+	}
+
+	digits := 0
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if digits < minPhoneDigits || digits > maxPhoneDigits {
+		result.Reason = fmt.Sprintf("phone must have between %d and %d digits, got %d", minPhoneDigits, maxPhoneDigits, digits)
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// ===== Tool Creation =====
+
+// NewValidateContactTool creates a tool that deterministically validates a lead's email and phone
+func NewValidateContactTool() (tool.Tool, error) {
+	validateContactTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "validate_contact",
+			Description: "Validates the format of a lead's email address and phone number and returns per-field validity",
+		},
+		validateContact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validate_contact tool: %w", err)
+	}
+
+	return validateContactTool, nil
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"jane@example.com", true},
+		{" Jane.Doe+sales@mail.example.co.uk ", true},
+		{"j_d%1@sub-domain.example.io", true},
+		{"plainaddress", false},
+		{"@example.com", false},
+		{"jane@", false},
+		{"jane@example", false},
+		{"jane@example.c", false},
+		{"jane doe@example.com", false},
+		{"jane@-example.com", false},
+		{"jane@example-.com", false},
+		{"jane@@example.com", false},
+		{strings.Repeat("a", 250) + "@example.com", false}, // longer than 254 characters
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got := validateEmail(tt.email)
+			if got.Valid != tt.want || !got.Provided {
+				t.Errorf("validateEmail(%q) = %+v, want valid %v", tt.email, got, tt.want)
+			}
+		})
+	}
+
+	if got := validateEmail("  "); got.Provided || got.Valid {
+		t.Errorf("validateEmail(blank) = %+v, want not provided", got)
+	}
+}
+
+func TestValidatePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  bool
+	}{
+		{"555-0100", true},
+		{"+1 (555) 123-4567", true},
+		{"020.7946.0958", true},
+		{"+442079460958", true},
+		{"1234567", true},           // 7 digits
+		{"123456789012345", true},   // 15 digits
+		{"123456", false},           // 6 digits
+		{"1234567890123456", false}, // 16 digits
+		{"555-CALL-NOW", false},
+		{"555 0100 ext 2", false},
+		{"1+555 0100", false},
+		{"++1 555 0100", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got := validatePhone(tt.phone)
+			if got.Valid != tt.want || !got.Provided {
+				t.Errorf("validatePhone(%q) = %+v, want valid %v", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateContact(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       validateContactArgs
+		wantValid   bool
+		wantSummary string
+	}{
+		{"both valid", validateContactArgs{Email: "jane@example.com", Phone: "555-0100"}, true, "valid"},
+		{"valid phone only", validateContactArgs{Email: "jane@", Phone: "555-0100"}, true, "valid"},
+		{"neither valid", validateContactArgs{Email: "jane@", Phone: "123"}, false, "invalid: no valid email or phone"},
+		{"nothing provided", validateContactArgs{}, false, "invalid: no email or phone provided"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newStateToolContext()
+			results, err := validateContact(ctx, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results.HasValidContact != tt.wantValid || results.Summary != tt.wantSummary {
+				t.Errorf("validateContact(%+v) = %+v, want %v and %q", tt.input, results, tt.wantValid, tt.wantSummary)
+			}
+			stored, _ := ctx.state.Value(CONTACT_VALIDATION_KEY).(map[string]any)
+			if stored["has_valid_contact"] != tt.wantValid || stored["summary"] != tt.wantSummary {
+				t.Errorf("%s = %v, want the results", CONTACT_VALIDATION_KEY, stored)
+			}
+		})
+	}
+}
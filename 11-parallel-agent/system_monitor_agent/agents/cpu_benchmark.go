@@ -0,0 +1,52 @@
+// Package agents implements the sub-agents for the system monitor parallel workflow.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// NewCPUBenchmarkAgent creates an agent that runs the CPU benchmark when the user asks for one.
+// main.go runs it after the parallel gatherers rather than among them: the benchmark loads
+// every core, which would inflate the CPU usage the CPU info agent samples at the same time.
+func NewCPUBenchmarkAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+	// Create the CPU benchmark tool (only runs when explicitly requested)
+	cpuBenchmarkTool, err := tools.NewCPUBenchmark()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU benchmark tool: %w", err)
+	}
+
+	cpuBenchmarkAgent, err := llmagent.New(llmagent.Config{
+		Name:        "CPUBenchmarkAgent",
+		Model:       model,
+		Description: "Runs an opt-in CPU load test after the system information has been gathered",
+		Instruction: `You are a CPU Benchmark Specialist.
+
+Your task is to:
+1. Check whether the user explicitly asked for a CPU benchmark, load test or stress test
+2. ONLY if they did, call run_cpu_benchmark once with run=true (and duration_seconds if they
+   gave one) and report the ops/second score, the per-core score and the duration
+3. Otherwise do not call any tool and reply with exactly: "No CPU benchmark was requested."
+
+IMPORTANT:
+- Never run the benchmark for a normal health check; it loads every core for a few seconds
+- If the tool fails, report the error instead of guessing numbers
+
+Store your benchmark report in state with the key "cpu_benchmark_report".`,
+		OutputKey: "cpu_benchmark_report",
+		Tools: []tool.Tool{
+			cpuBenchmarkTool,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU benchmark agent: %w", err)
+	}
+
+	return cpuBenchmarkAgent, nil
+}
@@ -14,10 +14,11 @@ import (
 
 // NewCPUInfoAgent creates an agent that collects and analyzes real CPU information.
 // This agent runs in parallel with other system information gatherers and uses
-// gopsutil to gather actual CPU metrics from the system.
-func NewCPUInfoAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// gopsutil to gather actual CPU metrics from the system. Usage above thresholds.CPU is
+// reported as a performance concern unless the session set its own limit.
+func NewCPUInfoAgent(ctx context.Context, model model.LLM, thresholds tools.Thresholds) (agent.Agent, error) {
 	// Create the CPU info tool
-	cpuInfoTool, err := tools.NewGetCPUInfo()
+	cpuInfoTool, err := tools.NewGetCPUInfo(thresholds)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CPU info tool: %w", err)
 	}
@@ -37,7 +38,7 @@ Your task is to:
    - Current usage statistics per core
    - Average CPU usage
    - Performance indicators and trends
-   - Any potential issues (high usage, bottlenecks, etc.)
+   - Any potential issues (usage above the alert_threshold in the stats, bottlenecks, etc.)
    - Recommendations for optimization if needed
 
 IMPORTANT:
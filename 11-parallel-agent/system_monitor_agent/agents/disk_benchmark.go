@@ -0,0 +1,55 @@
+// Package agents implements the sub-agents for the system monitor parallel workflow.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// NewDiskBenchmarkAgent creates an agent that measures disk write and read speed.
+// This agent is optional: it actively writes to disk, so main.go only adds it to
+// the parallel gatherers when ENABLE_DISK_BENCHMARK is set.
+func NewDiskBenchmarkAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+	// Create the disk benchmark tool
+	diskBenchmarkTool, err := tools.NewDiskBenchmark()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create disk benchmark tool: %w", err)
+	}
+
+	diskBenchmarkAgent, err := llmagent.New(llmagent.Config{
+		Name:        "DiskBenchmarkAgent",
+		Model:       model,
+		Description: "Measures sequential disk write and read throughput with a small temporary file",
+		Instruction: `You are a Disk Performance Specialist.
+
+Your task is to:
+1. Use the run_disk_benchmark tool once to measure REAL disk throughput (use the default size unless the user asks for a specific one)
+2. Report:
+   - Sequential write speed (MB/s)
+   - Sequential read speed (MB/s)
+   - The file size and directory used for the test
+   - Whether the speeds suggest a slow disk (e.g. under 50 MB/s write) or a fast SSD
+3. Mention that read speed may be inflated by the OS cache
+
+IMPORTANT:
+- Call the tool only once; it writes to disk
+- If the tool fails (for example because of low free space), report the error instead of guessing numbers
+
+Store your disk performance analysis in state with the key "disk_benchmark_report".`,
+		OutputKey: "disk_benchmark_report",
+		Tools: []tool.Tool{
+			diskBenchmarkTool,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create disk benchmark agent: %w", err)
+	}
+
+	return diskBenchmarkAgent, nil
+}
@@ -4,23 +4,31 @@ package agents
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 )
 
+// DISK_INFO_TIMEOUT bounds the disk scan; an unresponsive mount (e.g. a stale network share)
+// can otherwise hang the whole parallel phase
+const DISK_INFO_TIMEOUT = 10 * time.Second
+
 // NewDiskInfoAgent creates an agent that gathers real disk space information.
 // This agent runs in parallel with other system information gatherers and uses
-// gopsutil to gather actual disk metrics from the system.
-func NewDiskInfoAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// gopsutil to gather actual disk metrics from the system. Partitions fuller than
+// thresholds.Disk are flagged; only the disk value of thresholds is used here.
+func NewDiskInfoAgent(ctx context.Context, model model.LLM, thresholds tools.Thresholds) (agent.Agent, error) {
 	// Create the disk info tool
-	diskInfoTool, err := tools.NewGetDiskInfo()
+	diskInfoTool, err := tools.NewGetDiskInfo(thresholds)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create disk info tool: %w", err)
 	}
+	diskInfoTool = toolutil.WithTimeout(DISK_INFO_TIMEOUT, diskInfoTool)
 
 	diskInfoAgent, err := llmagent.New(llmagent.Config{
 		Name:        "DiskInfoAgent",
@@ -44,8 +52,9 @@ IMPORTANT:
 - Always call the get_disk_info tool first to get real system data
 - Base your analysis on the ACTUAL data returned by the tool
 - Do not simulate or make up data - use only the real metrics provided
-- Pay special attention to high disk usage (>80%)
+- Pay special attention to disk usage above the alert_threshold in the stats
 - Provide actionable recommendations if disk space is low
+- If get_disk_info returns a timeout error, report that the disk scan timed out instead of guessing
 
 Store your disk analysis in state with the key "disk_info_report".`,
 		OutputKey: "disk_info_report",
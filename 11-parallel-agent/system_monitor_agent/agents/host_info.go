@@ -0,0 +1,55 @@
+// Package agents implements the sub-agents for the system monitor parallel workflow.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// NewHostInfoAgent creates an agent that reports host uptime, boot time and OS details.
+// This agent runs in parallel with the other system information gatherers.
+func NewHostInfoAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+	// Create the host info tool
+	hostInfoTool, err := tools.NewGetHostInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create host info tool: %w", err)
+	}
+
+	hostInfoAgent, err := llmagent.New(llmagent.Config{
+		Name:        "HostInfoAgent",
+		Model:       model,
+		Description: "Reports host uptime, boot time, operating system and kernel version using system tools",
+		Instruction: `You are a Host Information Specialist with access to real system metrics.
+
+Your task is to:
+1. Use the get_host_info tool to gather REAL host data from the system
+2. Provide a short report including:
+   - Hostname
+   - Uptime (as returned, e.g. "3 days, 4 hours, 12 minutes") and boot time
+   - Operating system, platform and platform version
+   - Kernel version and architecture
+   - A note if the machine has been up for a very long time (e.g. over 30 days),
+     since pending OS updates or a reboot may be due
+
+IMPORTANT:
+- Always call the get_host_info tool first to get real system data
+- Fields reported as "unknown" are not available on this platform; say so instead of guessing
+
+Store your host analysis in state with the key "host_info_report".`,
+		OutputKey: "host_info_report",
+		Tools: []tool.Tool{
+			hostInfoTool,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create host info agent: %w", err)
+	}
+
+	return hostInfoAgent, nil
+}
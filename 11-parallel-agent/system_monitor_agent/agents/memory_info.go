@@ -14,10 +14,11 @@ import (
 
 // NewMemoryInfoAgent creates an agent that gathers real memory usage information.
 // This agent runs in parallel with other system information gatherers and uses
-// gopsutil to gather actual memory metrics from the system.
-func NewMemoryInfoAgent(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// gopsutil to gather actual memory metrics from the system. thresholds.Memory applies to
+// both RAM and swap usage; a value saved with set_thresholds takes its place.
+func NewMemoryInfoAgent(ctx context.Context, model model.LLM, thresholds tools.Thresholds) (agent.Agent, error) {
 	// Create the memory info tool
-	memoryInfoTool, err := tools.NewGetMemoryInfo()
+	memoryInfoTool, err := tools.NewGetMemoryInfo(thresholds)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create memory info tool: %w", err)
 	}
@@ -44,7 +45,7 @@ IMPORTANT:
 - Always call the get_memory_info tool first to get real system data
 - Base your analysis on the ACTUAL data returned by the tool
 - Do not simulate or make up data - use only the real metrics provided
-- Pay special attention to high memory usage (>80%) or swap usage
+- Pay special attention to memory or swap usage above the alert_threshold in the stats
 
 Store your memory analysis in state with the key "memory_info_report".`,
 		OutputKey: "memory_info_report",
@@ -0,0 +1,83 @@
+// Package agents implements the sub-agents for the system monitor parallel workflow.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// NewRemediationAdvisor creates an agent that suggests commands to fix the flagged concerns.
+// It runs after the report synthesizer. Its suggestions are only text and are never executed.
+// With enableRemediation (ENABLE_REMEDIATION=true) it also gets run_remediation, which runs a
+// whitelisted cleanup after the user confirms. thresholds are the default alert thresholds.
+func NewRemediationAdvisor(ctx context.Context, model model.LLM, thresholds tools.Thresholds, enableRemediation bool) (agent.Agent, error) {
+	// Create the suggest remediation tool, which reads the same concerns as concerns_summary
+	suggestRemediationTool, err := tools.NewSuggestRemediation(thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create suggest remediation tool: %w", err)
+	}
+	advisorTools := []tool.Tool{suggestRemediationTool}
+
+	instruction := REMEDIATION_ADVISOR_INSTRUCTION
+	if enableRemediation {
+		runRemediationTool, err := tools.NewRunRemediation()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create run remediation tool: %w", err)
+		}
+		advisorTools = append(advisorTools, runRemediationTool)
+		instruction += "\n\n" + RUN_REMEDIATION_INSTRUCTION
+	}
+
+	remediationAdvisor, err := llmagent.New(llmagent.Config{
+		Name:        "RemediationAdvisor",
+		Model:       model,
+		Description: "Suggests OS-specific commands to address the concerns in the system health report, without running them",
+		Instruction: instruction,
+		OutputKey:   tools.REMEDIATION_ADVICE_KEY,
+		Tools:       advisorTools,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remediation advisor agent: %w", err)
+	}
+
+	return remediationAdvisor, nil
+}
+
+// REMEDIATION_ADVISOR_INSTRUCTION is the advisor's instruction without run_remediation
+const REMEDIATION_ADVISOR_INSTRUCTION = `You are a Remediation Advisor. You run after the system health report is written.
+
+Your task is to:
+1. Call the suggest_remediation tool. It returns the flagged concerns, most severe first, each
+   with steps and commands for the operating system the monitor runs on ("os").
+2. If its status is "healthy", reply with one line saying no remediation is needed.
+3. Otherwise, start with the tool's "notice" on its own line, prefixed with "⚠️", then for each
+   concern list its severity, the concern and its steps. Show each command in a code block, exactly
+   as returned; explain placeholders like <PID> (e.g. take the PID from the previous command).
+
+IMPORTANT:
+- These are suggestions only. You cannot run these commands and must never say or imply that a
+  command was run or that a problem was fixed
+- Do not add commands of your own, especially destructive ones (deleting files, killing
+  system processes, formatting disks)
+- Mention that commands with sudo or that stop processes need care
+
+Store your advice in state with the key "remediation_advice".`
+
+// RUN_REMEDIATION_INSTRUCTION is added to the advisor's instruction when run_remediation is enabled
+const RUN_REMEDIATION_INSTRUCTION = `RUNNING A REMEDIATION (run_remediation):
+You can run exactly one action yourself: clean_temp_dir, which deletes files older than 24 hours
+from the temp directory. Never offer or attempt anything else; the tool refuses other actions.
+- Only use it when the user explicitly asks you to clean up temp files (or to run a fix and you
+  offered this one). Never call it just because disk usage is high.
+- First call it with only action="clean_temp_dir". This is a dry run: show the user the directory,
+  the number of files, the size and the sample paths, and ask them to confirm.
+- Only in a LATER turn, after the user clearly confirms, call it again with dry_run=false and the
+  confirmation_token from the dry run. Never pass a token in the same turn you asked for it.
+- Report exactly what the result says was deleted and freed, and any files that failed. If the
+  status is invalid_token, expired, changed or not_confirmed, nothing ran: offer a new dry run.`
@@ -5,37 +5,104 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/util/instructionutil"
 )
 
 // NewSystemReportSynthesizer creates an agent that combines all gathered information into a comprehensive report.
-// This agent runs after the parallel information gathering is complete.
-func NewSystemReportSynthesizer(ctx context.Context, model model.LLM) (agent.Agent, error) {
+// This agent runs after the parallel information gathering is complete. It does the actual
+// reasoning of the workflow, so main passes it a stronger model than the gatherers.
+// thresholds are the default alert thresholds concerns_summary falls back to, and reportFormat
+// the report format used until set_report_format changes it, and baselinePath the file holding
+// this machine's normal usage ranges.
+func NewSystemReportSynthesizer(ctx context.Context, model model.LLM, thresholds tools.Thresholds, reportFormat, baselinePath string) (agent.Agent, error) {
+	// Create the concerns summary tool, which reads the metrics the info tools saved to state
+	concernsSummaryTool, err := tools.NewConcernsSummary(thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concerns summary tool: %w", err)
+	}
+
+	// Create the render report tool, which lays out the table and JSON formats deterministically
+	renderReportTool, err := tools.NewRenderReport(thresholds, reportFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render report tool: %w", err)
+	}
+
+	// Create the compare_to_baseline and capture_baseline tools, which read and write the baseline file
+	baselineTools, err := tools.NewBaselineTools(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create baseline tools: %w", err)
+	}
+
 	reportSynthesizer, err := llmagent.New(llmagent.Config{
 		Name:        "SystemReportSynthesizer",
 		Model:       model,
 		Description: "Combines parallel system information into a comprehensive health report",
-		Instruction: `You are a System Report Synthesizer.
+		// The format can change per session, so the instruction is built for each run; ADK doesn't
+		// inject state into an InstructionProvider's output, so the template is rendered here
+		InstructionProvider: func(ctx agent.ReadonlyContext) (string, error) {
+			format := tools.CurrentReportFormat(ctx.ReadonlyState(), reportFormat)
+			return instructionutil.InjectSessionState(ctx, SYNTHESIZER_INSTRUCTION+"\n\n"+reportFormatInstruction(format))
+		},
+		OutputKey: "system_health_report",
+		Tools: append([]tool.Tool{
+			concernsSummaryTool,
+			renderReportTool,
+		}, baselineTools...),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create system report synthesizer agent: %w", err)
+	}
+
+	return reportSynthesizer, nil
+}
+
+// SYNTHESIZER_INSTRUCTION is the synthesizer's instruction template; reportFormatInstruction
+// adds how to lay out the report
+const SYNTHESIZER_INSTRUCTION = `You are a System Report Synthesizer.
 
 Combine the system information gathered by the parallel agents into a comprehensive system health report. You have access to:
 
+Host Information: {state.host_info_report}
 CPU Information: {state.cpu_info_report}
 Memory Information: {state.memory_info_report}
 Disk Information: {state.disk_info_report}
+Disk Performance (optional, only present when the disk benchmark ran): {state.disk_benchmark_report}
+CPU Benchmark (ran after the other metrics were gathered, so it doesn't affect the CPU usage): {state.cpu_benchmark_report}
+
+Before writing, call the concerns_summary tool. It returns every CPU, memory, swap and disk
+concern flagged by the info tools in one list, most severe first (critical, high, warning).
+Also call the compare_to_baseline tool. It compares the usage with this machine's normal ranges
+and lists every metric "above" or "below" its range with the deviation in percentage points. If
+its status is "no_baseline", there is no baseline yet and nothing deviates.
+
+If the user asked to save the current usage as the baseline (the new normal), call the
+capture_baseline tool (with margin if they gave one) and confirm the saved ranges in the report.
+Never capture a baseline unless the user asked for it.
 
 Create a well-structured report that includes:
 
 EXECUTIVE SUMMARY:
+- Lead with the concerns from concerns_summary in the order returned, with their severity and
+  usage percentage; if its status is "healthy", say all monitored metrics are healthy
 - Overall system health status
+- Host, operating system and uptime
 - Key metrics and their implications
 - Critical issues requiring immediate attention
+- Deviations from baseline: cite each metric outside its normal range from compare_to_baseline,
+  e.g. "CPU 92% is 12 points above its usual 40-80%"; a metric can be unusual for this machine
+  without crossing an alert threshold, and vice versa
 
 DETAILED ANALYSIS:
 - CPU performance and utilization
 - Memory usage and pressure indicators
 - Disk space and storage health
+- Disk read/write throughput (only if a disk benchmark report is available)
+- CPU benchmark score (only if a CPU benchmark was run)
 - Performance bottlenecks or concerns
 
 RECOMMENDATIONS:
@@ -46,12 +113,21 @@ RECOMMENDATIONS:
 
 Format the report professionally with clear sections and actionable insights. Make it easy to understand for both technical and non-technical users.
 
-Store your comprehensive report in state with the key "system_health_report".`,
-		OutputKey: "system_health_report",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create system report synthesizer agent: %w", err)
-	}
+Store your comprehensive report in state with the key "system_health_report".`
 
-	return reportSynthesizer, nil
-}
\ No newline at end of file
+// reportFormatInstruction tells the synthesizer how to write the report in format
+func reportFormatInstruction(format string) string {
+	switch format {
+	case tools.FORMAT_MARKDOWN_TABLE:
+		return `REPORT FORMAT: markdown-table
+The user asked for a compact table instead of the sections above. Call render_report and start
+your reply with its "rendered" table exactly as returned. Below it, add at most three one-line
+recommendations for the concerns in the table. Do not write the prose sections.`
+	case tools.FORMAT_JSON:
+		return `REPORT FORMAT: json
+The report is read by a dashboard or script. Call render_report and reply with its "rendered"
+JSON exactly as returned: no code fences, no text before or after it, no changed values.`
+	}
+	return `REPORT FORMAT: prose
+Write the report as prose in the sections above.`
+}
@@ -0,0 +1,65 @@
+// Package agents implements the sub-agents for the system monitor parallel workflow.
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// NewThresholdAgent creates an agent that applies alert threshold changes the user asks for,
+// such as "warn me only above 90% CPU", and report format changes such as "give me JSON". It
+// runs before the parallel gatherers, so their info tools and the synthesizer already see the
+// new settings in session state. thresholds and reportFormat are the defaults.
+func NewThresholdAgent(ctx context.Context, model model.LLM, thresholds tools.Thresholds, reportFormat string) (agent.Agent, error) {
+	// Create the set thresholds tool
+	setThresholdsTool, err := tools.NewSetThresholds(thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create set thresholds tool: %w", err)
+	}
+
+	// Create the set report format tool
+	setReportFormatTool, err := tools.NewSetReportFormat(reportFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create set report format tool: %w", err)
+	}
+
+	thresholdAgent, err := llmagent.New(llmagent.Config{
+		Name:        "ThresholdAgent",
+		Model:       model,
+		Description: "Applies the user's requested alert thresholds and report format before the system check",
+		Instruction: `You are an Alert Threshold Manager. You run before the system check.
+
+Your task is to:
+1. If the user asks to change when concerns are flagged (e.g. "warn me only above 90% CPU",
+   "alert me when disk is over 70%"), call set_thresholds with only the values they mentioned,
+   as percentages between 0 and 100. Memory thresholds also apply to swap.
+2. If the user asks what the thresholds are, call set_thresholds with no values.
+3. If the user asks for the report in a particular format, call set_report_format with "prose",
+   "markdown-table" (a compact table) or "json" (machine-readable, e.g. for a dashboard).
+4. Otherwise, do not call any tool.
+
+Then reply with one short line:
+- After a change, the thresholds or report format now applied, from the tool's result
+- If the tool returned invalid_threshold, that thresholds must be between 0 and 100 and nothing changed
+- If the tool returned invalid_format, the supported formats and that the format didn't change
+- If no tool was called, "Using the current alert thresholds."
+
+Do not analyze the system yourself; the agents after you do that.`,
+		OutputKey: "threshold_update",
+		Tools: []tool.Tool{
+			setThresholdsTool,
+			setReportFormatTool,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create threshold agent: %w", err)
+	}
+
+	return thresholdAgent, nil
+}
@@ -2,8 +2,15 @@
 // This example demonstrates how to create a hybrid workflow using both Parallel and Sequential agents.
 //
 // The system monitoring workflow:
-// 1. Parallel Information Gathering: Concurrently collect CPU, Memory, and Disk information
-// 2. Sequential Report Synthesis: Combine all information into a comprehensive report
+// 1. Threshold Settings: Apply any alert thresholds or report format the user asks for
+//    ("warn me only above 90% CPU", "give me the report as JSON")
+// 2. Parallel Information Gathering: Concurrently collect CPU, Memory, Disk, and Host information
+// 3. CPU Benchmark: Load-test the CPU if the user asked for it, after the usage was sampled
+// 4. Sequential Report Synthesis: Combine all information into a comprehensive report
+// 5. Remediation Advice: Suggest OS-specific commands for the concerns, never running them
+//
+// The gatherers run on a fast, cheap model and the synthesizer on a stronger one.
+// With -watch <interval> the workflow re-runs on a timer and prints each report (see watch.go).
 //
 // This hybrid approach shows how to combine workflow agent types for optimal performance
 // and logical flow - parallel for independent tasks, sequential for dependent processing.
@@ -11,9 +18,11 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
@@ -26,52 +35,125 @@ import (
 	"google.golang.org/adk/model/gemini"
 
 	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/agents"
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
 )
 
 const (
-	MODEL_NAME = "gemini-2.0-flash"
+	// FAST_MODEL_NAME runs the information gatherers, which only call a tool and summarize it;
+	// STRONG_MODEL_NAME writes the final report, where reasoning quality matters most
+	FAST_MODEL_NAME   = "gemini-2.0-flash"
+	STRONG_MODEL_NAME = "gemini-2.5-pro"
 )
 
 func main() {
+	watch := flag.Duration("watch", 0, "re-run the health check every interval (e.g. 30s) and print trends instead of launching")
+	flag.Parse()
+
 	godotenv.Load()
 	ctx := context.Background()
 
-	// Create the Gemini model
-	model, err := gemini.NewModel(ctx, MODEL_NAME, &genai.ClientConfig{
+	// Create a cheap model for the gatherers and a stronger one for the report synthesis
+	fastModel, err := gemini.NewModel(ctx, FAST_MODEL_NAME, &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model %s: %v", FAST_MODEL_NAME, err)
+	}
+
+	strongModel, err := gemini.NewModel(ctx, STRONG_MODEL_NAME, &genai.ClientConfig{
 		APIKey: os.Getenv("GOOGLE_API_KEY"),
 	})
 	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
+		log.Fatalf("Failed to create model %s: %v", STRONG_MODEL_NAME, err)
+	}
+
+	// Alert thresholds default to 80% unless CPU_ALERT_THRESHOLD, MEMORY_ALERT_THRESHOLD or
+	// DISK_ALERT_THRESHOLD is set; the user can change them per session with set_thresholds
+	thresholds, err := tools.LoadThresholds(os.Getenv, tools.DefaultThresholds())
+	if err != nil {
+		log.Fatalf("Failed to load alert thresholds: %v", err)
+	}
+
+	// The report is prose unless REPORT_FORMAT is markdown-table or json; the user can change it
+	// per session with set_report_format
+	reportFormat, err := tools.LoadReportFormat(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to load report format: %v", err)
+	}
+
+	// The baseline holds this machine's normal usage ranges, in baseline.json unless BASELINE_FILE
+	// is set. A missing file means everything is in range; an invalid one stops the monitor here
+	baselinePath := tools.BaselinePath(os.Getenv)
+	if _, found, err := tools.LoadBaseline(baselinePath); err != nil {
+		log.Fatalf("Failed to load baseline: %v", err)
+	} else if !found {
+		log.Printf("No baseline at %s yet; ask the monitor to capture one", baselinePath)
+	}
+
+	thresholdAgent, err := agents.NewThresholdAgent(ctx, fastModel, thresholds, reportFormat)
+	if err != nil {
+		log.Fatalf("Failed to create threshold agent: %v", err)
 	}
 
 	// Create sub-agents for parallel system information gathering
-	cpuInfoAgent, err := agents.NewCPUInfoAgent(ctx, model)
+	cpuInfoAgent, err := agents.NewCPUInfoAgent(ctx, fastModel, thresholds)
 	if err != nil {
 		log.Fatalf("Failed to create CPU info agent: %v", err)
 	}
 
-	memoryInfoAgent, err := agents.NewMemoryInfoAgent(ctx, model)
+	memoryInfoAgent, err := agents.NewMemoryInfoAgent(ctx, fastModel, thresholds)
 	if err != nil {
 		log.Fatalf("Failed to create memory info agent: %v", err)
 	}
 
-	diskInfoAgent, err := agents.NewDiskInfoAgent(ctx, model)
+	diskInfoAgent, err := agents.NewDiskInfoAgent(ctx, fastModel, thresholds)
 	if err != nil {
 		log.Fatalf("Failed to create disk info agent: %v", err)
 	}
 
+	hostInfoAgent, err := agents.NewHostInfoAgent(ctx, fastModel)
+	if err != nil {
+		log.Fatalf("Failed to create host info agent: %v", err)
+	}
+
+	gatherers := []agent.Agent{cpuInfoAgent, memoryInfoAgent, diskInfoAgent, hostInfoAgent}
+
+	// The disk benchmark writes a temp file, so it only runs when explicitly enabled
+	if enabled, _ := strconv.ParseBool(os.Getenv("ENABLE_DISK_BENCHMARK")); enabled {
+		diskBenchmarkAgent, err := agents.NewDiskBenchmarkAgent(ctx, fastModel)
+		if err != nil {
+			log.Fatalf("Failed to create disk benchmark agent: %v", err)
+		}
+		gatherers = append(gatherers, diskBenchmarkAgent)
+	}
+
+	// The CPU benchmark loads every core, so it runs after the parallel gatherers rather than
+	// alongside the CPU usage sampling
+	cpuBenchmarkAgent, err := agents.NewCPUBenchmarkAgent(ctx, fastModel)
+	if err != nil {
+		log.Fatalf("Failed to create CPU benchmark agent: %v", err)
+	}
+
 	// Create report synthesizer agent
-	reportSynthesizer, err := agents.NewSystemReportSynthesizer(ctx, model)
+	reportSynthesizer, err := agents.NewSystemReportSynthesizer(ctx, strongModel, thresholds, reportFormat, baselinePath)
 	if err != nil {
 		log.Fatalf("Failed to create report synthesizer agent: %v", err)
 	}
 
+	// Create the remediation advisor, which suggests (but never runs) commands for the concerns.
+	// The temp directory cleanup it can run after the user confirms is only added when explicitly enabled
+	enableRemediation, _ := strconv.ParseBool(os.Getenv(tools.ENABLE_REMEDIATION_ENV))
+	remediationAdvisor, err := agents.NewRemediationAdvisor(ctx, fastModel, thresholds, enableRemediation)
+	if err != nil {
+		log.Fatalf("Failed to create remediation advisor agent: %v", err)
+	}
+
 	// Create Parallel Agent for concurrent system information gathering
 	parallelInfoGatherer, err := parallelagent.New(parallelagent.Config{
 		AgentConfig: agent.Config{
 			Name:        "system_info_gatherer",
-			Description: "Gathers system information concurrently from CPU, memory, and disk",
-			SubAgents:   []agent.Agent{cpuInfoAgent, memoryInfoAgent, diskInfoAgent},
+			Description: "Gathers system information concurrently from CPU, memory, disk, and host",
+			SubAgents:   gatherers,
 		},
 	})
 	if err != nil {
@@ -83,13 +165,20 @@ func main() {
 		AgentConfig: agent.Config{
 			Name:        "system_monitor_agent",
 			Description: "Monitors system health using parallel data gathering and sequential synthesis",
-			SubAgents:   []agent.Agent{parallelInfoGatherer, reportSynthesizer},
+			SubAgents:   []agent.Agent{thresholdAgent, parallelInfoGatherer, cpuBenchmarkAgent, reportSynthesizer, remediationAdvisor},
 		},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create system monitor sequential agent: %v", err)
 	}
 
+	if *watch > 0 {
+		if err := runWatch(ctx, sequentialAgent, *watch); err != nil {
+			log.Fatalf("Watch failed: %v", err)
+		}
+		return
+	}
+
 	fmt.Println("\n🚀 Launching System Monitor Parallel Agent...")
 	fmt.Println("========================================================")
 	fmt.Println("Example prompts to try:")
@@ -97,6 +186,8 @@ func main() {
 	fmt.Println("• 'Provide a comprehensive system report with recommendations'")
 	fmt.Println("• 'Is my system running out of memory or disk space?'")
 	fmt.Println("• 'Generate a detailed system status report'")
+	fmt.Println("• 'Warn me only above 90% CPU, then check my system'")
+	fmt.Println("• 'Check my system and give me the report as a table'")
 	fmt.Println("========================================================")
 
 	// Configure and launch the agent
@@ -105,7 +196,7 @@ func main() {
 	}
 
 	l := full.NewLauncher()
-	if err := l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err := l.Execute(ctx, config, flag.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
 }
@@ -0,0 +1,308 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// A baseline is the normal usage range of each area on this machine, e.g. a build server that
+// always runs at 70-90% CPU. compare_to_baseline flags usage outside the range, which says more
+// than a flat alert threshold; capture_baseline saves the current usage as the new baseline.
+const (
+	BASELINE_FILE_ENV     = "BASELINE_FILE"
+	DEFAULT_BASELINE_FILE = "baseline.json"
+	// DEFAULT_BASELINE_MARGIN is how many percentage points around the current usage
+	// capture_baseline counts as normal
+	DEFAULT_BASELINE_MARGIN = 10.0
+)
+
+// Baseline is the content of the baseline file. Areas without a range are always in range.
+type Baseline struct {
+	CapturedAt string                   `json:"captured_at,omitempty"`
+	Metrics    map[string]BaselineRange `json:"metrics"`
+}
+
+// BaselineRange is the normal usage percentage range of an area, inclusive
+type BaselineRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// baselineFile reads and writes the baseline file; the mutex keeps a capture from interleaving
+// with a read
+type baselineFile struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// LoadBaseline reads the baseline file at path. A missing file is an empty baseline, so every
+// metric is in range; found reports whether the file exists.
+func LoadBaseline(path string) (baseline Baseline, found bool, err error) {
+	found, err = toolutil.LoadToolData(path, []byte(`{"metrics": {}}`), &baseline)
+	if err != nil {
+		return Baseline{}, found, fmt.Errorf("failed to load baseline: %w", err)
+	}
+	if err := baseline.validate(); err != nil {
+		return Baseline{}, found, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	return baseline, found, nil
+}
+
+func (b Baseline) validate() error {
+	for area, r := range b.Metrics {
+		if !isConcernArea(area) {
+			return fmt.Errorf("unknown area %q, want cpu, memory, swap or disk", area)
+		}
+		if !validThreshold(r.Min) || !validThreshold(r.Max) || r.Min > r.Max {
+			return fmt.Errorf("%s range %g-%g: want 0 <= min <= max <= 100", area, r.Min, r.Max)
+		}
+	}
+	return nil
+}
+
+func (f *baselineFile) load() (Baseline, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return LoadBaseline(f.path)
+}
+
+// save writes baseline to a temporary file and renames it, so a crash never leaves half a file
+func (f *baselineFile) save(baseline Baseline) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".baseline-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// CompareToBaselineArgs represents the input arguments for the baseline comparison
+type CompareToBaselineArgs struct{}
+
+// CompareToBaselineResults represents the result from the baseline comparison
+type CompareToBaselineResults struct {
+	Status     string              `json:"status"` // "deviations", "in_range" or "no_baseline"
+	File       string              `json:"file"`
+	CapturedAt string              `json:"captured_at,omitempty"`
+	Metrics    []BaselineDeviation `json:"metrics"`
+	Message    string              `json:"message"`
+}
+
+// BaselineDeviation is one area's usage against its baseline range
+type BaselineDeviation struct {
+	Area            string  `json:"area"`
+	Status          string  `json:"status"` // "above", "below", "in_range", "no_range" or "not_collected"
+	UsagePercentage float64 `json:"usage_percentage,omitempty"`
+	Min             float64 `json:"min,omitempty"`
+	Max             float64 `json:"max,omitempty"`
+	Deviation       float64 `json:"deviation,omitempty"` // percentage points outside the range
+}
+
+// CaptureBaselineArgs represents the input arguments for capturing a baseline
+type CaptureBaselineArgs struct {
+	// Margin is how many percentage points around the current usage are normal (default 10)
+	Margin float64 `json:"margin,omitempty"`
+}
+
+// CaptureBaselineResults represents the result from capturing a baseline
+type CaptureBaselineResults struct {
+	Status   string   `json:"status"` // "success" or "no_metrics"
+	File     string   `json:"file"`
+	Baseline Baseline `json:"baseline"`
+	Message  string   `json:"message"`
+}
+
+// NewBaselineTools creates the compare_to_baseline and capture_baseline tools for the baseline
+// file at path (see LoadBaseline)
+func NewBaselineTools(path string) ([]tool.Tool, error) {
+	file := &baselineFile{path: path, now: time.Now}
+
+	compareToBaseline := func(ctx tool.Context, input CompareToBaselineArgs) (CompareToBaselineResults, error) {
+		fmt.Println("\n🔧 Tool: compare_to_baseline called - comparing usage with the baseline")
+		baseline, found, err := file.load()
+		if err != nil {
+			return CompareToBaselineResults{}, err
+		}
+		results := compareToBaseline(ctx.State(), baseline, found)
+		results.File = path
+		fmt.Printf("   ✓ %s\n", results.Message)
+		return results, nil
+	}
+
+	captureBaseline := func(ctx tool.Context, input CaptureBaselineArgs) (CaptureBaselineResults, error) {
+		fmt.Println("\n🔧 Tool: capture_baseline called - saving current usage as the baseline")
+		baseline, ok := captureBaseline(ctx.State(), input.Margin, file.now())
+		if !ok {
+			return CaptureBaselineResults{
+				Status:  "no_metrics",
+				File:    path,
+				Message: "No CPU, memory, swap or disk usage was collected, so the baseline was not changed",
+			}, nil
+		}
+		if err := file.save(baseline); err != nil {
+			return CaptureBaselineResults{}, err
+		}
+		fmt.Printf("   ✓ Baseline saved to %s\n", path)
+		return CaptureBaselineResults{
+			Status:   "success",
+			File:     path,
+			Baseline: baseline,
+			Message:  fmt.Sprintf("Saved the normal range of %d area(s) to %s", len(baseline.Metrics), path),
+		}, nil
+	}
+
+	compareTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "compare_to_baseline",
+			Description: "Compare the collected CPU, memory, swap and disk usage with this machine's normal ranges from the baseline file, flagging usage above or below them",
+		},
+		compareToBaseline,
+	)
+	if err != nil {
+		return nil, err
+	}
+	captureTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "capture_baseline",
+			Description: "Save the collected CPU, memory, swap and disk usage as this machine's new normal ranges, plus or minus margin percentage points (default 10). Only when the user asks.",
+		},
+		captureBaseline,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.Tool{compareTool, captureTool}, nil
+}
+
+// compareToBaseline checks the usage of every area against its baseline range, in the order of
+// concernSources. Areas without a range, or any area when there is no baseline, are in range.
+func compareToBaseline(state session.ReadonlyState, baseline Baseline, found bool) CompareToBaselineResults {
+	usage := UsagePercentages(state)
+	results := CompareToBaselineResults{CapturedAt: baseline.CapturedAt, Metrics: []BaselineDeviation{}}
+
+	var deviations []string
+	for _, area := range concernAreas() {
+		d := BaselineDeviation{Area: area}
+		current, collected := usage[area]
+		r, hasRange := baseline.Metrics[area]
+		switch {
+		case !collected:
+			d.Status = "not_collected"
+		case !hasRange:
+			d.Status, d.UsagePercentage = "no_range", current
+		default:
+			d.UsagePercentage, d.Min, d.Max = current, r.Min, r.Max
+			switch {
+			case current > r.Max:
+				d.Status, d.Deviation = "above", roundPoints(current-r.Max)
+			case current < r.Min:
+				d.Status, d.Deviation = "below", roundPoints(r.Min-current)
+			default:
+				d.Status = "in_range"
+			}
+		}
+		if d.Status == "above" || d.Status == "below" {
+			deviations = append(deviations, fmt.Sprintf("%s %s by %g points", area, d.Status, d.Deviation))
+		}
+		results.Metrics = append(results.Metrics, d)
+	}
+
+	switch {
+	case !found:
+		results.Status = "no_baseline"
+		results.Message = "No baseline file yet, so every metric counts as in range; capture one with capture_baseline"
+	case len(deviations) > 0:
+		results.Status = "deviations"
+		results.Message = "Outside the baseline: " + strings.Join(deviations, ", ")
+	default:
+		results.Status = "in_range"
+		results.Message = "All collected metrics are within their baseline range"
+	}
+	return results
+}
+
+// captureBaseline returns the collected usage as a baseline, margin points either side of it.
+// It reports false when no usage was collected.
+func captureBaseline(state session.ReadonlyState, margin float64, now time.Time) (Baseline, bool) {
+	if margin <= 0 || margin > 100 || math.IsNaN(margin) {
+		margin = DEFAULT_BASELINE_MARGIN
+	}
+	usage := UsagePercentages(state)
+	if len(usage) == 0 {
+		return Baseline{}, false
+	}
+
+	baseline := Baseline{CapturedAt: now.Format(time.RFC3339), Metrics: map[string]BaselineRange{}}
+	for area, current := range usage {
+		baseline.Metrics[area] = BaselineRange{
+			Min: roundPoints(math.Max(0, current-margin)),
+			Max: roundPoints(math.Min(100, current+margin)),
+		}
+	}
+	return baseline, true
+}
+
+// concernAreas returns the areas of concernSources in order
+func concernAreas() []string {
+	areas := make([]string, 0, len(concernSources))
+	for _, src := range concernSources {
+		areas = append(areas, src.area)
+	}
+	return areas
+}
+
+func isConcernArea(area string) bool {
+	for _, src := range concernSources {
+		if src.area == area {
+			return true
+		}
+	}
+	return false
+}
+
+func roundPoints(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// BaselinePath returns BASELINE_FILE, or DEFAULT_BASELINE_FILE when it is unset. getenv is
+// usually os.Getenv.
+func BaselinePath(getenv func(string) string) string {
+	if path := strings.TrimSpace(getenv(BASELINE_FILE_ENV)); path != "" {
+		return path
+	}
+	return DEFAULT_BASELINE_FILE
+}
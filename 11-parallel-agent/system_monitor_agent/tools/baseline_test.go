@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func baselineState() *agenttest.State {
+	return agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY: metrics(map[string]any{"avg_usage_percentage": 92.0}, map[string]any{}),
+		MEMORY_METRICS_KEY: metrics(
+			map[string]any{"memory_usage_percentage": 20.0, "swap_usage_percentage": 5.0},
+			map[string]any{}),
+	}, false)
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	baseline := Baseline{Metrics: map[string]BaselineRange{
+		"cpu":    {Min: 40, Max: 80},
+		"memory": {Min: 30, Max: 60},
+		"swap":   {Min: 0, Max: 10},
+		"disk":   {Min: 50, Max: 70},
+	}}
+
+	results := compareToBaseline(baselineState(), baseline, true)
+	if results.Status != "deviations" {
+		t.Fatalf("status = %q, want deviations", results.Status)
+	}
+	want := map[string]struct {
+		status    string
+		deviation float64
+	}{
+		"cpu":    {"above", 12},
+		"memory": {"below", 10},
+		"swap":   {"in_range", 0},
+		"disk":   {"not_collected", 0},
+	}
+	if len(results.Metrics) != len(want) {
+		t.Fatalf("metrics = %+v, want one per area", results.Metrics)
+	}
+	for _, d := range results.Metrics {
+		if w := want[d.Area]; d.Status != w.status || d.Deviation != w.deviation {
+			t.Errorf("%s = %+v, want %s by %g", d.Area, d, w.status, w.deviation)
+		}
+	}
+	if !strings.Contains(results.Message, "cpu above by 12 points") {
+		t.Errorf("message = %q, want the cpu deviation", results.Message)
+	}
+}
+
+func TestCompareToBaselineMissingFile(t *testing.T) {
+	baseline, found, err := LoadBaseline(filepath.Join(t.TempDir(), "baseline.json"))
+	if err != nil || found {
+		t.Fatalf("LoadBaseline() = %v, %v; want no file and no error", found, err)
+	}
+
+	results := compareToBaseline(baselineState(), baseline, found)
+	if results.Status != "no_baseline" {
+		t.Errorf("status = %q, want no_baseline", results.Status)
+	}
+	for _, d := range results.Metrics {
+		if d.Status == "above" || d.Status == "below" {
+			t.Errorf("%s = %+v, want in range without a baseline", d.Area, d)
+		}
+	}
+}
+
+func TestCaptureBaselineRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	file := &baselineFile{path: path, now: func() time.Time { return now }}
+
+	baseline, ok := captureBaseline(baselineState(), 0, now)
+	if !ok {
+		t.Fatal("captureBaseline() found no metrics")
+	}
+	if err := file.save(baseline); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, found, err := file.load()
+	if err != nil || !found {
+		t.Fatalf("load() = %v, %v; want the saved baseline", found, err)
+	}
+	want := map[string]BaselineRange{
+		"cpu":    {Min: 82, Max: 100}, // clamped at 100
+		"memory": {Min: 10, Max: 30},
+		"swap":   {Min: 0, Max: 15}, // clamped at 0
+	}
+	if len(loaded.Metrics) != len(want) || loaded.CapturedAt != "2025-01-02T12:00:00Z" {
+		t.Fatalf("loaded = %+v, want %v", loaded, want)
+	}
+	for area, r := range want {
+		if loaded.Metrics[area] != r {
+			t.Errorf("%s = %+v, want %+v", area, loaded.Metrics[area], r)
+		}
+	}
+
+	// The same usage is in range of the baseline captured from it
+	if results := compareToBaseline(baselineState(), loaded, found); results.Status != "in_range" {
+		t.Errorf("status = %q, want in_range", results.Status)
+	}
+
+	if _, ok := captureBaseline(agenttest.NewState(nil, false), 5, now); ok {
+		t.Error("captureBaseline() without metrics = ok, want false")
+	}
+}
+
+func TestLoadBaselineRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"unknown area":  `{"metrics": {"gpu": {"min": 0, "max": 50}}}`,
+		"min above max": `{"metrics": {"cpu": {"min": 80, "max": 40}}}`,
+		"above 100":     `{"metrics": {"disk": {"min": 50, "max": 120}}}`,
+		"unknown field": `{"metrics": {}, "cpus": {}}`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := LoadBaseline(path); err == nil {
+			t.Errorf("%s: LoadBaseline() = nil error, want invalid", name)
+		}
+	}
+}
+
+func TestBaselinePath(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+	if got := BaselinePath(getenv); got != DEFAULT_BASELINE_FILE {
+		t.Errorf("BaselinePath() = %q, want %q", got, DEFAULT_BASELINE_FILE)
+	}
+	env[BASELINE_FILE_ENV] = " /var/lib/monitor/baseline.json "
+	if got := BaselinePath(getenv); got != "/var/lib/monitor/baseline.json" {
+		t.Errorf("BaselinePath() = %q, want the env path", got)
+	}
+}
@@ -0,0 +1,209 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// State keys where the info tools save their stats and additional info, so concerns_summary
+// can read the numbers rather than the agents' free-text reports
+const (
+	CPU_METRICS_KEY    = "cpu_metrics"
+	MEMORY_METRICS_KEY = "memory_metrics"
+	DISK_METRICS_KEY   = "disk_metrics"
+)
+
+// Usage levels for the severity of a flagged concern; below HIGH_THRESHOLD it is a warning.
+// Whether usage is a concern at all depends on the alert thresholds (see thresholds.go).
+const (
+	HIGH_THRESHOLD     = 90.0
+	CRITICAL_THRESHOLD = 95.0
+)
+
+// ConcernsSummaryArgs represents the input arguments for the concerns summary
+type ConcernsSummaryArgs struct{}
+
+// ConcernsSummaryResults represents the result from the concerns summary
+type ConcernsSummaryResults struct {
+	Status   string    `json:"status"` // "healthy" or "concerns"
+	Concerns []Concern `json:"concerns"`
+	Missing  []string  `json:"missing,omitempty"` // reports with no metrics in state
+	Message  string    `json:"message"`
+}
+
+// Concern is one flagged metric, most severe first in ConcernsSummaryResults
+type Concern struct {
+	Severity        string  `json:"severity"` // "critical", "high" or "warning"
+	Area            string  `json:"area"`     // "cpu", "memory", "swap" or "disk"
+	Concern         string  `json:"concern"`
+	UsagePercentage float64 `json:"usage_percentage"`
+	Report          string  `json:"report"` // state key of the agent report with the details
+}
+
+// concernSource is one usage metric and the concern pointer that flags it
+type concernSource struct {
+	area       string
+	metricsKey string
+	reportKey  string
+	statsField string
+	concernKey string
+}
+
+var concernSources = []concernSource{
+	{"cpu", CPU_METRICS_KEY, "cpu_info_report", "avg_usage_percentage", "performance_concern"},
+	{"memory", MEMORY_METRICS_KEY, "memory_info_report", "memory_usage_percentage", "performance_concern"},
+	{"swap", MEMORY_METRICS_KEY, "memory_info_report", "swap_usage_percentage", "swap_concern"},
+	{"disk", DISK_METRICS_KEY, "disk_info_report", "usage_percentage", "disk_space_concern"},
+}
+
+var severityRank = map[string]int{"critical": 0, "high": 1, "warning": 2}
+
+// NewConcernsSummary creates a tool that collects every CPU, memory, swap and disk concern
+// the info tools flagged into one list, most severe first. defaults are the alert thresholds
+// used when set_thresholds hasn't changed them.
+func NewConcernsSummary(defaults Thresholds) (tool.Tool, error) {
+	concernsSummary := func(ctx tool.Context, input ConcernsSummaryArgs) (ConcernsSummaryResults, error) {
+		fmt.Println("\n🔧 Tool: concerns_summary called - prioritizing flagged concerns")
+
+		results := summarizeConcerns(ctx.State(), CurrentThresholds(ctx.State(), defaults))
+
+		fmt.Printf("   ✓ Found %d concern(s)\n", len(results.Concerns))
+		return results, nil
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "concerns_summary",
+			Description: "List all CPU, memory, swap and disk concerns flagged by the info tools in one list, ordered by severity",
+		},
+		concernsSummary,
+	)
+}
+
+// saveMetrics stores a tool's stats and additional info under key. They are saved as plain
+// JSON objects, the form they take after a database round trip anyway.
+func saveMetrics(ctx tool.Context, key string, stats any, info AdditionalInfo) error {
+	data, err := json.Marshal(map[string]any{"stats": stats, "additional_info": info})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	var metrics map[string]any
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	if err := ctx.State().Set(key, metrics); err != nil {
+		return fmt.Errorf("failed to save %s to state: %w", key, err)
+	}
+	return nil
+}
+
+// summarizeConcerns reads the saved metrics. A metric is a concern when its concern pointer is
+// set or its usage is above its alert threshold; the usage percentage sets the severity.
+func summarizeConcerns(state session.ReadonlyState, thresholds Thresholds) ConcernsSummaryResults {
+	results := ConcernsSummaryResults{Concerns: []Concern{}}
+
+	for _, src := range concernSources {
+		stats, info, ok := readMetrics(state, src.metricsKey)
+		if !ok {
+			if !slices.Contains(results.Missing, src.reportKey) {
+				results.Missing = append(results.Missing, src.reportKey)
+			}
+			continue
+		}
+
+		usage, _ := stats[src.statsField].(float64)
+		threshold := thresholds.forArea(src.area)
+		concern, flagged := info[src.concernKey].(string)
+		// Memory and CPU both use performance_concern, so it only counts for the usage that's high
+		if src.concernKey == "performance_concern" && usage <= threshold {
+			flagged = false
+		}
+		if !flagged && usage <= threshold {
+			continue
+		}
+		if concern == "" {
+			concern = fmt.Sprintf("High %s usage detected", src.area)
+		}
+
+		results.Concerns = append(results.Concerns, Concern{
+			Severity:        concernSeverity(usage),
+			Area:            src.area,
+			Concern:         concern,
+			UsagePercentage: usage,
+			Report:          src.reportKey,
+		})
+	}
+
+	slices.SortStableFunc(results.Concerns, func(a, b Concern) int {
+		if d := severityRank[a.Severity] - severityRank[b.Severity]; d != 0 {
+			return d
+		}
+		switch {
+		case a.UsagePercentage > b.UsagePercentage:
+			return -1
+		case a.UsagePercentage < b.UsagePercentage:
+			return 1
+		}
+		return 0
+	})
+
+	switch {
+	case len(results.Concerns) > 0:
+		results.Status = "concerns"
+		results.Message = fmt.Sprintf("%d concern(s) flagged, most severe first", len(results.Concerns))
+	case len(results.Missing) > 0:
+		results.Status = "healthy"
+		results.Message = "All healthy: no concerns flagged in the metrics that were collected"
+	default:
+		results.Status = "healthy"
+		results.Message = "All healthy: no CPU, memory, swap or disk concerns flagged"
+	}
+	return results
+}
+
+// UsagePercentages returns the usage percentage of each area ("cpu", "memory", "swap" and
+// "disk") whose metrics the info tools saved to state
+func UsagePercentages(state session.ReadonlyState) map[string]float64 {
+	usage := make(map[string]float64, len(concernSources))
+	for _, src := range concernSources {
+		stats, _, ok := readMetrics(state, src.metricsKey)
+		if !ok {
+			continue
+		}
+		if val, ok := stats[src.statsField].(float64); ok {
+			usage[src.area] = val
+		}
+	}
+	return usage
+}
+
+// readMetrics returns the stats and additional info saved under key by saveMetrics
+func readMetrics(state session.ReadonlyState, key string) (stats, info map[string]any, ok bool) {
+	val, err := state.Get(key)
+	if err != nil {
+		return nil, nil, false
+	}
+	metrics, ok := val.(map[string]any)
+	if !ok {
+		return nil, nil, false
+	}
+	stats, _ = metrics["stats"].(map[string]any)
+	info, _ = metrics["additional_info"].(map[string]any)
+	return stats, info, true
+}
+
+func concernSeverity(usage float64) string {
+	switch {
+	case usage >= CRITICAL_THRESHOLD:
+		return "critical"
+	case usage >= HIGH_THRESHOLD:
+		return "high"
+	}
+	return "warning"
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func metrics(stats, info map[string]any) map[string]any {
+	return map[string]any{"stats": stats, "additional_info": info}
+}
+
+func TestSummarizeConcernsPrioritizes(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY: metrics(
+			map[string]any{"avg_usage_percentage": 85.0},
+			map[string]any{"performance_concern": "High CPU usage detected"}),
+		MEMORY_METRICS_KEY: metrics(
+			map[string]any{"memory_usage_percentage": 50.0, "swap_usage_percentage": 92.0},
+			map[string]any{"swap_concern": "High swap usage detected"}),
+		DISK_METRICS_KEY: metrics(
+			map[string]any{"usage_percentage": 97.5},
+			map[string]any{"disk_space_concern": "High disk usage detected"}),
+	}, false)
+
+	results := summarizeConcerns(state, DefaultThresholds())
+	if results.Status != "concerns" || len(results.Missing) != 0 {
+		t.Fatalf("results = %+v, want concerns with nothing missing", results)
+	}
+	want := []struct{ area, severity string }{{"disk", "critical"}, {"swap", "high"}, {"cpu", "warning"}}
+	if len(results.Concerns) != len(want) {
+		t.Fatalf("got %d concerns %+v, want %d", len(results.Concerns), results.Concerns, len(want))
+	}
+	for i, w := range want {
+		if c := results.Concerns[i]; c.Area != w.area || c.Severity != w.severity {
+			t.Errorf("concern %d = %s/%s, want %s/%s", i, c.Area, c.Severity, w.area, w.severity)
+		}
+	}
+}
+
+func TestSummarizeConcernsUsesThresholds(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY:  metrics(map[string]any{"avg_usage_percentage": 85.0}, map[string]any{}),
+		DISK_METRICS_KEY: metrics(map[string]any{"usage_percentage": 60.0}, map[string]any{}),
+	}, false)
+
+	results := summarizeConcerns(state, Thresholds{CPU: 90, Memory: 80, Disk: 50})
+	if len(results.Concerns) != 1 || results.Concerns[0].Area != "disk" {
+		t.Errorf("concerns = %+v, want only disk above its lowered threshold", results.Concerns)
+	}
+}
+
+func TestSummarizeConcernsHealthy(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY:    metrics(map[string]any{"avg_usage_percentage": 12.0}, map[string]any{}),
+		MEMORY_METRICS_KEY: metrics(map[string]any{"memory_usage_percentage": 40.0, "swap_usage_percentage": 0.0}, map[string]any{}),
+	}, false)
+
+	results := summarizeConcerns(state, DefaultThresholds())
+	if results.Status != "healthy" || len(results.Concerns) != 0 {
+		t.Errorf("results = %+v, want healthy with no concerns", results)
+	}
+	if len(results.Missing) != 1 || results.Missing[0] != "disk_info_report" {
+		t.Errorf("missing = %v, want [disk_info_report]", results.Missing)
+	}
+}
+
+func TestUsagePercentages(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		MEMORY_METRICS_KEY: metrics(map[string]any{"memory_usage_percentage": 40.0, "swap_usage_percentage": 5.0}, map[string]any{}),
+		DISK_METRICS_KEY:   metrics(map[string]any{"usage_percentage": 70.0}, map[string]any{}),
+	}, false)
+
+	usage := UsagePercentages(state)
+	want := map[string]float64{"memory": 40, "swap": 5, "disk": 70}
+	if len(usage) != len(want) {
+		t.Fatalf("usage = %v, want %v", usage, want)
+	}
+	for area, v := range want {
+		if usage[area] != v {
+			t.Errorf("usage[%s] = %v, want %v", area, usage[area], v)
+		}
+	}
+}
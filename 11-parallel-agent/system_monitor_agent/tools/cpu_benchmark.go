@@ -0,0 +1,139 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	DEFAULT_CPU_BENCHMARK_SECONDS = 2
+	MAX_CPU_BENCHMARK_SECONDS     = 5
+	// How many operations a worker runs between checks of the stop flag
+	cpuBenchmarkBatch = 4096
+)
+
+// CPUBenchmarkArgs represents the input arguments for the CPU benchmark.
+// Run must be set explicitly because the benchmark saturates every core.
+type CPUBenchmarkArgs struct {
+	Run             bool `json:"run"`
+	DurationSeconds int  `json:"duration_seconds,omitempty"`
+}
+
+// CPUBenchmarkResults represents the result from the CPU benchmark
+type CPUBenchmarkResults struct {
+	Ran                 bool    `json:"ran"`
+	Cores               int     `json:"cores"`
+	Duration            string  `json:"duration,omitempty"`
+	TotalOperations     uint64  `json:"total_operations,omitempty"`
+	OpsPerSecond        float64 `json:"ops_per_second,omitempty"`
+	OpsPerSecondPerCore float64 `json:"ops_per_second_per_core,omitempty"`
+	Message             string  `json:"message"`
+}
+
+// NewCPUBenchmark creates a tool that runs a short busy loop on every core and
+// reports operations per second as a rough, relative CPU score.
+func NewCPUBenchmark() (tool.Tool, error) {
+	runCPUBenchmark := func(ctx tool.Context, input CPUBenchmarkArgs) (CPUBenchmarkResults, error) {
+		cores := runtime.NumCPU()
+
+		if !input.Run {
+			return CPUBenchmarkResults{
+				Ran:     false,
+				Cores:   cores,
+				Message: "Benchmark not run. It loads every CPU core, so call again with run=true only if the user explicitly asked for it.",
+			}, nil
+		}
+
+		seconds := input.DurationSeconds
+		if seconds <= 0 {
+			seconds = DEFAULT_CPU_BENCHMARK_SECONDS
+		}
+		if seconds > MAX_CPU_BENCHMARK_SECONDS {
+			seconds = MAX_CPU_BENCHMARK_SECONDS
+		}
+		duration := time.Duration(seconds) * time.Second
+
+		fmt.Printf("\n🔧 Tool: run_cpu_benchmark called - loading %d cores for %s\n", cores, duration)
+
+		var stop atomic.Bool
+		timer := time.AfterFunc(duration, func() { stop.Store(true) })
+		defer timer.Stop()
+
+		// Also stop early if the request is cancelled
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				stop.Store(true)
+			case <-done:
+			}
+		}()
+
+		var total atomic.Uint64
+		var wg sync.WaitGroup
+		start := time.Now()
+		for i := 0; i < cores; i++ {
+			wg.Add(1)
+			go func(seed uint64) {
+				defer wg.Done()
+				total.Add(cpuBusyLoop(seed, &stop))
+			}(uint64(i) + 1)
+		}
+		wg.Wait()
+		elapsed := time.Since(start)
+
+		if err := ctx.Err(); err != nil {
+			return CPUBenchmarkResults{}, fmt.Errorf("cpu benchmark cancelled: %w", err)
+		}
+
+		ops := total.Load()
+		opsPerSecond := float64(ops) / elapsed.Seconds()
+
+		fmt.Printf("   ✓ Measured: %.0f ops/s across %d cores\n", opsPerSecond, cores)
+
+		return CPUBenchmarkResults{
+			Ran:                 true,
+			Cores:               cores,
+			Duration:            elapsed.Round(time.Millisecond).String(),
+			TotalOperations:     ops,
+			OpsPerSecond:        opsPerSecond,
+			OpsPerSecondPerCore: opsPerSecond / float64(cores),
+			Message:             "Rough integer-arithmetic score; only comparable between runs of this tool.",
+		}, nil
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "run_cpu_benchmark",
+			Description: "Actively load-test the CPU with a busy loop on every core and report operations per second. Intrusive: set run=true only when the user explicitly asks for a benchmark or stress test. Optional duration_seconds (default 2, max 5).",
+		},
+		runCPUBenchmark,
+	)
+}
+
+// cpuBusyLoop runs xorshift rounds until stop is set and returns how many it completed
+func cpuBusyLoop(seed uint64, stop *atomic.Bool) uint64 {
+	var ops uint64
+	x := seed
+	for !stop.Load() {
+		for i := 0; i < cpuBenchmarkBatch; i++ {
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+		}
+		ops += cpuBenchmarkBatch
+	}
+	// Keep the result observable so the compiler can't drop the loop
+	if x == 0 {
+		ops++
+	}
+	return ops
+}
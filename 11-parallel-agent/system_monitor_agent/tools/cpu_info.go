@@ -34,6 +34,7 @@ type CPUStats struct {
 	LogicalCores       int     `json:"logical_cores"`
 	AvgUsagePercentage float64 `json:"avg_usage_percentage"`
 	HighUsageAlert     bool    `json:"high_usage_alert"`
+	AlertThreshold     float64 `json:"alert_threshold"`
 }
 
 // AdditionalInfo contains metadata about the data collection
@@ -46,8 +47,9 @@ type AdditionalInfo struct {
 }
 
 // NewGetCPUInfo creates a tool to gather real CPU information using gopsutil.
-// This tool collects actual CPU metrics from the system.
-func NewGetCPUInfo() (tool.Tool, error) {
+// This tool collects actual CPU metrics from the system and flags usage above the session's
+// CPU threshold, or the one in defaults if set_thresholds hasn't changed it.
+func NewGetCPUInfo(defaults Thresholds) (tool.Tool, error) {
 	getCPUInfo := func(ctx tool.Context, input CPUInfoArgs) (CPUInfoResults, error) {
 		fmt.Println("\n🔧 Tool: get_cpu_info called - gathering real CPU metrics")
 
@@ -62,8 +64,9 @@ func NewGetCPUInfo() (tool.Tool, error) {
 			return CPUInfoResults{}, fmt.Errorf("failed to get logical CPU count: %w", err)
 		}
 
-		// Get per-core CPU usage (with 1 second interval for accuracy)
-		perCPU, err := cpu.Percent(time.Second, true)
+		// Get per-core CPU usage (with 1 second interval for accuracy); the context stops the
+		// sampling when the run is canceled, so it never spills into a watch mode's next cycle
+		perCPU, err := cpu.PercentWithContext(ctx, time.Second, true)
 		if err != nil {
 			return CPUInfoResults{}, fmt.Errorf("failed to get per-CPU usage: %w", err)
 		}
@@ -78,12 +81,13 @@ func NewGetCPUInfo() (tool.Tool, error) {
 
 		// Calculate average usage
 		avgUsage := totalUsage / float64(len(perCPU))
-		highUsage := avgUsage > 80
+		threshold := CurrentThresholds(ctx.State(), defaults).CPU
+		highUsage := avgUsage > threshold
 
 		// Performance concern
 		var performanceConcern *string
 		if highUsage {
-			concern := "High CPU usage detected"
+			concern := fmt.Sprintf("High CPU usage detected (above %g%%)", threshold)
 			performanceConcern = &concern
 		}
 
@@ -99,6 +103,7 @@ func NewGetCPUInfo() (tool.Tool, error) {
 			LogicalCores:       logicalCount,
 			AvgUsagePercentage: avgUsage,
 			HighUsageAlert:     highUsage,
+			AlertThreshold:     threshold,
 		}
 
 		additionalInfo := AdditionalInfo{
@@ -107,6 +112,10 @@ func NewGetCPUInfo() (tool.Tool, error) {
 			PerformanceConcern:  performanceConcern,
 		}
 
+		if err := saveMetrics(ctx, CPU_METRICS_KEY, stats, additionalInfo); err != nil {
+			return CPUInfoResults{}, err
+		}
+
 		fmt.Printf("   ✓ Collected: %d physical cores, %d logical cores, avg usage: %.1f%%\n",
 			physicalCount, logicalCount, avgUsage)
 
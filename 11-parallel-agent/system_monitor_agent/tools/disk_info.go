@@ -38,11 +38,13 @@ type DiskStats struct {
 	TotalSpaceGB    float64 `json:"total_space_gb"`
 	FreeSpaceGB     float64 `json:"free_space_gb"`
 	UsedSpaceGB     float64 `json:"used_space_gb"`
+	AlertThreshold  float64 `json:"alert_threshold"`
 }
 
 // NewGetDiskInfo creates a tool to gather real disk information using gopsutil.
-// This tool collects actual disk usage from the system.
-func NewGetDiskInfo() (tool.Tool, error) {
+// This tool collects actual disk usage from the system and flags usage above the session's
+// disk threshold, or the one in defaults if set_thresholds hasn't changed it.
+func NewGetDiskInfo(defaults Thresholds) (tool.Tool, error) {
 	getDiskInfo := func(ctx tool.Context, input DiskInfoArgs) (DiskInfoResults, error) {
 		fmt.Println("\n🔧 Tool: get_disk_info called - gathering real disk metrics")
 
@@ -52,8 +54,8 @@ func NewGetDiskInfo() (tool.Tool, error) {
 			mountPoint = "C:"
 		}
 
-		// Get disk usage for the primary mount point
-		usage, err := disk.Usage(mountPoint)
+		// Get disk usage for the primary mount point; the context lets a timeout wrapper stop a hung scan
+		usage, err := disk.UsageWithContext(ctx, mountPoint)
 		if err != nil {
 			return DiskInfoResults{}, fmt.Errorf("failed to get disk usage: %w", err)
 		}
@@ -64,7 +66,7 @@ func NewGetDiskInfo() (tool.Tool, error) {
 		freeGB := float64(usage.Free) / (1024 * 1024 * 1024)
 
 		// Get partition information
-		partitions, err := disk.Partitions(false)
+		partitions, err := disk.PartitionsWithContext(ctx, false)
 		var partitionInfo []string
 		if err == nil {
 			for _, partition := range partitions {
@@ -82,18 +84,21 @@ func NewGetDiskInfo() (tool.Tool, error) {
 			Partitions:      partitionInfo,
 		}
 
+		threshold := CurrentThresholds(ctx.State(), defaults).Disk
+
 		stats := DiskStats{
 			UsagePercentage: usage.UsedPercent,
 			TotalSpaceGB:    totalGB,
 			FreeSpaceGB:     freeGB,
 			UsedSpaceGB:     usedGB,
+			AlertThreshold:  threshold,
 		}
 
 		// Check for disk space concerns
-		highDiskUsage := usage.UsedPercent > 80
+		highDiskUsage := usage.UsedPercent > threshold
 		var diskConcern *string
 		if highDiskUsage {
-			concern := "High disk usage detected"
+			concern := fmt.Sprintf("High disk usage detected (above %g%%)", threshold)
 			diskConcern = &concern
 		}
 
@@ -103,6 +108,13 @@ func NewGetDiskInfo() (tool.Tool, error) {
 			DiskSpaceConcern:    diskConcern,
 		}
 
+		// Past a timeout the wrapper has already answered, so the metrics are discarded
+		if ctx.Err() == nil {
+			if err := saveMetrics(ctx, DISK_METRICS_KEY, stats, additionalInfo); err != nil {
+				return DiskInfoResults{}, err
+			}
+		}
+
 		fmt.Printf("   ✓ Collected: %.2f GB total, %.2f GB free, %.1f%% used\n",
 			totalGB, freeGB, usage.UsedPercent)
 
@@ -37,11 +37,13 @@ type MemoryStats struct {
 	SwapUsagePercentage   float64 `json:"swap_usage_percentage"`
 	TotalMemoryGB         float64 `json:"total_memory_gb"`
 	AvailableMemoryGB     float64 `json:"available_memory_gb"`
+	AlertThreshold        float64 `json:"alert_threshold"` // applies to memory and swap
 }
 
 // NewGetMemoryInfo creates a tool to gather real memory information using gopsutil.
-// This tool collects actual RAM and swap usage from the system.
-func NewGetMemoryInfo() (tool.Tool, error) {
+// This tool collects actual RAM and swap usage from the system and flags either above the
+// session's memory threshold, or the one in defaults if set_thresholds hasn't changed it.
+func NewGetMemoryInfo(defaults Thresholds) (tool.Tool, error) {
 	getMemoryInfo := func(ctx tool.Context, input MemoryInfoArgs) (MemoryInfoResults, error) {
 		fmt.Println("\n🔧 Tool: get_memory_info called - gathering real memory metrics")
 
@@ -74,24 +76,27 @@ func NewGetMemoryInfo() (tool.Tool, error) {
 			SwapPercentage:   fmt.Sprintf("%.1f%%", swapStat.UsedPercent),
 		}
 
+		threshold := CurrentThresholds(ctx.State(), defaults).Memory
+
 		stats := MemoryStats{
 			MemoryUsagePercentage: vmStat.UsedPercent,
 			SwapUsagePercentage:   swapStat.UsedPercent,
 			TotalMemoryGB:         totalGB,
 			AvailableMemoryGB:     availableGB,
+			AlertThreshold:        threshold,
 		}
 
 		// Check for concerns
-		highMemoryUsage := vmStat.UsedPercent > 80
-		highSwapUsage := swapStat.UsedPercent > 80
+		highMemoryUsage := vmStat.UsedPercent > threshold
+		highSwapUsage := swapStat.UsedPercent > threshold
 
 		var memConcern, swapConcern *string
 		if highMemoryUsage {
-			concern := "High memory usage detected"
+			concern := fmt.Sprintf("High memory usage detected (above %g%%)", threshold)
 			memConcern = &concern
 		}
 		if highSwapUsage {
-			concern := "High swap usage detected"
+			concern := fmt.Sprintf("High swap usage detected (above %g%%)", threshold)
 			swapConcern = &concern
 		}
 
@@ -102,6 +107,10 @@ func NewGetMemoryInfo() (tool.Tool, error) {
 			SwapConcern:         swapConcern,
 		}
 
+		if err := saveMetrics(ctx, MEMORY_METRICS_KEY, stats, additionalInfo); err != nil {
+			return MemoryInfoResults{}, err
+		}
+
 		fmt.Printf("   ✓ Collected: %.2f GB total, %.2f GB available, %.1f%% used\n",
 			totalGB, availableGB, vmStat.UsedPercent)
 
@@ -0,0 +1,170 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"fmt"
+	"runtime"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// REMEDIATION_ADVICE_KEY is where the Remediation Advisor stores its advice
+const REMEDIATION_ADVICE_KEY = "remediation_advice"
+
+// ADVISORY_NOTICE goes with every suggestion: the commands are only text for the user to
+// review. No tool in this example runs them.
+const ADVISORY_NOTICE = "Advisory only: these commands were NOT run. Review each one and run it yourself only if it fits your system."
+
+// SuggestRemediationArgs represents the input arguments for remediation suggestions
+type SuggestRemediationArgs struct{}
+
+// SuggestRemediationResults represents the result from remediation suggestions
+type SuggestRemediationResults struct {
+	Status      string        `json:"status"` // "suggestions" or "healthy"
+	OS          string        `json:"os"`
+	Advisory    bool          `json:"advisory"`
+	Notice      string        `json:"notice"`
+	Remediation []Remediation `json:"remediation"`
+	Message     string        `json:"message"`
+}
+
+// Remediation is the suggested steps for one concern from concerns_summary
+type Remediation struct {
+	Severity string            `json:"severity"`
+	Area     string            `json:"area"`
+	Concern  string            `json:"concern"`
+	Steps    []RemediationStep `json:"steps"`
+}
+
+// RemediationStep is one suggested step; Command is empty when there is no command for the OS
+type RemediationStep struct {
+	Description string `json:"description"`
+	Command     string `json:"command,omitempty"`
+}
+
+// remediationSteps are the suggested steps per area and GOOS. Placeholders like <PID> are left
+// for the user to fill in; nothing here is ever executed.
+var remediationSteps = map[string]map[string][]RemediationStep{
+	"cpu": {
+		"linux": {
+			{"List the processes using the most CPU", "ps -eo pid,comm,%cpu --sort=-%cpu | head -n 6"},
+			{"Lower the priority of a busy process that must keep running", "renice +10 -p <PID>"},
+			{"Stop a runaway process once you're sure it's safe to", "kill <PID>"},
+		},
+		"darwin": {
+			{"List the processes using the most CPU", "ps -Ao pid,comm,%cpu -r | head -n 6"},
+			{"Lower the priority of a busy process that must keep running", "renice +10 -p <PID>"},
+			{"Stop a runaway process once you're sure it's safe to", "kill <PID>"},
+		},
+		"windows": {
+			{"List the processes using the most CPU (PowerShell)", "Get-Process | Sort-Object CPU -Descending | Select-Object -First 5 Id, ProcessName, CPU"},
+			{"Stop a runaway process once you're sure it's safe to", "Stop-Process -Id <PID>"},
+		},
+	},
+	"memory": {
+		"linux": {
+			{"List the processes using the most memory", "ps -eo pid,comm,%mem,rss --sort=-%mem | head -n 6"},
+			{"Stop or restart the top memory process once you're sure it's safe to", "kill <PID>"},
+		},
+		"darwin": {
+			{"List the processes using the most memory", "ps -Ao pid,comm,%mem,rss -m | head -n 6"},
+			{"Stop or restart the top memory process once you're sure it's safe to", "kill <PID>"},
+		},
+		"windows": {
+			{"List the processes using the most memory (PowerShell)", "Get-Process | Sort-Object WorkingSet64 -Descending | Select-Object -First 5 Id, ProcessName, WorkingSet64"},
+			{"Stop or restart the top memory process once you're sure it's safe to", "Stop-Process -Id <PID>"},
+		},
+	},
+	"swap": {
+		"linux": {
+			{"Check whether the system is actively swapping (si/so columns)", "vmstat 1 5"},
+			{"Find the processes with the most swapped-out memory", "grep VmSwap /proc/[0-9]*/status | sort -k2 -n -r | head -n 5"},
+		},
+		"darwin": {
+			{"Check swap usage", "sysctl vm.swapusage"},
+			{"Check memory pressure", "memory_pressure"},
+		},
+		"windows": {
+			{"Check page file usage (PowerShell)", "Get-CimInstance Win32_PageFileUsage | Select-Object Name, CurrentUsage, PeakUsage"},
+		},
+	},
+	"disk": {
+		"linux": {
+			{"Find the largest directories on the root filesystem", "sudo du -xh / --max-depth=2 2>/dev/null | sort -rh | head -n 10"},
+			{"Clear the package cache (Debian/Ubuntu)", "sudo apt-get clean"},
+			{"Clear the package cache (Fedora/RHEL)", "sudo dnf clean all"},
+			{"Shrink the systemd journal to the last 7 days", "sudo journalctl --vacuum-time=7d"},
+		},
+		"darwin": {
+			{"Find the largest folders in your home directory", "du -sh ~/* 2>/dev/null | sort -rh | head -n 10"},
+			{"Clear the Homebrew cache", "brew cleanup --prune=all"},
+		},
+		"windows": {
+			{"Find the largest folders in your profile (PowerShell)", "Get-ChildItem $env:USERPROFILE -Directory | ForEach-Object { [pscustomobject]@{Folder=$_.Name; GB=[math]::Round((Get-ChildItem $_.FullName -Recurse -File -ErrorAction SilentlyContinue | Measure-Object Length -Sum).Sum / 1GB, 2)} } | Sort-Object GB -Descending | Select-Object -First 10"},
+			{"Open Disk Cleanup to remove temporary files", "cleanmgr"},
+		},
+	},
+}
+
+// genericSteps are used for an OS without specific commands
+var genericSteps = map[string][]RemediationStep{
+	"cpu":    {{Description: "Use the system's process monitor to find and stop or deprioritize the busiest process"}},
+	"memory": {{Description: "Use the system's process monitor to find and restart the process using the most memory"}},
+	"swap":   {{Description: "Check whether the system is actively swapping and free memory by closing large processes"}},
+	"disk":   {{Description: "Find the largest directories and remove caches, old logs and files you no longer need"}},
+}
+
+// NewSuggestRemediation creates a tool that suggests commands for each concern concerns_summary
+// reports, tailored to the OS the monitor runs on. It only returns text; it never runs them.
+// thresholds are the default alert thresholds.
+func NewSuggestRemediation(thresholds Thresholds) (tool.Tool, error) {
+	suggestRemediation := func(ctx tool.Context, input SuggestRemediationArgs) (SuggestRemediationResults, error) {
+		fmt.Println("\n🔧 Tool: suggest_remediation called - preparing advisory commands")
+
+		results := suggestRemediation(ctx.State(), CurrentThresholds(ctx.State(), thresholds), runtime.GOOS)
+
+		fmt.Printf("   ✓ %d concern(s) with suggestions for %s\n", len(results.Remediation), results.OS)
+		return results, nil
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "suggest_remediation",
+			Description: "Suggest OS-specific commands the user could run to address each flagged CPU, memory, swap and disk concern. Suggestions are advisory and are never executed.",
+		},
+		suggestRemediation,
+	)
+}
+
+func suggestRemediation(state session.ReadonlyState, thresholds Thresholds, goos string) SuggestRemediationResults {
+	concerns := summarizeConcerns(state, thresholds)
+	results := SuggestRemediationResults{
+		Status:      "healthy",
+		OS:          goos,
+		Advisory:    true,
+		Notice:      ADVISORY_NOTICE,
+		Remediation: []Remediation{},
+		Message:     "No concerns flagged, so no remediation is needed",
+	}
+
+	for _, c := range concerns.Concerns {
+		steps, ok := remediationSteps[c.Area][goos]
+		if !ok {
+			steps = genericSteps[c.Area]
+		}
+		results.Remediation = append(results.Remediation, Remediation{
+			Severity: c.Severity,
+			Area:     c.Area,
+			Concern:  c.Concern,
+			Steps:    steps,
+		})
+	}
+
+	if len(results.Remediation) > 0 {
+		results.Status = "suggestions"
+		results.Message = fmt.Sprintf("Suggestions for %d concern(s) on %s, most severe first", len(results.Remediation), goos)
+	}
+	return results
+}
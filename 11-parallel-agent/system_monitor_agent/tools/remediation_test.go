@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestSuggestRemediation(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		MEMORY_METRICS_KEY: metrics(
+			map[string]any{"memory_usage_percentage": 91.0, "swap_usage_percentage": 10.0},
+			map[string]any{"performance_concern": "High memory usage detected"}),
+		DISK_METRICS_KEY: metrics(
+			map[string]any{"usage_percentage": 97.0},
+			map[string]any{"disk_space_concern": "High disk usage detected"}),
+	}, false)
+
+	results := suggestRemediation(state, DefaultThresholds(), "linux")
+	if results.Status != "suggestions" || !results.Advisory || results.Notice != ADVISORY_NOTICE || results.OS != "linux" {
+		t.Fatalf("results = %+v, want advisory suggestions for linux", results)
+	}
+	if len(results.Remediation) != 2 || results.Remediation[0].Area != "disk" || results.Remediation[1].Area != "memory" {
+		t.Fatalf("remediation = %+v, want disk then memory", results.Remediation)
+	}
+	if cmd := results.Remediation[0].Steps[1].Command; cmd != "sudo apt-get clean" {
+		t.Errorf("linux disk step = %q, want the apt cache clean", cmd)
+	}
+
+	windows := suggestRemediation(state, DefaultThresholds(), "windows")
+	if cmd := windows.Remediation[1].Steps[0].Command; !strings.HasPrefix(cmd, "Get-Process") {
+		t.Errorf("windows memory step = %q, want a PowerShell command", cmd)
+	}
+
+	// An OS without specific commands gets generic advice without commands
+	other := suggestRemediation(state, DefaultThresholds(), "plan9")
+	for _, r := range other.Remediation {
+		if len(r.Steps) == 0 || r.Steps[0].Command != "" || r.Steps[0].Description == "" {
+			t.Errorf("plan9 %s steps = %+v, want a description without a command", r.Area, r.Steps)
+		}
+	}
+}
+
+func TestSuggestRemediationHealthy(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY: metrics(map[string]any{"avg_usage_percentage": 20.0}, map[string]any{}),
+	}, false)
+	results := suggestRemediation(state, DefaultThresholds(), "darwin")
+	if results.Status != "healthy" || len(results.Remediation) != 0 || !results.Advisory {
+		t.Errorf("results = %+v, want healthy with no suggestions", results)
+	}
+}
+
+func TestRemediationStepsCoverEveryArea(t *testing.T) {
+	for _, src := range concernSources {
+		if len(genericSteps[src.area]) == 0 {
+			t.Errorf("no generic steps for %s", src.area)
+		}
+		for _, goos := range []string{"linux", "darwin", "windows"} {
+			if len(remediationSteps[src.area][goos]) == 0 {
+				t.Errorf("no %s steps for %s", goos, src.area)
+			}
+		}
+	}
+}
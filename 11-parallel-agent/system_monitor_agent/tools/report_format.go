@@ -0,0 +1,259 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Report formats: prose for people reading the report, a compact markdown table, or JSON for
+// dashboards. The default comes from the environment, and set_report_format overrides it for
+// the rest of the session.
+const (
+	REPORT_FORMAT_STATE_KEY = "report_format"
+	REPORT_FORMAT_ENV       = "REPORT_FORMAT"
+
+	FORMAT_PROSE          = "prose"
+	FORMAT_MARKDOWN_TABLE = "markdown-table"
+	FORMAT_JSON           = "json"
+
+	DEFAULT_REPORT_FORMAT = FORMAT_PROSE
+)
+
+// ReportFormats are the supported report formats
+var ReportFormats = []string{FORMAT_PROSE, FORMAT_MARKDOWN_TABLE, FORMAT_JSON}
+
+// reportFormatAliases are other names users and configs use for the formats
+var reportFormatAliases = map[string]string{
+	"text":     FORMAT_PROSE,
+	"table":    FORMAT_MARKDOWN_TABLE,
+	"markdown": FORMAT_MARKDOWN_TABLE,
+	"md":       FORMAT_MARKDOWN_TABLE,
+}
+
+// ParseReportFormat returns the report format named by name, case-insensitively and accepting
+// aliases such as "table". Unknown names are an error listing the supported formats.
+func ParseReportFormat(name string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := reportFormatAliases[format]; ok {
+		format = alias
+	}
+	if !slices.Contains(ReportFormats, format) {
+		return "", fmt.Errorf("unknown report format %q: want one of %s", name, strings.Join(ReportFormats, ", "))
+	}
+	return format, nil
+}
+
+// LoadReportFormat returns the format in REPORT_FORMAT, or DEFAULT_REPORT_FORMAT when it is
+// unset. getenv is usually os.Getenv.
+func LoadReportFormat(getenv func(string) string) (string, error) {
+	val := strings.TrimSpace(getenv(REPORT_FORMAT_ENV))
+	if val == "" {
+		return DEFAULT_REPORT_FORMAT, nil
+	}
+	format, err := ParseReportFormat(val)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", REPORT_FORMAT_ENV, err)
+	}
+	return format, nil
+}
+
+// CurrentReportFormat returns the session's report format, falling back to defaultFormat when
+// set_report_format hasn't changed it or the stored value isn't a known format
+func CurrentReportFormat(state session.ReadonlyState, defaultFormat string) string {
+	val, err := state.Get(REPORT_FORMAT_STATE_KEY)
+	if err != nil {
+		return defaultFormat
+	}
+	name, _ := val.(string)
+	format, err := ParseReportFormat(name)
+	if err != nil {
+		return defaultFormat
+	}
+	return format
+}
+
+// SetReportFormatArgs represents the input arguments for setting the report format
+type SetReportFormatArgs struct {
+	Format string `json:"format"` // "prose", "markdown-table" or "json"
+}
+
+// SetReportFormatResults represents the result from setting the report format
+type SetReportFormatResults struct {
+	Status  string `json:"status"` // "success" or "invalid_format"
+	Format  string `json:"format"` // the format now applied
+	Message string `json:"message"`
+}
+
+// NewSetReportFormat creates a tool that changes the session's report format. defaultFormat is
+// the format in effect until it is called (see LoadReportFormat).
+func NewSetReportFormat(defaultFormat string) (tool.Tool, error) {
+	setReportFormat := func(ctx tool.Context, input SetReportFormatArgs) (SetReportFormatResults, error) {
+		fmt.Println("\n🔧 Tool: set_report_format called - updating report format")
+		return applyReportFormat(ctx.State(), defaultFormat, input)
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "set_report_format",
+			Description: "Set how the system health report is written: prose (default), markdown-table for a compact table, or json for dashboards and scripts.",
+		},
+		setReportFormat,
+	)
+}
+
+func applyReportFormat(state session.State, defaultFormat string, input SetReportFormatArgs) (SetReportFormatResults, error) {
+	format, err := ParseReportFormat(input.Format)
+	if err != nil {
+		current := CurrentReportFormat(state, defaultFormat)
+		return SetReportFormatResults{
+			Status:  "invalid_format",
+			Format:  current,
+			Message: fmt.Sprintf("%v. The report stays in %s format.", err, current),
+		}, nil
+	}
+
+	if err := state.Set(REPORT_FORMAT_STATE_KEY, format); err != nil {
+		return SetReportFormatResults{}, fmt.Errorf("failed to save %s to state: %w", REPORT_FORMAT_STATE_KEY, err)
+	}
+
+	fmt.Printf("   ✓ Report format: %s\n", format)
+	return SetReportFormatResults{
+		Status:  "success",
+		Format:  format,
+		Message: fmt.Sprintf("The system health report will be written as %s.", format),
+	}, nil
+}
+
+// ===== Report Rendering =====
+
+// RenderReportArgs represents the input arguments for rendering the report
+type RenderReportArgs struct{}
+
+// RenderReportResults represents the result from rendering the report
+type RenderReportResults struct {
+	Status   string `json:"status"` // "success", or "prose" when the report should be written freely
+	Format   string `json:"format"`
+	Rendered string `json:"rendered,omitempty"`
+	Message  string `json:"message"`
+}
+
+// HealthReport is the machine-readable report rendered for the json format
+type HealthReport struct {
+	Status      string                  `json:"status"` // "healthy" or "concerns"
+	GeneratedAt string                  `json:"generated_at"`
+	Metrics     map[string]MetricStatus `json:"metrics"`
+	Concerns    []Concern               `json:"concerns"`
+	Missing     []string                `json:"missing,omitempty"`
+}
+
+// MetricStatus is the usage of one area against its alert threshold
+type MetricStatus struct {
+	UsagePercentage float64 `json:"usage_percentage"`
+	Threshold       float64 `json:"threshold"`
+	Severity        string  `json:"severity,omitempty"` // set when the area has a concern
+}
+
+// NewRenderReport creates a tool that renders the collected metrics and concerns in the
+// session's report format, so tables and JSON come out the same every run instead of being
+// written by the model. thresholds and defaultFormat are the defaults for the session.
+func NewRenderReport(thresholds Thresholds, defaultFormat string) (tool.Tool, error) {
+	renderReport := func(ctx tool.Context, input RenderReportArgs) (RenderReportResults, error) {
+		fmt.Println("\n🔧 Tool: render_report called - formatting the health report")
+
+		state := ctx.State()
+		format := CurrentReportFormat(state, defaultFormat)
+		return renderHealthReport(state, CurrentThresholds(state, thresholds), format, time.Now())
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "render_report",
+			Description: "Render the collected CPU, memory, swap and disk metrics and concerns in the session's report format (markdown-table or json)",
+		},
+		renderReport,
+	)
+}
+
+func renderHealthReport(state session.ReadonlyState, thresholds Thresholds, format string, now time.Time) (RenderReportResults, error) {
+	if format == FORMAT_PROSE {
+		return RenderReportResults{
+			Status:  "prose",
+			Format:  format,
+			Message: "The report format is prose; write the report yourself.",
+		}, nil
+	}
+
+	report := buildHealthReport(state, thresholds, now)
+	var rendered string
+	switch format {
+	case FORMAT_JSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return RenderReportResults{}, fmt.Errorf("failed to encode report: %w", err)
+		}
+		rendered = string(data)
+	case FORMAT_MARKDOWN_TABLE:
+		rendered = markdownTable(report)
+	default:
+		return RenderReportResults{}, fmt.Errorf("unknown report format %q", format)
+	}
+
+	return RenderReportResults{
+		Status:   "success",
+		Format:   format,
+		Rendered: rendered,
+		Message:  fmt.Sprintf("Rendered the report as %s", format),
+	}, nil
+}
+
+// buildHealthReport collects the usage of every area with its threshold and the concerns
+func buildHealthReport(state session.ReadonlyState, thresholds Thresholds, now time.Time) HealthReport {
+	concerns := summarizeConcerns(state, thresholds)
+	report := HealthReport{
+		Status:      concerns.Status,
+		GeneratedAt: now.Format(time.RFC3339),
+		Metrics:     map[string]MetricStatus{},
+		Concerns:    concerns.Concerns,
+		Missing:     concerns.Missing,
+	}
+	for area, usage := range UsagePercentages(state) {
+		report.Metrics[area] = MetricStatus{UsagePercentage: usage, Threshold: thresholds.forArea(area)}
+	}
+	for _, c := range concerns.Concerns {
+		metric := report.Metrics[c.Area]
+		metric.Severity = c.Severity
+		report.Metrics[c.Area] = metric
+	}
+	return report
+}
+
+// markdownTable renders report as one row per area, in the order of concernSources
+func markdownTable(report HealthReport) string {
+	var b strings.Builder
+	b.WriteString("| Area | Usage | Threshold | Status |\n")
+	b.WriteString("|------|-------|-----------|--------|\n")
+	for _, src := range concernSources {
+		metric, ok := report.Metrics[src.area]
+		if !ok {
+			fmt.Fprintf(&b, "| %s | n/a | n/a | not collected |\n", src.area)
+			continue
+		}
+		status := "ok"
+		for _, c := range report.Concerns {
+			if c.Area == src.area {
+				status = fmt.Sprintf("%s: %s", c.Severity, c.Concern)
+			}
+		}
+		fmt.Fprintf(&b, "| %s | %.1f%% | %g%% | %s |\n", src.area, metric.UsagePercentage, metric.Threshold, status)
+	}
+	fmt.Fprintf(&b, "\nOverall: %s (%d concern(s)) at %s", report.Status, len(report.Concerns), report.GeneratedAt)
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestParseReportFormat(t *testing.T) {
+	tests := map[string]string{
+		"prose":          FORMAT_PROSE,
+		" JSON ":         FORMAT_JSON,
+		"markdown-table": FORMAT_MARKDOWN_TABLE,
+		"table":          FORMAT_MARKDOWN_TABLE,
+		"Markdown":       FORMAT_MARKDOWN_TABLE,
+		"text":           FORMAT_PROSE,
+		"yaml":           "",
+		"":               "",
+	}
+	for name, want := range tests {
+		got, err := ParseReportFormat(name)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("ParseReportFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestLoadReportFormat(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	if format, err := LoadReportFormat(getenv); err != nil || format != DEFAULT_REPORT_FORMAT {
+		t.Errorf("LoadReportFormat(unset) = %q, %v; want %q", format, err, DEFAULT_REPORT_FORMAT)
+	}
+	env[REPORT_FORMAT_ENV] = "table"
+	if format, err := LoadReportFormat(getenv); err != nil || format != FORMAT_MARKDOWN_TABLE {
+		t.Errorf("LoadReportFormat(table) = %q, %v; want %q", format, err, FORMAT_MARKDOWN_TABLE)
+	}
+	env[REPORT_FORMAT_ENV] = "xml"
+	if _, err := LoadReportFormat(getenv); err == nil || !strings.Contains(err.Error(), REPORT_FORMAT_ENV) {
+		t.Errorf("LoadReportFormat(xml) error = %v, want one naming %s", err, REPORT_FORMAT_ENV)
+	}
+}
+
+func TestApplyReportFormat(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+
+	results, err := applyReportFormat(state, FORMAT_PROSE, SetReportFormatArgs{Format: "JSON"})
+	if err != nil || results.Status != "success" || CurrentReportFormat(state, FORMAT_PROSE) != FORMAT_JSON {
+		t.Fatalf("applyReportFormat(JSON) = %+v, %v; want json saved", results, err)
+	}
+
+	results, err = applyReportFormat(state, FORMAT_PROSE, SetReportFormatArgs{Format: "pdf"})
+	if err != nil || results.Status != "invalid_format" || results.Format != FORMAT_JSON {
+		t.Errorf("applyReportFormat(pdf) = %+v, %v; want invalid_format keeping json", results, err)
+	}
+
+	// An unknown stored value falls back to the default
+	state.Set(REPORT_FORMAT_STATE_KEY, "pdf")
+	if got := CurrentReportFormat(state, FORMAT_MARKDOWN_TABLE); got != FORMAT_MARKDOWN_TABLE {
+		t.Errorf("CurrentReportFormat() = %q, want the default", got)
+	}
+}
+
+func TestRenderHealthReport(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	state := agenttest.NewState(map[string]any{
+		CPU_METRICS_KEY: metrics(map[string]any{"avg_usage_percentage": 42.5}, map[string]any{}),
+		DISK_METRICS_KEY: metrics(
+			map[string]any{"usage_percentage": 96.0},
+			map[string]any{"disk_space_concern": "High disk usage detected"}),
+	}, false)
+
+	results, err := renderHealthReport(state, DefaultThresholds(), FORMAT_MARKDOWN_TABLE, now)
+	if err != nil || results.Status != "success" {
+		t.Fatalf("renderHealthReport(table) = %+v, %v", results, err)
+	}
+	for _, row := range []string{
+		"| cpu | 42.5% | 80% | ok |",
+		"| memory | n/a | n/a | not collected |",
+		"| disk | 96.0% | 80% | critical: High disk usage detected |",
+		"Overall: concerns (1 concern(s)) at 2025-01-02T03:04:05Z",
+	} {
+		if !strings.Contains(results.Rendered, row) {
+			t.Errorf("table is missing %q:\n%s", row, results.Rendered)
+		}
+	}
+
+	results, err = renderHealthReport(state, DefaultThresholds(), FORMAT_JSON, now)
+	if err != nil || results.Status != "success" {
+		t.Fatalf("renderHealthReport(json) = %+v, %v", results, err)
+	}
+	var report HealthReport
+	if err := json.Unmarshal([]byte(results.Rendered), &report); err != nil {
+		t.Fatalf("rendered JSON doesn't parse: %v\n%s", err, results.Rendered)
+	}
+	if report.Status != "concerns" || report.Metrics["disk"].Severity != "critical" || report.Metrics["cpu"].Threshold != 80 ||
+		len(report.Concerns) != 1 || len(report.Missing) != 1 || report.GeneratedAt != "2025-01-02T03:04:05Z" {
+		t.Errorf("report = %+v, want the disk concern and the missing memory report", report)
+	}
+
+	if results, err := renderHealthReport(state, DefaultThresholds(), FORMAT_PROSE, now); err != nil || results.Status != "prose" || results.Rendered != "" {
+		t.Errorf("renderHealthReport(prose) = %+v, %v; want nothing rendered", results, err)
+	}
+}
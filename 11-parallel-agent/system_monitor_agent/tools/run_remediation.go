@@ -0,0 +1,332 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+const (
+	// ENABLE_REMEDIATION_ENV must be "true" for main to add run_remediation at all
+	ENABLE_REMEDIATION_ENV = "ENABLE_REMEDIATION"
+
+	CLEAN_TEMP_DIR = "clean_temp_dir"
+	// TEMP_CLEANUP_MIN_AGE keeps files that running programs may still be using
+	TEMP_CLEANUP_MIN_AGE = 24 * time.Hour
+	// MAX_CLEANUP_FILES bounds the files one cleanup deletes; run it again for the rest
+	MAX_CLEANUP_FILES = 1000
+	// CLEANUP_SAMPLE_SIZE is how many paths the result lists
+	CLEANUP_SAMPLE_SIZE = 10
+)
+
+// ===== Remediation Execution =====
+//
+// run_remediation is the only tool in this example that changes the system, so it is guarded
+// three ways:
+//  1. main only adds it when ENABLE_REMEDIATION=true
+//  2. it only runs actions on its whitelist (clean_temp_dir); anything else is refused
+//  3. it runs in dry-run mode unless dry_run is false AND a confirmation token from an earlier
+//     turn (see toolutil.Confirmer) is passed, which the agent only does after the user agreed.
+//     The token is bound to the files the dry run listed, so if they changed in between, e.g. a
+//     file aged past TEMP_CLEANUP_MIN_AGE, nothing is deleted and a new dry run is needed
+//
+// The only action is clean_temp_dir: it deletes regular files in the OS temp directory that
+// haven't been modified for TEMP_CLEANUP_MIN_AGE. Symlinks and directories are never removed.
+
+// remediationAction is one whitelisted action. plan lists what it would do; apply does it.
+type remediationAction struct {
+	description string
+	plan        func(now time.Time) (cleanupPlan, error)
+	apply       func(plan cleanupPlan, now time.Time) cleanupOutcome
+}
+
+// cleanupPlan is the files a cleanup would delete
+type cleanupPlan struct {
+	dir   string
+	files []cleanupFile
+	bytes int64
+}
+
+// scope identifies the planned files for a confirmation token: a hash of the directory and the
+// sorted paths, so a token only deletes the files its dry run reported
+func (p cleanupPlan) scope() string {
+	h := sha256.New()
+	h.Write([]byte(p.dir))
+	for _, f := range p.files {
+		h.Write([]byte{0})
+		h.Write([]byte(f.path))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type cleanupFile struct {
+	path string
+	size int64
+}
+
+// cleanupOutcome is what a cleanup did
+type cleanupOutcome struct {
+	removed    []string
+	freedBytes int64
+	failed     []string
+}
+
+// RunRemediationArgs represents the input arguments for running a remediation
+type RunRemediationArgs struct {
+	Action            string `json:"action"`
+	DryRun            *bool  `json:"dry_run,omitempty"` // true unless explicitly false
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// RunRemediationResults represents the result from running a remediation
+type RunRemediationResults struct {
+	Action string `json:"action"`
+	// "dry_run", "confirmation_required", "success", "refused", "invalid_token", "expired",
+	// "changed", "not_confirmed" or "error"
+	Status            string   `json:"status"`
+	DryRun            bool     `json:"dry_run"`
+	Directory         string   `json:"directory,omitempty"`
+	Files             int      `json:"files"`
+	Bytes             int64    `json:"bytes"`
+	Sample            []string `json:"sample,omitempty"` // up to CLEANUP_SAMPLE_SIZE affected paths
+	Failed            []string `json:"failed,omitempty"`
+	ConfirmationToken string   `json:"confirmation_token,omitempty"`
+	ExpiresInSeconds  int      `json:"expires_in_seconds,omitempty"`
+	AllowedActions    []string `json:"allowed_actions,omitempty"`
+	Message           string   `json:"message"`
+}
+
+// remediationRunner runs the whitelisted actions
+type remediationRunner struct {
+	actions map[string]remediationAction
+	confirm *toolutil.Confirmer
+	now     func() time.Time
+}
+
+// NewRunRemediation creates a tool that runs a whitelisted remediation after the user confirms.
+// Only add it when ENABLE_REMEDIATION is true.
+func NewRunRemediation() (tool.Tool, error) {
+	r := &remediationRunner{
+		actions: map[string]remediationAction{
+			CLEAN_TEMP_DIR: tempDirCleanup(os.TempDir(), TEMP_CLEANUP_MIN_AGE),
+		},
+		confirm: toolutil.NewConfirmer(toolutil.DEFAULT_CONFIRMATION_TTL),
+		now:     time.Now,
+	}
+
+	runRemediation := func(ctx tool.Context, input RunRemediationArgs) (RunRemediationResults, error) {
+		fmt.Printf("\n🔧 Tool: run_remediation called - %s (dry run: %v)\n", input.Action, input.DryRun == nil || *input.DryRun)
+		results, err := r.run(ctx.State(), ctx.InvocationID(), input)
+		if err == nil {
+			fmt.Printf("   ✓ %s: %s\n", results.Status, results.Message)
+		}
+		return results, err
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name: "run_remediation",
+			Description: "Run a whitelisted remediation (only clean_temp_dir: delete files older than 24 hours from the temp directory). " +
+				"It is a dry run unless dry_run is false and a confirmation_token from an earlier call is passed after the user confirms.",
+		},
+		runRemediation,
+	)
+}
+
+func (r *remediationRunner) run(state session.State, invocationID string, input RunRemediationArgs) (RunRemediationResults, error) {
+	name := strings.TrimSpace(input.Action)
+	action, ok := r.actions[name]
+	if !ok {
+		return RunRemediationResults{
+			Action:         name,
+			Status:         "refused",
+			DryRun:         true,
+			AllowedActions: r.allowedActions(),
+			Message:        fmt.Sprintf("%q is not an allowed remediation; nothing was run. Allowed: %s", name, strings.Join(r.allowedActions(), ", ")),
+		}, nil
+	}
+
+	now := r.now()
+	plan, err := action.plan(now)
+	if err != nil {
+		return RunRemediationResults{Action: name, Status: "error", DryRun: true, Message: fmt.Sprintf("Nothing was run: %v", err)}, nil
+	}
+	results := RunRemediationResults{
+		Action:    name,
+		DryRun:    true,
+		Directory: plan.dir,
+		Files:     len(plan.files),
+		Bytes:     plan.bytes,
+		Sample:    samplePaths(plan.files),
+	}
+
+	dryRun := input.DryRun == nil || *input.DryRun
+	token := strings.TrimSpace(input.ConfirmationToken)
+	if dryRun || token == "" {
+		confirmation, err := r.confirm.Request(state, invocationID, name, plan.scope())
+		if err != nil {
+			return RunRemediationResults{}, err
+		}
+		results.Status = "dry_run"
+		if !dryRun {
+			results.Status = "confirmation_required"
+		}
+		results.ConfirmationToken = confirmation.Token
+		results.ExpiresInSeconds = int(r.confirm.TTL.Seconds())
+		results.Message = fmt.Sprintf("Dry run, nothing was changed: %s would affect %d file(s), %s. To run it, show this to the user "+
+			"and only after they confirm call run_remediation with dry_run=false and this confirmation_token.",
+			action.description, len(plan.files), formatBytes(plan.bytes))
+		return results, nil
+	}
+
+	if err := r.confirm.Confirm(state, invocationID, name, plan.scope(), token); err != nil {
+		status := "invalid_token"
+		switch {
+		case errors.Is(err, toolutil.ErrConfirmationExpired):
+			status = "expired"
+		case errors.Is(err, toolutil.ErrConfirmationChanged):
+			status = "changed"
+		case errors.Is(err, toolutil.ErrConfirmationSameInvocation):
+			status = "not_confirmed"
+		case !errors.Is(err, toolutil.ErrConfirmationInvalid):
+			return RunRemediationResults{}, err
+		}
+		results.Status = status
+		results.Message = fmt.Sprintf("Nothing was run: %v. Call run_remediation without a token for a new dry run.", err)
+		return results, nil
+	}
+
+	outcome := action.apply(plan, now)
+	results.Status = "success"
+	results.DryRun = false
+	results.Files = len(outcome.removed)
+	results.Bytes = outcome.freedBytes
+	results.Sample = outcome.removed[:min(len(outcome.removed), CLEANUP_SAMPLE_SIZE)]
+	results.Failed = outcome.failed
+	results.Message = fmt.Sprintf("%s: deleted %d file(s), freeing %s", action.description, len(outcome.removed), formatBytes(outcome.freedBytes))
+	if len(outcome.failed) > 0 {
+		results.Message += fmt.Sprintf("; %d file(s) could not be deleted", len(outcome.failed))
+	}
+	return results, nil
+}
+
+func (r *remediationRunner) allowedActions() []string {
+	names := make([]string, 0, len(r.actions))
+	for name := range r.actions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ===== Temp Directory Cleanup =====
+
+// tempDirCleanup deletes regular files under dir last modified more than minAge ago
+func tempDirCleanup(dir string, minAge time.Duration) remediationAction {
+	return remediationAction{
+		description: fmt.Sprintf("Delete files older than %s from %s", minAge, dir),
+		plan: func(now time.Time) (cleanupPlan, error) {
+			return planTempCleanup(dir, minAge, now)
+		},
+		apply: func(plan cleanupPlan, now time.Time) cleanupOutcome {
+			return applyTempCleanup(plan, minAge, now)
+		},
+	}
+}
+
+// planTempCleanup lists up to MAX_CLEANUP_FILES old regular files under dir, sorted by path.
+// Unreadable directories and symlinks are skipped.
+func planTempCleanup(dir string, minAge time.Duration, now time.Time) (cleanupPlan, error) {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return cleanupPlan{}, fmt.Errorf("failed to read temp directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return cleanupPlan{}, fmt.Errorf("temp directory %s is not a directory", dir)
+	}
+
+	plan := cleanupPlan{dir: dir}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip what can't be read, e.g. other users' directories
+			if d != nil && d.IsDir() && path != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if len(plan.files) >= MAX_CLEANUP_FILES {
+			return fs.SkipAll
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || now.Sub(info.ModTime()) < minAge {
+			return nil
+		}
+		plan.files = append(plan.files, cleanupFile{path: path, size: info.Size()})
+		plan.bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return cleanupPlan{}, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	sort.Slice(plan.files, func(i, j int) bool { return plan.files[i].path < plan.files[j].path })
+	return plan, nil
+}
+
+// applyTempCleanup deletes the planned files, checking each again first: a file that became a
+// symlink or was modified since the plan is kept
+func applyTempCleanup(plan cleanupPlan, minAge time.Duration, now time.Time) cleanupOutcome {
+	var outcome cleanupOutcome
+	for _, f := range plan.files {
+		info, err := os.Lstat(f.path)
+		if err != nil || !info.Mode().IsRegular() || now.Sub(info.ModTime()) < minAge {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			outcome.failed = append(outcome.failed, f.path)
+			continue
+		}
+		outcome.removed = append(outcome.removed, f.path)
+		outcome.freedBytes += info.Size()
+	}
+	return outcome
+}
+
+func samplePaths(files []cleanupFile) []string {
+	sample := make([]string, 0, min(len(files), CLEANUP_SAMPLE_SIZE))
+	for _, f := range files[:min(len(files), CLEANUP_SAMPLE_SIZE)] {
+		sample = append(sample, f.path)
+	}
+	return sample
+}
+
+// formatBytes renders a size like "12.3 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// newTestRunner returns a runner cleaning dir, and the files it creates there: two old files,
+// one of them nested, a fresh file and a symlink to an old file outside dir
+func newTestRunner(t *testing.T) (*remediationRunner, map[string]string) {
+	t.Helper()
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	dir, outside := t.TempDir(), t.TempDir()
+
+	files := map[string]string{
+		"old":     filepath.Join(dir, "old.tmp"),
+		"nested":  filepath.Join(dir, "cache", "old.bin"),
+		"fresh":   filepath.Join(dir, "fresh.tmp"),
+		"outside": filepath.Join(outside, "keep.txt"),
+		"link":    filepath.Join(dir, "link.tmp"),
+	}
+	for name, path := range files {
+		if name == "link" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(-48 * time.Hour)
+		if name == "fresh" {
+			modTime = now.Add(-time.Hour)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(files["outside"], files["link"]); err != nil {
+		t.Fatal(err)
+	}
+
+	return &remediationRunner{
+		actions: map[string]remediationAction{CLEAN_TEMP_DIR: tempDirCleanup(dir, TEMP_CLEANUP_MIN_AGE)},
+		confirm: &toolutil.Confirmer{TTL: time.Minute, Now: func() time.Time { return now }},
+		now:     func() time.Time { return now },
+	}, files
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func TestRunRemediationDryRunByDefault(t *testing.T) {
+	r, files := newTestRunner(t)
+	state := agenttest.NewState(nil, false)
+
+	results, err := r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR})
+	if err != nil || results.Status != "dry_run" || !results.DryRun || results.ConfirmationToken == "" {
+		t.Fatalf("run() = %+v, %v; want a dry run with a token", results, err)
+	}
+	if results.Files != 2 || results.Bytes != 200 || len(results.Sample) != 2 {
+		t.Errorf("dry run = %+v, want the 2 old files", results)
+	}
+
+	// A token alone doesn't run it; dry_run has to be false too
+	results, _ = r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR, ConfirmationToken: results.ConfirmationToken})
+	if results.Status != "dry_run" {
+		t.Errorf("run() with a token = %+v, want another dry run", results)
+	}
+
+	// dry_run=false without a token needs a confirmation first
+	dryRun := false
+	results, _ = r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR, DryRun: &dryRun})
+	if results.Status != "confirmation_required" || results.ConfirmationToken == "" {
+		t.Errorf("run(dry_run=false) = %+v, want confirmation_required", results)
+	}
+
+	for name, path := range files {
+		if !exists(path) {
+			t.Errorf("%s was deleted without confirmation", name)
+		}
+	}
+}
+
+func TestRunRemediationAfterConfirmation(t *testing.T) {
+	r, files := newTestRunner(t)
+	state := agenttest.NewState(nil, false)
+
+	preview, _ := r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR})
+	dryRun := false
+	results, err := r.run(state, "inv-2", RunRemediationArgs{Action: CLEAN_TEMP_DIR, DryRun: &dryRun, ConfirmationToken: preview.ConfirmationToken})
+	if err != nil || results.Status != "success" || results.DryRun || results.Files != 2 || results.Bytes != 200 {
+		t.Fatalf("run() = %+v, %v; want the 2 old files deleted", results, err)
+	}
+
+	for name, want := range map[string]bool{"old": false, "nested": false, "fresh": true, "outside": true, "link": true} {
+		if exists(files[name]) != want {
+			t.Errorf("%s exists = %v, want %v", name, !want, want)
+		}
+	}
+
+	// The token works once
+	results, _ = r.run(state, "inv-3", RunRemediationArgs{Action: CLEAN_TEMP_DIR, DryRun: &dryRun, ConfirmationToken: preview.ConfirmationToken})
+	if results.Status != "invalid_token" {
+		t.Errorf("run() with a used token = %+v, want invalid_token", results)
+	}
+}
+
+func TestRunRemediationRefusesUnconfirmedTokens(t *testing.T) {
+	r, files := newTestRunner(t)
+	dryRun := false
+
+	// A token can't be used in the turn that asked for it, before the user answered
+	state := agenttest.NewState(nil, false)
+	preview, _ := r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR})
+	results, err := r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR, DryRun: &dryRun, ConfirmationToken: preview.ConfirmationToken})
+	if err != nil || results.Status != "not_confirmed" {
+		t.Errorf("run() in the same invocation = %+v, %v; want not_confirmed", results, err)
+	}
+
+	// A token only deletes the files its dry run listed
+	state = agenttest.NewState(nil, false)
+	preview, _ = r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR})
+	old := r.now().Add(-48 * time.Hour)
+	if err := os.Chtimes(files["fresh"], old, old); err != nil {
+		t.Fatal(err)
+	}
+	results, err = r.run(state, "inv-2", RunRemediationArgs{Action: CLEAN_TEMP_DIR, DryRun: &dryRun, ConfirmationToken: preview.ConfirmationToken})
+	if err != nil || results.Status != "changed" {
+		t.Errorf("run() after the file set changed = %+v, %v; want changed", results, err)
+	}
+
+	for name, path := range files {
+		if !exists(path) {
+			t.Errorf("%s was deleted without a valid confirmation", name)
+		}
+	}
+}
+
+func TestRunRemediationRefusesOtherActions(t *testing.T) {
+	r, _ := newTestRunner(t)
+	state := agenttest.NewState(nil, false)
+	preview, _ := r.run(state, "inv-1", RunRemediationArgs{Action: CLEAN_TEMP_DIR})
+
+	dryRun := false
+	for _, action := range []string{"rm -rf /", "kill_process", ""} {
+		results, err := r.run(state, "inv-2", RunRemediationArgs{Action: action, DryRun: &dryRun, ConfirmationToken: preview.ConfirmationToken})
+		if err != nil || results.Status != "refused" || len(results.AllowedActions) != 1 || results.AllowedActions[0] != CLEAN_TEMP_DIR {
+			t.Errorf("run(%q) = %+v, %v; want refused", action, results, err)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KB", 5 * 1024 * 1024: "5.0 MB"}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
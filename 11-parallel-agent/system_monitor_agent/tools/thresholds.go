@@ -0,0 +1,179 @@
+// Package tools implements real system information gathering tools using gopsutil.
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Alert thresholds: usage above them is flagged as a concern by the info tools. Defaults come
+// from the environment, and set_thresholds overrides them for the rest of the session.
+const (
+	THRESHOLDS_STATE_KEY    = "alert_thresholds"
+	CPU_THRESHOLD_ENV       = "CPU_ALERT_THRESHOLD"
+	MEMORY_THRESHOLD_ENV    = "MEMORY_ALERT_THRESHOLD" // also used for swap
+	DISK_THRESHOLD_ENV      = "DISK_ALERT_THRESHOLD"
+	DEFAULT_ALERT_THRESHOLD = 80.0
+)
+
+// Thresholds are usage percentages (0-100) above which a concern is flagged
+type Thresholds struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+	Disk   float64 `json:"disk"`
+}
+
+// DefaultThresholds returns DEFAULT_ALERT_THRESHOLD for every area
+func DefaultThresholds() Thresholds {
+	return Thresholds{CPU: DEFAULT_ALERT_THRESHOLD, Memory: DEFAULT_ALERT_THRESHOLD, Disk: DEFAULT_ALERT_THRESHOLD}
+}
+
+// LoadThresholds returns defaults with CPU_ALERT_THRESHOLD, MEMORY_ALERT_THRESHOLD and
+// DISK_ALERT_THRESHOLD, if set, replacing the matching field. getenv is usually os.Getenv.
+func LoadThresholds(getenv func(string) string, defaults Thresholds) (Thresholds, error) {
+	thresholds := defaults
+	for env, field := range map[string]*float64{
+		CPU_THRESHOLD_ENV:    &thresholds.CPU,
+		MEMORY_THRESHOLD_ENV: &thresholds.Memory,
+		DISK_THRESHOLD_ENV:   &thresholds.Disk,
+	} {
+		val := strings.TrimSpace(getenv(env))
+		if val == "" {
+			continue
+		}
+		percent, err := strconv.ParseFloat(val, 64)
+		if err != nil || !validThreshold(percent) {
+			return Thresholds{}, fmt.Errorf("invalid %s %q: want a percentage between 0 and 100", env, val)
+		}
+		*field = percent
+	}
+	return thresholds, nil
+}
+
+// SetThresholdsArgs represents the input arguments for setting thresholds; omitted areas keep
+// their current threshold
+type SetThresholdsArgs struct {
+	CPU    *float64 `json:"cpu,omitempty"`
+	Memory *float64 `json:"memory,omitempty"`
+	Disk   *float64 `json:"disk,omitempty"`
+}
+
+// SetThresholdsResults represents the result from setting thresholds
+type SetThresholdsResults struct {
+	Status     string     `json:"status"` // "success", "unchanged" or "invalid_threshold"
+	Thresholds Thresholds `json:"thresholds"`
+	Message    string     `json:"message"`
+}
+
+// NewSetThresholds creates a tool that changes the session's alert thresholds. defaults are
+// the thresholds in effect until it is called (see LoadThresholds).
+func NewSetThresholds(defaults Thresholds) (tool.Tool, error) {
+	setThresholds := func(ctx tool.Context, input SetThresholdsArgs) (SetThresholdsResults, error) {
+		fmt.Println("\n🔧 Tool: set_thresholds called - updating alert thresholds")
+		return applyThresholds(ctx.State(), defaults, input)
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "set_thresholds",
+			Description: "Set the CPU, memory (also used for swap) and disk usage percentages above which concerns are flagged. Omitted values stay as they are; call with no values to see the current thresholds.",
+		},
+		setThresholds,
+	)
+}
+
+func applyThresholds(state session.State, defaults Thresholds, input SetThresholdsArgs) (SetThresholdsResults, error) {
+	thresholds := CurrentThresholds(state, defaults)
+
+	// A fixed order keeps the message the same from call to call
+	var invalid []string
+	for _, given := range []struct {
+		name string
+		val  *float64
+	}{{"cpu", input.CPU}, {"memory", input.Memory}, {"disk", input.Disk}} {
+		if given.val != nil && !validThreshold(*given.val) {
+			invalid = append(invalid, fmt.Sprintf("%s=%g", given.name, *given.val))
+		}
+	}
+	if len(invalid) > 0 {
+		return SetThresholdsResults{
+			Status:     "invalid_threshold",
+			Thresholds: thresholds,
+			Message:    fmt.Sprintf("Thresholds must be between 0 and 100, got %s. Nothing was changed.", strings.Join(invalid, ", ")),
+		}, nil
+	}
+
+	if input.CPU == nil && input.Memory == nil && input.Disk == nil {
+		return SetThresholdsResults{
+			Status:     "unchanged",
+			Thresholds: thresholds,
+			Message:    "No thresholds given; these are the current ones.",
+		}, nil
+	}
+
+	if input.CPU != nil {
+		thresholds.CPU = *input.CPU
+	}
+	if input.Memory != nil {
+		thresholds.Memory = *input.Memory
+	}
+	if input.Disk != nil {
+		thresholds.Disk = *input.Disk
+	}
+	err := state.Set(THRESHOLDS_STATE_KEY, map[string]any{
+		"cpu":    thresholds.CPU,
+		"memory": thresholds.Memory,
+		"disk":   thresholds.Disk,
+	})
+	if err != nil {
+		return SetThresholdsResults{}, fmt.Errorf("failed to save %s to state: %w", THRESHOLDS_STATE_KEY, err)
+	}
+
+	fmt.Printf("   ✓ Thresholds: CPU %.0f%%, memory %.0f%%, disk %.0f%%\n", thresholds.CPU, thresholds.Memory, thresholds.Disk)
+	return SetThresholdsResults{
+		Status:     "success",
+		Thresholds: thresholds,
+		Message: fmt.Sprintf("Concerns are now flagged above %g%% CPU, %g%% memory and swap, and %g%% disk usage.",
+			thresholds.CPU, thresholds.Memory, thresholds.Disk),
+	}, nil
+}
+
+// CurrentThresholds returns the session's thresholds, falling back to defaults for any area
+// set_thresholds hasn't changed
+func CurrentThresholds(state session.ReadonlyState, defaults Thresholds) Thresholds {
+	thresholds := defaults
+	val, err := state.Get(THRESHOLDS_STATE_KEY)
+	if err != nil {
+		return thresholds
+	}
+	saved, ok := val.(map[string]any)
+	if !ok {
+		return thresholds
+	}
+	for name, field := range map[string]*float64{"cpu": &thresholds.CPU, "memory": &thresholds.Memory, "disk": &thresholds.Disk} {
+		if percent, ok := saved[name].(float64); ok && validThreshold(percent) {
+			*field = percent
+		}
+	}
+	return thresholds
+}
+
+// forArea returns the threshold of a concerns_summary area; swap shares the memory threshold
+func (t Thresholds) forArea(area string) float64 {
+	switch area {
+	case "cpu":
+		return t.CPU
+	case "disk":
+		return t.Disk
+	}
+	return t.Memory
+}
+
+func validThreshold(percent float64) bool {
+	return percent >= 0 && percent <= 100
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestLoadThresholds(t *testing.T) {
+	env := map[string]string{CPU_THRESHOLD_ENV: "90", DISK_THRESHOLD_ENV: " 70.5 "}
+	thresholds, err := LoadThresholds(func(key string) string { return env[key] }, DefaultThresholds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Thresholds{CPU: 90, Memory: DEFAULT_ALERT_THRESHOLD, Disk: 70.5}); thresholds != want {
+		t.Errorf("thresholds = %+v, want %+v", thresholds, want)
+	}
+
+	for _, val := range []string{"101", "-1", "high", "NaN"} {
+		if _, err := LoadThresholds(func(key string) string {
+			if key == MEMORY_THRESHOLD_ENV {
+				return val
+			}
+			return ""
+		}, DefaultThresholds()); err == nil {
+			t.Errorf("%s=%q: expected an error", MEMORY_THRESHOLD_ENV, val)
+		}
+	}
+}
+
+func TestApplyThresholds(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	defaults := DefaultThresholds()
+	cpu := 90.0
+
+	results, err := applyThresholds(state, defaults, SetThresholdsArgs{CPU: &cpu})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Thresholds{CPU: 90, Memory: 80, Disk: 80}); results.Status != "success" || results.Thresholds != want {
+		t.Errorf("results = %+v, want success with %+v", results, want)
+	}
+
+	// Thresholds come back as float64 in a plain map after a database round trip as well
+	data, _ := json.Marshal(state.Value(THRESHOLDS_STATE_KEY))
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := CurrentThresholds(agenttest.NewState(map[string]any{THRESHOLDS_STATE_KEY: saved}, false), defaults); got != results.Thresholds {
+		t.Errorf("thresholds after round trip = %+v, want %+v", got, results.Thresholds)
+	}
+
+	disk := 120.0
+	results, _ = applyThresholds(state, defaults, SetThresholdsArgs{Disk: &disk})
+	if results.Status != "invalid_threshold" || CurrentThresholds(state, defaults).Disk != 80 {
+		t.Errorf("results = %+v, want invalid_threshold and the disk threshold unchanged", results)
+	}
+
+	// Every invalid value is listed, always in the same order
+	cpu, memory := -1.0, 101.0
+	results, _ = applyThresholds(state, defaults, SetThresholdsArgs{CPU: &cpu, Memory: &memory, Disk: &disk})
+	if !strings.Contains(results.Message, "cpu=-1, memory=101, disk=120") {
+		t.Errorf("message = %q, want cpu, memory and disk listed in order", results.Message)
+	}
+
+	results, _ = applyThresholds(state, defaults, SetThresholdsArgs{})
+	if results.Status != "unchanged" || results.Thresholds.CPU != 90 {
+		t.Errorf("results = %+v, want unchanged with the current thresholds", results)
+	}
+}
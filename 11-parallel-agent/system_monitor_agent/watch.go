@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/11-parallel-agent/system_monitor_agent/tools"
+)
+
+// ===== Watch Mode =====
+//
+// With -watch <interval> the monitor runs the workflow on a timer instead of launching the
+// web UI or console, printing the health report and the trend of each usage metric every
+// cycle until Ctrl-C. Cycles never overlap: the next one starts after the previous finishes,
+// and a cycle that runs past the interval makes the missed ticks collapse into one.
+
+const (
+	WATCH_APP_NAME     = "system_monitor_watch"
+	WATCH_USER_ID      = "watch"
+	WATCH_PROMPT       = "Check my system health"
+	WATCH_HISTORY_SIZE = 10 // cycles kept for the trend table
+	// MIN_WATCH_INTERVAL leaves room for the 1-second CPU sampling and the model calls of a cycle
+	MIN_WATCH_INTERVAL = 10 * time.Second
+	// TREND_THRESHOLD is the change in percentage points shown as rising or falling
+	TREND_THRESHOLD = 1.0
+)
+
+// watchAreas are the usage metrics in the trend table, in display order
+var watchAreas = []string{"cpu", "memory", "swap", "disk"}
+
+// metricsSample is the usage percentages of one cycle; areas whose metrics weren't collected are absent
+type metricsSample struct {
+	at    time.Time
+	usage map[string]float64
+}
+
+// metricsHistory keeps the most recent samples, oldest first
+type metricsHistory struct {
+	samples []metricsSample
+	size    int
+}
+
+func (h *metricsHistory) add(s metricsSample) {
+	h.samples = append(h.samples, s)
+	if len(h.samples) > h.size {
+		h.samples = h.samples[len(h.samples)-h.size:]
+	}
+}
+
+// runWatch runs monitor every interval until ctx is canceled or the process gets Ctrl-C
+func runWatch(ctx context.Context, monitor agent.Agent, interval time.Duration) error {
+	if interval < MIN_WATCH_INTERVAL {
+		return fmt.Errorf("watch interval %s is too short, use at least %s", interval, MIN_WATCH_INTERVAL)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        WATCH_APP_NAME,
+		Agent:          monitor,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	fmt.Printf("\n👀 Watching system health every %s (Ctrl-C to stop)\n", interval)
+
+	history := &metricsHistory{size: WATCH_HISTORY_SIZE}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for cycle := 1; ; cycle++ {
+		sample, report, err := runWatchCycle(ctx, r, sessionService)
+		switch {
+		case ctx.Err() != nil:
+			fmt.Println("\n🛑 Watch stopped")
+			return nil
+		case err != nil:
+			fmt.Printf("\n❌ Cycle %d failed: %v\n", cycle, err)
+		default:
+			history.add(sample)
+			printWatchCycle(cycle, report, history)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("\n🛑 Watch stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWatchCycle runs the workflow once in a new session, so no report or metric is carried
+// over from the previous cycle. The cycle gets its own context, canceled when it returns,
+// which also stops anything the cycle left running (e.g. a timed-out disk scan).
+func runWatchCycle(parent context.Context, r *runner.Runner, sessionService session.Service) (metricsSample, string, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	sessionID := uuid.New().String()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   WATCH_APP_NAME,
+		UserID:    WATCH_USER_ID,
+		SessionID: sessionID,
+	}); err != nil {
+		return metricsSample{}, "", fmt.Errorf("failed to create session: %w", err)
+	}
+	// The session is only needed for this cycle; drop it so a long watch doesn't grow memory
+	defer sessionService.Delete(context.WithoutCancel(ctx), &session.DeleteRequest{
+		AppName:   WATCH_APP_NAME,
+		UserID:    WATCH_USER_ID,
+		SessionID: sessionID,
+	})
+
+	msg := genai.NewContentFromText(WATCH_PROMPT, genai.RoleUser)
+	for _, err := range r.Run(ctx, WATCH_USER_ID, sessionID, msg, agent.RunConfig{}) {
+		if err != nil {
+			return metricsSample{}, "", fmt.Errorf("agent run failed: %w", err)
+		}
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{
+		AppName:   WATCH_APP_NAME,
+		UserID:    WATCH_USER_ID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return metricsSample{}, "", fmt.Errorf("failed to get session: %w", err)
+	}
+	state := resp.Session.State()
+
+	report, _ := state.Get("system_health_report")
+	reportText, _ := report.(string)
+	if reportText == "" {
+		return metricsSample{}, "", errors.New("the synthesizer produced no health report")
+	}
+	// The remediation advice is printed with the report when the advisor produced any
+	advice, _ := state.Get(tools.REMEDIATION_ADVICE_KEY)
+	if adviceText, _ := advice.(string); strings.TrimSpace(adviceText) != "" {
+		reportText = strings.TrimSpace(reportText) + "\n\n🛠️  Remediation advice:\n" + strings.TrimSpace(adviceText)
+	}
+	return metricsSample{at: time.Now(), usage: tools.UsagePercentages(state)}, reportText, nil
+}
+
+func printWatchCycle(cycle int, report string, history *metricsHistory) {
+	latest := history.samples[len(history.samples)-1]
+	fmt.Printf("\n==================== Cycle %d · %s ====================\n", cycle, latest.at.Format(time.TimeOnly))
+	fmt.Println(strings.TrimSpace(report))
+	fmt.Println()
+	fmt.Print(formatTrends(history))
+}
+
+// formatTrends renders the latest usage of each area with its change since the previous
+// cycle and the range over the kept history
+func formatTrends(history *metricsHistory) string {
+	if len(history.samples) == 0 {
+		return ""
+	}
+	latest := history.samples[len(history.samples)-1]
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📈 Trends (last %d cycle(s)):\n", len(history.samples))
+	for _, area := range watchAreas {
+		current, ok := latest.usage[area]
+		if !ok {
+			fmt.Fprintf(&sb, "   %-7s not collected\n", area)
+			continue
+		}
+
+		low, high := current, current
+		for _, s := range history.samples {
+			if v, ok := s.usage[area]; ok {
+				low, high = min(low, v), max(high, v)
+			}
+		}
+
+		change := "first sample"
+		if len(history.samples) > 1 {
+			if prev, ok := history.samples[len(history.samples)-2].usage[area]; ok {
+				change = fmt.Sprintf("%s %+.1f", trendArrow(current-prev), current-prev)
+			}
+		}
+		fmt.Fprintf(&sb, "   %-7s %5.1f%%  %-14s range %.1f–%.1f%%\n", area, current, change, low, high)
+	}
+	return sb.String()
+}
+
+func trendArrow(delta float64) string {
+	switch {
+	case delta >= TREND_THRESHOLD:
+		return "↑"
+	case delta <= -TREND_THRESHOLD:
+		return "↓"
+	}
+	return "→"
+}
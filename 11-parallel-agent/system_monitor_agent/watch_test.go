@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsHistoryKeepsLatest(t *testing.T) {
+	h := &metricsHistory{size: 3}
+	for i := range 5 {
+		h.add(metricsSample{usage: map[string]float64{"cpu": float64(i)}})
+	}
+	if len(h.samples) != 3 || h.samples[0].usage["cpu"] != 2 || h.samples[2].usage["cpu"] != 4 {
+		t.Errorf("samples = %+v, want the last 3", h.samples)
+	}
+}
+
+func TestFormatTrends(t *testing.T) {
+	h := &metricsHistory{size: WATCH_HISTORY_SIZE}
+	now := time.Now()
+	h.add(metricsSample{at: now, usage: map[string]float64{"cpu": 40, "memory": 60, "disk": 70}})
+	h.add(metricsSample{at: now.Add(time.Minute), usage: map[string]float64{"cpu": 55, "memory": 60.2, "disk": 65}})
+
+	out := formatTrends(h)
+	for _, want := range []string{"last 2 cycle(s)", "↑ +15.0", "→ +0.2", "↓ -5.0", "range 40.0–55.0%", "swap    not collected"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trends missing %q:\n%s", want, out)
+		}
+	}
+}
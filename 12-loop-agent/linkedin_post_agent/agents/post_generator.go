@@ -8,12 +8,14 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 // NewInitialPostGenerator creates an agent that generates the initial draft of a LinkedIn post.
 // This agent runs first in the sequential pipeline to create the starting content.
-func NewInitialPostGenerator(ctx context.Context, model model.LLM) (agent.Agent, error) {
-	initialPostGenerator, err := llmagent.New(llmagent.Config{
+func NewInitialPostGenerator(ctx context.Context, model model.LLM, limit agentutil.ModelRateLimit) (agent.Agent, error) {
+	initialPostGenerator, err := llmagent.New(agentutil.WithRateLimit(llmagent.Config{
 		Name:        "InitialPostGenerator",
 		Model:       model,
 		Description: "Generates the initial draft of a LinkedIn post about Agent Development Kit",
@@ -42,7 +44,7 @@ Create a comprehensive, engaging LinkedIn post that the refinement loop can late
 
 Store your initial post draft in state with the key "current_post".`,
 		OutputKey: "current_post",
-	})
+	}, limit))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create initial post generator: %w", err)
 	}
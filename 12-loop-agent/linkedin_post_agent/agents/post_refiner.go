@@ -8,12 +8,14 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 // NewPostRefiner creates an agent that refines LinkedIn posts based on reviewer feedback.
 // This agent improves the post content in each iteration of the loop.
-func NewPostRefiner(ctx context.Context, model model.LLM) (agent.Agent, error) {
-	postRefiner, err := llmagent.New(llmagent.Config{
+func NewPostRefiner(ctx context.Context, model model.LLM, limit agentutil.ModelRateLimit) (agent.Agent, error) {
+	postRefiner, err := llmagent.New(agentutil.WithRateLimit(llmagent.Config{
 		Name:        "PostRefiner",
 		Model:       model,
 		Description: "Refines LinkedIn posts based on reviewer feedback to improve quality",
@@ -52,7 +54,7 @@ Create an improved version of the LinkedIn post that addresses all the feedback
 
 Store your refined post in state with the key "current_post" (overwriting the previous version).`,
 		OutputKey: "current_post", // This overwrites the previous version
-	})
+	}, limit))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post refiner agent: %w", err)
 	}
@@ -11,23 +11,35 @@ import (
 	"google.golang.org/adk/tool"
 
 	"github.com/muchlist/agent-dev-kit/12-loop-agent/linkedin_post_agent/tools"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolregistry"
 )
 
 // NewPostReviewer creates an agent that reviews LinkedIn posts for quality and can exit the loop.
 // This agent evaluates posts against quality criteria and calls exit_loop when requirements are met.
-func NewPostReviewer(ctx context.Context, model model.LLM) (agent.Agent, error) {
+func NewPostReviewer(ctx context.Context, model model.LLM, limit agentutil.ModelRateLimit) (agent.Agent, error) {
 	// Create the tools for the post reviewer
 	charCounterTool, err := tools.NewCharacterCounter()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create character counter tool: %w", err)
 	}
 
+	readabilityTool, err := tools.NewReadabilityTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create readability tool: %w", err)
+	}
+
+	readingTimeTool, err := toolregistry.Get(toolregistry.READING_TIME)
+	if err != nil {
+		return nil, err
+	}
+
 	exitLoopTool, err := tools.NewExitLoop()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create exit loop tool: %w", err)
 	}
 
-	postReviewer, err := llmagent.New(llmagent.Config{
+	postReviewer, err := llmagent.New(agentutil.WithRateLimit(llmagent.Config{
 		Name:        "PostReviewer",
 		Model:       model,
 		Description: "Reviews post quality and provides feedback or exits loop when requirements are met",
@@ -42,7 +54,14 @@ Your task is to evaluate the quality of a LinkedIn post about Agent Development
 2. If the length check fails (tool result is "fail"), provide specific feedback on what needs to be fixed.
    Use the tool's message as a guideline, but add your own professional critique.
 
-3. If length check passes, evaluate the post against these criteria:
+3. If the length check passes, use the readability_score tool on the same text.
+   If it fails (the post is too dense), give feedback on shortening sentences and
+   simplifying wording, citing the score from the tool.
+
+   Whenever you give feedback, also call the reading_time tool on the post and mention its
+   human-readable estimate (e.g. "about 1 minute"); a LinkedIn post should read in under 2 minutes.
+
+4. If both checks pass, evaluate the post against these criteria:
 
    REQUIRED ELEMENTS:
    1. Mentions @kalseldev
@@ -69,9 +88,9 @@ ELSE IF the post meets ALL requirements:
 Access the current post from state: {state.current_post}
 
 Do not embellish your response. Either provide feedback on what to improve OR call exit_loop and return the completion message.`,
-		Tools:     []tool.Tool{charCounterTool, exitLoopTool},
+		Tools:     []tool.Tool{charCounterTool, readabilityTool, readingTimeTool, exitLoopTool},
 		OutputKey: "review_feedback",
-	})
+	}, limit))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post reviewer agent: %w", err)
 	}
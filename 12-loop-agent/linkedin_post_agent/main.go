@@ -11,6 +11,7 @@
 // - Loop agent with max iterations and exit conditions
 // - Quality-driven loop termination using exit tools
 // - Feedback-based improvement process
+// - Per-session rate limiting of model calls with a before-model callback
 package main
 
 import (
@@ -18,6 +19,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
@@ -30,10 +32,18 @@ import (
 	"google.golang.org/adk/model/gemini"
 
 	"github.com/muchlist/agent-dev-kit/12-loop-agent/linkedin_post_agent/agents"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 const (
 	MODEL_NAME = "gemini-2.0-flash"
+
+	// Default per-session model call limits; the loop can call the model many times in quick
+	// succession. Override with MODEL_MIN_INTERVAL and MODEL_MAX_CALLS_PER_MINUTE (0 disables).
+	// The minimum interval is off by default because the call answering a tool result follows
+	// the previous call almost immediately.
+	DEFAULT_MODEL_MIN_INTERVAL         = 0 * time.Second
+	DEFAULT_MODEL_MAX_CALLS_PER_MINUTE = 30
 )
 
 func main() {
@@ -48,19 +58,28 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
+	// Limit how often one session can call the model, so a fast loop can't exhaust the quota
+	limit, err := agentutil.LoadModelRateLimit(os.Getenv, agentutil.ModelRateLimit{
+		MinInterval:  DEFAULT_MODEL_MIN_INTERVAL,
+		MaxPerMinute: DEFAULT_MODEL_MAX_CALLS_PER_MINUTE,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load model rate limit: %v", err)
+	}
+
 	// Create sub-agents for the refinement loop
-	postReviewer, err := agents.NewPostReviewer(ctx, model)
+	postReviewer, err := agents.NewPostReviewer(ctx, model, limit)
 	if err != nil {
 		log.Fatalf("Failed to create post reviewer agent: %v", err)
 	}
 
-	postRefiner, err := agents.NewPostRefiner(ctx, model)
+	postRefiner, err := agents.NewPostRefiner(ctx, model, limit)
 	if err != nil {
 		log.Fatalf("Failed to create post refiner agent: %v", err)
 	}
 
 	// Create initial post generator
-	initialPostGenerator, err := agents.NewInitialPostGenerator(ctx, model)
+	initialPostGenerator, err := agents.NewInitialPostGenerator(ctx, model, limit)
 	if err != nil {
 		log.Fatalf("Failed to create initial post generator agent: %v", err)
 	}
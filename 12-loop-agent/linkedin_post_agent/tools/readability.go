@@ -0,0 +1,203 @@
+// Package tools implements tools for the LinkedIn post generator loop workflow.
+package tools
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ReadabilityArgs represents the input arguments for the readability tool
+type ReadabilityArgs struct {
+	Text string `json:"text"`
+}
+
+// ReadabilityResult represents the result from the readability tool
+type ReadabilityResult struct {
+	Result         string  `json:"result"`
+	Score          float64 `json:"score"`
+	GradeLevel     string  `json:"grade_level"`
+	Interpretation string  `json:"interpretation"`
+	Sentences      int     `json:"sentences"`
+	Words          int     `json:"words"`
+	Syllables      int     `json:"syllables"`
+	Message        string  `json:"message"`
+}
+
+// MIN_READABILITY_SCORE is the lowest Flesch Reading Ease score accepted for a LinkedIn post.
+// Anything below this reads at college level or harder, which is too dense for a feed.
+const MIN_READABILITY_SCORE = 50.0
+
+// NewReadabilityTool creates a tool that computes the Flesch Reading Ease score of a text.
+// The reviewer uses it to flag posts that are too dense for a LinkedIn audience.
+func NewReadabilityTool() (tool.Tool, error) {
+	readability := func(ctx tool.Context, args ReadabilityArgs) (ReadabilityResult, error) {
+		result := scoreReadability(args.Text)
+
+		log.Printf("\n----------- TOOL DEBUG -----------")
+		log.Printf("Readability score: %.1f (%s)", result.Score, result.GradeLevel)
+		log.Printf("----------------------------------\n")
+
+		return result, nil
+	}
+
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "readability_score",
+			Description: "Computes the Flesch Reading Ease score of text and flags posts that are too dense to read",
+		},
+		readability,
+	)
+}
+
+// scoreReadability scores text and passes it when the score reaches MIN_READABILITY_SCORE
+func scoreReadability(text string) ReadabilityResult {
+	score, sentences, words, syllables := FleschReadingEase(text)
+	if words == 0 {
+		return ReadabilityResult{
+			Result:  "fail",
+			Message: "Text is empty, nothing to score.",
+		}
+	}
+
+	grade, interpretation := interpretFleschScore(score)
+	result := ReadabilityResult{
+		Result:         "pass",
+		Score:          score,
+		GradeLevel:     grade,
+		Interpretation: interpretation,
+		Sentences:      sentences,
+		Words:          words,
+		Syllables:      syllables,
+		Message:        fmt.Sprintf("Readability is good (score %.1f, %s).", score, interpretation),
+	}
+	if score < MIN_READABILITY_SCORE {
+		result.Result = "fail"
+		result.Message = fmt.Sprintf("Post is too dense (score %.1f, %s). Use shorter sentences and simpler words to reach at least %.0f.", score, interpretation, MIN_READABILITY_SCORE)
+	}
+	return result
+}
+
+// FleschReadingEase returns the Flesch Reading Ease score of text along with the
+// sentence, word and syllable counts used to compute it:
+//
+//	206.835 - 1.015 * (words / sentences) - 84.6 * (syllables / words)
+//
+// The score is clamped to the 0-100 range. Empty text scores 0.
+func FleschReadingEase(text string) (score float64, sentences, words, syllables int) {
+	for _, word := range strings.FieldsFunc(text, isWordSeparator) {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word == "" {
+			continue
+		}
+		words++
+		syllables += CountSyllables(word)
+	}
+	if words == 0 {
+		return 0, 0, 0, 0
+	}
+
+	sentences = countSentences(text)
+	score = 206.835 - 1.015*(float64(words)/float64(sentences)) - 84.6*(float64(syllables)/float64(words))
+	score = math.Max(0, math.Min(100, score))
+	return math.Round(score*10) / 10, sentences, words, syllables
+}
+
+// CountSyllables estimates the number of syllables in a single English word.
+//
+// The heuristic counts groups of consecutive vowels (a, e, i, o, u, y), then
+// drops a trailing silent "e" ("make" -> 1) unless the word ends in a
+// consonant + "le" ("table" -> 2). Every word has at least one syllable.
+// It is approximate: words like "queue" or "business" are miscounted, which is
+// acceptable because the score is averaged over the whole text.
+func CountSyllables(word string) int {
+	word = strings.ToLower(word)
+	letters := make([]rune, 0, len(word))
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	if len(letters) == 0 {
+		return 1
+	}
+
+	count := 0
+	prevVowel := false
+	for _, r := range letters {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	n := len(letters)
+	if n > 2 && letters[n-1] == 'e' && !strings.ContainsRune("aeiouy", letters[n-2]) {
+		endsInConsonantLe := letters[n-2] == 'l' && !strings.ContainsRune("aeiouy", letters[n-3])
+		if !endsInConsonantLe {
+			count--
+		}
+	}
+
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// countSentences counts runs of sentence-ending punctuation; text without any
+// terminator is treated as a single sentence.
+func countSentences(text string) int {
+	count := 0
+	inTerminator := false
+	for _, r := range text {
+		terminator := r == '.' || r == '!' || r == '?'
+		if terminator && !inTerminator {
+			count++
+		}
+		inTerminator = terminator
+	}
+
+	// Trailing text after the last terminator is its own sentence
+	trimmed := strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`"')]`, r)
+	})
+	if trimmed != "" && !strings.ContainsRune(".!?", rune(trimmed[len(trimmed)-1])) {
+		count++
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+func isWordSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == '/' || r == '—' || r == '–'
+}
+
+// interpretFleschScore maps a Flesch Reading Ease score to the conventional
+// US grade level and difficulty label.
+func interpretFleschScore(score float64) (gradeLevel, interpretation string) {
+	switch {
+	case score >= 90:
+		return "5th grade", "very easy to read"
+	case score >= 80:
+		return "6th grade", "easy to read"
+	case score >= 70:
+		return "7th grade", "fairly easy to read"
+	case score >= 60:
+		return "8th-9th grade", "plain English"
+	case score >= 50:
+		return "10th-12th grade", "fairly difficult to read"
+	case score >= 30:
+		return "college", "difficult to read"
+	default:
+		return "college graduate", "very difficult to read"
+	}
+}
@@ -0,0 +1,52 @@
+package tools
+
+import "testing"
+
+func TestScoreReadability(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		score     float64
+		sentences int
+		words     int
+		syllables int
+		result    string
+	}{
+		{"empty", "", 0, 0, 0, 0, "fail"},
+		{"whitespace only", "  \n ", 0, 0, 0, 0, "fail"},
+		{"single short sentence", "The cat sat on the mat.", 100, 1, 6, 6, "pass"},
+		{"single dense sentence", "The committee reviewed the quarterly results yesterday.", 6.4, 1, 7, 16, "fail"},
+		{"no terminator", "Hello world", 77.9, 1, 2, 3, "pass"},
+		{"plain English", "Our team launched a new product today. Customers love the simple design.", 66.8, 2, 12, 19, "pass"},
+		{"trailing sentence without terminator", "I learned a lot this year. Thank you to everyone who helped", 87.9, 2, 12, 16, "pass"},
+		{"clamped to zero", "Leadership requires listening carefully to different perspectives before making important decisions.", 0, 1, 11, 31, "fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreReadability(tt.text)
+			if got.Score != tt.score || got.Sentences != tt.sentences || got.Words != tt.words || got.Syllables != tt.syllables {
+				t.Errorf("score %.1f from %d sentences, %d words, %d syllables; want %.1f from %d, %d, %d",
+					got.Score, got.Sentences, got.Words, got.Syllables, tt.score, tt.sentences, tt.words, tt.syllables)
+			}
+			if got.Result != tt.result || got.Message == "" {
+				t.Errorf("result = %q (%q), want %q", got.Result, got.Message, tt.result)
+			}
+		})
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"cat":       1,
+		"make":      1,
+		"table":     2,
+		"beautiful": 3,
+		"rhythm":    1,
+		"a":         1,
+	}
+	for word, want := range tests {
+		if got := CountSyllables(word); got != want {
+			t.Errorf("CountSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package main provides a code execution agent example using ADK with Gemini's built-in code executor.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/geminitool"
+)
+
+func main() {
+	godotenv.Load()
+	ctx := context.Background()
+
+	// Create the Gemini model with API key from environment
+	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	// Built-in code execution tool
+	// There is no typed helper like geminitool.GoogleSearch{} for code execution yet,
+	// so we wrap the raw genai.Tool with geminitool.New().
+	// The code runs in Gemini's sandbox (Python), not on this machine.
+	tools := []tool.Tool{
+		geminitool.New("code_execution", &genai.Tool{
+			CodeExecution: &genai.ToolCodeExecution{},
+		}),
+	}
+
+	// IMPORTANT NOTE:
+	// Currently, for each root agent or single agent, only ONE built-in tool is supported.
+	// You CANNOT combine code execution with GoogleSearch, or with custom function tools,
+	// in the same agent. To use both types, you would need to use a multi-agent approach.
+	//
+	// This WILL NOT WORK:
+	// tools = []tool.Tool{
+	//     geminitool.New("code_execution", &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}}),
+	//     geminitool.GoogleSearch{},
+	// }
+
+	// Create the code execution agent
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "code_exec_agent",
+		Model:       model,
+		Description: "Solves math and data questions by writing and running code",
+		Instruction: `You are a helpful assistant for math and data questions.
+
+You can write and run Python code with the code execution tool. Use it whenever a question involves:
+- Arithmetic beyond simple mental math
+- Statistics, unit conversions or financial calculations
+- Working with data the user pastes (CSV, lists of numbers, JSON)
+
+Always run the code instead of guessing a result. After the code runs, explain the answer
+in plain language and show the key numbers. If the code fails, fix it and run it again.`,
+		Tools: tools,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	// Configure and launch the agent
+	config := &launcher.Config{
+		AgentLoader: agent.NewSingleLoader(a),
+	}
+
+	l := full.NewLauncher()
+	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// ===== Batch Mode =====
+//
+// With -batch <file> the email workflow runs once per recipient instead of launching the web UI
+// or console. The file is a JSON array of objects or a CSV with a header row; each entry's fields
+// (name, company, context, ...) become the request for one personalized email. Every recipient
+// gets a new session, so no email or feedback is carried over from the previous one. Failures
+// are recorded for the recipient and the batch goes on; the results are written as a JSON
+// array to -batch-out.
+
+const (
+	BATCH_APP_NAME        = "email_batch"
+	BATCH_USER_ID         = "batch"
+	DEFAULT_BATCH_OUTPUT  = "emails.json"
+	BATCH_PROMPT_TEMPLATE = "Write a personalized email for this recipient:\n%s"
+)
+
+// batchRecipient is one entry of the batch file, with its fields in CSV column order (sorted by
+// name for JSON)
+type batchRecipient struct {
+	fields [][2]string
+}
+
+// label names the recipient in progress output: its name or email field, or its position
+func (r batchRecipient) label(i int) string {
+	for _, key := range []string{"name", "email"} {
+		if v := r.get(key); v != "" {
+			return v
+		}
+	}
+	return "recipient " + strconv.Itoa(i+1)
+}
+
+// get returns the value of field key (case-insensitive), or ""
+func (r batchRecipient) get(key string) string {
+	for _, f := range r.fields {
+		if strings.EqualFold(f[0], key) {
+			return f[1]
+		}
+	}
+	return ""
+}
+
+// prompt is the request sent to the email workflow for this recipient
+func (r batchRecipient) prompt() string {
+	var sb strings.Builder
+	for _, f := range r.fields {
+		fmt.Fprintf(&sb, "- %s: %s\n", f[0], f[1])
+	}
+	return fmt.Sprintf(BATCH_PROMPT_TEMPLATE, sb.String())
+}
+
+// MarshalJSON writes the fields as an object
+func (r batchRecipient) MarshalJSON() ([]byte, error) {
+	m := make(map[string]string, len(r.fields))
+	for _, f := range r.fields {
+		m[f[0]] = f[1]
+	}
+	return json.Marshal(m)
+}
+
+// batchResult is the outcome for one recipient; Email is nil when Error is set
+type batchResult struct {
+	Recipient batchRecipient `json:"recipient"`
+	Email     *email         `json:"email,omitempty"`
+	// ValidationFeedback lists the checks the last attempt still failed, if any
+	ValidationFeedback string `json:"validation_feedback,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+// readRecipients reads a .json or .csv batch file
+func readRecipients(path string) ([]batchRecipient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return parseJSONRecipients(f)
+	case ".csv":
+		return parseCSVRecipients(f)
+	default:
+		return nil, fmt.Errorf("unsupported batch file %q: use .json or .csv", path)
+	}
+}
+
+// parseJSONRecipients reads an array of objects. Values are written as text (numbers and booleans
+// as they appear), and fields are sorted by name since JSON objects have no order.
+func parseJSONRecipients(r io.Reader) ([]batchRecipient, error) {
+	var entries []map[string]any
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("want a JSON array of objects: %w", err)
+	}
+
+	recipients := make([]batchRecipient, 0, len(entries))
+	for _, entry := range entries {
+		var recipient batchRecipient
+		for key, val := range entry {
+			text := ""
+			switch v := val.(type) {
+			case nil:
+			case string:
+				text = v
+			case json.Number, bool:
+				text = fmt.Sprint(v)
+			default:
+				b, _ := json.Marshal(v)
+				text = string(b)
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				recipient.fields = append(recipient.fields, [2]string{key, text})
+			}
+		}
+		slices.SortFunc(recipient.fields, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// parseCSVRecipients reads a header row of field names and one recipient per row. Empty cells
+// are left out.
+func parseCSVRecipients(r io.Reader) ([]batchRecipient, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the CSV file is empty, want a header row")
+	}
+
+	header := rows[0]
+	recipients := make([]batchRecipient, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		var recipient batchRecipient
+		for i, cell := range row {
+			if cell = strings.TrimSpace(cell); cell != "" {
+				recipient.fields = append(recipient.fields, [2]string{strings.TrimSpace(header[i]), cell})
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// runBatch generates an email per recipient in inPath with workflow and writes the results to
+// outPath. Ctrl-C stops the batch; the recipient in progress is recorded as failed, and the
+// results so far are still written.
+func runBatch(ctx context.Context, workflow agent.Agent, inPath, outPath string) error {
+	recipients, err := readRecipients(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to read recipients: %w", err)
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipients in %s", inPath)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        BATCH_APP_NAME,
+		Agent:          workflow,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	fmt.Printf("\n📨 Generating %d email(s) from %s\n", len(recipients), inPath)
+	results := generateBatch(ctx, r, sessionService, recipients)
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if err := writeBatchResults(outPath, results); err != nil {
+		return err
+	}
+	fmt.Printf("\n✅ %d of %d email(s) generated, %d failed, %d skipped; results in %s\n",
+		len(results)-failed, len(recipients), failed, len(recipients)-len(results), outPath)
+	return nil
+}
+
+// generateBatch runs each recipient in turn, recording failures and going on, until all are
+// done or ctx is canceled
+func generateBatch(ctx context.Context, r *runner.Runner, sessionService session.Service, recipients []batchRecipient) []batchResult {
+	results := make([]batchResult, 0, len(recipients))
+	for i, recipient := range recipients {
+		if ctx.Err() != nil {
+			fmt.Println("\n🛑 Batch stopped")
+			break
+		}
+
+		result := batchResult{Recipient: recipient}
+		e, feedback, err := generateEmail(ctx, r, sessionService, recipient)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+			fmt.Printf("[%d/%d] ❌ %s: %v\n", i+1, len(recipients), recipient.label(i), err)
+		case feedback != "":
+			result.Email, result.ValidationFeedback = &e, feedback
+			fmt.Printf("[%d/%d] ⚠️  %s: %q (%s)\n", i+1, len(recipients), recipient.label(i), e.Subject, feedback)
+		default:
+			result.Email = &e
+			fmt.Printf("[%d/%d] ✉️  %s: %q\n", i+1, len(recipients), recipient.label(i), e.Subject)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// generateEmail runs the workflow for one recipient in a new session and returns the email and
+// any validation feedback left in state
+func generateEmail(ctx context.Context, r *runner.Runner, sessionService session.Service, recipient batchRecipient) (email, string, error) {
+	sessionID := uuid.New().String()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   BATCH_APP_NAME,
+		UserID:    BATCH_USER_ID,
+		SessionID: sessionID,
+	}); err != nil {
+		return email{}, "", fmt.Errorf("failed to create session: %w", err)
+	}
+	// The session is only needed for this recipient; drop it so a long batch doesn't grow memory
+	defer sessionService.Delete(context.WithoutCancel(ctx), &session.DeleteRequest{
+		AppName:   BATCH_APP_NAME,
+		UserID:    BATCH_USER_ID,
+		SessionID: sessionID,
+	})
+
+	msg := genai.NewContentFromText(recipient.prompt(), genai.RoleUser)
+	for event, err := range r.Run(ctx, BATCH_USER_ID, sessionID, msg, agent.RunConfig{}) {
+		if err != nil {
+			return email{}, "", fmt.Errorf("agent run failed: %w", err)
+		}
+		if event.ErrorCode != "" {
+			return email{}, "", fmt.Errorf("model error %s: %s", event.ErrorCode, event.ErrorMessage)
+		}
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{
+		AppName:   BATCH_APP_NAME,
+		UserID:    BATCH_USER_ID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return email{}, "", fmt.Errorf("failed to get session: %w", err)
+	}
+	state := resp.Session.State()
+
+	e, err := getEmail(state)
+	if err != nil {
+		return email{}, "", err
+	}
+	feedback, _ := state.Get(EMAIL_FEEDBACK_KEY)
+	feedbackText, _ := feedback.(string)
+	return e, feedbackText, nil
+}
+
+// writeBatchResults writes results to path as an indented JSON array
+func writeBatchResults(path string, results []batchResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+func TestParseCSVRecipients(t *testing.T) {
+	recipients, err := parseCSVRecipients(strings.NewReader("name,company,context\nSam,Acme,\"renewal, due soon\"\n,Globex,intro\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 2 {
+		t.Fatalf("got %d recipients, want 2", len(recipients))
+	}
+
+	want := "Write a personalized email for this recipient:\n- name: Sam\n- company: Acme\n- context: renewal, due soon\n"
+	if got := recipients[0].prompt(); got != want {
+		t.Errorf("prompt() = %q, want %q", got, want)
+	}
+	if got := recipients[1].label(1); got != "recipient 2" {
+		t.Errorf("label() without a name = %q, want the position", got)
+	}
+
+	if _, err := parseCSVRecipients(strings.NewReader("name,company\nSam\n")); err == nil {
+		t.Error("a row with a missing column was accepted")
+	}
+}
+
+func TestParseJSONRecipients(t *testing.T) {
+	recipients, err := parseJSONRecipients(strings.NewReader(`[{"name": "Sam", "seats": 25, "vip": true, "notes": null}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Write a personalized email for this recipient:\n- name: Sam\n- seats: 25\n- vip: true\n"
+	if len(recipients) != 1 || recipients[0].prompt() != want {
+		t.Errorf("got %+v, want one recipient with prompt %q", recipients, want)
+	}
+
+	if _, err := parseJSONRecipients(strings.NewReader(`{"name": "Sam"}`)); err == nil {
+		t.Error("a JSON object instead of an array was accepted")
+	}
+}
+
+// newFakeWriter returns an agent that stores an email addressed to the recipient's name, fails
+// for names starting with "fail", and leaves validation feedback for names starting with "long"
+func newFakeWriter(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: "fake_writer",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				prompt := ctx.UserContent().Parts[0].Text
+				name := strings.TrimSpace(strings.SplitN(strings.SplitN(prompt, "- name: ", 2)[1], "\n", 2)[0])
+				if strings.HasPrefix(name, "fail") {
+					yield(nil, errors.New("model unavailable"))
+					return
+				}
+
+				e, _ := json.Marshal(email{Subject: "Hello " + name, Body: "Hi " + name + ",\n\nBest regards,\nAlex"})
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "fake_writer"
+				event.Content = genai.NewContentFromText(string(e), genai.RoleModel)
+				event.Actions.StateDelta[EMAIL_KEY] = string(e)
+				if strings.HasPrefix(name, "long") {
+					event.Actions.StateDelta[EMAIL_FEEDBACK_KEY] = "The subject is too long"
+				}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestGenerateBatchContinuesAfterFailures(t *testing.T) {
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: BATCH_APP_NAME, Agent: newFakeWriter(t), SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipients, err := parseCSVRecipients(strings.NewReader("name\nSam\nfail-Kim\nlong-Lee\nAri\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := generateBatch(context.Background(), r, sessionService, recipients)
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want one per recipient", len(results))
+	}
+
+	if results[0].Email == nil || results[0].Email.Subject != "Hello Sam" || results[0].Error != "" {
+		t.Errorf("result 0 = %+v, want Sam's email", results[0])
+	}
+	if results[1].Email != nil || !strings.Contains(results[1].Error, "model unavailable") {
+		t.Errorf("result 1 = %+v, want the run error", results[1])
+	}
+	if results[2].Email == nil || results[2].ValidationFeedback != "The subject is too long" {
+		t.Errorf("result 2 = %+v, want the email with its validation feedback", results[2])
+	}
+	// Each recipient has its own session, so the previous feedback isn't carried over
+	if results[3].Email == nil || results[3].Email.Subject != "Hello Ari" || results[3].ValidationFeedback != "" {
+		t.Errorf("result 3 = %+v, want Ari's email without feedback", results[3])
+	}
+
+	data, err := json.Marshal(results[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"recipient":{"name":"fail-Kim"},"error":"agent run failed: model unavailable"}` {
+		t.Errorf("failed result JSON = %s", got)
+	}
+}
@@ -1,33 +1,386 @@
 // Package main provides an email generator agent example using ADK with structured outputs.
+//
+// The email writer writes the email as JSON with an output schema. Agents with an output schema
+// can't call tools, so an email validator agent runs after it in a loop and checks the email
+// with the validate_email tool. Failing checks are passed back to the email writer, which
+// revises the email until it passes or the loop runs out of iterations. The email agent at the
+// root hands writing to that loop and translates the finished email with translate_email.
+//
+// With -batch <file> the loop runs once per recipient in a JSON or CSV file and the emails are
+// written to a JSON file (see batch.go).
 package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
-func main() {
-	godotenv.Load()
-	ctx := context.Background()
+const (
+	EMAIL_KEY          = "email"
+	EMAIL_FEEDBACK_KEY = "email_feedback"
+	VALIDATE_EMAIL     = "validate_email"
 
-	// Create the Gemini model with API key from environment
-	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
+	// Most inboxes cut a subject off after about 60 characters
+	MAX_SUBJECT_LENGTH = 60
+
+	// Rounds of writing and validating before the last email is kept as it is
+	MAX_EMAIL_ATTEMPTS = 3
+)
+
+// EMAIL_AGENT_GENERATION is the email writer's default generation parameters. A low temperature
+// keeps the JSON output and tone consistent; override with EMAIL_AGENT_TEMPERATURE, _TOP_P and
+// _MAX_OUTPUT_TOKENS.
+var EMAIL_AGENT_GENERATION = agentutil.GenerationParams{
+	Temperature: genai.Ptr[float32](0.2),
+}
+
+// ===== Email Validation =====
+//
+// The output schema only makes sure subject and body are present strings. validate_email adds
+// the checks a schema can't express: the subject fits in an inbox, the body has content, and
+// the body ends with a sign-off and the sender's name.
+
+// signOffRe matches closing lines such as "Best regards," or "Thanks,"
+var signOffRe = regexp.MustCompile(`(?i)^(best|kind|warm|warmest)?\s*(regards|wishes)\b|^(sincerely|thanks|thank you|cheers|best|all the best|respectfully|yours)\b`)
+
+// placeholderRe matches unfilled template text such as "[Your Name]"
+var placeholderRe = regexp.MustCompile(`\[[^\]]*\]`)
+
+type validateEmailArgs struct{}
+
+// emailCheck is the result of one constraint on one field
+type emailCheck struct {
+	Field   string `json:"field"`
+	Check   string `json:"check"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+type validateEmailResults struct {
+	Status        string       `json:"status"` // "valid", "invalid" or "error"
+	SubjectLength int          `json:"subject_length"`
+	BodyLength    int          `json:"body_length"`
+	BodyWords     int          `json:"body_words"`
+	Checks        []emailCheck `json:"checks"`
+	Message       string       `json:"message"`
+}
+
+// email is the structure the output schema asks for
+type email struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// validateEmail checks the email in state and stores the failing checks under email_feedback
+// for the email writer's next attempt. It ends the loop when every check passes.
+func validateEmail(ctx tool.Context, input validateEmailArgs) (validateEmailResults, error) {
+	fmt.Printf("--- Tool: %s called ---\n", VALIDATE_EMAIL)
+
+	e, err := getEmail(ctx.State())
 	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
+		return validateEmailResults{Status: "error", Message: err.Error()}, nil
+	}
+
+	results := checkEmail(e)
+	feedback := ""
+	if results.Status == "valid" {
+		ctx.Actions().Escalate = true
+	} else {
+		feedback = results.Message
+	}
+	if err := ctx.State().Set(EMAIL_FEEDBACK_KEY, feedback); err != nil {
+		return validateEmailResults{Status: "error", Message: fmt.Sprintf("failed to save feedback: %v", err)}, nil
+	}
+	return results, nil
+}
+
+// getEmail reads the email writer's output. OutputKey stores the response text, so it is JSON;
+// the writer's JSONOutputCleanup strips fences, but state saved before it may still have one.
+func getEmail(state session.ReadonlyState) (email, error) {
+	val, err := state.Get(EMAIL_KEY)
+	if err != nil {
+		return email{}, fmt.Errorf("no email in state yet, the email writer hasn't written one")
+	}
+
+	var e email
+	switch v := val.(type) {
+	case string:
+		text := strings.TrimSpace(v)
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.Trim(text, "`\n ")
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return email{}, fmt.Errorf("the email isn't valid JSON with subject and body: %v", err)
+		}
+	case map[string]any:
+		e.Subject, _ = v["subject"].(string)
+		e.Body, _ = v["body"].(string)
+	default:
+		return email{}, fmt.Errorf("unexpected email value of type %T", val)
+	}
+	return e, nil
+}
+
+// checkEmail runs every check on e and summarizes the failures
+func checkEmail(e email) validateEmailResults {
+	subject := strings.TrimSpace(e.Subject)
+	body := strings.TrimSpace(e.Body)
+	results := validateEmailResults{
+		SubjectLength: utf8.RuneCountInString(subject),
+		BodyLength:    utf8.RuneCountInString(body),
+		BodyWords:     len(strings.Fields(body)),
+	}
+
+	add := func(field, check string, passed bool, message string) {
+		results.Checks = append(results.Checks, emailCheck{Field: field, Check: check, Passed: passed, Message: message})
+	}
+
+	switch {
+	case subject == "":
+		add("subject", "not_empty", false, "The subject is empty; write a short subject that says what the email is about")
+	case strings.ContainsAny(subject, "\r\n"):
+		add("subject", "single_line", false, "The subject has a line break; put it on one line")
+	default:
+		add("subject", "not_empty", true, "The subject is present")
+	}
+
+	if results.SubjectLength > MAX_SUBJECT_LENGTH {
+		add("subject", "max_length", false, fmt.Sprintf(
+			"The subject is %d characters; shorten it to %d or fewer (cut at least %d)",
+			results.SubjectLength, MAX_SUBJECT_LENGTH, results.SubjectLength-MAX_SUBJECT_LENGTH))
+	} else {
+		add("subject", "max_length", true, fmt.Sprintf("The subject is %d of %d characters", results.SubjectLength, MAX_SUBJECT_LENGTH))
+	}
+
+	if body == "" {
+		add("body", "not_empty", false, "The body is empty; write the greeting, the message and a closing")
+	} else {
+		add("body", "not_empty", true, fmt.Sprintf("The body has %d words", results.BodyWords))
+	}
+
+	passed, message := checkSignature(body)
+	add("body", "signature", passed, message)
+
+	var failures []string
+	for _, c := range results.Checks {
+		if !c.Passed {
+			failures = append(failures, c.Message)
+		}
+	}
+	if len(failures) == 0 {
+		results.Status = "valid"
+		results.Message = "The email passes all checks"
+		return results
+	}
+	results.Status = "invalid"
+	results.Message = fmt.Sprintf("%d check(s) failed: %s", len(failures), strings.Join(failures, ". "))
+	return results
+}
+
+// checkSignature looks for a sign-off line near the end of body with the sender's name after it
+func checkSignature(body string) (bool, string) {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	// The sign-off is one of the last few lines; anything after it is the name and title
+	for i := max(0, len(lines)-4); i < len(lines); i++ {
+		if !signOffRe.MatchString(lines[i]) {
+			continue
+		}
+		if i == len(lines)-1 {
+			return false, fmt.Sprintf("The body ends with %q but no name; add the sender's name on the line after it", lines[i])
+		}
+		if name := lines[i+1]; placeholderRe.MatchString(name) {
+			return false, fmt.Sprintf("The signature %q is a placeholder; replace it with the sender's name", name)
+		}
+		return true, "The body ends with a sign-off and a name"
+	}
+	return false, `The body has no sign-off; end it with a closing such as "Best regards," followed by the sender's name`
+}
+
+// ===== Email Translation =====
+//
+// translate_email sends the subject and body of state["email"] through toolutil.Translate, the
+// translator behind the general translate tool, and stores the translation under
+// email_<language code> with the same subject/body shape, e.g. state["email_es"]. Long bodies
+// are split at paragraph breaks into chunks of at most toolutil.MAX_TRANSLATE_CHUNK characters,
+// so each request stays small and the paragraphs are kept. Bodies that would need more than
+// MAX_TRANSLATION_CHUNKS chunks are refused.
+
+const (
+	TRANSLATE_EMAIL = "translate_email"
+
+	MAX_TRANSLATION_CHUNKS = 10
+)
+
+// translationLanguages maps the supported language codes to their English names
+var translationLanguages = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"zh": "Chinese",
+}
+
+type translateEmailArgs struct {
+	Language string `json:"language"` // language name or code, e.g. "Spanish" or "es"
+}
+
+type translateEmailResults struct {
+	Status   string `json:"status"` // "success", "unsupported_language" or "error"
+	Language string `json:"language,omitempty"`
+	StateKey string `json:"state_key,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Body     string `json:"body,omitempty"`
+	Chunks   int    `json:"chunks,omitempty"`
+	Message  string `json:"message"`
+}
+
+// newTranslateEmail returns the translate_email tool function, translating with mdl
+func newTranslateEmail(mdl model.LLM) func(tool.Context, translateEmailArgs) (translateEmailResults, error) {
+	return func(ctx tool.Context, input translateEmailArgs) (translateEmailResults, error) {
+		fmt.Printf("--- Tool: %s called for language %q ---\n", TRANSLATE_EMAIL, input.Language)
+
+		code, name, ok := lookupLanguage(input.Language)
+		if !ok {
+			return translateEmailResults{
+				Status:  "unsupported_language",
+				Message: fmt.Sprintf("Can't translate into %q. Supported languages: %s", input.Language, supportedLanguages()),
+			}, nil
+		}
+
+		e, err := getEmail(ctx.State())
+		if err != nil {
+			return translateEmailResults{Status: "error", Message: err.Error()}, nil
+		}
+
+		translated, chunks, err := translateEmail(ctx, mdl, e, name)
+		if err != nil {
+			return translateEmailResults{Status: "error", Language: name, Message: err.Error()}, nil
+		}
+
+		key := EMAIL_KEY + "_" + code
+		if err := ctx.State().Set(key, map[string]any{"subject": translated.Subject, "body": translated.Body}); err != nil {
+			return translateEmailResults{Status: "error", Language: name, Message: fmt.Sprintf("failed to save translation: %v", err)}, nil
+		}
+
+		return translateEmailResults{
+			Status:   "success",
+			Language: name,
+			StateKey: key,
+			Subject:  translated.Subject,
+			Body:     translated.Body,
+			Chunks:   chunks,
+			Message:  fmt.Sprintf("Translated the email into %s and saved it as %s", name, key),
+		}, nil
+	}
+}
+
+// lookupLanguage finds a supported language by code or English name, ignoring case
+func lookupLanguage(language string) (code, name string, ok bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if name, ok := translationLanguages[language]; ok {
+		return language, name, true
+	}
+	for code, name := range translationLanguages {
+		if strings.ToLower(name) == language {
+			return code, name, true
+		}
+	}
+	return "", "", false
+}
+
+// supportedLanguages lists the supported languages, e.g. "Arabic (ar), Chinese (zh), ..."
+func supportedLanguages() string {
+	names := make([]string, 0, len(translationLanguages))
+	for code, name := range translationLanguages {
+		names = append(names, fmt.Sprintf("%s (%s)", name, code))
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// translateEmail translates the subject and the body into language and returns the translated
+// email and the number of body chunks
+func translateEmail(ctx context.Context, mdl model.LLM, e email, language string) (email, int, error) {
+	body, err := toolutil.Translate(ctx, mdl, e.Body, language, MAX_TRANSLATION_CHUNKS)
+	if err != nil {
+		return email{}, 0, fmt.Errorf("failed to translate the body: %w", err)
+	}
+	subject, err := toolutil.Translate(ctx, mdl, e.Subject, language, 1)
+	if err != nil {
+		return email{}, 0, fmt.Errorf("failed to translate the subject: %w", err)
 	}
+	return email{Subject: subject.Text, Body: body.Text}, body.Chunks, nil
+}
+
+// ===== Agents =====
+
+// emailWriterInstruction includes the validator's feedback from the previous attempt, if any
+const emailWriterInstruction = `You are an Email Generation Assistant.
+Your task is to generate a professional email based on the user's request.
+
+GUIDELINES:
+- Create an appropriate subject line (concise and relevant, at most 60 characters)
+- Write a well-structured email body with:
+    * Professional greeting
+    * Clear and concise main content
+    * Appropriate closing
+    * Your name as signature
+- Email tone should match the purpose (formal for business, friendly for colleagues)
+- Keep emails concise but complete
+
+FEEDBACK ON YOUR PREVIOUS EMAIL (empty on the first attempt):
+{email_feedback?}
+
+If there is feedback, rewrite the email to fix every point and keep everything else as it was.
+
+IMPORTANT: Your response MUST be valid JSON matching this structure:
+{
+    "subject": "Subject line here",
+    "body": "Email body here with proper paragraphs and formatting"
+}
+
+DO NOT include any explanations or additional text outside the JSON response.`
 
+// newEmailWriter creates the agent that writes the email as JSON into state["email"]
+func newEmailWriter(mdl model.LLM, generation agentutil.GenerationParams) (agent.Agent, error) {
 	// Define the output schema for structured email content
 	// This ensures the LLM response is in a specific JSON format
 	emailSchema := &genai.Schema{
@@ -45,45 +398,155 @@ func main() {
 		Required: []string{"subject", "body"},
 	}
 
-	// Create the email generator agent with structured output
-	a, err := llmagent.New(llmagent.Config{
+	// Models sometimes wrap the JSON in a ```json fence or add a sentence around it; the cleanup
+	// strips that before the response is stored under EMAIL_KEY
+	return llmagent.New(agentutil.WithJSONOutputCleanup(llmagent.Config{
+		Name:                  "email_writer",
+		Model:                 mdl,
+		Description:           "Generates professional emails with structured subject and body",
+		Instruction:           emailWriterInstruction,
+		OutputSchema:          emailSchema,
+		OutputKey:             EMAIL_KEY,
+		GenerateContentConfig: generation.ContentConfig(),
+	}))
+}
+
+// newEmailValidator creates the agent that checks the email with validate_email
+func newEmailValidator(mdl model.LLM) (agent.Agent, error) {
+	validateEmailTool, err := functiontool.New(
+		functiontool.Config{
+			Name: VALIDATE_EMAIL,
+			Description: "Checks the generated email in state: subject length, non-empty body and a signature. " +
+				"Returns pass/fail with a message for each check and ends the review when everything passes",
+		},
+		validateEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", VALIDATE_EMAIL, err)
+	}
+
+	return llmagent.New(llmagent.Config{
+		Name:        "email_validator",
+		Model:       mdl,
+		Description: "Checks the generated email against length and signature rules",
+		Instruction: `You are an Email Validator.
+
+ALWAYS call the validate_email tool first. Do not check the email yourself.
+
+- If the status is "invalid", list each failing check's message as a short bullet, so the email
+  can be revised. Don't rewrite the email yourself.
+- If the status is "error", report the message.`,
+		Tools: []tool.Tool{validateEmailTool},
+	})
+}
+
+// newEmailAgent creates the root agent, which also names the app (/apps/email_agent in the API).
+// It hands writing to emailLoop and translates the current email itself with translate_email.
+func newEmailAgent(mdl model.LLM, emailLoop agent.Agent) (agent.Agent, error) {
+	translateEmailTool, err := functiontool.New(
+		functiontool.Config{
+			Name: TRANSLATE_EMAIL,
+			Description: "Translates the current email's subject and body into another language and saves it as email_<language code>. " +
+				"Takes the target language as a name or code, e.g. \"Spanish\" or \"es\"",
+		},
+		newTranslateEmail(mdl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", TRANSLATE_EMAIL, err)
+	}
+
+	return llmagent.New(llmagent.Config{
 		Name:        "email_agent",
-		Model:       model,
-		Description: "Generates professional emails with structured subject and body",
-		Instruction: `You are an Email Generation Assistant.
-Your task is to generate a professional email based on the user's request.
+		Model:       mdl,
+		Description: "Writes professional emails and translates them",
+		Instruction: `You are an Email Assistant.
 
-GUIDELINES:
-- Create an appropriate subject line (concise and relevant)
-- Write a well-structured email body with:
-    * Professional greeting
-    * Clear and concise main content
-    * Appropriate closing
-    * Your name as signature
-- Email tone should match the purpose (formal for business, friendly for colleagues)
-- Keep emails concise but complete
+- To write a new email or change the current one, transfer to EmailGenerationLoop.
+- To translate the current email, call the translate_email tool with the target language, then
+  show the translated subject and body.
+  * If the status is "unsupported_language", tell the user and list the supported languages.
+  * If the status is "error", report the message (e.g. ask the user to write an email first).
+- If the user asks for an email in another language, write it first, then offer to translate it.`,
+		Tools:     []tool.Tool{translateEmailTool},
+		SubAgents: []agent.Agent{emailLoop},
+	})
+}
 
-IMPORTANT: Your response MUST be valid JSON matching this structure:
-{
-    "subject": "Subject line here",
-    "body": "Email body here with proper paragraphs and formatting"
+// clearEmailFeedback removes feedback left from the previous request, so the first attempt
+// of a new email starts clean
+func clearEmailFeedback(ctx agent.CallbackContext) (*genai.Content, error) {
+	if err := ctx.State().Set(EMAIL_FEEDBACK_KEY, ""); err != nil {
+		return nil, fmt.Errorf("failed to clear %s: %w", EMAIL_FEEDBACK_KEY, err)
+	}
+	return nil, nil
 }
 
-DO NOT include any explanations or additional text outside the JSON response.`,
-		OutputSchema: emailSchema,
-		OutputKey:    "email",
+func main() {
+	batch := flag.String("batch", "", "generate an email per recipient in this .json or .csv file instead of launching")
+	batchOut := flag.String("batch-out", DEFAULT_BATCH_OUTPUT, "where -batch writes the generated emails (JSON)")
+	flag.Parse()
+
+	godotenv.Load()
+	ctx := context.Background()
+
+	// Create the Gemini model with API key from environment
+	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
 	})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	generation, err := agentutil.LoadGenerationParams(os.Getenv, "EMAIL_AGENT", EMAIL_AGENT_GENERATION)
+	if err != nil {
+		log.Fatalf("Failed to load generation parameters: %v", err)
+	}
+	log.Printf("email_writer generation: %s", generation)
+
+	// Create the email generator agent with structured output
+	emailWriter, err := newEmailWriter(model, generation)
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
 
+	emailValidator, err := newEmailValidator(model)
+	if err != nil {
+		log.Fatalf("Failed to create email validator agent: %v", err)
+	}
+
+	// Write and validate until the email passes, at most MAX_EMAIL_ATTEMPTS times
+	emailLoop, err := loopagent.New(loopagent.Config{
+		MaxIterations: MAX_EMAIL_ATTEMPTS,
+		AgentConfig: agent.Config{
+			Name:                 "EmailGenerationLoop",
+			Description:          "Writes an email and revises it until it passes validation",
+			SubAgents:            []agent.Agent{emailWriter, emailValidator},
+			BeforeAgentCallbacks: []agent.BeforeAgentCallback{clearEmailFeedback},
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create email generation loop: %v", err)
+	}
+
+	// Batch mode runs the loop directly, once per recipient
+	if *batch != "" {
+		if err := runBatch(ctx, emailLoop, *batch, *batchOut); err != nil {
+			log.Fatalf("Batch failed: %v", err)
+		}
+		return
+	}
+
+	// The root agent routes writing to the loop and handles translations
+	a, err := newEmailAgent(model, emailLoop)
+	if err != nil {
+		log.Fatalf("Failed to create email agent: %v", err)
+	}
+
 	// Configure and launch the agent
 	config := &launcher.Config{
 		AgentLoader: agent.NewSingleLoader(a),
 	}
 
 	l := full.NewLauncher()
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err = l.Execute(ctx, config, flag.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+const signedBody = "Hi Sam,\n\nThe report is attached.\n\nBest regards,\nAlex"
+
+func TestCheckEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      email
+		wantStatus string
+		wantFailed []string // field/check of the failing checks
+	}{
+		{"valid", email{"Quarterly report", signedBody}, "valid", nil},
+		{
+			"long subject",
+			email{strings.Repeat("Very long subject ", 5), signedBody},
+			"invalid",
+			[]string{"subject/max_length"},
+		},
+		{"empty subject", email{"  ", signedBody}, "invalid", []string{"subject/not_empty"}},
+		{"two-line subject", email{"Report\nattached", signedBody}, "invalid", []string{"subject/single_line"}},
+		{"empty body", email{"Quarterly report", ""}, "invalid", []string{"body/not_empty", "body/signature"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkEmail(tt.email)
+			if results.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q (%s)", results.Status, tt.wantStatus, results.Message)
+			}
+			var failed []string
+			for _, c := range results.Checks {
+				if !c.Passed {
+					failed = append(failed, c.Field+"/"+c.Check)
+				}
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("failed checks = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+
+	results := checkEmail(email{strings.Repeat("x", 72), signedBody})
+	if results.SubjectLength != 72 || !strings.Contains(results.Message, "cut at least 12") {
+		t.Errorf("got length %d and message %q, want 72 and how much to cut", results.SubjectLength, results.Message)
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"sign-off and name", signedBody, true},
+		{"name and title", "Hello,\n\nSee you then.\n\nThanks,\nAlex\nProduct Manager", true},
+		{"sincerely", "Dear team,\n\nWelcome aboard.\n\nSincerely,\nJordan", true},
+		{"no sign-off", "Hi Sam,\n\nThe report is attached.", false},
+		{"sign-off without name", "Hi Sam,\n\nThe report is attached.\n\nBest regards,", false},
+		{"placeholder name", "Hi Sam,\n\nThe report is attached.\n\nBest regards,\n[Your Name]", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, message := checkSignature(tt.body); got != tt.want {
+				t.Errorf("checkSignature() = %v (%s), want %v", got, message, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEmail(t *testing.T) {
+	for _, raw := range []any{
+		`{"subject": "Hi", "body": "Text"}`,
+		"```json\n{\"subject\": \"Hi\", \"body\": \"Text\"}\n```",
+		map[string]any{"subject": "Hi", "body": "Text"},
+	} {
+		e, err := getEmail(agenttest.NewState(map[string]any{EMAIL_KEY: raw}, false))
+		if err != nil || e.Subject != "Hi" || e.Body != "Text" {
+			t.Errorf("getEmail(%q) = %+v, %v", raw, e, err)
+		}
+	}
+
+	if _, err := getEmail(agenttest.NewState(nil, false)); err == nil {
+		t.Error("getEmail() with no email succeeded")
+	}
+	if _, err := getEmail(agenttest.NewState(map[string]any{EMAIL_KEY: "Subject: Hi"}, false)); err == nil {
+		t.Error("getEmail() with plain text succeeded")
+	}
+}
+
+// prefixModel "translates" by prefixing the text it's sent, answering in the JSON shape
+// toolutil.Translate asks for, and records each request
+type prefixModel struct {
+	texts []string
+}
+
+func (m *prefixModel) Name() string { return "prefix-model" }
+
+func (m *prefixModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	text := req.Contents[0].Parts[0].Text
+	m.texts = append(m.texts, text)
+	reply, _ := json.Marshal(map[string]string{"source_language": "English", "translation": "ES: " + text})
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(string(reply), genai.RoleModel)}, nil)
+	}
+}
+
+func TestTranslateEmail(t *testing.T) {
+	mdl := &prefixModel{}
+	translated, chunks, err := translateEmail(context.Background(), mdl, email{"Quarterly report", signedBody}, "Spanish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks != 1 || translated.Subject != "ES: Quarterly report" || translated.Body != "ES: "+signedBody {
+		t.Errorf("translateEmail() = %+v in %d chunks", translated, chunks)
+	}
+
+	// A long body is sent in several chunks and put back together with paragraph breaks
+	paragraph := strings.Repeat("word ", toolutil.MAX_TRANSLATE_CHUNK/10)
+	long := strings.TrimSpace(strings.Repeat(paragraph+"\n\n", 4))
+	mdl = &prefixModel{}
+	translated, chunks, err = translateEmail(context.Background(), mdl, email{"Hi", long}, "Spanish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks != 2 || len(mdl.texts) != 3 || strings.Count(translated.Body, "ES: ") != 2 {
+		t.Errorf("got %d chunks and %d requests, want 2 chunks and 3 requests", chunks, len(mdl.texts))
+	}
+
+	tooLong := strings.Repeat("x", toolutil.MAX_TRANSLATE_CHUNK*MAX_TRANSLATION_CHUNKS+1)
+	if _, _, err := translateEmail(context.Background(), &prefixModel{}, email{"Hi", tooLong}, "Spanish"); err == nil {
+		t.Error("translateEmail() of an overly long body succeeded")
+	}
+}
+
+func TestLookupLanguage(t *testing.T) {
+	for _, language := range []string{"es", "ES", "Spanish", " spanish "} {
+		if code, name, ok := lookupLanguage(language); !ok || code != "es" || name != "Spanish" {
+			t.Errorf("lookupLanguage(%q) = %q, %q, %v", language, code, name, ok)
+		}
+	}
+	if _, _, ok := lookupLanguage("Klingon"); ok {
+		t.Error("lookupLanguage(Klingon) succeeded")
+	}
+}
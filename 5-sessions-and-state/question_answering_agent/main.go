@@ -1,6 +1,11 @@
 // Package main demonstrates sessions and state management in ADK.
 // This example shows how to create sessions with initial state and use
 // template variables to access that state in agent instructions.
+//
+// The user's preferences are kept as structured key-values in the user_preferences
+// state value and can be changed at runtime with the update_preferences tool. Because
+// the instruction template is rendered on every model call, the agent sees updated
+// preferences on the next turn.
 package main
 
 import (
@@ -8,6 +13,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -18,14 +26,228 @@ import (
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 const (
 	APP_NAME   = "Bot"
 	USER_ID    = "muchlis"
 	MODEL_NAME = "gemini-2.0-flash"
+
+	USER_PREFERENCES_KEY = "user_preferences"
 )
 
+// ===== Preference Tool Structures =====
+
+type preferenceEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type updatePreferencesArgs struct {
+	Updates []preferenceEntry `json:"updates"`
+	Remove  []string          `json:"remove,omitempty"`
+}
+
+type updatePreferencesResults struct {
+	Status      string            `json:"status"`
+	Updated     []string          `json:"updated,omitempty"`
+	Removed     []string          `json:"removed,omitempty"`
+	NotFound    []string          `json:"not_found,omitempty"`
+	Preferences []preferenceEntry `json:"preferences"`
+	Message     string            `json:"message"`
+}
+
+type getPreferencesArgs struct {
+	Keys []string `json:"keys,omitempty"`
+}
+
+type getPreferencesResults struct {
+	Status      string            `json:"status"`
+	Preferences []preferenceEntry `json:"preferences"`
+	NotFound    []string          `json:"not_found,omitempty"`
+}
+
+// ===== Tool Implementations =====
+
+// updatePreferences sets or removes entries in the structured user_preferences state value
+func updatePreferences(ctx tool.Context, input updatePreferencesArgs) (updatePreferencesResults, error) {
+	fmt.Printf("--- Tool: update_preferences called with %d update(s), %d removal(s) ---\n", len(input.Updates), len(input.Remove))
+
+	state := ctx.State()
+	prefs := getPreferenceMap(state)
+
+	var updated, removed, notFound []string
+	for _, entry := range input.Updates {
+		key := preferenceKey(entry.Key)
+		value := strings.TrimSpace(entry.Value)
+		if key == "" || value == "" {
+			continue
+		}
+		prefs[key] = value
+		updated = append(updated, key)
+	}
+	for _, raw := range input.Remove {
+		key := preferenceKey(raw)
+		if _, ok := prefs[key]; !ok {
+			notFound = append(notFound, key)
+			continue
+		}
+		delete(prefs, key)
+		removed = append(removed, key)
+	}
+
+	if len(updated) == 0 && len(removed) == 0 {
+		return updatePreferencesResults{
+			Status:      "error",
+			NotFound:    notFound,
+			Preferences: preferenceEntries(prefs),
+			Message:     "No preferences were changed. Pass updates as key/value pairs, or keys of existing preferences to remove.",
+		}, nil
+	}
+
+	if err := state.Set(USER_PREFERENCES_KEY, prefs); err != nil {
+		return updatePreferencesResults{}, fmt.Errorf("failed to set %s: %w", USER_PREFERENCES_KEY, err)
+	}
+
+	return updatePreferencesResults{
+		Status:      "success",
+		Updated:     updated,
+		Removed:     removed,
+		NotFound:    notFound,
+		Preferences: preferenceEntries(prefs),
+		Message:     fmt.Sprintf("Updated %d and removed %d preference(s)", len(updated), len(removed)),
+	}, nil
+}
+
+// getPreferences returns the requested preferences, or all of them when no keys are given
+func getPreferences(ctx tool.Context, input getPreferencesArgs) (getPreferencesResults, error) {
+	fmt.Printf("--- Tool: get_preferences called for %v ---\n", input.Keys)
+
+	prefs := getPreferenceMap(ctx.State())
+	if len(input.Keys) == 0 {
+		return getPreferencesResults{Status: "success", Preferences: preferenceEntries(prefs)}, nil
+	}
+
+	selected := map[string]any{}
+	var notFound []string
+	for _, raw := range input.Keys {
+		key := preferenceKey(raw)
+		if value, ok := prefs[key]; ok {
+			selected[key] = value
+		} else {
+			notFound = append(notFound, key)
+		}
+	}
+
+	return getPreferencesResults{
+		Status:      "success",
+		Preferences: preferenceEntries(selected),
+		NotFound:    notFound,
+	}, nil
+}
+
+// ===== Callbacks =====
+
+// migrateUserPreferences converts a legacy free-text user_preferences blob into
+// structured key-values before the instruction template is rendered
+func migrateUserPreferences(ctx agent.CallbackContext) (*genai.Content, error) {
+	state := ctx.State()
+
+	val, err := state.Get(USER_PREFERENCES_KEY)
+	if err == nil {
+		text, isText := val.(string)
+		if !isText {
+			return nil, nil
+		}
+		val = parsePreferenceText(text)
+		fmt.Println("--- Migrated free-text user_preferences to structured preferences ---")
+	} else {
+		// The {user_preferences} template variable is required, so start with no preferences
+		val = map[string]any{}
+	}
+
+	if err := state.Set(USER_PREFERENCES_KEY, val); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", USER_PREFERENCES_KEY, err)
+	}
+	return nil, nil
+}
+
+// ===== Utility Functions =====
+
+var (
+	favoritePreferenceRe = regexp.MustCompile(`(?i)^my favou?rite (.+?) (?:is|are) (.+)$`)
+	likesPreferenceRe    = regexp.MustCompile(`(?i)^i (?:like|love|enjoy) (?:to )?(.+)$`)
+	nonKeyCharsRe        = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// parsePreferenceText turns one-preference-per-line free text into key-values:
+// "My favorite food is Mexican." becomes favorite_food=Mexican, "I like to ..." is
+// collected under likes, and any other line is kept as note_1, note_2, ...
+func parsePreferenceText(text string) map[string]any {
+	prefs := map[string]any{}
+	notes := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ".")
+		if line == "" {
+			continue
+		}
+
+		if m := favoritePreferenceRe.FindStringSubmatch(line); m != nil {
+			prefs[preferenceKey("favorite "+m[1])] = m[2]
+			continue
+		}
+		if m := likesPreferenceRe.FindStringSubmatch(line); m != nil {
+			if likes, ok := prefs["likes"].(string); ok {
+				prefs["likes"] = likes + "; " + m[1]
+			} else {
+				prefs["likes"] = m[1]
+			}
+			continue
+		}
+
+		notes++
+		prefs[fmt.Sprintf("note_%d", notes)] = line
+	}
+	return prefs
+}
+
+// getPreferenceMap returns a copy of the structured preferences in state
+func getPreferenceMap(state session.ReadonlyState) map[string]any {
+	prefs := map[string]any{}
+	val, err := state.Get(USER_PREFERENCES_KEY)
+	if err != nil {
+		return prefs
+	}
+	switch v := val.(type) {
+	case map[string]any:
+		for key, value := range v {
+			prefs[key] = value
+		}
+	case string:
+		// Not migrated yet
+		return parsePreferenceText(v)
+	}
+	return prefs
+}
+
+// preferenceKey normalizes "Favorite TV Show" to favorite_tv_show
+func preferenceKey(raw string) string {
+	return strings.Trim(nonKeyCharsRe.ReplaceAllString(strings.ToLower(raw), "_"), "_")
+}
+
+func preferenceEntries(prefs map[string]any) []preferenceEntry {
+	entries := make([]preferenceEntry, 0, len(prefs))
+	for key, value := range prefs {
+		entries = append(entries, preferenceEntry{Key: key, Value: fmt.Sprint(value)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
 func main() {
 	godotenv.Load()
 	ctx := context.Background()
@@ -38,19 +260,49 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
+	// Create preference tools
+	updatePreferencesTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "update_preferences",
+			Description: "Add, change or remove the user's preferences. updates is a list of key/value pairs " +
+				"(keys like favorite_food or favorite_tv_show); remove is a list of keys to delete",
+		},
+		updatePreferences)
+	if err != nil {
+		log.Fatalf("Failed to create update_preferences tool: %v", err)
+	}
+
+	getPreferencesTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "get_preferences",
+			Description: "Get the user's preferences by key, or all preferences when no keys are given",
+		},
+		getPreferences)
+	if err != nil {
+		log.Fatalf("Failed to create get_preferences tool: %v", err)
+	}
+
 	// Create the question answering agent with template variables
-	// The {user_name} and {user_preferences} will be replaced with values from session state
+	// The {user_name} and {user_preferences} will be replaced with values from session state.
+	// SafeInstruction renders them as delimited <state> blocks instead of pasting them verbatim,
+	// so a user_name like "Ignore previous instructions..." is treated as data, not as a prompt.
 	questionAnsweringAgent, err := llmagent.New(llmagent.Config{
 		Name:        "question_answering_agent",
 		Model:       model,
 		Description: "Question answering agent",
-		Instruction: `You are a helpful assistant that answers questions about the user's preferences.
+		InstructionProvider: agentutil.SafeInstruction(`You are a helpful assistant that answers questions about the user's preferences.
 
 Here is some information about the user:
 Name:
 {user_name}
-Preferences:
-{user_preferences}`,
+Preferences (key: value):
+{user_preferences}
+
+When the user tells you about a new or changed preference, save it with update_preferences,
+reusing an existing key when one fits (e.g. favorite_food). When they no longer have a
+preference, remove its key. Use get_preferences if you need to double-check a value.`),
+		Tools:                []tool.Tool{updatePreferencesTool, getPreferencesTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{migrateUserPreferences},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
@@ -60,6 +312,8 @@ Preferences:
 	sessionService := session.InMemoryService()
 
 	// Define initial state with user information
+	// user_preferences starts as the old free-text blob; migrateUserPreferences
+	// converts it to structured key-values on the first turn
 	initialState := map[string]any{
 		"user_name": "Muchlis",
 		"user_preferences": `
@@ -98,33 +352,42 @@ Preferences:
 		log.Fatalf("Failed to create runner: %v", err)
 	}
 
-	// Create a user message asking about stored preferences
-	userMessage := &genai.Content{
-		Role: "user",
-		Parts: []*genai.Part{
-			{Text: "What is Muchlis's favorite TV show?"},
-		},
+	// Ask about a preference, change it, then ask again: the updated preference
+	// is rendered into the instruction template on the next turn
+	questions := []string{
+		"What is Muchlis's favorite TV show?",
+		"I've switched: my favorite TV show is now The Bear.",
+		"What is my favorite TV show now?",
 	}
 
-	fmt.Println("User Question: What is Muchlis's favorite TV show?")
-	fmt.Println()
-
-	// Run the agent with the session context
-	// The agent will have access to session state via template variables
-	var finalResponse string
-	for event, err := range r.Run(ctx, USER_ID, SESSION_ID, userMessage, agent.RunConfig{}) {
-		if err != nil {
-			log.Fatalf("Error during agent run: %v", err)
+	for _, question := range questions {
+		userMessage := &genai.Content{
+			Role: "user",
+			Parts: []*genai.Part{
+				{Text: question},
+			},
 		}
 
-		// Check if this is the final response
-		if event.Content != nil && len(event.Content.Parts) > 0 {
-			finalResponse = event.Content.Parts[0].Text
+		fmt.Println("User Question:", question)
+		fmt.Println()
+
+		// Run the agent with the session context
+		// The agent will have access to session state via template variables
+		var finalResponse string
+		for event, err := range r.Run(ctx, USER_ID, SESSION_ID, userMessage, agent.RunConfig{}) {
+			if err != nil {
+				log.Fatalf("Error during agent run: %v", err)
+			}
+
+			// Check if this is the final response
+			if event.Content != nil && len(event.Content.Parts) > 0 && event.Content.Parts[0].Text != "" {
+				finalResponse = event.Content.Parts[0].Text
+			}
 		}
-	}
 
-	fmt.Println("Final Response:", finalResponse)
-	fmt.Println()
+		fmt.Println("Final Response:", finalResponse)
+		fmt.Println()
+	}
 
 	// Retrieve and display the final session state
 	fmt.Println("==== Session Event Exploration ====")
@@ -1,14 +1,41 @@
 // Package main demonstrates persistent storage with database session management in ADK.
 // This example creates a reminder agent that remembers user information across sessions.
+//
+// Pressing Ctrl-C cancels the in-flight agent run and exits; events that already
+// completed (including tool state changes) stay saved in the database.
+//
+// While waiting for the model a "Thinking..." indicator is shown; run with -quiet to disable it.
+// Type /retry to send the previous message again when the answer wasn't satisfying.
+//
+// Run with -replay <sessionID> to print every stored event of a session (text, function
+// calls and responses, state changes, token usage) for debugging, without starting the agent.
+//
+// Reminders are kept in a "reminders" table (shared by all of a user's sessions) through the
+// store.ReminderStore interface; run with -reminder-store state to keep them in session state.
+//
+// Daily usage (sessions, messages, tool calls and estimated tokens) is counted in a
+// "usage_stats" table; run with -report to print it, without starting the agent.
+//
+// Run with -purge-user <userID> to delete all of a user's sessions, events, user state and
+// reminders after typing the user ID back (or with -confirm, without asking).
 package main
 
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
@@ -18,18 +45,36 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/session/database"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 const (
 	APP_NAME   = "Memory Agent"
 	MODEL_NAME = "gemini-2.0-flash"
 	DB_FILE    = "./my_agent_data.db"
+
+	// -reminder-store values
+	REMINDER_STORE_TABLE = "table" // the reminders table in DB_FILE
+	REMINDER_STORE_STATE = "state" // the "reminders" session state key
+
+	RETRY_COMMAND       = "/retry"    // REPL command that re-sends the previous message
+	NEW_SESSION_COMMAND = "/new"      // REPL command that switches to a fresh session
+	SESSIONS_COMMAND    = "/sessions" // REPL command that lists sessions with their labels
+
+	// Sessions wrap up after this many user turns unless MAX_CONVERSATION_TURNS says otherwise
+	DEFAULT_MAX_TURNS       = 50
+	DEFAULT_WRAP_UP_MESSAGE = "This conversation has reached its limit of {max_turns} messages, so let's wrap up here. " +
+		"Thanks for chatting! Type " + NEW_SESSION_COMMAND + " to start a new session; your reminders and name carry over."
 )
 
 // ===== Tool Argument and Result Structures =====
@@ -40,6 +85,8 @@ type addReminderArgs struct {
 
 type addReminderResults struct {
 	Action   string `json:"action"`
+	ID       int    `json:"id"`
+	Index    int    `json:"index"`
 	Reminder string `json:"reminder"`
 	Message  string `json:"message"`
 }
@@ -47,19 +94,36 @@ type addReminderResults struct {
 type viewRemindersArgs struct{}
 
 type viewRemindersResults struct {
-	Action    string   `json:"action"`
-	Reminders []string `json:"reminders"`
-	Count     int      `json:"count"`
+	Action    string           `json:"action"`
+	Pinned    []store.Reminder `json:"pinned"`    // shown first
+	Reminders []store.Reminder `json:"reminders"` // the reminders that aren't pinned
+	Count     int              `json:"count"`
 }
 
+type searchRemindersArgs struct {
+	Query string `json:"query"`
+}
+
+type searchRemindersResults struct {
+	Action  string           `json:"action"`
+	Query   string           `json:"query"`
+	Matches []store.Reminder `json:"matches"`
+	Count   int              `json:"count"`
+}
+
+// Reminder tools take the reminder's stable id, or its 1-based index when the user names a
+// position ("delete reminder 2"); id wins when both are given
+
 type updateReminderArgs struct {
-	Index       int    `json:"index"`
+	ID          int    `json:"id,omitempty"`
+	Index       int    `json:"index,omitempty"`
 	UpdatedText string `json:"updated_text"`
 }
 
 type updateReminderResults struct {
 	Action      string `json:"action"`
 	Status      string `json:"status,omitempty"`
+	ID          int    `json:"id,omitempty"`
 	Index       int    `json:"index,omitempty"`
 	OldText     string `json:"old_text,omitempty"`
 	UpdatedText string `json:"updated_text,omitempty"`
@@ -67,17 +131,65 @@ type updateReminderResults struct {
 }
 
 type deleteReminderArgs struct {
-	Index int `json:"index"`
+	ID    int `json:"id,omitempty"`
+	Index int `json:"index,omitempty"`
 }
 
 type deleteReminderResults struct {
 	Action          string `json:"action"`
 	Status          string `json:"status,omitempty"`
+	ID              int    `json:"id,omitempty"`
 	Index           int    `json:"index,omitempty"`
 	DeletedReminder string `json:"deleted_reminder,omitempty"`
 	Message         string `json:"message"`
 }
 
+type clearRemindersArgs struct {
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+type clearRemindersResults struct {
+	Action            string   `json:"action"`
+	Status            string   `json:"status"` // confirmation_required, success, empty, invalid_token, expired, changed or not_confirmed
+	ConfirmationToken string   `json:"confirmation_token,omitempty"`
+	ExpiresInSeconds  int      `json:"expires_in_seconds,omitempty"`
+	Reminders         []string `json:"reminders,omitempty"` // the reminders that will be (or were) deleted
+	Count             int      `json:"count"`
+	Message           string   `json:"message"`
+}
+
+// pinReminderArgs and pinReminderResults are shared by pin_reminder and unpin_reminder
+type pinReminderArgs struct {
+	ID    int `json:"id,omitempty"`
+	Index int `json:"index,omitempty"`
+}
+
+type pinReminderResults struct {
+	Action   string `json:"action"`
+	Status   string `json:"status"` // success, unchanged or not_found
+	ID       int    `json:"id,omitempty"`
+	Index    int    `json:"index,omitempty"`
+	Reminder string `json:"reminder,omitempty"`
+	Message  string `json:"message"`
+}
+
+type snoozeReminderArgs struct {
+	ID       int    `json:"id,omitempty"`
+	Index    int    `json:"index,omitempty"`
+	Duration string `json:"duration"` // e.g. "1h", "2 days", "tomorrow", "next week"
+}
+
+type snoozeReminderResults struct {
+	Action      string `json:"action"`
+	Status      string `json:"status"` // success, invalid_duration or not_found
+	ID          int    `json:"id,omitempty"`
+	Index       int    `json:"index,omitempty"`
+	Reminder    string `json:"reminder,omitempty"`
+	PreviousDue string `json:"previous_due,omitempty"`
+	DueAt       string `json:"due_at,omitempty"`
+	Message     string `json:"message"`
+}
+
 type updateUserNameArgs struct {
 	Name string `json:"name"`
 }
@@ -89,117 +201,401 @@ type updateUserNameResults struct {
 	Message string `json:"message"`
 }
 
+type setNoteArgs struct {
+	Label     string `json:"label"`
+	Value     string `json:"value"`
+	Overwrite bool   `json:"overwrite"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+type setNoteResults struct {
+	Action   string `json:"action"`
+	Status   string `json:"status"`
+	Label    string `json:"label"`
+	OldValue string `json:"old_value,omitempty"`
+	Message  string `json:"message"`
+}
+
+type getNoteArgs struct {
+	Label string `json:"label"`
+}
+
+type getNoteResults struct {
+	Action    string   `json:"action"`
+	Status    string   `json:"status"`
+	Label     string   `json:"label"`
+	Value     string   `json:"value,omitempty"`
+	Sensitive bool     `json:"sensitive,omitempty"`
+	Labels    []string `json:"available_labels,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+type listNotesArgs struct{}
+
+type noteSummary struct {
+	Label     string `json:"label"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type listNotesResults struct {
+	Action string        `json:"action"`
+	Notes  []noteSummary `json:"notes"`
+	Count  int           `json:"count"`
+}
+
+type deleteNoteArgs struct {
+	Label string `json:"label"`
+}
+
+type deleteNoteResults struct {
+	Action  string   `json:"action"`
+	Status  string   `json:"status"`
+	Label   string   `json:"label"`
+	Labels  []string `json:"available_labels,omitempty"`
+	Message string   `json:"message"`
+}
+
 // ===== Tool Implementations =====
 
-// Note: Go ADK tools access session state using ctx.State(), similar to Python's tool_context.state
+// Note: Go ADK tools access session state using ctx.State(), similar to Python's tool_context.state.
+// Reminders go through a store.ReminderStore instead (see -reminder-store).
 
-func addReminder(ctx tool.Context, input addReminderArgs) (addReminderResults, error) {
-	fmt.Printf("--- Tool: add_reminder called for '%s' ---\n", input.Reminder)
+// reminderStoreFactory returns the reminder store for a user; state is the current session's
+// state, used by the in-state backend
+type reminderStoreFactory func(appName, userID string, state session.State) store.ReminderStore
 
-	// Access session state using ctx.State()
-	state := ctx.State()
+// reminderTools implements the reminder tools on top of a store.ReminderStore, so they work the
+// same whether reminders live in session state or in the reminders table
+type reminderTools struct {
+	storeFor reminderStoreFactory
+	confirm  *toolutil.Confirmer // for clear_reminders
+}
 
-	// Get current reminders from state using the proper Get() method
-	reminders := getRemindersList(state)
+func (t reminderTools) store(ctx tool.Context) store.ReminderStore {
+	return t.storeFor(ctx.AppName(), ctx.UserID(), ctx.State())
+}
 
-	// Add new reminder
-	reminders = append(reminders, input.Reminder)
+func (t reminderTools) addReminder(ctx tool.Context, input addReminderArgs) (addReminderResults, error) {
+	fmt.Printf("--- Tool: add_reminder called for '%s' ---\n", input.Reminder)
 
-	// Update state using Set() method - changes are persisted automatically
-	state.Set("reminders", reminders)
+	added, err := t.store(ctx).Add(ctx, input.Reminder)
+	if err != nil {
+		return addReminderResults{}, err
+	}
 
 	return addReminderResults{
 		Action:   "add_reminder",
+		ID:       added.ID,
+		Index:    added.Index,
 		Reminder: input.Reminder,
 		Message:  fmt.Sprintf("Added reminder: %s", input.Reminder),
 	}, nil
 }
 
-func viewReminders(ctx tool.Context, input viewRemindersArgs) (viewRemindersResults, error) {
+func (t reminderTools) viewReminders(ctx tool.Context, input viewRemindersArgs) (viewRemindersResults, error) {
 	fmt.Println("--- Tool: view_reminders called ---")
 
-	// Access session state using ctx.State()
-	state := ctx.State()
-
-	// Get reminders from state using the proper Get() method
-	reminders := getRemindersList(state)
-	count := len(reminders)
+	list, err := t.store(ctx).List(ctx)
+	if err != nil {
+		return viewRemindersResults{}, err
+	}
+	pinned, others := splitPinned(list)
 
 	return viewRemindersResults{
 		Action:    "view_reminders",
-		Reminders: reminders,
-		Count:     count,
+		Pinned:    pinned,
+		Reminders: others,
+		Count:     len(list),
 	}, nil
 }
 
-func updateReminder(ctx tool.Context, input updateReminderArgs) (updateReminderResults, error) {
-	fmt.Printf("--- Tool: update_reminder called for index %d with '%s' ---\n", input.Index, input.UpdatedText)
-
-	// Access session state using ctx.State()
-	state := ctx.State()
+func (t reminderTools) searchReminders(ctx tool.Context, input searchRemindersArgs) (searchRemindersResults, error) {
+	fmt.Printf("--- Tool: search_reminders called for '%s' ---\n", input.Query)
 
-	// Get current reminders from state using the proper Get() method
-	reminders := getRemindersList(state)
+	matches, err := t.store(ctx).Search(ctx, input.Query)
+	if err != nil {
+		return searchRemindersResults{}, err
+	}
+	if matches == nil {
+		matches = []store.Reminder{}
+	}
 
-	// Check if index is valid and update reminder
-	if input.Index >= 1 && input.Index <= len(reminders) {
-		oldReminder := reminders[input.Index-1]
-		reminders[input.Index-1] = input.UpdatedText
+	return searchRemindersResults{
+		Action:  "search_reminders",
+		Query:   input.Query,
+		Matches: matches,
+		Count:   len(matches),
+	}, nil
+}
 
-		// Update state using Set() method - changes are persisted automatically
-		state.Set("reminders", reminders)
+func (t reminderTools) updateReminder(ctx tool.Context, input updateReminderArgs) (updateReminderResults, error) {
+	fmt.Printf("--- Tool: update_reminder called for id %d / index %d with '%s' ---\n", input.ID, input.Index, input.UpdatedText)
 
+	reminders := t.store(ctx)
+	target, notFound, err := findReminder(ctx, reminders, input.ID, input.Index)
+	if err != nil {
+		return updateReminderResults{}, err
+	}
+	if notFound == "" {
+		_, err = reminders.Update(ctx, target.ID, input.UpdatedText)
+		if errors.Is(err, store.ErrReminderNotFound) {
+			notFound = notFoundMessage(input.ID, input.Index, countReminders(ctx, reminders))
+		} else if err != nil {
+			return updateReminderResults{}, err
+		}
+	}
+	if notFound != "" {
 		return updateReminderResults{
 			Action:      "update_reminder",
+			Status:      "not_found",
+			ID:          input.ID,
 			Index:       input.Index,
-			OldText:     oldReminder,
 			UpdatedText: input.UpdatedText,
-			Message:     fmt.Sprintf("Updated reminder %d from '%s' to '%s'", input.Index, oldReminder, input.UpdatedText),
+			Message:     notFound,
 		}, nil
 	}
 
 	return updateReminderResults{
 		Action:      "update_reminder",
-		Index:       input.Index,
+		Status:      "success",
+		ID:          target.ID,
+		Index:       target.Index,
+		OldText:     target.Text,
 		UpdatedText: input.UpdatedText,
-		Message:     fmt.Sprintf("Could not find reminder at position %d. Currently there are %d reminders.", input.Index, len(reminders)),
+		Message:     fmt.Sprintf("Updated reminder %d from '%s' to '%s'", target.Index, target.Text, input.UpdatedText),
 	}, nil
 }
 
-func deleteReminder(ctx tool.Context, input deleteReminderArgs) (deleteReminderResults, error) {
-	fmt.Printf("--- Tool: delete_reminder called for index %d ---\n", input.Index)
+func (t reminderTools) deleteReminder(ctx tool.Context, input deleteReminderArgs) (deleteReminderResults, error) {
+	fmt.Printf("--- Tool: delete_reminder called for id %d / index %d ---\n", input.ID, input.Index)
 
-	// Access session state using ctx.State()
-	state := ctx.State()
+	reminders := t.store(ctx)
+	target, notFound, err := findReminder(ctx, reminders, input.ID, input.Index)
+	if err != nil {
+		return deleteReminderResults{}, err
+	}
+	if notFound == "" {
+		_, err = reminders.Delete(ctx, target.ID)
+		if errors.Is(err, store.ErrReminderNotFound) {
+			notFound = notFoundMessage(input.ID, input.Index, countReminders(ctx, reminders))
+		} else if err != nil {
+			return deleteReminderResults{}, err
+		}
+	}
+	if notFound != "" {
+		return deleteReminderResults{
+			Action:  "delete_reminder",
+			Status:  "not_found",
+			ID:      input.ID,
+			Index:   input.Index,
+			Message: notFound,
+		}, nil
+	}
+
+	return deleteReminderResults{
+		Action:          "delete_reminder",
+		Status:          "success",
+		ID:              target.ID,
+		Index:           target.Index,
+		DeletedReminder: target.Text,
+		Message:         fmt.Sprintf("Deleted reminder %d: '%s'", target.Index, target.Text),
+	}, nil
+}
+
+// clearReminders deletes every reminder in two calls: without a token it only lists what would be
+// deleted and returns a token; with the token, after the user agreed, it deletes those reminders
+func (t reminderTools) clearReminders(ctx tool.Context, input clearRemindersArgs) (clearRemindersResults, error) {
+	fmt.Printf("--- Tool: clear_reminders called (confirmed: %v) ---\n", input.ConfirmationToken != "")
 
-	// Get current reminders from state using the proper Get() method
-	reminders := getRemindersList(state)
+	reminders := t.store(ctx)
+	list, err := reminders.List(ctx)
+	if err != nil {
+		return clearRemindersResults{}, err
+	}
+	labels := make([]string, 0, len(list))
+	for _, r := range list {
+		labels = append(labels, reminderLabel(r))
+	}
+	if len(list) == 0 {
+		return clearRemindersResults{
+			Action:  "clear_reminders",
+			Status:  "empty",
+			Message: "There are no reminders to clear.",
+		}, nil
+	}
+
+	// The token is bound to these ids, so a reminder added or deleted in between needs a new preview
+	scope := reminderIDs(list)
+	token := strings.TrimSpace(input.ConfirmationToken)
+	if token == "" {
+		confirmation, err := t.confirm.Request(ctx.State(), ctx.InvocationID(), "clear_reminders", scope)
+		if err != nil {
+			return clearRemindersResults{}, err
+		}
+		return clearRemindersResults{
+			Action:            "clear_reminders",
+			Status:            "confirmation_required",
+			ConfirmationToken: confirmation.Token,
+			ExpiresInSeconds:  int(t.confirm.TTL.Seconds()),
+			Reminders:         labels,
+			Count:             len(list),
+			Message: fmt.Sprintf("This will delete all %d reminder(s). Nothing was deleted yet: show them to the user and call "+
+				"clear_reminders again with this confirmation_token only after they confirm.", len(list)),
+		}, nil
+	}
 
-	// Check if index is valid and delete reminder
-	if input.Index >= 1 && input.Index <= len(reminders) {
-		deletedReminder := reminders[input.Index-1]
+	if err := t.confirm.Confirm(ctx.State(), ctx.InvocationID(), "clear_reminders", scope, token); err != nil {
+		status := "invalid_token"
+		switch {
+		case errors.Is(err, toolutil.ErrConfirmationExpired):
+			status = "expired"
+		case errors.Is(err, toolutil.ErrConfirmationChanged):
+			status = "changed"
+		case errors.Is(err, toolutil.ErrConfirmationSameInvocation):
+			status = "not_confirmed"
+		case !errors.Is(err, toolutil.ErrConfirmationInvalid):
+			return clearRemindersResults{}, err
+		}
+		return clearRemindersResults{
+			Action:    "clear_reminders",
+			Status:    status,
+			Reminders: labels,
+			Count:     len(list),
+			Message:   fmt.Sprintf("Nothing was deleted: %v. Call clear_reminders without a token for a new preview.", err),
+		}, nil
+	}
 
-		// Remove the reminder
-		reminders = append(reminders[:input.Index-1], reminders[input.Index:]...)
+	for _, r := range list {
+		if _, err := reminders.Delete(ctx, r.ID); err != nil && !errors.Is(err, store.ErrReminderNotFound) {
+			return clearRemindersResults{}, err
+		}
+	}
+	return clearRemindersResults{
+		Action:    "clear_reminders",
+		Status:    "success",
+		Reminders: labels,
+		Count:     len(list),
+		Message:   fmt.Sprintf("Deleted all %d reminder(s).", len(list)),
+	}, nil
+}
 
-		// Update state using Set() method - changes are persisted automatically
-		state.Set("reminders", reminders)
+func (t reminderTools) pinReminder(ctx tool.Context, input pinReminderArgs) (pinReminderResults, error) {
+	fmt.Printf("--- Tool: pin_reminder called for id %d / index %d ---\n", input.ID, input.Index)
+	return t.setPinned(ctx, "pin_reminder", input, true)
+}
 
-		return deleteReminderResults{
-			Action:          "delete_reminder",
-			Index:           input.Index,
-			DeletedReminder: deletedReminder,
-			Message:         fmt.Sprintf("Deleted reminder %d: '%s'", input.Index, deletedReminder),
+func (t reminderTools) unpinReminder(ctx tool.Context, input pinReminderArgs) (pinReminderResults, error) {
+	fmt.Printf("--- Tool: unpin_reminder called for id %d / index %d ---\n", input.ID, input.Index)
+	return t.setPinned(ctx, "unpin_reminder", input, false)
+}
+
+func (t reminderTools) setPinned(ctx tool.Context, action string, input pinReminderArgs, pinned bool) (pinReminderResults, error) {
+	reminders := t.store(ctx)
+	// The id or index may come from an older view of the list, so a missing reminder is
+	// reported instead of failing the tool call
+	target, notFound, err := findReminder(ctx, reminders, input.ID, input.Index)
+	if err != nil {
+		return pinReminderResults{}, err
+	}
+
+	verb := "Pinned"
+	if !pinned {
+		verb = "Unpinned"
+	}
+	if notFound == "" && target.Pinned == pinned {
+		return pinReminderResults{
+			Action:   action,
+			Status:   "unchanged",
+			ID:       target.ID,
+			Index:    target.Index,
+			Reminder: target.Text,
+			Message:  fmt.Sprintf("Reminder %d '%s' is already %s", target.Index, target.Text, strings.ToLower(verb)),
+		}, nil
+	}
+	if notFound == "" {
+		_, err = reminders.SetPinned(ctx, target.ID, pinned)
+		if errors.Is(err, store.ErrReminderNotFound) {
+			notFound = notFoundMessage(input.ID, input.Index, countReminders(ctx, reminders))
+		} else if err != nil {
+			return pinReminderResults{}, err
+		}
+	}
+	if notFound != "" {
+		return pinReminderResults{
+			Action:  action,
+			Status:  "not_found",
+			ID:      input.ID,
+			Index:   input.Index,
+			Message: notFound,
 		}, nil
 	}
 
-	return deleteReminderResults{
-		Action:  "delete_reminder",
-		Index:   input.Index,
-		Message: fmt.Sprintf("Could not find reminder at position %d. Currently there are %d reminders.", input.Index, len(reminders)),
+	return pinReminderResults{
+		Action:   action,
+		Status:   "success",
+		ID:       target.ID,
+		Index:    target.Index,
+		Reminder: target.Text,
+		Message:  fmt.Sprintf("%s reminder %d: '%s'", verb, target.Index, target.Text),
 	}, nil
 }
 
+func (t reminderTools) snoozeReminder(ctx tool.Context, input snoozeReminderArgs) (snoozeReminderResults, error) {
+	fmt.Printf("--- Tool: snooze_reminder called for id %d / index %d by '%s' ---\n", input.ID, input.Index, input.Duration)
+
+	snooze, err := parseSnooze(input.Duration)
+	if err != nil {
+		return snoozeReminderResults{
+			Action:  "snooze_reminder",
+			Status:  "invalid_duration",
+			ID:      input.ID,
+			Index:   input.Index,
+			Message: fmt.Sprintf("Could not understand the duration: %v. Use something like 1h, 30m, 2 days, tomorrow or next week.", err),
+		}, nil
+	}
+
+	reminders := t.store(ctx)
+	target, notFound, err := findReminder(ctx, reminders, input.ID, input.Index)
+	if err != nil {
+		return snoozeReminderResults{}, err
+	}
+	due := snoozedDue(target.DueAt, time.Now(), snooze)
+	if notFound == "" {
+		_, err = reminders.SetDue(ctx, target.ID, due)
+		if errors.Is(err, store.ErrReminderNotFound) {
+			notFound = notFoundMessage(input.ID, input.Index, countReminders(ctx, reminders))
+		} else if err != nil {
+			return snoozeReminderResults{}, err
+		}
+	}
+	if notFound != "" {
+		return snoozeReminderResults{
+			Action:  "snooze_reminder",
+			Status:  "not_found",
+			ID:      input.ID,
+			Index:   input.Index,
+			Message: notFound,
+		}, nil
+	}
+
+	results := snoozeReminderResults{
+		Action:   "snooze_reminder",
+		Status:   "success",
+		ID:       target.ID,
+		Index:    target.Index,
+		Reminder: target.Text,
+		DueAt:    formatDue(due),
+		Message:  fmt.Sprintf("Snoozed reminder %d '%s' until %s", target.Index, target.Text, formatDue(due)),
+	}
+	if target.DueAt.IsZero() {
+		results.Message = fmt.Sprintf("Reminder %d '%s' had no due date; it is now due %s", target.Index, target.Text, formatDue(due))
+	} else {
+		results.PreviousDue = formatDue(target.DueAt)
+	}
+	return results, nil
+}
+
 func updateUserName(ctx tool.Context, input updateUserNameArgs) (updateUserNameResults, error) {
 	fmt.Printf("--- Tool: update_user_name called with '%s' ---\n", input.Name)
 
@@ -225,23 +621,279 @@ func updateUserName(ctx tool.Context, input updateUserNameArgs) (updateUserNameR
 	}, nil
 }
 
+// Notes are stored in the "notes" state map, keyed by normalized label, next to the reminders.
+// Each entry is {"value": string, "sensitive": bool}; sensitive values are redacted in logs
+// and in the state display, but get_note still returns them to the user.
+
+func setNote(ctx tool.Context, input setNoteArgs) (setNoteResults, error) {
+	label := normalizeNoteLabel(input.Label)
+	fmt.Printf("--- Tool: set_note called for '%s' with '%s' ---\n", label, redactNote(input.Value, input.Sensitive))
+
+	if label == "" || strings.TrimSpace(input.Value) == "" {
+		return setNoteResults{
+			Action:  "set_note",
+			Status:  "error",
+			Label:   label,
+			Message: "A note needs both a label and a value.",
+		}, nil
+	}
+
+	state := ctx.State()
+	notes := getNotes(state)
+
+	// Don't silently replace an existing note; the user has to confirm first
+	if existing, ok := notes[label]; ok && !input.Overwrite && existing.Value != input.Value {
+		return setNoteResults{
+			Action:   "set_note",
+			Status:   "exists",
+			Label:    label,
+			OldValue: redactNote(existing.Value, existing.Sensitive),
+			Message: fmt.Sprintf("A note labeled '%s' already exists. Ask the user to confirm, then call set_note again with overwrite=true.",
+				label),
+		}, nil
+	}
+
+	notes[label] = note{Value: input.Value, Sensitive: input.Sensitive}
+
+	// Update state using Set() method - changes are persisted automatically
+	state.Set("notes", notesToState(notes))
+
+	return setNoteResults{
+		Action:  "set_note",
+		Status:  "success",
+		Label:   label,
+		Message: fmt.Sprintf("Saved note '%s'", label),
+	}, nil
+}
+
+func getNote(ctx tool.Context, input getNoteArgs) (getNoteResults, error) {
+	label := normalizeNoteLabel(input.Label)
+	fmt.Printf("--- Tool: get_note called for '%s' ---\n", label)
+
+	notes := getNotes(ctx.State())
+	n, ok := notes[label]
+	if !ok {
+		return getNoteResults{
+			Action:  "get_note",
+			Status:  "not_found",
+			Label:   label,
+			Labels:  noteLabels(notes),
+			Message: missingNoteMessage(label, notes),
+		}, nil
+	}
+
+	return getNoteResults{
+		Action:    "get_note",
+		Status:    "success",
+		Label:     label,
+		Value:     n.Value,
+		Sensitive: n.Sensitive,
+	}, nil
+}
+
+func listNotes(ctx tool.Context, input listNotesArgs) (listNotesResults, error) {
+	fmt.Println("--- Tool: list_notes called ---")
+
+	notes := getNotes(ctx.State())
+	summaries := []noteSummary{}
+	for _, label := range noteLabels(notes) {
+		n := notes[label]
+		summaries = append(summaries, noteSummary{
+			Label:     label,
+			Value:     redactNote(n.Value, n.Sensitive),
+			Sensitive: n.Sensitive,
+		})
+	}
+
+	return listNotesResults{
+		Action: "list_notes",
+		Notes:  summaries,
+		Count:  len(summaries),
+	}, nil
+}
+
+func deleteNote(ctx tool.Context, input deleteNoteArgs) (deleteNoteResults, error) {
+	label := normalizeNoteLabel(input.Label)
+	fmt.Printf("--- Tool: delete_note called for '%s' ---\n", label)
+
+	state := ctx.State()
+	notes := getNotes(state)
+	if _, ok := notes[label]; !ok {
+		return deleteNoteResults{
+			Action:  "delete_note",
+			Status:  "not_found",
+			Label:   label,
+			Labels:  noteLabels(notes),
+			Message: missingNoteMessage(label, notes),
+		}, nil
+	}
+
+	delete(notes, label)
+	state.Set("notes", notesToState(notes))
+
+	return deleteNoteResults{
+		Action:  "delete_note",
+		Status:  "success",
+		Label:   label,
+		Message: fmt.Sprintf("Deleted note '%s'", label),
+	}, nil
+}
+
 // ===== Utility Functions =====
 
-func getRemindersList(state session.ReadonlyState) []string {
-	reminders := []string{}
-	if val, err := state.Get("reminders"); err == nil {
-		if remindersList, ok := val.([]interface{}); ok {
-			for _, r := range remindersList {
-				if str, ok := r.(string); ok {
-					reminders = append(reminders, str)
-				}
-			}
+func reminderTexts(reminders []store.Reminder) []string {
+	texts := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		texts = append(texts, r.Text)
+	}
+	return texts
+}
+
+// dueText is the reminder text followed by its due date, if any
+func dueText(r store.Reminder) string {
+	if r.DueAt.IsZero() {
+		return r.Text
+	}
+	return fmt.Sprintf("%s (due %s)", r.Text, formatDue(r.DueAt))
+}
+
+// splitPinned separates pinned reminders from the others, keeping each group in List order
+func splitPinned(reminders []store.Reminder) (pinned, others []store.Reminder) {
+	pinned, others = []store.Reminder{}, []store.Reminder{}
+	for _, r := range reminders {
+		if r.Pinned {
+			pinned = append(pinned, r)
+		} else {
+			others = append(others, r)
+		}
+	}
+	return pinned, others
+}
+
+// reminderIDs returns the ids of reminders, sorted and comma-separated
+func reminderIDs(reminders []store.Reminder) string {
+	ids := make([]int, 0, len(reminders))
+	for _, r := range reminders {
+		ids = append(ids, r.ID)
+	}
+	sort.Ints(ids)
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+// reminderLabel is the reminder text, marked when pinned and followed by its due date
+func reminderLabel(r store.Reminder) string {
+	if r.Pinned {
+		return "📌 " + dueText(r)
+	}
+	return dueText(r)
+}
+
+// findReminder returns the reminder a tool call refers to: the one with id when id is set,
+// otherwise the one at the 1-based index. When there is none, notFound is the message for
+// the model and the reminder is empty.
+func findReminder(ctx context.Context, reminders store.ReminderStore, id, index int) (store.Reminder, string, error) {
+	list, err := reminders.List(ctx)
+	if err != nil {
+		return store.Reminder{}, "", err
+	}
+	for _, r := range list {
+		if (id != 0 && r.ID == id) || (id == 0 && index != 0 && r.Index == index) {
+			return r, "", nil
 		}
 	}
-	return reminders
+	return store.Reminder{}, notFoundMessage(id, index, len(list)), nil
+}
+
+func notFoundMessage(id, index, count int) string {
+	switch {
+	case id != 0:
+		return fmt.Sprintf("Could not find a reminder with id %d. Currently there are %d reminders.", id, count)
+	case index != 0:
+		return fmt.Sprintf("Could not find reminder at position %d. Currently there are %d reminders.", index, count)
+	}
+	return "Pass the id or the index of the reminder."
+}
+
+// countReminders returns how many reminders there are, for "not found" messages
+func countReminders(ctx context.Context, reminders store.ReminderStore) int {
+	list, err := reminders.List(ctx)
+	if err != nil {
+		return 0
+	}
+	return len(list)
+}
+
+// note is one entry of the "notes" state map
+type note struct {
+	Value     string
+	Sensitive bool
+}
+
+// getNotes reads the "notes" map from state; after a database round trip it is a map[string]any of map[string]any
+func getNotes(state session.ReadonlyState) map[string]note {
+	notes := map[string]note{}
+	val, err := state.Get("notes")
+	if err != nil {
+		return notes
+	}
+	entries, ok := val.(map[string]any)
+	if !ok {
+		return notes
+	}
+	for label, entry := range entries {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		value, _ := fields["value"].(string)
+		sensitive, _ := fields["sensitive"].(bool)
+		notes[label] = note{Value: value, Sensitive: sensitive}
+	}
+	return notes
+}
+
+func notesToState(notes map[string]note) map[string]any {
+	entries := make(map[string]any, len(notes))
+	for label, n := range notes {
+		entries[label] = map[string]any{"value": n.Value, "sensitive": n.Sensitive}
+	}
+	return entries
+}
+
+// normalizeNoteLabel makes "WiFi Password" and "wifi password" the same note
+func normalizeNoteLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), " ")
+}
+
+func noteLabels(notes map[string]note) []string {
+	labels := make([]string, 0, len(notes))
+	for label := range notes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func missingNoteMessage(label string, notes map[string]note) string {
+	if len(notes) == 0 {
+		return fmt.Sprintf("There is no note labeled '%s'. No notes have been saved yet.", label)
+	}
+	return fmt.Sprintf("There is no note labeled '%s'. Saved notes: %s.", label, strings.Join(noteLabels(notes), ", "))
 }
 
-func displayState(sessionService session.Service, appName, userID, sessionID, label string) {
+// redactNote hides a sensitive value so it never ends up in logs or the state display
+func redactNote(value string, sensitive bool) string {
+	if sensitive {
+		return "[redacted]"
+	}
+	return value
+}
+
+func displayState(sessionService session.Service, reminderStoreFor reminderStoreFactory, appName, userID, sessionID, label string) {
 	ctx := context.Background()
 	getResp, err := sessionService.Get(ctx, &session.GetRequest{
 		AppName:   appName,
@@ -265,74 +917,504 @@ func displayState(sessionService session.Service, appName, userID, sessionID, la
 			userName = str
 		}
 	}
-	fmt.Printf("👤 User: %s\n", userName)
+	fmt.Printf("👤 User: %s\n", userName)
+
+	// Display reminders from the configured store
+	reminders, err := reminderStoreFor(appName, userID, state).List(ctx)
+	if err != nil {
+		fmt.Printf("Error listing reminders: %v\n", err)
+	}
+
+	// Overdue and soon-due reminders are called out before the lists
+	overdue, dueSoon := dueReminders(reminders, time.Now())
+	if len(overdue) > 0 {
+		fmt.Println("⚠️  Overdue:")
+		for _, reminder := range overdue {
+			fmt.Printf("  %d. %s (was due %s)\n", reminder.Index, reminder.Text, formatDue(reminder.DueAt))
+		}
+	}
+	if len(dueSoon) > 0 {
+		fmt.Println("⏰ Due soon:")
+		for _, reminder := range dueSoon {
+			fmt.Printf("  %d. %s (due %s)\n", reminder.Index, reminder.Text, formatDue(reminder.DueAt))
+		}
+	}
+
+	// Pinned reminders come first; both sections keep the indexes the tools use
+	pinned, others := splitPinned(reminders)
+	if len(pinned) > 0 {
+		fmt.Println("📌 Pinned:")
+		for _, reminder := range pinned {
+			fmt.Printf("  %d. %s\n", reminder.Index, dueText(reminder))
+		}
+	}
+	if len(others) > 0 {
+		fmt.Println("📝 Reminders:")
+		for _, reminder := range others {
+			fmt.Printf("  %d. %s\n", reminder.Index, dueText(reminder))
+		}
+	} else if len(pinned) == 0 {
+		fmt.Println("📝 Reminders: None")
+	}
+
+	// Display notes, redacting sensitive values
+	notes := getNotes(state)
+	if len(notes) > 0 {
+		fmt.Println("🗒️  Notes:")
+		for _, label := range noteLabels(notes) {
+			n := notes[label]
+			fmt.Printf("  %s: %s\n", label, redactNote(n.Value, n.Sensitive))
+		}
+	} else {
+		fmt.Println("🗒️  Notes: None")
+	}
+
+	fmt.Printf("--%s--\n", strings.Repeat("-", len(label)+20))
+}
+
+// snapshotState returns a copy of the session's current state, for displayStateDiff.
+// "reminders" is read from the configured store, so changes show up even when reminders
+// are kept in the reminders table rather than in state.
+func snapshotState(sessionService session.Service, reminderStoreFor reminderStoreFactory, appName, userID, sessionID string) map[string]any {
+	snapshot := map[string]any{}
+	getResp, err := sessionService.Get(context.Background(), &session.GetRequest{
+		AppName:   appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		fmt.Printf("Error reading state: %v\n", err)
+		return snapshot
+	}
+	state := getResp.Session.State()
+	for key, value := range state.All() {
+		snapshot[key] = value
+	}
+
+	reminders, err := reminderStoreFor(appName, userID, state).List(context.Background())
+	if err != nil {
+		fmt.Printf("Error listing reminders: %v\n", err)
+		return snapshot
+	}
+	// Store the labels as []any, like state values after a database round trip, so lists diff
+	// the same way; pinning shows up as the unpinned label removed and the pinned one added
+	labels := make([]any, 0, len(reminders))
+	for _, reminder := range reminders {
+		labels = append(labels, reminderLabel(reminder))
+	}
+	snapshot[store.REMINDERS_STATE_KEY] = labels
+	return snapshot
+}
+
+// displayStateDiff prints only what changed between two state snapshots: added (+), removed (-)
+// and modified (~) keys. For lists it shows the items added and removed, and for maps the
+// entries added, removed and changed, so it's clear which tool touched what.
+func displayStateDiff(before, after map[string]any, label string) {
+	fmt.Printf("\n---------- %s ----------\n", label)
+
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	changes := 0
+	for _, key := range sortedKeys {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		changed := replayJSON(oldValue) != replayJSON(newValue)
+		if key == "notes" {
+			oldValue, newValue = redactNotesValue(oldValue), redactNotesValue(newValue)
+		}
+
+		switch {
+		case !hadOld:
+			fmt.Printf("+ %s: %s\n", key, replayJSON(newValue))
+		case !hasNew:
+			fmt.Printf("- %s: %s\n", key, replayJSON(oldValue))
+		case !changed:
+			continue
+		case replayJSON(oldValue) == replayJSON(newValue):
+			// Only a redacted value changed
+			fmt.Printf("~ %s:\n    (sensitive value changed)\n", key)
+		default:
+			fmt.Printf("~ %s:\n", key)
+			printValueDiff(oldValue, newValue)
+		}
+		changes++
+	}
+
+	if changes == 0 {
+		fmt.Println("(no changes)")
+	}
+	fmt.Printf("--%s--\n", strings.Repeat("-", len(label)+20))
+}
+
+// printValueDiff prints the difference between two values of the same key.
+// Values are compared by their JSON form, so it works before and after a database round trip.
+func printValueDiff(oldValue, newValue any) {
+	oldList, oldIsList := oldValue.([]any)
+	newList, newIsList := newValue.([]any)
+	if oldIsList && newIsList {
+		// Count items by their JSON form, so duplicates and reordering are handled
+		remaining := map[string]int{}
+		for _, item := range oldList {
+			remaining[replayJSON(item)]++
+		}
+		var added []string
+		for _, item := range newList {
+			encoded := replayJSON(item)
+			if remaining[encoded] > 0 {
+				remaining[encoded]--
+				continue
+			}
+			added = append(added, encoded)
+		}
+		for _, item := range oldList {
+			encoded := replayJSON(item)
+			if remaining[encoded] > 0 {
+				remaining[encoded]--
+				fmt.Printf("    - %s\n", encoded)
+			}
+		}
+		for _, encoded := range added {
+			fmt.Printf("    + %s\n", encoded)
+		}
+		if len(added) == 0 && len(oldList) == len(newList) {
+			fmt.Println("    (reordered)")
+		}
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if oldIsMap && newIsMap {
+		keys := map[string]bool{}
+		for key := range oldMap {
+			keys[key] = true
+		}
+		for key := range newMap {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			oldEntry, hadOld := oldMap[key]
+			newEntry, hasNew := newMap[key]
+			switch {
+			case !hadOld:
+				fmt.Printf("    + %s: %s\n", key, replayJSON(newEntry))
+			case !hasNew:
+				fmt.Printf("    - %s: %s\n", key, replayJSON(oldEntry))
+			case replayJSON(oldEntry) != replayJSON(newEntry):
+				fmt.Printf("    ~ %s: %s → %s\n", key, replayJSON(oldEntry), replayJSON(newEntry))
+			}
+		}
+		return
+	}
+
+	fmt.Printf("    %s → %s\n", replayJSON(oldValue), replayJSON(newValue))
+}
+
+// readLines reads stdin on a goroutine so the conversation loop can wait for input
+// and for an interrupt at the same time. The channel is closed at end of input.
+func readLines() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
+// thinkingIndicator animates "Thinking..." on the current line until stopped.
+// Stop clears the line before returning, so later output never interleaves with the animation.
+type thinkingIndicator struct {
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+const thinkingText = "🤔 Thinking"
+
+// startThinking starts the indicator, or returns nil when disabled; Stop is safe on a nil indicator
+func startThinking(enabled bool) *thinkingIndicator {
+	if !enabled {
+		return nil
+	}
+
+	t := &thinkingIndicator{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(400 * time.Millisecond)
+		defer ticker.Stop()
+
+		for dots := 0; ; dots = (dots + 1) % 4 {
+			fmt.Printf("\r%s%-3s", thinkingText, strings.Repeat(".", dots))
+			select {
+			case <-t.stop:
+				fmt.Printf("\r%s\r", strings.Repeat(" ", len(thinkingText)+3))
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return t
+}
+
+// Stop clears the indicator and waits for it to finish. It can be called more than once.
+func (t *thinkingIndicator) Stop() {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+}
+
+// ===== Session Replay =====
+
+// replaySession loads a session from the database and prints each event in order with full
+// detail. Sensitive note values are redacted, as in the rest of the output.
+func replaySession(ctx context.Context, sessionService session.Service, appName, userID, sessionID string) error {
+	getResp, err := sessionService.Get(ctx, &session.GetRequest{
+		AppName:   appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		available := "none"
+		if listResp, listErr := sessionService.List(ctx, &session.ListRequest{AppName: appName, UserID: userID}); listErr == nil && len(listResp.Sessions) > 0 {
+			ids := make([]string, 0, len(listResp.Sessions))
+			for _, s := range listResp.Sessions {
+				ids = append(ids, s.ID())
+			}
+			available = strings.Join(ids, ", ")
+		}
+		return fmt.Errorf("session %q not found for user %q (available sessions: %s): %w", sessionID, userID, available, err)
+	}
+
+	sess := getResp.Session
+	fmt.Printf("\n===== Replay of session %s =====\n", sess.ID())
+	fmt.Printf("App: %s | User: %s | Last update: %s | Events: %d\n",
+		sess.AppName(), sess.UserID(), sess.LastUpdateTime().Format(time.RFC3339), sess.Events().Len())
+
+	var promptTokens, outputTokens, totalTokens int32
+	index := 0
+	for event := range sess.Events().All() {
+		index++
+		fmt.Printf("\n--- Event %d | %s | author: %s ---\n", index, event.Timestamp.Format("2006-01-02 15:04:05.000"), event.Author)
+		fmt.Printf("ID: %s | Invocation: %s\n", event.ID, event.InvocationID)
+		if event.Branch != "" {
+			fmt.Printf("Branch: %s\n", event.Branch)
+		}
+
+		if event.Content != nil {
+			fmt.Printf("Role: %s\n", event.Content.Role)
+			for _, part := range event.Content.Parts {
+				printReplayPart(part)
+			}
+		}
+
+		if event.ErrorCode != "" || event.ErrorMessage != "" {
+			fmt.Printf("Error: %s %s\n", event.ErrorCode, event.ErrorMessage)
+		}
+		if event.FinishReason != "" {
+			fmt.Printf("Finish reason: %s\n", event.FinishReason)
+		}
+		if event.Partial || event.Interrupted {
+			fmt.Printf("Partial: %v | Interrupted: %v\n", event.Partial, event.Interrupted)
+		}
+
+		if len(event.Actions.StateDelta) > 0 {
+			fmt.Println("State changes:")
+			keys := make([]string, 0, len(event.Actions.StateDelta))
+			for key := range event.Actions.StateDelta {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				value := event.Actions.StateDelta[key]
+				if key == "notes" {
+					value = redactNotesValue(value)
+				}
+				fmt.Printf("  %s = %s\n", key, replayJSON(value))
+			}
+		}
+		if event.Actions.TransferToAgent != "" {
+			fmt.Printf("Transfer to agent: %s\n", event.Actions.TransferToAgent)
+		}
+		if event.Actions.Escalate {
+			fmt.Println("Escalate: true")
+		}
+
+		if usage := event.UsageMetadata; usage != nil {
+			fmt.Printf("Tokens: prompt=%d output=%d total=%d\n", usage.PromptTokenCount, usage.CandidatesTokenCount, usage.TotalTokenCount)
+			promptTokens += usage.PromptTokenCount
+			outputTokens += usage.CandidatesTokenCount
+			totalTokens += usage.TotalTokenCount
+		}
+	}
 
-	// Display reminders
-	reminders := []string{}
-	if val, err := state.Get("reminders"); err == nil {
-		if remindersList, ok := val.([]interface{}); ok {
-			for _, r := range remindersList {
-				if str, ok := r.(string); ok {
-					reminders = append(reminders, str)
-				}
-			}
+	fmt.Printf("\n===== End of replay: %d events | tokens: prompt=%d output=%d total=%d =====\n",
+		index, promptTokens, outputTokens, totalTokens)
+	return nil
+}
+
+// printReplayPart prints one content part: text, a function call or a function response
+func printReplayPart(part *genai.Part) {
+	switch {
+	case part.FunctionCall != nil:
+		args := part.FunctionCall.Args
+		if part.FunctionCall.Name == "set_note" && args["sensitive"] == true {
+			args = redactMapValue(args, "value")
 		}
+		fmt.Printf("Function call: %s(%s) [id: %s]\n", part.FunctionCall.Name, replayJSON(args), part.FunctionCall.ID)
+	case part.FunctionResponse != nil:
+		response := part.FunctionResponse.Response
+		if part.FunctionResponse.Name == "get_note" && response["sensitive"] == true {
+			response = redactMapValue(response, "value")
+		}
+		fmt.Printf("Function response: %s -> %s [id: %s]\n", part.FunctionResponse.Name, replayJSON(response), part.FunctionResponse.ID)
+	case part.Thought:
+		fmt.Printf("Thought: %s\n", part.Text)
+	case part.Text != "":
+		fmt.Printf("Text: %s\n", part.Text)
+	case part.ExecutableCode != nil:
+		fmt.Printf("Code (%s):\n%s\n", part.ExecutableCode.Language, part.ExecutableCode.Code)
+	case part.CodeExecutionResult != nil:
+		fmt.Printf("Code result (%s): %s\n", part.CodeExecutionResult.Outcome, part.CodeExecutionResult.Output)
+	case part.InlineData != nil:
+		fmt.Printf("Inline data: %s (%d bytes)\n", part.InlineData.MIMEType, len(part.InlineData.Data))
 	}
+}
 
-	if len(reminders) > 0 {
-		fmt.Println("📝 Reminders:")
-		for idx, reminder := range reminders {
-			fmt.Printf("  %d. %s\n", idx+1, reminder)
+// redactNotesValue redacts sensitive entries of a "notes" state value
+func redactNotesValue(value any) any {
+	entries, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	redacted := make(map[string]any, len(entries))
+	for label, entry := range entries {
+		if fields, ok := entry.(map[string]any); ok && fields["sensitive"] == true {
+			entry = redactMapValue(fields, "value")
 		}
-	} else {
-		fmt.Println("📝 Reminders: None")
+		redacted[label] = entry
 	}
+	return redacted
+}
 
-	fmt.Printf("--%s--\n", strings.Repeat("-", len(label)+20))
+// redactMapValue returns a copy of m with m[key] replaced by "[redacted]"
+func redactMapValue(m map[string]any, key string) map[string]any {
+	redacted := make(map[string]any, len(m))
+	for k, v := range m {
+		redacted[k] = v
+	}
+	redacted[key] = redactNote("", true)
+	return redacted
 }
 
-// ===== Main Function =====
+func replayJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
 
-func main() {
-	godotenv.Load()
-	ctx := context.Background()
+// importStateReminders copies the session's state reminders into table when the user has no
+// reminders there yet. The state copy is left untouched (state only changes through events)
+// and is ignored from then on.
+func importStateReminders(ctx context.Context, sessionService session.Service, table store.ReminderStore, appName, userID, sessionID string) (int, error) {
+	existing, err := table.List(ctx)
+	if err != nil || len(existing) > 0 {
+		return 0, err
+	}
 
-	// Create the Gemini model
-	model, err := gemini.NewModel(ctx, MODEL_NAME, &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	getResp, err := sessionService.Get(ctx, &session.GetRequest{
+		AppName:   appName,
+		UserID:    userID,
+		SessionID: sessionID,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
+		return 0, err
 	}
-
-	// Create database session service with SQLite
-	sessionService, err := database.NewSessionService(
-		sqlite.Open(DB_FILE),
-		&gorm.Config{
-			PrepareStmt: true,
-			Logger:      logger.Default.LogMode(logger.Silent),
-		},
-	)
+	legacy, err := store.NewStateReminderStore(getResp.Session.State()).List(ctx)
 	if err != nil {
-		log.Fatalf("Failed to create database session service: %v", err)
+		return 0, err
 	}
 
-	// Initialize database schema
-	if err := database.AutoMigrate(sessionService); err != nil {
-		log.Fatalf("Failed to auto-migrate database: %v", err)
+	for _, reminder := range legacy {
+		added, err := table.Add(ctx, reminder.Text)
+		if err != nil {
+			return 0, err
+		}
+		if reminder.Pinned {
+			if _, err := table.SetPinned(ctx, added.ID, true); err != nil {
+				return 0, err
+			}
+		}
+		if !reminder.DueAt.IsZero() {
+			if _, err := table.SetDue(ctx, added.ID, reminder.DueAt); err != nil {
+				return 0, err
+			}
+		}
 	}
+	return len(legacy), nil
+}
 
-	fmt.Println("✅ Connected to database:", DB_FILE)
+// ===== Session Service Wrapper =====
+
+// persistentSessionService wraps a session service so events are saved even when the run's
+// context is canceled. An interrupt then stops the model call without losing the state
+// changes of tool calls that already completed.
+type persistentSessionService struct {
+	session.Service
+}
 
+// AppendEvent saves the event with a context that is not canceled along with the run
+func (s *persistentSessionService) AppendEvent(ctx context.Context, sess session.Session, event *session.Event) error {
+	return s.Service.AppendEvent(context.WithoutCancel(ctx), sess, event)
+}
+
+// ===== Agent Creation =====
+
+// newMemoryAgent creates the memory agent; reminder tools use the stores from reminderStoreFor.
+// Its usage is counted in usage unless that is nil. now is the clock the first-turn greeting is
+// picked with, usually time.Now. Sessions with more user turns than turnLimit allows get its
+// wrap-up message instead of a model reply; turns are counted through sessionService.
+func newMemoryAgent(mdl model.LLM, reminderStoreFor reminderStoreFactory, sessionService session.Service, usage *store.UsageStats, now func() time.Time, turnLimit agentutil.TurnLimit) (agent.Agent, error) {
 	// Create reminder management tools
+	reminders := reminderTools{storeFor: reminderStoreFor, confirm: toolutil.NewConfirmer(toolutil.DEFAULT_CONFIRMATION_TTL)}
+
 	addReminderTool, err := functiontool.New(
 		functiontool.Config{
 			Name:        "add_reminder",
 			Description: "Add a new reminder to the user's reminder list",
 		},
-		addReminder)
+		reminders.addReminder)
 	if err != nil {
-		log.Fatalf("Failed to create add_reminder tool: %v", err)
+		return nil, fmt.Errorf("failed to create add_reminder tool: %w", err)
 	}
 
 	viewRemindersTool, err := functiontool.New(
@@ -340,29 +1422,80 @@ func main() {
 			Name:        "view_reminders",
 			Description: "View all current reminders",
 		},
-		viewReminders)
+		reminders.viewReminders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create view_reminders tool: %w", err)
+	}
+
+	searchRemindersTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "search_reminders",
+			Description: "Find reminders whose text contains the query (case-insensitive); returns each match with its id and index",
+		},
+		reminders.searchReminders)
 	if err != nil {
-		log.Fatalf("Failed to create view_reminders tool: %v", err)
+		return nil, fmt.Errorf("failed to create search_reminders tool: %w", err)
 	}
 
 	updateReminderTool, err := functiontool.New(
 		functiontool.Config{
 			Name:        "update_reminder",
-			Description: "Update an existing reminder",
+			Description: "Update an existing reminder, given its id or its index (position starting at 1)",
 		},
-		updateReminder)
+		reminders.updateReminder)
 	if err != nil {
-		log.Fatalf("Failed to create update_reminder tool: %v", err)
+		return nil, fmt.Errorf("failed to create update_reminder tool: %w", err)
 	}
 
 	deleteReminderTool, err := functiontool.New(
 		functiontool.Config{
 			Name:        "delete_reminder",
-			Description: "Delete a reminder",
+			Description: "Delete a reminder, given its id or its index (position starting at 1)",
+		},
+		reminders.deleteReminder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delete_reminder tool: %w", err)
+	}
+
+	clearRemindersTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "clear_reminders",
+			Description: "Delete ALL reminders, in two steps: without confirmation_token it deletes nothing and returns the reminders " +
+				"that would be deleted and a token; call it again with that token only after the user confirms",
+		},
+		reminders.clearReminders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clear_reminders tool: %w", err)
+	}
+
+	pinReminderTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "pin_reminder",
+			Description: "Pin an important reminder so it is always shown first",
+		},
+		reminders.pinReminder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pin_reminder tool: %w", err)
+	}
+
+	unpinReminderTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "unpin_reminder",
+			Description: "Unpin a reminder so it is listed with the others again",
 		},
-		deleteReminder)
+		reminders.unpinReminder)
 	if err != nil {
-		log.Fatalf("Failed to create delete_reminder tool: %v", err)
+		return nil, fmt.Errorf("failed to create unpin_reminder tool: %w", err)
+	}
+
+	snoozeReminderTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "snooze_reminder",
+			Description: "Push a reminder's due date later by a duration such as '1h', '2 days', 'tomorrow' or 'next week'; a reminder without a due date gets one counted from now",
+		},
+		reminders.snoozeReminder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snooze_reminder tool: %w", err)
 	}
 
 	updateUserNameTool, err := functiontool.New(
@@ -372,36 +1505,103 @@ func main() {
 		},
 		updateUserName)
 	if err != nil {
-		log.Fatalf("Failed to create update_user_name tool: %v", err)
+		return nil, fmt.Errorf("failed to create update_user_name tool: %w", err)
 	}
 
-	// Create the memory agent
-	memoryAgent, err := llmagent.New(llmagent.Config{
+	sessions := sessionTools{sessions: sessionService}
+
+	renameSessionTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "rename_session",
+			Description: "Give the current session a human-readable label, shown by /sessions; warns if another session already uses it",
+		},
+		sessions.renameSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rename_session tool: %w", err)
+	}
+
+	// Create note tools for arbitrary facts the user wants remembered
+	setNoteTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "set_note",
+			Description: "Save a note (a labeled fact, e.g. label 'wifi password'). Set overwrite=true only after the user confirms replacing an existing note, and sensitive=true for secrets such as passwords",
+		},
+		setNote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create set_note tool: %w", err)
+	}
+
+	getNoteTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "get_note",
+			Description: "Get the value of a note by its label",
+		},
+		getNote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create get_note tool: %w", err)
+	}
+
+	listNotesTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "list_notes",
+			Description: "List all saved notes (sensitive values are redacted)",
+		},
+		listNotes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_notes tool: %w", err)
+	}
+
+	deleteNoteTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "delete_note",
+			Description: "Delete a note by its label",
+		},
+		deleteNote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delete_note tool: %w", err)
+	}
+
+	// Create the agent with all tools; empty model responses become a fallback reply, and long
+	// sessions are wrapped up before the model is called
+	return llmagent.New(agentutil.WithTurnLimit(agentutil.WithEmptyResponseFallback(withUsageStats(llmagent.Config{
 		Name:        "memory_agent",
-		Model:       model,
+		Model:       mdl,
 		Description: "A smart reminder agent with persistent memory",
 		Instruction: `You are a friendly reminder assistant that remembers users across conversations.
 
-You have access to tools to manage reminders and user information.
+You have access to tools to manage reminders, notes and user information.
 
 You can help users manage their reminders with the following capabilities:
 1. Add new reminders
-2. View existing reminders
+2. View and search existing reminders
 3. Update reminders
-4. Delete reminders
-5. Update the user's name
+4. Delete reminders, or clear all of them after the user confirms
+5. Pin important reminders to the top, and unpin them
+6. Snooze reminders to a later due date
+7. Update the user's name
+8. Save, look up, list and delete notes
+9. Label the current session so it is easier to find later
 
 Always be friendly and address the user by name. If you don't know their name yet,
 use the update_user_name tool to store it when they introduce themselves.
 
+Time-of-day greeting for the start of this conversation: {greeting_prefix?}
+If it is set, open your reply with it. If it is empty, don't add one.
+
 **REMINDER MANAGEMENT GUIDELINES:**
 
+Every reminder has a stable id, returned by add_reminder, view_reminders and search_reminders, and an
+index, its current position starting at 1. Indexes shift when a reminder is deleted; ids never change.
+update_reminder, delete_reminder, pin_reminder, unpin_reminder and snooze_reminder accept either:
+- Pass id for a reminder you found in a tool result
+- Pass index only for a position the user just named ("delete reminder 2")
+
 When dealing with reminders, you need to be smart about finding the right reminder:
 
-1. When the user asks to update or delete a reminder but doesn't provide an index:
+1. When the user asks to update or delete a reminder but doesn't provide a number:
    - If they mention the content of the reminder (e.g., "delete my meeting reminder"),
-     look through the reminders to find a match
-   - If you find an exact or close match, use that index
+     call search_reminders with a keyword (e.g. "meeting") and use the id it returns
+   - If you find an exact or close match, use that id
    - Never ask for clarification, just use the first match
    - If no match is found, list all reminders and ask the user to specify
 
@@ -420,6 +1620,8 @@ When dealing with reminders, you need to be smart about finding the right remind
    - IMPORTANT: The tool result may not contain the actual reminder data
    - Use the current session state information that is displayed before/after processing
    - Format the response in a numbered list for clarity
+   - view_reminders returns pinned reminders separately: list them first under a "Pinned" heading,
+     then the other reminders, and number every reminder with its index field
    - If there are no reminders, suggest adding some
 
 5. For addition:
@@ -429,12 +1631,56 @@ When dealing with reminders, you need to be smart about finding the right remind
 
 6. For updates:
    - Identify both which reminder to update and what the new text should be
-   - For example, "change my second reminder to pick up groceries" → update_reminder(2, "pick up groceries")
+   - For example, "change my second reminder to pick up groceries" → update_reminder(index=2, updated_text="pick up groceries")
 
 7. For deletions:
    - Confirm deletion when complete and mention which reminder was removed
    - For example, "I've deleted your reminder to 'buy milk'"
 
+   - To delete ALL reminders ("clear my reminders", "delete everything"), use clear_reminders, never
+     one delete_reminder call per reminder. It works in two steps:
+     a. Call it without confirmation_token. Nothing is deleted yet: list the reminders it returns and
+        ask the user to confirm deleting all of them
+     b. Only after the user clearly says yes in their next message, call it again with the
+        confirmation_token from step a. Never pass a token in the same turn you asked for it
+     - If it returns "expired", "changed", "not_confirmed" or "invalid_token", nothing was deleted: start again at step a
+     - If it returns "empty", tell the user there is nothing to clear
+
+8. For pinning:
+   - Use pin_reminder when the user wants a reminder kept at the top or marks it as important,
+     and unpin_reminder when they no longer need it there
+   - Find the reminder the same way as for updates and deletions
+   - If a tool returns status "not_found", the reminder no longer exists (or not at that position):
+     call view_reminders and use the right id, or tell the user it's gone
+   - If it returns "unchanged", tell the user it was already pinned (or not pinned)
+
+9. For snoozing:
+   - Use snooze_reminder when the user wants to be reminded later ("snooze my dentist reminder until
+     tomorrow"), passing the duration as they said it: "1h", "30 minutes", "2 days", "tomorrow", "next week"
+   - Tell the user the new due date from the tool result; if the reminder had no due date, mention
+     that it now has one
+   - If it returns "invalid_duration", ask for a duration in one of those forms
+
+**NOTE MANAGEMENT GUIDELINES:**
+
+Notes are labeled facts the user wants you to remember ("my wifi password is X"), separate from reminders,
+which are tasks to do.
+
+1. To save a note, pick a short label (e.g. "wifi password") and call set_note
+   - Set sensitive=true for passwords, PINs, account numbers and other secrets
+   - If set_note returns status "exists", tell the user the current value and ask whether to replace it;
+     only call set_note again with overwrite=true after they confirm
+2. To answer "what's my ...?", call get_note with the label. If it returns "not_found", say so and
+   mention the saved labels it lists
+3. Use list_notes when the user asks what notes they have
+4. Use delete_note when the user wants a note removed
+
+**SESSION LABELS:**
+
+When the user wants to name or rename this conversation ("call this session trip planning"), call
+rename_session with the label. If the result lists duplicate_session_ids, mention that other sessions
+already use that label (it was still applied). Users can list their sessions with /sessions.
+
 Remember to explain that you can remember their information across conversations.
 
 IMPORTANT:
@@ -444,21 +1690,284 @@ IMPORTANT:
 		Tools: []tool.Tool{
 			addReminderTool,
 			viewRemindersTool,
+			searchRemindersTool,
 			updateReminderTool,
 			deleteReminderTool,
+			clearRemindersTool,
+			pinReminderTool,
+			unpinReminderTool,
+			snoozeReminderTool,
 			updateUserNameTool,
+			renameSessionTool,
+			setNoteTool,
+			getNoteTool,
+			listNotesTool,
+			deleteNoteTool,
+		},
+		// Greet each session once, by the time of day
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{agentutil.FirstTurnGreeting(now)},
+	}, usage)), sessionService, turnLimit))
+}
+
+// ===== Sessions =====
+
+// defaultInitialState is the state every new session starts with, unless initial_state.json
+// overrides it
+func defaultInitialState() map[string]any {
+	return map[string]any{
+		"user_name": "User",
+		"reminders": []string{},
+		"notes":     map[string]any{},
+	}
+}
+
+// validateInitialState checks that the tools can read state as it is: a user name, reminders
+// that are texts or {"text", ...} objects, and notes that are {"value", "sensitive"} objects
+func validateInitialState(state map[string]any) error {
+	var problems []string
+	if name, _ := state["user_name"].(string); strings.TrimSpace(name) == "" {
+		problems = append(problems, "user_name must be a non-empty string")
+	}
+
+	if reminders, ok := state[store.REMINDERS_STATE_KEY].([]any); ok {
+		for i, item := range reminders {
+			switch r := item.(type) {
+			case string:
+				if strings.TrimSpace(r) == "" {
+					problems = append(problems, fmt.Sprintf("reminders[%d] is empty", i))
+				}
+			case map[string]any:
+				if text, _ := r["text"].(string); strings.TrimSpace(text) == "" {
+					problems = append(problems, fmt.Sprintf("reminders[%d] must have a non-empty text", i))
+				}
+			default:
+				problems = append(problems, fmt.Sprintf("reminders[%d] must be a text or an object with a text", i))
+			}
+		}
+	}
+
+	if notes, ok := state["notes"].(map[string]any); ok {
+		labels := make([]string, 0, len(notes))
+		for label := range notes {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			fields, ok := notes[label].(map[string]any)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("note %q must be an object with a value", label))
+				continue
+			}
+			if _, ok := fields["value"].(string); !ok {
+				problems = append(problems, fmt.Sprintf("note %q must have a string value", label))
+			}
+			if sensitive, present := fields["sensitive"]; present {
+				if _, ok := sensitive.(bool); !ok {
+					problems = append(problems, fmt.Sprintf("note %q: sensitive must be true or false", label))
+				}
+			}
+			if label != normalizeNoteLabel(label) {
+				problems = append(problems, fmt.Sprintf("note label %q must be lowercase with single spaces (%q)", label, normalizeNoteLabel(label)))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid initial state: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// createSession creates a session with initialState and returns its ID
+func createSession(ctx context.Context, sessionService session.Service, appName, userID string, initialState map[string]any) (string, error) {
+	createResp, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName: appName,
+		UserID:  userID,
+		State:   initialState,
+	})
+	if err != nil {
+		return "", err
+	}
+	return createResp.Session.ID(), nil
+}
+
+// ===== Agent Turns =====
+
+// runTurn runs the agent on one message and returns its final response. onEvent is called
+// for every event, before it is handled. Empty model responses are already replaced by the
+// agent's fallback callback; should a run still end without any text, it returns
+// agentutil.FALLBACK_RESPONSE as well and logs why. Errors and interrupts return what was
+// received so far, without the fallback.
+func runTurn(ctx context.Context, r *runner.Runner, userID, sessionID string, msg *genai.Content, onEvent func()) (string, error) {
+	var finalResponse string
+	var lastEvent *session.Event
+	for event, err := range r.Run(ctx, userID, sessionID, msg, agent.RunConfig{}) {
+		onEvent()
+		if ctx.Err() != nil {
+			return finalResponse, nil
+		}
+		if err != nil {
+			return finalResponse, err
+		}
+		lastEvent = event
+
+		// Capture final response
+		if event.Content != nil && len(event.Content.Parts) > 0 && event.Content.Parts[0].Text != "" {
+			finalResponse = event.Content.Parts[0].Text
+		}
+	}
+	if ctx.Err() != nil || finalResponse != "" {
+		return finalResponse, nil
+	}
+
+	reason := agentutil.EmptyResponseReason(nil)
+	if lastEvent != nil {
+		reason = agentutil.EmptyResponseReason(&lastEvent.LLMResponse)
+	}
+	log.Printf("No response text in session %s (%s); showing a fallback reply", sessionID, reason)
+	return agentutil.FALLBACK_RESPONSE, nil
+}
+
+// warnTurnLimit tells the user when a resumed session has already used up its turns, so the
+// first message isn't a surprise wrap-up. List doesn't load events, so the session is read
+// again; errors only skip the notice.
+func warnTurnLimit(ctx context.Context, sessionService session.Service, limit agentutil.TurnLimit, appName, userID, sessionID string) {
+	if !limit.Enabled() {
+		return
+	}
+	getResp, err := sessionService.Get(ctx, &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		return
+	}
+	if turns := agentutil.CountUserTurns(getResp.Session.Events()); turns >= limit.MaxTurns {
+		fmt.Printf("⏹️  This session has reached its limit of %d messages; type %s to start a new one.\n", limit.MaxTurns, NEW_SESSION_COMMAND)
+	}
+}
+
+// ===== Main Function =====
+
+func main() {
+	quiet := flag.Bool("quiet", false, "disable the thinking indicator while the agent responds")
+	replay := flag.String("replay", "", "print every event of the given session ID in detail and exit")
+	reminderBackend := flag.String("reminder-store", REMINDER_STORE_TABLE, "where reminders are kept: table (shared by all sessions) or state (per session)")
+	report := flag.Bool("report", false, "print the daily usage summary and exit")
+	reportDays := flag.Int("report-days", DEFAULT_REPORT_DAYS, "number of days shown by -report, up to today")
+	purgeUser := flag.String("purge-user", "", "delete every session, event, user state and reminder of the given user ID and exit")
+	confirm := flag.Bool("confirm", false, "with -purge-user, delete without asking to type the user ID")
+	flag.Parse()
+
+	godotenv.Load()
+
+	// Cancel the context on Ctrl-C (or SIGTERM) so an in-flight agent run stops cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Create database session service with SQLite
+	sessionService, err := database.NewSessionService(
+		sqlite.Open(DB_FILE),
+		&gorm.Config{
+			PrepareStmt: true,
+			Logger:      logger.Default.LogMode(logger.Silent),
 		},
+	)
+	if err != nil {
+		log.Fatalf("Failed to create database session service: %v", err)
+	}
+
+	// Initialize database schema
+	if err := database.AutoMigrate(sessionService); err != nil {
+		log.Fatalf("Failed to auto-migrate database: %v", err)
+	}
+
+	fmt.Println("✅ Connected to database:", DB_FILE)
+
+	// A separate GORM connection to the same SQLite file, for the reminders and usage_stats tables
+	db, err := gorm.Open(sqlite.Open(DB_FILE), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		log.Fatalf("Failed to create agent: %v", err)
+		log.Fatalf("Failed to open reminders database: %v", err)
 	}
+	if err := store.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to auto-migrate store tables: %v", err)
+	}
+	usage := store.NewUsageStats(db, APP_NAME)
+
+	// In report mode, print the daily usage and exit without starting the agent
+	if *report {
+		if err := printUsageReport(ctx, usage, *reportDays); err != nil {
+			log.Fatalf("Report failed: %v", err)
+		}
+		return
+	}
+
+	// In purge mode, delete the user's data and exit without starting the agent
+	if *purgeUser != "" {
+		if err := runPurge(ctx, sessionService, db, APP_NAME, *purgeUser, *confirm, os.Stdin); err != nil {
+			log.Fatalf("Purge failed: %v", err)
+		}
+		return
+	}
+
+	// Choose where reminders are kept
+	var reminderStoreFor reminderStoreFactory
+	switch *reminderBackend {
+	case REMINDER_STORE_TABLE:
+		reminderStoreFor = func(appName, userID string, _ session.State) store.ReminderStore {
+			return store.NewGormReminderStore(db, appName, userID)
+		}
+	case REMINDER_STORE_STATE:
+		reminderStoreFor = func(_, _ string, state session.State) store.ReminderStore {
+			return store.NewStateReminderStore(state)
+		}
+	default:
+		log.Fatalf("Unknown -reminder-store %q (use %s or %s)", *reminderBackend, REMINDER_STORE_TABLE, REMINDER_STORE_STATE)
+	}
+	fmt.Println("📝 Reminder store:", *reminderBackend)
 
-	// Setup user and check for existing sessions
+	// Setup user
 	USER_ID := "user_" + os.Getenv("USER")
 	if USER_ID == "user_" {
 		USER_ID = "default_user"
 	}
 
+	// In replay mode, print the stored session and exit without starting the agent
+	if *replay != "" {
+		if err := replaySession(ctx, sessionService, APP_NAME, USER_ID, *replay); err != nil {
+			log.Fatalf("Replay failed: %v", err)
+		}
+		return
+	}
+
+	// New sessions start with the defaults, overridden by initial_state.json when it exists
+	initialState, fromFile, err := toolutil.LoadInitialState(toolutil.INITIAL_STATE_FILE, defaultInitialState(), validateInitialState)
+	if err != nil {
+		log.Fatalf("Failed to load initial state: %v", err)
+	}
+	if fromFile {
+		fmt.Println("🌱 Initial state loaded from", toolutil.INITIAL_STATE_FILE)
+	}
+
+	// Create the Gemini model
+	model, err := gemini.NewModel(ctx, MODEL_NAME, &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	// Sessions wrap up after MAX_CONVERSATION_TURNS user turns (0 turns the limit off)
+	turnLimit, err := agentutil.LoadTurnLimit(os.Getenv, agentutil.TurnLimit{MaxTurns: DEFAULT_MAX_TURNS, Message: DEFAULT_WRAP_UP_MESSAGE})
+	if err != nil {
+		log.Fatalf("Failed to load the conversation turn limit: %v", err)
+	}
+
+	// Create the memory agent
+	memoryAgent, err := newMemoryAgent(model, reminderStoreFor, sessionService, usage, time.Now, turnLimit)
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
 	// Check for existing sessions
 	listResp, err := sessionService.List(ctx, &session.ListRequest{
 		AppName: APP_NAME,
@@ -472,30 +1981,34 @@ IMPORTANT:
 	if len(listResp.Sessions) > 0 {
 		// Use the most recent session
 		SESSION_ID = listResp.Sessions[0].ID()
-		fmt.Printf("🔄 Continuing existing session: %s\n", SESSION_ID)
+		fmt.Printf("🔄 Continuing existing session: %s\n", sessionName(listResp.Sessions[0]))
+		warnTurnLimit(ctx, sessionService, turnLimit, APP_NAME, USER_ID, SESSION_ID)
 	} else {
 		// Create a new session with initial state
-		initialState := map[string]any{
-			"user_name": "User",
-			"reminders": []string{},
-		}
-		createResp, err := sessionService.Create(ctx, &session.CreateRequest{
-			AppName: APP_NAME,
-			UserID:  USER_ID,
-			State:   initialState,
-		})
+		SESSION_ID, err = createSession(ctx, sessionService, APP_NAME, USER_ID, initialState)
 		if err != nil {
 			log.Fatalf("Failed to create session: %v", err)
 		}
-		SESSION_ID = createResp.Session.ID()
+		recordUsage(ctx, usage, store.UsageCounts{Sessions: 1})
 		fmt.Printf("✨ Created new session: %s\n", SESSION_ID)
 	}
 
+	// Reminders kept in session state before the reminders table existed are moved over once
+	if *reminderBackend == REMINDER_STORE_TABLE {
+		imported, err := importStateReminders(ctx, sessionService, reminderStoreFor(APP_NAME, USER_ID, nil), APP_NAME, USER_ID, SESSION_ID)
+		if err != nil {
+			log.Fatalf("Failed to import reminders from session state: %v", err)
+		}
+		if imported > 0 {
+			fmt.Printf("📥 Imported %d reminders from session state into the reminders table\n", imported)
+		}
+	}
+
 	// Create runner with the memory agent
 	r, err := runner.New(runner.Config{
 		AppName:        APP_NAME,
 		Agent:          memoryAgent,
-		SessionService: sessionService,
+		SessionService: &persistentSessionService{Service: sessionService},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create runner: %v", err)
@@ -504,19 +2017,37 @@ IMPORTANT:
 	// Interactive conversation loop
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("Welcome to Memory Agent Chat!")
-	fmt.Println("Your reminders will be remembered across conversations.")
-	fmt.Println("Type 'exit' or 'quit' to end the conversation.")
+	fmt.Println("Your reminders and notes will be remembered across conversations.")
+	fmt.Println("Type 'exit' or 'quit' (or press Ctrl-C) to end the conversation.")
+	fmt.Printf("Type '%s' to send your previous message again.\n", RETRY_COMMAND)
+	fmt.Printf("Type '%s' to start a new session (the current one is kept).\n", NEW_SESSION_COMMAND)
+	fmt.Printf("Type '%s' to list your sessions; ask the agent to rename this one.\n", SESSIONS_COMMAND)
 	fmt.Println(strings.Repeat("=", 60) + "\n")
 
-	scanner := bufio.NewScanner(os.Stdin)
+	// Show the full state once; after each turn only the changes are shown
+	displayState(sessionService, reminderStoreFor, APP_NAME, USER_ID, SESSION_ID, "Current state")
+
+	lines := readLines()
+
+	// The last message sent to the agent, for /retry
+	var lastUserInput string
 
 	for {
 		fmt.Print("You: ")
-		if !scanner.Scan() {
-			break
+
+		var line string
+		select {
+		case <-ctx.Done():
+			fmt.Println("\n\nInterrupted. Your data has been saved to the database.")
+			return
+		case input, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = input
 		}
 
-		userInput := strings.TrimSpace(scanner.Text())
+		userInput := strings.TrimSpace(line)
 
 		if userInput == "" {
 			continue
@@ -528,8 +2059,41 @@ IMPORTANT:
 			break
 		}
 
-		// Display state before processing
-		displayState(sessionService, APP_NAME, USER_ID, SESSION_ID, "State BEFORE processing")
+		// /sessions lists every session of the user with its label
+		if strings.ToLower(userInput) == SESSIONS_COMMAND {
+			displaySessions(ctx, sessionService, APP_NAME, USER_ID, SESSION_ID)
+			continue
+		}
+
+		// /new switches to a fresh session; the old one stays in the database and can be
+		// replayed with -replay
+		if strings.ToLower(userInput) == NEW_SESSION_COMMAND {
+			newSessionID, err := createSession(ctx, sessionService, APP_NAME, USER_ID, initialState)
+			if err != nil {
+				fmt.Printf("Failed to create session: %v\n", err)
+				continue
+			}
+			recordUsage(ctx, usage, store.UsageCounts{Sessions: 1})
+			fmt.Printf("✨ Created new session: %s (previous session %s is kept)\n", newSessionID, SESSION_ID)
+			SESSION_ID = newSessionID
+			lastUserInput = ""
+			displayState(sessionService, reminderStoreFor, APP_NAME, USER_ID, SESSION_ID, "Current state")
+			continue
+		}
+
+		// /retry sends the previous message again as a new turn, so the model gets another try
+		if strings.ToLower(userInput) == RETRY_COMMAND {
+			if lastUserInput == "" {
+				fmt.Println("Nothing to retry yet: send a message first.")
+				continue
+			}
+			userInput = lastUserInput
+			fmt.Printf("🔁 Retrying: %s\n", userInput)
+		}
+		lastUserInput = userInput
+
+		// Capture state before processing, to show what the turn changed
+		stateBefore := snapshotState(sessionService, reminderStoreFor, APP_NAME, USER_ID, SESSION_ID)
 
 		// Create user message
 		userMessage := &genai.Content{
@@ -541,18 +2105,21 @@ IMPORTANT:
 
 		// Run the agent
 		fmt.Printf("\n--- Running Query: %s ---\n", userInput)
-		var finalResponse string
 
-		for event, err := range r.Run(ctx, USER_ID, SESSION_ID, userMessage, agent.RunConfig{}) {
-			if err != nil {
-				fmt.Printf("Error during agent run: %v\n", err)
-				break
-			}
+		// Show the indicator until the first event; tools only run after an event has been
+		// handled here, so their debug prints always come after the line is cleared
+		thinking := startThinking(!*quiet)
+		finalResponse, err := runTurn(ctx, r, USER_ID, SESSION_ID, userMessage, thinking.Stop)
+		thinking.Stop()
+		if err != nil {
+			fmt.Printf("Error during agent run: %v\n", err)
+		}
 
-			// Capture final response
-			if event.Content != nil && len(event.Content.Parts) > 0 && event.Content.Parts[0].Text != "" {
-				finalResponse = event.Content.Parts[0].Text
-			}
+		// Stop here if the run was interrupted; completed tool calls are already saved
+		if ctx.Err() != nil {
+			fmt.Println("\n\nInterrupted. The response was canceled; completed changes have been saved to the database.")
+			displayStateDiff(stateBefore, snapshotState(sessionService, reminderStoreFor, APP_NAME, USER_ID, SESSION_ID), "State changes before interrupt")
+			return
 		}
 
 		// Display agent response
@@ -562,8 +2129,8 @@ IMPORTANT:
 			fmt.Println("╚════════════════════════════════════════════════════════")
 		}
 
-		// Display state after processing
-		displayState(sessionService, APP_NAME, USER_ID, SESSION_ID, "State AFTER processing")
+		// Display only what changed during this turn
+		displayStateDiff(stateBefore, snapshotState(sessionService, reminderStoreFor, APP_NAME, USER_ID, SESSION_ID), "State changes")
 		fmt.Println()
 	}
 }
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// Re-record testdata/reminders_recording.json against Gemini with:
+//
+//	GOOGLE_API_KEY=... go test ./6-persistent-storage/memory_agent -record
+
+const (
+	TEST_USER_ID        = "test_user"
+	TEST_SESSION_ID     = "test_session"
+	REMINDERS_RECORDING = "testdata/reminders_recording.json"
+)
+
+// testMorning fixes the clock at 9:00, so the recorded first turn is greeted with "Good morning"
+func testMorning() time.Time {
+	return time.Date(2024, 12, 3, 9, 0, 0, 0, time.Local)
+}
+
+// newTestRunner creates a runner for the memory agent with a fresh in-memory session that
+// keeps reminders in session state, so the test needs no database
+func newTestRunner(t *testing.T, mdl model.LLM) (*runner.Runner, session.Service) {
+	t.Helper()
+	return newTestRunnerWithUsage(t, mdl, nil)
+}
+
+// newTestRunnerWithUsage is newTestRunner with the agent's usage counted in usage
+func newTestRunnerWithUsage(t *testing.T, mdl model.LLM, usage *store.UsageStats) (*runner.Runner, session.Service) {
+	t.Helper()
+	ctx := context.Background()
+
+	memoryAgent, err := newMemoryAgent(mdl, func(_, _ string, state session.State) store.ReminderStore {
+		return store.NewStateReminderStore(state)
+	}, nil, usage, testMorning, agentutil.TurnLimit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName:   APP_NAME,
+		UserID:    TEST_USER_ID,
+		SessionID: TEST_SESSION_ID,
+		State:     defaultInitialState(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := runner.New(runner.Config{
+		AppName:        APP_NAME,
+		Agent:          memoryAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, sessionService
+}
+
+func TestAddThenViewReminders(t *testing.T) {
+	r, sessionService := newTestRunner(t, agenttest.ReplayModel(t, REMINDERS_RECORDING, MODEL_NAME))
+
+	replies := agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{
+		"Add a reminder to buy milk",
+		"Show me my reminders",
+	})
+
+	if !strings.Contains(strings.ToLower(replies[1]), "buy milk") {
+		t.Errorf("view reply = %q, want it to list the buy milk reminder", replies[1])
+	}
+
+	// The reply is recorded; check the tool really stored the reminder
+	resp, err := sessionService.Get(context.Background(), &session.GetRequest{
+		AppName:   APP_NAME,
+		UserID:    TEST_USER_ID,
+		SessionID: TEST_SESSION_ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminders, err := store.NewStateReminderStore(resp.Session.State()).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reminderTexts(reminders); !slices.Equal(got, []string{"buy milk"}) {
+		t.Errorf("stored reminders = %v, want [buy milk]", got)
+	}
+}
+
+func TestCreateSessionKeepsOldSession(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+
+	oldID, err := createSession(ctx, sessionService, APP_NAME, TEST_USER_ID, defaultInitialState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newID, err := createSession(ctx, sessionService, APP_NAME, TEST_USER_ID, defaultInitialState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newID == oldID {
+		t.Fatalf("createSession returned the existing session ID %q", oldID)
+	}
+
+	created, err := sessionService.Get(ctx, &session.GetRequest{AppName: APP_NAME, UserID: TEST_USER_ID, SessionID: newID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := created.Session.State()
+	if name, _ := state.Get("user_name"); name != "User" {
+		t.Errorf("new session user_name = %v, want User", name)
+	}
+	if reminders, _ := state.Get("reminders"); reminders == nil || len(reminders.([]string)) != 0 {
+		t.Errorf("new session reminders = %v, want an empty list", reminders)
+	}
+
+	listResp, err := sessionService.List(ctx, &session.ListRequest{AppName: APP_NAME, UserID: TEST_USER_ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, sess := range listResp.Sessions {
+		ids = append(ids, sess.ID())
+	}
+	if !slices.Contains(ids, oldID) || !slices.Contains(ids, newID) {
+		t.Errorf("listed sessions = %v, want both %q and %q", ids, oldID, newID)
+	}
+}
+
+// emptyModel answers every request with no content, like a response blocked for safety
+type emptyModel struct{}
+
+func (emptyModel) Name() string { return MODEL_NAME }
+
+func (emptyModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{FinishReason: genai.FinishReasonSafety}, nil)
+	}
+}
+
+func TestRunTurnEmptyResponseFallsBack(t *testing.T) {
+	r, _ := newTestRunner(t, emptyModel{})
+
+	events := 0
+	reply, err := runTurn(context.Background(), r, TEST_USER_ID, TEST_SESSION_ID,
+		genai.NewContentFromText("Add a reminder to buy milk", genai.RoleUser), func() { events++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != agentutil.FALLBACK_RESPONSE {
+		t.Errorf("reply = %q, want the fallback response", reply)
+	}
+	if events == 0 {
+		t.Error("onEvent was never called")
+	}
+}
+
+// countingModel answers every request with "Done." and counts the calls
+type countingModel struct{ calls *int }
+
+func (countingModel) Name() string { return MODEL_NAME }
+
+func (m countingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	*m.calls++
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("Done.", genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+func TestRunTurnWrapsUpAfterTurnLimit(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	sessionService := session.InMemoryService()
+	limit := agentutil.TurnLimit{MaxTurns: 2, Message: DEFAULT_WRAP_UP_MESSAGE}
+	memoryAgent, err := newMemoryAgent(countingModel{&calls}, func(_, _ string, state session.State) store.ReminderStore {
+		return store.NewStateReminderStore(state)
+	}, sessionService, nil, testMorning, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{
+		AppName: APP_NAME, UserID: TEST_USER_ID, SessionID: TEST_SESSION_ID, State: defaultInitialState(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(runner.Config{AppName: APP_NAME, Agent: memoryAgent, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"Done.", "Done.", limit.WrapUpMessage(), limit.WrapUpMessage()} {
+		reply, err := runTurn(ctx, r, TEST_USER_ID, TEST_SESSION_ID, genai.NewContentFromText("Hello", genai.RoleUser), func() {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply != want {
+			t.Errorf("turn %d reply = %q, want %q", i+1, reply, want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("model called %d times, want only the 2 turns within the limit", calls)
+	}
+	if !strings.Contains(limit.WrapUpMessage(), "2 messages") || !strings.Contains(limit.WrapUpMessage(), NEW_SESSION_COMMAND) {
+		t.Errorf("wrap-up message = %q, want the limit and %s", limit.WrapUpMessage(), NEW_SESSION_COMMAND)
+	}
+}
+
+func TestInitialStateExampleFile(t *testing.T) {
+	state, fromFile, err := toolutil.LoadInitialState("initial_state.example.json", defaultInitialState(), validateInitialState)
+	if err != nil || !fromFile {
+		t.Fatalf("LoadInitialState(example) = %v, %v, want it loaded", fromFile, err)
+	}
+
+	resp, err := session.InMemoryService().Create(context.Background(), &session.CreateRequest{
+		AppName: APP_NAME, UserID: TEST_USER_ID, State: state,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := getNotes(resp.Session.State())
+	if n := notes["wifi password"]; n.Value == "" || !n.Sensitive {
+		t.Errorf("wifi password note = %+v, want a sensitive value", n)
+	}
+	if state["user_name"] != "Alex" {
+		t.Errorf("user_name = %v, want Alex", state["user_name"])
+	}
+}
+
+func TestValidateInitialState(t *testing.T) {
+	if err := validateInitialState(defaultInitialState()); err != nil {
+		t.Errorf("validateInitialState(defaults) = %v, want nil", err)
+	}
+
+	err := validateInitialState(map[string]any{
+		"user_name": "",
+		"reminders": []any{"buy milk", 42, map[string]any{"id": float64(2)}},
+		"notes": map[string]any{
+			"WiFi":   map[string]any{"value": "secret"},
+			"locker": "1234",
+			"door":   map[string]any{"value": "5678", "sensitive": "yes"},
+		},
+	})
+	if err == nil {
+		t.Fatal("validateInitialState(bad state) = nil, want an error")
+	}
+	for _, want := range []string{"user_name", "reminders[1]", "reminders[2] must have a non-empty text", `note "locker"`, `note "door": sensitive`, `note label "WiFi"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+}
+
+// toolCallModel turns each user message into one tool call and answers with the tool's status
+// once the result comes back. "add <text>" calls add_reminder, "clear" calls clear_reminders
+// without a token, "confirm" with the token of the last clear_reminders result and "confirm
+// <token>" with the given token. "clear and confirm" confirms the preview right away, in the
+// same turn, without the user answering.
+type toolCallModel struct{}
+
+func (toolCallModel) Name() string { return MODEL_NAME }
+
+func (toolCallModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		last := req.Contents[len(req.Contents)-1]
+		for _, part := range last.Parts {
+			if part.FunctionResponse != nil {
+				if part.FunctionResponse.Response["status"] == "confirmation_required" && lastUserText(req.Contents) == "clear and confirm" {
+					call := genai.NewPartFromFunctionCall("clear_reminders", map[string]any{"confirmation_token": lastConfirmationToken(req.Contents)})
+					yield(&model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{call}, genai.RoleModel)}, nil)
+					return
+				}
+				yield(&model.LLMResponse{Content: genai.NewContentFromText(fmt.Sprint(part.FunctionResponse.Response["status"]), genai.RoleModel)}, nil)
+				return
+			}
+		}
+
+		text := last.Parts[0].Text
+		var call *genai.Part
+		switch {
+		case strings.HasPrefix(text, "add "):
+			call = genai.NewPartFromFunctionCall("add_reminder", map[string]any{"reminder": strings.TrimPrefix(text, "add ")})
+		case text == "clear" || text == "clear and confirm":
+			call = genai.NewPartFromFunctionCall("clear_reminders", map[string]any{})
+		case text == "confirm":
+			call = genai.NewPartFromFunctionCall("clear_reminders", map[string]any{"confirmation_token": lastConfirmationToken(req.Contents)})
+		case strings.HasPrefix(text, "confirm "):
+			call = genai.NewPartFromFunctionCall("clear_reminders", map[string]any{"confirmation_token": strings.TrimPrefix(text, "confirm ")})
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{call}, genai.RoleModel)}, nil)
+	}
+}
+
+// lastConfirmationToken returns the confirmation_token of the last clear_reminders result in contents
+func lastConfirmationToken(contents []*genai.Content) string {
+	token := ""
+	for _, content := range contents {
+		for _, part := range content.Parts {
+			if part.FunctionResponse != nil && part.FunctionResponse.Name == "clear_reminders" {
+				if t, ok := part.FunctionResponse.Response["confirmation_token"].(string); ok {
+					token = t
+				}
+			}
+		}
+	}
+	return token
+}
+
+// lastUserText returns the text of the last user message in contents
+func lastUserText(contents []*genai.Content) string {
+	for i := len(contents) - 1; i >= 0; i-- {
+		if contents[i].Role == genai.RoleUser && len(contents[i].Parts) > 0 && contents[i].Parts[0].Text != "" {
+			return contents[i].Parts[0].Text
+		}
+	}
+	return ""
+}
+
+// storedReminders returns the texts of the reminders in the test session
+func storedReminders(t *testing.T, sessionService session.Service) []string {
+	t.Helper()
+	resp, err := sessionService.Get(context.Background(), &session.GetRequest{AppName: APP_NAME, UserID: TEST_USER_ID, SessionID: TEST_SESSION_ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminders, err := store.NewStateReminderStore(resp.Session.State()).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reminderTexts(reminders)
+}
+
+func TestClearRemindersNeedsConfirmation(t *testing.T) {
+	r, sessionService := newTestRunner(t, toolCallModel{})
+	agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"add buy milk", "add call mom"})
+
+	// The first call only previews; nothing is deleted
+	replies := agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"clear"})
+	if replies[0] != "confirmation_required" {
+		t.Fatalf("clear reply = %q, want confirmation_required", replies[0])
+	}
+	if got := storedReminders(t, sessionService); len(got) != 2 {
+		t.Fatalf("reminders after the preview = %v, want both kept", got)
+	}
+
+	// A made-up token deletes nothing and uses up nothing
+	replies = agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"confirm 0123456789ab"})
+	if replies[0] != "invalid_token" || len(storedReminders(t, sessionService)) != 2 {
+		t.Fatalf("made-up token: reply %q, reminders %v; want invalid_token and both kept", replies[0], storedReminders(t, sessionService))
+	}
+
+	// The second call with the token deletes them, in a later turn
+	replies = agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"confirm"})
+	if replies[0] != "success" {
+		t.Fatalf("confirm reply = %q, want success", replies[0])
+	}
+	if got := storedReminders(t, sessionService); len(got) != 0 {
+		t.Errorf("reminders after confirming = %v, want none", got)
+	}
+
+	// The token works once
+	agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"add water plants"})
+	replies = agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"confirm"})
+	if replies[0] != "invalid_token" || len(storedReminders(t, sessionService)) != 1 {
+		t.Errorf("reused token: reply %q, want invalid_token and the new reminder kept", replies[0])
+	}
+}
+
+func TestClearRemindersRefusesSameTurnConfirmation(t *testing.T) {
+	r, sessionService := newTestRunner(t, toolCallModel{})
+	agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"add buy milk"})
+
+	replies := agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"clear and confirm"})
+	if replies[0] != "not_confirmed" || len(storedReminders(t, sessionService)) != 1 {
+		t.Errorf("same-turn confirmation: reply %q, reminders %v; want not_confirmed and the reminder kept", replies[0], storedReminders(t, sessionService))
+	}
+}
+
+func TestClearRemindersRefusesChangedList(t *testing.T) {
+	r, sessionService := newTestRunner(t, toolCallModel{})
+	replies := agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{"clear", "add buy milk", "clear", "add call mom", "confirm"})
+
+	if replies[0] != "empty" {
+		t.Errorf("clear with no reminders = %q, want empty", replies[0])
+	}
+	// call mom was added after the preview, which only showed buy milk
+	if replies[4] != "changed" {
+		t.Errorf("confirm after a change = %q, want changed", replies[4])
+	}
+	if got := storedReminders(t, sessionService); !slices.Equal(got, []string{"buy milk", "call mom"}) {
+		t.Errorf("reminders = %v, want both kept", got)
+	}
+}
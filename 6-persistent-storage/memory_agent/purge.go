@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+)
+
+// ===== Purging User Data =====
+//
+// Run with -purge-user <userID> to delete everything stored for one user of this app: their
+// sessions (including notes and any reminders kept in session state), the sessions' events,
+// their user-scoped state and their rows in the reminders table. The counts are shown first
+// and nothing is deleted until the user ID is typed back; -confirm skips that prompt for
+// scripts. usage_stats holds per-app daily totals with no user ID, so it is left as is.
+//
+// Sessions are deleted through the session service. The service only removes the sessions
+// row (SQLite doesn't cascade to events by default), so events and user state, whose tables
+// the service doesn't expose, are deleted with plain SQL on the same database, always
+// filtered by app and user.
+
+// purgeCounts are the records of one user, found before a purge or deleted by it
+type purgeCounts struct {
+	Sessions   int64
+	Events     int64
+	UserStates int64
+	Reminders  int64
+}
+
+func (c purgeCounts) Total() int64 {
+	return c.Sessions + c.Events + c.UserStates + c.Reminders
+}
+
+func (c purgeCounts) String() string {
+	return fmt.Sprintf("%d session(s), %d event(s), %d user state record(s), %d reminder(s)",
+		c.Sessions, c.Events, c.UserStates, c.Reminders)
+}
+
+// countUserData counts what purgeUserData would delete for userID
+func countUserData(ctx context.Context, sessionService session.Service, db *gorm.DB, appName, userID string) (purgeCounts, error) {
+	if userID == "" {
+		return purgeCounts{}, errors.New("a user ID is required")
+	}
+
+	var counts purgeCounts
+	listResp, err := sessionService.List(ctx, &session.ListRequest{AppName: appName, UserID: userID})
+	if err != nil {
+		return purgeCounts{}, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	counts.Sessions = int64(len(listResp.Sessions))
+
+	if err := userRows(ctx, db, "events", appName, userID).Count(&counts.Events).Error; err != nil {
+		return purgeCounts{}, fmt.Errorf("failed to count events: %w", err)
+	}
+	if err := userRows(ctx, db, "user_states", appName, userID).Count(&counts.UserStates).Error; err != nil {
+		return purgeCounts{}, fmt.Errorf("failed to count user state: %w", err)
+	}
+	counts.Reminders, err = store.NewGormReminderStore(db, appName, userID).Count(ctx)
+	if err != nil {
+		return purgeCounts{}, err
+	}
+	return counts, nil
+}
+
+// purgeUserData deletes every record of userID in appName and returns how many were deleted.
+// Other users' data is never touched. A failure stops the purge and returns what was deleted
+// so far; running it again finishes the job.
+func purgeUserData(ctx context.Context, sessionService session.Service, db *gorm.DB, appName, userID string) (purgeCounts, error) {
+	if userID == "" {
+		return purgeCounts{}, errors.New("a user ID is required")
+	}
+
+	var deleted purgeCounts
+
+	// Events first, so the sessions they belong to can be deleted even when foreign keys are enforced
+	result := db.WithContext(ctx).Exec("DELETE FROM events WHERE app_name = ? AND user_id = ?", appName, userID)
+	if result.Error != nil {
+		return deleted, fmt.Errorf("failed to delete events: %w", result.Error)
+	}
+	deleted.Events = result.RowsAffected
+
+	listResp, err := sessionService.List(ctx, &session.ListRequest{AppName: appName, UserID: userID})
+	if err != nil {
+		return deleted, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, sess := range listResp.Sessions {
+		if err := sessionService.Delete(ctx, &session.DeleteRequest{
+			AppName:   appName,
+			UserID:    userID,
+			SessionID: sess.ID(),
+		}); err != nil {
+			return deleted, fmt.Errorf("failed to delete session %s: %w", sess.ID(), err)
+		}
+		deleted.Sessions++
+	}
+
+	result = db.WithContext(ctx).Exec("DELETE FROM user_states WHERE app_name = ? AND user_id = ?", appName, userID)
+	if result.Error != nil {
+		return deleted, fmt.Errorf("failed to delete user state: %w", result.Error)
+	}
+	deleted.UserStates = result.RowsAffected
+
+	deleted.Reminders, err = store.NewGormReminderStore(db, appName, userID).DeleteAll(ctx)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// userRows selects the rows of table that belong to userID in appName
+func userRows(ctx context.Context, db *gorm.DB, table, appName, userID string) *gorm.DB {
+	return db.WithContext(ctx).Table(table).Where("app_name = ? AND user_id = ?", appName, userID)
+}
+
+// runPurge shows what is stored for userID and deletes it once confirmed. Without confirmed,
+// the user ID has to be typed back on in.
+func runPurge(ctx context.Context, sessionService session.Service, db *gorm.DB, appName, userID string, confirmed bool, in io.Reader) error {
+	counts, err := countUserData(ctx, sessionService, db, appName, userID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n🗑️  Data stored for user %q in %q: %s\n", userID, appName, counts)
+	if counts.Total() == 0 {
+		fmt.Println("Nothing to delete.")
+		return nil
+	}
+
+	if !confirmed {
+		fmt.Printf("This can't be undone. Type the user ID (%s) to delete it all, or anything else to cancel: ", userID)
+		answer, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if strings.TrimSpace(answer) != userID {
+			fmt.Println("Canceled; nothing was deleted.")
+			return nil
+		}
+	}
+
+	deleted, err := purgeUserData(ctx, sessionService, db, appName, userID)
+	if err != nil {
+		return fmt.Errorf("purge stopped after deleting %s: %w", deleted, err)
+	}
+	fmt.Printf("✅ Deleted %d record(s): %s\n", deleted.Total(), deleted)
+	return nil
+}
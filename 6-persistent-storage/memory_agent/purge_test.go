@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+)
+
+// newPurgeTestDB opens the session service and the store tables on one temporary SQLite file
+func newPurgeTestDB(t *testing.T) (session.Service, *gorm.DB) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "purge.db")
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	sessionService, err := database.NewSessionService(sqlite.Open(path), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.AutoMigrate(sessionService); err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(sqlite.Open(path), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return sessionService, db
+}
+
+// addUserData gives userID two sessions with one event each, user-scoped state and a reminder
+func addUserData(t *testing.T, sessionService session.Service, db *gorm.DB, userID string) {
+	t.Helper()
+	ctx := context.Background()
+	for range 2 {
+		sessionID, err := createSession(ctx, sessionService, APP_NAME, userID, defaultInitialState())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: APP_NAME, UserID: userID, SessionID: sessionID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := session.NewEvent("invocation")
+		event.Author = "user"
+		event.Content = genai.NewContentFromText("hello", genai.RoleUser)
+		event.Actions.StateDelta["user:nickname"] = userID
+		if err := sessionService.AppendEvent(ctx, resp.Session, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.NewGormReminderStore(db, APP_NAME, userID).Add(ctx, "buy milk"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeUserDataDeletesOnlyTargetUser(t *testing.T) {
+	ctx := context.Background()
+	sessionService, db := newPurgeTestDB(t)
+	addUserData(t, sessionService, db, "alice")
+	addUserData(t, sessionService, db, "bob")
+
+	want := purgeCounts{Sessions: 2, Events: 2, UserStates: 1, Reminders: 1}
+	if counts, err := countUserData(ctx, sessionService, db, APP_NAME, "alice"); err != nil || counts != want {
+		t.Fatalf("countUserData(alice) = %+v, %v, want %+v", counts, err, want)
+	}
+
+	deleted, err := purgeUserData(ctx, sessionService, db, APP_NAME, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != want {
+		t.Errorf("purgeUserData(alice) = %+v, want %+v", deleted, want)
+	}
+
+	if left, err := countUserData(ctx, sessionService, db, APP_NAME, "alice"); err != nil || left.Total() != 0 {
+		t.Errorf("alice's data after purge = %+v, %v, want none", left, err)
+	}
+	if bob, err := countUserData(ctx, sessionService, db, APP_NAME, "bob"); err != nil || bob != want {
+		t.Errorf("bob's data after purge = %+v, %v, want %+v untouched", bob, err, want)
+	}
+}
+
+func TestPurgeUserDataRequiresUserID(t *testing.T) {
+	sessionService, db := newPurgeTestDB(t)
+	addUserData(t, sessionService, db, "alice")
+
+	if _, err := purgeUserData(context.Background(), sessionService, db, APP_NAME, ""); err == nil {
+		t.Error("purgeUserData with an empty user ID succeeded, want an error")
+	}
+	if counts, _ := countUserData(context.Background(), sessionService, db, APP_NAME, "alice"); counts.Total() == 0 {
+		t.Error("alice's data was deleted by a purge without a user ID")
+	}
+}
+
+func TestRunPurgeAsksForConfirmation(t *testing.T) {
+	ctx := context.Background()
+	sessionService, db := newPurgeTestDB(t)
+	addUserData(t, sessionService, db, "alice")
+
+	// Anything but the user ID cancels
+	if err := runPurge(ctx, sessionService, db, APP_NAME, "alice", false, strings.NewReader("yes\n")); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := countUserData(ctx, sessionService, db, APP_NAME, "alice"); counts.Total() == 0 {
+		t.Fatal("data was deleted without typing the user ID")
+	}
+
+	if err := runPurge(ctx, sessionService, db, APP_NAME, "alice", false, strings.NewReader("alice\n")); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := countUserData(ctx, sessionService, db, APP_NAME, "alice"); counts.Total() != 0 {
+		t.Errorf("data left after confirming = %+v, want none", counts)
+	}
+}
+
+func TestRunPurgeWithConfirmFlagDoesNotAsk(t *testing.T) {
+	ctx := context.Background()
+	sessionService, db := newPurgeTestDB(t)
+	addUserData(t, sessionService, db, "alice")
+
+	if err := runPurge(ctx, sessionService, db, APP_NAME, "alice", true, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := countUserData(ctx, sessionService, db, APP_NAME, "alice"); counts.Total() != 0 {
+		t.Errorf("data left after -confirm = %+v, want none", counts)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+// ===== Session Labels =====
+//
+// Sessions are identified by UUID. rename_session stores a human label under session_label in
+// the session's state, so it is persisted by the session service like any other state change,
+// and /sessions lists every session with its label.
+
+const (
+	SESSION_LABEL_KEY        = "session_label"
+	MAX_SESSION_LABEL_LENGTH = 60 // in characters; longer labels are cut
+)
+
+type renameSessionArgs struct {
+	Label string `json:"label"`
+}
+
+type renameSessionResults struct {
+	Action   string `json:"action"`
+	Status   string `json:"status"` // success, unchanged or error
+	OldLabel string `json:"old_label,omitempty"`
+	Label    string `json:"label,omitempty"`
+	// Other sessions that already use the label; duplicates are allowed but the user is warned
+	DuplicateSessionIDs []string `json:"duplicate_session_ids,omitempty"`
+	Message             string   `json:"message"`
+}
+
+// sessionTools holds the session service rename_session checks for duplicate labels.
+// A nil service skips the check.
+type sessionTools struct {
+	sessions session.Service
+}
+
+func (t sessionTools) renameSession(ctx tool.Context, input renameSessionArgs) (renameSessionResults, error) {
+	label := normalizeSessionLabel(input.Label)
+	fmt.Printf("--- Tool: rename_session called with '%s' ---\n", label)
+
+	if label == "" {
+		return renameSessionResults{
+			Action:  "rename_session",
+			Status:  "error",
+			Message: "A session label can't be empty.",
+		}, nil
+	}
+
+	state := ctx.State()
+	oldLabel := getSessionLabel(state)
+	if label == oldLabel {
+		return renameSessionResults{
+			Action:   "rename_session",
+			Status:   "unchanged",
+			OldLabel: oldLabel,
+			Label:    label,
+			Message:  fmt.Sprintf("This session is already labeled '%s'", label),
+		}, nil
+	}
+
+	// Update state using Set() method - changes are persisted automatically
+	state.Set(SESSION_LABEL_KEY, label)
+
+	results := renameSessionResults{
+		Action:   "rename_session",
+		Status:   "success",
+		OldLabel: oldLabel,
+		Label:    label,
+		Message:  fmt.Sprintf("Labeled this session '%s'", label),
+	}
+	duplicates, err := t.sessionsLabeled(ctx, ctx.AppName(), ctx.UserID(), label, ctx.SessionID())
+	if err != nil {
+		// The label is saved either way; only the duplicate warning is lost
+		fmt.Printf("Error checking for duplicate session labels: %v\n", err)
+	}
+	if len(duplicates) > 0 {
+		results.DuplicateSessionIDs = duplicates
+		results.Message += fmt.Sprintf(". Warning: %d other session(s) already use this label: %s",
+			len(duplicates), strings.Join(duplicates, ", "))
+	}
+	return results, nil
+}
+
+// sessionsLabeled returns the IDs of the user's sessions, other than exceptID, labeled label
+func (t sessionTools) sessionsLabeled(ctx context.Context, appName, userID, label, exceptID string) ([]string, error) {
+	if t.sessions == nil {
+		return nil, nil
+	}
+	listResp, err := t.sessions.List(ctx, &session.ListRequest{AppName: appName, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, sess := range listResp.Sessions {
+		if sess.ID() != exceptID && strings.EqualFold(getSessionLabel(sess.State()), label) {
+			ids = append(ids, sess.ID())
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// normalizeSessionLabel turns control characters (newlines, tabs, escape codes) into spaces,
+// collapses whitespace and cuts the label to MAX_SESSION_LABEL_LENGTH characters. Other
+// characters, such as quotes, slashes and emoji, are kept as typed.
+func normalizeSessionLabel(label string) string {
+	label = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, label)
+	label = strings.Join(strings.Fields(label), " ")
+	if runes := []rune(label); len(runes) > MAX_SESSION_LABEL_LENGTH {
+		label = strings.TrimSpace(string(runes[:MAX_SESSION_LABEL_LENGTH]))
+	}
+	return label
+}
+
+func getSessionLabel(state session.ReadonlyState) string {
+	val, err := state.Get(SESSION_LABEL_KEY)
+	if err != nil {
+		return ""
+	}
+	label, _ := val.(string)
+	return label
+}
+
+// sessionName is the session ID followed by its label, if it has one
+func sessionName(sess session.Session) string {
+	if label := getSessionLabel(sess.State()); label != "" {
+		return fmt.Sprintf("%s (%q)", sess.ID(), label)
+	}
+	return sess.ID()
+}
+
+// displaySessions prints the user's sessions, most recently updated first, marking the current one
+func displaySessions(ctx context.Context, sessionService session.Service, appName, userID, currentID string) {
+	listResp, err := sessionService.List(ctx, &session.ListRequest{AppName: appName, UserID: userID})
+	if err != nil {
+		fmt.Printf("Error listing sessions: %v\n", err)
+		return
+	}
+
+	sessions := slices.Clone(listResp.Sessions)
+	slices.SortFunc(sessions, func(a, b session.Session) int {
+		return b.LastUpdateTime().Compare(a.LastUpdateTime())
+	})
+
+	fmt.Printf("\n---------- Sessions (%d) ----------\n", len(sessions))
+	for _, sess := range sessions {
+		marker := " "
+		if sess.ID() == currentID {
+			marker = "*"
+		}
+		fmt.Printf("%s %s  updated %s\n", marker, sessionName(sess), sess.LastUpdateTime().Local().Format(DUE_DATE_FORMAT))
+	}
+	fmt.Println("-----------------------------------")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/session"
+)
+
+func TestNormalizeSessionLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"  Trip planning ", "Trip planning"},
+		{"Work\n\tstuff", "Work stuff"},
+		{"Q3 \"budget\" / review 🚀", "Q3 \"budget\" / review 🚀"},
+		{"red\x1b[31malert", "red [31malert"},
+		{" \n ", ""},
+		{strings.Repeat("é", MAX_SESSION_LABEL_LENGTH+5), strings.Repeat("é", MAX_SESSION_LABEL_LENGTH)},
+	}
+	for _, tt := range tests {
+		if got := normalizeSessionLabel(tt.input); got != tt.want {
+			t.Errorf("normalizeSessionLabel(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSessionsLabeled(t *testing.T) {
+	ctx := context.Background()
+	sessionService := session.InMemoryService()
+	for id, label := range map[string]string{"a": "Trip planning", "b": "trip PLANNING", "c": "Work", "d": ""} {
+		state := defaultInitialState()
+		if label != "" {
+			state[SESSION_LABEL_KEY] = label
+		}
+		if _, err := sessionService.Create(ctx, &session.CreateRequest{
+			AppName: APP_NAME, UserID: TEST_USER_ID, SessionID: id, State: state,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tools := sessionTools{sessions: sessionService}
+	got, err := tools.sessionsLabeled(ctx, APP_NAME, TEST_USER_ID, "Trip planning", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"b"}) {
+		t.Errorf("sessionsLabeled(Trip planning, except a) = %v, want [b]", got)
+	}
+
+	if got, _ := (sessionTools{}).sessionsLabeled(ctx, APP_NAME, TEST_USER_ID, "Work", ""); got != nil {
+		t.Errorf("sessionsLabeled without a session service = %v, want nil", got)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+)
+
+// ===== Snoozing and Due Dates =====
+//
+// snooze_reminder pushes a reminder's due date forward by a duration such as "1h", "2 days",
+// "tomorrow" or "next week". The new due date is counted from the current one, or from now
+// when the reminder has no due date or is already overdue, so snoozing never lands in the past.
+
+const (
+	DUE_SOON_WINDOW = 24 * time.Hour // reminders due within this are listed under "Due soon"
+	DUE_DATE_FORMAT = "Mon 2 Jan 2006 15:04"
+)
+
+// snoozeDuration is a parsed snooze; days and months are calendar steps, so "tomorrow" keeps
+// the time of day across daylight saving changes
+type snoozeDuration struct {
+	months int
+	days   int
+	clock  time.Duration
+}
+
+// snoozeAmountRe matches "2 days", "in 3h", "an hour", "1 week"
+var snoozeAmountRe = regexp.MustCompile(`^(?:in\s+)?(\d+|an?|one)\s*([a-z]+)$`)
+
+// snoozeUnits maps unit spellings to a one-unit snooze
+var snoozeUnits = map[string]snoozeDuration{
+	"m": {clock: time.Minute}, "min": {clock: time.Minute}, "mins": {clock: time.Minute},
+	"minute": {clock: time.Minute}, "minutes": {clock: time.Minute},
+	"h": {clock: time.Hour}, "hr": {clock: time.Hour}, "hrs": {clock: time.Hour},
+	"hour": {clock: time.Hour}, "hours": {clock: time.Hour},
+	"d": {days: 1}, "day": {days: 1}, "days": {days: 1},
+	"w": {days: 7}, "wk": {days: 7}, "wks": {days: 7}, "week": {days: 7}, "weeks": {days: 7},
+	"mo": {months: 1}, "month": {months: 1}, "months": {months: 1},
+}
+
+// parseSnooze reads a snooze duration: a Go duration ("1h30m"), an amount with a unit
+// ("2 days", "in 3 hours", "an hour") or "tomorrow", "next week" or "next month"
+func parseSnooze(input string) (snoozeDuration, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	switch text {
+	case "":
+		return snoozeDuration{}, fmt.Errorf("no snooze duration given")
+	case "tomorrow":
+		return snoozeDuration{days: 1}, nil
+	case "next week":
+		return snoozeDuration{days: 7}, nil
+	case "next month":
+		return snoozeDuration{months: 1}, nil
+	}
+
+	if d, err := time.ParseDuration(text); err == nil {
+		if d <= 0 {
+			return snoozeDuration{}, fmt.Errorf("snooze duration %q must be positive", input)
+		}
+		return snoozeDuration{clock: d}, nil
+	}
+
+	match := snoozeAmountRe.FindStringSubmatch(text)
+	if match == nil {
+		return snoozeDuration{}, fmt.Errorf("unrecognized snooze duration %q", input)
+	}
+	unit, ok := snoozeUnits[match[2]]
+	if !ok {
+		return snoozeDuration{}, fmt.Errorf("unrecognized time unit %q in %q", match[2], input)
+	}
+	amount := 1
+	if n, err := strconv.Atoi(match[1]); err == nil {
+		amount = n
+	}
+	if amount <= 0 {
+		return snoozeDuration{}, fmt.Errorf("snooze duration %q must be positive", input)
+	}
+	return snoozeDuration{
+		months: unit.months * amount,
+		days:   unit.days * amount,
+		clock:  unit.clock * time.Duration(amount),
+	}, nil
+}
+
+// snoozedDue returns the new due date of a reminder due at current (zero for none)
+func snoozedDue(current, now time.Time, snooze snoozeDuration) time.Time {
+	base := now
+	if current.After(now) {
+		base = current
+	}
+	return base.AddDate(0, snooze.months, snooze.days).Add(snooze.clock)
+}
+
+func formatDue(due time.Time) string {
+	return due.Local().Format(DUE_DATE_FORMAT)
+}
+
+// dueReminders returns the reminders that are overdue and those due within DUE_SOON_WINDOW,
+// each soonest first
+func dueReminders(reminders []store.Reminder, now time.Time) (overdue, dueSoon []store.Reminder) {
+	for _, r := range reminders {
+		switch {
+		case r.DueAt.IsZero():
+		case !r.DueAt.After(now):
+			overdue = append(overdue, r)
+		case r.DueAt.Sub(now) <= DUE_SOON_WINDOW:
+			dueSoon = append(dueSoon, r)
+		}
+	}
+	byDue := func(list []store.Reminder) {
+		slices.SortStableFunc(list, func(a, b store.Reminder) int { return a.DueAt.Compare(b.DueAt) })
+	}
+	byDue(overdue)
+	byDue(dueSoon)
+	return overdue, dueSoon
+}
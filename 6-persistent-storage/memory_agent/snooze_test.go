@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+)
+
+func TestParseSnooze(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"1h", now.Add(time.Hour)},
+		{"1h30m", now.Add(90 * time.Minute)},
+		{"30 minutes", now.Add(30 * time.Minute)},
+		{"in 2 Hours", now.Add(2 * time.Hour)},
+		{"an hour", now.Add(time.Hour)},
+		{"3d", now.AddDate(0, 0, 3)},
+		{"tomorrow", now.AddDate(0, 0, 1)},
+		{"next  week", now.AddDate(0, 0, 7)},
+		{"2 weeks", now.AddDate(0, 0, 14)},
+		{"next month", now.AddDate(0, 1, 0)},
+	}
+	for _, tt := range tests {
+		snooze, err := parseSnooze(tt.input)
+		if err != nil {
+			t.Errorf("parseSnooze(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got := snoozedDue(time.Time{}, now, snooze); !got.Equal(tt.want) {
+			t.Errorf("parseSnooze(%q) from now = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "later", "5 fortnights", "0h", "-1h", "0 days", "soonish"} {
+		if _, err := parseSnooze(input); err == nil {
+			t.Errorf("parseSnooze(%q): expected an error", input)
+		}
+	}
+}
+
+func TestSnoozedDueCountsFromLaterOfDueAndNow(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	hour := snoozeDuration{clock: time.Hour}
+
+	if got, want := snoozedDue(now.Add(2*time.Hour), now, hour), now.Add(3*time.Hour); !got.Equal(want) {
+		t.Errorf("future due date: got %v, want it pushed to %v", got, want)
+	}
+	// An overdue reminder is snoozed from now, not to another time in the past
+	if got, want := snoozedDue(now.Add(-5*time.Hour), now, hour), now.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("overdue: got %v, want %v", got, want)
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	reminders := []store.Reminder{
+		{Index: 1, Text: "no due date"},
+		{Index: 2, Text: "next week", DueAt: now.AddDate(0, 0, 7)},
+		{Index: 3, Text: "tonight", DueAt: now.Add(10 * time.Hour)},
+		{Index: 4, Text: "yesterday", DueAt: now.Add(-24 * time.Hour)},
+		{Index: 5, Text: "in an hour", DueAt: now.Add(time.Hour)},
+	}
+
+	overdue, dueSoon := dueReminders(reminders, now)
+	if len(overdue) != 1 || overdue[0].Index != 4 {
+		t.Errorf("overdue = %+v, want reminder 4", overdue)
+	}
+	if len(dueSoon) != 2 || dueSoon[0].Index != 5 || dueSoon[1].Index != 3 {
+		t.Errorf("due soon = %+v, want reminders 5 then 3", dueSoon)
+	}
+}
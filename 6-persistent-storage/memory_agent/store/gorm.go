@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reminderRecord is a row of the reminders table. Rows are scoped by app and user, not by
+// session, so every session of a user sees the same reminders.
+type reminderRecord struct {
+	ID        uint   `gorm:"primaryKey"`
+	AppName   string `gorm:"index:idx_reminders_owner;not null"`
+	UserID    string `gorm:"index:idx_reminders_owner;not null"`
+	Text      string `gorm:"not null"`
+	Pinned    bool   `gorm:"not null;default:false"`
+	DueAt     *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (reminderRecord) TableName() string { return "reminders" }
+
+// AutoMigrate creates or updates the reminders and usage_stats tables
+func AutoMigrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&reminderRecord{}); err != nil {
+		return fmt.Errorf("failed to migrate reminders table: %w", err)
+	}
+	if err := db.AutoMigrate(&usageRecord{}); err != nil {
+		return fmt.Errorf("failed to migrate usage_stats table: %w", err)
+	}
+	return nil
+}
+
+// GormReminderStore keeps one user's reminders in the reminders table. Call AutoMigrate once
+// before using it.
+type GormReminderStore struct {
+	db      *gorm.DB
+	appName string
+	userID  string
+}
+
+// NewGormReminderStore creates a store for the reminders of userID in appName
+func NewGormReminderStore(db *gorm.DB, appName, userID string) *GormReminderStore {
+	return &GormReminderStore{db: db, appName: appName, userID: userID}
+}
+
+func (s *GormReminderStore) Add(ctx context.Context, text string) (Reminder, error) {
+	record := reminderRecord{AppName: s.appName, UserID: s.userID, Text: text}
+	if err := s.owned(ctx).Create(&record).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to add reminder: %w", err)
+	}
+
+	return s.withIndex(ctx, record)
+}
+
+func (s *GormReminderStore) List(ctx context.Context) ([]Reminder, error) {
+	records, err := s.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reminders := make([]Reminder, 0, len(records))
+	for i, record := range records {
+		reminders = append(reminders, toReminder(i+1, record))
+	}
+	return reminders, nil
+}
+
+func (s *GormReminderStore) Update(ctx context.Context, id int, text string) (Reminder, error) {
+	old, err := s.reminder(ctx, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+
+	if err := s.owned(ctx).Model(&reminderRecord{ID: uint(id)}).Update("text", text).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to update reminder %d: %w", id, err)
+	}
+	return old, nil
+}
+
+func (s *GormReminderStore) Delete(ctx context.Context, id int) (Reminder, error) {
+	deleted, err := s.reminder(ctx, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+
+	if err := s.owned(ctx).Delete(&reminderRecord{ID: uint(id)}).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to delete reminder %d: %w", id, err)
+	}
+	return deleted, nil
+}
+
+func (s *GormReminderStore) Search(ctx context.Context, query string) ([]Reminder, error) {
+	// Indexes are positions in the full list, so match in Go rather than with LIKE
+	// (which would also need escaping of % and _ in the query)
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []Reminder
+	for _, r := range all {
+		if strings.Contains(strings.ToLower(r.Text), query) {
+			matches = append(matches, r)
+		}
+	}
+	return matches, nil
+}
+
+func (s *GormReminderStore) SetPinned(ctx context.Context, id int, pinned bool) (Reminder, error) {
+	reminder, err := s.reminder(ctx, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+
+	if err := s.owned(ctx).Model(&reminderRecord{ID: uint(id)}).Update("pinned", pinned).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to pin reminder %d: %w", id, err)
+	}
+	reminder.Pinned = pinned
+	return reminder, nil
+}
+
+func (s *GormReminderStore) SetDue(ctx context.Context, id int, due time.Time) (Reminder, error) {
+	old, err := s.reminder(ctx, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+
+	if err := s.owned(ctx).Model(&reminderRecord{ID: uint(id)}).Update("due_at", due).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to set the due date of reminder %d: %w", id, err)
+	}
+	return old, nil
+}
+
+// DeleteAll removes every reminder of this store's user and returns how many were deleted.
+// It isn't part of ReminderStore: it is only used to purge a user's data.
+func (s *GormReminderStore) DeleteAll(ctx context.Context) (int64, error) {
+	result := s.owned(ctx).Delete(&reminderRecord{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete reminders: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Count returns how many reminders this store's user has
+func (s *GormReminderStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := s.owned(ctx).Model(&reminderRecord{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count reminders: %w", err)
+	}
+	return count, nil
+}
+
+// owned scopes queries to this store's app and user
+func (s *GormReminderStore) owned(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Where("app_name = ? AND user_id = ?", s.appName, s.userID)
+}
+
+// records returns all reminders in List order
+func (s *GormReminderStore) records(ctx context.Context) ([]reminderRecord, error) {
+	var records []reminderRecord
+	if err := s.owned(ctx).Order("id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+	return records, nil
+}
+
+// reminder returns this user's reminder with id; another user's reminder is not found
+func (s *GormReminderStore) reminder(ctx context.Context, id int) (Reminder, error) {
+	var record reminderRecord
+	err := s.owned(ctx).Where("id = ?", id).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Reminder{}, fmt.Errorf("%w: id %d", ErrReminderNotFound, id)
+	}
+	if err != nil {
+		return Reminder{}, fmt.Errorf("failed to get reminder %d: %w", id, err)
+	}
+	return s.withIndex(ctx, record)
+}
+
+// withIndex converts a record, counting the user's reminders up to it for its index
+func (s *GormReminderStore) withIndex(ctx context.Context, record reminderRecord) (Reminder, error) {
+	var count int64
+	if err := s.owned(ctx).Model(&reminderRecord{}).Where("id <= ?", record.ID).Count(&count).Error; err != nil {
+		return Reminder{}, fmt.Errorf("failed to count reminders: %w", err)
+	}
+	return toReminder(int(count), record), nil
+}
+
+func toReminder(index int, record reminderRecord) Reminder {
+	reminder := Reminder{ID: int(record.ID), Index: index, Text: record.Text, Pinned: record.Pinned, CreatedAt: record.CreatedAt}
+	if record.DueAt != nil {
+		reminder.DueAt = *record.DueAt
+	}
+	return reminder
+}
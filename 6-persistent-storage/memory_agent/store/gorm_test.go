@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "reminders.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func addAll(t *testing.T, s ReminderStore, texts ...string) []Reminder {
+	t.Helper()
+	var added []Reminder
+	for _, text := range texts {
+		reminder, err := s.Add(context.Background(), text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		added = append(added, reminder)
+	}
+	return added
+}
+
+func texts(t *testing.T, s ReminderStore) []string {
+	t.Helper()
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for i, r := range reminders {
+		if r.Index != i+1 {
+			t.Errorf("reminder %q has index %d, want %d", r.Text, r.Index, i+1)
+		}
+		result = append(result, r.Text)
+	}
+	return result
+}
+
+func TestAutoMigrateIsRepeatable(t *testing.T) {
+	db := newTestDB(t)
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("second AutoMigrate failed: %v", err)
+	}
+	if !db.Migrator().HasTable("reminders") {
+		t.Error("reminders table was not created")
+	}
+}
+
+func TestGormReminderStoreAddAndList(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+
+	added, err := s.Add(context.Background(), "buy milk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID == 0 || added.Index != 1 || added.Text != "buy milk" || added.CreatedAt.IsZero() {
+		t.Errorf("Add() = %+v, want an ID, index 1 and a creation time", added)
+	}
+	addAll(t, s, "call mom")
+
+	if got, want := texts(t, s), []string{"buy milk", "call mom"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestGormReminderStoreUpdate(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+	added := addAll(t, s, "buy milk", "call mom")
+
+	old, err := s.Update(context.Background(), added[1].ID, "call dad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Text != "call mom" {
+		t.Errorf("Update() returned %q, want the previous text", old.Text)
+	}
+	if got, want := texts(t, s), []string{"buy milk", "call dad"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+
+	if _, err := s.Update(context.Background(), added[1].ID+1, "nope"); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("Update(unknown ID) error = %v, want ErrReminderNotFound", err)
+	}
+}
+
+func TestGormReminderStoreDeleteRenumbers(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+	added := addAll(t, s, "buy milk", "call mom", "pay rent")
+
+	deleted, err := s.Delete(context.Background(), added[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.Text != "buy milk" {
+		t.Errorf("Delete() returned %q, want buy milk", deleted.Text)
+	}
+	if got, want := texts(t, s), []string{"call mom", "pay rent"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+
+	// Indexes move up, IDs stay; a new reminder doesn't reuse the deleted ID
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reminders[1].ID != added[2].ID || reminders[1].Index != 2 {
+		t.Errorf("pay rent = %+v, want ID %d at index 2", reminders[1], added[2].ID)
+	}
+	if again := addAll(t, s, "walk dog"); again[0].ID == added[0].ID {
+		t.Errorf("new reminder reused deleted ID %d", again[0].ID)
+	}
+
+	if _, err := s.Delete(context.Background(), added[0].ID); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("Delete(deleted ID) error = %v, want ErrReminderNotFound", err)
+	}
+}
+
+func TestGormReminderStoreSearch(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+	addAll(t, s, "Buy milk", "call mom", "buy 100% cotton socks")
+
+	matches, err := s.Search(context.Background(), "BUY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Index != 1 || matches[1].Index != 3 {
+		t.Errorf("Search(BUY) = %+v, want reminders 1 and 3", matches)
+	}
+
+	matches, err = s.Search(context.Background(), "100%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Text != "buy 100% cotton socks" {
+		t.Errorf("Search(100%%) = %+v, want only the socks reminder", matches)
+	}
+}
+
+func TestGormReminderStoreScopesByUser(t *testing.T) {
+	db := newTestDB(t)
+	alice := NewGormReminderStore(db, "app", "alice")
+	bob := NewGormReminderStore(db, "app", "bob")
+	otherApp := NewGormReminderStore(db, "other", "alice")
+	addAll(t, alice, "buy milk")
+	bobs := addAll(t, bob, "walk dog", "feed cat")
+
+	if got := texts(t, alice); !slices.Equal(got, []string{"buy milk"}) {
+		t.Errorf("alice's reminders = %v, want only her own", got)
+	}
+	if got := texts(t, otherApp); len(got) != 0 {
+		t.Errorf("other app's reminders = %v, want none", got)
+	}
+
+	// IDs are shared by the whole table, but alice can't delete bob's reminder by its ID
+	if _, err := alice.Delete(context.Background(), bobs[0].ID); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("alice.Delete(bob's ID) error = %v, want ErrReminderNotFound", err)
+	}
+	if got := texts(t, bob); !slices.Equal(got, []string{"walk dog", "feed cat"}) {
+		t.Errorf("bob's reminders = %v, want them untouched", got)
+	}
+}
+
+func TestGormReminderStoreDeleteAllOnlyOwnReminders(t *testing.T) {
+	db := newTestDB(t)
+	alice := NewGormReminderStore(db, "app", "alice")
+	bob := NewGormReminderStore(db, "app", "bob")
+	otherApp := NewGormReminderStore(db, "other", "alice")
+	addAll(t, alice, "buy milk", "call mom")
+	addAll(t, bob, "walk dog")
+	addAll(t, otherApp, "water plants")
+
+	if count, err := alice.Count(context.Background()); err != nil || count != 2 {
+		t.Fatalf("Count() = %d, %v, want 2", count, err)
+	}
+	deleted, err := alice.DeleteAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteAll() = %d, want 2", deleted)
+	}
+	if got := texts(t, alice); len(got) != 0 {
+		t.Errorf("alice's reminders = %v, want none", got)
+	}
+	if got := texts(t, bob); !slices.Equal(got, []string{"walk dog"}) {
+		t.Errorf("bob's reminders = %v, want them untouched", got)
+	}
+	if got := texts(t, otherApp); !slices.Equal(got, []string{"water plants"}) {
+		t.Errorf("other app's reminders = %v, want them untouched", got)
+	}
+}
+
+func TestGormReminderStoreSharedAcrossInstances(t *testing.T) {
+	db := newTestDB(t)
+	addAll(t, NewGormReminderStore(db, "app", "alice"), "buy milk")
+
+	// A store created later (e.g. in another session) sees the same reminders
+	if got := texts(t, NewGormReminderStore(db, "app", "alice")); !slices.Equal(got, []string{"buy milk"}) {
+		t.Errorf("List() = %v, want the reminder added through the first store", got)
+	}
+}
+
+func TestGormReminderStorePinFollowsReminder(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+	added := addAll(t, s, "buy milk", "call mom", "pay rent")
+
+	pinned, err := s.SetPinned(context.Background(), added[2].ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned.Pinned || pinned.Text != "pay rent" || pinned.Index != 3 {
+		t.Errorf("SetPinned() = %+v, want pay rent pinned at index 3", pinned)
+	}
+
+	// Deleting an earlier reminder moves pay rent to index 2; the pin moves with it
+	if _, err := s.Delete(context.Background(), added[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reminders) != 2 || reminders[0].Pinned || !reminders[1].Pinned {
+		t.Errorf("List() = %+v, want only pay rent pinned", reminders)
+	}
+
+	if _, err := s.SetPinned(context.Background(), added[0].ID, false); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("SetPinned(deleted ID) error = %v, want ErrReminderNotFound", err)
+	}
+}
+
+func TestGormReminderStoreSetDue(t *testing.T) {
+	s := NewGormReminderStore(newTestDB(t), "app", "alice")
+	added := addAll(t, s, "buy milk")
+	due := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	old, err := s.SetDue(context.Background(), added[0].ID, due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !old.DueAt.IsZero() {
+		t.Errorf("SetDue() returned due %v, want the previous (empty) due date", old.DueAt)
+	}
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reminders[0].DueAt.Equal(due) {
+		t.Errorf("due = %v, want %v", reminders[0].DueAt, due)
+	}
+
+	if _, err := s.SetDue(context.Background(), added[0].ID+1, due); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("SetDue(unknown ID) error = %v, want ErrReminderNotFound", err)
+	}
+}
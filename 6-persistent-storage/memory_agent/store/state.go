@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// Session state keys the in-state store uses
+const (
+	REMINDERS_STATE_KEY         = "reminders"
+	REMINDERS_NEXT_ID_STATE_KEY = "reminders_next_id" // counter for reminder IDs, so deleted IDs aren't reused
+)
+
+// StateReminderStore keeps reminders as a list in session state, so they belong to a single
+// session and are saved with its events. Each item is an {"id", "text", "pinned"} object,
+// plus "due_at" (RFC 3339) when the reminder has a due date.
+// Items written by earlier versions (plain strings, or objects without an id) are given IDs
+// in list order when loaded; the IDs are saved with the next change.
+type StateReminderStore struct {
+	state session.State
+}
+
+// NewStateReminderStore creates a store backed by the given session state
+func NewStateReminderStore(state session.State) *StateReminderStore {
+	return &StateReminderStore{state: state}
+}
+
+// stateReminder is one item of the reminders list
+type stateReminder struct {
+	ID     int
+	Text   string
+	Pinned bool
+	DueAt  time.Time
+}
+
+func (s *StateReminderStore) Add(_ context.Context, text string) (Reminder, error) {
+	items, nextID := s.items()
+	items = append(items, stateReminder{ID: nextID, Text: text})
+	if err := s.save(items, nextID+1); err != nil {
+		return Reminder{}, err
+	}
+	return items[len(items)-1].toReminder(len(items)), nil
+}
+
+func (s *StateReminderStore) List(_ context.Context) ([]Reminder, error) {
+	items, _ := s.items()
+	reminders := make([]Reminder, 0, len(items))
+	for i, item := range items {
+		reminders = append(reminders, item.toReminder(i+1))
+	}
+	return reminders, nil
+}
+
+func (s *StateReminderStore) Update(_ context.Context, id int, text string) (Reminder, error) {
+	items, nextID := s.items()
+	pos, err := findStateReminder(items, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	old := items[pos].toReminder(pos + 1)
+	items[pos].Text = text
+	if err := s.save(items, nextID); err != nil {
+		return Reminder{}, err
+	}
+	return old, nil
+}
+
+func (s *StateReminderStore) Delete(_ context.Context, id int) (Reminder, error) {
+	items, nextID := s.items()
+	pos, err := findStateReminder(items, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	deleted := items[pos].toReminder(pos + 1)
+	items = append(items[:pos], items[pos+1:]...)
+	if err := s.save(items, nextID); err != nil {
+		return Reminder{}, err
+	}
+	return deleted, nil
+}
+
+func (s *StateReminderStore) Search(ctx context.Context, query string) ([]Reminder, error) {
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []Reminder
+	for _, r := range all {
+		if strings.Contains(strings.ToLower(r.Text), query) {
+			matches = append(matches, r)
+		}
+	}
+	return matches, nil
+}
+
+func (s *StateReminderStore) SetPinned(_ context.Context, id int, pinned bool) (Reminder, error) {
+	items, nextID := s.items()
+	pos, err := findStateReminder(items, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	items[pos].Pinned = pinned
+	if err := s.save(items, nextID); err != nil {
+		return Reminder{}, err
+	}
+	return items[pos].toReminder(pos + 1), nil
+}
+
+func (s *StateReminderStore) SetDue(_ context.Context, id int, due time.Time) (Reminder, error) {
+	items, nextID := s.items()
+	pos, err := findStateReminder(items, id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	old := items[pos].toReminder(pos + 1)
+	items[pos].DueAt = due
+	if err := s.save(items, nextID); err != nil {
+		return Reminder{}, err
+	}
+	return old, nil
+}
+
+// items reads the reminders list and the next free ID. Fresh values are []map[string]any,
+// after a database round trip []any (with float64 numbers); sessions from before IDs hold
+// plain strings or objects without an id, which get the next IDs in list order.
+func (s *StateReminderStore) items() ([]stateReminder, int) {
+	items := []stateReminder{}
+	if val, err := s.state.Get(REMINDERS_STATE_KEY); err == nil {
+		switch list := val.(type) {
+		case []string:
+			for _, text := range list {
+				items = append(items, stateReminder{Text: text})
+			}
+		case []map[string]any:
+			for _, m := range list {
+				if item, ok := toStateReminder(m); ok {
+					items = append(items, item)
+				}
+			}
+		case []any:
+			for _, r := range list {
+				if item, ok := toStateReminder(r); ok {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+
+	// The counter may be missing (older sessions) or behind the stored IDs
+	nextID := 1
+	if val, err := s.state.Get(REMINDERS_NEXT_ID_STATE_KEY); err == nil {
+		nextID = max(nextID, toInt(val))
+	}
+	for _, item := range items {
+		nextID = max(nextID, item.ID+1)
+	}
+	for i := range items {
+		if items[i].ID == 0 {
+			items[i].ID = nextID
+			nextID++
+		}
+	}
+	return items, nextID
+}
+
+func (s *StateReminderStore) save(items []stateReminder, nextID int) error {
+	list := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		m := map[string]any{"id": item.ID, "text": item.Text, "pinned": item.Pinned}
+		if !item.DueAt.IsZero() {
+			m["due_at"] = item.DueAt.Format(time.RFC3339)
+		}
+		list = append(list, m)
+	}
+	if err := s.state.Set(REMINDERS_STATE_KEY, list); err != nil {
+		return fmt.Errorf("failed to save reminders to state: %w", err)
+	}
+	if err := s.state.Set(REMINDERS_NEXT_ID_STATE_KEY, nextID); err != nil {
+		return fmt.Errorf("failed to save the next reminder ID to state: %w", err)
+	}
+	return nil
+}
+
+// findStateReminder returns the position of the reminder with id
+func findStateReminder(items []stateReminder, id int) (int, error) {
+	for i, item := range items {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: id %d", ErrReminderNotFound, id)
+}
+
+// toStateReminder converts one stored item, either a legacy string or an {"id", "text", "pinned"}
+// map; a missing id is left 0 for items to assign
+func toStateReminder(v any) (stateReminder, bool) {
+	switch item := v.(type) {
+	case string:
+		return stateReminder{Text: item}, true
+	case map[string]any:
+		text, ok := item["text"].(string)
+		if !ok {
+			return stateReminder{}, false
+		}
+		pinned, _ := item["pinned"].(bool)
+		var due time.Time
+		if str, ok := item["due_at"].(string); ok {
+			due, _ = time.Parse(time.RFC3339, str) // an unreadable date is treated as no due date
+		}
+		return stateReminder{ID: max(toInt(item["id"]), 0), Text: text, Pinned: pinned, DueAt: due}, true
+	}
+	return stateReminder{}, false
+}
+
+// toInt reads a number from state; after a database round trip numbers are float64
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+func (item stateReminder) toReminder(index int) Reminder {
+	return Reminder{ID: item.ID, Index: index, Text: item.Text, Pinned: item.Pinned, DueAt: item.DueAt}
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestStateReminderStoreAssignsIDsToLegacyReminders(t *testing.T) {
+	// Sessions saved before IDs hold plain strings, read back from the database as []any
+	s := NewStateReminderStore(agenttest.NewState(map[string]any{REMINDERS_STATE_KEY: []any{"buy milk", "call mom"}}, false))
+
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reminders) != 2 || reminders[0].ID != 1 || reminders[1].ID != 2 {
+		t.Fatalf("List() = %+v, want IDs 1 and 2 in list order", reminders)
+	}
+
+	// The assigned IDs work right away and are saved with the change
+	if _, err := s.SetPinned(context.Background(), 2, true); err != nil {
+		t.Fatal(err)
+	}
+	added := addAll(t, s, "pay rent")
+	if added[0].ID != 3 {
+		t.Errorf("Add() ID = %d, want 3", added[0].ID)
+	}
+	reminders, err = s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reminders[0].Pinned || !reminders[1].Pinned || reminders[1].ID != 2 {
+		t.Errorf("List() = %+v, want call mom (ID 2) pinned", reminders)
+	}
+}
+
+func TestStateReminderStoreIDsSurviveDeletes(t *testing.T) {
+	// A database round trip turns the saved list into []any with float64 numbers
+	s := NewStateReminderStore(agenttest.NewState(map[string]any{
+		REMINDERS_STATE_KEY: []any{
+			map[string]any{"id": float64(4), "text": "buy milk", "pinned": false},
+			map[string]any{"id": float64(7), "text": "call mom", "pinned": false},
+			map[string]any{"id": float64(9), "text": "pay rent", "pinned": false},
+		},
+		REMINDERS_NEXT_ID_STATE_KEY: float64(12),
+	}, false))
+
+	if _, err := s.SetPinned(context.Background(), 9, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(context.Background(), 4); err != nil {
+		t.Fatal(err)
+	}
+	old, err := s.Update(context.Background(), 9, "pay rent today")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Text != "pay rent" || old.Index != 2 {
+		t.Errorf("Update() = %+v, want the previous text at index 2", old)
+	}
+
+	if got, want := texts(t, s), []string{"call mom", "pay rent today"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	reminders, err := s.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reminders[1].Pinned || reminders[1].ID != 9 {
+		t.Errorf("List() = %+v, want pay rent (ID 9) still pinned", reminders)
+	}
+	// The counter, not the highest ID, decides the next ID
+	if added := addAll(t, s, "walk dog"); added[0].ID != 12 {
+		t.Errorf("Add() ID = %d, want 12", added[0].ID)
+	}
+
+	if _, err := s.SetPinned(context.Background(), 4, true); !errors.Is(err, ErrReminderNotFound) {
+		t.Errorf("SetPinned(deleted ID) error = %v, want ErrReminderNotFound", err)
+	}
+}
+
+func TestStateReminderStoreSetDue(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	s := NewStateReminderStore(state)
+	added := addAll(t, s, "buy milk", "call mom")
+	due := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	if _, err := s.SetDue(context.Background(), added[1].ID, due); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the saved list back as it comes out of the database
+	data, err := json.Marshal(state.Value(REMINDERS_STATE_KEY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip []any
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	reminders, err := NewStateReminderStore(agenttest.NewState(map[string]any{REMINDERS_STATE_KEY: roundTrip}, false)).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reminders[0].DueAt.IsZero() || !reminders[1].DueAt.Equal(due) {
+		t.Errorf("List() = %+v, want only call mom due at %v", reminders, due)
+	}
+}
@@ -0,0 +1,45 @@
+// Package store defines where the memory agent keeps reminders. The agent's tools only see
+// the ReminderStore interface, so reminders can live in session state (the original
+// behavior) or in a dedicated database table that can be queried outside the agent and
+// across sessions. The package also keeps the usage_stats table, the daily usage counts
+// shown by the -report mode.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReminderNotFound is returned by Update, Delete, SetPinned and SetDue when no reminder has the given ID
+var ErrReminderNotFound = errors.New("reminder not found")
+
+// Reminder is one reminder as shown to the user
+type Reminder struct {
+	ID        int       `json:"id"`    // stable, never reused; doesn't change when other reminders are deleted
+	Index     int       `json:"index"` // 1-based position in List order
+	Text      string    `json:"text"`
+	Pinned    bool      `json:"pinned,omitempty"`    // pinned reminders are shown before the others
+	DueAt     time.Time `json:"due_at,omitzero"`     // zero when the reminder has no due date
+	CreatedAt time.Time `json:"created_at,omitzero"` // zero for the in-state store, which doesn't track it
+}
+
+// ReminderStore keeps one user's reminders. Reminders are addressed by their stable ID;
+// the 1-based Index is only their current position in List order, which shifts on delete.
+// Callers that get a position from the user ("delete reminder 2") look the ID up with List.
+type ReminderStore interface {
+	// Add appends a reminder and returns it with its ID and index
+	Add(ctx context.Context, text string) (Reminder, error)
+	// List returns all reminders, oldest first
+	List(ctx context.Context) ([]Reminder, error)
+	// Update replaces the text of the reminder with id and returns the previous reminder
+	Update(ctx context.Context, id int, text string) (Reminder, error)
+	// Delete removes the reminder with id and returns it; later reminders move up by one index
+	Delete(ctx context.Context, id int) (Reminder, error)
+	// Search returns the reminders whose text contains query, ignoring case
+	Search(ctx context.Context, query string) ([]Reminder, error)
+	// SetPinned pins or unpins the reminder with id and returns it with the new state
+	SetPinned(ctx context.Context, id int, pinned bool) (Reminder, error)
+	// SetDue sets the due date of the reminder with id and returns the previous reminder
+	SetDue(ctx context.Context, id int, due time.Time) (Reminder, error)
+}
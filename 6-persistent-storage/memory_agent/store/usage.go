@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// USAGE_DAY_FORMAT is how days are stored in the usage_stats table; it sorts chronologically
+const USAGE_DAY_FORMAT = "2006-01-02"
+
+// usageRecord is a row of the usage_stats table: the running counts of one app for one day
+type usageRecord struct {
+	ID              uint   `gorm:"primaryKey"`
+	AppName         string `gorm:"uniqueIndex:idx_usage_stats_day;not null"`
+	Day             string `gorm:"uniqueIndex:idx_usage_stats_day;not null"` // USAGE_DAY_FORMAT, local time
+	Sessions        int64  `gorm:"not null;default:0"`
+	Messages        int64  `gorm:"not null;default:0"`
+	ToolCalls       int64  `gorm:"not null;default:0"`
+	EstimatedTokens int64  `gorm:"not null;default:0"`
+	UpdatedAt       time.Time
+}
+
+func (usageRecord) TableName() string { return "usage_stats" }
+
+// UsageCounts are the counts kept per day, or the amounts to add to them
+type UsageCounts struct {
+	Sessions        int64 `json:"sessions"`         // sessions created
+	Messages        int64 `json:"messages"`         // user messages sent to the agent
+	ToolCalls       int64 `json:"tool_calls"`       // tool calls made by the agent
+	EstimatedTokens int64 `json:"estimated_tokens"` // total tokens reported by the model
+}
+
+// IsZero reports whether every count is zero
+func (c UsageCounts) IsZero() bool {
+	return c == UsageCounts{}
+}
+
+// Add returns the sum of c and other
+func (c UsageCounts) Add(other UsageCounts) UsageCounts {
+	return UsageCounts{
+		Sessions:        c.Sessions + other.Sessions,
+		Messages:        c.Messages + other.Messages,
+		ToolCalls:       c.ToolCalls + other.ToolCalls,
+		EstimatedTokens: c.EstimatedTokens + other.EstimatedTokens,
+	}
+}
+
+// DailyUsage is the usage of one day
+type DailyUsage struct {
+	Day string `json:"day"` // USAGE_DAY_FORMAT
+	UsageCounts
+}
+
+// UsageStats records and queries one app's daily usage in the usage_stats table. Call
+// AutoMigrate once before using it.
+type UsageStats struct {
+	db      *gorm.DB
+	appName string
+}
+
+// NewUsageStats creates usage stats for appName
+func NewUsageStats(db *gorm.DB, appName string) *UsageStats {
+	return &UsageStats{db: db, appName: appName}
+}
+
+// Record adds delta to the counts of the day at (in local time), creating the day's row if needed
+func (u *UsageStats) Record(ctx context.Context, at time.Time, delta UsageCounts) error {
+	if delta.IsZero() {
+		return nil
+	}
+
+	record := usageRecord{
+		AppName:         u.appName,
+		Day:             at.Local().Format(USAGE_DAY_FORMAT),
+		Sessions:        delta.Sessions,
+		Messages:        delta.Messages,
+		ToolCalls:       delta.ToolCalls,
+		EstimatedTokens: delta.EstimatedTokens,
+	}
+	// A single upsert, so concurrent callbacks can't lose each other's increments
+	err := u.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "app_name"}, {Name: "day"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "sessions"}, Value: gorm.Expr("usage_stats.sessions + excluded.sessions")},
+			{Column: clause.Column{Name: "messages"}, Value: gorm.Expr("usage_stats.messages + excluded.messages")},
+			{Column: clause.Column{Name: "tool_calls"}, Value: gorm.Expr("usage_stats.tool_calls + excluded.tool_calls")},
+			{Column: clause.Column{Name: "estimated_tokens"}, Value: gorm.Expr("usage_stats.estimated_tokens + excluded.estimated_tokens")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(&record).Error
+	if err != nil {
+		return fmt.Errorf("failed to record usage for %s: %w", record.Day, err)
+	}
+	return nil
+}
+
+// Daily returns the usage of the last days days up to and including the day of now, oldest
+// first. Days without any usage are included with zero counts, so the result always has days
+// entries.
+func (u *UsageStats) Daily(ctx context.Context, now time.Time, days int) ([]DailyUsage, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("days must be at least 1, got %d", days)
+	}
+
+	now = now.Local()
+	first := now.AddDate(0, 0, -(days - 1)).Format(USAGE_DAY_FORMAT)
+	last := now.Format(USAGE_DAY_FORMAT)
+
+	var records []usageRecord
+	err := u.db.WithContext(ctx).
+		Where("app_name = ? AND day >= ? AND day <= ?", u.appName, first, last).
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	byDay := make(map[string]usageRecord, len(records))
+	for _, record := range records {
+		byDay[record.Day] = record
+	}
+
+	usage := make([]DailyUsage, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		day := now.AddDate(0, 0, -i).Format(USAGE_DAY_FORMAT)
+		record := byDay[day]
+		usage = append(usage, DailyUsage{
+			Day: day,
+			UsageCounts: UsageCounts{
+				Sessions:        record.Sessions,
+				Messages:        record.Messages,
+				ToolCalls:       record.ToolCalls,
+				EstimatedTokens: record.EstimatedTokens,
+			},
+		})
+	}
+	return usage, nil
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMemoryDB opens a migrated in-memory SQLite database. Every connection to ":memory:" is
+// a separate database, so the pool is limited to one.
+func newMemoryDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUsageStatsRecordAndDaily(t *testing.T) {
+	ctx := context.Background()
+	usage := NewUsageStats(newMemoryDB(t), "memory_agent")
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+	yesterday := now.AddDate(0, 0, -1)
+
+	for _, r := range []struct {
+		at    time.Time
+		delta UsageCounts
+	}{
+		{yesterday, UsageCounts{Sessions: 1, Messages: 2, ToolCalls: 1, EstimatedTokens: 900}},
+		{now, UsageCounts{Sessions: 1}},
+		{now, UsageCounts{Messages: 1, EstimatedTokens: 400}},
+		{now, UsageCounts{ToolCalls: 2, EstimatedTokens: 250}},
+		{now, UsageCounts{}}, // nothing to record
+	} {
+		if err := usage.Record(ctx, r.at, r.delta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	days, err := usage.Daily(ctx, now, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DailyUsage{
+		{Day: "2025-03-08"},
+		{Day: "2025-03-09", UsageCounts: UsageCounts{Sessions: 1, Messages: 2, ToolCalls: 1, EstimatedTokens: 900}},
+		{Day: "2025-03-10", UsageCounts: UsageCounts{Sessions: 1, Messages: 1, ToolCalls: 2, EstimatedTokens: 650}},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("Daily = %+v, want %+v", days, want)
+	}
+	for i := range want {
+		if days[i] != want[i] {
+			t.Errorf("day %d = %+v, want %+v", i, days[i], want[i])
+		}
+	}
+}
+
+func TestUsageStatsScopedByApp(t *testing.T) {
+	ctx := context.Background()
+	db := newMemoryDB(t)
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+	if err := NewUsageStats(db, "memory_agent").Record(ctx, now, UsageCounts{Messages: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewUsageStats(db, "other_app").Record(ctx, now, UsageCounts{Messages: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	days, err := NewUsageStats(db, "memory_agent").Daily(ctx, now, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 || days[0].Messages != 3 {
+		t.Errorf("Daily = %+v, want 3 messages for memory_agent only", days)
+	}
+}
+
+func TestUsageStatsConcurrentRecords(t *testing.T) {
+	ctx := context.Background()
+	usage := NewUsageStats(newMemoryDB(t), "memory_agent")
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if err := usage.Record(ctx, now, UsageCounts{ToolCalls: 1}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	days, err := usage.Daily(ctx, now, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if days[0].ToolCalls != 20 {
+		t.Errorf("tool calls = %d, want 20", days[0].ToolCalls)
+	}
+}
+
+func TestUsageStatsDailyRejectsNoDays(t *testing.T) {
+	if _, err := NewUsageStats(newMemoryDB(t), "memory_agent").Daily(context.Background(), time.Now(), 0); err == nil {
+		t.Error("Daily with 0 days succeeded, want an error")
+	}
+}
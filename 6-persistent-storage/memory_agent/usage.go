@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+)
+
+// ===== Usage Stats =====
+//
+// Callbacks add every user message, tool call and model response's token count to today's row
+// of the usage_stats table; new sessions are counted where they are created. Run with -report
+// to print the daily totals instead of starting the agent. Recording is best effort: a failed
+// write is logged and the conversation goes on.
+
+// DEFAULT_REPORT_DAYS is how many days -report shows unless -report-days is set
+const DEFAULT_REPORT_DAYS = 7
+
+// withUsageStats returns a copy of cfg with callbacks that record messages, tool calls and
+// tokens in usage. A nil usage returns cfg unchanged. The model callback goes first, so a later
+// callback that replaces the response can't skip it.
+func withUsageStats(cfg llmagent.Config, usage *store.UsageStats) llmagent.Config {
+	if usage == nil {
+		return cfg
+	}
+
+	countMessage := func(ctx agent.CallbackContext) (*genai.Content, error) {
+		recordUsage(ctx, usage, store.UsageCounts{Messages: 1})
+		return nil, nil
+	}
+	countTokens := func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+		if respErr != nil || resp == nil || resp.Partial || resp.UsageMetadata == nil {
+			return nil, nil
+		}
+		recordUsage(ctx, usage, store.UsageCounts{EstimatedTokens: int64(resp.UsageMetadata.TotalTokenCount)})
+		return nil, nil
+	}
+	countToolCall := func(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+		recordUsage(ctx, usage, store.UsageCounts{ToolCalls: 1})
+		return nil, nil
+	}
+
+	cfg.BeforeAgentCallbacks = append(append([]agent.BeforeAgentCallback{}, cfg.BeforeAgentCallbacks...), countMessage)
+	cfg.AfterModelCallbacks = append([]llmagent.AfterModelCallback{countTokens}, cfg.AfterModelCallbacks...)
+	cfg.AfterToolCallbacks = append(append([]llmagent.AfterToolCallback{}, cfg.AfterToolCallbacks...), countToolCall)
+	return cfg
+}
+
+// recordUsage adds delta to today's usage, logging instead of failing. A nil usage records
+// nothing. The write uses a context that isn't canceled with the run, like saved events.
+func recordUsage(ctx context.Context, usage *store.UsageStats, delta store.UsageCounts) {
+	if usage == nil {
+		return
+	}
+	if err := usage.Record(context.WithoutCancel(ctx), time.Now(), delta); err != nil {
+		log.Printf("Failed to record usage stats: %v", err)
+	}
+}
+
+// printUsageReport prints the daily usage of the last days days and their totals
+func printUsageReport(ctx context.Context, usage *store.UsageStats, days int) error {
+	daily, err := usage.Daily(ctx, time.Now(), days)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n===== Usage report: last %d day(s) =====\n", days)
+	fmt.Printf("%-10s  %8s  %8s  %10s  %16s\n", "Day", "Sessions", "Messages", "Tool calls", "Estimated tokens")
+	fmt.Println(strings.Repeat("-", 60))
+
+	var total store.UsageCounts
+	for _, d := range daily {
+		fmt.Printf("%-10s  %8d  %8d  %10d  %16d\n", d.Day, d.Sessions, d.Messages, d.ToolCalls, d.EstimatedTokens)
+		total = total.Add(d.UsageCounts)
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("%-10s  %8d  %8d  %10d  %16d\n", "Total", total.Sessions, total.Messages, total.ToolCalls, total.EstimatedTokens)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/muchlist/agent-dev-kit/6-persistent-storage/memory_agent/store"
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestUsageStatsCountsTurns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every ":memory:" connection is a separate database
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := store.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	usage := store.NewUsageStats(db, APP_NAME)
+
+	r, _ := newTestRunnerWithUsage(t, agenttest.ReplayModel(t, REMINDERS_RECORDING, MODEL_NAME), usage)
+	agenttest.RunScript(t, r, TEST_USER_ID, TEST_SESSION_ID, []string{
+		"Add a reminder to buy milk",
+		"Show me my reminders",
+	})
+
+	days, err := usage.Daily(context.Background(), time.Now(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := days[0]
+	if today.Messages != 2 {
+		t.Errorf("messages = %d, want 2", today.Messages)
+	}
+	if today.ToolCalls != 2 { // add_reminder, then view_reminders
+		t.Errorf("tool calls = %d, want 2", today.ToolCalls)
+	}
+	if today.EstimatedTokens <= 0 {
+		t.Errorf("estimated tokens = %d, want the recorded usage counted", today.EstimatedTokens)
+	}
+}
@@ -3,12 +3,18 @@ package agents
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 // ===== Funny Nerd Tool Structures =====
@@ -25,84 +31,104 @@ type getNerdJokeResults struct {
 
 // ===== Tool Implementation =====
 
-// getNerdJoke returns a nerdy joke about a specific topic
-func getNerdJoke(ctx tool.Context, input getNerdJokeArgs) (getNerdJokeResults, error) {
-	fmt.Printf("--- Tool: get_nerd_joke called for topic: %s ---\n", input.Topic)
-
-	// Collection of nerdy jokes by topic
-	// In production, you might want to use a jokes API or larger database
-	jokes := map[string]string{
-		"python":      "Why don't Python programmers like to use inheritance? Because they don't like to inherit anything!",
-		"javascript":  "Why did the JavaScript developer go broke? Because he used up all his cache!",
-		"java":        "Why do Java developers wear glasses? Because they can't C#!",
-		"go":          "Why do Go programmers prefer channels over callbacks? Because they don't want to get caught in callback hell!",
-		"golang":      "What's a gopher's favorite type of code? Go code that's concurrent and simple!",
-		"programming": "Why do programmers prefer dark mode? Because light attracts bugs!",
-		"math":        "Why was the equal sign so humble? Because he knew he wasn't less than or greater than anyone else!",
-		"physics":     "Why did the photon check into a hotel? Because it was travelling light!",
-		"chemistry":   "Why did the acid go to the gym? To become a buffer solution!",
-		"biology":     "Why did the cell go to therapy? Because it had too many issues!",
-		"computer":    "Why did the computer keep freezing? It left its Windows open!",
-		"database":    "Why did the DBA break up with their partner? Too many relationship conflicts!",
-		"default":     "Why did the computer go to the doctor? Because it had a virus!",
-	}
-
-	// Find joke, use default if topic not found
-	joke, exists := jokes[input.Topic]
-	if !exists {
-		joke = jokes["default"]
+// jokeFetcher fetches a joke about a topic from an external source
+type jokeFetcher func(ctx context.Context, topic string) (string, error)
+
+// newGetNerdJoke returns the get_nerd_joke handler backed by the given joke source.
+// When the source fails or has no joke for the topic, the built-in jokes in local are used instead.
+// Jokes already told in this session (tracked by hash in told_jokes) are never repeated.
+func newGetNerdJoke(fetch jokeFetcher, local nerdJokes) functiontool.Func[getNerdJokeArgs, getNerdJokeResults] {
+	return func(ctx tool.Context, input getNerdJokeArgs) (getNerdJokeResults, error) {
+		fmt.Printf("--- Tool: get_nerd_joke called for topic: %s ---\n", input.Topic)
+
+		topic := strings.ToLower(strings.TrimSpace(input.Topic))
+
+		// Store last joke topic in session state
+		state := ctx.State()
+		state.Set("last_joke_topic", input.Topic)
+
+		told := getToldJokes(state)
+		joke, fresh := pickFreshJoke(ctx, topic, told, fetch, local)
+		if !fresh {
+			return getNerdJokeResults{
+				Status: "exhausted",
+				Joke:   OUT_OF_JOKES,
+				Topic:  input.Topic,
+			}, nil
+		}
+
+		state.Set(TOLD_JOKES_KEY, rememberJoke(told, joke))
+
+		return getNerdJokeResults{
+			Status: "success",
+			Joke:   joke,
+			Topic:  input.Topic,
+		}, nil
 	}
+}
 
-	// Store last joke topic in session state
-	state := ctx.State()
-	state.Set("last_joke_topic", input.Topic)
+// ===== Agent Creation =====
 
-	return getNerdJokeResults{
-		Status: "success",
-		Joke:   joke,
-		Topic:  input.Topic,
-	}, nil
+// FUNNY_NERD_GENERATION is the funny nerd's default generation parameters. A higher temperature
+// gives more varied jokes and explanations; override with FUNNY_NERD_TEMPERATURE, _TOP_P and
+// _MAX_OUTPUT_TOKENS.
+var FUNNY_NERD_GENERATION = agentutil.GenerationParams{
+	Temperature: genai.Ptr[float32](1.3),
 }
 
-// ===== Agent Creation =====
+// NewFunnyNerd creates a specialized agent for telling nerdy jokes, generating with generation
+// (see FUNNY_NERD_GENERATION). Its built-in jokes come from NERD_JOKES_FILE when that exists.
+func NewFunnyNerd(ctx context.Context, mdl model.LLM, generation agentutil.GenerationParams) (agent.Agent, error) {
+	jokes, fromFile, err := loadNerdJokes(NERD_JOKES_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load built-in jokes: %w", err)
+	}
+	if fromFile {
+		slog.Info("built-in jokes loaded", "file", NERD_JOKES_FILE, "topics", len(jokes.topics()))
+	}
 
-// NewFunnyNerd creates a specialized agent for telling nerdy jokes
-func NewFunnyNerd(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
-	// Create get_nerd_joke tool
+	// Create get_nerd_joke tool, fetching from JokeAPI with the built-in jokes as fallback
 	getNerdJokeTool, err := functiontool.New(
 		functiontool.Config{
 			Name:        "get_nerd_joke",
 			Description: "Get a nerdy joke about a specific topic",
 		},
-		getNerdJoke)
+		newGetNerdJoke(fetchJokeAPIJoke, jokes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create get_nerd_joke tool: %w", err)
 	}
 
 	// Create funny nerd agent
 	funnyNerd, err := llmagent.New(llmagent.Config{
-		Name:        "funny_nerd",
-		Model:       mdl,
-		Description: "An agent that tells nerdy jokes about various topics.",
-		Instruction: `You are a funny nerd agent that tells nerdy jokes about various topics.
+		Name:                  "funny_nerd",
+		Model:                 mdl,
+		Description:           "An agent that tells nerdy jokes about various topics.",
+		Instruction:           funnyNerdInstruction(jokes.topics()),
+		Tools:                 []tool.Tool{getNerdJokeTool},
+		GenerateContentConfig: generation.ContentConfig(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create funny nerd agent: %w", err)
+	}
+
+	return funnyNerd, nil
+}
+
+// funnyNerdInstruction returns the funny nerd's instruction, listing the topics with built-in jokes
+func funnyNerdInstruction(topics []string) string {
+	return `You are a funny nerd agent that tells nerdy jokes about various topics.
 
 When asked to tell a joke:
 1. Use the get_nerd_joke tool to fetch a joke about the requested topic
 2. If no specific topic is mentioned, ask the user what kind of nerdy joke they'd like to hear
 3. Format the response to include both the joke and a brief explanation if needed
 
-Available topics include:
-- python
-- javascript
-- java
-- go / golang
-- programming
-- math
-- physics
-- chemistry
-- biology
-- computer
-- database
+Jokes come from an online jokes API, so any tech or science topic works. Topics with
+built-in fallback jokes include:
+- ` + strings.Join(topics, "\n- ") + `
+
+If get_nerd_joke returns status "exhausted", don't make up a joke. Tell the user you're out of
+fresh ones on that topic and suggest another topic.
 
 Example response format:
 "Here's a nerdy joke about <TOPIC>:
@@ -111,12 +137,5 @@ Example response format:
 
 😄 Explanation: {brief explanation if needed}"
 
-If the user asks about anything else, you should delegate the task to the manager agent.`,
-		Tools: []tool.Tool{getNerdJokeTool},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create funny nerd agent: %w", err)
-	}
-
-	return funnyNerd, nil
+If the user asks about anything else, you should delegate the task to the manager agent.`
 }
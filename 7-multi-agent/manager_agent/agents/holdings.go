@@ -0,0 +1,267 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	HOLDINGS_KEY = "holdings"
+	MAX_HOLDINGS = 20
+)
+
+// ===== Holdings State =====
+//
+// Holdings are the shares the user owns, stored in the manager's session under HOLDINGS_KEY as an
+// object of ticker to quantity. add_holding and remove_holding change a quantity by the shares
+// bought or sold, unlike watch_stock, which sets the quantity a watched ticker is valued with.
+// Fractional shares are allowed; a holding sold down to zero is removed.
+
+// getHoldings reads the holdings from state, dropping entries that aren't positive numbers
+func getHoldings(state session.ReadonlyState) map[string]float64 {
+	holdings := map[string]float64{}
+	val, err := state.Get(HOLDINGS_KEY)
+	if err != nil {
+		return holdings
+	}
+	all, _ := val.(map[string]any)
+	for ticker, raw := range all {
+		if quantity, ok := raw.(float64); ok && quantity > 0 {
+			holdings[ticker] = quantity
+		}
+	}
+	return holdings
+}
+
+// holdingsToState converts the holdings to the plain map stored in state
+func holdingsToState(holdings map[string]float64) map[string]any {
+	stored := make(map[string]any, len(holdings))
+	for ticker, quantity := range holdings {
+		stored[ticker] = quantity
+	}
+	return stored
+}
+
+// validQuantity reports whether quantity is a positive, finite number of shares
+func validQuantity(quantity float64) bool {
+	return quantity > 0 && !math.IsInf(quantity, 0)
+}
+
+// ===== Holdings Tool Structures =====
+
+type holdingArgs struct {
+	Ticker   string  `json:"ticker"`
+	Quantity float64 `json:"quantity"` // shares bought or sold
+}
+
+type holdingResults struct {
+	Status   string  `json:"status"`
+	Ticker   string  `json:"ticker,omitempty"`
+	Quantity float64 `json:"quantity"` // shares held afterwards
+	Message  string  `json:"message"`
+}
+
+type portfolioValueArgs struct{}
+
+// portfolioHolding is one holding valued at its current price
+type portfolioHolding struct {
+	Ticker   string  `json:"ticker"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Value    float64 `json:"value"`
+}
+
+type portfolioValueResults struct {
+	Status     string             `json:"status"`
+	Holdings   []portfolioHolding `json:"holdings,omitempty"`
+	TotalValue float64            `json:"total_value"`
+	Omitted    []omittedTicker    `json:"omitted,omitempty"`
+	Message    string             `json:"message"`
+}
+
+// ===== Tool Implementation =====
+
+// addHolding adds quantity shares of ticker to the holdings. The ticker is checked against the
+// price source, so shares of an unknown ticker aren't recorded.
+func addHolding(state session.State, cache *stockPriceCache, input holdingArgs) holdingResults {
+	ticker := strings.ToUpper(strings.TrimSpace(input.Ticker))
+	if ticker == "" {
+		return holdingResults{Status: "error", Message: "A ticker symbol is required"}
+	}
+	if !validQuantity(input.Quantity) {
+		return holdingResults{Status: "error", Ticker: ticker, Message: fmt.Sprintf("Quantity must be a positive number of shares, got %v", input.Quantity)}
+	}
+
+	holdings := getHoldings(state)
+	if _, ok := holdings[ticker]; !ok && len(holdings) >= MAX_HOLDINGS {
+		return holdingResults{
+			Status:  "error",
+			Ticker:  ticker,
+			Message: fmt.Sprintf("You already hold %d different stocks, the most that can be tracked", MAX_HOLDINGS),
+		}
+	}
+	if _, _, err := fetchCurrentPrice(cache, ticker); errors.Is(err, errUnknownTicker) {
+		return holdingResults{
+			Status:  "error",
+			Ticker:  ticker,
+			Message: fmt.Sprintf("Unknown ticker %s. Available tickers: GOOG, GOOGL, TSLA, META, AAPL, MSFT, AMZN", ticker),
+		}
+	}
+
+	holdings[ticker] += input.Quantity
+	if err := state.Set(HOLDINGS_KEY, holdingsToState(holdings)); err != nil {
+		return holdingResults{Status: "error", Ticker: ticker, Message: fmt.Sprintf("Failed to save holdings: %v", err)}
+	}
+	return holdingResults{
+		Status:   "success",
+		Ticker:   ticker,
+		Quantity: holdings[ticker],
+		Message:  fmt.Sprintf("Added %v shares of %s; you now hold %v", input.Quantity, ticker, holdings[ticker]),
+	}
+}
+
+// removeHolding removes quantity shares of ticker from the holdings. Selling more than is held
+// changes nothing and returns status insufficient with the shares held.
+func removeHolding(state session.State, input holdingArgs) holdingResults {
+	ticker := strings.ToUpper(strings.TrimSpace(input.Ticker))
+	if !validQuantity(input.Quantity) {
+		return holdingResults{Status: "error", Ticker: ticker, Message: fmt.Sprintf("Quantity must be a positive number of shares, got %v", input.Quantity)}
+	}
+
+	holdings := getHoldings(state)
+	held, ok := holdings[ticker]
+	if !ok {
+		return holdingResults{Status: "not_found", Ticker: ticker, Message: fmt.Sprintf("You don't hold any shares of %s", ticker)}
+	}
+	if input.Quantity > held+1e-9 {
+		return holdingResults{
+			Status:   "insufficient",
+			Ticker:   ticker,
+			Quantity: held,
+			Message:  fmt.Sprintf("Can't remove %v shares of %s, you only hold %v", input.Quantity, ticker, held),
+		}
+	}
+
+	// Round away float leftovers, so selling 0.3 of 0.1+0.2 shares removes the holding
+	remaining := math.Round((held-input.Quantity)*1e6) / 1e6
+	if remaining == 0 {
+		delete(holdings, ticker)
+	} else {
+		holdings[ticker] = remaining
+	}
+	if err := state.Set(HOLDINGS_KEY, holdingsToState(holdings)); err != nil {
+		return holdingResults{Status: "error", Ticker: ticker, Quantity: held, Message: fmt.Sprintf("Failed to save holdings: %v", err)}
+	}
+	return holdingResults{
+		Status:   "success",
+		Ticker:   ticker,
+		Quantity: remaining,
+		Message:  fmt.Sprintf("Removed %v shares of %s; you now hold %v", input.Quantity, ticker, remaining),
+	}
+}
+
+// portfolioValue values every holding at its current price. Holdings whose price can't be fetched
+// are left out of the total and listed in Omitted.
+func portfolioValue(state session.ReadonlyState, cache *stockPriceCache) portfolioValueResults {
+	holdings := getHoldings(state)
+	if len(holdings) == 0 {
+		return portfolioValueResults{Status: "success", Message: "You don't have any holdings yet; add them with add_holding"}
+	}
+
+	tickers := make([]string, 0, len(holdings))
+	for ticker := range holdings {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	results := portfolioValueResults{Status: "success"}
+	var total float64
+	for _, ticker := range tickers {
+		price, _, err := fetchCurrentPrice(cache, ticker)
+		if err != nil {
+			results.Omitted = append(results.Omitted, omittedTicker{Ticker: ticker, Reason: err.Error()})
+			continue
+		}
+		value := price * holdings[ticker]
+		total += value
+		results.Holdings = append(results.Holdings, portfolioHolding{
+			Ticker:   ticker,
+			Quantity: holdings[ticker],
+			Price:    price,
+			Value:    roundCents(value),
+		})
+	}
+
+	if len(results.Holdings) == 0 {
+		results.Status = "error"
+		results.Message = fmt.Sprintf("Could not fetch a price for any of your %d holdings", len(tickers))
+		return results
+	}
+
+	results.TotalValue = roundCents(total)
+	results.Message = fmt.Sprintf("Valued %d of %d holdings", len(results.Holdings), len(tickers))
+	if len(results.Omitted) > 0 {
+		omitted := make([]string, len(results.Omitted))
+		for i, o := range results.Omitted {
+			omitted[i] = o.Ticker
+		}
+		results.Message += fmt.Sprintf("; %s left out of the total because the price couldn't be fetched", strings.Join(omitted, ", "))
+	}
+	return results
+}
+
+// ===== Tool Creation =====
+
+// NewHoldingsTools creates the add_holding, remove_holding and portfolio_value tools. Like the
+// watchlist tools they belong on the manager, so holdings are saved in the user's session.
+func NewHoldingsTools() ([]tool.Tool, error) {
+	priceCache := newStockPriceCache(stockPriceCacheTTL(), fetchMockStockPrice)
+
+	addHoldingTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "add_holding",
+			Description: "Records shares of a stock the user bought or owns, adding them to any shares already held",
+		},
+		func(ctx tool.Context, input holdingArgs) (holdingResults, error) {
+			fmt.Printf("--- Tool: add_holding called for %v shares of %s ---\n", input.Quantity, input.Ticker)
+			return addHolding(ctx.State(), priceCache, input), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create add_holding tool: %w", err)
+	}
+
+	removeHoldingTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "remove_holding",
+			Description: "Removes shares of a stock the user sold from their holdings",
+		},
+		func(ctx tool.Context, input holdingArgs) (holdingResults, error) {
+			fmt.Printf("--- Tool: remove_holding called for %v shares of %s ---\n", input.Quantity, input.Ticker)
+			return removeHolding(ctx.State(), input), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remove_holding tool: %w", err)
+	}
+
+	portfolioValueTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "portfolio_value",
+			Description: "Returns the current value of each of the user's holdings (shares times current price) and the total",
+		},
+		func(ctx tool.Context, _ portfolioValueArgs) (portfolioValueResults, error) {
+			fmt.Println("--- Tool: portfolio_value called ---")
+			return portfolioValue(ctx.State(), priceCache), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create portfolio_value tool: %w", err)
+	}
+
+	return []tool.Tool{addHoldingTool, removeHoldingTool, portfolioValueTool}, nil
+}
@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestAddHolding(t *testing.T) {
+	cache := mockPrices(map[string]string{"AAPL": "189.50"})
+	state := agenttest.NewState(nil, true)
+
+	addHolding(state, cache, holdingArgs{Ticker: "aapl", Quantity: 10})
+	if results := addHolding(state, cache, holdingArgs{Ticker: "AAPL", Quantity: 2.5}); results.Status != "success" || results.Quantity != 12.5 {
+		t.Errorf("addHolding(AAPL) = %+v, want 12.5 shares held", results)
+	}
+
+	for _, input := range []holdingArgs{
+		{Ticker: "XYZ", Quantity: 1},
+		{Ticker: "", Quantity: 1},
+		{Ticker: "AAPL", Quantity: 0},
+		{Ticker: "AAPL", Quantity: -3},
+		{Ticker: "AAPL", Quantity: math.NaN()},
+		{Ticker: "AAPL", Quantity: math.Inf(1)},
+	} {
+		if results := addHolding(state, cache, input); results.Status != "error" || results.Message == "" {
+			t.Errorf("addHolding(%+v) = %+v, want an error", input, results)
+		}
+	}
+	if got := getHoldings(state); len(got) != 1 || got["AAPL"] != 12.5 {
+		t.Errorf("holdings = %v, want only 12.5 AAPL", got)
+	}
+}
+
+func TestRemoveHolding(t *testing.T) {
+	cache := mockPrices(map[string]string{"AAPL": "189.50", "MSFT": "378.25"})
+	state := agenttest.NewState(nil, true)
+	addHolding(state, cache, holdingArgs{Ticker: "AAPL", Quantity: 10})
+	addHolding(state, cache, holdingArgs{Ticker: "MSFT", Quantity: 0.1})
+	addHolding(state, cache, holdingArgs{Ticker: "MSFT", Quantity: 0.2})
+
+	if results := removeHolding(state, holdingArgs{Ticker: "AAPL", Quantity: 11}); results.Status != "insufficient" || results.Quantity != 10 {
+		t.Errorf("removeHolding(11 AAPL) = %+v, want insufficient with 10 held", results)
+	}
+	if results := removeHolding(state, holdingArgs{Ticker: "AAPL", Quantity: 4}); results.Status != "success" || results.Quantity != 6 {
+		t.Errorf("removeHolding(4 AAPL) = %+v, want 6 left", results)
+	}
+	if results := removeHolding(state, holdingArgs{Ticker: "msft", Quantity: 0.3}); results.Status != "success" || results.Quantity != 0 {
+		t.Errorf("removeHolding(0.3 MSFT) = %+v, want none left", results)
+	}
+	if results := removeHolding(state, holdingArgs{Ticker: "MSFT", Quantity: 1}); results.Status != "not_found" {
+		t.Errorf("removeHolding(MSFT) again = %+v, want not_found", results)
+	}
+	if results := removeHolding(state, holdingArgs{Ticker: "AAPL", Quantity: -1}); results.Status != "error" {
+		t.Errorf("removeHolding(-1 AAPL) = %+v, want an error", results)
+	}
+	if got := getHoldings(state); len(got) != 1 || got["AAPL"] != 6 {
+		t.Errorf("holdings = %v, want only 6 AAPL", got)
+	}
+}
+
+func TestPortfolioValue(t *testing.T) {
+	prices := map[string]string{"AAPL": "189.50", "MSFT": "378.25", "TSLA": "156.78"}
+	cache := mockPrices(prices)
+	state := agenttest.NewState(nil, true)
+	if results := portfolioValue(state, cache); results.Status != "success" || len(results.Holdings) != 0 {
+		t.Errorf("portfolioValue(empty) = %+v, want no holdings", results)
+	}
+
+	addHolding(state, cache, holdingArgs{Ticker: "AAPL", Quantity: 10})
+	addHolding(state, cache, holdingArgs{Ticker: "MSFT", Quantity: 0.5})
+	addHolding(state, cache, holdingArgs{Ticker: "TSLA", Quantity: 3})
+	delete(prices, "TSLA")
+
+	results := portfolioValue(state, cache)
+	if results.Status != "success" || len(results.Holdings) != 2 || results.TotalValue != 2084.13 {
+		t.Fatalf("portfolioValue() = %+v, want AAPL and MSFT worth 2084.13", results)
+	}
+	if len(results.Omitted) != 1 || results.Omitted[0].Ticker != "TSLA" || !strings.Contains(results.Message, "TSLA left out") {
+		t.Errorf("portfolioValue() = %+v, want TSLA omitted", results)
+	}
+
+	delete(prices, "AAPL")
+	delete(prices, "MSFT")
+	if results := portfolioValue(state, cache); results.Status != "error" || len(results.Omitted) != 3 {
+		t.Errorf("portfolioValue() without prices = %+v, want an error", results)
+	}
+}
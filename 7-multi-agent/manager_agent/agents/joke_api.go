@@ -0,0 +1,127 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	JOKE_API_URL        = "https://v2.jokeapi.dev/joke/"
+	JOKE_API_TIMEOUT    = 5 * time.Second
+	JOKE_API_MAX_BYTES  = 64 * 1024
+	JOKE_API_BLACKLIST  = "nsfw,religious,political,racist,sexist,explicit"
+	jokeAPIProgramming  = "Programming"
+	jokeAPIGeneralHumor = "Misc,Pun"
+)
+
+var errNoJokeFound = errors.New("no joke found for topic")
+
+// jokeAPICategories maps get_nerd_joke topics to JokeAPI categories.
+// JokeAPI has no science categories, so science topics search the general ones.
+var jokeAPICategories = map[string]string{
+	"python":      jokeAPIProgramming,
+	"javascript":  jokeAPIProgramming,
+	"java":        jokeAPIProgramming,
+	"go":          jokeAPIProgramming,
+	"golang":      jokeAPIProgramming,
+	"programming": jokeAPIProgramming,
+	"computer":    jokeAPIProgramming,
+	"database":    jokeAPIProgramming,
+	"math":        jokeAPIGeneralHumor,
+	"physics":     jokeAPIGeneralHumor,
+	"chemistry":   jokeAPIGeneralHumor,
+	"biology":     jokeAPIGeneralHumor,
+}
+
+// ===== JokeAPI Response =====
+
+type jokeAPIResponse struct {
+	Error    bool            `json:"error"`
+	Message  string          `json:"message"`
+	Type     string          `json:"type"`
+	Joke     string          `json:"joke"`
+	Setup    string          `json:"setup"`
+	Delivery string          `json:"delivery"`
+	Safe     bool            `json:"safe"`
+	Flags    map[string]bool `json:"flags"`
+}
+
+var jokeAPIClient = &http.Client{Timeout: JOKE_API_TIMEOUT}
+
+// ===== Joke Sources =====
+
+// fetchJokeAPIJoke fetches a safe-mode joke for the topic from JokeAPI (https://jokeapi.dev).
+// Topic-specific jokes are searched with "contains", and flagged or unsafe jokes are rejected.
+func fetchJokeAPIJoke(ctx context.Context, topic string) (string, error) {
+	// Unknown topics (e.g. "rust") are searched for in programming jokes
+	category, known := jokeAPICategories[topic]
+	if !known {
+		category = jokeAPIProgramming
+	}
+
+	query := url.Values{}
+	query.Set("blacklistFlags", JOKE_API_BLACKLIST)
+	// "programming" and "computer" are covered by the category itself, so any joke in it fits
+	if topic != "" && topic != "programming" && topic != "computer" {
+		query.Set("contains", topic)
+	}
+	// safe-mode is a flag without a value, so it's added by hand
+	endpoint := JOKE_API_URL + url.PathEscape(category) + "?safe-mode&" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := jokeAPIClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call JokeAPI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, JOKE_API_MAX_BYTES))
+	if err != nil {
+		return "", fmt.Errorf("failed to read JokeAPI response: %w", err)
+	}
+
+	var joke jokeAPIResponse
+	if err := json.Unmarshal(body, &joke); err != nil {
+		return "", fmt.Errorf("failed to parse JokeAPI response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	// JokeAPI reports "no matching joke" as an error payload
+	if joke.Error {
+		return "", fmt.Errorf("%w: %s", errNoJokeFound, joke.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("JokeAPI returned HTTP %d", resp.StatusCode)
+	}
+
+	if !joke.Safe {
+		return "", fmt.Errorf("JokeAPI returned a joke not marked safe")
+	}
+	for flag, set := range joke.Flags {
+		if set {
+			return "", fmt.Errorf("JokeAPI returned a joke flagged %q", flag)
+		}
+	}
+
+	switch joke.Type {
+	case "single":
+		if text := strings.TrimSpace(joke.Joke); text != "" {
+			return text, nil
+		}
+	case "twopart":
+		if joke.Setup != "" && joke.Delivery != "" {
+			return strings.TrimSpace(joke.Setup) + " " + strings.TrimSpace(joke.Delivery), nil
+		}
+	}
+	return "", fmt.Errorf("JokeAPI returned an empty %q joke", joke.Type)
+}
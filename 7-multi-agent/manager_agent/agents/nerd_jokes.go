@@ -0,0 +1,74 @@
+package agents
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+const (
+	// NERD_JOKES_FILE replaces the built-in jokes when it exists in the working directory. It has
+	// the same shape as the embedded nerd_jokes.json: an object of topic to joke.
+	NERD_JOKES_FILE = "nerd_jokes.json"
+
+	DEFAULT_JOKE_TOPIC = "default" // its joke is told for topics without one of their own
+)
+
+// ===== Built-in Jokes =====
+
+//go:embed nerd_jokes.json
+var embeddedNerdJokes []byte
+
+// nerdJokes maps a lowercase topic to its built-in joke, used when JokeAPI is unavailable
+type nerdJokes map[string]string
+
+// loadNerdJokes reads the jokes from path when it exists, and the embedded jokes otherwise. It
+// reports whether the file was used.
+func loadNerdJokes(path string) (nerdJokes, bool, error) {
+	var raw map[string]string
+	fromFile, err := toolutil.LoadToolData(path, embeddedNerdJokes, &raw)
+	if err != nil {
+		return nil, fromFile, err
+	}
+
+	jokes := nerdJokes{}
+	for _, topic := range slices.Sorted(maps.Keys(raw)) {
+		key := strings.ToLower(strings.TrimSpace(topic))
+		joke := strings.TrimSpace(raw[topic])
+		if key == "" || joke == "" {
+			return nil, fromFile, fmt.Errorf("jokes in %s: topic %q has an empty topic or joke", path, topic)
+		}
+		if _, exists := jokes[key]; exists {
+			return nil, fromFile, fmt.Errorf("jokes in %s: topic %q is listed more than once", path, key)
+		}
+		jokes[key] = joke
+	}
+	if jokes[DEFAULT_JOKE_TOPIC] == "" {
+		return nil, fromFile, fmt.Errorf("jokes in %s: a %q joke is required for other topics", path, DEFAULT_JOKE_TOPIC)
+	}
+	return jokes, fromFile, nil
+}
+
+// joke returns the joke about topic, or the default joke
+func (j nerdJokes) joke(topic string) string {
+	if joke, exists := j[topic]; exists {
+		return joke
+	}
+	return j[DEFAULT_JOKE_TOPIC]
+}
+
+// topics lists the topics with a joke of their own, sorted
+func (j nerdJokes) topics() []string {
+	topics := make([]string, 0, len(j))
+	for topic := range j {
+		if topic != DEFAULT_JOKE_TOPIC {
+			topics = append(topics, topic)
+		}
+	}
+	slices.Sort(topics)
+	return topics
+}
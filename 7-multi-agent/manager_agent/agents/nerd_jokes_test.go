@@ -0,0 +1,81 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// builtinJokes returns the embedded jokes
+func builtinJokes(t *testing.T) nerdJokes {
+	t.Helper()
+	jokes, fromFile, err := loadNerdJokes(filepath.Join(t.TempDir(), NERD_JOKES_FILE))
+	if err != nil || fromFile {
+		t.Fatalf("loadNerdJokes() = %v, %v, want the embedded jokes", fromFile, err)
+	}
+	return jokes
+}
+
+func writeNerdJokes(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), NERD_JOKES_FILE)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEmbeddedNerdJokes(t *testing.T) {
+	jokes := builtinJokes(t)
+
+	for _, topic := range []string{"python", "go", "golang", "physics", "database"} {
+		if jokes[topic] == "" {
+			t.Errorf("no built-in joke about %s", topic)
+		}
+	}
+	if got := jokes.joke("cobol"); got != jokes[DEFAULT_JOKE_TOPIC] {
+		t.Errorf("joke(cobol) = %q, want the default joke", got)
+	}
+	if got := jokes.topics(); len(got) != len(jokes)-1 || strings.Contains(strings.Join(got, " "), DEFAULT_JOKE_TOPIC) {
+		t.Errorf("topics() = %v, want every topic but %s", got, DEFAULT_JOKE_TOPIC)
+	}
+}
+
+func TestNerdJokesFileOverridesEmbedded(t *testing.T) {
+	path := writeNerdJokes(t, `{" Rust ": "Why did the Rust developer never worry? Everything was borrowed.", "default": "Why do nerds love trees? The roots."}`)
+
+	jokes, fromFile, err := loadNerdJokes(path)
+	if err != nil || !fromFile {
+		t.Fatalf("loadNerdJokes() = %v, %v, want the file's jokes", fromFile, err)
+	}
+	if got := jokes.joke("rust"); got != "Why did the Rust developer never worry? Everything was borrowed." {
+		t.Errorf("joke(rust) = %q, want the file's joke", got)
+	}
+	// The file replaces the embedded jokes rather than adding to them
+	if got := jokes.joke("python"); got != "Why do nerds love trees? The roots." {
+		t.Errorf("joke(python) = %q, want the file's default joke", got)
+	}
+	if got := jokes.topics(); len(got) != 1 || got[0] != "rust" {
+		t.Errorf("topics() = %v, want [rust]", got)
+	}
+}
+
+func TestNerdJokesFileInvalid(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"no default":      {`{"python": "A joke"}`, `"default" joke is required`},
+		"empty joke":      {`{"python": " ", "default": "A joke"}`, "empty topic or joke"},
+		"duplicate topic": {`{"Go": "One", "go": "Two", "default": "A joke"}`, "more than once"},
+		"not an object":   {`["A joke"]`, "invalid JSON"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := loadNerdJokes(writeNerdJokes(t, tt.content)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadNerdJokes() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/geminitool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 // ===== Agent Creation =====
@@ -17,8 +20,11 @@ import (
 // Note: This agent uses GoogleSearch (a built-in tool), so it should be wrapped
 // as an AgentTool when used by the manager due to Go ADK's limitation:
 // a single agent can only use ONE built-in tool, and cannot mix built-in tools with custom tools
-func NewNewsAnalyst(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
-	// Create news analyst agent with Google Search tool
+//
+// searchQuota limits the Google searches per session; once a session reaches it, the analyst
+// answers from what it already found instead of searching again
+func NewNewsAnalyst(ctx context.Context, mdl model.LLM, searchQuota *toolutil.SearchQuota) (agent.Agent, error) {
+	// Create news analyst agent with Google Search tool, limited by searchQuota
 	newsAnalyst, err := llmagent.New(llmagent.Config{
 		Name:        "news_analyst",
 		Model:       mdl,
@@ -27,7 +33,8 @@ func NewNewsAnalyst(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
 
 When asked about news:
 1. Use the google_search tool to search for relevant news articles
-2. Summarize the key findings from the search results
+2. Summarize the key findings from the search results. A list of the sources you used is added
+   to your answer automatically, so don't write one yourself
 3. Focus on recent and relevant information
 4. If the user asks for news using a relative time (e.g., "today", "this week"),
    mention that you're searching for the most recent information
@@ -40,8 +47,13 @@ Tips for effective news search:
 Example searches:
 - "latest artificial intelligence news 2024"
 - "recent Google product announcements"
-- "technology industry trends this week"`,
-		Tools: []tool.Tool{geminitool.GoogleSearch{}},
+- "technology industry trends this week"
+
+If web search is unavailable because the search quota was reached, summarize what was already
+found earlier in this conversation and say that newer results could not be searched.`,
+		Tools: []tool.Tool{searchQuota.Wrap(geminitool.GoogleSearch{})},
+		// CiteSources goes last: it replaces the response, which skips the callbacks after it
+		AfterModelCallbacks: []llmagent.AfterModelCallback{searchQuota.AfterModel, agentutil.CiteSources()},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create news analyst agent: %w", err)
@@ -2,7 +2,9 @@ package agents
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"google.golang.org/adk/agent"
@@ -10,6 +12,8 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 // ===== Stock Analyst Tool Structures =====
@@ -19,20 +23,23 @@ type getStockPriceArgs struct {
 }
 
 type getStockPriceResults struct {
-	Status       string `json:"status"`
-	Ticker       string `json:"ticker,omitempty"`
-	Price        string `json:"price,omitempty"`
-	Timestamp    string `json:"timestamp,omitempty"`
-	ErrorMessage string `json:"error_message,omitempty"`
+	Status          string `json:"status"`
+	Ticker          string `json:"ticker,omitempty"`
+	Price           string `json:"price,omitempty"`
+	Timestamp       string `json:"timestamp,omitempty"`
+	Cached          bool   `json:"cached,omitempty"`
+	CacheAgeSeconds int    `json:"cache_age_seconds,omitempty"`
+	ErrorMessage    string `json:"error_message,omitempty"`
 }
 
 // ===== Tool Implementation =====
 
-// getStockPrice retrieves current stock price using mock data
-// Note: In production, replace with real stock API like Alpha Vantage or IEX Cloud
-func getStockPrice(ctx tool.Context, input getStockPriceArgs) (getStockPriceResults, error) {
-	fmt.Printf("--- Tool: get_stock_price called for %s ---\n", input.Ticker)
+// errUnknownTicker is returned by the price source for tickers it has no data for
+var errUnknownTicker = errors.New("unknown ticker")
 
+// fetchMockStockPrice looks up the current price of a ticker using mock data
+// Note: In production, replace with real stock API like Alpha Vantage or IEX Cloud
+func fetchMockStockPrice(ticker string) (string, error) {
 	// Mock stock prices for demonstration
 	// In production, you would use a real stock API:
 	// - Alpha Vantage: https://www.alphavantage.co/ (free tier: 5 API requests per minute)
@@ -48,35 +55,64 @@ func getStockPrice(ctx tool.Context, input getStockPriceArgs) (getStockPriceResu
 		"AMZN":  "145.67",
 	}
 
-	price, exists := mockPrices[input.Ticker]
+	price, exists := mockPrices[ticker]
 	if !exists {
-		return getStockPriceResults{
-			Status:       "error",
-			ErrorMessage: fmt.Sprintf("Could not fetch price for %s. Available tickers: GOOG, GOOGL, TSLA, META, AAPL, MSFT, AMZN", input.Ticker),
-		}, nil
+		return "", errUnknownTicker
 	}
+	return price, nil
+}
 
-	currentTime := time.Now().Format("2006-01-02 15:04:05")
-
-	return getStockPriceResults{
-		Status:    "success",
-		Ticker:    input.Ticker,
-		Price:     price,
-		Timestamp: currentTime,
-	}, nil
+// newGetStockPrice returns the get_stock_price handler backed by the given cache
+// Repeated lookups of the same ticker within the cache TTL don't hit the price source
+func newGetStockPrice(cache *stockPriceCache) functiontool.Func[getStockPriceArgs, getStockPriceResults] {
+	return func(ctx tool.Context, input getStockPriceArgs) (getStockPriceResults, error) {
+		fmt.Printf("--- Tool: get_stock_price called for %s ---\n", input.Ticker)
+
+		ticker := strings.ToUpper(strings.TrimSpace(input.Ticker))
+		price, fetchedAt, cached, err := cache.Get(ticker)
+		if errors.Is(err, errUnknownTicker) {
+			return getStockPriceResults{
+				Status:       "error",
+				ErrorMessage: fmt.Sprintf("Could not fetch price for %s. Available tickers: GOOG, GOOGL, TSLA, META, AAPL, MSFT, AMZN", input.Ticker),
+			}, nil
+		}
+		if err != nil {
+			return getStockPriceResults{
+				Status:       "error",
+				ErrorMessage: fmt.Sprintf("Could not fetch price for %s: %v", input.Ticker, err),
+			}, nil
+		}
+
+		result := getStockPriceResults{
+			Status:    "success",
+			Ticker:    ticker,
+			Price:     price,
+			Timestamp: fetchedAt.Format("2006-01-02 15:04:05"),
+		}
+		if cached {
+			result.Cached = true
+			result.CacheAgeSeconds = int(time.Since(fetchedAt).Seconds())
+		}
+		return result, nil
+	}
 }
 
 // ===== Agent Creation =====
 
-// NewStockAnalyst creates a specialized agent for stock market analysis
-func NewStockAnalyst(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
+// NewStockAnalyst creates a specialized agent for stock market analysis. It runs as an agent
+// tool, so its failed tool calls are recorded through errorLog's AfterNestedTool.
+func NewStockAnalyst(ctx context.Context, mdl model.LLM, errorLog *toolutil.ToolErrorLog) (agent.Agent, error) {
+	// Cache prices so repeated questions about the same ticker don't waste API rate limit
+	// The TTL is configurable through STOCK_PRICE_CACHE_TTL (default 60s)
+	priceCache := newStockPriceCache(stockPriceCacheTTL(), fetchMockStockPrice)
+
 	// Create get_stock_price tool
 	getStockPriceTool, err := functiontool.New(
 		functiontool.Config{
 			Name:        "get_stock_price",
 			Description: "Retrieves current stock price for a given ticker symbol",
 		},
-		getStockPrice)
+		newGetStockPrice(priceCache))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create get_stock_price tool: %w", err)
 	}
@@ -92,6 +128,8 @@ When asked about stock prices:
 1. Use the get_stock_price tool to fetch the latest price for the requested stock(s)
 2. Format the response to show each stock's current price and the time it was fetched
 3. If a stock price couldn't be fetched, mention this in your response
+4. If the tool result has "cached": true, the price was fetched "cache_age_seconds" ago;
+   mention that it's a recently cached price
 
 Example response format:
 "Here are the current prices for your stocks:
@@ -100,7 +138,8 @@ Example response format:
 - META: $123.45 (updated at 2024-04-21 16:30:00)"
 
 Available tickers: GOOG, GOOGL, TSLA, META, AAPL, MSFT, AMZN`,
-		Tools: []tool.Tool{getStockPriceTool},
+		Tools:              []tool.Tool{getStockPriceTool},
+		AfterToolCallbacks: []llmagent.AfterToolCallback{errorLog.AfterNestedTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create stock analyst agent: %w", err)
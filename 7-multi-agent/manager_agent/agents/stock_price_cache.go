@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_STOCK_PRICE_CACHE_TTL = 60 * time.Second
+)
+
+// ===== Stock Price Cache =====
+
+// cachedStockPrice is a single cached lookup
+type cachedStockPrice struct {
+	price     string
+	fetchedAt time.Time
+}
+
+// stockPriceCache is an in-memory TTL cache in front of the stock price source.
+// It is safe for concurrent use, since the manager may ask for several tickers at once.
+type stockPriceCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	fetch   func(ticker string) (string, error)
+	now     func() time.Time
+	entries map[string]cachedStockPrice
+}
+
+// newStockPriceCache creates a cache that calls fetch on a miss and keeps results for ttl.
+// A ttl of zero or less disables caching.
+func newStockPriceCache(ttl time.Duration, fetch func(ticker string) (string, error)) *stockPriceCache {
+	return &stockPriceCache{
+		ttl:     ttl,
+		fetch:   fetch,
+		now:     time.Now,
+		entries: make(map[string]cachedStockPrice),
+	}
+}
+
+// Get returns the price for ticker, the time it was fetched, and whether it was served from cache
+func (c *stockPriceCache) Get(ticker string) (price string, fetchedAt time.Time, cached bool, err error) {
+	c.mu.Lock()
+	entry, ok := c.entries[ticker]
+	c.mu.Unlock()
+
+	if ok && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.price, entry.fetchedAt, true, nil
+	}
+
+	// Fetch outside the lock so a slow lookup doesn't block other tickers
+	price, err = c.fetch(ticker)
+	if err != nil {
+		return "", time.Time{}, false, err
+	}
+	fetchedAt = c.now()
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[ticker] = cachedStockPrice{price: price, fetchedAt: fetchedAt}
+		c.mu.Unlock()
+	}
+
+	return price, fetchedAt, false, nil
+}
+
+// stockPriceCacheTTL reads STOCK_PRICE_CACHE_TTL (e.g. "60s", "5m", "0" to disable),
+// falling back to DEFAULT_STOCK_PRICE_CACHE_TTL when unset or invalid
+func stockPriceCacheTTL() time.Duration {
+	raw := os.Getenv("STOCK_PRICE_CACHE_TTL")
+	if raw == "" {
+		return DEFAULT_STOCK_PRICE_CACHE_TTL
+	}
+	if raw == "0" {
+		return 0
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		log.Printf("Invalid STOCK_PRICE_CACHE_TTL %q, using default %s", raw, DEFAULT_STOCK_PRICE_CACHE_TTL)
+		return DEFAULT_STOCK_PRICE_CACHE_TTL
+	}
+	return ttl
+}
@@ -0,0 +1,80 @@
+package agents
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingFetch returns a fetch func that answers with prices in turn, or err when set, and
+// counts its calls
+func countingFetch(calls *int, err *error, prices ...string) func(string) (string, error) {
+	return func(ticker string) (string, error) {
+		*calls++
+		if *err != nil {
+			return "", *err
+		}
+		return prices[min(*calls, len(prices))-1], nil
+	}
+}
+
+func TestStockPriceCache(t *testing.T) {
+	start := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+	now := start
+	calls := 0
+	var fetchErr error
+	cache := newStockPriceCache(time.Minute, countingFetch(&calls, &fetchErr, "$100", "$105"))
+	cache.now = func() time.Time { return now }
+
+	price, fetchedAt, cached, err := cache.Get("GOOG")
+	if err != nil || price != "$100" || !fetchedAt.Equal(start) || cached || calls != 1 {
+		t.Fatalf("first Get = %q, %v, cached %v, %v after %d fetches, want a fresh $100", price, fetchedAt, cached, err, calls)
+	}
+
+	// Within the TTL the cached price is served with its original fetch time
+	now = start.Add(59 * time.Second)
+	price, fetchedAt, cached, err = cache.Get("GOOG")
+	if err != nil || price != "$100" || !fetchedAt.Equal(start) || !cached || calls != 1 {
+		t.Errorf("Get within TTL = %q, %v, cached %v, %v after %d fetches, want the cached $100", price, fetchedAt, cached, err, calls)
+	}
+
+	// Once the TTL has passed the price is fetched again
+	now = start.Add(time.Minute)
+	price, fetchedAt, cached, err = cache.Get("GOOG")
+	if err != nil || price != "$105" || !fetchedAt.Equal(now) || cached || calls != 2 {
+		t.Errorf("Get after TTL = %q, %v, cached %v, %v after %d fetches, want a fresh $105", price, fetchedAt, cached, err, calls)
+	}
+}
+
+func TestStockPriceCacheDoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	fetchErr := errors.New("rate limited")
+	cache := newStockPriceCache(time.Minute, countingFetch(&calls, &fetchErr, "$100"))
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	if _, _, _, err := cache.Get("GOOG"); !errors.Is(err, fetchErr) {
+		t.Fatalf("Get() error = %v, want %v", err, fetchErr)
+	}
+
+	// The next call within the TTL fetches again instead of repeating the error
+	fetchErr = nil
+	price, _, cached, err := cache.Get("GOOG")
+	if err != nil || price != "$100" || cached || calls != 2 {
+		t.Errorf("Get after error = %q, cached %v, %v after %d fetches, want a fresh $100", price, cached, err, calls)
+	}
+}
+
+func TestStockPriceCacheDisabled(t *testing.T) {
+	calls := 0
+	var fetchErr error
+	cache := newStockPriceCache(0, countingFetch(&calls, &fetchErr, "$100"))
+	for i := 0; i < 3; i++ {
+		if _, _, cached, err := cache.Get("GOOG"); err != nil || cached {
+			t.Fatalf("Get() cached %v, %v, want a fetch every time", cached, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("fetches = %d, want 3 with caching disabled", calls)
+	}
+}
@@ -0,0 +1,81 @@
+package agents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/session"
+)
+
+const (
+	TOLD_JOKES_KEY      = "told_jokes"
+	MAX_TOLD_JOKES      = 100 // oldest hashes are dropped beyond this, so very old jokes may return
+	JOKE_FETCH_ATTEMPTS = 3   // API fetches per request before giving up on a fresh joke
+	OUT_OF_JOKES        = "I'm out of fresh ones on that topic"
+)
+
+// ===== Told Joke Tracking =====
+
+// jokeHash identifies a joke by its normalized text, so state stores short hashes instead of full jokes
+func jokeHash(joke string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.Join(strings.Fields(joke), " "))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// getToldJokes reads the told joke hashes from state
+// Values loaded from the database come back as []any, freshly set ones as []string
+func getToldJokes(state session.ReadonlyState) []string {
+	told := []string{}
+	if val, err := state.Get(TOLD_JOKES_KEY); err == nil {
+		switch list := val.(type) {
+		case []string:
+			told = append(told, list...)
+		case []any:
+			for _, h := range list {
+				if str, ok := h.(string); ok {
+					told = append(told, str)
+				}
+			}
+		}
+	}
+	return told
+}
+
+// rememberJoke appends the joke's hash, keeping only the most recent MAX_TOLD_JOKES
+func rememberJoke(told []string, joke string) []string {
+	told = append(told, jokeHash(joke))
+	if len(told) > MAX_TOLD_JOKES {
+		told = told[len(told)-MAX_TOLD_JOKES:]
+	}
+	return told
+}
+
+// pickFreshJoke returns a joke about the topic that isn't in told. It tries the joke source up to
+// JOKE_FETCH_ATTEMPTS times, then the built-in joke from local, and reports false once both are
+// exhausted.
+func pickFreshJoke(ctx context.Context, topic string, told []string, fetch jokeFetcher, local nerdJokes) (string, bool) {
+	seen := make(map[string]bool, len(told))
+	for _, h := range told {
+		seen[h] = true
+	}
+
+	for attempt := 1; attempt <= JOKE_FETCH_ATTEMPTS; attempt++ {
+		joke, err := fetch(ctx, topic)
+		if err != nil {
+			// A failing source won't do better on retry, so go straight to the built-in joke
+			fmt.Printf("--- Tool: get_nerd_joke falling back to local jokes: %v ---\n", err)
+			break
+		}
+		if !seen[jokeHash(joke)] {
+			return joke, true
+		}
+	}
+
+	if joke := local.joke(topic); !seen[jokeHash(joke)] {
+		return joke, true
+	}
+	return "", false
+}
@@ -0,0 +1,78 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPickFreshJokeExhausted(t *testing.T) {
+	apiJoke := "Why do programmers confuse Halloween and Christmas? Because OCT 31 == DEC 25."
+	calls := 0
+	fetch := func(context.Context, string) (string, error) {
+		calls++
+		return apiJoke, nil
+	}
+
+	local := builtinJokes(t)
+	told := rememberJoke(nil, apiJoke)
+	told = rememberJoke(told, local.joke("python"))
+
+	joke, fresh := pickFreshJoke(context.Background(), "python", told, fetch, local)
+	if fresh {
+		t.Fatalf("pickFreshJoke() = %q, true; want exhausted", joke)
+	}
+	if calls != JOKE_FETCH_ATTEMPTS {
+		t.Errorf("fetch called %d times, want %d", calls, JOKE_FETCH_ATTEMPTS)
+	}
+}
+
+func TestPickFreshJokeSkipsToldJokes(t *testing.T) {
+	jokes := []string{"first joke", "second joke"}
+	calls := 0
+	fetch := func(context.Context, string) (string, error) {
+		joke := jokes[calls%len(jokes)]
+		calls++
+		return joke, nil
+	}
+
+	joke, fresh := pickFreshJoke(context.Background(), "go", rememberJoke(nil, "first joke"), fetch, builtinJokes(t))
+	if !fresh || joke != "second joke" {
+		t.Errorf("pickFreshJoke() = %q, %v; want %q, true", joke, fresh, "second joke")
+	}
+}
+
+func TestPickFreshJokeFallsBackToLocal(t *testing.T) {
+	fetch := func(context.Context, string) (string, error) {
+		return "", errors.New("api down")
+	}
+
+	local := builtinJokes(t)
+	joke, fresh := pickFreshJoke(context.Background(), "physics", nil, fetch, local)
+	if !fresh || joke != local.joke("physics") {
+		t.Errorf("pickFreshJoke() = %q, %v; want local physics joke", joke, fresh)
+	}
+
+	// Once the local joke is told too, a failing API leaves nothing fresh
+	if joke, fresh := pickFreshJoke(context.Background(), "physics", rememberJoke(nil, joke), fetch, local); fresh {
+		t.Errorf("pickFreshJoke() = %q, true; want exhausted", joke)
+	}
+}
+
+func TestRememberJokeBounded(t *testing.T) {
+	var told []string
+	for i := 0; i < MAX_TOLD_JOKES+10; i++ {
+		told = rememberJoke(told, fmt.Sprintf("joke %d", i))
+	}
+
+	if len(told) != MAX_TOLD_JOKES {
+		t.Errorf("len(told) = %d, want %d", len(told), MAX_TOLD_JOKES)
+	}
+}
+
+func TestJokeHashNormalizesWhitespaceAndCase(t *testing.T) {
+	if jokeHash("Why  did the\nPhoton travel light?") != jokeHash("why did the photon travel light?") {
+		t.Error("jokeHash() differs for jokes that only differ in case and whitespace")
+	}
+}
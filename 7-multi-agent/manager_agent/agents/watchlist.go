@@ -0,0 +1,376 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	WATCHLIST_KEY         = "stock_watchlist"
+	MAX_WATCHLIST_TICKERS = 20
+	WATCHLIST_TIME_FORMAT = "2006-01-02 15:04:05"
+)
+
+// ===== Watchlist State =====
+//
+// The watchlist is stored in the manager's session under WATCHLIST_KEY as an object of ticker to
+// {"quantity", "last_price", "last_price_at"}. The manager owns these tools because the stock
+// analyst runs as an agent tool, whose state is discarded after every call.
+//
+// last_price is the price seen by the previous watch_stock or watchlist_stats call, so the stats
+// report the change since the user last looked. A quantity of 0 means the ticker is only watched,
+// not held, and it doesn't count toward the total value.
+
+// watchlistEntry is one watched ticker
+type watchlistEntry struct {
+	Quantity    float64
+	LastPrice   float64
+	LastPriceAt string
+}
+
+// getWatchlist reads the watchlist from state; entries without a usable last price are dropped
+// Numbers are float64 both when freshly set and after a database round trip
+func getWatchlist(state session.ReadonlyState) map[string]watchlistEntry {
+	watchlist := map[string]watchlistEntry{}
+	val, err := state.Get(WATCHLIST_KEY)
+	if err != nil {
+		return watchlist
+	}
+	all, _ := val.(map[string]any)
+	for ticker, raw := range all {
+		fields, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		lastPrice, ok := fields["last_price"].(float64)
+		if !ok || lastPrice <= 0 {
+			continue
+		}
+		quantity, _ := fields["quantity"].(float64)
+		lastPriceAt, _ := fields["last_price_at"].(string)
+		watchlist[ticker] = watchlistEntry{Quantity: quantity, LastPrice: lastPrice, LastPriceAt: lastPriceAt}
+	}
+	return watchlist
+}
+
+// watchlistToState converts the watchlist to the plain maps stored in state
+func watchlistToState(watchlist map[string]watchlistEntry) map[string]any {
+	stored := make(map[string]any, len(watchlist))
+	for ticker, entry := range watchlist {
+		stored[ticker] = map[string]any{
+			"quantity":      entry.Quantity,
+			"last_price":    entry.LastPrice,
+			"last_price_at": entry.LastPriceAt,
+		}
+	}
+	return stored
+}
+
+// fetchCurrentPrice returns the current price of ticker as a number
+func fetchCurrentPrice(cache *stockPriceCache, ticker string) (float64, time.Time, error) {
+	raw, fetchedAt, _, err := cache.Get(ticker)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	price, err := strconv.ParseFloat(raw, 64)
+	if err != nil || price <= 0 {
+		return 0, time.Time{}, fmt.Errorf("invalid price %q", raw)
+	}
+	return price, fetchedAt, nil
+}
+
+// ===== Watchlist Tool Structures =====
+
+type watchStockArgs struct {
+	Ticker   string  `json:"ticker"`
+	Quantity float64 `json:"quantity,omitempty"` // shares held; 0 to only watch the price
+}
+
+type watchStockResults struct {
+	Status   string  `json:"status"`
+	Ticker   string  `json:"ticker,omitempty"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	Tickers  int     `json:"tickers,omitempty"`
+	Message  string  `json:"message"`
+}
+
+type unwatchStockArgs struct {
+	Ticker string `json:"ticker"`
+}
+
+type unwatchStockResults struct {
+	Status  string `json:"status"`
+	Ticker  string `json:"ticker,omitempty"`
+	Tickers int    `json:"tickers"`
+	Message string `json:"message"`
+}
+
+type watchlistStatsArgs struct{}
+
+// watchlistStock is one ticker in the stats. ChangePercent is the change since the last check.
+type watchlistStock struct {
+	Ticker        string  `json:"ticker"`
+	Price         float64 `json:"price"`
+	Quantity      float64 `json:"quantity,omitempty"`
+	Value         float64 `json:"value,omitempty"`
+	PreviousPrice float64 `json:"previous_price"`
+	PreviousAt    string  `json:"previous_at,omitempty"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// watchlistMover is the biggest gainer or loser
+type watchlistMover struct {
+	Ticker        string  `json:"ticker"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// omittedTicker is a ticker left out of the stats because its price couldn't be fetched
+type omittedTicker struct {
+	Ticker string `json:"ticker"`
+	Reason string `json:"reason"`
+}
+
+type watchlistStatsResults struct {
+	Status        string           `json:"status"`
+	Stocks        []watchlistStock `json:"stocks,omitempty"`
+	TotalValue    *float64         `json:"total_value,omitempty"` // only when quantities are stored
+	AveragePrice  float64          `json:"average_price,omitempty"`
+	BiggestGainer *watchlistMover  `json:"biggest_gainer,omitempty"`
+	BiggestLoser  *watchlistMover  `json:"biggest_loser,omitempty"`
+	Omitted       []omittedTicker  `json:"omitted,omitempty"`
+	Message       string           `json:"message"`
+}
+
+// ===== Tool Implementation =====
+
+// watchStock adds ticker to the watchlist or updates its quantity. A new ticker's current price
+// becomes its last price; an existing ticker keeps its last price so its change isn't reset.
+func watchStock(state session.State, cache *stockPriceCache, input watchStockArgs) watchStockResults {
+	ticker := strings.ToUpper(strings.TrimSpace(input.Ticker))
+	if ticker == "" {
+		return watchStockResults{Status: "error", Message: "A ticker symbol is required"}
+	}
+	if input.Quantity < 0 || math.IsNaN(input.Quantity) || math.IsInf(input.Quantity, 0) {
+		return watchStockResults{Status: "error", Message: fmt.Sprintf("Quantity must be 0 or more, got %v", input.Quantity)}
+	}
+
+	watchlist := getWatchlist(state)
+	entry, exists := watchlist[ticker]
+	if !exists && len(watchlist) >= MAX_WATCHLIST_TICKERS {
+		return watchStockResults{
+			Status:  "error",
+			Message: fmt.Sprintf("The watchlist is full (%d tickers); remove one with unwatch_stock first", MAX_WATCHLIST_TICKERS),
+		}
+	}
+
+	// Fetch even for known tickers so the reported price is current
+	price, fetchedAt, err := fetchCurrentPrice(cache, ticker)
+	if errors.Is(err, errUnknownTicker) {
+		return watchStockResults{
+			Status:  "error",
+			Message: fmt.Sprintf("Could not fetch price for %s. Available tickers: GOOG, GOOGL, TSLA, META, AAPL, MSFT, AMZN", ticker),
+		}
+	}
+	if err != nil {
+		return watchStockResults{Status: "error", Message: fmt.Sprintf("Could not fetch price for %s: %v", ticker, err)}
+	}
+
+	if !exists {
+		entry = watchlistEntry{LastPrice: price, LastPriceAt: fetchedAt.Format(WATCHLIST_TIME_FORMAT)}
+	}
+	entry.Quantity = input.Quantity
+	watchlist[ticker] = entry
+	if err := state.Set(WATCHLIST_KEY, watchlistToState(watchlist)); err != nil {
+		return watchStockResults{Status: "error", Message: fmt.Sprintf("Failed to save the watchlist: %v", err)}
+	}
+
+	verb := "Added"
+	if exists {
+		verb = "Updated"
+	}
+	return watchStockResults{
+		Status:   "success",
+		Ticker:   ticker,
+		Quantity: input.Quantity,
+		Price:    price,
+		Tickers:  len(watchlist),
+		Message:  fmt.Sprintf("%s %s on the watchlist (%d tickers)", verb, ticker, len(watchlist)),
+	}
+}
+
+// unwatchStock removes ticker from the watchlist
+func unwatchStock(state session.State, input unwatchStockArgs) unwatchStockResults {
+	ticker := strings.ToUpper(strings.TrimSpace(input.Ticker))
+	watchlist := getWatchlist(state)
+	if _, ok := watchlist[ticker]; !ok {
+		return unwatchStockResults{
+			Status:  "not_found",
+			Ticker:  ticker,
+			Tickers: len(watchlist),
+			Message: fmt.Sprintf("%s is not on the watchlist", ticker),
+		}
+	}
+
+	delete(watchlist, ticker)
+	if err := state.Set(WATCHLIST_KEY, watchlistToState(watchlist)); err != nil {
+		return unwatchStockResults{Status: "error", Ticker: ticker, Tickers: len(watchlist) + 1, Message: fmt.Sprintf("Failed to save the watchlist: %v", err)}
+	}
+	return unwatchStockResults{
+		Status:  "success",
+		Ticker:  ticker,
+		Tickers: len(watchlist),
+		Message: fmt.Sprintf("Removed %s from the watchlist", ticker),
+	}
+}
+
+// watchlistStats fetches the price of every watched ticker and computes the total value, the
+// average price and the biggest gainer and loser since the last check. Tickers whose price can't
+// be fetched are left out and listed in Omitted; they keep their last price for the next check.
+func watchlistStats(state session.State, cache *stockPriceCache) watchlistStatsResults {
+	watchlist := getWatchlist(state)
+	if len(watchlist) == 0 {
+		return watchlistStatsResults{Status: "success", Message: "The watchlist is empty; add tickers with watch_stock"}
+	}
+
+	tickers := make([]string, 0, len(watchlist))
+	for ticker := range watchlist {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	results := watchlistStatsResults{Status: "success"}
+	var total, priceSum float64
+	held := false
+	for _, ticker := range tickers {
+		entry := watchlist[ticker]
+		price, fetchedAt, err := fetchCurrentPrice(cache, ticker)
+		if err != nil {
+			results.Omitted = append(results.Omitted, omittedTicker{Ticker: ticker, Reason: err.Error()})
+			continue
+		}
+
+		stock := watchlistStock{
+			Ticker:        ticker,
+			Price:         price,
+			Quantity:      entry.Quantity,
+			PreviousPrice: entry.LastPrice,
+			PreviousAt:    entry.LastPriceAt,
+			ChangePercent: roundCents((price - entry.LastPrice) / entry.LastPrice * 100),
+		}
+		if entry.Quantity > 0 {
+			stock.Value = roundCents(price * entry.Quantity)
+			total += price * entry.Quantity
+			held = true
+		}
+		priceSum += price
+		results.Stocks = append(results.Stocks, stock)
+
+		if stock.ChangePercent > 0 && (results.BiggestGainer == nil || stock.ChangePercent > results.BiggestGainer.ChangePercent) {
+			results.BiggestGainer = &watchlistMover{Ticker: ticker, ChangePercent: stock.ChangePercent}
+		}
+		if stock.ChangePercent < 0 && (results.BiggestLoser == nil || stock.ChangePercent < results.BiggestLoser.ChangePercent) {
+			results.BiggestLoser = &watchlistMover{Ticker: ticker, ChangePercent: stock.ChangePercent}
+		}
+
+		entry.LastPrice = price
+		entry.LastPriceAt = fetchedAt.Format(WATCHLIST_TIME_FORMAT)
+		watchlist[ticker] = entry
+	}
+
+	if len(results.Stocks) == 0 {
+		results.Status = "error"
+		results.Message = fmt.Sprintf("Could not fetch a price for any of the %d watched tickers", len(tickers))
+		return results
+	}
+
+	if held {
+		total = roundCents(total)
+		results.TotalValue = &total
+	}
+	results.AveragePrice = roundCents(priceSum / float64(len(results.Stocks)))
+
+	// Only the fetched tickers got a new last price, so the next check compares against it
+	if err := state.Set(WATCHLIST_KEY, watchlistToState(watchlist)); err != nil {
+		results.Status = "error"
+		results.Message = fmt.Sprintf("Failed to save the new prices: %v", err)
+		return results
+	}
+
+	results.Message = fmt.Sprintf("Stats for %d of %d watched tickers", len(results.Stocks), len(tickers))
+	if len(results.Omitted) > 0 {
+		omitted := make([]string, len(results.Omitted))
+		for i, o := range results.Omitted {
+			omitted[i] = o.Ticker
+		}
+		results.Message += fmt.Sprintf("; %s left out because the price couldn't be fetched", strings.Join(omitted, ", "))
+	}
+	if results.BiggestGainer == nil && results.BiggestLoser == nil {
+		results.Message += "; no price changes since the last check"
+	}
+	return results
+}
+
+// roundCents rounds to two decimals
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ===== Tool Creation =====
+
+// NewWatchlistTools creates the watch_stock, unwatch_stock and watchlist_stats tools. They belong
+// on the manager, so the watchlist is saved in the user's session. Prices come from the same mock
+// source as the stock analyst, through a cache of their own.
+func NewWatchlistTools() ([]tool.Tool, error) {
+	priceCache := newStockPriceCache(stockPriceCacheTTL(), fetchMockStockPrice)
+
+	watchStockTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "watch_stock",
+			Description: "Adds a ticker to the user's stock watchlist, or updates it, with the number of shares held (0 to only watch the price)",
+		},
+		func(ctx tool.Context, input watchStockArgs) (watchStockResults, error) {
+			fmt.Printf("--- Tool: watch_stock called for %s (quantity %v) ---\n", input.Ticker, input.Quantity)
+			return watchStock(ctx.State(), priceCache, input), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch_stock tool: %w", err)
+	}
+
+	unwatchStockTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "unwatch_stock",
+			Description: "Removes a ticker from the user's stock watchlist",
+		},
+		func(ctx tool.Context, input unwatchStockArgs) (unwatchStockResults, error) {
+			fmt.Printf("--- Tool: unwatch_stock called for %s ---\n", input.Ticker)
+			return unwatchStock(ctx.State(), input), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unwatch_stock tool: %w", err)
+	}
+
+	watchlistStatsTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "watchlist_stats",
+			Description: "Fetches the current price of every stock on the user's watchlist and returns the total value of the holdings, the average price and the biggest gainer and loser since the last check",
+		},
+		func(ctx tool.Context, _ watchlistStatsArgs) (watchlistStatsResults, error) {
+			fmt.Println("--- Tool: watchlist_stats called ---")
+			return watchlistStats(ctx.State(), priceCache), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watchlist_stats tool: %w", err)
+	}
+
+	return []tool.Tool{watchStockTool, unwatchStockTool, watchlistStatsTool}, nil
+}
@@ -0,0 +1,136 @@
+package agents
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+// mockPrices returns a price cache without caching, serving prices that tests can change
+func mockPrices(prices map[string]string) *stockPriceCache {
+	cache := newStockPriceCache(0, func(ticker string) (string, error) {
+		if price, ok := prices[ticker]; ok {
+			return price, nil
+		}
+		return "", errUnknownTicker
+	})
+	cache.now = func() time.Time { return time.Date(2024, 4, 21, 16, 30, 0, 0, time.UTC) }
+	return cache
+}
+
+func TestWatchStock(t *testing.T) {
+	prices := map[string]string{"GOOG": "175.34", "TSLA": "156.78"}
+	cache := mockPrices(prices)
+	state := agenttest.NewState(nil, true)
+
+	if results := watchStock(state, cache, watchStockArgs{Ticker: " goog ", Quantity: 10}); results.Status != "success" || results.Ticker != "GOOG" || results.Price != 175.34 {
+		t.Fatalf("watchStock(goog) = %+v, want GOOG added at 175.34", results)
+	}
+
+	// Updating the quantity keeps the last price, so the change since then isn't lost
+	prices["GOOG"] = "180.00"
+	if results := watchStock(state, cache, watchStockArgs{Ticker: "GOOG", Quantity: 4}); results.Status != "success" || !strings.HasPrefix(results.Message, "Updated") {
+		t.Errorf("watchStock(GOOG) again = %+v, want it updated", results)
+	}
+	if entry := getWatchlist(state)["GOOG"]; entry.Quantity != 4 || entry.LastPrice != 175.34 || entry.LastPriceAt != "2024-04-21 16:30:00" {
+		t.Errorf("GOOG entry = %+v, want quantity 4 and last price 175.34", entry)
+	}
+
+	for _, input := range []watchStockArgs{{Ticker: "XYZ"}, {Ticker: " "}, {Ticker: "TSLA", Quantity: -1}} {
+		if results := watchStock(state, cache, input); results.Status != "error" || results.Message == "" {
+			t.Errorf("watchStock(%+v) = %+v, want an error", input, results)
+		}
+	}
+	if got := getWatchlist(state); len(got) != 1 {
+		t.Errorf("watchlist = %+v, want only GOOG", got)
+	}
+}
+
+func TestUnwatchStock(t *testing.T) {
+	state := agenttest.NewState(nil, true)
+	watchStock(state, mockPrices(map[string]string{"GOOG": "175.34"}), watchStockArgs{Ticker: "GOOG"})
+
+	if results := unwatchStock(state, unwatchStockArgs{Ticker: "goog"}); results.Status != "success" || results.Tickers != 0 {
+		t.Errorf("unwatchStock(goog) = %+v, want it removed", results)
+	}
+	if results := unwatchStock(state, unwatchStockArgs{Ticker: "GOOG"}); results.Status != "not_found" {
+		t.Errorf("unwatchStock(GOOG) again = %+v, want not_found", results)
+	}
+}
+
+func TestWatchlistStats(t *testing.T) {
+	prices := map[string]string{"GOOG": "100.00", "TSLA": "200.00", "META": "50.00", "AAPL": "80.00"}
+	cache := mockPrices(prices)
+	state := agenttest.NewState(nil, true)
+	watchStock(state, cache, watchStockArgs{Ticker: "GOOG", Quantity: 10})
+	watchStock(state, cache, watchStockArgs{Ticker: "TSLA", Quantity: 2})
+	watchStock(state, cache, watchStockArgs{Ticker: "META"}) // watched, not held
+	watchStock(state, cache, watchStockArgs{Ticker: "AAPL", Quantity: 1})
+
+	prices["GOOG"] = "110.00"  // +10%
+	prices["TSLA"] = "190.00"  // -5%
+	prices["META"] = "not-set" // unparsable
+	delete(prices, "AAPL")     // fetch fails
+
+	results := watchlistStats(state, cache)
+	if results.Status != "success" || len(results.Stocks) != 2 {
+		t.Fatalf("watchlistStats() = %+v, want stats for GOOG and TSLA", results)
+	}
+	if results.TotalValue == nil || *results.TotalValue != 1480 {
+		t.Errorf("total value = %v, want 1480", results.TotalValue)
+	}
+	if results.AveragePrice != 150 {
+		t.Errorf("average price = %v, want 150", results.AveragePrice)
+	}
+	if results.BiggestGainer == nil || results.BiggestGainer.Ticker != "GOOG" || results.BiggestGainer.ChangePercent != 10 {
+		t.Errorf("biggest gainer = %+v, want GOOG +10%%", results.BiggestGainer)
+	}
+	if results.BiggestLoser == nil || results.BiggestLoser.Ticker != "TSLA" || results.BiggestLoser.ChangePercent != -5 {
+		t.Errorf("biggest loser = %+v, want TSLA -5%%", results.BiggestLoser)
+	}
+	if len(results.Omitted) != 2 || results.Omitted[0].Ticker != "AAPL" || results.Omitted[1].Ticker != "META" {
+		t.Errorf("omitted = %+v, want AAPL and META", results.Omitted)
+	}
+	if !strings.Contains(results.Message, "AAPL, META left out") {
+		t.Errorf("message = %q, want it to note the omitted tickers", results.Message)
+	}
+
+	// Fetched tickers now compare against the new prices; omitted ones keep their old price
+	watchlist := getWatchlist(state)
+	if watchlist["GOOG"].LastPrice != 110 || watchlist["AAPL"].LastPrice != 80 {
+		t.Errorf("watchlist = %+v, want GOOG updated and AAPL unchanged", watchlist)
+	}
+	results = watchlistStats(state, cache)
+	if results.BiggestGainer != nil || results.BiggestLoser != nil || !strings.Contains(results.Message, "no price changes") {
+		t.Errorf("second watchlistStats() = %+v, want no changes", results)
+	}
+}
+
+func TestWatchlistStatsWithoutHoldings(t *testing.T) {
+	cache := mockPrices(map[string]string{"GOOG": "175.34", "TSLA": "156.78"})
+	state := agenttest.NewState(nil, true)
+	if results := watchlistStats(state, cache); results.Status != "success" || !strings.Contains(results.Message, "empty") {
+		t.Errorf("watchlistStats(empty) = %+v, want an empty watchlist", results)
+	}
+
+	watchStock(state, cache, watchStockArgs{Ticker: "GOOG"})
+	watchStock(state, cache, watchStockArgs{Ticker: "TSLA"})
+	results := watchlistStats(state, cache)
+	if results.TotalValue != nil || results.AveragePrice != 166.06 {
+		t.Errorf("watchlistStats() = %+v, want no total value and average 166.06", results)
+	}
+}
+
+func TestWatchlistStatsAllFailing(t *testing.T) {
+	state := agenttest.NewState(nil, true)
+	watchStock(state, mockPrices(map[string]string{"GOOG": "175.34"}), watchStockArgs{Ticker: "GOOG", Quantity: 1})
+
+	down := newStockPriceCache(0, func(string) (string, error) { return "", errors.New("rate limited") })
+	results := watchlistStats(state, down)
+	if results.Status != "error" || len(results.Omitted) != 1 || results.Omitted[0].Reason != "rate limited" {
+		t.Errorf("watchlistStats() = %+v, want an error with GOOG omitted", results)
+	}
+}
@@ -1,5 +1,5 @@
 // Package main demonstrates multi-agent systems in ADK with modular organization.
-// This example creates a manager agent that delegates tasks to specialized agents:
+// This example creates a manager agent that coordinates specialized agents:
 // - Stock Analyst: Provides stock market information (agents/stock_analyst.go)
 // - Funny Nerd: Tells nerdy jokes about technical topics (agents/funny_nerd.go)
 // - News Analyst: Provides current technology news (agents/news_analyst.go)
@@ -27,6 +27,9 @@ import (
 
 	"github.com/muchlist/agent-dev-kit/7-multi-agent/manager_agent/agents"
 	"github.com/muchlist/agent-dev-kit/7-multi-agent/manager_agent/tools"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolregistry"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 const (
@@ -36,11 +39,29 @@ const (
 // ===== Manager Agent Creation =====
 
 // createManagerAgent creates the root manager agent that coordinates other agents
-func createManagerAgent(_ context.Context, mdl model.LLM, stockAnalyst, funnyNerd, newsAnalyst agent.Agent) (agent.Agent, error) {
-	// Create get_current_time tool from tools package
-	getCurrentTimeTool, err := tools.NewGetCurrentTimeTool()
+func createManagerAgent(_ context.Context, mdl model.LLM, errorLog *toolutil.ToolErrorLog, searchQuota *toolutil.SearchQuota, stockAnalyst, funnyNerd, newsAnalyst agent.Agent) (agent.Agent, error) {
+	// Get the shared get_current_time tool from the registry (also used by the customer service order agent)
+	getCurrentTimeTool, err := toolregistry.Get(toolregistry.GET_CURRENT_TIME)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create get_current_time tool: %w", err)
+		return nil, err
+	}
+
+	// Create fetch_and_summarize tool for user-supplied article links
+	fetchAndSummarizeTool, err := tools.NewFetchAndSummarizeTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fetch_and_summarize tool: %w", err)
+	}
+
+	// Create calculate tool so arithmetic is computed instead of guessed by the model
+	calculatorTool, err := tools.NewCalculatorTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calculate tool: %w", err)
+	}
+
+	// Create convert_units tool for length, mass and temperature conversions
+	unitConverterTool, err := tools.NewUnitConverterTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create convert_units tool: %w", err)
 	}
 
 	// Wrap news_analyst as an AgentTool
@@ -48,6 +69,66 @@ func createManagerAgent(_ context.Context, mdl model.LLM, stockAnalyst, funnyNer
 	// Note: In Go ADK, agents with built-in tools should be wrapped as AgentTools
 	newsAnalystTool := agenttool.New(newsAnalyst, &agenttool.Config{})
 
+	// Wrap stock_analyst as an AgentTool as well, so the manager stays in control
+	// and can combine stock data with news or time lookups in a single answer.
+	// Note: AgentTool runs the agent in a fresh session seeded with a copy of the
+	// manager's state. State the stock analyst writes there is NOT merged back, so
+	// any per-user stock state (e.g. a watchlist) must be written by the manager's
+	// own tools to persist across calls.
+	stockAnalystTool := agenttool.New(stockAnalyst, &agenttool.Config{})
+
+	subAgents := []agent.Agent{funnyNerd}
+	agentTools := []tool.Tool{stockAnalystTool, newsAnalystTool}
+	managerTools := append(append([]tool.Tool{}, agentTools...), getCurrentTimeTool, fetchAndSummarizeTool, calculatorTool, unitConverterTool)
+
+	// Create the watchlist tools (watch_stock, unwatch_stock, watchlist_stats) on the manager,
+	// since state written inside the stock analyst's agent tool session is discarded
+	watchlistTools, err := agents.NewWatchlistTools()
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, watchlistTools...)
+
+	// Create the holdings tools (add_holding, remove_holding, portfolio_value) for the same reason
+	holdingsTools, err := agents.NewHoldingsTools()
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, holdingsTools...)
+
+	// Create list_delegates tool from the constructed sub-agents and agent tools, so routing can
+	// follow the live configuration; the list in the instruction below stays as a fallback
+	listDelegatesTool, err := toolutil.NewListDelegatesTool(subAgents, agentTools)
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, listDelegatesTool)
+
+	// Count every tool call (and transfer to a sub-agent) in session state, and create the
+	// tool_stats tool that reports the counts
+	toolCallCounter := toolutil.NewToolCallCounter()
+	toolStatsTool, err := toolCallCounter.NewStatsTool()
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, toolStatsTool)
+
+	// Create recent_errors tool; it reports the failed tool calls errorLog records in state,
+	// including those of the stock analyst
+	recentErrorsTool, err := errorLog.NewRecentErrorsTool()
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, recentErrorsTool)
+
+	// Create what_can_you_do tool from the actual tool and sub-agent lists, so "what can you help
+	// with?" is answered from the configuration instead of made up
+	capabilitiesTool, err := toolutil.NewCapabilitiesTool(managerTools, subAgents)
+	if err != nil {
+		return nil, err
+	}
+	managerTools = append(managerTools, capabilitiesTool)
+
 	// Create manager agent with sub-agents and tools
 	manager, err := llmagent.New(llmagent.Config{
 		Name:        "manager",
@@ -58,24 +139,65 @@ func createManagerAgent(_ context.Context, mdl model.LLM, stockAnalyst, funnyNer
 Always delegate the task to the appropriate agent. Use your best judgement
 to determine which agent to delegate to.
 
-You are responsible for delegating tasks to the following agents:
-- stock_analyst: Use this agent for questions about stock prices, market data, or financial information
+You are responsible for delegating tasks to the following agent:
 - funny_nerd: Use this agent when users want to hear nerdy jokes about technical topics
 
 You also have access to the following tools:
-- news_analyst: Use this tool to search and analyze current news (especially tech news)
+- stock_analyst: Use this tool for questions about stock prices, market data, or financial information.
+  Pass it the user's question (including the ticker symbols); it returns its analysis to you, so
+  relay the answer and combine it with other tools when the user asks for more than prices
+- news_analyst: Use this tool to search and analyze current news (especially tech news).
+  Its answer may end with a "Sources:" list; always include that list unchanged in your reply
 - get_current_time: Use this tool to get the current date and time
+- fetch_and_summarize: Use this tool when the user pastes a specific article or page URL.
+  It returns the page's text; summarize that text yourself. If it returns an error, explain it
+  and offer to search for the topic with news_analyst instead
+- calculate: Use this tool for ANY arithmetic (percentages, totals, price changes, etc.).
+  Never do math yourself; pass the full expression, e.g. "(189.50 - 175.34) / 175.34 * 100".
+  If it returns an error, fix the expression or explain the problem (e.g. division by zero)
+- convert_units: Use this tool to convert a value between units of length, mass or temperature
+  (e.g. 5 km to miles, 150 lb to kg, 72 F to C). Never convert units yourself. If it returns an
+  error (e.g. meters to kilograms), explain why the units can't be converted
+- watch_stock / unwatch_stock: Use these tools when the user wants to add a stock to or remove it from
+  their watchlist. Pass the number of shares as "quantity" if the user mentions it (0 = only watch the price)
+- watchlist_stats: Use this tool for questions about the watchlist as a whole (total value, average price,
+  best and worst performers). Report its numbers as returned; changes are since the last check. If it lists
+  "omitted" tickers, say their prices couldn't be fetched and that they aren't included
+- add_holding / remove_holding: Use these tools when the user says they bought, own or sold shares.
+  Pass the number of shares bought or sold as "quantity". If remove_holding returns "insufficient", tell
+  the user how many shares they actually hold
+- portfolio_value: Use this tool when the user asks what their shares or portfolio are worth. Report each
+  holding's value and the total; if it lists "omitted" holdings, say they aren't included in the total
+- what_can_you_do: Use this tool when the user asks what you can do, help with, or which tools you have.
+  Describe your capabilities only from its result, in friendly terms with an example for each
+- list_delegates: Use this tool when you are unsure which agent should handle a request. It lists the
+  agents you were actually built with, their descriptions, and whether to transfer to them or call them
+  as a tool. Prefer its result over the lists above; they are only a fallback if the tool fails
+- tool_stats: Use this tool when the user asks how often tools or agents have been used in this session.
+  Report the counts from its result; transfer_to_agent/<name> entries are hand-offs to that agent
+- recent_errors: Use this tool when the user asks whether tools have been failing or why an answer was
+  incomplete. Report which tool failed, the message and when, newest first
 
 When a user asks a question:
-1. Determine if it's about stocks (→ delegate to stock_analyst)
+1. Determine if it's about stocks (→ use stock_analyst tool, or the watchlist and holdings tools for the user's own stocks)
 2. Determine if it's about nerdy jokes (→ delegate to funny_nerd)
 3. Determine if it's about news (→ use news_analyst tool)
 4. Determine if it's about current time (→ use get_current_time tool)
-5. For general questions, you can answer directly
+5. Determine if it contains a link to summarize (→ use fetch_and_summarize tool)
+6. Determine if it involves a calculation (→ use calculate tool, also for math on stock prices)
+7. Determine if it's a unit conversion (→ use convert_units tool)
+8. Determine if it asks what you can do (→ use what_can_you_do tool)
+9. Determine if it asks about tool usage (→ use tool_stats tool) or tool failures (→ use recent_errors tool)
+10. If none of the above clearly fits (→ use list_delegates and route based on its descriptions)
+11. For general questions, you can answer directly
 
 Be friendly and helpful in your responses!`,
-		SubAgents: []agent.Agent{stockAnalyst, funnyNerd},
-		Tools:     []tool.Tool{newsAnalystTool, getCurrentTimeTool},
+		SubAgents: subAgents,
+		Tools:     managerTools,
+		// The news analyst runs in a new session for every call; recording the manager's session
+		// lets its searches count against this conversation's quota
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{searchQuota.BeforeRootAgent},
+		AfterToolCallbacks:   []llmagent.AfterToolCallback{toolCallCounter.AfterTool, errorLog.AfterTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create manager agent: %w", err)
@@ -98,28 +220,47 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
+	// Failed tool calls of the manager and the stock analyst are recorded in the errors state key
+	errorLog := toolutil.NewToolErrorLog(toolutil.DEFAULT_MAX_TOOL_ERRORS)
+
 	// Create specialized agents using modular agent constructors
-	stockAnalyst, err := agents.NewStockAnalyst(ctx, model)
+	stockAnalyst, err := agents.NewStockAnalyst(ctx, model, errorLog)
 	if err != nil {
 		log.Fatalf("Failed to create stock analyst agent: %v", err)
 	}
 
-	funnyNerd, err := agents.NewFunnyNerd(ctx, model)
+	funnyNerdGeneration, err := agentutil.LoadGenerationParams(os.Getenv, "FUNNY_NERD", agents.FUNNY_NERD_GENERATION)
+	if err != nil {
+		log.Fatalf("Failed to load funny nerd generation parameters: %v", err)
+	}
+	funnyNerd, err := agents.NewFunnyNerd(ctx, model, funnyNerdGeneration)
 	if err != nil {
 		log.Fatalf("Failed to create funny nerd agent: %v", err)
 	}
 
-	newsAnalyst, err := agents.NewNewsAnalyst(ctx, model)
+	// Limit the news analyst's Google searches per conversation (MAX_SEARCHES_PER_SESSION, 0 = no limit)
+	maxSearches, err := toolutil.LoadMaxSearches(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to load search quota: %v", err)
+	}
+	searchQuota := toolutil.NewSearchQuota(maxSearches)
+
+	newsAnalyst, err := agents.NewNewsAnalyst(ctx, model, searchQuota)
 	if err != nil {
 		log.Fatalf("Failed to create news analyst agent: %v", err)
 	}
 
 	// Create manager agent that coordinates all specialized agents
-	managerAgent, err := createManagerAgent(ctx, model, stockAnalyst, funnyNerd, newsAnalyst)
+	managerAgent, err := createManagerAgent(ctx, model, errorLog, searchQuota, stockAnalyst, funnyNerd, newsAnalyst)
 	if err != nil {
 		log.Fatalf("Failed to create manager agent: %v", err)
 	}
 
+	// Catch duplicate sub-agent names before they cause confusing routing
+	if err := agentutil.ValidateAgentTree(managerAgent); err != nil {
+		log.Fatalf("Invalid agent tree: %v", err)
+	}
+
 	fmt.Println("\n🚀 Launching Multi-Agent System...")
 	fmt.Println("========================================")
 
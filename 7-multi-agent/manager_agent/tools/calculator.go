@@ -0,0 +1,372 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	CALCULATOR_MAX_LENGTH = 500 // longest expression accepted
+	CALCULATOR_MAX_DEPTH  = 50  // deepest nesting of parentheses, unary signs and function calls
+)
+
+var errDivisionByZero = errors.New("division by zero")
+
+// calculatorFunctions are the functions an expression may call, by name and number of arguments
+var calculatorFunctions = map[string]struct {
+	args int
+	fn   func(args []float64) (float64, error)
+}{
+	"sqrt": {1, func(a []float64) (float64, error) {
+		if a[0] < 0 {
+			return 0, fmt.Errorf("sqrt of a negative number (%g)", a[0])
+		}
+		return math.Sqrt(a[0]), nil
+	}},
+	"abs":   {1, func(a []float64) (float64, error) { return math.Abs(a[0]), nil }},
+	"round": {1, func(a []float64) (float64, error) { return math.Round(a[0]), nil }},
+	"floor": {1, func(a []float64) (float64, error) { return math.Floor(a[0]), nil }},
+	"ceil":  {1, func(a []float64) (float64, error) { return math.Ceil(a[0]), nil }},
+	"ln": {1, func(a []float64) (float64, error) {
+		if a[0] <= 0 {
+			return 0, fmt.Errorf("ln of a non-positive number (%g)", a[0])
+		}
+		return math.Log(a[0]), nil
+	}},
+	"log10": {1, func(a []float64) (float64, error) {
+		if a[0] <= 0 {
+			return 0, fmt.Errorf("log10 of a non-positive number (%g)", a[0])
+		}
+		return math.Log10(a[0]), nil
+	}},
+	"sin": {1, func(a []float64) (float64, error) { return math.Sin(a[0]), nil }},
+	"cos": {1, func(a []float64) (float64, error) { return math.Cos(a[0]), nil }},
+	"tan": {1, func(a []float64) (float64, error) { return math.Tan(a[0]), nil }},
+	"pow": {2, func(a []float64) (float64, error) { return math.Pow(a[0], a[1]), nil }},
+	"min": {2, func(a []float64) (float64, error) { return math.Min(a[0], a[1]), nil }},
+	"max": {2, func(a []float64) (float64, error) { return math.Max(a[0], a[1]), nil }},
+}
+
+var calculatorConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// ===== Calculator Tool Structures =====
+
+type calculateArgs struct {
+	Expression string `json:"expression"`
+}
+
+type calculateResults struct {
+	Status       string  `json:"status"`
+	Expression   string  `json:"expression"`
+	Result       float64 `json:"result"`
+	Formatted    string  `json:"formatted,omitempty"`
+	ErrorMessage string  `json:"error_message,omitempty"`
+}
+
+// ===== Tool Implementation =====
+
+// calculate evaluates an arithmetic expression with a small recursive-descent parser.
+// Nothing is ever executed: only numbers, operators, parentheses and a fixed set of
+// functions and constants are understood, so the expression can't inject code.
+func calculate(ctx tool.Context, input calculateArgs) (calculateResults, error) {
+	fmt.Printf("--- Tool: calculate called for %q ---\n", input.Expression)
+
+	result, err := evaluateExpression(input.Expression)
+	if err != nil {
+		return calculateResults{
+			Status:       "error",
+			Expression:   input.Expression,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	return calculateResults{
+		Status:     "success",
+		Expression: input.Expression,
+		Result:     result,
+		Formatted:  strconv.FormatFloat(result, 'f', -1, 64),
+	}, nil
+}
+
+// evaluateExpression parses and evaluates an arithmetic expression.
+//
+// Grammar (standard precedence, ^ is right-associative and binds tighter than unary minus):
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = ("+" | "-") unary | power
+//	power      = primary [ "^" unary ]
+//	primary    = number | constant | function "(" expression { "," expression } ")" | "(" expression ")"
+func evaluateExpression(expression string) (float64, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return 0, fmt.Errorf("expression is empty")
+	}
+	if len(expression) > CALCULATOR_MAX_LENGTH {
+		return 0, fmt.Errorf("expression is longer than %d characters", CALCULATOR_MAX_LENGTH)
+	}
+
+	p := &expressionParser{input: expression}
+	result, err := p.parseExpression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return result, nil
+}
+
+// expressionParser walks the expression string; pos is the index of the next unread byte
+type expressionParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end of the input
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *expressionParser) enter() error {
+	p.depth++
+	if p.depth > CALCULATOR_MAX_DEPTH {
+		return fmt.Errorf("expression is nested more than %d levels deep", CALCULATOR_MAX_DEPTH)
+	}
+	return nil
+}
+
+func (p *expressionParser) parseExpression() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *expressionParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, errDivisionByZero
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, errDivisionByZero
+			}
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+func (p *expressionParser) parseUnary() (float64, error) {
+	if err := p.enter(); err != nil {
+		return 0, err
+	}
+	defer func() { p.depth-- }()
+
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err := p.parseUnary()
+		return -value, err
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *expressionParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *expressionParser) parsePrimary() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return 0, fmt.Errorf("expression ended unexpectedly")
+	case c == '(':
+		p.pos++
+		value, err := p.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing closing parenthesis at position %d", p.pos+1)
+		}
+		p.pos++
+		return value, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case unicode.IsLetter(rune(c)):
+		return p.parseIdentifier()
+	}
+	return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+func (p *expressionParser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		isExponent := (c == 'e' || c == 'E') && p.pos+1 < len(p.input) &&
+			(p.input[p.pos+1] >= '0' && p.input[p.pos+1] <= '9' || p.input[p.pos+1] == '-' || p.input[p.pos+1] == '+')
+		switch {
+		case c >= '0' && c <= '9' || c == '.' || c == '_':
+			p.pos++
+		case isExponent:
+			p.pos += 2
+		default:
+			return p.finishNumber(start)
+		}
+	}
+	return p.finishNumber(start)
+}
+
+func (p *expressionParser) finishNumber(start int) (float64, error) {
+	text := strings.ReplaceAll(p.input[start:p.pos], "_", "")
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at position %d", p.input[start:p.pos], start+1)
+	}
+	return value, nil
+}
+
+func (p *expressionParser) parseIdentifier() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+
+	if p.peek() != '(' {
+		if value, ok := calculatorConstants[name]; ok {
+			return value, nil
+		}
+		return 0, fmt.Errorf("unknown name %q at position %d", name, start+1)
+	}
+
+	function, ok := calculatorFunctions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown function %q at position %d", name, start+1)
+	}
+	if err := p.enter(); err != nil {
+		return 0, err
+	}
+	defer func() { p.depth-- }()
+
+	p.pos++ // consume "("
+	var args []float64
+	if p.peek() != ')' {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, arg)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	if p.peek() != ')' {
+		return 0, fmt.Errorf("missing closing parenthesis for %s at position %d", name, p.pos+1)
+	}
+	p.pos++
+
+	if len(args) != function.args {
+		return 0, fmt.Errorf("%s takes %d argument(s), got %d", name, function.args, len(args))
+	}
+	return function.fn(args)
+}
+
+// ===== Tool Creation =====
+
+// NewCalculatorTool creates a tool that safely evaluates arithmetic expressions,
+// so the agent doesn't have to do math itself
+func NewCalculatorTool() (tool.Tool, error) {
+	calculatorTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "calculate",
+			Description: "Evaluates an arithmetic expression and returns the exact numeric result. " +
+				"Supports + - * / % ^, parentheses, the constants pi and e, and the functions " +
+				"sqrt, abs, round, floor, ceil, ln, log10, sin, cos, tan (radians), pow(x, y), min(x, y) and max(x, y). " +
+				"Example: (1250 * 1.07^3) / 12",
+		},
+		calculate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calculate tool: %w", err)
+	}
+
+	return calculatorTool, nil
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"2 + 3 * 4", 14},
+		{"(2 + 3) * 4", 20},
+		{"10 - 4 - 3", 3},   // left-associative
+		{"100 / 10 / 5", 2}, // left-associative
+		{"7 % 3 * 2", 2},
+		{"2 ^ 3 ^ 2", 512}, // right-associative
+		{"2 * 3 ^ 2", 18},
+		{"-2 ^ 2", -4}, // ^ binds tighter than unary minus
+		{"(-2) ^ 2", 4},
+		{"2 ^ -1", 0.5},
+		{"--3", 3},
+		{"+4 - -1", 5},
+		{"1_000 * 1.5e3", 1500000},
+		{"max(2, pow(2, 3)) + abs(-1)", 9},
+		{"round(2 * pi)", 6},
+		{"sqrt(16) + ln(e)", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := evaluateExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("evaluateExpression(%q) failed: %v", tt.expression, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("evaluateExpression(%q) = %g, want %g", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + "1" + strings.Repeat(")", depth)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{"empty", "  ", "empty"},
+		{"division by zero", "1 / (2 - 2)", "division by zero"},
+		{"modulo by zero", "5 % 0", "division by zero"},
+		{"infinite result", "10 ^ 400", "not a finite number"},
+		{"infinite power of zero", "0 ^ -1", "not a finite number"},
+		{"negative sqrt", "sqrt(-1)", "sqrt of a negative number"},
+		{"log of zero", "log10(0)", "non-positive"},
+		{"too long", strings.Repeat("1+", CALCULATOR_MAX_LENGTH/2) + "1", "longer than"},
+		{"nested too deep", nested(CALCULATOR_MAX_DEPTH), "nested more than"},
+		{"too many signs", strings.Repeat("-", CALCULATOR_MAX_DEPTH) + "1", "nested more than"},
+		{"trailing operator", "1 +", "ended unexpectedly"},
+		{"doubled operator", "2 ** 3", "unexpected '*'"},
+		{"unclosed parenthesis", "(1 + 2", "missing closing parenthesis"},
+		{"extra parenthesis", "1 + 2)", "unexpected ')'"},
+		{"bad number", "1..2", "invalid number"},
+		{"unknown name", "x + 1", "unknown name"},
+		{"unknown function", "exp(1)", "unknown function"},
+		{"wrong argument count", "sqrt(1, 2)", "takes 1 argument"},
+		{"code", "os.Exit(1)", "unknown name \"os\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evaluateExpression(tt.expression)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("evaluateExpression(%q) error = %v, want one containing %q", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := evaluateExpression("1 / 0"); !errors.Is(err, errDivisionByZero) {
+		t.Errorf("evaluateExpression(1 / 0) error = %v, want errDivisionByZero", err)
+	}
+}
+
+func TestEvaluateExpressionLimits(t *testing.T) {
+	// Just within both limits
+	if _, err := evaluateExpression(strings.Repeat("(", CALCULATOR_MAX_DEPTH-1) + "1" + strings.Repeat(")", CALCULATOR_MAX_DEPTH-1)); err != nil {
+		t.Errorf("nesting within the limit failed: %v", err)
+	}
+	if _, err := evaluateExpression(strings.Repeat("1+", (CALCULATOR_MAX_LENGTH-1)/2) + "1"); err != nil {
+		t.Errorf("expression within the length limit failed: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	FETCH_TIMEOUT       = 10 * time.Second
+	FETCH_MAX_BYTES     = 2 * 1024 * 1024 // stop reading pages after 2 MB
+	FETCH_MAX_CHARS     = 8000            // cleaned text handed to the model
+	FETCH_USER_AGENT    = "agent-dev-kit-news-reader/1.0 (+https://github.com/muchlist/agent-dev-kit)"
+	FETCH_MAX_REDIRECTS = 10
+	fetchTruncatedNote  = "\n\n[content truncated]"
+)
+
+// ===== Fetch Tool Structures =====
+
+type fetchAndSummarizeArgs struct {
+	URL string `json:"url"`
+}
+
+type fetchAndSummarizeResults struct {
+	Status       string `json:"status"`
+	URL          string `json:"url,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Content      string `json:"content,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+var (
+	scriptStyleRe = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)[^>]*>.*?</(script|style|noscript|svg|head)>`)
+	commentRe     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	blockTagRe    = regexp.MustCompile(`(?i)</?(p|div|br|li|h[1-6]|tr|section|article|header|footer)[^>]*>`)
+	tagRe         = regexp.MustCompile(`(?s)<[^>]+>`)
+	titleRe       = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	spacesRe      = regexp.MustCompile(`[ \t\f\r]+`)
+	newlinesRe    = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// fetchClient only connects to public addresses. The model picks the URL, so without this
+// check it could reach loopback services, the cloud metadata endpoint (169.254.169.254) or
+// the private network, directly or through a redirect. The check runs on the resolved IP
+// at connect time, so a hostname can't resolve to a public address first and a private one later.
+var fetchClient = &http.Client{
+	Timeout: FETCH_TIMEOUT,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: FETCH_TIMEOUT,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				return checkFetchAddress(address)
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: FETCH_TIMEOUT,
+	},
+	CheckRedirect: checkFetchRedirect,
+}
+
+var (
+	errBlockedAddress = errors.New("refusing to connect to a private, loopback or link-local address")
+
+	// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), not covered by netip's IsPrivate
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+// checkFetchAddress rejects a dial address ("ip:port") that isn't a public unicast address
+func checkFetchAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errBlockedAddress, address)
+	}
+	ip := addrPort.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", errBlockedAddress, ip)
+	}
+	return nil
+}
+
+// checkFetchRedirect only follows redirects to http and https URLs, at most FETCH_MAX_REDIRECTS times
+func checkFetchRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing to follow a redirect to a %s URL", req.URL.Scheme)
+	}
+	if len(via) >= FETCH_MAX_REDIRECTS {
+		return fmt.Errorf("stopped after %d redirects", FETCH_MAX_REDIRECTS)
+	}
+	return nil
+}
+
+// ===== Tool Implementation =====
+
+// fetchAndSummarize downloads a web page and returns its readable text so the model can summarize it
+// Only the first FETCH_MAX_BYTES of the page are read and the cleaned text is capped at FETCH_MAX_CHARS
+func fetchAndSummarize(ctx tool.Context, input fetchAndSummarizeArgs) (fetchAndSummarizeResults, error) {
+	fmt.Printf("--- Tool: fetch_and_summarize called for %s ---\n", input.URL)
+
+	pageURL, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          input.URL,
+			ErrorMessage: "Please provide a full http:// or https:// URL.",
+		}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return fetchAndSummarizeResults{}, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", FETCH_USER_AGENT)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+
+	resp, err := fetchClient.Do(req)
+	if errors.Is(err, errBlockedAddress) {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			ErrorMessage: "That URL points to a private or local address, which can't be fetched.",
+		}, nil
+	}
+	if err != nil {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			ErrorMessage: fmt.Sprintf("Could not fetch the page: %v", err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			ErrorMessage: fmt.Sprintf("The page returned HTTP %d.", resp.StatusCode),
+		}, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && mediaType != "text/plain" {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			ErrorMessage: fmt.Sprintf("Unsupported content type %q. Only HTML and plain-text pages can be summarized.", mediaType),
+		}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, FETCH_MAX_BYTES+1))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			ErrorMessage: fmt.Sprintf("Failed to read the page: %v", err),
+		}, nil
+	}
+	truncated := len(body) > FETCH_MAX_BYTES
+	if truncated {
+		body = body[:FETCH_MAX_BYTES]
+	}
+
+	var title, text string
+	if isHTML {
+		title, text = extractText(string(body))
+	} else {
+		text = strings.TrimSpace(string(body))
+	}
+
+	if len(text) > FETCH_MAX_CHARS {
+		text = truncateUTF8(text, FETCH_MAX_CHARS)
+		truncated = true
+	}
+	if truncated {
+		text += fetchTruncatedNote
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return fetchAndSummarizeResults{
+			Status:       "error",
+			URL:          pageURL.String(),
+			Title:        title,
+			ErrorMessage: "The page did not contain any readable text (it may require JavaScript).",
+		}, nil
+	}
+
+	return fetchAndSummarizeResults{
+		Status:    "success",
+		URL:       pageURL.String(),
+		Title:     title,
+		Content:   text,
+		Truncated: truncated,
+	}, nil
+}
+
+// extractText performs a basic HTML-to-text conversion: it drops scripts, styles and
+// comments, turns block-level tags into line breaks, strips the remaining tags and
+// decodes HTML entities.
+func extractText(page string) (title, text string) {
+	if m := titleRe.FindStringSubmatch(page); m != nil {
+		title = strings.TrimSpace(html.UnescapeString(tagRe.ReplaceAllString(m[1], "")))
+	}
+
+	text = scriptStyleRe.ReplaceAllString(page, " ")
+	text = commentRe.ReplaceAllString(text, " ")
+	text = blockTagRe.ReplaceAllString(text, "\n")
+	text = tagRe.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = spacesRe.ReplaceAllString(text, " ")
+	text = newlinesRe.ReplaceAllString(text, "\n\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return title, strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// truncateUTF8 cuts s to at most max bytes without splitting a multi-byte character
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+// ===== Tool Creation =====
+
+// NewFetchAndSummarizeTool creates a tool that fetches a web page and returns its cleaned text
+// Note: This is a function tool, so it lives on the manager rather than on news_analyst,
+// which already uses the GoogleSearch built-in tool
+func NewFetchAndSummarizeTool() (tool.Tool, error) {
+	fetchAndSummarizeTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "fetch_and_summarize",
+			Description: "Fetches a web page (e.g. a news article URL) and returns its cleaned text content so it can be summarized",
+		},
+		fetchAndSummarize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fetch_and_summarize tool: %w", err)
+	}
+
+	return fetchAndSummarizeTool, nil
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckFetchAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"169.254.169.254:80", false}, // cloud metadata endpoint
+		{"[fe80::1]:80", false},
+		{"10.0.0.5:8080", false},
+		{"172.16.3.4:80", false},
+		{"192.168.1.1:80", false},
+		{"100.64.0.1:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false}, // IPv4-mapped loopback
+		{"0.0.0.0:80", false},
+		{"224.0.0.1:80", false},
+		{"localhost:80", false}, // only resolved addresses are dialed
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkFetchAddress(tt.address)
+			if allowed := err == nil; allowed != tt.allowed {
+				t.Errorf("checkFetchAddress(%q) = %v, want allowed %v", tt.address, err, tt.allowed)
+			}
+			if err != nil && !errors.Is(err, errBlockedAddress) {
+				t.Errorf("checkFetchAddress(%q) = %v, want errBlockedAddress", tt.address, err)
+			}
+		})
+	}
+}
+
+func TestFetchClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request reached the loopback server")
+	}))
+	defer server.Close()
+
+	resp, err := fetchClient.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, errBlockedAddress) {
+		t.Errorf("Get(%s) error = %v, want errBlockedAddress", server.URL, err)
+	}
+}
+
+func TestCheckFetchRedirect(t *testing.T) {
+	request := func(rawURL string) *http.Request {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Request{URL: u}
+	}
+
+	if err := checkFetchRedirect(request("https://example.com/next"), nil); err != nil {
+		t.Errorf("redirect to https: %v", err)
+	}
+	for _, target := range []string{"file:///etc/passwd", "ftp://example.com/file", "gopher://example.com"} {
+		if err := checkFetchRedirect(request(target), nil); err == nil {
+			t.Errorf("redirect to %s was allowed", target)
+		}
+	}
+	via := make([]*http.Request, FETCH_MAX_REDIRECTS)
+	if err := checkFetchRedirect(request("https://example.com/next"), via); err == nil {
+		t.Errorf("redirect number %d was allowed", FETCH_MAX_REDIRECTS+1)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		wantTitle string
+		wantText  string
+	}{
+		{
+			name: "article",
+			page: `<html><head><title> Markets &amp; Stocks </title><style>p { color: red }</style></head>` +
+				`<body><h1>Rates rise</h1><p>The Fed raised   rates.</p><p>Stocks <b>fell</b> 2%.</p></body></html>`,
+			wantTitle: "Markets & Stocks",
+			wantText:  "Rates rise\n\nThe Fed raised rates.\n\nStocks fell 2%.",
+		},
+		{
+			name:     "scripts and comments",
+			page:     `<div>Before<script>var x = "<p>hidden</p>";</script><!-- note --> after</div>`,
+			wantText: "Before after",
+		},
+		{
+			name:     "line breaks and entities",
+			page:     `Line one<br>Line &quot;two&quot;<br/>&lt;three&gt;`,
+			wantText: "Line one\nLine \"two\"\n<three>",
+		},
+		{
+			name:      "tags in the title",
+			page:      `<head><title><b>Bold</b> title</title></head><p>Body</p>`,
+			wantTitle: "Bold title",
+			wantText:  "Body",
+		},
+		{
+			name:     "only scripts",
+			page:     `<script>render()</script><noscript>Enable JavaScript</noscript>`,
+			wantText: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, text := extractText(tt.page)
+			if title != tt.wantTitle || text != tt.wantText {
+				t.Errorf("extractText() = %q, %q, want %q, %q", title, text, tt.wantTitle, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes and isn't split
+		{"héllo", 3, "hé"},
+		{"日本語", 4, "日"},
+		{"日本語", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateUTF8(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncateUTF8(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("é", FETCH_MAX_CHARS)
+	if got := truncateUTF8(long, FETCH_MAX_CHARS+1); len(got) != FETCH_MAX_CHARS {
+		t.Errorf("truncateUTF8 of a long text has %d bytes, want %d", len(got), FETCH_MAX_CHARS)
+	}
+}
@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// unitDefinition describes a unit by its canonical name and how it relates to the category's base unit
+// (meters for length, kilograms for mass, kelvin for temperature): base = value*factor + offset
+type unitDefinition struct {
+	name   string
+	factor float64
+	offset float64
+}
+
+// unitCategories maps each category to its units, keyed by every accepted alias
+var unitCategories = map[string]map[string]unitDefinition{
+	"length": withAliases(map[string][]string{
+		"millimeter":    {"mm", "millimeter", "millimeters", "millimetre", "millimetres"},
+		"centimeter":    {"cm", "centimeter", "centimeters", "centimetre", "centimetres"},
+		"meter":         {"m", "meter", "meters", "metre", "metres"},
+		"kilometer":     {"km", "kilometer", "kilometers", "kilometre", "kilometres", "kms"},
+		"inch":          {"in", "inch", "inches", "\""},
+		"foot":          {"ft", "foot", "feet", "'"},
+		"yard":          {"yd", "yard", "yards", "yds"},
+		"mile":          {"mi", "mile", "miles"},
+		"nautical mile": {"nmi", "nautical mile", "nautical miles"},
+	}, map[string]unitDefinition{
+		"millimeter":    {factor: 0.001},
+		"centimeter":    {factor: 0.01},
+		"meter":         {factor: 1},
+		"kilometer":     {factor: 1000},
+		"inch":          {factor: 0.0254},
+		"foot":          {factor: 0.3048},
+		"yard":          {factor: 0.9144},
+		"mile":          {factor: 1609.344},
+		"nautical mile": {factor: 1852},
+	}),
+	"mass": withAliases(map[string][]string{
+		"milligram": {"mg", "milligram", "milligrams", "milligramme", "milligrammes"},
+		"gram":      {"g", "gram", "grams", "gramme", "grammes"},
+		"kilogram":  {"kg", "kgs", "kilo", "kilos", "kilogram", "kilograms", "kilogramme", "kilogrammes"},
+		"tonne":     {"t", "tonne", "tonnes", "metric ton", "metric tons"},
+		"ounce":     {"oz", "ounce", "ounces"},
+		"pound":     {"lb", "lbs", "pound", "pounds"},
+		"stone":     {"st", "stone", "stones"},
+	}, map[string]unitDefinition{
+		"milligram": {factor: 0.000001},
+		"gram":      {factor: 0.001},
+		"kilogram":  {factor: 1},
+		"tonne":     {factor: 1000},
+		"ounce":     {factor: 0.028349523125},
+		"pound":     {factor: 0.45359237},
+		"stone":     {factor: 6.35029318},
+	}),
+	// Temperatures are affine: they need an offset as well as a factor
+	"temperature": withAliases(map[string][]string{
+		"celsius":    {"c", "°c", "celsius", "centigrade", "degc"},
+		"fahrenheit": {"f", "°f", "fahrenheit", "degf"},
+		"kelvin":     {"k", "kelvin", "kelvins"},
+	}, map[string]unitDefinition{
+		"celsius":    {factor: 1, offset: 273.15},
+		"fahrenheit": {factor: 5.0 / 9.0, offset: 273.15 - 32*5.0/9.0},
+		"kelvin":     {factor: 1},
+	}),
+}
+
+// categoryAliases lets the model say "weight" or "temp" for a category
+var categoryAliases = map[string]string{
+	"length":      "length",
+	"distance":    "length",
+	"mass":        "mass",
+	"weight":      "mass",
+	"temperature": "temperature",
+	"temp":        "temperature",
+}
+
+// withAliases builds an alias lookup from canonical unit names to their definitions
+func withAliases(aliases map[string][]string, units map[string]unitDefinition) map[string]unitDefinition {
+	lookup := make(map[string]unitDefinition)
+	for name, names := range aliases {
+		unit := units[name]
+		unit.name = name
+		for _, alias := range names {
+			lookup[alias] = unit
+		}
+	}
+	return lookup
+}
+
+// ===== Unit Converter Tool Structures =====
+
+type convertUnitsArgs struct {
+	Value    float64 `json:"value"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Category string  `json:"category"`
+}
+
+type convertUnitsResults struct {
+	Status       string  `json:"status"`
+	Category     string  `json:"category,omitempty"`
+	Value        float64 `json:"value"`
+	From         string  `json:"from,omitempty"`
+	To           string  `json:"to,omitempty"`
+	Result       float64 `json:"result"`
+	Formatted    string  `json:"formatted,omitempty"`
+	ErrorMessage string  `json:"error_message,omitempty"`
+}
+
+// ===== Tool Implementation =====
+
+// convertUnits converts a value between two units of the same category
+func convertUnits(ctx tool.Context, input convertUnitsArgs) (convertUnitsResults, error) {
+	fmt.Printf("--- Tool: convert_units called for %g %s -> %s (%s) ---\n", input.Value, input.From, input.To, input.Category)
+
+	category, from, to, err := resolveUnits(input.Category, input.From, input.To)
+	if err != nil {
+		return convertUnitsResults{
+			Status:       "error",
+			Value:        input.Value,
+			From:         input.From,
+			To:           input.To,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	base := input.Value*from.factor + from.offset
+	if category == "temperature" && base < 0 {
+		return convertUnitsResults{
+			Status:       "error",
+			Category:     category,
+			Value:        input.Value,
+			From:         from.name,
+			To:           to.name,
+			ErrorMessage: fmt.Sprintf("%g %s is below absolute zero", input.Value, from.name),
+		}, nil
+	}
+	result := (base - to.offset) / to.factor
+	// Trim floating point noise such as 99.99999999999999
+	result = math.Round(result*1e9) / 1e9
+
+	return convertUnitsResults{
+		Status:    "success",
+		Category:  category,
+		Value:     input.Value,
+		From:      from.name,
+		To:        to.name,
+		Result:    result,
+		Formatted: fmt.Sprintf("%s %s = %s %s", strconv.FormatFloat(input.Value, 'f', -1, 64), from.name, strconv.FormatFloat(result, 'f', -1, 64), to.name),
+	}, nil
+}
+
+// resolveUnits finds both units and checks they belong to the same category.
+// An empty category is inferred from the "from" unit.
+func resolveUnits(rawCategory, rawFrom, rawTo string) (string, unitDefinition, unitDefinition, error) {
+	fromKey, toKey := normalizeUnit(rawFrom), normalizeUnit(rawTo)
+
+	category := ""
+	if strings.TrimSpace(rawCategory) != "" {
+		var ok bool
+		category, ok = categoryAliases[strings.ToLower(strings.TrimSpace(rawCategory))]
+		if !ok {
+			return "", unitDefinition{}, unitDefinition{}, fmt.Errorf("unknown category %q, use length, mass or temperature", rawCategory)
+		}
+	} else if category = unitCategory(fromKey); category == "" {
+		return "", unitDefinition{}, unitDefinition{}, fmt.Errorf("unknown unit %q", rawFrom)
+	}
+
+	units := unitCategories[category]
+	from, fromOK := units[fromKey]
+	to, toOK := units[toKey]
+	switch {
+	case !fromOK && !toOK:
+		return "", unitDefinition{}, unitDefinition{}, fmt.Errorf("neither %q nor %q is a %s unit (supported: %s)", rawFrom, rawTo, category, supportedUnits(category))
+	case !fromOK:
+		return "", unitDefinition{}, unitDefinition{}, incompatibleUnitError(rawFrom, fromKey, category)
+	case !toOK:
+		return "", unitDefinition{}, unitDefinition{}, incompatibleUnitError(rawTo, toKey, category)
+	}
+
+	return category, from, to, nil
+}
+
+// incompatibleUnitError explains why a unit can't be used in the category, e.g. meters to kilograms
+func incompatibleUnitError(raw, key, category string) error {
+	if other := unitCategory(key); other != "" {
+		return fmt.Errorf("cannot convert between %s and %s: %q is a %s unit", category, other, raw, other)
+	}
+	return fmt.Errorf("unknown %s unit %q (supported: %s)", category, raw, supportedUnits(category))
+}
+
+// normalizeUnit lowercases a unit and drops a leading "degrees"/"degree", so "Degrees Celsius" matches "celsius"
+func normalizeUnit(raw string) string {
+	unit := strings.Join(strings.Fields(strings.ToLower(raw)), " ")
+	for _, prefix := range []string{"degrees ", "degree ", "deg "} {
+		unit = strings.TrimPrefix(unit, prefix)
+	}
+	return unit
+}
+
+// unitCategory returns the category that knows the unit, or "" if none does
+func unitCategory(key string) string {
+	for category, units := range unitCategories {
+		if _, ok := units[key]; ok {
+			return category
+		}
+	}
+	return ""
+}
+
+func supportedUnits(category string) string {
+	seen := map[string]bool{}
+	var names []string
+	for _, unit := range unitCategories[category] {
+		if !seen[unit.name] {
+			seen[unit.name] = true
+			names = append(names, unit.name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ===== Tool Creation =====
+
+// NewUnitConverterTool creates a tool that converts values between length, mass and temperature units
+func NewUnitConverterTool() (tool.Tool, error) {
+	unitConverterTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "convert_units",
+			Description: "Converts a value between units of the same category. " +
+				"category is length, mass or temperature. Units accept common names and abbreviations, " +
+				"e.g. km/kilometer/kilometre, mi, ft, in, kg, lb, oz, g, celsius/C, fahrenheit/F, kelvin/K",
+		},
+		convertUnits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create convert_units tool: %w", err)
+	}
+
+	return unitConverterTool, nil
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input convertUnitsArgs
+		want  float64
+	}{
+		{"kilometers to miles", convertUnitsArgs{Value: 10, From: "km", To: "mi", Category: "length"}, 6.213711922},
+		{"feet to meters", convertUnitsArgs{Value: 3, From: "feet", To: "Metres", Category: "distance"}, 0.9144},
+		{"inches to centimeters", convertUnitsArgs{Value: 12, From: "in", To: "cm"}, 30.48},
+		{"negative length", convertUnitsArgs{Value: -2, From: "m", To: "mm"}, -2000},
+		{"pounds to kilograms", convertUnitsArgs{Value: 10, From: "lbs", To: "kg", Category: "weight"}, 4.5359237},
+		{"stone to pounds", convertUnitsArgs{Value: 1, From: "st", To: "lb", Category: "mass"}, 14},
+		{"grams to ounces", convertUnitsArgs{Value: 1000, From: "g", To: "oz"}, 35.273961950},
+		{"celsius to fahrenheit", convertUnitsArgs{Value: 100, From: "C", To: "F", Category: "temp"}, 212},
+		{"fahrenheit to celsius", convertUnitsArgs{Value: 32, From: "degrees fahrenheit", To: "celsius"}, 0},
+		{"celsius to kelvin", convertUnitsArgs{Value: 25, From: "°C", To: "K"}, 298.15},
+		{"kelvin to fahrenheit", convertUnitsArgs{Value: 0, From: "kelvin", To: "F"}, -459.67},
+		{"negative temperature", convertUnitsArgs{Value: -40, From: "f", To: "c"}, -40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, _ := convertUnits(nil, tt.input)
+			if results.Status != "success" || results.Result != tt.want {
+				t.Errorf("convertUnits(%+v) = %+v, want %g", tt.input, results, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertUnitsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   convertUnitsArgs
+		wantErr string
+	}{
+		{"unknown unit", convertUnitsArgs{Value: 1, From: "furlong", To: "m"}, `unknown unit "furlong"`},
+		{"unknown target unit", convertUnitsArgs{Value: 1, From: "m", To: "parsec"}, `unknown length unit "parsec"`},
+		{"unknown category", convertUnitsArgs{Value: 1, From: "l", To: "ml", Category: "volume"}, "unknown category"},
+		{"mass to length", convertUnitsArgs{Value: 1, From: "kg", To: "km"}, "cannot convert between mass and length"},
+		{"units outside the category", convertUnitsArgs{Value: 1, From: "kg", To: "lb", Category: "length"}, "neither"},
+		{"below absolute zero", convertUnitsArgs{Value: -300, From: "C", To: "F"}, "below absolute zero"},
+		{"negative kelvin", convertUnitsArgs{Value: -1, From: "K", To: "C"}, "below absolute zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, _ := convertUnits(nil, tt.input)
+			if results.Status != "error" || !strings.Contains(results.ErrorMessage, tt.wantErr) {
+				t.Errorf("convertUnits(%+v) = %+v, want an error containing %q", tt.input, results, tt.wantErr)
+			}
+		})
+	}
+}
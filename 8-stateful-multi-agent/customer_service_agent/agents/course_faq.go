@@ -0,0 +1,196 @@
+package agents
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// ===== Course FAQ =====
+//
+// Course support gets the same questions again and again ("where do I start?", "when are the
+// coaching calls?"). match_faq answers those from a fixed FAQ, so the answer is the same every
+// time, and the agent only reasons from the course outline when nothing matches.
+//
+// Similarity is the cosine similarity of the keyword sets of the user's question and each FAQ
+// question: both go through termSet (lowercased, stop words dropped, endings stemmed as in
+// search_policy), and the score is |shared| / sqrt(|question| * |faq question|), from 0 (no
+// shared keyword) to 1 (the same keywords). Each FAQ entry lists a few phrasings; the best one
+// counts. Below FAQ_MATCH_THRESHOLD there is no match.
+
+const (
+	// COURSE_FAQ_FILE replaces the built-in FAQ when it exists in the working directory. It has
+	// the same shape as the embedded course_faq.json.
+	COURSE_FAQ_FILE = "course_faq.json"
+
+	// FAQ_MATCH_THRESHOLD is the lowest similarity counted as a match: for a two-keyword question
+	// against a two-keyword FAQ question, one shared keyword is exactly 0.5
+	FAQ_MATCH_THRESHOLD = 0.5
+
+	FAQ_SIMILARITY_METHOD = "cosine similarity of stemmed keywords"
+)
+
+//go:embed course_faq.json
+var embeddedCourseFAQ []byte
+
+// faqEntry is one FAQ answer and the questions it answers
+type faqEntry struct {
+	ID        string   `json:"id"`
+	Questions []string `json:"questions"`
+	Answer    string   `json:"answer"`
+}
+
+// courseFAQ is the loaded FAQ with each question's keywords
+type courseFAQ struct {
+	entries []faqEntry
+	terms   [][]map[string]bool // terms[i][j] are the keywords of entries[i].Questions[j]
+}
+
+type matchFAQArgs struct {
+	Question string `json:"question"`
+}
+
+type matchFAQResults struct {
+	Status          string  `json:"status"` // success, no_match or error
+	FAQID           string  `json:"faq_id,omitempty"`
+	MatchedQuestion string  `json:"matched_question,omitempty"`
+	Answer          string  `json:"answer,omitempty"`
+	Similarity      float64 `json:"similarity"`
+	Threshold       float64 `json:"threshold"`
+	Method          string  `json:"method"`
+	Message         string  `json:"message"`
+}
+
+// loadCourseFAQ reads the FAQ from path when it exists, and the embedded FAQ otherwise. It
+// reports whether the file was used.
+func loadCourseFAQ(path string) (courseFAQ, bool, error) {
+	var entries []faqEntry
+	fromFile, err := toolutil.LoadToolData(path, embeddedCourseFAQ, &entries)
+	if err != nil {
+		return courseFAQ{}, fromFile, err
+	}
+	faq, err := newCourseFAQ(entries)
+	if err != nil {
+		return courseFAQ{}, fromFile, fmt.Errorf("FAQ in %s: %w", path, err)
+	}
+	return faq, fromFile, nil
+}
+
+// newCourseFAQ checks the entries and extracts the keywords of their questions
+func newCourseFAQ(entries []faqEntry) (courseFAQ, error) {
+	if len(entries) == 0 {
+		return courseFAQ{}, fmt.Errorf("no FAQ entries")
+	}
+	faq := courseFAQ{entries: entries}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.ID) == "" || strings.TrimSpace(entry.Answer) == "" || len(entry.Questions) == 0 {
+			return courseFAQ{}, fmt.Errorf("entry %q needs an id, an answer and at least one question", entry.ID)
+		}
+		if seen[entry.ID] {
+			return courseFAQ{}, fmt.Errorf("entry %q is listed more than once", entry.ID)
+		}
+		seen[entry.ID] = true
+
+		var questionTerms []map[string]bool
+		for _, question := range entry.Questions {
+			terms := termSet(question)
+			if len(terms) == 0 {
+				return courseFAQ{}, fmt.Errorf("entry %q: question %q has no keywords", entry.ID, question)
+			}
+			questionTerms = append(questionTerms, terms)
+		}
+		faq.terms = append(faq.terms, questionTerms)
+	}
+	return faq, nil
+}
+
+// match returns the entry whose questions are most similar to question, if any is at least
+// FAQ_MATCH_THRESHOLD similar
+func (f courseFAQ) match(question string) matchFAQResults {
+	results := matchFAQResults{Threshold: FAQ_MATCH_THRESHOLD, Method: FAQ_SIMILARITY_METHOD}
+	terms := termSet(question)
+	if len(terms) == 0 {
+		results.Status = "error"
+		results.Message = "question needs at least one keyword; pass the user's question as asked"
+		return results
+	}
+
+	best, bestQuestion := -1, 0
+	for i, questionTerms := range f.terms {
+		for j, faqTerms := range questionTerms {
+			// Strictly greater, so earlier entries win ties
+			if score := keywordSimilarity(terms, faqTerms); score > results.Similarity {
+				results.Similarity, best, bestQuestion = score, i, j
+			}
+		}
+	}
+	results.Similarity = math.Round(results.Similarity*100) / 100
+
+	if best < 0 || results.Similarity < FAQ_MATCH_THRESHOLD {
+		results.Status = "no_match"
+		results.Message = fmt.Sprintf("No FAQ match (best similarity %.2f, threshold %.2f). Answer from the course outline instead.",
+			results.Similarity, FAQ_MATCH_THRESHOLD)
+		return results
+	}
+
+	entry := f.entries[best]
+	results.Status = "success"
+	results.FAQID = entry.ID
+	results.MatchedQuestion = entry.Questions[bestQuestion]
+	results.Answer = entry.Answer
+	results.Message = fmt.Sprintf("FAQ match %q (similarity %.2f). Give the user this answer.", entry.ID, results.Similarity)
+	return results
+}
+
+// keywordSimilarity is the cosine similarity of two keyword sets, from 0 to 1
+func keywordSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(a)*len(b)))
+}
+
+// NewMatchFAQTool creates the match_faq tool over the course FAQ (see COURSE_FAQ_FILE)
+func NewMatchFAQTool() (tool.Tool, error) {
+	faq, fromFile, err := loadCourseFAQ(COURSE_FAQ_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load course FAQ: %w", err)
+	}
+	if fromFile {
+		slog.Info("course FAQ loaded", "file", COURSE_FAQ_FILE, "entries", len(faq.entries))
+	}
+
+	matchFAQ := func(ctx tool.Context, input matchFAQArgs) (matchFAQResults, error) {
+		results := faq.match(input.Question)
+		slog.Info("tool called", "tool", "match_faq", "agent", ctx.AgentName(), "session_id", ctx.SessionID(),
+			"status", results.Status, "faq_id", results.FAQID, "similarity", results.Similarity)
+		return results, nil
+	}
+
+	matchFAQTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "match_faq",
+			Description: "Matches the user's question against the course FAQ and returns the FAQ answer when it is " +
+				"similar enough, or status no_match",
+		},
+		matchFAQ,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create match_faq tool: %w", err)
+	}
+	return matchFAQTool, nil
+}
@@ -0,0 +1,112 @@
+package agents
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func embeddedFAQ(t *testing.T) courseFAQ {
+	t.Helper()
+	faq, fromFile, err := loadCourseFAQ(filepath.Join(t.TempDir(), COURSE_FAQ_FILE))
+	if err != nil || fromFile {
+		t.Fatalf("loadCourseFAQ() = %v, %v; want the embedded FAQ", fromFile, err)
+	}
+	return faq
+}
+
+func TestMatchFAQ(t *testing.T) {
+	faq := embeddedFAQ(t)
+
+	for question, wantID := range map[string]string{
+		"where do I start":                                  "where_to_start",
+		"When are the weekly coaching calls?":               "coaching_calls",
+		"Does my access to the course ever expire?":         "access_duration",
+		"How do I setup the Postgres database?":             "database",
+		"Which section covers Stripe payments?":             "payments",
+		"Can I use the course code in my own side project?": "code_usage",
+	} {
+		results := faq.match(question)
+		if results.Status != "success" || results.FAQID != wantID {
+			t.Errorf("match(%q) = %s %q (%.2f), want %s", question, results.Status, results.FAQID, results.Similarity, wantID)
+			continue
+		}
+		if results.Answer == "" || results.MatchedQuestion == "" || results.Similarity < FAQ_MATCH_THRESHOLD {
+			t.Errorf("match(%q) = %+v, want an answer above the threshold", question, results)
+		}
+		if results.Method != FAQ_SIMILARITY_METHOD || results.Threshold != FAQ_MATCH_THRESHOLD {
+			t.Errorf("match(%q) method = %q at %v, want %q at %v", question, results.Method, results.Threshold, FAQ_SIMILARITY_METHOD, FAQ_MATCH_THRESHOLD)
+		}
+	}
+}
+
+func TestMatchFAQNoMatch(t *testing.T) {
+	faq := embeddedFAQ(t)
+
+	for _, question := range []string{
+		"Why does my useEffect hook run twice in section 12?",
+		"How do I add dark mode to the sidebar?",
+	} {
+		results := faq.match(question)
+		if results.Status != "no_match" || results.Answer != "" || results.Similarity >= FAQ_MATCH_THRESHOLD {
+			t.Errorf("match(%q) = %+v, want no_match below the threshold", question, results)
+		}
+	}
+
+	if results := faq.match("Where is it?"); results.Status != "error" {
+		t.Errorf("match of stop words = %+v, want error", results)
+	}
+}
+
+func TestKeywordSimilarity(t *testing.T) {
+	set := func(words ...string) map[string]bool {
+		s := map[string]bool{}
+		for _, w := range words {
+			s[w] = true
+		}
+		return s
+	}
+	tests := []struct {
+		a, b map[string]bool
+		want float64
+	}{
+		{set("start"), set("start"), 1},
+		{set("coach", "call"), set("coach", "call", "weekly", "sunday"), 2 / math.Sqrt(8)},
+		{set("deploy"), set("stripe", "payment"), 0},
+		{set("deploy"), set(), 0},
+	}
+	for _, tt := range tests {
+		if got := keywordSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("keywordSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCourseFAQFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), COURSE_FAQ_FILE)
+	if err := os.WriteFile(path, []byte(`[{"id": "refund", "questions": ["Can I get a refund?"], "answer": "Ask the order agent."}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	faq, fromFile, err := loadCourseFAQ(path)
+	if err != nil || !fromFile {
+		t.Fatalf("loadCourseFAQ() = %v, %v; want the file", fromFile, err)
+	}
+	if results := faq.match("refund please"); results.FAQID != "refund" {
+		t.Errorf("match = %+v, want the file's entry", results)
+	}
+
+	for name, content := range map[string]string{
+		"no answer":     `[{"id": "a", "questions": ["Where do I start?"], "answer": ""}]`,
+		"duplicate id":  `[{"id": "a", "questions": ["start"], "answer": "x"}, {"id": "a", "questions": ["deploy"], "answer": "y"}]`,
+		"stop words":    `[{"id": "a", "questions": ["Where is it?"], "answer": "x"}]`,
+		"unknown field": `[{"id": "a", "question": "start", "answer": "x"}]`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := loadCourseFAQ(path); err == nil {
+			t.Errorf("%s: loadCourseFAQ() = nil error, want invalid", name)
+		}
+	}
+}
@@ -3,18 +3,263 @@ package agents
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+)
+
+const (
+	PENDING_ONBOARDING_KEY = "pending_onboarding"
+	ONBOARDING_WINDOW      = 24 * time.Hour // purchases older than this don't trigger onboarding
+
+	ACTIVE_COURSE_KEY = "active_course" // id of the course the user is currently asking about
 )
 
+// ===== Course Support Tool Structures =====
+
+type listMyCoursesArgs struct{}
+
+type ownedCourse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	PurchaseDate string `json:"purchase_date"`
+	Active       bool   `json:"active"`
+}
+
+type listMyCoursesResults struct {
+	Status       string        `json:"status"`
+	Courses      []ownedCourse `json:"courses"`
+	ActiveCourse string        `json:"active_course,omitempty"`
+	Message      string        `json:"message"`
+}
+
+type setActiveCourseArgs struct {
+	CourseID string `json:"course_id"`
+}
+
+type setActiveCourseResults struct {
+	Status       string   `json:"status"` // success, not_owned or error
+	ActiveCourse string   `json:"active_course,omitempty"`
+	CourseName   string   `json:"course_name,omitempty"`
+	OwnedCourses []string `json:"owned_courses,omitempty"`
+	Message      string   `json:"message"`
+}
+
+// ===== Tool Implementations =====
+
+// listMyCourses returns the courses the user owns and which one is active
+func listMyCourses(ctx tool.Context, input listMyCoursesArgs) (listMyCoursesResults, error) {
+	slog.Info("tool called", "tool", "list_my_courses", "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+
+	state := ctx.State()
+	active := getActiveCourse(state)
+
+	courses := []ownedCourse{}
+	for _, course := range getPurchasedCourses(state) {
+		courses = append(courses, ownedCourse{
+			ID:           course.ID,
+			Name:         courseName(course.ID),
+			PurchaseDate: course.PurchaseDate,
+			Active:       course.ID == active,
+		})
+	}
+
+	message := fmt.Sprintf("The user owns %d course(s)", len(courses))
+	if len(courses) == 0 {
+		message = "The user doesn't own any courses yet; the sales agent can help them buy one"
+	}
+	return listMyCoursesResults{
+		Status:       "success",
+		Courses:      courses,
+		ActiveCourse: active,
+		Message:      message,
+	}, nil
+}
+
+// setActiveCourse makes a course the user owns the one support questions are about
+func setActiveCourse(ctx tool.Context, input setActiveCourseArgs) (setActiveCourseResults, error) {
+	slog.Info("tool called", "tool", "set_active_course", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "course_id", input.CourseID)
+
+	state := ctx.State()
+	courseID := strings.ToLower(strings.TrimSpace(input.CourseID))
+
+	var owned []string
+	for _, course := range getPurchasedCourses(state) {
+		owned = append(owned, course.ID)
+	}
+
+	if _, known := courseNames[courseID]; !known {
+		return setActiveCourseResults{
+			Status:       "error",
+			OwnedCourses: owned,
+			Message:      fmt.Sprintf("Unknown course %q. Known courses: %s", input.CourseID, strings.Join(knownCourseIDs(), ", ")),
+		}, nil
+	}
+	if _, ok := ownsCourse(state, courseID); !ok {
+		return setActiveCourseResults{
+			Status:       "not_owned",
+			OwnedCourses: owned,
+			Message:      fmt.Sprintf("The user doesn't own %s, so course support can't help with it. Suggest the sales agent to buy it.", courseName(courseID)),
+		}, nil
+	}
+
+	if err := state.Set(ACTIVE_COURSE_KEY, courseID); err != nil {
+		return setActiveCourseResults{}, fmt.Errorf("failed to set %s: %w", ACTIVE_COURSE_KEY, err)
+	}
+	return setActiveCourseResults{
+		Status:       "success",
+		ActiveCourse: courseID,
+		CourseName:   courseName(courseID),
+		OwnedCourses: owned,
+		Message:      fmt.Sprintf("Support is now scoped to %s", courseName(courseID)),
+	}, nil
+}
+
+// ===== Callbacks =====
+
+// offerOnboarding reacts to purchase_course events published by the sales agent.
+// If a course was bought recently (and not refunded since), it sets pending_onboarding to
+// the course id so the instruction tells the agent to offer onboarding, and marks the
+// event handled so onboarding is only offered once.
+func offerOnboarding(ctx agent.CallbackContext) (*genai.Content, error) {
+	state := ctx.State()
+
+	pending := ""
+	var purchase AgentEvent
+	refunded := map[string]bool{}
+	events := GetEvents(state)
+	// Walk newest first, so a refund is seen before the purchase it cancels
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		courseID := fmt.Sprintf("%v", event.Data["course_id"])
+		switch {
+		case event.Type == EVENT_REFUND_COURSE:
+			refunded[courseID] = true
+		case event.Type == EVENT_PURCHASE_COURSE && !refunded[courseID] && !event.handledBy(ctx.AgentName()) &&
+			time.Since(event.publishedAt()) <= ONBOARDING_WINDOW:
+			pending, purchase = courseID, event
+		}
+		if pending != "" {
+			break
+		}
+	}
+
+	if pending != "" {
+		if err := MarkEventHandled(state, purchase.ID, ctx.AgentName()); err != nil {
+			return nil, err
+		}
+		slog.Info("reacting to event", "event", purchase.Type, "event_id", purchase.ID, "agent", ctx.AgentName(), "course_id", pending)
+	}
+
+	// Only write when the value changes, so quiet turns don't produce state updates
+	if current, err := state.Get(PENDING_ONBOARDING_KEY); err == nil && current == pending {
+		return nil, nil
+	}
+	if err := state.Set(PENDING_ONBOARDING_KEY, pending); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", PENDING_ONBOARDING_KEY, err)
+	}
+	return nil, nil
+}
+
+// clearUnownedActiveCourse resets active_course when the user no longer owns that course,
+// e.g. after a refund, so support isn't given for a course the user gave back
+func clearUnownedActiveCourse(ctx agent.CallbackContext) (*genai.Content, error) {
+	state := ctx.State()
+	active := getActiveCourse(state)
+	if active == "" {
+		return nil, nil
+	}
+	if _, ok := ownsCourse(state, active); ok {
+		return nil, nil
+	}
+
+	if err := state.Set(ACTIVE_COURSE_KEY, ""); err != nil {
+		return nil, fmt.Errorf("failed to clear %s: %w", ACTIVE_COURSE_KEY, err)
+	}
+	slog.Info("active course cleared", "agent", ctx.AgentName(), "course_id", active, "reason", "not owned")
+	return nil, nil
+}
+
+// ===== Utility Functions =====
+
+func getActiveCourse(state session.ReadonlyState) string {
+	val, err := state.Get(ACTIVE_COURSE_KEY)
+	if err != nil {
+		return ""
+	}
+	courseID, _ := val.(string)
+	return courseID
+}
+
+func courseName(courseID string) string {
+	if name, ok := courseNames[courseID]; ok {
+		return name
+	}
+	return courseID
+}
+
+func knownCourseIDs() []string {
+	ids := make([]string, 0, len(courseNames))
+	for id := range courseNames {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // ===== Agent Creation =====
 
 // NewCourseSupportAgent creates a specialized agent for course content support
-func NewCourseSupportAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
-	// Create course support agent (no tools needed)
-	courseSupportAgent, err := llmagent.New(llmagent.Config{
+func NewCourseSupportAgent(ctx context.Context, mdl model.LLM, persona string, emitter *agentutil.EventEmitter, prices agentutil.PriceTable) (agent.Agent, error) {
+	// Create list_my_courses tool
+	listMyCoursesTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "list_my_courses",
+			Description: "Lists the courses the user owns and which one is the active course",
+		},
+		listMyCourses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_my_courses tool: %w", err)
+	}
+
+	// Create set_active_course tool; it refuses courses the user doesn't own
+	setActiveCourseTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "set_active_course",
+			Description: "Sets the course the user is asking about (by course id); only works for courses the user owns",
+		},
+		setActiveCourse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create set_active_course tool: %w", err)
+	}
+
+	// Create the shared owns_course tool
+	ownsCourseTool, err := newOwnsCourseTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create match_faq tool; it answers common questions from the embedded course FAQ
+	matchFAQTool, err := NewMatchFAQTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create course support agent; offerOnboarding listens for purchase events and
+	// clearUnownedActiveCourse drops an active course that was refunded
+	courseSupportAgent, err := agentutil.NewLLMAgent(agentutil.WithCostEstimate(agentutil.WithEventEmitter(WithPersona(llmagent.Config{
 		Name:        "course_support",
 		Model:       mdl,
 		Description: "Course support agent for the AI Marketing Platform course",
@@ -25,17 +270,36 @@ Your role is to help users with questions about course content and sections.
 Name: {user_name}
 </user_info>
 
-<purchase_info>
-Purchased Courses: {purchased_courses}
-</purchase_info>
+<pending_onboarding>
+{pending_onboarding?}
+</pending_onboarding>
+
+<active_course>
+{active_course?}
+</active_course>
+
+If pending_onboarding above contains a course id, the user just bought that course. Before anything
+else, congratulate them and offer a short onboarding: suggest starting with section 1 (Introduction)
+and section 4 (Setup Environment), and mention the 6 weeks of group support with weekly coaching calls.
 
 Before helping:
-- Check if the user owns the AI Marketing Platform course
-- Course information is stored as objects with "id" and "purchase_date" properties
-- Look for a course with id "ai_marketing_platform" in the purchased courses
-- Only provide detailed help if they own the course
+- Check if the user owns the AI Marketing Platform course: call owns_course with course_id "ai_marketing_platform"
+- Only provide detailed help if it returns owned=true
 - If they don't own the course, direct them to the sales agent
-- If they do own the course, you can mention when they purchased it (from the purchase_date property)
+- If they do own the course, you can mention when they purchased it (from its purchase_date)
+
+Active course:
+- active_course above is the course the user is currently asking about. Scope every answer to it
+- If it is empty, or the user asks about a different course, call set_active_course with that course id
+  first (for a single owned course, use that one). Use list_my_courses when you need the owned courses
+- If set_active_course returns "not_owned", don't help with that course: tell the user they don't own
+  it and suggest the sales agent
+
+Common questions:
+- For every course question, first call match_faq with the user's question as they asked it
+- If it returns "success", answer with its "answer": you may shorten it or adapt its tone, but keep
+  every fact (section numbers, names, days) exactly as given and don't add new ones
+- If it returns "no_match", answer from the course sections below as usual
 
 Course Sections:
 1. Introduction
@@ -150,7 +414,9 @@ When helping:
 2. Explain concepts clearly
 3. Provide context for how sections connect
 4. Encourage hands-on practice`,
-	})
+		Tools:                []tool.Tool{ownsCourseTool, listMyCoursesTool, setActiveCourseTool, matchFAQTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{offerOnboarding, clearUnownedActiveCourse},
+	}, persona), emitter), prices))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create course support agent: %w", err)
 	}
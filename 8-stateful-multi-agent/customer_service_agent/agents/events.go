@@ -0,0 +1,158 @@
+package agents
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// ===== Agent Event Bus =====
+//
+// Agents don't call each other directly; instead a tool publishes an AgentEvent to the
+// "events" list in session state, and interested agents check that list in a
+// BeforeAgentCallback. Because the list lives in session state it is persisted with the
+// session, so an event published by one agent is still there when another agent runs
+// later, even after a restart.
+
+const (
+	EVENTS_KEY = "events"
+	MAX_EVENTS = 50 // oldest events are dropped beyond this
+
+	EVENT_PURCHASE_COURSE = "purchase_course"
+	EVENT_REFUND_COURSE   = "refund_course"
+
+	EVENT_TIME_FORMAT = time.RFC3339
+)
+
+// AgentEvent is one entry of the "events" state list
+type AgentEvent struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`       // e.g. purchase_course
+	Source    string         `json:"source"`     // name of the agent whose tool published the event
+	Data      map[string]any `json:"data"`       // event-specific payload, e.g. course_id
+	Timestamp string         `json:"timestamp"`  // RFC 3339
+	HandledBy []string       `json:"handled_by"` // agents that already reacted to the event
+}
+
+// PublishEvent appends an event to the "events" list, filling in its ID and timestamp
+func PublishEvent(state session.State, source, eventType string, data map[string]any) (AgentEvent, error) {
+	now := time.Now()
+	event := AgentEvent{
+		ID:        fmt.Sprintf("%s-%d", eventType, now.UnixNano()),
+		Type:      eventType,
+		Source:    source,
+		Data:      data,
+		Timestamp: now.Format(EVENT_TIME_FORMAT),
+		HandledBy: []string{},
+	}
+
+	events := append(GetEvents(state), event)
+	if len(events) > MAX_EVENTS {
+		events = events[len(events)-MAX_EVENTS:]
+	}
+	if err := state.Set(EVENTS_KEY, eventsToState(events)); err != nil {
+		return AgentEvent{}, fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
+	return event, nil
+}
+
+// GetEvents reads the "events" list from state, oldest first
+func GetEvents(state session.ReadonlyState) []AgentEvent {
+	var events []AgentEvent
+	val, err := state.Get(EVENTS_KEY)
+	if err != nil {
+		return events
+	}
+
+	// Fresh values are []map[string]any; after a database round trip they are []any
+	var entries []map[string]any
+	switch v := val.(type) {
+	case []map[string]any:
+		entries = v
+	case []any:
+		for _, e := range v {
+			if eMap, ok := e.(map[string]any); ok {
+				entries = append(entries, eMap)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		event := AgentEvent{
+			ID:        fmt.Sprintf("%v", e["id"]),
+			Type:      fmt.Sprintf("%v", e["type"]),
+			Source:    fmt.Sprintf("%v", e["source"]),
+			Timestamp: fmt.Sprintf("%v", e["timestamp"]),
+			HandledBy: []string{},
+		}
+		if data, ok := e["data"].(map[string]any); ok {
+			event.Data = data
+		}
+		switch handled := e["handled_by"].(type) {
+		case []string:
+			event.HandledBy = append(event.HandledBy, handled...)
+		case []any:
+			for _, h := range handled {
+				if name, ok := h.(string); ok {
+					event.HandledBy = append(event.HandledBy, name)
+				}
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// MarkEventHandled records that an agent reacted to an event, so it won't react again
+func MarkEventHandled(state session.State, eventID, handler string) error {
+	events := GetEvents(state)
+	for i := range events {
+		if events[i].ID == eventID && !events[i].handledBy(handler) {
+			events[i].HandledBy = append(events[i].HandledBy, handler)
+			if err := state.Set(EVENTS_KEY, eventsToState(events)); err != nil {
+				return fmt.Errorf("failed to mark event %s handled: %w", eventID, err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e AgentEvent) handledBy(handler string) bool {
+	for _, name := range e.HandledBy {
+		if name == handler {
+			return true
+		}
+	}
+	return false
+}
+
+// publishedAt parses the event timestamp; a malformed timestamp counts as very old
+func (e AgentEvent) publishedAt() time.Time {
+	t, err := time.Parse(EVENT_TIME_FORMAT, e.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// eventsToState converts events to plain maps for state storage
+func eventsToState(events []AgentEvent) []map[string]any {
+	entries := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		data := event.Data
+		if data == nil {
+			data = map[string]any{}
+		}
+		entries = append(entries, map[string]any{
+			"id":         event.ID,
+			"type":       event.Type,
+			"source":     event.Source,
+			"data":       data,
+			"timestamp":  event.Timestamp,
+			"handled_by": event.HandledBy,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,281 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// ===== Feedback =====
+//
+// After an issue is resolved the root agent asks the user for a 1-5 rating. submit_feedback
+// stores it in the feedback state list with the agent that took it, the agent that handled the
+// issue (the last handoff) and a timestamp; the database session service saves it with the
+// session. feedback_summary aggregates the ratings of all the user's sessions, read back through
+// the session service, so earlier conversations count too.
+
+const (
+	FEEDBACK_KEY     = "feedback"
+	SUBMIT_FEEDBACK  = "submit_feedback"
+	FEEDBACK_SUMMARY = "feedback_summary"
+
+	MIN_RATING           = 1
+	MAX_RATING           = 5
+	MAX_FEEDBACK         = 50  // oldest feedback is dropped beyond this
+	MAX_FEEDBACK_COMMENT = 500 // longer comments are cut off
+	MAX_RECENT_COMMENTS  = 3   // comments feedback_summary returns
+
+	FEEDBACK_TIME_FORMAT = time.RFC3339
+)
+
+// Feedback is one entry of the feedback state list
+type Feedback struct {
+	Rating    int    `json:"rating"` // MIN_RATING to MAX_RATING
+	Comment   string `json:"comment,omitempty"`
+	Agent     string `json:"agent"`                // the agent that took the feedback
+	HandledBy string `json:"handled_by,omitempty"` // the agent of the last handoff, if any
+	Timestamp string `json:"timestamp"`            // FEEDBACK_TIME_FORMAT
+}
+
+type submitFeedbackArgs struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type submitFeedbackResults struct {
+	Status   string    `json:"status"` // success, invalid_rating or error
+	Feedback *Feedback `json:"feedback,omitempty"`
+	Message  string    `json:"message"`
+}
+
+type feedbackSummaryArgs struct{}
+
+// FeedbackSummary aggregates feedback ratings
+type FeedbackSummary struct {
+	Count          int            `json:"count"`
+	AverageRating  float64        `json:"average_rating"` // 0 without feedback
+	Distribution   map[string]int `json:"distribution"`   // rating ("1" to "5") to count
+	Sessions       int            `json:"sessions"`       // sessions with feedback
+	RecentComments []Feedback     `json:"recent_comments"`
+}
+
+type feedbackSummaryResults struct {
+	Status string `json:"status"` // success or error
+	FeedbackSummary
+	Message string `json:"message"`
+}
+
+// ===== Tool Implementations =====
+
+// submitFeedbackTool stores a rating in feedback
+func submitFeedbackTool(ctx tool.Context, input submitFeedbackArgs) (submitFeedbackResults, error) {
+	slog.Info("tool called", "tool", SUBMIT_FEEDBACK, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "rating", input.Rating)
+	return submitFeedback(ctx.State(), ctx.AgentName(), input, time.Now()), nil
+}
+
+func submitFeedback(state session.State, agentName string, input submitFeedbackArgs, now time.Time) submitFeedbackResults {
+	if input.Rating < MIN_RATING || input.Rating > MAX_RATING {
+		return submitFeedbackResults{
+			Status: "invalid_rating",
+			Message: fmt.Sprintf("rating must be a whole number from %d to %d, got %d; ask the user again and don't guess",
+				MIN_RATING, MAX_RATING, input.Rating),
+		}
+	}
+
+	comment := strings.TrimSpace(input.Comment)
+	if runes := []rune(comment); len(runes) > MAX_FEEDBACK_COMMENT {
+		comment = string(runes[:MAX_FEEDBACK_COMMENT])
+	}
+	feedback := Feedback{
+		Rating:    input.Rating,
+		Comment:   comment,
+		Agent:     agentName,
+		HandledBy: lastHandoffAgent(state),
+		Timestamp: now.Format(FEEDBACK_TIME_FORMAT),
+	}
+
+	entries := append(getFeedback(state), feedback)
+	if len(entries) > MAX_FEEDBACK {
+		entries = entries[len(entries)-MAX_FEEDBACK:]
+	}
+	if err := state.Set(FEEDBACK_KEY, feedbackToState(entries)); err != nil {
+		return submitFeedbackResults{Status: "error", Message: fmt.Sprintf("failed to save the feedback: %v", err)}
+	}
+	return submitFeedbackResults{
+		Status:   "success",
+		Feedback: &feedback,
+		Message:  fmt.Sprintf("Saved a %d/%d rating", feedback.Rating, MAX_RATING),
+	}
+}
+
+// summarizeFeedback aggregates the feedback lists of several sessions. Comments are returned
+// newest first.
+func summarizeFeedback(sessions [][]Feedback) FeedbackSummary {
+	summary := FeedbackSummary{Distribution: map[string]int{}, RecentComments: []Feedback{}}
+	for rating := MIN_RATING; rating <= MAX_RATING; rating++ {
+		summary.Distribution[fmt.Sprint(rating)] = 0
+	}
+
+	var all []Feedback
+	total := 0
+	for _, entries := range sessions {
+		if len(entries) > 0 {
+			summary.Sessions++
+		}
+		for _, feedback := range entries {
+			summary.Count++
+			total += feedback.Rating
+			summary.Distribution[fmt.Sprint(feedback.Rating)]++
+			all = append(all, feedback)
+		}
+	}
+	if summary.Count > 0 {
+		summary.AverageRating = math.Round(float64(total)/float64(summary.Count)*100) / 100
+	}
+
+	// RFC 3339 timestamps in one zone sort like the times they hold
+	for i := 1; i < len(all); i++ {
+		for j := i; j > 0 && all[j].Timestamp > all[j-1].Timestamp; j-- {
+			all[j], all[j-1] = all[j-1], all[j]
+		}
+	}
+	for _, feedback := range all {
+		if len(summary.RecentComments) == MAX_RECENT_COMMENTS {
+			break
+		}
+		if feedback.Comment != "" {
+			summary.RecentComments = append(summary.RecentComments, feedback)
+		}
+	}
+	return summary
+}
+
+// NewSubmitFeedbackTool creates the submit_feedback tool for the root agent
+func NewSubmitFeedbackTool() (tool.Tool, error) {
+	submitFeedbackTool, err := functiontool.New(
+		functiontool.Config{
+			Name: SUBMIT_FEEDBACK,
+			Description: fmt.Sprintf("Saves the user's satisfaction rating (a whole number from %d to %d) and an optional comment. "+
+				"Only call it with a rating the user gave", MIN_RATING, MAX_RATING),
+		},
+		submitFeedbackTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", SUBMIT_FEEDBACK, err)
+	}
+	return submitFeedbackTool, nil
+}
+
+// NewFeedbackSummaryTool creates the feedback_summary tool for the root agent. Tools can't reach
+// the session service through their context, so the service is passed in here (use the same
+// one given to the launcher).
+func NewFeedbackSummaryTool(sessions session.Service) (tool.Tool, error) {
+	feedbackSummary := func(ctx tool.Context, input feedbackSummaryArgs) (feedbackSummaryResults, error) {
+		slog.Info("tool called", "tool", FEEDBACK_SUMMARY, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+
+		resp, err := sessions.List(ctx, &session.ListRequest{AppName: ctx.AppName(), UserID: ctx.UserID()})
+		if err != nil {
+			return feedbackSummaryResults{}, fmt.Errorf("failed to list sessions of user %s: %w", ctx.UserID(), err)
+		}
+		// The stored copy of this session may not have this turn's feedback yet, so it is read
+		// from the tool's state instead
+		lists := [][]Feedback{getFeedback(ctx.State())}
+		for _, sess := range resp.Sessions {
+			if sess.ID() != ctx.SessionID() {
+				lists = append(lists, getFeedback(sess.State()))
+			}
+		}
+
+		summary := summarizeFeedback(lists)
+		message := "No feedback yet"
+		if summary.Count > 0 {
+			message = fmt.Sprintf("Average rating %.2f/%d from %d rating(s) in %d session(s)", summary.AverageRating, MAX_RATING, summary.Count, summary.Sessions)
+		}
+		return feedbackSummaryResults{Status: "success", FeedbackSummary: summary, Message: message}, nil
+	}
+
+	feedbackSummaryTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        FEEDBACK_SUMMARY,
+			Description: "Summarizes the user's feedback ratings across all their conversations: count, average rating, distribution and recent comments",
+		},
+		feedbackSummary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", FEEDBACK_SUMMARY, err)
+	}
+	return feedbackSummaryTool, nil
+}
+
+// ===== Utility Functions =====
+
+// lastHandoffAgent returns the agent of the last recorded handoff, or ""
+func lastHandoffAgent(state session.ReadonlyState) string {
+	val, err := state.Get(toolutil.LAST_HANDOFF_KEY)
+	if err != nil {
+		return ""
+	}
+	switch handoff := val.(type) {
+	case toolutil.Handoff:
+		return handoff.To
+	case map[string]any:
+		to, _ := handoff["to"].(string)
+		return to
+	}
+	return ""
+}
+
+// getFeedback reads feedback, oldest first; fresh values are []map[string]any, after a database
+// round trip they are []any with float64 ratings. Entries with a rating outside 1-5 are skipped.
+func getFeedback(state session.ReadonlyState) []Feedback {
+	entries := []Feedback{}
+	val, err := state.Get(FEEDBACK_KEY)
+	if err != nil {
+		return entries
+	}
+	list, _ := toList(val)
+
+	for i, entry := range list {
+		m, _ := entry.(map[string]any)
+		var rating int
+		switch r := m["rating"].(type) {
+		case int:
+			rating = r
+		case float64:
+			if r == math.Trunc(r) {
+				rating = int(r)
+			}
+		}
+		if rating < MIN_RATING || rating > MAX_RATING {
+			slog.Warn("skipping malformed feedback", "index", i, "entry", fmt.Sprintf("%v", entry))
+			continue
+		}
+		comment, _ := m["comment"].(string)
+		agentName, _ := m["agent"].(string)
+		handledBy, _ := m["handled_by"].(string)
+		timestamp, _ := m["timestamp"].(string)
+		entries = append(entries, Feedback{Rating: rating, Comment: comment, Agent: agentName, HandledBy: handledBy, Timestamp: timestamp})
+	}
+	return entries
+}
+
+// feedbackToState converts feedback to the stored feedback form
+func feedbackToState(entries []Feedback) []map[string]any {
+	list := make([]map[string]any, 0, len(entries))
+	for _, feedback := range entries {
+		list = append(list, map[string]any{
+			"rating":     feedback.Rating,
+			"comment":    feedback.Comment,
+			"agent":      feedback.Agent,
+			"handled_by": feedback.HandledBy,
+			"timestamp":  feedback.Timestamp,
+		})
+	}
+	return list
+}
@@ -0,0 +1,96 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+func TestSubmitFeedback(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+	state := agenttest.NewState(map[string]any{toolutil.LAST_HANDOFF_KEY: map[string]any{"from": "customer_service", "to": "order_agent"}}, true)
+
+	results := submitFeedback(state, "customer_service", submitFeedbackArgs{Rating: 5, Comment: "  Quick refund, thanks!  "}, now)
+	if results.Status != "success" || results.Feedback == nil {
+		t.Fatalf("submitFeedback() = %+v, want success", results)
+	}
+	want := Feedback{Rating: 5, Comment: "Quick refund, thanks!", Agent: "customer_service", HandledBy: "order_agent", Timestamp: "2024-12-10T15:00:00Z"}
+	if *results.Feedback != want {
+		t.Errorf("feedback = %+v, want %+v", *results.Feedback, want)
+	}
+
+	for _, rating := range []int{0, -1, 6, 10} {
+		results := submitFeedback(state, "customer_service", submitFeedbackArgs{Rating: rating}, now)
+		if results.Status != "invalid_rating" || results.Feedback != nil || results.Message == "" {
+			t.Errorf("submitFeedback(rating %d) = %+v, want invalid_rating", rating, results)
+		}
+	}
+	if got := getFeedback(state); len(got) != 1 || got[0] != want {
+		t.Errorf("feedback = %+v, want only the valid rating", got)
+	}
+}
+
+func TestGetFeedbackAfterDatabaseRoundTrip(t *testing.T) {
+	state := agenttest.NewState(nil, true)
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+	submitFeedback(state, "customer_service", submitFeedbackArgs{Rating: 4}, now)
+
+	// The database returns []any with float64 ratings; malformed entries are skipped
+	stored := agenttest.NewState(map[string]any{FEEDBACK_KEY: append(feedbackToState(getFeedback(state)),
+		map[string]any{"rating": 9}, map[string]any{"rating": 2.5}, map[string]any{"comment": "no rating"})}, true)
+	got := getFeedback(stored)
+	if len(got) != 1 || got[0].Rating != 4 || got[0].Agent != "customer_service" {
+		t.Errorf("getFeedback() = %+v, want the one 4 rating", got)
+	}
+
+	if results := submitFeedback(stored, "customer_service", submitFeedbackArgs{Rating: 3}, now); results.Status != "success" {
+		t.Fatalf("submitFeedback() = %+v, want success", results)
+	}
+	if got := getFeedback(stored); len(got) != 2 || got[1].Rating != 3 {
+		t.Errorf("feedback = %+v, want the new rating appended", got)
+	}
+}
+
+func TestSubmitFeedbackKeepsNewest(t *testing.T) {
+	state := agenttest.NewState(nil, true)
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+	for i := 0; i < MAX_FEEDBACK+5; i++ {
+		submitFeedback(state, "customer_service", submitFeedbackArgs{Rating: i%MAX_RATING + 1}, now.Add(time.Duration(i)*time.Minute))
+	}
+	got := getFeedback(state)
+	if len(got) != MAX_FEEDBACK || got[len(got)-1].Timestamp != now.Add((MAX_FEEDBACK+4)*time.Minute).Format(FEEDBACK_TIME_FORMAT) {
+		t.Errorf("kept %d feedback ending %+v, want the newest %d", len(got), got[len(got)-1], MAX_FEEDBACK)
+	}
+}
+
+func TestSummarizeFeedback(t *testing.T) {
+	summary := summarizeFeedback([][]Feedback{
+		{
+			{Rating: 5, Comment: "Great", Timestamp: "2024-12-01T10:00:00Z"},
+			{Rating: 4, Timestamp: "2024-12-03T10:00:00Z"},
+		},
+		{},
+		{
+			{Rating: 2, Comment: "Slow", Timestamp: "2024-12-05T10:00:00Z"},
+		},
+	})
+	if summary.Count != 3 || summary.Sessions != 2 || summary.AverageRating != 3.67 {
+		t.Errorf("summary = %+v, want 3 ratings in 2 sessions averaging 3.67", summary)
+	}
+	wantDistribution := map[string]int{"1": 0, "2": 1, "3": 0, "4": 1, "5": 1}
+	for rating, count := range wantDistribution {
+		if summary.Distribution[rating] != count {
+			t.Errorf("distribution = %v, want %v", summary.Distribution, wantDistribution)
+			break
+		}
+	}
+	if len(summary.RecentComments) != 2 || summary.RecentComments[0].Comment != "Slow" || summary.RecentComments[1].Comment != "Great" {
+		t.Errorf("recent comments = %+v, want Slow then Great", summary.RecentComments)
+	}
+
+	if empty := summarizeFeedback(nil); empty.Count != 0 || empty.AverageRating != 0 || len(empty.Distribution) != MAX_RATING {
+		t.Errorf("empty summary = %+v, want no ratings", empty)
+	}
+}
@@ -0,0 +1,390 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== Follow-ups =====
+//
+// follow_ups is a list of reminders to bring up with the user later, e.g. re-enrolling a week
+// after a refund. The order agent schedules one with schedule_follow_up after a successful
+// refund; due_follow_ups returns the ones whose due date has come and marks them delivered, so
+// each is mentioned once. list_follow_ups and cancel_follow_up let the user see and remove them.
+// Follow-ups are addressed by their id, which doesn't change when others are added or removed.
+// Due dates are calendar dates in local time.
+
+const (
+	FOLLOW_UPS_KEY     = "follow_ups"
+	SCHEDULE_FOLLOW_UP = "schedule_follow_up"
+	DUE_FOLLOW_UPS     = "due_follow_ups"
+	LIST_FOLLOW_UPS    = "list_follow_ups"
+	CANCEL_FOLLOW_UP   = "cancel_follow_up"
+
+	FOLLOW_UP_DATE_FORMAT = "2006-01-02"
+	MAX_FOLLOW_UPS        = 20 // oldest follow-ups are dropped beyond this
+)
+
+// FollowUp is one entry of the follow_ups state list
+type FollowUp struct {
+	ID        string `json:"id"`
+	Message   string `json:"message"`
+	DueDate   string `json:"due_date"`   // FOLLOW_UP_DATE_FORMAT
+	CreatedAt string `json:"created_at"` // PURCHASE_DATE_FORMAT
+	Delivered bool   `json:"delivered"`
+}
+
+type scheduleFollowUpArgs struct {
+	Message string `json:"message"`
+	// Either a date (YYYY-MM-DD) or a number of days from today
+	DueDate     string `json:"due_date,omitempty"`
+	DaysFromNow int    `json:"days_from_now,omitempty"`
+}
+
+type scheduleFollowUpResults struct {
+	Status   string    `json:"status"` // success or error
+	FollowUp *FollowUp `json:"follow_up,omitempty"`
+	Message  string    `json:"message"`
+}
+
+type dueFollowUpsArgs struct{}
+
+type dueFollowUpsResults struct {
+	Status    string     `json:"status"`
+	FollowUps []FollowUp `json:"follow_ups"`
+	Upcoming  int        `json:"upcoming"` // follow-ups not due yet
+	Message   string     `json:"message"`
+}
+
+type listFollowUpsArgs struct {
+	IncludeDelivered bool `json:"include_delivered,omitempty"`
+}
+
+type listFollowUpsResults struct {
+	Status    string     `json:"status"`
+	FollowUps []FollowUp `json:"follow_ups"` // by due date
+	Message   string     `json:"message"`
+}
+
+type cancelFollowUpArgs struct {
+	ID string `json:"id"`
+}
+
+type cancelFollowUpResults struct {
+	Status    string    `json:"status"` // success, not_found or error
+	Cancelled *FollowUp `json:"cancelled,omitempty"`
+	Remaining int       `json:"remaining"` // pending follow-ups left
+	Message   string    `json:"message"`
+}
+
+// ===== Tool Implementations =====
+
+// scheduleFollowUpTool adds a follow-up to follow_ups
+func scheduleFollowUpTool(ctx tool.Context, input scheduleFollowUpArgs) (scheduleFollowUpResults, error) {
+	slog.Info("tool called", "tool", SCHEDULE_FOLLOW_UP, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "due_date", input.DueDate, "days_from_now", input.DaysFromNow)
+	return scheduleFollowUp(ctx.State(), input, time.Now()), nil
+}
+
+func scheduleFollowUp(state session.State, input scheduleFollowUpArgs, now time.Time) scheduleFollowUpResults {
+	message := strings.TrimSpace(input.Message)
+	if message == "" {
+		return scheduleFollowUpResults{Status: "error", Message: "message is required: what to remind the user about"}
+	}
+	due, err := parseFollowUpDate(input.DueDate, input.DaysFromNow, now)
+	if err != nil {
+		return scheduleFollowUpResults{Status: "error", Message: err.Error()}
+	}
+
+	followUp := FollowUp{
+		ID:        fmt.Sprintf("follow_up-%d", now.UnixNano()),
+		Message:   message,
+		DueDate:   due.Format(FOLLOW_UP_DATE_FORMAT),
+		CreatedAt: now.Format(PURCHASE_DATE_FORMAT),
+	}
+	followUps := append(getFollowUps(state), followUp)
+	if len(followUps) > MAX_FOLLOW_UPS {
+		followUps = followUps[len(followUps)-MAX_FOLLOW_UPS:]
+	}
+	if err := state.Set(FOLLOW_UPS_KEY, followUpsToState(followUps)); err != nil {
+		return scheduleFollowUpResults{Status: "error", Message: fmt.Sprintf("failed to save the follow-up: %v", err)}
+	}
+	return scheduleFollowUpResults{
+		Status:   "success",
+		FollowUp: &followUp,
+		Message:  fmt.Sprintf("Follow-up scheduled for %s", followUp.DueDate),
+	}
+}
+
+// dueFollowUpsTool returns the follow-ups that are due and marks them delivered
+func dueFollowUpsTool(ctx tool.Context, input dueFollowUpsArgs) (dueFollowUpsResults, error) {
+	slog.Info("tool called", "tool", DUE_FOLLOW_UPS, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+	return dueFollowUps(ctx.State(), time.Now()), nil
+}
+
+func dueFollowUps(state session.State, now time.Time) dueFollowUpsResults {
+	followUps := getFollowUps(state)
+	today := now.Format(FOLLOW_UP_DATE_FORMAT)
+
+	results := dueFollowUpsResults{Status: "success", FollowUps: []FollowUp{}}
+	for i, followUp := range followUps {
+		switch {
+		case followUp.Delivered:
+		case followUp.DueDate <= today: // FOLLOW_UP_DATE_FORMAT sorts like the dates it holds
+			results.FollowUps = append(results.FollowUps, followUp)
+			followUps[i].Delivered = true
+		default:
+			results.Upcoming++
+		}
+	}
+
+	if len(results.FollowUps) == 0 {
+		results.Message = "No follow-ups are due"
+		if results.Upcoming > 0 {
+			results.Message = fmt.Sprintf("No follow-ups are due; %d scheduled for later", results.Upcoming)
+		}
+		return results
+	}
+	if err := state.Set(FOLLOW_UPS_KEY, followUpsToState(followUps)); err != nil {
+		return dueFollowUpsResults{Status: "error", FollowUps: []FollowUp{}, Message: fmt.Sprintf("failed to mark the follow-ups delivered: %v", err)}
+	}
+	results.Message = fmt.Sprintf("%d follow-up(s) due", len(results.FollowUps))
+	return results
+}
+
+// listFollowUpsTool lists the pending follow-ups, and the delivered ones when asked
+func listFollowUpsTool(ctx tool.Context, input listFollowUpsArgs) (listFollowUpsResults, error) {
+	slog.Info("tool called", "tool", LIST_FOLLOW_UPS, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "include_delivered", input.IncludeDelivered)
+	return listFollowUps(ctx.State(), input.IncludeDelivered), nil
+}
+
+func listFollowUps(state session.ReadonlyState, includeDelivered bool) listFollowUpsResults {
+	followUps := []FollowUp{}
+	for _, followUp := range getFollowUps(state) {
+		if includeDelivered || !followUp.Delivered {
+			followUps = append(followUps, followUp)
+		}
+	}
+	sort.SliceStable(followUps, func(i, j int) bool { return followUps[i].DueDate < followUps[j].DueDate })
+
+	message := fmt.Sprintf("%d pending follow-up(s)", len(followUps))
+	if includeDelivered {
+		message = fmt.Sprintf("%d follow-up(s), including delivered ones", len(followUps))
+	}
+	if len(followUps) == 0 {
+		message = "No pending follow-ups"
+		if includeDelivered {
+			message = "No follow-ups"
+		}
+	}
+	return listFollowUpsResults{Status: "success", FollowUps: followUps, Message: message}
+}
+
+// cancelFollowUpTool removes a follow-up by id
+func cancelFollowUpTool(ctx tool.Context, input cancelFollowUpArgs) (cancelFollowUpResults, error) {
+	slog.Info("tool called", "tool", CANCEL_FOLLOW_UP, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "id", input.ID)
+	return cancelFollowUp(ctx.State(), input.ID), nil
+}
+
+func cancelFollowUp(state session.State, rawID string) cancelFollowUpResults {
+	id := strings.TrimSpace(rawID)
+	followUps := getFollowUps(state)
+	pending := countPending(followUps)
+	if id == "" {
+		return cancelFollowUpResults{Status: "error", Remaining: pending, Message: "id is required: call list_follow_ups to get the follow-up ids"}
+	}
+
+	remaining := make([]FollowUp, 0, len(followUps))
+	var cancelled *FollowUp
+	for _, followUp := range followUps {
+		if cancelled == nil && followUp.ID == id {
+			cancelled = &followUp
+			continue
+		}
+		remaining = append(remaining, followUp)
+	}
+	if cancelled == nil {
+		message := fmt.Sprintf("No follow-up with id %q. There are %d pending follow-up(s); call list_follow_ups for their ids", id, pending)
+		if pending == 0 {
+			message = fmt.Sprintf("No follow-up with id %q. There are no pending follow-ups", id)
+		}
+		return cancelFollowUpResults{Status: "not_found", Remaining: pending, Message: message}
+	}
+
+	if err := state.Set(FOLLOW_UPS_KEY, followUpsToState(remaining)); err != nil {
+		return cancelFollowUpResults{Status: "error", Remaining: pending, Message: fmt.Sprintf("failed to cancel the follow-up: %v", err)}
+	}
+	return cancelFollowUpResults{
+		Status:    "success",
+		Cancelled: cancelled,
+		Remaining: countPending(remaining),
+		Message:   fmt.Sprintf("Cancelled the follow-up due %s: %s", cancelled.DueDate, cancelled.Message),
+	}
+}
+
+// newScheduleFollowUpTool creates the schedule_follow_up tool for the order agent
+func newScheduleFollowUpTool() (tool.Tool, error) {
+	scheduleFollowUpTool, err := functiontool.New(
+		functiontool.Config{
+			Name: SCHEDULE_FOLLOW_UP,
+			Description: "Schedules a follow-up reminder for the user: a message and either a due_date (YYYY-MM-DD) " +
+				"or days_from_now. Due follow-ups are returned by due_follow_ups",
+		},
+		scheduleFollowUpTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", SCHEDULE_FOLLOW_UP, err)
+	}
+	return scheduleFollowUpTool, nil
+}
+
+// NewDueFollowUpsTool creates the due_follow_ups tool shared by the root and order agents
+func NewDueFollowUpsTool() (tool.Tool, error) {
+	dueFollowUpsTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        DUE_FOLLOW_UPS,
+			Description: "Returns the follow-up reminders that are due today or earlier and marks them delivered, so each is returned once",
+		},
+		dueFollowUpsTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", DUE_FOLLOW_UPS, err)
+	}
+	return dueFollowUpsTool, nil
+}
+
+// newListFollowUpsTool creates the list_follow_ups tool for the order agent
+func newListFollowUpsTool() (tool.Tool, error) {
+	listFollowUpsTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        LIST_FOLLOW_UPS,
+			Description: "Lists the user's pending follow-up reminders (id, message, due date) by due date; include_delivered also lists those already delivered",
+		},
+		listFollowUpsTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", LIST_FOLLOW_UPS, err)
+	}
+	return listFollowUpsTool, nil
+}
+
+// newCancelFollowUpTool creates the cancel_follow_up tool for the order agent
+func newCancelFollowUpTool() (tool.Tool, error) {
+	cancelFollowUpTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        CANCEL_FOLLOW_UP,
+			Description: "Cancels (removes) a follow-up reminder by the id list_follow_ups returns",
+		},
+		cancelFollowUpTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", CANCEL_FOLLOW_UP, err)
+	}
+	return cancelFollowUpTool, nil
+}
+
+// ===== Utility Functions =====
+
+// countPending returns how many follow-ups haven't been delivered
+func countPending(followUps []FollowUp) int {
+	pending := 0
+	for _, followUp := range followUps {
+		if !followUp.Delivered {
+			pending++
+		}
+	}
+	return pending
+}
+
+// parseFollowUpDate returns the due date from dueDate, or from daysFromNow when dueDate is empty.
+// dueDate may also be a purchase_date style timestamp or RFC 3339; the time of day is ignored.
+// Dates before today are rejected.
+func parseFollowUpDate(dueDate string, daysFromNow int, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dueDate = strings.TrimSpace(dueDate)
+
+	var due time.Time
+	switch {
+	case dueDate != "" && daysFromNow != 0:
+		return time.Time{}, fmt.Errorf("give either due_date or days_from_now, not both")
+	case dueDate != "":
+		parsed, err := parseDate(dueDate, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid due_date %q: use YYYY-MM-DD, e.g. %s", dueDate, today.AddDate(0, 0, 7).Format(FOLLOW_UP_DATE_FORMAT))
+		}
+		due = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	case daysFromNow < 0:
+		return time.Time{}, fmt.Errorf("days_from_now must not be negative, got %d", daysFromNow)
+	case daysFromNow > 0:
+		due = today.AddDate(0, 0, daysFromNow)
+	default:
+		return time.Time{}, fmt.Errorf("due_date (YYYY-MM-DD) or days_from_now is required")
+	}
+
+	if due.Before(today) {
+		return time.Time{}, fmt.Errorf("due_date %s is in the past; today is %s", due.Format(FOLLOW_UP_DATE_FORMAT), today.Format(FOLLOW_UP_DATE_FORMAT))
+	}
+	return due, nil
+}
+
+// parseDate accepts FOLLOW_UP_DATE_FORMAT, PURCHASE_DATE_FORMAT and RFC 3339
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{FOLLOW_UP_DATE_FORMAT, PURCHASE_DATE_FORMAT} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// getFollowUps reads follow_ups, oldest first; fresh values are []map[string]any, after a
+// database round trip they are []any. Entries without a message or a valid due date are skipped,
+// and entries without an id get one from their position.
+func getFollowUps(state session.ReadonlyState) []FollowUp {
+	followUps := []FollowUp{}
+	val, err := state.Get(FOLLOW_UPS_KEY)
+	if err != nil {
+		return followUps
+	}
+	entries, _ := toList(val)
+
+	for i, entry := range entries {
+		m, _ := entry.(map[string]any)
+		message, _ := m["message"].(string)
+		dueDate, _ := m["due_date"].(string)
+		if _, err := time.Parse(FOLLOW_UP_DATE_FORMAT, dueDate); err != nil || strings.TrimSpace(message) == "" {
+			slog.Warn("skipping malformed follow-up", "index", i, "entry", fmt.Sprintf("%v", entry))
+			continue
+		}
+		id, _ := m["id"].(string)
+		if strings.TrimSpace(id) == "" {
+			// e.g. added by hand; the id is saved with the next change to the list
+			id = fmt.Sprintf("follow_up-%s-%d", dueDate, i)
+		}
+		createdAt, _ := m["created_at"].(string)
+		delivered, _ := m["delivered"].(bool)
+		followUps = append(followUps, FollowUp{ID: id, Message: message, DueDate: dueDate, CreatedAt: createdAt, Delivered: delivered})
+	}
+	return followUps
+}
+
+// followUpsToState converts follow-ups to the stored follow_ups form
+func followUpsToState(followUps []FollowUp) []map[string]any {
+	list := make([]map[string]any, 0, len(followUps))
+	for _, followUp := range followUps {
+		list = append(list, map[string]any{
+			"id":         followUp.ID,
+			"message":    followUp.Message,
+			"due_date":   followUp.DueDate,
+			"created_at": followUp.CreatedAt,
+			"delivered":  followUp.Delivered,
+		})
+	}
+	return list
+}
@@ -0,0 +1,172 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestParseFollowUpDate(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		dueDate     string
+		daysFromNow int
+		want        string // "" for an error
+	}{
+		{"2024-12-17", 0, "2024-12-17"},
+		{" 2024-12-10 ", 0, "2024-12-10"}, // today is allowed
+		{"2024-12-17 09:30:00", 0, "2024-12-17"},
+		{"2024-12-17T09:30:00Z", 0, "2024-12-17"},
+		{"", 7, "2024-12-17"},
+		{"", 30, "2025-01-09"},
+		{"2024-12-09", 0, ""}, // in the past
+		{"next week", 0, ""},
+		{"12/17/2024", 0, ""},
+		{"2024-12-17", 7, ""}, // both
+		{"", -1, ""},
+		{"", 0, ""}, // neither
+	}
+	for _, tt := range tests {
+		due, err := parseFollowUpDate(tt.dueDate, tt.daysFromNow, now)
+		got := ""
+		if err == nil {
+			got = due.Format(FOLLOW_UP_DATE_FORMAT)
+		}
+		if got != tt.want {
+			t.Errorf("parseFollowUpDate(%q, %d) = %q, %v; want %q", tt.dueDate, tt.daysFromNow, got, err, tt.want)
+		}
+	}
+}
+
+func TestScheduleFollowUp(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.Local)
+	state := agenttest.NewState(nil, true)
+
+	results := scheduleFollowUp(state, scheduleFollowUpArgs{Message: "Re-enroll in the AI Marketing Platform?", DaysFromNow: 7}, now)
+	if results.Status != "success" || results.FollowUp == nil || results.FollowUp.DueDate != "2024-12-17" {
+		t.Fatalf("scheduleFollowUp() = %+v, want a follow-up due 2024-12-17", results)
+	}
+
+	for _, input := range []scheduleFollowUpArgs{
+		{Message: " ", DaysFromNow: 7},
+		{Message: "Re-enroll?", DueDate: "someday"},
+	} {
+		if results := scheduleFollowUp(state, input, now); results.Status != "error" || results.Message == "" {
+			t.Errorf("scheduleFollowUp(%+v) = %+v, want an error", input, results)
+		}
+	}
+	if got := getFollowUps(state); len(got) != 1 {
+		t.Errorf("follow-ups = %+v, want only the valid one", got)
+	}
+}
+
+func TestDueFollowUps(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.Local)
+
+	// An empty or missing list has nothing due
+	if results := dueFollowUps(agenttest.NewState(nil, true), now); results.Status != "success" || len(results.FollowUps) != 0 || results.Message != "No follow-ups are due" {
+		t.Errorf("dueFollowUps(empty) = %+v, want nothing due", results)
+	}
+
+	// Stored as the tools store it, then read back after a database round trip
+	state := agenttest.NewState(map[string]any{
+		FOLLOW_UPS_KEY: followUpsToState([]FollowUp{
+			{ID: "1", Message: "Re-enroll?", DueDate: "2024-12-03"},
+			{ID: "2", Message: "Due today", DueDate: "2024-12-10"},
+			{ID: "3", Message: "Next week", DueDate: "2024-12-17"},
+			{ID: "4", Message: "Already told", DueDate: "2024-12-01", Delivered: true},
+		}),
+	}, true)
+	state.Set(FOLLOW_UPS_KEY, append(state.Value(FOLLOW_UPS_KEY).([]any), map[string]any{"message": "no date"}, "not an object"))
+
+	results := dueFollowUps(state, now)
+	if len(results.FollowUps) != 2 || results.FollowUps[0].ID != "1" || results.FollowUps[1].ID != "2" || results.Upcoming != 1 {
+		t.Fatalf("dueFollowUps() = %+v, want follow-ups 1 and 2 with 1 upcoming", results)
+	}
+
+	// Each follow-up is returned once
+	results = dueFollowUps(state, now)
+	if len(results.FollowUps) != 0 || !strings.Contains(results.Message, "1 scheduled for later") {
+		t.Errorf("second dueFollowUps() = %+v, want nothing due and 1 upcoming", results)
+	}
+	results = dueFollowUps(state, now.AddDate(0, 0, 7))
+	if len(results.FollowUps) != 1 || results.FollowUps[0].ID != "3" {
+		t.Errorf("dueFollowUps() a week later = %+v, want follow-up 3", results)
+	}
+}
+
+func TestFollowUpsAreCapped(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.Local)
+	state := agenttest.NewState(nil, true)
+	for i := 0; i < MAX_FOLLOW_UPS+3; i++ {
+		scheduleFollowUp(state, scheduleFollowUpArgs{Message: "Re-enroll?", DaysFromNow: i + 1}, now)
+	}
+	followUps := getFollowUps(state)
+	if len(followUps) != MAX_FOLLOW_UPS || followUps[0].DueDate != "2024-12-14" {
+		t.Errorf("got %d follow-ups starting %s, want the newest %d", len(followUps), followUps[0].DueDate, MAX_FOLLOW_UPS)
+	}
+}
+
+func TestListFollowUps(t *testing.T) {
+	if results := listFollowUps(agenttest.NewState(nil, true), false); results.Status != "success" || len(results.FollowUps) != 0 || results.Message != "No pending follow-ups" {
+		t.Errorf("listFollowUps(empty) = %+v, want no follow-ups", results)
+	}
+
+	state := agenttest.NewState(map[string]any{
+		FOLLOW_UPS_KEY: followUpsToState([]FollowUp{
+			{ID: "late", Message: "Next month", DueDate: "2025-01-10"},
+			{ID: "told", Message: "Already told", DueDate: "2024-12-01", Delivered: true},
+			{ID: "soon", Message: "Re-enroll?", DueDate: "2024-12-17"},
+		}),
+	}, true)
+	results := listFollowUps(state, false)
+	if len(results.FollowUps) != 2 || results.FollowUps[0].ID != "soon" || results.FollowUps[1].ID != "late" {
+		t.Errorf("listFollowUps() = %+v, want the pending ones by due date", results)
+	}
+	if results := listFollowUps(state, true); len(results.FollowUps) != 3 || results.FollowUps[0].ID != "told" {
+		t.Errorf("listFollowUps(include delivered) = %+v, want all three by due date", results)
+	}
+}
+
+func TestCancelFollowUp(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.Local)
+	state := agenttest.NewState(nil, true)
+	first := scheduleFollowUp(state, scheduleFollowUpArgs{Message: "Re-enroll?", DaysFromNow: 7}, now).FollowUp
+	second := scheduleFollowUp(state, scheduleFollowUpArgs{Message: "Try the new module", DaysFromNow: 14}, now.Add(time.Second)).FollowUp
+
+	// Ids stay valid after a database round trip and after other follow-ups are removed
+	state = agenttest.NewState(map[string]any{FOLLOW_UPS_KEY: state.Value(FOLLOW_UPS_KEY)}, true)
+	results := cancelFollowUp(state, " "+first.ID+" ")
+	if results.Status != "success" || results.Cancelled == nil || results.Cancelled.Message != "Re-enroll?" || results.Remaining != 1 {
+		t.Fatalf("cancelFollowUp(first) = %+v, want it cancelled with 1 remaining", results)
+	}
+	if results := cancelFollowUp(state, first.ID); results.Status != "not_found" || !strings.Contains(results.Message, "1 pending") {
+		t.Errorf("cancelFollowUp(first) again = %+v, want not_found", results)
+	}
+	if results := cancelFollowUp(state, second.ID); results.Status != "success" || results.Remaining != 0 {
+		t.Errorf("cancelFollowUp(second) = %+v, want it cancelled", results)
+	}
+
+	if results := cancelFollowUp(state, "follow_up-1"); results.Status != "not_found" || !strings.Contains(results.Message, "no pending follow-ups") {
+		t.Errorf("cancelFollowUp() on an empty list = %+v, want not_found saying there are none", results)
+	}
+	if results := cancelFollowUp(state, ""); results.Status != "error" {
+		t.Errorf("cancelFollowUp(\"\") = %+v, want an error", results)
+	}
+}
+
+func TestFollowUpWithoutIDCanBeCancelled(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		FOLLOW_UPS_KEY: []any{map[string]any{"message": "Added by hand", "due_date": "2024-12-17"}},
+	}, true)
+	listed := listFollowUps(state, false)
+	if len(listed.FollowUps) != 1 || listed.FollowUps[0].ID == "" {
+		t.Fatalf("listFollowUps() = %+v, want the entry with an id", listed)
+	}
+	if results := cancelFollowUp(state, listed.FollowUps[0].ID); results.Status != "success" {
+		t.Errorf("cancelFollowUp(%s) = %+v, want it cancelled", listed.FollowUps[0].ID, results)
+	}
+}
@@ -0,0 +1,352 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== Interaction History Compaction =====
+//
+// interaction_history is injected into several instructions, so it must not grow forever.
+// Once it holds more than HISTORY_MAX_ENTRIES entries, the oldest ones are folded into
+// history_summary and only the most recent HISTORY_MAX_ENTRIES are kept. The summary is
+// built from counts rather than by the model, so the same history always gives the same
+// summary.
+
+const (
+	INTERACTION_HISTORY_KEY = "interaction_history"
+	HISTORY_SUMMARY_KEY     = "history_summary"
+	HISTORY_STATS_KEY       = "history_summary_stats" // running totals behind history_summary
+	HISTORY_MAX_ENTRIES     = 10
+)
+
+// historyStats are the running totals of every entry folded into the summary so far
+type historyStats struct {
+	Count   int
+	First   string              // timestamp of the oldest summarized entry
+	Last    string              // timestamp of the newest summarized entry
+	Actions map[string]int      // entries per action
+	Courses map[string][]string // course ids seen per action, sorted
+}
+
+// CompactInteractionHistory is a BeforeAgentCallback that summarizes the oldest
+// interaction_history entries once there are more than HISTORY_MAX_ENTRIES
+func CompactInteractionHistory(ctx agent.CallbackContext) (*genai.Content, error) {
+	state := ctx.State()
+
+	history := getInteractionHistory(state)
+	if len(history) <= HISTORY_MAX_ENTRIES {
+		return nil, nil
+	}
+
+	split := len(history) - HISTORY_MAX_ENTRIES
+	stats := summarizeHistory(getHistoryStats(state), history[:split])
+	recent := history[split:]
+
+	if err := state.Set(HISTORY_STATS_KEY, stats.toState()); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", HISTORY_STATS_KEY, err)
+	}
+	if err := state.Set(HISTORY_SUMMARY_KEY, stats.String()); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", HISTORY_SUMMARY_KEY, err)
+	}
+	if err := state.Set(INTERACTION_HISTORY_KEY, recent); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", INTERACTION_HISTORY_KEY, err)
+	}
+
+	slog.Info("interaction history compacted", "agent", ctx.AgentName(), "summarized", split, "kept", len(recent), "total_summarized", stats.Count)
+	return nil, nil
+}
+
+// summarizeHistory adds entries to the running totals
+func summarizeHistory(stats historyStats, entries []map[string]any) historyStats {
+	if stats.Actions == nil {
+		stats.Actions = map[string]int{}
+	}
+	if stats.Courses == nil {
+		stats.Courses = map[string][]string{}
+	}
+
+	for _, entry := range entries {
+		stats.Count++
+
+		action := "unknown"
+		if a, ok := entry["action"].(string); ok && a != "" {
+			action = a
+		}
+		stats.Actions[action]++
+
+		if courseID, ok := entry["course_id"].(string); ok && courseID != "" && !containsString(stats.Courses[action], courseID) {
+			stats.Courses[action] = append(stats.Courses[action], courseID)
+			sort.Strings(stats.Courses[action])
+		}
+
+		if ts, ok := entry["timestamp"].(string); ok && ts != "" {
+			if stats.First == "" {
+				stats.First = ts
+			}
+			stats.Last = ts
+		}
+	}
+	return stats
+}
+
+// String renders the summary injected as {history_summary}, e.g.
+// "12 earlier interactions (2024-12-03 15:29:00 to 2024-12-05 10:00:00): 2x purchase_course (ai_marketing_platform), 1x refund_course (ai_marketing_platform), 9x user_query"
+func (s historyStats) String() string {
+	if s.Count == 0 {
+		return ""
+	}
+
+	actions := make([]string, 0, len(s.Actions))
+	for action := range s.Actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	parts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		part := fmt.Sprintf("%dx %s", s.Actions[action], action)
+		if courses := s.Courses[action]; len(courses) > 0 {
+			part += " (" + strings.Join(courses, ", ") + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	period := ""
+	if s.First != "" {
+		period = fmt.Sprintf(" (%s to %s)", s.First, s.Last)
+	}
+	return fmt.Sprintf("%d earlier interactions%s: %s", s.Count, period, strings.Join(parts, ", "))
+}
+
+func (s historyStats) toState() map[string]any {
+	actions := make(map[string]any, len(s.Actions))
+	for action, count := range s.Actions {
+		actions[action] = count
+	}
+	courses := make(map[string]any, len(s.Courses))
+	for action, ids := range s.Courses {
+		courses[action] = ids
+	}
+	return map[string]any{
+		"count":   s.Count,
+		"first":   s.First,
+		"last":    s.Last,
+		"actions": actions,
+		"courses": courses,
+	}
+}
+
+// ===== History Lookup =====
+//
+// get_history returns interaction_history entries newest first, optionally only one action
+// (e.g. refund_course), so "show my refunds" doesn't depend on the model reading the whole list.
+// Entries already folded into history_summary are only counted, in summarized_matches.
+
+const (
+	DEFAULT_HISTORY_LIMIT = 10
+	MAX_HISTORY_LIMIT     = 50
+)
+
+type getHistoryArgs struct {
+	Action string `json:"action,omitempty"` // e.g. "purchase_course" or "refund_course"; empty for all
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type historyEntry struct {
+	Action    string `json:"action"`
+	CourseID  string `json:"course_id,omitempty"`
+	Query     string `json:"query,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+type getHistoryResults struct {
+	Status            string         `json:"status"`
+	Entries           []historyEntry `json:"entries"`
+	TotalMatches      int            `json:"total_matches"`                // matching entries before the limit
+	SummarizedMatches int            `json:"summarized_matches,omitempty"` // older matches only counted in history_summary
+	Message           string         `json:"message"`
+}
+
+// getHistory looks up interaction history entries for the get_history tool
+func getHistory(ctx tool.Context, input getHistoryArgs) (getHistoryResults, error) {
+	slog.Info("tool called", "tool", "get_history", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "action", input.Action, "limit", input.Limit)
+	return findHistory(ctx.State(), input.Action, input.Limit), nil
+}
+
+func findHistory(state session.ReadonlyState, action string, limit int) getHistoryResults {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if limit <= 0 {
+		limit = DEFAULT_HISTORY_LIMIT
+	}
+	limit = min(limit, MAX_HISTORY_LIMIT)
+
+	// Walk the list backwards so entries with equal or missing timestamps stay newest first
+	history := getInteractionHistory(state)
+	matches := []historyEntry{}
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := toHistoryEntry(history[i])
+		if action == "" || entry.Action == action {
+			matches = append(matches, entry)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		ti, okI := parseHistoryTime(matches[i].Timestamp)
+		tj, okJ := parseHistoryTime(matches[j].Timestamp)
+		if okI != okJ {
+			return okI // entries without a readable timestamp go last
+		}
+		return ti.After(tj)
+	})
+
+	results := getHistoryResults{Status: "success", TotalMatches: len(matches)}
+	stats := getHistoryStats(state)
+	if action == "" {
+		results.SummarizedMatches = stats.Count
+	} else {
+		results.SummarizedMatches = stats.Actions[action]
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	results.Entries = matches
+
+	what := "interaction(s)"
+	if action != "" {
+		what = action + " interaction(s)"
+	}
+	results.Message = fmt.Sprintf("Showing %d of %d %s, newest first", len(matches), results.TotalMatches, what)
+	if results.TotalMatches == 0 {
+		results.Message = fmt.Sprintf("No %s in the recent history", what)
+	}
+	if results.SummarizedMatches > 0 {
+		results.Message += fmt.Sprintf("; %d older one(s) are only in the history summary", results.SummarizedMatches)
+	}
+	return results
+}
+
+// toHistoryEntry reads one stored entry; missing or non-string fields are left empty and a
+// missing action becomes "unknown", as in history_summary
+func toHistoryEntry(m map[string]any) historyEntry {
+	entry := historyEntry{Action: "unknown"}
+	if action, ok := m["action"].(string); ok && action != "" {
+		entry.Action = action
+	}
+	entry.CourseID, _ = m["course_id"].(string)
+	entry.Query, _ = m["query"].(string)
+	entry.Timestamp, _ = m["timestamp"].(string)
+	return entry
+}
+
+// parseHistoryTime reads a timestamp written by the tools (PURCHASE_DATE_FORMAT, local time) or RFC 3339
+func parseHistoryTime(ts string) (time.Time, bool) {
+	ts = strings.TrimSpace(ts)
+	if t, err := time.ParseInLocation(PURCHASE_DATE_FORMAT, ts, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// newGetHistoryTool creates the get_history tool
+func newGetHistoryTool() (tool.Tool, error) {
+	getHistoryTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "get_history",
+			Description: "Lists the user's recent interactions newest first, optionally only one action (e.g. purchase_course or refund_course), up to limit entries (default 10)",
+		},
+		getHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create get_history tool: %w", err)
+	}
+	return getHistoryTool, nil
+}
+
+// getHistoryStats reads the running totals; after a database round trip numbers are
+// float64 and lists are []any
+func getHistoryStats(state session.ReadonlyState) historyStats {
+	stats := historyStats{Actions: map[string]int{}, Courses: map[string][]string{}}
+	val, err := state.Get(HISTORY_STATS_KEY)
+	if err != nil {
+		return stats
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		return stats
+	}
+
+	stats.Count = toInt(m["count"])
+	stats.First, _ = m["first"].(string)
+	stats.Last, _ = m["last"].(string)
+	if actions, ok := m["actions"].(map[string]any); ok {
+		for action, count := range actions {
+			stats.Actions[action] = toInt(count)
+		}
+	}
+	if courses, ok := m["courses"].(map[string]any); ok {
+		for action, ids := range courses {
+			switch v := ids.(type) {
+			case []string:
+				stats.Courses[action] = append([]string{}, v...)
+			case []any:
+				for _, id := range v {
+					if s, ok := id.(string); ok {
+						stats.Courses[action] = append(stats.Courses[action], s)
+					}
+				}
+			}
+		}
+	}
+	return stats
+}
+
+// getInteractionHistory reads interaction_history, oldest first
+func getInteractionHistory(state session.ReadonlyState) []map[string]any {
+	var history []map[string]any
+	val, err := state.Get(INTERACTION_HISTORY_KEY)
+	if err != nil {
+		return history
+	}
+	switch v := val.(type) {
+	case []map[string]any:
+		history = append(history, v...)
+	case []any:
+		for _, h := range v {
+			if hMap, ok := h.(map[string]any); ok {
+				history = append(history, hMap)
+			}
+		}
+	}
+	return history
+}
+
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package agents
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func historyEntries(n int) []map[string]any {
+	entries := make([]map[string]any, 0, n)
+	for i := 0; i < n; i++ {
+		entry := map[string]any{
+			"action":    "user_query",
+			"timestamp": fmt.Sprintf("2024-12-03 15:%02d:00", i),
+		}
+		switch i {
+		case 2:
+			entry["action"] = "purchase_course"
+			entry["course_id"] = "ai_marketing_platform"
+		case 5:
+			entry["action"] = "refund_course"
+			entry["course_id"] = "ai_marketing_platform"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSummarizeHistory(t *testing.T) {
+	stats := summarizeHistory(historyStats{}, historyEntries(12))
+
+	want := "12 earlier interactions (2024-12-03 15:00:00 to 2024-12-03 15:11:00): " +
+		"1x purchase_course (ai_marketing_platform), 1x refund_course (ai_marketing_platform), 10x user_query"
+	if got := stats.String(); got != want {
+		t.Errorf("String() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSummarizeHistoryAcrossCompactions(t *testing.T) {
+	entries := historyEntries(20)
+	all := summarizeHistory(historyStats{}, entries)
+
+	// Summarize in two steps, persisting the stats in between
+	first := summarizeHistory(historyStats{}, entries[:8])
+	state := agenttest.NewState(map[string]any{HISTORY_STATS_KEY: first.toState()}, true)
+	second := summarizeHistory(getHistoryStats(state), entries[8:])
+
+	if second.String() != all.String() {
+		t.Errorf("incremental summary =\n%q\nwant\n%q", second.String(), all.String())
+	}
+}
+
+func TestGetInteractionHistoryAfterRoundTrip(t *testing.T) {
+	state := agenttest.NewState(map[string]any{INTERACTION_HISTORY_KEY: historyEntries(3)}, true)
+
+	history := getInteractionHistory(state)
+	if len(history) != 3 {
+		t.Fatalf("got %d entries, want 3", len(history))
+	}
+	if history[2]["timestamp"] != "2024-12-03 15:02:00" {
+		t.Errorf("history[2] = %v, want the newest entry last", history[2])
+	}
+}
+
+func TestFindHistoryFiltersNewestFirst(t *testing.T) {
+	entries := historyEntries(8)
+	entries = append(entries,
+		map[string]any{"action": "refund_course", "course_id": "ai_marketing_platform", "timestamp": "2024-12-03 16:00:00"},
+		map[string]any{"timestamp": "2024-12-03 16:01:00"}, // no action
+		map[string]any{"action": "refund_course"},          // no timestamp
+	)
+	state := agenttest.NewState(map[string]any{
+		INTERACTION_HISTORY_KEY: entries,
+		HISTORY_STATS_KEY:       map[string]any{"count": 4, "actions": map[string]any{"refund_course": 1, "user_query": 3}},
+	}, true)
+
+	results := findHistory(state, " Refund_Course ", 0)
+	if results.TotalMatches != 3 || results.SummarizedMatches != 1 {
+		t.Errorf("results = %+v, want 3 matches and 1 summarized", results)
+	}
+	var got []string
+	for _, e := range results.Entries {
+		got = append(got, e.Timestamp)
+	}
+	if want := []string{"2024-12-03 16:00:00", "2024-12-03 15:05:00", ""}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("timestamps = %q, want %q", got, want)
+	}
+
+	results = findHistory(state, "", 2)
+	if len(results.Entries) != 2 || results.TotalMatches != 11 || results.Entries[0].Action != "unknown" {
+		t.Errorf("limited results = %+v, want the 2 newest of 11 with the missing action as unknown", results)
+	}
+
+	if results := findHistory(agenttest.NewState(nil, true), "refund_course", 5); results.TotalMatches != 0 || results.Entries == nil {
+		t.Errorf("empty history = %+v, want no matches", results)
+	}
+}
@@ -0,0 +1,71 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ===== Initial State =====
+
+// DefaultInitialState is the state new sessions start with when there is no initial_state.json
+func DefaultInitialState() map[string]any {
+	return map[string]any{
+		"user_name":             "Muchlis",
+		PURCHASED_COURSES_KEY:   []any{},
+		INTERACTION_HISTORY_KEY: []any{},
+		EVENTS_KEY:              []any{},
+		FOLLOW_UPS_KEY:          []any{},
+	}
+}
+
+// ValidateInitialState checks that state can be read by the tools as it is: user_name is set,
+// and purchased_courses and interaction_history need no repair (see repair_state). Every
+// problem found is reported, not only the first.
+func ValidateInitialState(state map[string]any) error {
+	var problems []string
+	if name, _ := state["user_name"].(string); strings.TrimSpace(name) == "" {
+		problems = append(problems, "user_name must be a non-empty string")
+	}
+
+	for _, key := range []struct {
+		name   string
+		repair func(any) (any, []StateRepair)
+	}{
+		{PURCHASED_COURSES_KEY, repairPurchasedCourses},
+		{INTERACTION_HISTORY_KEY, repairInteractionHistory},
+	} {
+		val, ok := state[key.name]
+		if !ok {
+			continue
+		}
+		_, repairs := key.repair(val)
+		for _, r := range repairs {
+			if r.Index < 0 {
+				problems = append(problems, fmt.Sprintf("%s: %s", r.Key, r.Problem))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s[%d]: %s", r.Key, r.Index, r.Problem))
+			}
+		}
+	}
+
+	if val, ok := state[EVENTS_KEY]; ok {
+		entries, isList := toList(val)
+		if !isList {
+			problems = append(problems, fmt.Sprintf("%s: not a list (%T)", EVENTS_KEY, val))
+		}
+		for i, entry := range entries {
+			e, _ := entry.(map[string]any)
+			id, _ := e["id"].(string)
+			eventType, _ := e["type"].(string)
+			if id == "" || eventType == "" {
+				problems = append(problems, fmt.Sprintf("%s[%d]: must be an object with string id and type", EVENTS_KEY, i))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid initial state: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
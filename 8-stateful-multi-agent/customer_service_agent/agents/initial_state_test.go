@@ -0,0 +1,50 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+func TestInitialStateExampleFile(t *testing.T) {
+	state, fromFile, err := toolutil.LoadInitialState("../initial_state.example.json", DefaultInitialState(), ValidateInitialState)
+	if err != nil || !fromFile {
+		t.Fatalf("LoadInitialState(example) = %v, %v, want it loaded", fromFile, err)
+	}
+
+	s := agenttest.NewState(state, true)
+	if _, owned := ownsCourse(s, "ai_marketing_platform"); !owned {
+		t.Error("the example's course isn't owned")
+	}
+	if history := getInteractionHistory(s); len(history) != 1 {
+		t.Errorf("interaction history = %v, want the example's entry", history)
+	}
+	if events := GetEvents(s); len(events) != 0 {
+		t.Errorf("events = %v, want the default empty list", events)
+	}
+}
+
+func TestValidateInitialState(t *testing.T) {
+	if err := ValidateInitialState(DefaultInitialState()); err != nil {
+		t.Errorf("ValidateInitialState(defaults) = %v, want nil", err)
+	}
+
+	err := ValidateInitialState(map[string]any{
+		"user_name": " ",
+		PURCHASED_COURSES_KEY: []any{
+			map[string]any{"purchase_date": "2024-12-03 15:30:00"},
+		},
+		INTERACTION_HISTORY_KEY: []any{"asked about refunds"},
+		EVENTS_KEY:              []any{map[string]any{"type": EVENT_PURCHASE_COURSE}},
+	})
+	if err == nil {
+		t.Fatal("ValidateInitialState(bad state) = nil, want an error")
+	}
+	for _, want := range []string{"user_name", "purchased_courses[0]: missing id", "interaction_history[0]: not an object", "events[0]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package agents
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/tool"
+)
+
+// ===== Invoices =====
+//
+// generate_invoice renders an invoice for a purchased course to invoices/<number>.html.
+// HTML keeps the example free of PDF dependencies; the file prints to PDF from any browser.
+// The invoice number is derived from the user, course and purchase date, so generating the
+// invoice for the same purchase again overwrites the same file instead of creating a new one.
+
+const (
+	INVOICE_DIR         = "./invoices"
+	INVOICE_DATE_FORMAT = "2006-01-02"
+	INVOICE_CURRENCY    = "USD"
+	INVOICE_SELLER      = "AI Developer Accelerator"
+)
+
+type generateInvoiceArgs struct {
+	CourseID string `json:"course_id,omitempty"` // may be empty when the user owns exactly one course
+}
+
+type generateInvoiceResults struct {
+	Status        string   `json:"status"` // success, not_owned or error
+	InvoiceNumber string   `json:"invoice_number,omitempty"`
+	Path          string   `json:"path,omitempty"`
+	CourseID      string   `json:"course_id,omitempty"`
+	CourseName    string   `json:"course_name,omitempty"`
+	Amount        float64  `json:"amount,omitempty"`
+	Currency      string   `json:"currency,omitempty"`
+	OwnedCourses  []string `json:"owned_courses,omitempty"`
+	Message       string   `json:"message"`
+}
+
+// invoice holds everything printed on an invoice
+type invoice struct {
+	Number       string
+	IssueDate    string
+	PurchaseDate string
+	Seller       string
+	BillTo       string
+	CourseID     string
+	CourseName   string
+	Amount       float64
+	Currency     string
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; max-width: 640px; margin: 40px auto; color: #222; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { text-align: left; padding: 8px; border-bottom: 1px solid #ddd; }
+td.amount, th.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice</h1>
+<p>
+<strong>Invoice number:</strong> {{.Number}}<br>
+<strong>Issue date:</strong> {{.IssueDate}}<br>
+<strong>Purchase date:</strong> {{.PurchaseDate}}
+</p>
+<p><strong>From:</strong> {{.Seller}}<br><strong>Bill to:</strong> {{.BillTo}}</p>
+<table>
+<tr><th>Item</th><th class="amount">Price</th></tr>
+<tr><td>{{.CourseName}} ({{.CourseID}})</td><td class="amount">{{printf "%.2f" .Amount}} {{.Currency}}</td></tr>
+<tr><th>Total</th><th class="amount">{{printf "%.2f" .Amount}} {{.Currency}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+// generateInvoice writes an invoice for a course the user owns
+func generateInvoice(ctx tool.Context, input generateInvoiceArgs) (generateInvoiceResults, error) {
+	slog.Info("tool called", "tool", "generate_invoice", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "course_id", input.CourseID)
+
+	state := ctx.State()
+	courses := getPurchasedCourses(state)
+	owned := make([]string, 0, len(courses))
+	for _, course := range courses {
+		owned = append(owned, course.ID)
+	}
+
+	courseID := strings.ToLower(strings.TrimSpace(input.CourseID))
+	if courseID == "" {
+		if len(courses) != 1 {
+			return generateInvoiceResults{
+				Status:       "error",
+				OwnedCourses: owned,
+				Message:      "Pass the course_id of the purchase to invoice",
+			}, nil
+		}
+		courseID = courses[0].ID
+	}
+
+	course, ok := ownsCourse(state, courseID)
+	if !ok {
+		return generateInvoiceResults{
+			Status:       "not_owned",
+			CourseID:     courseID,
+			OwnedCourses: owned,
+			Message:      fmt.Sprintf("The user hasn't purchased %s, so there is nothing to invoice", courseName(courseID)),
+		}, nil
+	}
+
+	price, ok := coursePrices[courseID]
+	if !ok {
+		return generateInvoiceResults{
+			Status:  "error",
+			Message: fmt.Sprintf("No price is known for %s", courseID),
+		}, nil
+	}
+
+	userName := "Customer"
+	if val, err := state.Get("user_name"); err == nil {
+		if name, ok := val.(string); ok && strings.TrimSpace(name) != "" {
+			userName = name
+		}
+	}
+
+	inv := invoice{
+		Number:       invoiceNumber(ctx.UserID(), course),
+		IssueDate:    time.Now().Format(INVOICE_DATE_FORMAT),
+		PurchaseDate: course.PurchaseDate,
+		Seller:       INVOICE_SELLER,
+		BillTo:       userName,
+		CourseID:     course.ID,
+		CourseName:   courseName(course.ID),
+		Amount:       price,
+		Currency:     INVOICE_CURRENCY,
+	}
+
+	path, err := writeInvoice(INVOICE_DIR, inv)
+	if err != nil {
+		slog.Error("failed to write invoice", "invoice_number", inv.Number, "session_id", ctx.SessionID(), "error", err)
+		return generateInvoiceResults{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to write the invoice: %v", err),
+		}, nil
+	}
+
+	return generateInvoiceResults{
+		Status:        "success",
+		InvoiceNumber: inv.Number,
+		Path:          path,
+		CourseID:      inv.CourseID,
+		CourseName:    inv.CourseName,
+		Amount:        inv.Amount,
+		Currency:      inv.Currency,
+		Message:       fmt.Sprintf("Invoice %s saved to %s", inv.Number, path),
+	}, nil
+}
+
+// invoiceNumber is stable for a purchase, e.g. INV-20241203-1a2b3c4d
+func invoiceNumber(userID string, course Course) string {
+	date := "00000000"
+	if purchased, err := time.Parse(PURCHASE_DATE_FORMAT, course.PurchaseDate); err == nil {
+		date = purchased.Format("20060102")
+	}
+	sum := sha256.Sum256([]byte(userID + "\x00" + course.ID + "\x00" + course.PurchaseDate))
+	return fmt.Sprintf("INV-%s-%s", date, hex.EncodeToString(sum[:4]))
+}
+
+// writeInvoice renders inv to dir/<number>.html and returns the path
+func writeInvoice(dir string, inv invoice) (string, error) {
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, inv); err != nil {
+		return "", fmt.Errorf("failed to render invoice: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, inv.Number+".html")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
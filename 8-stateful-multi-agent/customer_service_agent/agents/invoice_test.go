@@ -0,0 +1,59 @@
+package agents
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestInvoiceNumberIsStablePerPurchase(t *testing.T) {
+	course := Course{ID: "ai_marketing_platform", PurchaseDate: "2024-12-03 15:30:00"}
+
+	number := invoiceNumber("user", course)
+	if !regexp.MustCompile(`^INV-20241203-[0-9a-f]{8}$`).MatchString(number) {
+		t.Errorf("invoiceNumber = %q, want INV-20241203-<8 hex digits>", number)
+	}
+	if again := invoiceNumber("user", course); again != number {
+		t.Errorf("invoiceNumber changed between calls: %q != %q", again, number)
+	}
+	if other := invoiceNumber("other_user", course); other == number {
+		t.Error("different users got the same invoice number")
+	}
+}
+
+func TestWriteInvoice(t *testing.T) {
+	inv := invoice{
+		Number:       "INV-20241203-1a2b3c4d",
+		IssueDate:    "2024-12-10",
+		PurchaseDate: "2024-12-03 15:30:00",
+		Seller:       INVOICE_SELLER,
+		BillTo:       "<script>alert(1)</script>",
+		CourseID:     "ai_marketing_platform",
+		CourseName:   "Fullstack AI Marketing Platform",
+		Amount:       149,
+		Currency:     INVOICE_CURRENCY,
+	}
+
+	path, err := writeInvoice(t.TempDir(), inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "INV-20241203-1a2b3c4d.html") {
+		t.Errorf("path = %q, want it named after the invoice number", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{"INV-20241203-1a2b3c4d", "Fullstack AI Marketing Platform", "149.00 USD", "&lt;script&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("invoice doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("user name isn't escaped")
+	}
+}
@@ -3,6 +3,8 @@ package agents
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"google.golang.org/adk/agent"
@@ -10,14 +12,35 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolregistry"
+)
+
+const (
+	REFUND_WINDOW_DAYS   = 30                    // the 30-day money-back guarantee
+	PURCHASE_DATE_FORMAT = "2006-01-02 15:04:05" // how purchase_course stores purchase_date, in local time
 )
 
 // ===== Order Agent Tool Structures =====
 
-type getCurrentTimeArgs struct{}
+type getPurchaseHistoryArgs struct{}
+
+type purchaseRecord struct {
+	CourseID     string `json:"course_id"`
+	CourseName   string `json:"course_name"`
+	PurchaseDate string `json:"purchase_date"`
+	// Days left in the refund window; 0 is the last day and negative means it expired.
+	// Omitted when purchase_date can't be parsed.
+	RefundDaysRemaining *int  `json:"refund_days_remaining,omitempty"`
+	RefundWindowExpired *bool `json:"refund_window_expired,omitempty"`
+}
 
-type getCurrentTimeResults struct {
-	CurrentTime string `json:"current_time"`
+type getPurchaseHistoryResults struct {
+	Status           string           `json:"status"`
+	Purchases        []purchaseRecord `json:"purchases"`
+	RefundWindowDays int              `json:"refund_window_days"`
+	Message          string           `json:"message"`
 }
 
 type refundCourseArgs struct{}
@@ -31,70 +54,64 @@ type refundCourseResults struct {
 
 // ===== Tool Implementations =====
 
-// getCurrentTime returns the current time in YYYY-MM-DD HH:MM:SS format
-func getCurrentTime(ctx tool.Context, input getCurrentTimeArgs) (getCurrentTimeResults, error) {
-	fmt.Println("--- Tool: get_current_time called ---")
-	currentTime := time.Now().Format("2006-01-02 15:04:05")
-	return getCurrentTimeResults{
-		CurrentTime: currentTime,
+// getPurchaseHistory lists the purchased courses with the days left in each refund window
+func getPurchaseHistory(ctx tool.Context, input getPurchaseHistoryArgs) (getPurchaseHistoryResults, error) {
+	slog.Info("tool called", "tool", "get_purchase_history", "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+
+	now := time.Now()
+	purchases := []purchaseRecord{}
+	for _, course := range getPurchasedCourses(ctx.State()) {
+		record := purchaseRecord{
+			CourseID:     course.ID,
+			CourseName:   courseName(course.ID),
+			PurchaseDate: course.PurchaseDate,
+		}
+		if days, ok := refundDaysRemaining(course.PurchaseDate, now); ok {
+			expired := days < 0
+			record.RefundDaysRemaining = &days
+			record.RefundWindowExpired = &expired
+		}
+		purchases = append(purchases, record)
+	}
+
+	message := fmt.Sprintf("%d purchased course(s)", len(purchases))
+	if len(purchases) == 0 {
+		message = "No purchased courses"
+	}
+	return getPurchaseHistoryResults{
+		Status:           "success",
+		Purchases:        purchases,
+		RefundWindowDays: REFUND_WINDOW_DAYS,
+		Message:          message,
 	}, nil
 }
 
 // refundCourse simulates refunding the AI Marketing Platform course
 // Updates state by removing the course from purchased_courses
 func refundCourse(ctx tool.Context, input refundCourseArgs) (refundCourseResults, error) {
-	fmt.Println("--- Tool: refund_course called ---")
+	slog.Info("tool called", "tool", "refund_course", "agent", ctx.AgentName(), "session_id", ctx.SessionID())
 
 	courseID := "ai_marketing_platform"
 	currentTime := time.Now().Format("2006-01-02 15:04:05")
 
 	state := ctx.State()
 
-	// Get current purchased courses
-	var purchasedCourses []Course
-	if val, err := state.Get("purchased_courses"); err == nil {
-		if courses, ok := val.([]any); ok {
-			for _, c := range courses {
-				if courseMap, ok := c.(map[string]any); ok {
-					course := Course{
-						ID:           fmt.Sprintf("%v", courseMap["id"]),
-						PurchaseDate: fmt.Sprintf("%v", courseMap["purchase_date"]),
-					}
-					purchasedCourses = append(purchasedCourses, course)
-				}
-			}
-		}
-	}
-
 	// Check if user owns the course
-	found := false
-	for _, course := range purchasedCourses {
-		if course.ID == courseID {
-			found = true
-			break
-		}
-	}
-
-	if !found {
+	if _, ok := ownsCourse(state, courseID); !ok {
 		return refundCourseResults{
 			Status:  "error",
 			Message: "You don't own this course, so it can't be refunded.",
 		}, nil
 	}
 
-	// Create new list without the course to be refunded
-	var newPurchasedCourses []map[string]any
-	for _, course := range purchasedCourses {
+	// Keep every other course and update purchased courses in state
+	var remaining []Course
+	for _, course := range getPurchasedCourses(state) {
 		if course.ID != courseID {
-			newPurchasedCourses = append(newPurchasedCourses, map[string]any{
-				"id":            course.ID,
-				"purchase_date": course.PurchaseDate,
-			})
+			remaining = append(remaining, course)
 		}
 	}
-
-	// Update purchased courses in state
-	state.Set("purchased_courses", newPurchasedCourses)
+	state.Set(PURCHASED_COURSES_KEY, coursesToState(remaining))
 
 	// Get current interaction history
 	var interactionHistory []map[string]any
@@ -118,6 +135,11 @@ func refundCourse(ctx tool.Context, input refundCourseArgs) (refundCourseResults
 	// Update interaction history in state
 	state.Set("interaction_history", interactionHistory)
 
+	// Let other agents know the course was refunded, e.g. so onboarding is no longer offered
+	if _, err := PublishEvent(state, ctx.AgentName(), EVENT_REFUND_COURSE, map[string]any{"course_id": courseID}); err != nil {
+		slog.Warn("failed to publish event", "event", EVENT_REFUND_COURSE, "session_id", ctx.SessionID(), "error", err)
+	}
+
 	return refundCourseResults{
 		Status:    "success",
 		Message:   "Successfully refunded the AI Marketing Platform course! Your $149 will be returned to your original payment method within 3-5 business days.",
@@ -126,19 +148,58 @@ func refundCourse(ctx tool.Context, input refundCourseArgs) (refundCourseResults
 	}, nil
 }
 
+// ===== Utility Functions =====
+
+// refundDaysRemaining returns the calendar days from now until the last day of the refund
+// window of a purchase made at purchaseDate: 0 on the last day, negative once it expired.
+// ok is false when purchaseDate can't be parsed.
+func refundDaysRemaining(purchaseDate string, now time.Time) (days int, ok bool) {
+	purchased, err := time.ParseInLocation(PURCHASE_DATE_FORMAT, strings.TrimSpace(purchaseDate), now.Location())
+	if err != nil {
+		// Also accept RFC 3339, e.g. from hand-edited state
+		if purchased, err = time.Parse(time.RFC3339, strings.TrimSpace(purchaseDate)); err != nil {
+			return 0, false
+		}
+		purchased = purchased.In(now.Location())
+	}
+
+	// Compare calendar dates at UTC midnight, so DST changes don't shift the count
+	lastDay := purchased.AddDate(0, 0, REFUND_WINDOW_DAYS)
+	lastDate := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(lastDate.Sub(today).Hours() / 24), true
+}
+
 // ===== Agent Creation =====
 
 // NewOrderAgent creates a specialized agent for order management and refunds
-func NewOrderAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
-	// Create get_current_time tool
-	getCurrentTimeTool, err := functiontool.New(
+func NewOrderAgent(ctx context.Context, mdl model.LLM, persona string, emitter *agentutil.EventEmitter, prices agentutil.PriceTable) (agent.Agent, error) {
+	// Get the shared get_current_time tool from the registry (also used by the multi-agent manager)
+	getCurrentTimeTool, err := toolregistry.Get(toolregistry.GET_CURRENT_TIME)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create get_purchase_history tool
+	getPurchaseHistoryTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "get_purchase_history",
+			Description: "Lists the user's purchased courses with purchase dates and the days remaining in each 30-day refund window",
+		},
+		getPurchaseHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create get_purchase_history tool: %w", err)
+	}
+
+	// Create generate_invoice tool; invoices are written to INVOICE_DIR
+	generateInvoiceTool, err := functiontool.New(
 		functiontool.Config{
-			Name:        "get_current_time",
-			Description: "Get the current time in the format YYYY-MM-DD HH:MM:SS",
+			Name:        "generate_invoice",
+			Description: "Generates an invoice (HTML file under invoices/) for a purchased course and returns its number and path",
 		},
-		getCurrentTime)
+		generateInvoice)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create get_current_time tool: %w", err)
+		return nil, fmt.Errorf("failed to create generate_invoice tool: %w", err)
 	}
 
 	// Create refund_course tool
@@ -152,11 +213,43 @@ func NewOrderAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
 		return nil, fmt.Errorf("failed to create refund_course tool: %w", err)
 	}
 
+	// Create the shared owns_course tool
+	ownsCourseTool, err := newOwnsCourseTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create get_history tool, for questions like "show my refunds"
+	getHistoryTool, err := newGetHistoryTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create schedule_follow_up and due_follow_ups tools, for the re-enrollment reminder after a refund
+	scheduleFollowUpTool, err := newScheduleFollowUpTool()
+	if err != nil {
+		return nil, err
+	}
+	dueFollowUpsTool, err := NewDueFollowUpsTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create list_follow_ups and cancel_follow_up tools, so users can see and remove follow-ups
+	listFollowUpsTool, err := newListFollowUpsTool()
+	if err != nil {
+		return nil, err
+	}
+	cancelFollowUpTool, err := newCancelFollowUpTool()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create order agent
-	orderAgent, err := llmagent.New(llmagent.Config{
+	orderAgent, err := agentutil.NewLLMAgent(agentutil.WithCostEstimate(agentutil.WithEventEmitter(WithPersona(llmagent.Config{
 		Name:        "order_agent",
 		Model:       mdl,
-		Description: "Order agent for viewing purchase history and processing refunds",
+		Description: "Order agent for viewing purchase history, generating invoices and processing refunds",
 		Instruction: `You are the order agent for the AI Developer Accelerator community.
 Your role is to help users view their purchase history, course access, and process refunds.
 
@@ -164,31 +257,38 @@ Your role is to help users view their purchase history, course access, and proce
 Name: {user_name}
 </user_info>
 
-<purchase_info>
-Purchased Courses: {purchased_courses}
-</purchase_info>
+<history_summary>
+{history_summary?}
+</history_summary>
 
 <interaction_history>
 {interaction_history}
 </interaction_history>
 
 When users ask about their purchases:
-1. Check their course list from the purchase info above
-   - Course information is stored as objects with "id" and "purchase_date" properties
+1. Call the get_purchase_history tool
 2. Format the response clearly showing:
    - Which courses they own
    - When they were purchased (from the course.purchase_date property)
+   - How many days are left to request a refund (refund_days_remaining; 0 means today is the last day).
+     If refund_window_expired is true, say the refund window has closed. If the field is missing,
+     don't guess the number of days
 
 When users request a refund:
-1. Verify they own the course they want to refund ("ai_marketing_platform")
+1. Verify they own the course they want to refund: call owns_course with course_id "ai_marketing_platform"
 2. If they own it:
    - **CRITICAL**: You MUST call the refund_course tool to actually process the refund
    - DO NOT just say the refund is processed - actually call the tool
    - After calling the tool, confirm the refund was successful
    - Remind them the money will be returned to their original payment method
-   - If it's been more than 30 days, inform them that they are not eligible for a refund
+   - If it's been more than 30 days (get_purchase_history shows refund_window_expired), inform them
+     that they are not eligible for a refund
 3. If they don't own it:
    - Inform them they don't own the course, so no refund is needed
+4. After refund_course returns status "success", call schedule_follow_up with days_from_now 7 and
+   a message inviting them to re-enroll in the Fullstack AI Marketing Platform when they are ready.
+   Tell them you'll check in about re-enrolling on the returned due date. If it returns an error,
+   don't mention the follow-up; the refund itself still succeeded
 
 **IMPORTANT**: The refund_course tool is the ONLY way to remove courses from the user's account.
 You must call it for every refund request, not just acknowledge the request.
@@ -200,13 +300,36 @@ Example Response for Purchase History:
 "Here are your purchased courses:
 1. Fullstack AI Marketing Platform
    - Purchased on: 2024-04-21 10:30:00
-   - Full lifetime access"
+   - Full lifetime access
+   - 12 days left to request a refund"
 
 Example Response for Refund:
 "I've processed your refund for the Fullstack AI Marketing Platform course.
 Your $149 will be returned to your original payment method within 3-5 business days.
 The course has been removed from your account."
 
+When users ask about past purchases or refunds (e.g. "show my refunds", "when did I buy it?"):
+1. Call get_history with action "refund_course" or "purchase_course" (leave action empty for all
+   interactions) and a limit if they ask for a number of entries
+2. List the returned entries newest first with their dates. If summarized_matches is above 0, mention
+   that older ones exist but only as a summary
+
+When users ask for an invoice or receipt:
+1. Call the generate_invoice tool with the course id (it can be left empty if they own one course)
+2. Share the invoice number and the file path it returns
+3. If it returns "not_owned", tell them there is no purchase of that course to invoice
+
+When users ask whether any reminders or follow-ups are due, call due_follow_ups and share the
+messages it returns. If none are due but upcoming is above 0, say how many are scheduled for later.
+
+When users want to see their scheduled follow-ups, call list_follow_ups (include_delivered true only
+if they ask about past ones) and list each with its message and due date. If there are none, say so.
+
+When users want to cancel a follow-up (e.g. "I don't want the re-enrollment reminder"):
+1. Call list_follow_ups and pick the follow-up they mean; ask if more than one could match
+2. Call cancel_follow_up with its id, exactly as returned. Never guess an id
+3. If it returns "not_found", tell them that follow-up no longer exists and list the remaining ones
+
 If they haven't purchased any courses:
 - Let them know they don't have any courses yet
 - Suggest talking to the sales agent about the AI Marketing Platform course
@@ -216,8 +339,9 @@ Remember:
 - Mention our 30-day money-back guarantee if relevant
 - Direct course questions to course support
 - Direct purchase inquiries to sales`,
-		Tools: []tool.Tool{refundCourseTool, getCurrentTimeTool},
-	})
+		Tools:                []tool.Tool{ownsCourseTool, getPurchaseHistoryTool, getHistoryTool, generateInvoiceTool, refundCourseTool, scheduleFollowUpTool, dueFollowUpsTool, listFollowUpsTool, cancelFollowUpTool, getCurrentTimeTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{CompactInteractionHistory},
+	}, persona), emitter), prices))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order agent: %w", err)
 	}
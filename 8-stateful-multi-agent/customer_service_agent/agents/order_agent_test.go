@@ -0,0 +1,30 @@
+package agents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefundDaysRemaining(t *testing.T) {
+	now := time.Date(2024, 12, 10, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		purchaseDate string
+		wantDays     int
+		wantOK       bool
+	}{
+		{"2024-12-10 08:00:00", 30, true},  // bought today
+		{"2024-12-03 15:30:00", 23, true},  // a week ago
+		{"2024-11-10 23:59:59", 0, true},   // last day of the window
+		{"2024-11-09 10:00:00", -1, true},  // expired yesterday
+		{"2024-12-03T12:00:00Z", 23, true}, // RFC 3339
+		{"last tuesday", 0, false},         // unparseable
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		days, ok := refundDaysRemaining(tt.purchaseDate, now)
+		if days != tt.wantDays || ok != tt.wantOK {
+			t.Errorf("refundDaysRemaining(%q) = %d, %v, want %d, %v", tt.purchaseDate, days, ok, tt.wantDays, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== Course Ownership =====
+//
+// purchased_courses is a list of {"id", "purchase_date"} objects. Every agent reads it through
+// getPurchasedCourses, and the sales, order and course support agents check ownership with the
+// owns_course tool rather than reading the list in their prompts.
+
+const PURCHASED_COURSES_KEY = "purchased_courses"
+
+type ownsCourseArgs struct {
+	CourseID string `json:"course_id"`
+}
+
+type ownsCourseResults struct {
+	Status       string `json:"status"` // success, unknown_course or error
+	CourseID     string `json:"course_id,omitempty"`
+	CourseName   string `json:"course_name,omitempty"`
+	Owned        bool   `json:"owned"`
+	PurchaseDate string `json:"purchase_date,omitempty"`
+	OwnedCount   int    `json:"owned_count"` // how many courses the user owns in total
+	Message      string `json:"message"`
+}
+
+// ownsCourseTool reports whether the user owns a course and when they bought it
+func ownsCourseTool(ctx tool.Context, input ownsCourseArgs) (ownsCourseResults, error) {
+	slog.Info("tool called", "tool", "owns_course", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "course_id", input.CourseID)
+	return checkOwnership(ctx.State(), input.CourseID), nil
+}
+
+func checkOwnership(state session.ReadonlyState, rawCourseID string) ownsCourseResults {
+	courses := getPurchasedCourses(state)
+	courseID := strings.ToLower(strings.TrimSpace(rawCourseID))
+
+	if courseID == "" {
+		return ownsCourseResults{
+			Status:     "error",
+			OwnedCount: len(courses),
+			Message:    fmt.Sprintf("course_id is required. Known courses: %s", strings.Join(knownCourseIDs(), ", ")),
+		}
+	}
+	if _, known := courseNames[courseID]; !known {
+		return ownsCourseResults{
+			Status:     "unknown_course",
+			CourseID:   courseID,
+			OwnedCount: len(courses),
+			Message:    fmt.Sprintf("Unknown course %q. Known courses: %s", rawCourseID, strings.Join(knownCourseIDs(), ", ")),
+		}
+	}
+
+	results := ownsCourseResults{
+		Status:     "success",
+		CourseID:   courseID,
+		CourseName: courseName(courseID),
+		OwnedCount: len(courses),
+	}
+	if course, ok := ownsCourse(state, courseID); ok {
+		results.Owned = true
+		results.PurchaseDate = course.PurchaseDate
+		results.Message = fmt.Sprintf("The user owns %s (purchased %s)", results.CourseName, course.PurchaseDate)
+	} else {
+		results.Message = fmt.Sprintf("The user doesn't own %s", results.CourseName)
+	}
+	return results
+}
+
+// newOwnsCourseTool creates the owns_course tool shared by the sales, order and course support agents
+func newOwnsCourseTool() (tool.Tool, error) {
+	ownsCourseTool, err := functiontool.New(
+		functiontool.Config{
+			Name:        "owns_course",
+			Description: "Checks whether the user owns a course (by course id) and returns its purchase date if so, plus how many courses they own",
+		},
+		ownsCourseTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create owns_course tool: %w", err)
+	}
+	return ownsCourseTool, nil
+}
+
+// ===== Utility Functions =====
+
+// getPurchasedCourses reads purchased_courses; fresh values are []map[string]any, after a
+// database round trip they are []any. Entries without a string id are skipped, and a missing
+// or non-string purchase_date is left empty.
+func getPurchasedCourses(state session.ReadonlyState) []Course {
+	courses := []Course{}
+	val, err := state.Get(PURCHASED_COURSES_KEY)
+	if err != nil {
+		return courses
+	}
+
+	var entries []any
+	switch v := val.(type) {
+	case []map[string]any:
+		for _, m := range v {
+			entries = append(entries, m)
+		}
+	case []any:
+		entries = v
+	}
+
+	for i, entry := range entries {
+		courseMap, ok := entry.(map[string]any)
+		id, _ := courseMap["id"].(string)
+		if !ok || strings.TrimSpace(id) == "" {
+			slog.Warn("skipping malformed purchased course", "index", i, "entry", fmt.Sprintf("%v", entry))
+			continue
+		}
+		purchaseDate, _ := courseMap["purchase_date"].(string)
+		courses = append(courses, Course{ID: id, PurchaseDate: purchaseDate})
+	}
+	return courses
+}
+
+// ownsCourse reports whether courseID is in purchased_courses
+func ownsCourse(state session.ReadonlyState, courseID string) (Course, bool) {
+	for _, course := range getPurchasedCourses(state) {
+		if course.ID == courseID {
+			return course, true
+		}
+	}
+	return Course{}, false
+}
+
+// coursesToState converts courses to the stored purchased_courses form
+func coursesToState(courses []Course) []map[string]any {
+	list := make([]map[string]any, 0, len(courses))
+	for _, course := range courses {
+		list = append(list, map[string]any{
+			"id":            course.ID,
+			"purchase_date": course.PurchaseDate,
+		})
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestOwnsCourseAfterRoundTrip(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses": []map[string]any{
+			{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+		},
+	}, true)
+
+	course, ok := ownsCourse(state, "ai_marketing_platform")
+	if !ok || course.PurchaseDate != "2024-12-03 15:30:00" {
+		t.Errorf("ownsCourse = %+v, %v, want the purchased course", course, ok)
+	}
+	if _, ok := ownsCourse(state, "other_course"); ok {
+		t.Error("ownsCourse reported a course that wasn't purchased")
+	}
+	if _, ok := ownsCourse(agenttest.NewState(map[string]any{}, true), "ai_marketing_platform"); ok {
+		t.Error("ownsCourse reported a course without purchased_courses in state")
+	}
+}
+
+func TestGetPurchasedCoursesSkipsMalformedEntries(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses": []any{
+			map[string]any{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+			map[string]any{"purchase_date": "2024-12-04 10:00:00"}, // no id
+			map[string]any{"id": 42},
+			"ai_marketing_platform",
+			map[string]any{"id": "other_course"}, // no purchase date
+		},
+	}, true)
+
+	courses := getPurchasedCourses(state)
+	if len(courses) != 2 || courses[0].ID != "ai_marketing_platform" || courses[1] != (Course{ID: "other_course"}) {
+		t.Errorf("courses = %+v, want the two entries with a string id", courses)
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	owned := agenttest.NewState(map[string]any{
+		"purchased_courses": []map[string]any{
+			{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+		},
+	}, true)
+
+	res := checkOwnership(owned, " AI_Marketing_Platform ")
+	if res.Status != "success" || !res.Owned || res.PurchaseDate != "2024-12-03 15:30:00" || res.OwnedCount != 1 {
+		t.Errorf("owned course = %+v", res)
+	}
+
+	empty := agenttest.NewState(map[string]any{"purchased_courses": []any{}}, true)
+	if res := checkOwnership(empty, "ai_marketing_platform"); res.Status != "success" || res.Owned || res.OwnedCount != 0 {
+		t.Errorf("empty purchased_courses = %+v, want not owned", res)
+	}
+	if res := checkOwnership(owned, "cooking_101"); res.Status != "unknown_course" || res.Owned {
+		t.Errorf("unknown course = %+v", res)
+	}
+	if res := checkOwnership(owned, ""); res.Status != "error" {
+		t.Errorf("empty course_id = %+v, want error", res)
+	}
+}
@@ -0,0 +1,49 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/agent/llmagent"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+)
+
+// ===== Persona =====
+//
+// Every customer service agent speaks with the same persona, so the system keeps one tone
+// whichever agent answers. The persona is prepended to each agent's instruction by its
+// constructor; the agent's own instruction follows unchanged, so its {state} template variables
+// still resolve. Changing CUSTOMER_SERVICE_PERSONA restyles every agent at once.
+
+const (
+	PERSONA_ENV = "CUSTOMER_SERVICE_PERSONA"
+
+	DEFAULT_PERSONA = `Be friendly but concise. Greet the user by name once, then get to the point:
+answer in a few short sentences or a short list, and skip filler and repeated apologies.
+Stay warm and encouraging, and end with a clear next step when there is one.`
+)
+
+// LoadPersona returns the persona from CUSTOMER_SERVICE_PERSONA, or DEFAULT_PERSONA when it is
+// unset. getenv is usually os.Getenv. Like the global instruction, the persona must not
+// contain template placeholders.
+func LoadPersona(getenv func(string) string) (string, error) {
+	persona := strings.TrimSpace(getenv(PERSONA_ENV))
+	if persona == "" {
+		return DEFAULT_PERSONA, nil
+	}
+	if match := agentutil.TemplatePlaceholder(persona); match != "" {
+		return "", fmt.Errorf("%s must not contain template placeholders, found %q", PERSONA_ENV, match)
+	}
+	return persona, nil
+}
+
+// WithPersona returns a copy of cfg with persona prepended to its instruction in a <persona>
+// block. An empty persona leaves cfg as is.
+func WithPersona(cfg llmagent.Config, persona string) llmagent.Config {
+	persona = strings.TrimSpace(persona)
+	if persona == "" {
+		return cfg
+	}
+	return agentutil.WithGlobalInstruction(cfg, "<persona>\n"+persona+"\n</persona>")
+}
@@ -0,0 +1,58 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/agent/llmagent"
+)
+
+func TestWithPersonaKeepsAgentInstruction(t *testing.T) {
+	instruction := "You are the order agent.\n\n<user_info>\nName: {user_name}\n</user_info>\n\nHistory: {history_summary?}"
+	cfg := llmagent.Config{Name: "order_agent", Instruction: instruction}
+
+	got := WithPersona(cfg, DEFAULT_PERSONA).Instruction
+
+	if !strings.HasPrefix(got, "<persona>\n"+DEFAULT_PERSONA+"\n</persona>\n\n") {
+		t.Errorf("instruction %q does not start with the persona block", got)
+	}
+	if !strings.HasSuffix(got, instruction) {
+		t.Errorf("instruction %q does not end with the agent's own instruction", got)
+	}
+	if strings.Count(got, "{") != 2 {
+		t.Errorf("instruction %q has placeholders other than the agent's own", got)
+	}
+	if cfg.Instruction != instruction {
+		t.Error("WithPersona modified the original config")
+	}
+
+	if got := WithPersona(cfg, " ").Instruction; got != instruction {
+		t.Errorf("instruction with an empty persona = %q, want it unchanged", got)
+	}
+}
+
+func TestLoadPersona(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", env: "", want: DEFAULT_PERSONA},
+		{name: "custom", env: "  Formal and thorough.\n", want: "Formal and thorough."},
+		{name: "placeholder", env: "Call the user {user_name}.", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadPersona(func(key string) string {
+				if key == PERSONA_ENV {
+					return tt.env
+				}
+				return ""
+			})
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("LoadPersona() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -7,14 +7,23 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 // ===== Agent Creation =====
 
 // NewPolicyAgent creates a specialized agent for community policies and guidelines
-func NewPolicyAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
-	// Create policy agent (no tools needed)
-	policyAgent, err := llmagent.New(llmagent.Config{
+func NewPolicyAgent(ctx context.Context, mdl model.LLM, persona string, emitter *agentutil.EventEmitter, prices agentutil.PriceTable) (agent.Agent, error) {
+	// Create search_policy tool; it searches the embedded policy documents in policies/
+	searchPolicyTool, err := NewSearchPolicyTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create policy agent
+	policyAgent, err := agentutil.NewLLMAgent(agentutil.WithCostEstimate(agentutil.WithEventEmitter(WithPersona(llmagent.Config{
 		Name:        "policy_agent",
 		Model:       mdl,
 		Description: "Policy agent for the AI Developer Accelerator community",
@@ -25,49 +34,23 @@ understand our community guidelines and policies.
 Name: {user_name}
 </user_info>
 
-Community Guidelines:
-1. Promotions
-   - No self-promotion or advertising
-   - Focus on learning and growing together
-   - Share your work only in designated channels
-
-2. Content Quality
-   - Provide detailed, helpful responses
-   - Include code examples when relevant
-   - Use proper formatting for code snippets
-
-3. Behavior
-   - Be respectful and professional
-   - No politics or religion discussions
-   - Help maintain a positive learning environment
-
-Course Policies:
-1. Refund Policy
-   - 30-day money-back guarantee
-   - Full refund if you complete the course and aren't satisfied
-   - No questions asked
-
-2. Course Access
-   - Lifetime access to course content
-   - 6 weeks of group support included
-   - Weekly coaching calls every Sunday
-
-3. Code Usage
-   - You can use course code in your projects
-   - Credit not required but appreciated
-   - No reselling of course materials
-
-Privacy Policy:
-- We respect your privacy
-- Your data is never sold
-- Course progress is tracked for support purposes
+Answer policy questions only from our published policies, never from memory or general knowledge.
+Call the search_policy tool with the keywords of the user's question (e.g. "refund", "share my
+project", "data sold") before every policy answer:
+- status "success": answer from the returned snippet and cite it, e.g.
+  "(Course Policies > Refund Policy)". If "related" lists other sections that may apply, search
+  again with their keywords when the question spans several topics
+- status "no_match": try once more with other words; if there is still no match, say that no
+  published policy covers this and suggest escalating the question to our support team
+- status "error": the query had no keywords; search again with the topic of the question
 
 When responding:
 1. Be clear and direct
 2. Quote relevant policy sections
 3. Explain the reasoning behind policies
 4. Direct complex issues to support`,
-	})
+		Tools: []tool.Tool{searchPolicyTool},
+	}, persona), emitter), prices))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create policy agent: %w", err)
 	}
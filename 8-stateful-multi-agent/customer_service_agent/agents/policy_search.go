@@ -0,0 +1,298 @@
+package agents
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"unicode"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== Policy Knowledge Base =====
+//
+// The policy agent answers from the documents in policies/, embedded into the binary, instead of
+// from policy text in its prompt. Each document is markdown: a "# Title" line, then "## Section"
+// blocks. A section may start with a "Keywords:" line listing words users might search with
+// (e.g. "money back" for the refund policy); it is searched but not shown.
+//
+// search_policy is a plain keyword search: query words are matched against each section's
+// title, keywords and text, and the section with the most matching words wins. A word in the
+// title counts most, then the keywords, then the text.
+
+//go:embed policies/*.md
+var policyDocuments embed.FS
+
+const POLICY_DIR = "policies"
+
+const (
+	titleWeight   = 3
+	keywordWeight = 2
+	textWeight    = 1
+
+	maxRelatedPolicies = 2
+)
+
+// policySection is one "## Section" of a policy document
+type policySection struct {
+	document string // document title, e.g. "Course Policies"
+	title    string // section title, e.g. "Refund Policy"
+	source   string // e.g. "policies/course_policies.md#refund-policy"
+	text     string // the section without its heading and keywords line
+
+	titleTerms, keywordTerms, textTerms map[string]bool
+}
+
+type policyCitation struct {
+	Document string `json:"document"`
+	Section  string `json:"section"`
+	Source   string `json:"source"`
+}
+
+type searchPolicyArgs struct {
+	Query string `json:"query"`
+}
+
+type searchPolicyResults struct {
+	Status   string           `json:"status"` // success, no_match or error
+	Query    string           `json:"query"`
+	Snippet  string           `json:"snippet,omitempty"`
+	Citation *policyCitation  `json:"citation,omitempty"`
+	Related  []policyCitation `json:"related,omitempty"` // other sections that matched, best first
+	Message  string           `json:"message"`
+}
+
+// loadPolicies parses every .md document in fsys, sorted by file name
+func loadPolicies(fsys fs.FS) ([]policySection, error) {
+	names, err := fs.Glob(fsys, "*.md")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no policy documents found")
+	}
+
+	var sections []policySection
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %s: %w", name, err)
+		}
+		parsed, err := parsePolicyDocument(path.Join(POLICY_DIR, name), string(data))
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, parsed...)
+	}
+	return sections, nil
+}
+
+// parsePolicyDocument splits one document into its sections
+func parsePolicyDocument(source, content string) ([]policySection, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "# ") {
+		return nil, fmt.Errorf("policy %s must start with a \"# Title\" line", source)
+	}
+	document := strings.TrimSpace(strings.TrimPrefix(lines[0], "# "))
+
+	var sections []policySection
+	var current *policySection
+	var body []string
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		current.text = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.text == "" {
+			return fmt.Errorf("policy %s: section %q is empty", source, current.title)
+		}
+		current.textTerms = termSet(current.text)
+		sections = append(sections, *current)
+		return nil
+	}
+
+	for _, line := range lines[1:] {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			title := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			current = &policySection{
+				document:     document,
+				title:        title,
+				source:       source + "#" + anchor(title),
+				titleTerms:   termSet(title),
+				keywordTerms: map[string]bool{},
+			}
+			body = nil
+		case current == nil:
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("policy %s: text before the first \"## Section\"", source)
+			}
+		case len(body) == 0 && strings.HasPrefix(line, "Keywords:"):
+			current.keywordTerms = termSet(strings.TrimPrefix(line, "Keywords:"))
+		default:
+			body = append(body, line)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("policy %s has no \"## Section\"", source)
+	}
+	return sections, nil
+}
+
+// searchPolicies returns the best matching section for query, with up to maxRelatedPolicies
+// other matching sections
+func searchPolicies(sections []policySection, query string) searchPolicyResults {
+	query = strings.TrimSpace(query)
+	terms := termSet(query)
+	if len(terms) == 0 {
+		return searchPolicyResults{
+			Status:  "error",
+			Query:   query,
+			Message: "query needs at least one keyword, e.g. \"refund\" or \"self-promotion\"",
+		}
+	}
+
+	type match struct {
+		section policySection
+		score   int
+	}
+	var matches []match
+	for _, section := range sections {
+		score := 0
+		for term := range terms {
+			switch {
+			case section.titleTerms[term]:
+				score += titleWeight
+			case section.keywordTerms[term]:
+				score += keywordWeight
+			case section.textTerms[term]:
+				score += textWeight
+			}
+		}
+		if score == 0 {
+			continue
+		}
+		// Keep the documents' order for equal scores
+		i := len(matches)
+		for i > 0 && matches[i-1].score < score {
+			i--
+		}
+		matches = append(matches[:i], append([]match{{section, score}}, matches[i:]...)...)
+	}
+
+	if len(matches) == 0 {
+		return searchPolicyResults{
+			Status: "no_match",
+			Query:  query,
+			Message: "No matching policy. Don't answer from general knowledge: tell the user no published policy " +
+				"covers this and suggest escalating the question to the support team",
+		}
+	}
+
+	best := matches[0].section
+	results := searchPolicyResults{
+		Status:   "success",
+		Query:    query,
+		Snippet:  best.text,
+		Citation: best.citation(),
+		Message:  fmt.Sprintf("Best match: %s > %s. Answer from the snippet and cite it.", best.document, best.title),
+	}
+	for _, m := range matches[1:min(len(matches), maxRelatedPolicies+1)] {
+		results.Related = append(results.Related, *m.section.citation())
+	}
+	return results
+}
+
+func (s policySection) citation() *policyCitation {
+	return &policyCitation{Document: s.document, Section: s.title, Source: s.source}
+}
+
+// searchStopWords are left out of policy searches and FAQ matches because nearly every
+// question contains them
+var searchStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "you": true, "your": true, "can": true,
+	"what": true, "how": true, "does": true, "about": true, "with": true, "that": true, "this": true,
+	"have": true, "will": true, "our": true, "any": true, "get": true, "there": true, "when": true,
+	"where": true, "which": true, "who": true, "why": true, "should": true, "would": true, "could": true,
+	"policy": true, "policies": true, "rule": true, "rules": true, "tell": true, "please": true,
+}
+
+// termSet returns the stemmed words of text, without stop words and words shorter than three
+// letters; numbers such as "30" are kept
+func termSet(text string) map[string]bool {
+	terms := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if (len(word) < 3 && !isNumber(word)) || searchStopWords[word] {
+			continue
+		}
+		terms[stem(word)] = true
+	}
+	return terms
+}
+
+func isNumber(word string) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// stem strips common English endings so "refunds", "refunded" and "refunding" match "refund",
+// and "advertise" matches "advertising". It is crude, but documents and queries go through the
+// same function.
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "e", "s"} {
+		if len(word) > len(suffix)+3 && strings.HasSuffix(word, suffix) {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// anchor returns the markdown anchor of a heading, e.g. "refund-policy"
+func anchor(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// NewSearchPolicyTool creates the search_policy tool over the embedded policy documents
+func NewSearchPolicyTool() (tool.Tool, error) {
+	policyFS, err := fs.Sub(policyDocuments, POLICY_DIR)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := loadPolicies(policyFS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	searchPolicy := func(ctx tool.Context, input searchPolicyArgs) (searchPolicyResults, error) {
+		results := searchPolicies(sections, input.Query)
+		slog.Info("tool called", "tool", "search_policy", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "query", input.Query, "status", results.Status)
+		return results, nil
+	}
+
+	searchPolicyTool, err := functiontool.New(
+		functiontool.Config{
+			Name: "search_policy",
+			Description: "Searches the community guidelines, course policies and privacy policy by keyword. " +
+				"Returns the most relevant policy section with a citation, or status no_match",
+		},
+		searchPolicy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search_policy tool: %w", err)
+	}
+	return searchPolicyTool, nil
+}
@@ -0,0 +1,97 @@
+package agents
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func embeddedPolicies(t *testing.T) []policySection {
+	t.Helper()
+	policyFS, err := fs.Sub(policyDocuments, POLICY_DIR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := loadPolicies(policyFS)
+	if err != nil {
+		t.Fatalf("embedded policies: %v", err)
+	}
+	return sections
+}
+
+func TestSearchPolicies(t *testing.T) {
+	sections := embeddedPolicies(t)
+
+	tests := []struct {
+		query       string
+		wantSection string
+		wantSnippet string
+	}{
+		{"Can I get my money back?", "Refund Policy", "30-day money-back guarantee"},
+		{"refunds", "Refund Policy", "No questions asked"},
+		{"Am I allowed to advertise my startup?", "Promotions", "No self-promotion"},
+		{"Is my personal data sold?", "Your Data", "never sold"},
+		{"When are the coaching calls?", "Course Access", "every Sunday"},
+		{"Can I use the course code in a commercial project?", "Code Usage", "use course code"},
+	}
+	for _, tt := range tests {
+		results := searchPolicies(sections, tt.query)
+		if results.Status != "success" || results.Citation == nil {
+			t.Errorf("search(%q) = %+v, want a match", tt.query, results)
+			continue
+		}
+		if results.Citation.Section != tt.wantSection || !strings.Contains(results.Snippet, tt.wantSnippet) {
+			t.Errorf("search(%q) = %s: %q, want %s containing %q", tt.query, results.Citation.Section, results.Snippet, tt.wantSection, tt.wantSnippet)
+		}
+		if strings.Contains(results.Snippet, "Keywords:") {
+			t.Errorf("search(%q) snippet shows the keywords line: %q", tt.query, results.Snippet)
+		}
+	}
+
+	refund := searchPolicies(sections, "refund")
+	if want := "policies/course_policies.md#refund-policy"; refund.Citation.Source != want || refund.Citation.Document != "Course Policies" {
+		t.Errorf("refund citation = %+v, want %s in Course Policies", refund.Citation, want)
+	}
+}
+
+func TestSearchPoliciesNoMatch(t *testing.T) {
+	sections := embeddedPolicies(t)
+
+	results := searchPolicies(sections, "Do you offer student discounts in Brazil?")
+	if results.Status != "no_match" || results.Citation != nil || !strings.Contains(results.Message, "escalat") {
+		t.Errorf("search = %+v, want no_match suggesting escalation", results)
+	}
+
+	// Only stop words leaves nothing to search for
+	results = searchPolicies(sections, "What is the policy?")
+	if results.Status != "error" {
+		t.Errorf("search of stop words = %+v, want error", results)
+	}
+}
+
+func TestLoadPoliciesRejectsMalformedDocuments(t *testing.T) {
+	tests := map[string]string{
+		"no title":       "## Section\n- text\n",
+		"no sections":    "# Title\n",
+		"empty section":  "# Title\n\n## Empty\nKeywords: a, b\n\n## Full\n- text\n",
+		"text too early": "# Title\nstray text\n## Section\n- text\n",
+	}
+	for name, content := range tests {
+		fsys := fstest.MapFS{"doc.md": {Data: []byte(content)}}
+		if _, err := loadPolicies(fsys); err == nil {
+			t.Errorf("%s: loadPolicies() = nil error, want invalid", name)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	for word, want := range map[string]string{
+		"refunds": "refund", "refunded": "refund", "refunding": "refund", "refund": "refund",
+		"calls": "call", "advertise": "advertis", "advertising": "advertis", "bus": "bus", "red": "red",
+	} {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== State Repair =====
+//
+// The readers above skip what they can't parse, so a malformed entry (a purchased course
+// without an id, a history entry that isn't an object) is silently ignored on every turn but
+// stays in the session forever. The repair_state tool scans the known keys, fixes what can be
+// fixed, drops what can't, saves the cleaned values and reports each change, so a corrupted
+// session can be recovered instead of abandoned. Keys that are already well-formed are not
+// written.
+
+const REPAIR_STATE = "repair_state"
+
+type repairStateArgs struct{}
+
+// StateRepair is one change made by repair_state
+type StateRepair struct {
+	Key     string `json:"key"`
+	Index   int    `json:"index"`   // position of the entry in the stored list, -1 for the whole value
+	Problem string `json:"problem"` // what was wrong
+	Fix     string `json:"fix"`     // what was done about it
+}
+
+type repairStateResults struct {
+	Status   string        `json:"status"` // repaired, ok or error
+	Repairs  []StateRepair `json:"repairs"`
+	Repaired []string      `json:"repaired_keys"` // keys that were rewritten
+	Message  string        `json:"message"`
+}
+
+// repairStateTool validates and repairs the known state keys
+func repairStateTool(ctx tool.Context, input repairStateArgs) (repairStateResults, error) {
+	slog.Info("tool called", "tool", REPAIR_STATE, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+	results := repairState(ctx.State())
+	if len(results.Repairs) > 0 {
+		slog.Warn("state repaired", "session_id", ctx.SessionID(), "keys", results.Repaired, "repairs", len(results.Repairs))
+	}
+	return results, nil
+}
+
+// repairState checks purchased_courses and interaction_history and writes back the repaired
+// values of the keys that needed changes
+func repairState(state session.State) repairStateResults {
+	results := repairStateResults{Status: "ok", Repairs: []StateRepair{}, Repaired: []string{}}
+
+	for _, key := range []struct {
+		name   string
+		repair func(any) (any, []StateRepair)
+	}{
+		{PURCHASED_COURSES_KEY, repairPurchasedCourses},
+		{INTERACTION_HISTORY_KEY, repairInteractionHistory},
+	} {
+		val, err := state.Get(key.name)
+		if err != nil {
+			continue // missing keys are read as empty everywhere
+		}
+		fixed, repairs := key.repair(val)
+		if len(repairs) == 0 {
+			continue
+		}
+		if err := state.Set(key.name, fixed); err != nil {
+			return repairStateResults{
+				Status:   "error",
+				Repairs:  results.Repairs,
+				Repaired: results.Repaired,
+				Message:  fmt.Sprintf("failed to save the repaired %s: %v", key.name, err),
+			}
+		}
+		results.Repairs = append(results.Repairs, repairs...)
+		results.Repaired = append(results.Repaired, key.name)
+	}
+
+	if len(results.Repairs) == 0 {
+		results.Message = "purchased_courses and interaction_history are well-formed; nothing was changed"
+		return results
+	}
+	results.Status = "repaired"
+	results.Message = fmt.Sprintf("Made %d repair(s) to %s", len(results.Repairs), strings.Join(results.Repaired, " and "))
+	return results
+}
+
+// repairPurchasedCourses returns purchased_courses as a list of {"id", "purchase_date"} objects:
+//   - a single course object instead of a list is wrapped in a list; any other non-list is emptied
+//   - a plain string entry is taken as the course id, with no purchase date
+//   - entries without a string id, and later duplicates of an id, are dropped
+//   - ids are trimmed and lowercased, like course ids everywhere else
+//   - a missing or non-string purchase_date becomes ""
+func repairPurchasedCourses(val any) (any, []StateRepair) {
+	var repairs []StateRepair
+	fix := func(index int, problem, fix string) {
+		repairs = append(repairs, StateRepair{Key: PURCHASED_COURSES_KEY, Index: index, Problem: problem, Fix: fix})
+	}
+
+	entries, ok := toList(val)
+	if !ok {
+		if m, isMap := val.(map[string]any); isMap && m["id"] != nil {
+			fix(-1, "a single course object instead of a list", "wrapped it in a list")
+			entries = []any{m}
+		} else {
+			fix(-1, fmt.Sprintf("not a list (%T)", val), "replaced it with an empty list")
+			return []map[string]any{}, repairs
+		}
+	}
+
+	courses := make([]map[string]any, 0, len(entries))
+	seen := map[string]bool{}
+	for i, entry := range entries {
+		var course map[string]any
+		switch e := entry.(type) {
+		case string:
+			fix(i, "a plain string instead of a course object", "used it as the course id, without a purchase date")
+			course = map[string]any{"id": e, "purchase_date": ""}
+		case map[string]any:
+			course = e
+		default:
+			fix(i, fmt.Sprintf("not a course object (%T)", entry), "dropped it")
+			continue
+		}
+
+		rawID, _ := course["id"].(string)
+		id := strings.ToLower(strings.TrimSpace(rawID))
+		if id == "" {
+			fix(i, "missing id", "dropped it")
+			continue
+		}
+		if id != rawID {
+			fix(i, fmt.Sprintf("id %q isn't normalized", rawID), fmt.Sprintf("changed it to %q", id))
+		}
+		if seen[id] {
+			fix(i, fmt.Sprintf("duplicate of course %q", id), "dropped it, keeping the first purchase")
+			continue
+		}
+		seen[id] = true
+
+		purchaseDate, ok := course["purchase_date"].(string)
+		if !ok {
+			if course["purchase_date"] == nil {
+				fix(i, "missing purchase_date", "set it to empty")
+			} else {
+				fix(i, fmt.Sprintf("purchase_date isn't a string (%T)", course["purchase_date"]), "cleared it")
+			}
+		}
+		courses = append(courses, map[string]any{"id": id, "purchase_date": purchaseDate})
+	}
+	return courses, repairs
+}
+
+// repairInteractionHistory returns interaction_history as a list of objects:
+//   - a non-list value is emptied, and entries that aren't objects are dropped
+//   - an entry without an action becomes a "user_query" when it has a query, otherwise it's dropped
+//   - course_id, query and timestamp fields that aren't strings are removed
+func repairInteractionHistory(val any) (any, []StateRepair) {
+	var repairs []StateRepair
+	fix := func(index int, problem, fix string) {
+		repairs = append(repairs, StateRepair{Key: INTERACTION_HISTORY_KEY, Index: index, Problem: problem, Fix: fix})
+	}
+
+	entries, ok := toList(val)
+	if !ok {
+		fix(-1, fmt.Sprintf("not a list (%T)", val), "replaced it with an empty list")
+		return []map[string]any{}, repairs
+	}
+
+	history := make([]map[string]any, 0, len(entries))
+	for i, entry := range entries {
+		m, ok := entry.(map[string]any)
+		if !ok {
+			fix(i, fmt.Sprintf("not an object (%T)", entry), "dropped it")
+			continue
+		}
+
+		cleaned := make(map[string]any, len(m))
+		for k, v := range m {
+			cleaned[k] = v
+		}
+
+		for _, field := range []string{"course_id", "query", "timestamp"} {
+			if v, present := cleaned[field]; present {
+				if _, isString := v.(string); !isString {
+					fix(i, fmt.Sprintf("%s isn't a string (%T)", field, v), "removed "+field)
+					delete(cleaned, field)
+				}
+			}
+		}
+
+		if action, _ := cleaned["action"].(string); strings.TrimSpace(action) == "" {
+			if query, _ := cleaned["query"].(string); query != "" {
+				fix(i, "missing action", `set it to "user_query" since it has a query`)
+				cleaned["action"] = "user_query"
+			} else {
+				fix(i, "missing action", "dropped it")
+				continue
+			}
+		}
+		history = append(history, cleaned)
+	}
+	return history, repairs
+}
+
+// toList returns val's entries when it is a list, as stored fresh ([]map[string]any) or after a
+// database round trip ([]any)
+func toList(val any) ([]any, bool) {
+	switch v := val.(type) {
+	case []any:
+		return v, true
+	case []map[string]any:
+		entries := make([]any, 0, len(v))
+		for _, m := range v {
+			entries = append(entries, m)
+		}
+		return entries, true
+	case []string:
+		entries := make([]any, 0, len(v))
+		for _, s := range v {
+			entries = append(entries, s)
+		}
+		return entries, true
+	}
+	return nil, false
+}
+
+// NewRepairStateTool creates the repair_state tool for the root agent
+func NewRepairStateTool() (tool.Tool, error) {
+	repairStateTool, err := functiontool.New(
+		functiontool.Config{
+			Name: REPAIR_STATE,
+			Description: "Checks purchased_courses and interaction_history for malformed entries (e.g. a course without an id), " +
+				"fixes or drops them, saves the result and lists every change",
+		},
+		repairStateTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", REPAIR_STATE, err)
+	}
+	return repairStateTool, nil
+}
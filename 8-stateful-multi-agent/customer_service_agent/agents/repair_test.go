@@ -0,0 +1,131 @@
+package agents
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestRepairStateLeavesWellFormedStateAlone(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses": []map[string]any{
+			{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+		},
+		"interaction_history": historyEntries(3),
+	}, true)
+	before := state.Values()
+
+	results := repairState(state)
+
+	if results.Status != "ok" || len(results.Repairs) != 0 || len(results.Repaired) != 0 {
+		t.Errorf("repairState() = %+v, want ok without repairs", results)
+	}
+	if !reflect.DeepEqual(state.Values(), before) {
+		t.Errorf("state = %v, want it unchanged", state)
+	}
+}
+
+func TestRepairStateFixesPurchasedCourses(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses": []any{
+			map[string]any{"id": " AI_Marketing_Platform ", "purchase_date": "2024-12-03 15:30:00"},
+			map[string]any{"purchase_date": "2024-12-04 10:00:00"}, // no id
+			map[string]any{"id": 42}, // id isn't a string
+			"retired_course",         // plain id
+			map[string]any{"id": "ai_marketing_platform", "purchase_date": "2024-12-05 09:00:00"}, // duplicate
+			map[string]any{"id": "agent_course", "purchase_date": 20241206},
+			[]any{"not", "a", "course"},
+		},
+	}, true)
+
+	results := repairState(state)
+
+	if results.Status != "repaired" || !reflect.DeepEqual(results.Repaired, []string{PURCHASED_COURSES_KEY}) {
+		t.Fatalf("repairState() = %+v, want purchased_courses repaired", results)
+	}
+	// normalized id, missing id, non-string id, plain string, duplicate, non-string date, non-object
+	if len(results.Repairs) != 7 {
+		t.Errorf("repairs = %+v, want 7", results.Repairs)
+	}
+
+	want := []Course{
+		{ID: "ai_marketing_platform", PurchaseDate: "2024-12-03 15:30:00"},
+		{ID: "retired_course"},
+		{ID: "agent_course"},
+	}
+	if got := getPurchasedCourses(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("purchased courses = %+v, want %+v", got, want)
+	}
+
+	// A second pass finds nothing left to repair
+	if again := repairState(state); again.Status != "ok" {
+		t.Errorf("second repairState() = %+v, want ok", again)
+	}
+}
+
+func TestRepairStateWrapsSingleCourse(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses": map[string]any{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+	}, true)
+
+	results := repairState(state)
+
+	if len(results.Repairs) != 1 || results.Repairs[0].Index != -1 {
+		t.Errorf("repairs = %+v, want one repair of the whole value", results.Repairs)
+	}
+	if _, owned := ownsCourse(state, "ai_marketing_platform"); !owned {
+		t.Error("the wrapped course isn't owned after the repair")
+	}
+}
+
+func TestRepairStateFixesInteractionHistory(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"interaction_history": []any{
+			map[string]any{"action": "purchase_course", "course_id": "ai_marketing_platform", "timestamp": "2024-12-03 15:30:00"},
+			"user asked about refunds",                                         // not an object
+			map[string]any{"query": "What's the refund policy?"},               // no action but a query
+			map[string]any{"timestamp": "2024-12-04 10:00:00"},                 // no action and nothing else
+			map[string]any{"action": "refund_course", "course_id": []any{"x"}}, // course_id isn't a string
+			map[string]any{"action": "user_query", "timestamp": 1733300000},    // timestamp isn't a string
+		},
+	}, true)
+
+	results := repairState(state)
+
+	if results.Status != "repaired" || !reflect.DeepEqual(results.Repaired, []string{INTERACTION_HISTORY_KEY}) {
+		t.Fatalf("repairState() = %+v, want interaction_history repaired", results)
+	}
+	if len(results.Repairs) != 5 {
+		t.Errorf("repairs = %+v, want 5", results.Repairs)
+	}
+
+	want := []map[string]any{
+		{"action": "purchase_course", "course_id": "ai_marketing_platform", "timestamp": "2024-12-03 15:30:00"},
+		{"action": "user_query", "query": "What's the refund policy?"},
+		{"action": "refund_course"},
+		{"action": "user_query"},
+	}
+	if got := getInteractionHistory(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("interaction history = %v, want %v", got, want)
+	}
+}
+
+func TestRepairStateReplacesNonListValues(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"purchased_courses":   "ai_marketing_platform",
+		"interaction_history": 7,
+	}, true)
+
+	results := repairState(state)
+
+	if len(results.Repaired) != 2 || len(results.Repairs) != 2 {
+		t.Fatalf("repairState() = %+v, want both keys replaced", results)
+	}
+	if courses := getPurchasedCourses(state); len(courses) != 0 {
+		t.Errorf("purchased courses = %+v, want none", courses)
+	}
+	if history := getInteractionHistory(state); len(history) != 0 {
+		t.Errorf("interaction history = %v, want none", history)
+	}
+}
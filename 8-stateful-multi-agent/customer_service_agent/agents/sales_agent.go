@@ -3,6 +3,7 @@ package agents
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"google.golang.org/adk/agent"
@@ -10,6 +11,8 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
 )
 
 // ===== Course Structure =====
@@ -20,6 +23,16 @@ type Course struct {
 	PurchaseDate string `json:"purchase_date"`
 }
 
+// courseNames maps the id of every course on offer to its display name
+var courseNames = map[string]string{
+	"ai_marketing_platform": "Fullstack AI Marketing Platform",
+}
+
+// coursePrices maps course ids to their price in USD
+var coursePrices = map[string]float64{
+	"ai_marketing_platform": 149,
+}
+
 // ===== Sales Agent Tool Structures =====
 
 type purchaseCourseArgs struct{}
@@ -36,56 +49,27 @@ type purchaseCourseResults struct {
 // purchaseCourse simulates purchasing the AI Marketing Platform course
 // Updates state with purchase information
 func purchaseCourse(ctx tool.Context, input purchaseCourseArgs) (purchaseCourseResults, error) {
-	fmt.Println("--- Tool: purchase_course called ---")
+	slog.Info("tool called", "tool", "purchase_course", "agent", ctx.AgentName(), "session_id", ctx.SessionID())
 
 	courseID := "ai_marketing_platform"
 	currentTime := time.Now().Format("2006-01-02 15:04:05")
 
 	state := ctx.State()
 
-	// Get current purchased courses
-	var purchasedCourses []Course
-	if val, err := state.Get("purchased_courses"); err == nil {
-		if courses, ok := val.([]interface{}); ok {
-			for _, c := range courses {
-				if courseMap, ok := c.(map[string]interface{}); ok {
-					course := Course{
-						ID:           fmt.Sprintf("%v", courseMap["id"]),
-						PurchaseDate: fmt.Sprintf("%v", courseMap["purchase_date"]),
-					}
-					purchasedCourses = append(purchasedCourses, course)
-				}
-			}
-		}
-	}
-
 	// Check if user already owns the course
-	for _, course := range purchasedCourses {
-		if course.ID == courseID {
-			return purchaseCourseResults{
-				Status:  "error",
-				Message: "You already own this course!",
-			}, nil
-		}
+	if _, ok := ownsCourse(state, courseID); ok {
+		return purchaseCourseResults{
+			Status:  "error",
+			Message: "You already own this course!",
+		}, nil
 	}
 
-	// Add the new course
-	purchasedCourses = append(purchasedCourses, Course{
+	// Add the new course and update purchased courses in state
+	purchasedCourses := append(getPurchasedCourses(state), Course{
 		ID:           courseID,
 		PurchaseDate: currentTime,
 	})
-
-	// Convert to []map[string]any for state storage
-	var coursesForState []map[string]any
-	for _, course := range purchasedCourses {
-		coursesForState = append(coursesForState, map[string]any{
-			"id":            course.ID,
-			"purchase_date": course.PurchaseDate,
-		})
-	}
-
-	// Update purchased courses in state
-	state.Set("purchased_courses", coursesForState)
+	state.Set(PURCHASED_COURSES_KEY, coursesToState(purchasedCourses))
 
 	// Get current interaction history
 	var interactionHistory []map[string]interface{}
@@ -109,6 +93,11 @@ func purchaseCourse(ctx tool.Context, input purchaseCourseArgs) (purchaseCourseR
 	// Update interaction history in state
 	state.Set("interaction_history", interactionHistory)
 
+	// Let other agents (course support) know about the purchase
+	if _, err := PublishEvent(state, ctx.AgentName(), EVENT_PURCHASE_COURSE, map[string]any{"course_id": courseID}); err != nil {
+		slog.Warn("failed to publish event", "event", EVENT_PURCHASE_COURSE, "session_id", ctx.SessionID(), "error", err)
+	}
+
 	return purchaseCourseResults{
 		Status:    "success",
 		Message:   "Successfully purchased the AI Marketing Platform course!",
@@ -120,7 +109,7 @@ func purchaseCourse(ctx tool.Context, input purchaseCourseArgs) (purchaseCourseR
 // ===== Agent Creation =====
 
 // NewSalesAgent creates a specialized agent for course sales
-func NewSalesAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
+func NewSalesAgent(ctx context.Context, mdl model.LLM, persona string, emitter *agentutil.EventEmitter, prices agentutil.PriceTable) (agent.Agent, error) {
 	// Create purchase_course tool
 	purchaseCourseTool, err := functiontool.New(
 		functiontool.Config{
@@ -132,8 +121,14 @@ func NewSalesAgent(ctx context.Context, mdl model.LLM) (agent.Agent, error) {
 		return nil, fmt.Errorf("failed to create purchase_course tool: %w", err)
 	}
 
+	// Create the shared owns_course tool
+	ownsCourseTool, err := newOwnsCourseTool()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create sales agent
-	salesAgent, err := llmagent.New(llmagent.Config{
+	salesAgent, err := agentutil.NewLLMAgent(agentutil.WithCostEstimate(agentutil.WithEventEmitter(WithPersona(llmagent.Config{
 		Name:        "sales_agent",
 		Model:       mdl,
 		Description: "Sales agent for the AI Marketing Platform course",
@@ -144,9 +139,9 @@ for the Fullstack AI Marketing Platform course.
 Name: {user_name}
 </user_info>
 
-<purchase_info>
-Purchased Courses: {purchased_courses}
-</purchase_info>
+<history_summary>
+{history_summary?}
+</history_summary>
 
 <interaction_history>
 {interaction_history}
@@ -159,9 +154,8 @@ Course Details:
 - Includes: 6 weeks of group support with weekly coaching calls
 
 When interacting with users:
-1. Check if they already own the course (check purchased_courses above)
-   - Course information is stored as objects with "id" and "purchase_date" properties
-   - The course id is "ai_marketing_platform"
+1. Check if they already own the course: call owns_course with course_id "ai_marketing_platform"
+   - Rely on its "owned" result rather than guessing from the conversation
 2. If they own it:
    - Remind them they have access
    - Ask if they need help with any specific part
@@ -177,14 +171,16 @@ When interacting with users:
 
 4. After any interaction:
    - The state will automatically track the interaction
-   - Be ready to hand off to course support after purchase
+   - Be ready to hand off to course support after purchase; course support will offer
+     onboarding for the new course
 
 Remember:
 - Be helpful but not pushy
 - Focus on the value and practical skills they'll gain
 - Emphasize the hands-on nature of building a real AI application`,
-		Tools: []tool.Tool{purchaseCourseTool},
-	})
+		Tools:                []tool.Tool{ownsCourseTool, purchaseCourseTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{CompactInteractionHistory},
+	}, persona), emitter), prices))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sales agent: %w", err)
 	}
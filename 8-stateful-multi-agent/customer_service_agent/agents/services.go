@@ -0,0 +1,112 @@
+package agents
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// ===== Available Services =====
+//
+// The root agent checks which specialized agents the user can use right now with the
+// available_services tool instead of reasoning over purchased_courses in its prompt. Policy,
+// sales and order help are always available; course support only once the user owns a course.
+
+const AVAILABLE_SERVICES = "available_services"
+
+type availableServicesArgs struct{}
+
+// Service is one specialized agent and whether the user can use it
+type Service struct {
+	Agent       string   `json:"agent"` // agent name, as passed to handoff_to
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Available   bool     `json:"available"`
+	Reason      string   `json:"reason,omitempty"`  // why it isn't available
+	Courses     []string `json:"courses,omitempty"` // course ids it can help with, for course support
+}
+
+type availableServicesResults struct {
+	Status      string    `json:"status"`
+	Services    []Service `json:"services"`  // every service, available or not
+	Available   []string  `json:"available"` // agent names of the available services
+	Unavailable []string  `json:"unavailable"`
+	Message     string    `json:"message"`
+}
+
+// availableServicesTool reports which specialized agents the user can currently use
+func availableServicesTool(ctx tool.Context, input availableServicesArgs) (availableServicesResults, error) {
+	slog.Info("tool called", "tool", AVAILABLE_SERVICES, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+	return availableServices(ctx.State()), nil
+}
+
+// availableServices lists the specialized agents in routing order, with course support
+// available only when the user owns at least one known course
+func availableServices(state session.ReadonlyState) availableServicesResults {
+	var owned []string
+	for _, course := range getPurchasedCourses(state) {
+		if _, known := courseNames[course.ID]; known {
+			owned = append(owned, course.ID)
+		}
+	}
+
+	courseSupport := Service{
+		Agent:       "course_support",
+		Name:        "Course Support",
+		Description: "Help with the content of courses the user owns",
+		Available:   len(owned) > 0,
+		Courses:     owned,
+	}
+	if !courseSupport.Available {
+		courseSupport.Reason = "The user hasn't purchased a course yet"
+	}
+
+	results := availableServicesResults{
+		Status: "success",
+		Services: []Service{
+			{Agent: "policy_agent", Name: "Policies", Description: "Community guidelines, course policies and the refund policy", Available: true},
+			{Agent: "sales_agent", Name: "Sales", Description: "Course information, prices and purchases", Available: true},
+			{Agent: "order_agent", Name: "Orders", Description: "Purchase history, invoices and refunds", Available: true},
+			courseSupport,
+		},
+		Available:   []string{},
+		Unavailable: []string{},
+	}
+	for _, s := range results.Services {
+		if s.Available {
+			results.Available = append(results.Available, s.Agent)
+		} else {
+			results.Unavailable = append(results.Unavailable, s.Agent)
+		}
+	}
+
+	if courseSupport.Available {
+		names := make([]string, 0, len(owned))
+		for _, id := range owned {
+			names = append(names, courseName(id))
+		}
+		results.Message = fmt.Sprintf("All services are available, including course support for %s", strings.Join(names, ", "))
+	} else {
+		results.Message = "Policies, sales and orders are available. Course support isn't, because the user hasn't purchased a course yet"
+	}
+	return results
+}
+
+// NewAvailableServicesTool creates the available_services tool for the root agent
+func NewAvailableServicesTool() (tool.Tool, error) {
+	availableServicesTool, err := functiontool.New(
+		functiontool.Config{
+			Name: AVAILABLE_SERVICES,
+			Description: "Lists the specialized agents the user can use right now, based on their purchases. " +
+				"Course support is only available for courses the user owns",
+		},
+		availableServicesTool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", AVAILABLE_SERVICES, err)
+	}
+	return availableServicesTool, nil
+}
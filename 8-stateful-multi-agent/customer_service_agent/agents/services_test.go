@@ -0,0 +1,41 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestAvailableServicesWithoutPurchases(t *testing.T) {
+	results := availableServices(agenttest.NewState(map[string]any{"purchased_courses": []any{}}, true))
+
+	if strings.Join(results.Available, ",") != "policy_agent,sales_agent,order_agent" {
+		t.Errorf("available = %v, want policy, sales and order", results.Available)
+	}
+	if strings.Join(results.Unavailable, ",") != "course_support" {
+		t.Errorf("unavailable = %v, want course_support", results.Unavailable)
+	}
+	if s := results.Services[3]; s.Available || s.Reason == "" {
+		t.Errorf("course support = %+v, want unavailable with a reason", s)
+	}
+}
+
+func TestAvailableServicesWithPurchase(t *testing.T) {
+	results := availableServices(agenttest.NewState(map[string]any{
+		"purchased_courses": []map[string]any{
+			{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+			{"id": "retired_course", "purchase_date": "2023-01-01 09:00:00"}, // no longer offered
+		},
+	}, true))
+
+	if len(results.Available) != 4 || len(results.Unavailable) != 0 {
+		t.Errorf("available = %v, unavailable = %v, want all four available", results.Available, results.Unavailable)
+	}
+	if s := results.Services[3]; !s.Available || strings.Join(s.Courses, ",") != "ai_marketing_platform" {
+		t.Errorf("course support = %+v, want available for ai_marketing_platform only", s)
+	}
+	if !strings.Contains(results.Message, "Fullstack AI Marketing Platform") {
+		t.Errorf("message = %q, want the course name", results.Message)
+	}
+}
@@ -0,0 +1,69 @@
+package agents
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+// TestStateSnapshotKeepsCustomerData exports a populated session, written the way the tools
+// write it (typed lists, int counts), imports it back and checks every reader sees the same data
+func TestStateSnapshotKeepsCustomerData(t *testing.T) {
+	history := historyEntries(HISTORY_MAX_ENTRIES + 2)
+	stats := summarizeHistory(historyStats{}, history[:2])
+	state := agenttest.NewState(map[string]any{
+		"user_name": "Muchlis",
+		PURCHASED_COURSES_KEY: coursesToState([]Course{
+			{ID: "ai_marketing_platform", PurchaseDate: "2024-12-03 15:30:00"},
+		}),
+		INTERACTION_HISTORY_KEY: history[2:],
+		HISTORY_SUMMARY_KEY:     stats.String(),
+		HISTORY_STATS_KEY:       stats.toState(),
+	}, true)
+	if _, err := PublishEvent(state, "sales_agent", EVENT_PURCHASE_COURSE, map[string]any{"course_id": "ai_marketing_platform"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := MarkEventHandled(state, GetEvents(state)[0].ID, "course_support"); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := toolutil.NewStateSnapshot(state, "customer_service", "u1", "s1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := toolutil.WriteStateSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	read, err := toolutil.ReadStateSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := agenttest.NewState(read.State, false)
+
+	if got, want := getPurchasedCourses(imported), getPurchasedCourses(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("purchased courses = %+v, want %+v", got, want)
+	}
+	if _, owned := ownsCourse(imported, "ai_marketing_platform"); !owned {
+		t.Error("ai_marketing_platform isn't owned after the import")
+	}
+	if got, want := getInteractionHistory(imported), getInteractionHistory(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("interaction history = %v, want %v", got, want)
+	}
+	if got, want := getHistoryStats(imported), getHistoryStats(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("history stats = %+v, want %+v", got, want)
+	}
+	if got, want := findHistory(imported, "", 0), findHistory(state, "", 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("get_history = %+v, want %+v", got, want)
+	}
+	if got, want := GetEvents(imported), GetEvents(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+	if got, want := availableServices(imported), availableServices(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("available services = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package agents
+
+import (
+	"google.golang.org/adk/session"
+)
+
+// StateSummary is a compact view of a customer's session state, returned by the JSON API
+// alongside each response
+type StateSummary struct {
+	UserName         string   `json:"user_name"`
+	PurchasedCourses []Course `json:"purchased_courses"`
+	ActiveCourse     string   `json:"active_course,omitempty"`
+	Interactions     int      `json:"interactions"` // including those folded into history_summary
+}
+
+// SummarizeState reads the summary from session state; missing keys leave their zero value
+func SummarizeState(state session.ReadonlyState) StateSummary {
+	summary := StateSummary{
+		PurchasedCourses: getPurchasedCourses(state),
+		ActiveCourse:     getActiveCourse(state),
+		Interactions:     len(getInteractionHistory(state)) + getHistoryStats(state).Count,
+	}
+	if name, err := state.Get("user_name"); err == nil {
+		summary.UserName, _ = name.(string)
+	}
+	if summary.PurchasedCourses == nil {
+		summary.PurchasedCourses = []Course{}
+	}
+	return summary
+}
@@ -0,0 +1,29 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestSummarizeState(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"user_name":             "Muchlis",
+		"purchased_courses":     []map[string]any{{"id": "ai_marketing_platform", "purchase_date": "2025-01-02 10:00:00"}},
+		ACTIVE_COURSE_KEY:       "ai_marketing_platform",
+		INTERACTION_HISTORY_KEY: historyEntries(3),
+		HISTORY_STATS_KEY:       map[string]any{"count": 12},
+	}, true)
+
+	summary := SummarizeState(state)
+	if summary.UserName != "Muchlis" || summary.ActiveCourse != "ai_marketing_platform" || summary.Interactions != 15 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if len(summary.PurchasedCourses) != 1 || summary.PurchasedCourses[0].ID != "ai_marketing_platform" {
+		t.Errorf("purchased courses = %+v", summary.PurchasedCourses)
+	}
+
+	if empty := SummarizeState(agenttest.NewState(nil, true)); empty.PurchasedCourses == nil || empty.Interactions != 0 {
+		t.Errorf("empty state summary = %+v, want no courses and no interactions", empty)
+	}
+}
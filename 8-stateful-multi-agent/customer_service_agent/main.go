@@ -1,12 +1,19 @@
 // Package main demonstrates a stateful multi-agent system in ADK.
 // This example combines persistent state management with multi-agent delegation
 // for a customer service system that remembers user information and interactions.
+//
+// Operational logs use the shared slog setup (internal/logging), configured through
+// LOG_FORMAT (text/json) and LOG_LEVEL; user-facing banners are still printed with fmt.
+//
+// With -serve <addr> the agent is served as a JSON API (POST /message) instead of through
+// the launcher; see server.go.
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -14,7 +21,7 @@ import (
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
@@ -22,24 +29,103 @@ import (
 	"google.golang.org/adk/cmd/launcher/full"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/session/database"
+	"google.golang.org/adk/tool"
 
 	"github.com/muchlist/agent-dev-kit/8-stateful-multi-agent/customer_service_agent/agents"
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+	"github.com/muchlist/agent-dev-kit/internal/logging"
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 const (
-	APP_NAME   = "customer_service"
-	MODEL_NAME = "gemini-2.0-flash"
-	DB_FILE    = "./customer_service_data.db"
+	APP_NAME            = "customer_service"
+	MODEL_NAME          = "gemini-2.0-flash"
+	FALLBACK_MODEL_NAME = "gemini-2.0-flash-lite" // used when MODEL_NAME is rate limited or unavailable
+	DB_FILE             = "./customer_service_data.db"
+	EXPORT_DIR          = "./exports" // where export_conversation writes transcripts
 )
 
 // ===== Customer Service Agent Creation =====
 
 // createCustomerServiceAgent creates the root customer service agent that coordinates specialized agents
-func createCustomerServiceAgent(_ context.Context, mdl model.LLM, policyAgent, salesAgent, courseSupportAgent, orderAgent agent.Agent) (agent.Agent, error) {
+func createCustomerServiceAgent(_ context.Context, mdl model.LLM, persona string, sessionService session.Service, emitter *agentutil.EventEmitter, prices agentutil.PriceTable, policyAgent, salesAgent, courseSupportAgent, orderAgent agent.Agent) (agent.Agent, error) {
+	// Create export_conversation tool; it reads the current session's events from the session service
+	exportConversationTool, err := toolutil.NewExportConversationTool(sessionService, EXPORT_DIR)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create export_state and import_state tools; state snapshots go next to the transcripts
+	exportStateTool, err := toolutil.NewExportStateTool(EXPORT_DIR)
+	if err != nil {
+		return nil, err
+	}
+	importStateTool, err := toolutil.NewImportStateTool(sessionService, EXPORT_DIR)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create reset_state tool; only bookkeeping keys can be reset, never user_name or purchased_courses
+	resetStateTool, err := toolutil.NewResetStateTool(map[string]any{
+		agents.INTERACTION_HISTORY_KEY: []any{},
+		agents.HISTORY_SUMMARY_KEY:     "",
+		agents.HISTORY_STATS_KEY:       map[string]any{},
+		agents.EVENTS_KEY:              []any{},
+		agentutil.COST_ESTIMATE_KEY:    map[string]any{},
+		toolutil.HANDOFF_LOG_KEY:       []any{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create handoff_to tool; delegation goes through it so every handoff is validated and recorded
+	subAgents := []agent.Agent{policyAgent, salesAgent, courseSupportAgent, orderAgent}
+	handoffTool, err := toolutil.NewHandoffTool(subAgents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create available_services tool; decides from purchased_courses whether course support is offered
+	availableServicesTool, err := agents.NewAvailableServicesTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create repair_state tool; fixes or drops malformed purchased_courses and interaction_history entries
+	repairStateTool, err := agents.NewRepairStateTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create due_follow_ups tool; returns reminders such as the re-enrollment follow-up scheduled after a refund
+	dueFollowUpsTool, err := agents.NewDueFollowUpsTool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create submit_feedback and feedback_summary tools; ratings are kept in state, so the database
+	// session service persists them and the summary reads them back from all the user's sessions
+	submitFeedbackTool, err := agents.NewSubmitFeedbackTool()
+	if err != nil {
+		return nil, err
+	}
+	feedbackSummaryTool, err := agents.NewFeedbackSummaryTool(sessionService)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create translate tool; translates any text, e.g. the previous reply, with the agent's model
+	translateTool, err := toolutil.NewTranslateTool(mdl)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create customer service agent with all sub-agents
-	customerServiceAgent, err := llmagent.New(llmagent.Config{
+	customerServiceAgent, err := agentutil.NewLLMAgent(agentutil.WithCostEstimate(agentutil.WithEventEmitter(agents.WithPersona(llmagent.Config{
 		Name:        "customer_service",
 		Model:       mdl,
 		Description: "Customer service agent for AI Developer Accelerator community",
@@ -70,32 +156,85 @@ Purchased Courses: {purchased_courses}
 </purchase_info>
 
 **Interaction History:**
+history_summary summarizes older interactions; interaction_history lists the most recent ones.
+<history_summary>
+{history_summary?}
+</history_summary>
+
 <interaction_history>
 {interaction_history}
 </interaction_history>
 
-You have access to the following specialized agents:
+You have access to the following specialized agents (agent names in parentheses):
 
-1. Policy Agent
+1. Policy Agent (policy_agent)
    - For questions about community guidelines, course policies, refunds
    - Direct policy-related queries here
 
-2. Sales Agent
+2. Sales Agent (sales_agent)
    - For questions about purchasing the AI Marketing Platform course
    - Handles course purchases and updates state
    - Course price: $149
 
-3. Course Support Agent
+3. Course Support Agent (course_support)
    - For questions about course content
    - Only available for courses the user has purchased
-   - Check if a course with id "ai_marketing_platform" exists in the purchased courses before directing here
+   - Check with the available_services tool before directing here
 
-4. Order Agent
+4. Order Agent (order_agent)
    - For checking purchase history and processing refunds
    - Shows courses user has bought
    - Can process course refunds (30-day money-back guarantee)
    - References the purchased courses information
 
+**Available Services:**
+Call the available_services tool before offering or delegating to a service, and whenever the user
+asks what you can help with. It returns which agents the user can use right now (policy, sales and
+order are always available; course support only after a purchase) with a structured list. Only offer
+the available ones; never offer course support for a course the user hasn't purchased. If course
+support is unavailable, say so and offer the Sales Agent instead.
+
+**How to Delegate:**
+Always delegate by calling the handoff_to tool with the agent name and a one-sentence reason,
+e.g. handoff_to(agent="order_agent", reason="User asks for a refund of ai_marketing_platform").
+It records the handoff and transfers the conversation; don't call transfer_to_agent yourself.
+If it returns status "error", fix the agent name or add the reason as the message says and call it again.
+
+You also have the export_conversation tool. Use it when the user asks to save, export or download
+this conversation; pass a filename only if the user gives one, then tell them the returned path.
+
+You also have the export_state and import_state tools. Use export_state when the user (or support)
+asks to back up or save their account data, and tell them the returned path. Use import_state only
+when the user asks to restore a snapshot and names its file: it creates a new session with that data,
+so give them the new session_id and tell them to continue there; this conversation is unchanged.
+
+You also have the reset_state tool. Use it only when the user explicitly asks to clear or reset
+something, e.g. their interaction history; tell them what was cleared. It refuses keys that can't be
+reset (such as user_name or purchased_courses): explain that instead of working around it.
+
+You also have the repair_state tool. Use it when the user says their purchases or history look wrong
+or incomplete, or when the purchase or interaction information above looks malformed (e.g. a course
+without an id). Tell the user briefly what was repaired; if the status is "ok", nothing was wrong.
+
+You also have the due_follow_ups tool. Call it once at the start of a conversation, and when the
+user asks whether there is anything they should be reminded of. Mention each returned follow-up's
+message briefly before answering; if none are due, say nothing about follow-ups.
+
+**Feedback:**
+When the user's issue is resolved (e.g. a purchase, refund or question is done and they have nothing
+else), ask once: "How would you rate the help you got today, from 1 (poor) to 5 (excellent)? Any
+comments are welcome too." When they answer with a number, call submit_feedback with that rating and
+their comment if they gave one, then thank them. If it returns status "invalid_rating", tell them the
+rating must be a whole number from 1 to 5 and ask again; never pick a rating for them. If they'd rather
+not rate, don't ask again this conversation. Use feedback_summary when the user asks how they have rated
+us so far, and share the average and count.
+
+**Translation:**
+When the user asks for something in another language ("say that in Spanish"), call the translate tool
+with the full text of the reply they mean (usually your previous one) and the target language, then give
+them the translation as it is returned.
+If it returns status "error", tell them the translation failed and answer in English.
+
 Tailor your responses based on the user's purchase history and previous interactions.
 When the user hasn't purchased any courses yet, encourage them to explore the AI Marketing Platform.
 When the user has purchased courses, offer support for those specific courses.
@@ -107,15 +246,17 @@ When users express dissatisfaction or ask for a refund:
 
 **IMPORTANT ROUTING RULES:**
 - For purchases: DELEGATE to Sales Agent
-- For refunds or order history: DELEGATE to Order Agent
+- For refunds, order history, or seeing or cancelling follow-ups: DELEGATE to Order Agent
 - For course content help: DELEGATE to Course Support Agent
 - For policy questions: DELEGATE to Policy Agent
 - You are a COORDINATOR - always delegate to the appropriate specialist, never handle their tasks directly
 
 Always maintain a helpful and professional tone. If you're unsure which agent to delegate to,
 ask clarifying questions to better understand the user's needs.`,
-		SubAgents: []agent.Agent{policyAgent, salesAgent, courseSupportAgent, orderAgent},
-	})
+		SubAgents:            subAgents,
+		Tools:                []tool.Tool{handoffTool, availableServicesTool, exportConversationTool, exportStateTool, importStateTool, resetStateTool, repairStateTool, dueFollowUpsTool, submitFeedbackTool, feedbackSummaryTool, translateTool},
+		BeforeAgentCallbacks: []agent.BeforeAgentCallback{agents.CompactInteractionHistory},
+	}, persona), emitter), prices))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create customer service agent: %w", err)
 	}
@@ -126,45 +267,36 @@ ask clarifying questions to better understand the user's needs.`,
 // ===== Main Function =====
 
 func main() {
+	serve := flag.String("serve", "", "serve the JSON API on this address (e.g. :8081) instead of launching the web UI")
+	flag.Parse()
+
 	godotenv.Load()
+	logger := logging.Setup()
 	ctx := context.Background()
 
-	// Create the Gemini model
-	model, err := gemini.NewModel(ctx, MODEL_NAME, &genai.ClientConfig{
+	// Create the primary and fallback Gemini models
+	clientConfig := &genai.ClientConfig{
 		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
-	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
-	}
-
-	// Create all specialized agents
-	policyAgent, err := agents.NewPolicyAgent(ctx, model)
-	if err != nil {
-		log.Fatalf("Failed to create policy agent: %v", err)
-	}
-
-	salesAgent, err := agents.NewSalesAgent(ctx, model)
-	if err != nil {
-		log.Fatalf("Failed to create sales agent: %v", err)
 	}
-
-	courseSupportAgent, err := agents.NewCourseSupportAgent(ctx, model)
+	primaryModel, err := gemini.NewModel(ctx, MODEL_NAME, clientConfig)
 	if err != nil {
-		log.Fatalf("Failed to create course support agent: %v", err)
+		logging.Fatal(logger, "failed to create model", "model", MODEL_NAME, "error", err)
 	}
-
-	orderAgent, err := agents.NewOrderAgent(ctx, model)
+	fallbackModel, err := gemini.NewModel(ctx, FALLBACK_MODEL_NAME, clientConfig)
 	if err != nil {
-		log.Fatalf("Failed to create order agent: %v", err)
+		logging.Fatal(logger, "failed to create model", "model", FALLBACK_MODEL_NAME, "error", err)
 	}
 
-	// Create customer service manager agent
-	customerServiceAgent, err := createCustomerServiceAgent(ctx, model, policyAgent, salesAgent, courseSupportAgent, orderAgent)
+	// Requests go to the primary model; on rate limits, overloads or server errors they are
+	// retried on the fallback model, while auth failures and bad requests are returned as-is
+	model, err := modelutil.NewFallbackModel(primaryModel, fallbackModel)
 	if err != nil {
-		log.Fatalf("Failed to create customer service agent: %v", err)
+		logging.Fatal(logger, "failed to create fallback model", "error", err)
 	}
+	logger.Info("model created", "model", MODEL_NAME, "fallback_model", FALLBACK_MODEL_NAME)
 
 	// ===== Session Management Setup =====
+	// Set up before the agents, because the root agent's export_conversation tool reads sessions through it
 
 	// Create database session service with SQLite
 	// This properly persists state changes made by tools
@@ -172,27 +304,116 @@ func main() {
 		sqlite.Open(DB_FILE),
 		&gorm.Config{
 			PrepareStmt: true,
-			Logger:      logger.Default.LogMode(logger.Silent),
+			Logger:      gormlogger.Default.LogMode(gormlogger.Silent),
 		},
 	)
 	if err != nil {
-		log.Fatalf("Failed to create database session service: %v", err)
+		logging.Fatal(logger, "failed to create database session service", "db_file", DB_FILE, "error", err)
 	}
 
 	// Initialize database schema
 	if err := database.AutoMigrate(sessionService); err != nil {
-		log.Fatalf("Failed to auto-migrate database: %v", err)
+		logging.Fatal(logger, "failed to auto-migrate database", "db_file", DB_FILE, "error", err)
 	}
 
-	// Wrap session service to provide default initial state for new sessions
-	initialState := map[string]any{
-		"user_name":           "Muchlis",
-		"purchased_courses":   []any{},
-		"interaction_history": []any{},
+	// Wrap session service to provide default initial state for new sessions; initial_state.json,
+	// when present, overrides the built-in defaults key by key
+	initialState, fromFile, err := toolutil.LoadInitialState(toolutil.INITIAL_STATE_FILE, agents.DefaultInitialState(), agents.ValidateInitialState)
+	if err != nil {
+		logging.Fatal(logger, "failed to load initial state", "file", toolutil.INITIAL_STATE_FILE, "error", err)
+	}
+	if fromFile {
+		logger.Info("initial state loaded", "file", toolutil.INITIAL_STATE_FILE, "keys", len(initialState))
 	}
 	wrappedSessionService := &sessionServiceWithDefaults{
 		Service:      sessionService,
 		initialState: initialState,
+		logger:       logger,
+	}
+	logger.Info("session service ready", "backend", "sqlite", "db_file", DB_FILE)
+
+	// Every agent speaks with the same persona; CUSTOMER_SERVICE_PERSONA overrides the default
+	persona, err := agents.LoadPersona(os.Getenv)
+	if err != nil {
+		logging.Fatal(logger, "invalid persona", "error", err)
+	}
+	logger.Info("persona loaded", "custom", persona != agents.DEFAULT_PERSONA)
+
+	// With EVENT_SINK set (stdout, file:<path> or a webhook URL) every agent publishes its
+	// activity as JSON events; a failing sink is logged and never breaks a turn
+	eventSink, err := agentutil.LoadEventSink(os.Getenv)
+	if err != nil {
+		logging.Fatal(logger, "invalid event sink", "error", err)
+	}
+	var emitter *agentutil.EventEmitter
+	if eventSink != nil {
+		emitter = agentutil.NewEventEmitter(eventSink)
+		defer emitter.Close()
+		logger.Info("event emission enabled", "sink", fmt.Sprintf("%T", eventSink))
+	}
+
+	// Every agent estimates the cost of its model calls; MODEL_PRICES_FILE overrides the built-in prices
+	prices, err := agentutil.LoadPriceTable(os.Getenv)
+	if err != nil {
+		logging.Fatal(logger, "invalid model prices", "error", err)
+	}
+
+	// Create all specialized agents
+	policyAgent, err := agents.NewPolicyAgent(ctx, model, persona, emitter, prices)
+	if err != nil {
+		logging.Fatal(logger, "failed to create agent", "agent", "policy_agent", "error", err)
+	}
+
+	salesAgent, err := agents.NewSalesAgent(ctx, model, persona, emitter, prices)
+	if err != nil {
+		logging.Fatal(logger, "failed to create agent", "agent", "sales_agent", "error", err)
+	}
+
+	courseSupportAgent, err := agents.NewCourseSupportAgent(ctx, model, persona, emitter, prices)
+	if err != nil {
+		logging.Fatal(logger, "failed to create agent", "agent", "course_support", "error", err)
+	}
+
+	orderAgent, err := agents.NewOrderAgent(ctx, model, persona, emitter, prices)
+	if err != nil {
+		logging.Fatal(logger, "failed to create agent", "agent", "order_agent", "error", err)
+	}
+
+	// Create customer service manager agent
+	customerServiceAgent, err := createCustomerServiceAgent(ctx, model, persona, wrappedSessionService, emitter, prices, policyAgent, salesAgent, courseSupportAgent, orderAgent)
+	if err != nil {
+		logging.Fatal(logger, "failed to create agent", "agent", "customer_service", "error", err)
+	}
+
+	// Catch duplicate sub-agent names before they cause confusing routing
+	if err := agentutil.ValidateAgentTree(customerServiceAgent); err != nil {
+		logging.Fatal(logger, "invalid agent tree", "root_agent", customerServiceAgent.Name(), "error", err)
+	}
+
+	subAgentNames := make([]string, 0, len(customerServiceAgent.SubAgents()))
+	for _, subAgent := range customerServiceAgent.SubAgents() {
+		subAgentNames = append(subAgentNames, subAgent.Name())
+	}
+	logger.Info("agents created", "root_agent", customerServiceAgent.Name(), "sub_agents", subAgentNames)
+
+	// ===== Serve the JSON API =====
+
+	if *serve != "" {
+		r, err := runner.New(runner.Config{
+			AppName:        APP_NAME,
+			Agent:          customerServiceAgent,
+			SessionService: wrappedSessionService,
+		})
+		if err != nil {
+			logging.Fatal(logger, "failed to create runner", "error", err)
+		}
+		handler := newAPIHandler(r, wrappedSessionService, parseOrigins(os.Getenv(CORS_ALLOWED_ORIGINS_ENV)), logger)
+
+		fmt.Printf("\n🚀 Serving the customer service JSON API on %s (POST /message, Ctrl-C to stop)\n", *serve)
+		if err := serveAPI(ctx, *serve, handler, logger); err != nil {
+			logging.Fatal(logger, "json api failed", "addr", *serve, "error", err)
+		}
+		return
 	}
 
 	// ===== Launch with Web/API/WebUI =====
@@ -207,8 +428,8 @@ func main() {
 	}
 
 	l := full.NewLauncher()
-	if err := l.Execute(ctx, config, os.Args[1:]); err != nil {
-		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	if err := l.Execute(ctx, config, flag.Args()); err != nil {
+		logging.Fatal(logger, "run failed", "args", flag.Args(), "error", err, "usage", l.CommandLineSyntax())
 	}
 }
 
@@ -216,6 +437,7 @@ func main() {
 type sessionServiceWithDefaults struct {
 	session.Service
 	initialState map[string]any
+	logger       *slog.Logger
 }
 
 // Create wraps the Create method to ensure initial state is set
@@ -224,5 +446,13 @@ func (s *sessionServiceWithDefaults) Create(ctx context.Context, req *session.Cr
 	if len(req.State) == 0 {
 		req.State = s.initialState
 	}
-	return s.Service.Create(ctx, req)
+
+	resp, err := s.Service.Create(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to create session", "app_name", req.AppName, "user_id", req.UserID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("session created", "app_name", req.AppName, "user_id", req.UserID, "session_id", resp.Session.ID())
+	return resp, nil
 }
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/8-stateful-multi-agent/customer_service_agent/agents"
+)
+
+// ===== JSON API Server =====
+//
+// With -serve <addr> the agent is served as a small JSON API for embedding in a web app,
+// instead of through the launcher's web UI:
+//
+//	POST /message  {"user_id": "...", "session_id": "...", "text": "..."}
+//	            -> {"session_id": "...", "agent": "...", "response": "...", "state_summary": {...}}
+//
+// An empty or unknown session_id starts a new session with the default initial state.
+// CORS_ALLOWED_ORIGINS is a comma-separated list of origins browsers may call from. Unset, no
+// cross-origin calls are allowed; "*" allows any origin and has to be set explicitly.
+
+const (
+	CORS_ALLOWED_ORIGINS_ENV = "CORS_ALLOWED_ORIGINS"
+	MAX_MESSAGE_BYTES        = 64 << 10         // request body limit
+	MESSAGE_TIMEOUT          = 2 * time.Minute  // one agent run, including delegation to sub-agents
+	SHUTDOWN_TIMEOUT         = 30 * time.Second // how long in-flight messages get to finish on Ctrl-C
+)
+
+type messageRequest struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	Text      string `json:"text"`
+}
+
+type messageResponse struct {
+	SessionID    string              `json:"session_id"`
+	Agent        string              `json:"agent,omitempty"` // the agent that gave the final response
+	Response     string              `json:"response"`
+	StateSummary agents.StateSummary `json:"state_summary"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// apiServer answers /message with the runner; messages to the same session are handled one
+// at a time, so their state changes don't race
+type apiServer struct {
+	runner         *runner.Runner
+	sessionService session.Service
+	logger         *slog.Logger
+	locks          sessionLocks
+}
+
+func newAPIHandler(r *runner.Runner, sessionService session.Service, allowedOrigins []string, logger *slog.Logger) http.Handler {
+	s := &apiServer{runner: r, sessionService: sessionService, logger: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/message", s.handleMessage)
+	return withCORS(allowedOrigins, mux)
+}
+
+// serveAPI serves handler on addr until Ctrl-C (or SIGTERM), then stops accepting connections
+// and waits up to SHUTDOWN_TIMEOUT for in-flight messages to finish
+func serveAPI(ctx context.Context, addr string, handler http.Handler, logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	logger.Info("json api listening", "addr", addr)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("json api server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("json api shutting down", "timeout", SHUTDOWN_TIMEOUT.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("json api shutdown: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("json api server failed: %w", err)
+	}
+	return nil
+}
+
+func (s *apiServer) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "use POST"})
+		return
+	}
+
+	var req messageRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_MESSAGE_BYTES))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	req.UserID = strings.TrimSpace(req.UserID)
+	req.SessionID = strings.TrimSpace(req.SessionID)
+	switch {
+	case req.UserID == "":
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "user_id is required"})
+		return
+	case strings.TrimSpace(req.Text) == "":
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "text is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), MESSAGE_TIMEOUT)
+	defer cancel()
+
+	resp, status, err := s.reply(ctx, req)
+	if err != nil {
+		s.logger.Error("message failed", "user_id", req.UserID, "session_id", req.SessionID, "error", err)
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// reply runs the agent on one message; on failure it also returns the HTTP status to answer with
+func (s *apiServer) reply(ctx context.Context, req messageRequest) (messageResponse, int, error) {
+	sessionID, err := s.ensureSession(ctx, req.UserID, req.SessionID)
+	if err != nil {
+		return messageResponse{}, http.StatusInternalServerError, err
+	}
+	unlock := s.locks.lock(req.UserID + "/" + sessionID)
+	defer unlock()
+
+	resp := messageResponse{SessionID: sessionID}
+	var texts []string
+	msg := genai.NewContentFromText(req.Text, genai.RoleUser)
+	for event, err := range s.runner.Run(ctx, req.UserID, sessionID, msg, agent.RunConfig{}) {
+		if err != nil {
+			return messageResponse{}, http.StatusBadGateway, fmt.Errorf("agent run failed: %w", err)
+		}
+		if !event.IsFinalResponse() || event.Content == nil {
+			continue
+		}
+		if text := eventText(event.Content); text != "" {
+			texts = append(texts, text)
+			resp.Agent = event.Author
+		}
+	}
+	resp.Response = strings.Join(texts, "\n\n")
+
+	got, err := s.sessionService.Get(ctx, &session.GetRequest{AppName: APP_NAME, UserID: req.UserID, SessionID: sessionID})
+	if err != nil {
+		return messageResponse{}, http.StatusInternalServerError, fmt.Errorf("failed to read session state: %w", err)
+	}
+	resp.StateSummary = agents.SummarizeState(got.Session.State())
+	return resp, http.StatusOK, nil
+}
+
+// ensureSession returns sessionID if the session exists, otherwise creates it (with a new ID
+// when sessionID is empty) with the default initial state
+func (s *apiServer) ensureSession(ctx context.Context, userID, sessionID string) (string, error) {
+	if sessionID != "" {
+		if _, err := s.sessionService.Get(ctx, &session.GetRequest{AppName: APP_NAME, UserID: userID, SessionID: sessionID}); err == nil {
+			return sessionID, nil
+		}
+	}
+	created, err := s.sessionService.Create(ctx, &session.CreateRequest{AppName: APP_NAME, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return created.Session.ID(), nil
+}
+
+// eventText joins the text parts of a response, leaving out the model's thoughts
+func eventText(content *genai.Content) string {
+	var parts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" && !part.Thought {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, ""))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// ===== CORS =====
+
+// parseOrigins splits CORS_ALLOWED_ORIGINS; an unset value allows no other origins
+func parseOrigins(val string) []string {
+	var origins []string
+	for _, origin := range strings.Split(val, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// withCORS adds CORS headers for allowed origins and answers preflight requests. Requests from
+// other origins get no CORS headers, so browsers block them; non-browser clients are unaffected.
+func withCORS(allowed []string, next http.Handler) http.Handler {
+	anyOrigin := slices.Contains(allowed, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (anyOrigin || slices.Contains(allowed, origin)) {
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ===== Per-Session Locks =====
+
+// sessionLocks hands out one mutex per session key, dropped again when no request holds it
+type sessionLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *sessionLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sessionLock)
+	}
+	sl, ok := l.locks[key]
+	if !ok {
+		sl = &sessionLock{}
+		l.locks[key] = sl
+	}
+	sl.refs++
+	l.mu.Unlock()
+
+	sl.Lock()
+	return func() {
+		sl.Unlock()
+		l.mu.Lock()
+		if sl.refs--; sl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
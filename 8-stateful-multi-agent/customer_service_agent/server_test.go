@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// echoModel answers every request with a fixed text
+type echoModel struct{ text string }
+
+func (m echoModel) Name() string { return "echo" }
+
+func (m echoModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.text, genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+func newTestAPI(t *testing.T, origins []string) (http.Handler, session.Service) {
+	t.Helper()
+	a, err := llmagent.New(llmagent.Config{Name: "customer_service", Model: echoModel{text: "Hello Muchlis!"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := &sessionServiceWithDefaults{
+		Service:      session.InMemoryService(),
+		initialState: map[string]any{"user_name": "Muchlis", "purchased_courses": []any{}},
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	r, err := runner.New(runner.Config{AppName: APP_NAME, Agent: a, SessionService: sessions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newAPIHandler(r, sessions, origins, sessions.logger), sessions
+}
+
+func postMessage(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(body)))
+	return rec
+}
+
+func TestMessageCreatesAndReusesSession(t *testing.T) {
+	h, _ := newTestAPI(t, []string{"*"})
+
+	rec := postMessage(t, h, `{"user_id": "u1", "text": "hi"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var first messageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.SessionID == "" || first.Response != "Hello Muchlis!" || first.Agent != "customer_service" {
+		t.Errorf("response = %+v, want a new session answered by customer_service", first)
+	}
+	if first.StateSummary.UserName != "Muchlis" || first.StateSummary.PurchasedCourses == nil {
+		t.Errorf("state summary = %+v, want the default initial state", first.StateSummary)
+	}
+
+	rec = postMessage(t, h, `{"user_id": "u1", "session_id": "`+first.SessionID+`", "text": "again"}`)
+	var second messageResponse
+	json.Unmarshal(rec.Body.Bytes(), &second)
+	if rec.Code != http.StatusOK || second.SessionID != first.SessionID {
+		t.Errorf("second message = %d %+v, want the same session", rec.Code, second)
+	}
+}
+
+func TestMessageRejectsBadRequests(t *testing.T) {
+	h, _ := newTestAPI(t, []string{"*"})
+
+	for name, body := range map[string]string{
+		"not json":      `hi`,
+		"unknown field": `{"user_id": "u1", "text": "hi", "admin": true}`,
+		"no user":       `{"text": "hi"}`,
+		"no text":       `{"user_id": "u1", "text": "  "}`,
+	} {
+		if rec := postMessage(t, h, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/message", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", rec.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	h, _ := newTestAPI(t, parseOrigins("https://app.example.com/, https://admin.example.com"))
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/message", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("https://app.example.com")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("allowed preflight = %d %v", rec.Code, rec.Header())
+	}
+	if rec := preflight("https://evil.example.com"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin got CORS headers: %v", rec.Header())
+	}
+}
+
+func TestCORSDefaultsToSameOrigin(t *testing.T) {
+	for val, want := range map[string]string{"": "", " ": "", "*": "*"} {
+		h, _ := newTestAPI(t, parseOrigins(val))
+		req := httptest.NewRequest(http.MethodOptions, "/message", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("CORS_ALLOWED_ORIGINS=%q: Access-Control-Allow-Origin = %q, want %q", val, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 // - Log when agent processing starts and ends
 // - Track request counts across sessions
 // - Measure request duration
+// - Let the user reset the request counter with the shared reset_state tool
 package main
 
 import (
@@ -20,6 +21,9 @@ import (
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/tool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
 )
 
 // beforeAgentCallback runs when the agent starts processing a request
@@ -111,6 +115,15 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
+	// Create reset_state tool so the user can restart the request count without a new session.
+	// agent_name isn't listed, so it can't be reset
+	resetStateTool, err := toolutil.NewResetStateTool(map[string]any{
+		"request_counter": int64(0),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create reset_state tool: %v", err)
+	}
+
 	// Create the agent with before and after callbacks
 	a, err := llmagent.New(llmagent.Config{
 		Name:        "before_after_agent",
@@ -122,10 +135,12 @@ Your job is to:
 - Greet users politely
 - Respond to basic questions
 - Keep your responses friendly and concise
+- When the user asks to reset the request counter, call reset_state with key "request_counter"
 
 Current request counter: %d`, 1),
 		BeforeAgentCallbacks: []agent.BeforeAgentCallback{beforeAgentCallback},
 		AfterAgentCallbacks:  []agent.AfterAgentCallback{afterAgentCallback},
+		Tools:                []tool.Tool{resetStateTool},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
@@ -4,12 +4,18 @@
 // - Block certain tool calls completely (before_tool_callback)
 // - Enhance tool responses with additional information (after_tool_callback)
 // - Handle errors gracefully (after_tool_callback)
+// The tools look countries up in countries.json, embedded in the binary, so they work offline;
+// a countries_override.json in the working directory replaces it.
+// Run it with -dump-tools to print the JSON schemas the model sees for each tool.
 package main
 
 import (
 	"context"
+	_ "embed"
+	"flag"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"strings"
 
@@ -20,11 +26,87 @@ import (
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/cmd/launcher"
 	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/toolutil"
+)
+
+const (
+	GET_CAPITAL_CITY = "get_capital_city"
+	GET_COUNTRY_INFO = "get_country_info"
+
+	// COUNTRIES_FILE replaces the embedded country data when it exists in the working directory
+	COUNTRIES_FILE = "countries_override.json"
 )
 
+// ===== Country Data =====
+
+// countriesJSON lists every country with its capital, population (approximate 2023 estimates),
+// currency and region, so the tools work offline
+//
+//go:embed countries.json
+var countriesJSON []byte
+
+type country struct {
+	Name         string   `json:"name"`
+	Capital      string   `json:"capital"`
+	Population   int64    `json:"population"`
+	CurrencyCode string   `json:"currency_code"`
+	Currency     string   `json:"currency"`
+	Region       string   `json:"region"`
+	Aliases      []string `json:"aliases,omitempty"` // other names users say, e.g. USA for United States
+}
+
+// countryIndex finds countries by their name or an alias
+type countryIndex map[string]country
+
+// loadCountries reads the country list from path when it exists, and the embedded list otherwise.
+// It reports whether the file was used.
+func loadCountries(path string) (countryIndex, bool, error) {
+	var list []country
+	fromFile, err := toolutil.LoadToolData(path, countriesJSON, &list)
+	if err != nil {
+		return nil, fromFile, err
+	}
+	countries, err := indexCountries(list)
+	return countries, fromFile, err
+}
+
+// indexCountries indexes countries by normalized name and alias
+func indexCountries(list []country) (countryIndex, error) {
+	countries := countryIndex{}
+	for _, c := range list {
+		if c.Name == "" || c.Capital == "" {
+			return nil, fmt.Errorf("country data has an entry without a name or capital: %+v", c)
+		}
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			key := normalizeCountryName(name)
+			if other, exists := countries[key]; exists {
+				return nil, fmt.Errorf("country name %q is used by both %s and %s", name, other.Name, c.Name)
+			}
+			countries[key] = c
+		}
+	}
+	return countries, nil
+}
+
+// normalizeCountryName ignores case, periods, extra spaces and a leading "the", so "the U.S."
+// matches the alias "US"
+func normalizeCountryName(name string) string {
+	name = strings.ToLower(strings.NewReplacer(".", "", "’", "'").Replace(name))
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.TrimPrefix(name, "the ")
+}
+
+// lookup returns the country with the given name or alias
+func (idx countryIndex) lookup(name string) (country, bool) {
+	c, ok := idx[normalizeCountryName(name)]
+	return c, ok
+}
+
 // ===== Tool Structures =====
 
 type getCapitalCityArgs struct {
@@ -35,65 +117,85 @@ type getCapitalCityResults struct {
 	Result string `json:"result"`
 }
 
+type getCountryInfoArgs struct {
+	Country string `json:"country"` // name or common alias, e.g. "USA"
+}
+
+type getCountryInfoResults struct {
+	Status       string `json:"status"`            // success or not_found
+	Country      string `json:"country,omitempty"` // the country's name in the dataset
+	Capital      string `json:"capital,omitempty"`
+	Population   int64  `json:"population,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	CurrencyCode string `json:"currency_code,omitempty"`
+	Region       string `json:"region,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
 // getCapitalCity retrieves the capital city of a given country
-func getCapitalCity(ctx tool.Context, input getCapitalCityArgs) (getCapitalCityResults, error) {
-	country := input.Country
-	fmt.Printf("[TOOL] Executing get_capital_city tool with country: '%s'\n", country)
-	fmt.Printf("[TOOL] Input struct: %+v\n", input)
+func getCapitalCity(countries countryIndex) func(tool.Context, getCapitalCityArgs) (getCapitalCityResults, error) {
+	return func(ctx tool.Context, input getCapitalCityArgs) (getCapitalCityResults, error) {
+		fmt.Printf("[TOOL] Executing get_capital_city tool with country: '%s'\n", input.Country)
 
-	countryCapitals := map[string]string{
-		"united states": "Washington, D.C.",
-		"usa":           "Washington, D.C.",
-		"canada":        "Ottawa",
-		"france":        "Paris",
-		"germany":       "Berlin",
-		"japan":         "Tokyo",
-		"brazil":        "Brasília",
-		"australia":     "Canberra",
-		"india":         "New Delhi",
-	}
+		result := fmt.Sprintf("Capital not found for %s", input.Country)
+		if c, ok := countries.lookup(input.Country); ok {
+			result = c.Capital
+		}
 
-	// Use lowercase for comparison
-	result, exists := countryCapitals[strings.ToLower(country)]
-	if !exists {
-		result = fmt.Sprintf("Capital not found for %s", country)
+		fmt.Printf("[TOOL] Result: %s\n", result)
+		return getCapitalCityResults{Result: result}, nil
 	}
+}
 
-	fmt.Printf("[TOOL] Result: %s\n", result)
-	fmt.Printf("[TOOL] Returning: {Result: '%s'}\n", result)
+// getCountryInfo retrieves the capital, population, currency and region of a given country
+func getCountryInfo(countries countryIndex) func(tool.Context, getCountryInfoArgs) (getCountryInfoResults, error) {
+	return func(ctx tool.Context, input getCountryInfoArgs) (getCountryInfoResults, error) {
+		fmt.Printf("[TOOL] Executing get_country_info tool with country: '%s'\n", input.Country)
+
+		c, ok := countries.lookup(input.Country)
+		if !ok {
+			fmt.Printf("[TOOL] Country not found: '%s'\n", input.Country)
+			return getCountryInfoResults{
+				Status:       "not_found",
+				ErrorMessage: fmt.Sprintf("No country named %q; check the spelling or use its full name", input.Country),
+			}, nil
+		}
 
-	return getCapitalCityResults{Result: result}, nil
+		fmt.Printf("[TOOL] Found %s\n", c.Name)
+		return getCountryInfoResults{
+			Status:       "success",
+			Country:      c.Name,
+			Capital:      c.Capital,
+			Population:   c.Population,
+			Currency:     c.Currency,
+			CurrencyCode: c.CurrencyCode,
+			Region:       c.Region,
+		}, nil
+	}
 }
 
+// ===== Tool Callbacks =====
+//
+// Callbacks see args and results as maps keyed by the json tags of the tool structs ("country",
+// "result", "capital"): functiontool converts the model's args to the
+// struct and the struct back to the result map through JSON. Struct fields without a json tag
+// would appear under their Go names ("Country"), so every field here is tagged.
+
 // beforeToolCallback runs before a tool is executed
 // It can modify tool arguments or skip tool execution entirely
 func beforeToolCallback(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error) {
 	toolName := tool.Name()
 	fmt.Printf("[Callback] Before tool call for '%s'\n", toolName)
 	fmt.Printf("[Callback] Original args: %v\n", args)
-	fmt.Printf("[Callback] Args type: %T\n", args)
-	if args != nil {
-		fmt.Printf("[Callback] Args length: %d\n", len(args))
-		for k, v := range args {
-			fmt.Printf("[Callback]   %s: %v (type: %T)\n", k, v, v)
-		}
-	}
 
-	// Get the country argument
-	var country string
-	if countryVal, ok := args["country"]; ok {
-		if countryStr, ok := countryVal.(string); ok {
-			country = countryStr
-			fmt.Printf("[Callback] Found country argument: '%s'\n", country)
-		} else {
-			fmt.Printf("[Callback] Country argument exists but is not string: %T\n", countryVal)
-		}
-	} else {
-		fmt.Printf("[Callback] No 'country' argument found in args\n")
+	// Both tools take a country argument
+	if !isCountryTool(toolName) {
+		return nil, nil
 	}
+	country, _ := args["country"].(string)
 
 	// If someone asks about 'Merica, convert to United States
-	if toolName == "get_capital_city" && strings.ToLower(country) == "merica" {
+	if strings.ToLower(country) == "merica" {
 		fmt.Println("[Callback] Converting 'Merica to 'United States'")
 		args["country"] = "United States"
 		fmt.Printf("[Callback] Modified args: %v\n", args)
@@ -102,8 +204,11 @@ func beforeToolCallback(ctx tool.Context, tool tool.Tool, args map[string]any) (
 	}
 
 	// Skip the call completely for restricted countries
-	if toolName == "get_capital_city" && strings.ToLower(country) == "restricted" {
+	if strings.ToLower(country) == "restricted" {
 		fmt.Println("[Callback] Blocking restricted country")
+		if toolName == GET_COUNTRY_INFO {
+			return map[string]any{"status": "restricted", "error_message": "Access to this information has been restricted."}, nil
+		}
 		return map[string]any{"result": "Access to this information has been restricted."}, nil
 	}
 
@@ -121,56 +226,24 @@ func afterToolCallback(ctx tool.Context, tool tool.Tool, args, result map[string
 	fmt.Printf("[Callback] Original response: %v\n", result)
 	if err != nil {
 		fmt.Printf("[Callback] Error: %v\n", err)
+		// Return nil to keep the error
+		return nil, nil
 	}
 
-	// Detailed result debugging
-	if result == nil {
-		fmt.Printf("[Callback] Result is nil!\n")
-	} else {
-		fmt.Printf("[Callback] Result type: %T, length: %d\n", result, len(result))
-		for k, v := range result {
-			fmt.Printf("[Callback]   Result %s: %v (type: %T)\n", k, v, v)
-		}
-	}
-
-	// Extract the result - try both "result" and "Result" keys
-	var originalResult string
-	if result != nil {
-		// Try lowercase "result" first
-		if resultVal, ok := result["result"]; ok {
-			if resultStr, ok := resultVal.(string); ok {
-				originalResult = resultStr
-				fmt.Printf("[Callback] Found result using 'result' key: '%s'\n", originalResult)
-			} else {
-				fmt.Printf("[Callback] 'result' key exists but is not string: %T\n", resultVal)
-			}
-		} else if resultVal, ok := result["Result"]; ok {
-			// Try uppercase "Result" (from struct)
-			if resultStr, ok := resultVal.(string); ok {
-				originalResult = resultStr
-				fmt.Printf("[Callback] Found result using 'Result' key: '%s'\n", originalResult)
-			} else {
-				fmt.Printf("[Callback] 'Result' key exists but is not string: %T\n", resultVal)
-			}
-		} else {
-			fmt.Printf("[Callback] No 'result' or 'Result' key found in result map\n")
-		}
+	// get_capital_city returns the capital as result; get_country_info has it under capital
+	capitalKey := "result"
+	if toolName == GET_COUNTRY_INFO {
+		capitalKey = "capital"
 	}
-
-	fmt.Printf("[Callback] Extracted result: '%s'\n", originalResult)
+	originalResult, _ := result[capitalKey].(string)
 
 	// Add a note for any USA capital responses
-	if toolName == "get_capital_city" && strings.Contains(strings.ToLower(originalResult), "washington") {
+	if isCountryTool(toolName) && strings.Contains(strings.ToLower(originalResult), "washington") {
 		fmt.Println("[Callback] DETECTED USA CAPITAL - adding patriotic note!")
 
-		// Create a modified copy of the response - only modify the result field
-		modifiedResponse := map[string]any{}
-		for k, v := range result {
-			modifiedResponse[k] = v
-		}
-		// Update both possible keys to be safe
-		modifiedResponse["result"] = fmt.Sprintf("%s (Note: This is the capital of the USA. 🇺🇸)", originalResult)
-		modifiedResponse["Result"] = fmt.Sprintf("%s (Note: This is the capital of the USA. 🇺🇸)", originalResult)
+		// Return a modified copy of the response rather than changing the original
+		modifiedResponse := maps.Clone(result)
+		modifiedResponse[capitalKey] = fmt.Sprintf("%s (Note: This is the capital of the USA. 🇺🇸)", originalResult)
 
 		fmt.Printf("[Callback] Modified response: %v\n", modifiedResponse)
 		return modifiedResponse, nil
@@ -181,49 +254,105 @@ func afterToolCallback(ctx tool.Context, tool tool.Tool, args, result map[string
 	return nil, nil
 }
 
-func main() {
-	godotenv.Load()
-	ctx := context.Background()
+// isCountryTool reports whether the callbacks apply to a tool
+func isCountryTool(toolName string) bool {
+	return toolName == GET_CAPITAL_CITY || toolName == GET_COUNTRY_INFO
+}
 
-	// Create the Gemini model with API key from environment
-	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
-		APIKey: os.Getenv("GOOGLE_API_KEY"),
-	})
+// ===== Agent Creation =====
+
+// newCountryTools creates the get_capital_city and get_country_info tools on the country data in
+// path, or the embedded data when path doesn't exist
+func newCountryTools(path string) ([]tool.Tool, error) {
+	countries, fromFile, err := loadCountries(path)
 	if err != nil {
-		log.Fatalf("Failed to create model: %v", err)
+		return nil, err
+	}
+	if fromFile {
+		fmt.Printf("[DATA] Loaded %d country names from %s\n", len(countries), path)
 	}
 
-	// Create the tool from the function
 	getCapitalCityTool, err := functiontool.New(
 		functiontool.Config{
-			Name:        "get_capital_city",
+			Name:        GET_CAPITAL_CITY,
 			Description: "Retrieves the capital city of a given country",
 		},
-		getCapitalCity)
+		getCapitalCity(countries))
 	if err != nil {
-		log.Fatalf("Failed to create get_capital_city tool: %v", err)
+		return nil, fmt.Errorf("failed to create %s tool: %w", GET_CAPITAL_CITY, err)
 	}
 
-	// Create the agent with before and after tool callbacks
-	a, err := llmagent.New(llmagent.Config{
+	getCountryInfoTool, err := functiontool.New(
+		functiontool.Config{
+			Name: GET_COUNTRY_INFO,
+			Description: "Retrieves a country's capital, population, currency and region. Accepts the country's " +
+				"name or a common alias such as USA or UK; returns status not_found for unknown countries",
+		},
+		getCountryInfo(countries))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", GET_COUNTRY_INFO, err)
+	}
+
+	return []tool.Tool{getCapitalCityTool, getCountryInfoTool}, nil
+}
+
+// newToolCallbackAgent creates the agent with before and after tool callbacks
+func newToolCallbackAgent(mdl model.LLM, tools []tool.Tool) (agent.Agent, error) {
+	return llmagent.New(llmagent.Config{
 		Name:        "tool_callback_agent",
-		Model:       model,
-		Description: "An agent that demonstrates tool callbacks by looking up capital cities",
+		Model:       mdl,
+		Description: "An agent that demonstrates tool callbacks by looking up capital cities and country facts",
 		Instruction: `You are a helpful geography assistant.
 
 Your job is to:
 - Find capital cities when asked using the get_capital_city tool
+- Answer questions about a country's population, currency or region using the get_country_info tool
 - Use the exact country name provided by the user
 - ALWAYS return the EXACT result from the tool, without changing it
 - When reporting a capital, display it EXACTLY as returned by the tool
+- If get_country_info returns not_found, say you don't know that country and ask the user to check the name
 
 Examples:
 - "What is the capital of France?" → Use get_capital_city with country="France"
-- "Tell me the capital city of Japan" → Use get_capital_city with country="Japan"`,
-		Tools:                []tool.Tool{getCapitalCityTool},
-		BeforeToolCallbacks:  []llmagent.BeforeToolCallback{beforeToolCallback},
-		AfterToolCallbacks:   []llmagent.AfterToolCallback{afterToolCallback},
+- "Tell me the capital city of Japan" → Use get_capital_city with country="Japan"
+- "What currency does Brazil use?" → Use get_country_info with country="Brazil"`,
+		Tools:               tools,
+		BeforeToolCallbacks: []llmagent.BeforeToolCallback{beforeToolCallback},
+		AfterToolCallbacks:  []llmagent.AfterToolCallback{afterToolCallback},
 	})
+}
+
+func main() {
+	dumpTools := flag.Bool("dump-tools", false, "print the name, description and JSON schemas of every tool and exit")
+	flag.Parse()
+
+	godotenv.Load()
+	ctx := context.Background()
+
+	// Create the tools from the functions
+	tools, err := newCountryTools(COUNTRIES_FILE)
+	if err != nil {
+		log.Fatalf("Failed to create tools: %v", err)
+	}
+
+	// Show the tools exactly as they are declared to the model, without calling it
+	if *dumpTools {
+		if err := toolutil.DumpToolSchemas(os.Stdout, tools); err != nil {
+			log.Fatalf("Failed to dump tools: %v", err)
+		}
+		return
+	}
+
+	// Create the Gemini model with API key from environment
+	model, err := gemini.NewModel(ctx, "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey: os.Getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	// Create the agent with before and after tool callbacks
+	a, err := newToolCallbackAgent(model, tools)
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
@@ -234,7 +363,7 @@ Examples:
 	}
 
 	l := full.NewLauncher()
-	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+	if err = l.Execute(ctx, config, flag.Args()); err != nil {
 		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// capitalModel calls toolName for country, then answers with the tool's result. It keeps the
+// function response it was sent.
+type capitalModel struct {
+	toolName string
+	country  string
+	response map[string]any
+}
+
+func (m *capitalModel) Name() string { return "gemini-2.0-flash" }
+
+func (m *capitalModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		last := req.Contents[len(req.Contents)-1]
+		for _, part := range last.Parts {
+			if part.FunctionResponse != nil {
+				m.response = part.FunctionResponse.Response
+				yield(&model.LLMResponse{Content: genai.NewContentFromText("Here is the capital.", genai.RoleModel)}, nil)
+				return
+			}
+		}
+		call := genai.NewPartFromFunctionCall(m.toolName, map[string]any{"country": m.country})
+		yield(&model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{call}, genai.RoleModel)}, nil)
+	}
+}
+
+// askCountry runs one turn in which the model calls toolName for country, and returns the tool
+// result the model received
+func askCountry(t *testing.T, toolName, country string) map[string]any {
+	t.Helper()
+	ctx := context.Background()
+
+	tools, err := newCountryTools(filepath.Join(t.TempDir(), COUNTRIES_FILE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdl := &capitalModel{toolName: toolName, country: country}
+	a, err := newToolCallbackAgent(mdl, tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "capital_app", UserID: "test_user", SessionID: "test_session"}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(runner.Config{AppName: "capital_app", Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range r.Run(ctx, "test_user", "test_session", genai.NewContentFromText("What is the capital?", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return mdl.response
+}
+
+func TestCallbacksUseJSONTagKeys(t *testing.T) {
+	// The after callback only finds Washington under the "result" key
+	got := askCountry(t, GET_CAPITAL_CITY, "USA")
+	want := "Washington, D.C. (Note: This is the capital of the USA. 🇺🇸)"
+	if got["result"] != want {
+		t.Errorf("result = %v, want %q", got["result"], want)
+	}
+	if _, ok := got["Result"]; ok {
+		t.Errorf("response = %v, want no Result key", got)
+	}
+}
+
+func TestBeforeCallbackRewritesCountryArg(t *testing.T) {
+	// The before callback rewrites args["country"] before the tool reads it
+	if got := askCountry(t, GET_CAPITAL_CITY, "Merica"); got["result"] != "Washington, D.C. (Note: This is the capital of the USA. 🇺🇸)" {
+		t.Errorf("result = %v, want the USA capital", got["result"])
+	}
+}
+
+func TestGetCountryInfo(t *testing.T) {
+	got := askCountry(t, GET_COUNTRY_INFO, "japan")
+	if got["status"] != "success" || got["country"] != "Japan" || got["capital"] != "Tokyo" ||
+		got["currency_code"] != "JPY" || got["region"] != "Asia" || got["population"] == nil {
+		t.Errorf("response = %v, want Japan's info", got)
+	}
+
+	// The callbacks work with get_country_info too: 'Merica is rewritten and the capital noted
+	got = askCountry(t, GET_COUNTRY_INFO, "Merica")
+	if got["country"] != "United States" || got["capital"] != "Washington, D.C. (Note: This is the capital of the USA. 🇺🇸)" {
+		t.Errorf("response = %v, want the United States with a note on its capital", got)
+	}
+
+	got = askCountry(t, GET_COUNTRY_INFO, "Atlantis")
+	if got["status"] != "not_found" || got["error_message"] == nil || got["capital"] != nil {
+		t.Errorf("response = %v, want not_found", got)
+	}
+}
+
+func TestCountryLookup(t *testing.T) {
+	countries, fromFile, err := loadCountries(filepath.Join(t.TempDir(), COUNTRIES_FILE))
+	if err != nil || fromFile {
+		t.Fatalf("loadCountries() = %v, %v, want the embedded data", fromFile, err)
+	}
+
+	tests := []struct {
+		name string
+		want string // "" for not found
+	}{
+		{"France", "France"},
+		{"  FRANCE ", "France"},
+		{"USA", "United States"},
+		{"the U.S.", "United States"},
+		{"United States of America", "United States"},
+		{"UK", "United Kingdom"},
+		{"Ivory Coast", "Côte d'Ivoire"},
+		{"Côte d’Ivoire", "Côte d'Ivoire"},
+		{"the Netherlands", "Netherlands"},
+		{"Turkey", "Türkiye"},
+		{"Congo", "Republic of the Congo"},
+		{"DRC", "Democratic Republic of the Congo"},
+		{"Atlantis", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		c, ok := countries.lookup(tt.name)
+		if ok != (tt.want != "") || c.Name != tt.want {
+			t.Errorf("lookup(%q) = %q, %v; want %q", tt.name, c.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestCountryData(t *testing.T) {
+	var list []country
+	if err := json.Unmarshal(countriesJSON, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) < 195 {
+		t.Errorf("got %d countries, want every country", len(list))
+	}
+	for _, c := range list {
+		if c.Capital == "" || c.Population <= 0 || len(c.CurrencyCode) != 3 || c.Currency == "" || c.Region == "" {
+			t.Errorf("incomplete entry %+v", c)
+		}
+	}
+}
+
+func TestCountriesFileOverridesEmbedded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), COUNTRIES_FILE)
+	data := `[{"name": "Atlantis", "capital": "Poseidonia", "population": 1000, "currency_code": "ATL", "currency": "Orichalcum", "region": "Ocean", "aliases": ["Lost City"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	countries, fromFile, err := loadCountries(path)
+	if err != nil || !fromFile {
+		t.Fatalf("loadCountries() = %v, %v, want the file's data", fromFile, err)
+	}
+	if c, ok := countries.lookup("lost city"); !ok || c.Capital != "Poseidonia" {
+		t.Errorf("lookup(lost city) = %+v, %v; want Atlantis", c, ok)
+	}
+	if _, ok := countries.lookup("France"); ok {
+		t.Error("lookup(France) found a country that isn't in the file")
+	}
+}
+
+func TestIndexCountriesRejectsDuplicateNames(t *testing.T) {
+	list := []country{{Name: "Congo", Capital: "Brazzaville"}, {Name: "DR Congo", Capital: "Kinshasa", Aliases: []string{"congo"}}}
+	if _, err := indexCountries(list); err == nil {
+		t.Error("indexCountries accepted two countries named congo")
+	}
+}
@@ -0,0 +1,61 @@
+package agenttest
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
+)
+
+// record switches every test using ReplayModel to recording against Gemini, e.g.
+//
+//	GOOGLE_API_KEY=... go test ./1-basic-agent/greeting_agent -record
+var record = flag.Bool("record", false, "record model calls against Gemini instead of replaying them")
+
+// ReplayModel returns a model that replays the recording at path. With -record it calls the
+// Gemini model modelName instead and overwrites the recording.
+//
+// A missing recording skips the test rather than failing it: recordings are only made live with
+// -record, never written by hand, so after an instruction or tool change the stale file is
+// deleted until someone with an API key records it again.
+func ReplayModel(t testing.TB, path, modelName string) model.LLM {
+	t.Helper()
+	if !*record {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			t.Skipf("no recording at %s; record it with GOOGLE_API_KEY=... go test -record", path)
+		}
+		mdl, err := modelutil.NewRecordingModel(nil, modelutil.RECORD_MODE_REPLAY, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mdl
+	}
+
+	apiKey := os.Getenv("GOOGLE_API_KEY")
+	if apiKey == "" {
+		t.Fatal("-record needs GOOGLE_API_KEY")
+	}
+	inner, err := gemini.NewModel(context.Background(), modelName, &genai.ClientConfig{APIKey: apiKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdl, err := modelutil.NewRecordingModel(inner, modelutil.RECORD_MODE_RECORD, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mdl
+}
+
+// Recording reports whether the tests run with -record
+func Recording() bool {
+	return *record
+}
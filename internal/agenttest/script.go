@@ -0,0 +1,50 @@
+// Package agenttest provides helpers for testing agents end to end through a runner.
+package agenttest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// RunScript sends each turn to the runner as a user message, in order, and returns the
+// agent's final response to each one. The session must already exist. Any run error fails
+// the test, naming the turn it happened on.
+func RunScript(t testing.TB, r *runner.Runner, userID, sessionID string, turns []string) []string {
+	t.Helper()
+
+	responses := make([]string, 0, len(turns))
+	for i, turn := range turns {
+		var final string
+		for event, err := range r.Run(context.Background(), userID, sessionID, genai.NewContentFromText(turn, genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				t.Fatalf("turn %d (%q): %v", i+1, turn, err)
+			}
+			if text := finalText(event); text != "" {
+				final = text
+			}
+		}
+		responses = append(responses, final)
+	}
+	return responses
+}
+
+// finalText returns the text of a final response event, or "" for any other event
+func finalText(event *session.Event) string {
+	if event == nil || event.Content == nil || !event.IsFinalResponse() {
+		return ""
+	}
+	var parts []string
+	for _, part := range event.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "")
+}
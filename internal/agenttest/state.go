@@ -0,0 +1,68 @@
+package agenttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+
+	"google.golang.org/adk/session"
+)
+
+// State is an in-memory session.State for testing tools and callbacks without a runner
+type State struct {
+	values        map[string]any
+	jsonRoundTrip bool
+}
+
+var _ session.State = (*State)(nil)
+
+// NewState returns a State holding a copy of values. With jsonRoundTrip, the values and
+// everything Set later go through JSON, so they read back the way the database session
+// service returns them after a restart: numbers become float64, structs and typed maps become
+// map[string]any and slices become []any. values must be JSON-encodable then.
+func NewState(values map[string]any, jsonRoundTrip bool) *State {
+	s := &State{values: map[string]any{}, jsonRoundTrip: jsonRoundTrip}
+	for key, value := range values {
+		if err := s.Set(key, value); err != nil {
+			panic(fmt.Sprintf("agenttest.NewState: %v", err))
+		}
+	}
+	return s
+}
+
+func (s *State) Get(key string) (any, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+	return nil, session.ErrStateKeyNotExist
+}
+
+func (s *State) Set(key string, value any) error {
+	if s.jsonRoundTrip {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode state key %q: %w", key, err)
+		}
+		value = nil
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("failed to decode state key %q: %w", key, err)
+		}
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *State) All() iter.Seq2[string, any] {
+	return maps.All(s.values)
+}
+
+// Value returns the value stored under key, or nil when there is none
+func (s *State) Value(key string) any {
+	return s.values[key]
+}
+
+// Values returns a copy of everything stored
+func (s *State) Values() map[string]any {
+	return maps.Clone(s.values)
+}
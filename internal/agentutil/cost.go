@@ -0,0 +1,234 @@
+package agentutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
+)
+
+// ===== Model Cost Estimates =====
+//
+// An AfterModelCallback turns each response's token counts into an estimated USD cost and
+// adds it to the "cost_estimate" state key, so the running total is saved with the session.
+// Prices are per 1K tokens and can be overridden with a JSON file named by MODEL_PRICES_FILE:
+//
+//	{"gemini-2.0-flash": {"input_per_1k": 0.0001, "output_per_1k": 0.0004}, "default": {...}}
+//
+// These are estimates: they ignore free tiers, caching discounts and long-context pricing.
+
+const (
+	COST_ESTIMATE_KEY     = "cost_estimate"
+	MODEL_PRICES_FILE_ENV = "MODEL_PRICES_FILE"
+	DEFAULT_PRICE_KEY     = "default" // key of the fallback rate in a prices file
+)
+
+// ModelPrice is the USD price per 1K tokens of one model
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"` // also applied to thinking tokens
+}
+
+// PriceTable holds the prices by model name. Default is used for models not in Models,
+// and estimates made with it are flagged as approximate.
+type PriceTable struct {
+	Models  map[string]ModelPrice
+	Default ModelPrice
+}
+
+// DefaultPriceTable returns list prices of common Gemini models. The default rate is the
+// most expensive one listed, so an unknown model is overestimated rather than underestimated.
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		Models: map[string]ModelPrice{
+			"gemini-2.0-flash":      {InputPer1K: 0.0001, OutputPer1K: 0.0004},
+			"gemini-2.0-flash-lite": {InputPer1K: 0.000075, OutputPer1K: 0.0003},
+			"gemini-2.5-flash":      {InputPer1K: 0.0003, OutputPer1K: 0.0025},
+			"gemini-2.5-flash-lite": {InputPer1K: 0.0001, OutputPer1K: 0.0004},
+			"gemini-2.5-pro":        {InputPer1K: 0.00125, OutputPer1K: 0.01},
+		},
+		Default: ModelPrice{InputPer1K: 0.00125, OutputPer1K: 0.01},
+	}
+}
+
+// LoadPriceTable returns DefaultPriceTable with the entries of the MODEL_PRICES_FILE file,
+// if set, added or replaced. getenv is usually os.Getenv.
+func LoadPriceTable(getenv func(string) string) (PriceTable, error) {
+	prices := DefaultPriceTable()
+	path := strings.TrimSpace(getenv(MODEL_PRICES_FILE_ENV))
+	if path == "" {
+		return prices, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PriceTable{}, fmt.Errorf("failed to read %s %q: %w", MODEL_PRICES_FILE_ENV, path, err)
+	}
+	var overrides map[string]ModelPrice
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return PriceTable{}, fmt.Errorf("failed to parse %s %q: %w", MODEL_PRICES_FILE_ENV, path, err)
+	}
+	for name, price := range overrides {
+		if price.InputPer1K < 0 || price.OutputPer1K < 0 {
+			return PriceTable{}, fmt.Errorf("negative price for %q in %s", name, path)
+		}
+		if name == DEFAULT_PRICE_KEY {
+			prices.Default = price
+			continue
+		}
+		prices.Models[name] = price
+	}
+	return prices, nil
+}
+
+// Lookup returns the price of a model and whether it is known. Versioned names such as
+// "gemini-2.0-flash-001" use the price of the longest listed name they start with.
+func (p PriceTable) Lookup(modelName string) (ModelPrice, bool) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(modelName)), "models/")
+	if price, ok := p.Models[name]; ok {
+		return price, true
+	}
+
+	best := ""
+	for listed := range p.Models {
+		if strings.HasPrefix(name, listed+"-") && len(listed) > len(best) {
+			best = listed
+		}
+	}
+	if best != "" {
+		return p.Models[best], true
+	}
+	return p.Default, false
+}
+
+// costEstimate is the running total kept under COST_ESTIMATE_KEY
+type costEstimate struct {
+	TotalUSD     float64
+	InputTokens  int
+	OutputTokens int
+	ModelCalls   int
+	Approximate  bool // some calls used the default rate
+}
+
+// CostEstimateCallback returns an AfterModelCallback that adds the estimated cost of each
+// response to the session's cost_estimate and logs the call and session totals. A response is
+// priced for the model a modelutil.FallbackModel recorded on it, or for modelName otherwise.
+// Responses without usage metadata (errors, partial stream chunks) are not counted.
+func CostEstimateCallback(modelName string, prices PriceTable) llmagent.AfterModelCallback {
+	return func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+		if respErr != nil || resp == nil || resp.Partial || resp.UsageMetadata == nil {
+			return nil, nil
+		}
+
+		modelName := modelutil.ResponseModel(resp, modelName)
+		call, total, err := addCost(ctx.State(), prices, modelName, resp.UsageMetadata)
+		if err != nil {
+			return nil, err
+		}
+
+		slog.Info("model cost estimate",
+			"agent", ctx.AgentName(),
+			"model", modelName,
+			"input_tokens", call.InputTokens,
+			"output_tokens", call.OutputTokens,
+			"call_usd", formatUSD(call.TotalUSD),
+			"session_usd", formatUSD(total.TotalUSD),
+			"session_calls", total.ModelCalls,
+			"approximate", total.Approximate)
+		return nil, nil
+	}
+}
+
+// WithCostEstimate returns a copy of cfg with a CostEstimateCallback for its model appended
+// to the after-model callbacks. A config without a model is returned unchanged.
+func WithCostEstimate(cfg llmagent.Config, prices PriceTable) llmagent.Config {
+	if cfg.Model == nil {
+		return cfg
+	}
+	callbacks := make([]llmagent.AfterModelCallback, 0, len(cfg.AfterModelCallbacks)+1)
+	callbacks = append(callbacks, cfg.AfterModelCallbacks...)
+	cfg.AfterModelCallbacks = append(callbacks, CostEstimateCallback(cfg.Model.Name(), prices))
+	return cfg
+}
+
+// addCost estimates one response and adds it to the running total in state
+func addCost(state session.State, prices PriceTable, modelName string, usage *genai.GenerateContentResponseUsageMetadata) (costEstimate, costEstimate, error) {
+	price, known := prices.Lookup(modelName)
+	input := int(usage.PromptTokenCount)
+	output := int(usage.CandidatesTokenCount + usage.ThoughtsTokenCount)
+	call := costEstimate{
+		TotalUSD:     float64(input)/1000*price.InputPer1K + float64(output)/1000*price.OutputPer1K,
+		InputTokens:  input,
+		OutputTokens: output,
+		ModelCalls:   1,
+		Approximate:  !known,
+	}
+
+	total := getCostEstimate(state)
+	total.TotalUSD += call.TotalUSD
+	total.InputTokens += call.InputTokens
+	total.OutputTokens += call.OutputTokens
+	total.ModelCalls++
+	total.Approximate = total.Approximate || call.Approximate
+
+	if err := state.Set(COST_ESTIMATE_KEY, total.toState()); err != nil {
+		return costEstimate{}, costEstimate{}, fmt.Errorf("failed to set %s: %w", COST_ESTIMATE_KEY, err)
+	}
+	return call, total, nil
+}
+
+func (c costEstimate) toState() map[string]any {
+	return map[string]any{
+		"total_usd":     c.TotalUSD,
+		"input_tokens":  c.InputTokens,
+		"output_tokens": c.OutputTokens,
+		"model_calls":   c.ModelCalls,
+		"approximate":   c.Approximate,
+	}
+}
+
+// getCostEstimate reads the running total; after a database round trip numbers are float64
+func getCostEstimate(state session.ReadonlyState) costEstimate {
+	val, err := state.Get(COST_ESTIMATE_KEY)
+	if err != nil {
+		return costEstimate{}
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		return costEstimate{}
+	}
+	approximate, _ := m["approximate"].(bool)
+	return costEstimate{
+		TotalUSD:     toFloat(m["total_usd"]),
+		InputTokens:  int(toFloat(m["input_tokens"])),
+		OutputTokens: int(toFloat(m["output_tokens"])),
+		ModelCalls:   int(toFloat(m["model_calls"])),
+		Approximate:  approximate,
+	}
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+func formatUSD(usd float64) string {
+	return fmt.Sprintf("$%.6f", usd)
+}
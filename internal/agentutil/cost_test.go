@@ -0,0 +1,149 @@
+package agentutil
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+	"github.com/muchlist/agent-dev-kit/internal/modelutil"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func TestPriceTableLookup(t *testing.T) {
+	prices := DefaultPriceTable()
+
+	tests := []struct {
+		model     string
+		wantKnown bool
+		want      ModelPrice
+	}{
+		{"gemini-2.0-flash", true, prices.Models["gemini-2.0-flash"]},
+		{"gemini-2.0-flash-001", true, prices.Models["gemini-2.0-flash"]},
+		{"gemini-2.0-flash-lite-001", true, prices.Models["gemini-2.0-flash-lite"]},
+		{"models/gemini-2.5-pro", true, prices.Models["gemini-2.5-pro"]},
+		{"gemini-9-ultra", false, prices.Default},
+	}
+	for _, tt := range tests {
+		got, known := prices.Lookup(tt.model)
+		if known != tt.wantKnown || got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v, want %+v, %v", tt.model, got, known, tt.want, tt.wantKnown)
+		}
+	}
+}
+
+func TestAddCostAccumulatesAcrossRoundTrip(t *testing.T) {
+	prices := PriceTable{
+		Models:  map[string]ModelPrice{"cheap": {InputPer1K: 0.001, OutputPer1K: 0.002}},
+		Default: ModelPrice{InputPer1K: 0.01, OutputPer1K: 0.02},
+	}
+	state := agenttest.NewState(nil, false)
+
+	call, total, err := addCost(state, prices, "cheap", &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount: 2000, CandidatesTokenCount: 400, ThoughtsTokenCount: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2K input * 0.001 + 0.5K output (including thoughts) * 0.002
+	if !almostEqual(call.TotalUSD, 0.003) || call.OutputTokens != 500 || call.Approximate {
+		t.Errorf("call = %+v, want $0.003 for 500 output tokens, not approximate", call)
+	}
+	if total != call {
+		t.Errorf("total = %+v, want the first call %+v", total, call)
+	}
+
+	// Simulate the database round trip, which turns ints into float64
+	roundTripped := agenttest.NewState(state.Values(), true)
+
+	_, total, err = addCost(roundTripped, prices, "unknown-model", &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(total.TotalUSD, 0.013) || total.InputTokens != 3000 || total.ModelCalls != 2 || !total.Approximate {
+		t.Errorf("total = %+v, want $0.013 over 2 calls, flagged approximate", total)
+	}
+}
+
+// costContext is an emitterContext with session state
+type costContext struct {
+	emitterContext
+	state session.State
+}
+
+func (c costContext) State() session.State { return c.state }
+
+func TestCostEstimateCallbackUsesServingModel(t *testing.T) {
+	prices := PriceTable{
+		Models: map[string]ModelPrice{
+			"primary":  {InputPer1K: 0.001},
+			"fallback": {InputPer1K: 0.01},
+		},
+	}
+	state := agenttest.NewState(nil, false)
+	callback := CostEstimateCallback("primary", prices)
+	usage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1000}
+
+	// A response served by the fallback model is priced at the fallback's rate
+	served := &model.LLMResponse{UsageMetadata: usage, CustomMetadata: map[string]any{modelutil.RESPONSE_MODEL_KEY: "fallback"}}
+	if _, err := callback(costContext{emitterContext{agentName: "assistant"}, state}, served, nil); err != nil {
+		t.Fatal(err)
+	}
+	if total := getCostEstimate(state); !almostEqual(total.TotalUSD, 0.01) {
+		t.Errorf("total = %+v, want $0.01 at the fallback's rate", total)
+	}
+
+	// Without a recorded model the agent's model is used
+	if _, err := callback(costContext{emitterContext{agentName: "assistant"}, state}, &model.LLMResponse{UsageMetadata: usage}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if total := getCostEstimate(state); !almostEqual(total.TotalUSD, 0.011) || total.Approximate {
+		t.Errorf("total = %+v, want $0.011 with the primary's rate added", total)
+	}
+}
+
+func TestLoadPriceTableFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	content := `{"gemini-2.0-flash": {"input_per_1k": 0.5, "output_per_1k": 1}, "my-model": {"input_per_1k": 0.1}, "default": {"input_per_1k": 2, "output_per_1k": 3}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prices, err := LoadPriceTable(func(key string) string { return map[string]string{MODEL_PRICES_FILE_ENV: path}[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prices.Models["gemini-2.0-flash"]; got != (ModelPrice{InputPer1K: 0.5, OutputPer1K: 1}) {
+		t.Errorf("gemini-2.0-flash = %+v, want the file's price", got)
+	}
+	if _, ok := prices.Models["gemini-2.5-pro"]; !ok {
+		t.Error("built-in prices missing after loading the file")
+	}
+	if got, known := prices.Lookup("my-model"); !known || got.InputPer1K != 0.1 {
+		t.Errorf("Lookup(my-model) = %+v, %v, want the file's price", got, known)
+	}
+	if prices.Default != (ModelPrice{InputPer1K: 2, OutputPer1K: 3}) {
+		t.Errorf("Default = %+v, want the file's default", prices.Default)
+	}
+}
+
+func TestLoadPriceTableRejectsNegativePrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(path, []byte(`{"x": {"input_per_1k": -1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPriceTable(func(key string) string { return map[string]string{MODEL_PRICES_FILE_ENV: path}[key] }); err == nil {
+		t.Error("LoadPriceTable accepted a negative price")
+	}
+}
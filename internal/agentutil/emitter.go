@@ -0,0 +1,345 @@
+package agentutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/tool"
+)
+
+// ===== Activity Events =====
+//
+// An EventEmitter publishes what the agents do as JSON events, so external systems (a CRM,
+// an analytics pipeline, a Slack bot) can follow along without reading the session database:
+//
+//	{"type": "tool_called", "timestamp": "...", "app_name": "customer_service", "user_id": "...",
+//	 "session_id": "...", "invocation_id": "...", "agent": "order_agent",
+//	 "data": {"tool": "refund_course", "args": {...}, "status": "success"}}
+//
+// It hooks into the agent and tool callbacks (see WithEventEmitter):
+//   - turn_started: the first agent of an invocation starts
+//   - agent_delegated: another agent takes over in the same invocation (data: from, to)
+//   - tool_called: a tool returned (data: tool, args, status and error)
+//   - turn_completed: the first agent of the invocation finished (data: duration_ms)
+//
+// Events go to an EventSink: stdout, a JSON lines file or an HTTP webhook, chosen with
+// EVENT_SINK (see LoadEventSink). They are queued and sent by one goroutine in order, so a slow
+// webhook never holds up the agent. Emitting never fails a run: sink errors are logged and the
+// event is dropped, as are events that don't fit in the queue.
+
+const (
+	EVENT_SINK_ENV = "EVENT_SINK"
+
+	EVENT_TURN_STARTED    = "turn_started"
+	EVENT_AGENT_DELEGATED = "agent_delegated"
+	EVENT_TOOL_CALLED     = "tool_called"
+	EVENT_TURN_COMPLETED  = "turn_completed"
+
+	// EVENT_QUEUE_SIZE is how many events can wait for the sink before new ones are dropped
+	EVENT_QUEUE_SIZE = 256
+	// WEBHOOK_TIMEOUT bounds each webhook request
+	WEBHOOK_TIMEOUT = 5 * time.Second
+	// staleTurnAge is when a turn that never completed (e.g. its run failed) is forgotten
+	staleTurnAge = time.Hour
+)
+
+// ActivityEvent is one published event
+type ActivityEvent struct {
+	Type         string         `json:"type"`
+	Timestamp    string         `json:"timestamp"` // RFC 3339 with milliseconds
+	AppName      string         `json:"app_name"`
+	UserID       string         `json:"user_id"`
+	SessionID    string         `json:"session_id"`
+	InvocationID string         `json:"invocation_id"`
+	Agent        string         `json:"agent"`
+	Data         map[string]any `json:"data,omitempty"`
+}
+
+// EventSink delivers events. A sink that also implements io.Closer is closed by
+// EventEmitter.Close.
+type EventSink interface {
+	Send(ctx context.Context, event ActivityEvent) error
+}
+
+// ===== Sinks =====
+
+// WriterSink writes each event as one JSON line, e.g. to os.Stdout
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Send writes event as one JSON line
+func (s *WriterSink) Send(_ context.Context, event ActivityEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// FileSink appends events as JSON lines to a file
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+// NewFileSink opens (or creates) path for appending
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	return &FileSink{WriterSink: NewWriterSink(file), file: file}, nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// WebhookSink POSTs each event as JSON to a URL. Any status other than 2xx is an error.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink creates a sink posting to url with a WEBHOOK_TIMEOUT per request
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: WEBHOOK_TIMEOUT}}
+}
+
+// Send posts event to the webhook
+func (s *WebhookSink) Send(ctx context.Context, event ActivityEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// LoadEventSink returns the sink named by EVENT_SINK, or nil when it is unset:
+//   - "stdout": JSON lines on standard output
+//   - "file:<path>": JSON lines appended to path
+//   - an http:// or https:// URL: a webhook
+//
+// getenv is usually os.Getenv.
+func LoadEventSink(getenv func(string) string) (EventSink, error) {
+	raw := strings.TrimSpace(getenv(EVENT_SINK_ENV))
+	switch {
+	case raw == "":
+		return nil, nil
+	case raw == "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case strings.HasPrefix(raw, "file:"):
+		path := strings.TrimSpace(strings.TrimPrefix(raw, "file:"))
+		if path == "" {
+			return nil, fmt.Errorf("invalid %s %q: want file:<path>", EVENT_SINK_ENV, raw)
+		}
+		return NewFileSink(path)
+	case strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://"):
+		return NewWebhookSink(raw), nil
+	}
+	return nil, fmt.Errorf("invalid %s %q: want stdout, file:<path> or an http(s) webhook URL", EVENT_SINK_ENV, raw)
+}
+
+// ===== Emitter =====
+
+// turn is the agent that started an invocation and the agent running it now
+type turn struct {
+	root, current string
+	started       time.Time
+}
+
+// EventEmitter turns agent and tool callbacks into events for its sink. Create one with
+// NewEventEmitter, add it to each agent with WithEventEmitter and Close it before exiting.
+type EventEmitter struct {
+	sink  EventSink
+	queue chan ActivityEvent
+	done  chan struct{}
+	now   func() time.Time
+
+	mu      sync.Mutex
+	turns   map[string]*turn // by invocation ID
+	closed  bool
+	dropped int
+}
+
+// NewEventEmitter creates an emitter sending to sink and starts its delivery goroutine
+func NewEventEmitter(sink EventSink) *EventEmitter {
+	e := &EventEmitter{
+		sink:  sink,
+		queue: make(chan ActivityEvent, EVENT_QUEUE_SIZE),
+		done:  make(chan struct{}),
+		now:   time.Now,
+		turns: map[string]*turn{},
+	}
+	go e.deliver()
+	return e
+}
+
+func (e *EventEmitter) deliver() {
+	defer close(e.done)
+	for event := range e.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), WEBHOOK_TIMEOUT)
+		if err := e.sink.Send(ctx, event); err != nil {
+			slog.Warn("failed to emit event", "type", event.Type, "session_id", event.SessionID, "error", err)
+		}
+		cancel()
+	}
+}
+
+// Close sends the queued events, then closes the sink if it is an io.Closer. Events emitted
+// after Close are dropped.
+func (e *EventEmitter) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.queue)
+	dropped := e.dropped
+	e.mu.Unlock()
+
+	<-e.done
+	if dropped > 0 {
+		slog.Warn("events dropped because the sink fell behind", "dropped", dropped)
+	}
+	if closer, ok := e.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// emit queues an event without blocking; when the queue is full the event is dropped
+func (e *EventEmitter) emit(ctx agent.ReadonlyContext, eventType string, data map[string]any) {
+	event := ActivityEvent{
+		Type:         eventType,
+		Timestamp:    e.now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		AppName:      ctx.AppName(),
+		UserID:       ctx.UserID(),
+		SessionID:    ctx.SessionID(),
+		InvocationID: ctx.InvocationID(),
+		Agent:        ctx.AgentName(),
+		Data:         data,
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- event:
+	default:
+		e.dropped++
+	}
+}
+
+// BeforeAgent is an agent.BeforeAgentCallback that emits turn_started for the first agent of
+// an invocation and agent_delegated when another agent takes over
+func (e *EventEmitter) BeforeAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	now := e.now()
+	e.mu.Lock()
+	for id, t := range e.turns {
+		if now.Sub(t.started) > staleTurnAge {
+			delete(e.turns, id)
+		}
+	}
+	t, ok := e.turns[ctx.InvocationID()]
+	if !ok {
+		e.turns[ctx.InvocationID()] = &turn{root: ctx.AgentName(), current: ctx.AgentName(), started: now}
+	}
+	var from string
+	if ok && t.current != ctx.AgentName() {
+		from, t.current = t.current, ctx.AgentName()
+	}
+	e.mu.Unlock()
+
+	switch {
+	case !ok:
+		e.emit(ctx, EVENT_TURN_STARTED, nil)
+	case from != "":
+		e.emit(ctx, EVENT_AGENT_DELEGATED, map[string]any{"from": from, "to": ctx.AgentName()})
+	}
+	return nil, nil
+}
+
+// AfterAgent is an agent.AfterAgentCallback that emits turn_completed when the agent that
+// started the invocation finishes
+func (e *EventEmitter) AfterAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	e.mu.Lock()
+	t, ok := e.turns[ctx.InvocationID()]
+	if !ok || t.root != ctx.AgentName() {
+		e.mu.Unlock()
+		return nil, nil
+	}
+	delete(e.turns, ctx.InvocationID())
+	e.mu.Unlock()
+
+	e.emit(ctx, EVENT_TURN_COMPLETED, map[string]any{"duration_ms": e.now().Sub(t.started).Milliseconds()})
+	return nil, nil
+}
+
+// AfterTool is an llmagent.AfterToolCallback that emits tool_called and leaves the result as is
+func (e *EventEmitter) AfterTool(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	data := map[string]any{"tool": t.Name(), "args": args, "status": "success"}
+	if err != nil {
+		data["status"], data["error"] = "error", err.Error()
+	} else if status, ok := result["status"].(string); ok {
+		// Tools report handled failures in their result, e.g. {"status": "error"}
+		data["status"] = status
+	}
+	e.emit(ctx, EVENT_TOOL_CALLED, data)
+	return nil, nil
+}
+
+// WithEventEmitter returns a copy of cfg with e's callbacks prepended to its agent and
+// after-tool callbacks, so they run even when a later callback short-circuits. A nil e leaves
+// cfg as is.
+func WithEventEmitter(cfg llmagent.Config, e *EventEmitter) llmagent.Config {
+	if e == nil {
+		return cfg
+	}
+	cfg.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{e.BeforeAgent}, cfg.BeforeAgentCallbacks...)
+	cfg.AfterAgentCallbacks = append([]agent.AfterAgentCallback{e.AfterAgent}, cfg.AfterAgentCallbacks...)
+	cfg.AfterToolCallbacks = append([]llmagent.AfterToolCallback{e.AfterTool}, cfg.AfterToolCallbacks...)
+	return cfg
+}
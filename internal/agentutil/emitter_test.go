@@ -0,0 +1,229 @@
+package agentutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// emitterContext is a tool.Context (and so an agent.CallbackContext) for one invocation; only
+// the identity methods work
+type emitterContext struct {
+	tool.Context
+	agentName string
+}
+
+func (c emitterContext) AppName() string      { return "shop" }
+func (c emitterContext) UserID() string       { return "user-1" }
+func (c emitterContext) SessionID() string    { return "session-1" }
+func (c emitterContext) InvocationID() string { return "inv-1" }
+func (c emitterContext) AgentName() string    { return c.agentName }
+
+// recordingSink keeps the events it was sent, or fails when err is set
+type recordingSink struct {
+	mu     sync.Mutex
+	events []ActivityEvent
+	err    error
+	closed bool
+}
+
+func (s *recordingSink) Send(_ context.Context, event ActivityEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func testTool(t *testing.T, name string) tool.Tool {
+	t.Helper()
+	fn := func(ctx tool.Context, input struct{}) (struct{}, error) { return struct{}{}, nil }
+	tl, err := functiontool.New(functiontool.Config{Name: name, Description: name}, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tl
+}
+
+func TestEventEmitterTurn(t *testing.T) {
+	sink := &recordingSink{}
+	e := NewEventEmitter(sink)
+	root, order := emitterContext{agentName: "customer_service"}, emitterContext{agentName: "order_agent"}
+
+	e.BeforeAgent(root)
+	e.AfterTool(root, testTool(t, "handoff_to"), map[string]any{"agent": "order_agent"}, map[string]any{"status": "success"}, nil)
+	e.BeforeAgent(order)
+	e.AfterTool(order, testTool(t, "refund_course"), map[string]any{"course_id": "ai"}, map[string]any{"status": "error"}, nil)
+	e.AfterTool(order, testTool(t, "get_orders"), nil, nil, errors.New("boom"))
+	e.AfterAgent(order) // not the agent that started the turn
+	e.AfterAgent(root)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ typ, agent string }{
+		{EVENT_TURN_STARTED, "customer_service"},
+		{EVENT_TOOL_CALLED, "customer_service"},
+		{EVENT_AGENT_DELEGATED, "order_agent"},
+		{EVENT_TOOL_CALLED, "order_agent"},
+		{EVENT_TOOL_CALLED, "order_agent"},
+		{EVENT_TURN_COMPLETED, "customer_service"},
+	}
+	if len(sink.events) != len(want) {
+		t.Fatalf("events = %+v, want %d", sink.events, len(want))
+	}
+	for i, w := range want {
+		got := sink.events[i]
+		if got.Type != w.typ || got.Agent != w.agent {
+			t.Errorf("event %d = %s by %s, want %s by %s", i, got.Type, got.Agent, w.typ, w.agent)
+		}
+		if got.AppName != "shop" || got.UserID != "user-1" || got.SessionID != "session-1" || got.InvocationID != "inv-1" {
+			t.Errorf("event %d ids = %+v, want the session's", i, got)
+		}
+	}
+
+	if d := sink.events[2].Data; d["from"] != "customer_service" || d["to"] != "order_agent" {
+		t.Errorf("agent_delegated data = %v, want customer_service to order_agent", d)
+	}
+	if d := sink.events[3].Data; d["tool"] != "refund_course" || d["status"] != "error" {
+		t.Errorf("refund tool_called data = %v, want the status from the result", d)
+	}
+	if d := sink.events[4].Data; d["status"] != "error" || d["error"] != "boom" {
+		t.Errorf("failed tool_called data = %v, want the error", d)
+	}
+	if !sink.closed {
+		t.Error("Close() didn't close the sink")
+	}
+}
+
+func TestEventEmitterSinkFailureDoesNotFailCallbacks(t *testing.T) {
+	e := NewEventEmitter(&recordingSink{err: errors.New("sink down")})
+	ctx := emitterContext{agentName: "customer_service"}
+
+	if content, err := e.BeforeAgent(ctx); content != nil || err != nil {
+		t.Errorf("BeforeAgent() = %v, %v; want nil, nil", content, err)
+	}
+	if result, err := e.AfterTool(ctx, testTool(t, "x"), nil, nil, nil); result != nil || err != nil {
+		t.Errorf("AfterTool() = %v, %v; want nil, nil", result, err)
+	}
+	if content, err := e.AfterAgent(ctx); content != nil || err != nil {
+		t.Errorf("AfterAgent() = %v, %v; want nil, nil", content, err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Events after Close are dropped instead of panicking on the closed queue
+	e.BeforeAgent(ctx)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got ActivityEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.Type == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	if err := sink.Send(context.Background(), ActivityEvent{Type: EVENT_TURN_STARTED, SessionID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != EVENT_TURN_STARTED || got.SessionID != "s" {
+		t.Errorf("webhook got %+v", got)
+	}
+	if err := sink.Send(context.Background(), ActivityEvent{Type: "fail"}); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Send() = %v, want the 502 status", err)
+	}
+}
+
+func TestLoadEventSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	tests := []struct {
+		value   string
+		want    string // sink type
+		wantErr bool
+	}{
+		{"", "", false},
+		{"stdout", "*agentutil.WriterSink", false},
+		{"file:" + path, "*agentutil.FileSink", false},
+		{"https://example.com/hook", "*agentutil.WebhookSink", false},
+		{"file:", "", true},
+		{"kafka://broker", "", true},
+	}
+	for _, tt := range tests {
+		sink, err := LoadEventSink(func(string) string { return tt.value })
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadEventSink(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got := typeName(sink); got != tt.want {
+			t.Errorf("LoadEventSink(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+		if closer, ok := sink.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+func TestFileSinkAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	for range 2 {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := NewEventEmitter(sink)
+		e.now = func() time.Time { return time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC) }
+		e.BeforeAgent(emitterContext{agentName: "a"})
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("file = %q, want 2 lines", data)
+	}
+	var event ActivityEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil || event.Timestamp != "2025-01-02T12:00:00.000Z" {
+		t.Errorf("line = %q (%v), want a turn_started event", lines[1], err)
+	}
+}
+
+func typeName(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", v)
+}
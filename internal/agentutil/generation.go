@@ -0,0 +1,121 @@
+package agentutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// ===== Generation Parameters =====
+//
+// Generation parameters are set per agent through llmagent.Config.GenerateContentConfig. Each
+// agent has its own defaults in code, and the environment can override them with variables
+// named after the agent, e.g. for the prefix EMAIL_AGENT:
+//
+//	EMAIL_AGENT_TEMPERATURE=0.2          randomness, 0 to 2 (Gemini default 1.0)
+//	EMAIL_AGENT_TOP_P=0.9                nucleus sampling, above 0 up to 1 (Gemini default 0.95)
+//	EMAIL_AGENT_MAX_OUTPUT_TOKENS=1024   longest response in tokens (default: the model's limit)
+//
+// An unset parameter is left out of the request, so the model's default applies.
+
+const (
+	TEMPERATURE_ENV_SUFFIX       = "_TEMPERATURE"
+	TOP_P_ENV_SUFFIX             = "_TOP_P"
+	MAX_OUTPUT_TOKENS_ENV_SUFFIX = "_MAX_OUTPUT_TOKENS"
+
+	MIN_TEMPERATURE = 0.0
+	MAX_TEMPERATURE = 2.0
+)
+
+// GenerationParams are an agent's generation parameters. Nil and zero fields use the model's
+// default.
+type GenerationParams struct {
+	Temperature     *float32
+	TopP            *float32
+	MaxOutputTokens int32
+}
+
+// Validate reports parameters outside the range the Gemini API accepts
+func (p GenerationParams) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < MIN_TEMPERATURE || *p.Temperature > MAX_TEMPERATURE) {
+		return fmt.Errorf("temperature %g out of range: want %g to %g", *p.Temperature, MIN_TEMPERATURE, MAX_TEMPERATURE)
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p %g out of range: want above 0 up to 1", *p.TopP)
+	}
+	if p.MaxOutputTokens < 0 {
+		return fmt.Errorf("max_output_tokens %d out of range: want a positive number", p.MaxOutputTokens)
+	}
+	return nil
+}
+
+// ContentConfig returns the parameters as a GenerateContentConfig for llmagent.Config, or nil
+// when none are set
+func (p GenerationParams) ContentConfig() *genai.GenerateContentConfig {
+	if p.Temperature == nil && p.TopP == nil && p.MaxOutputTokens == 0 {
+		return nil
+	}
+	return &genai.GenerateContentConfig{
+		Temperature:     p.Temperature,
+		TopP:            p.TopP,
+		MaxOutputTokens: p.MaxOutputTokens,
+	}
+}
+
+// String describes the parameters for startup logs, e.g. "temperature=0.2 top_p=default
+// max_output_tokens=default"
+func (p GenerationParams) String() string {
+	format := func(v *float32) string {
+		if v == nil {
+			return "default"
+		}
+		return strconv.FormatFloat(float64(*v), 'g', -1, 32)
+	}
+	maxTokens := "default"
+	if p.MaxOutputTokens > 0 {
+		maxTokens = strconv.Itoa(int(p.MaxOutputTokens))
+	}
+	return fmt.Sprintf("temperature=%s top_p=%s max_output_tokens=%s", format(p.Temperature), format(p.TopP), maxTokens)
+}
+
+// LoadGenerationParams returns defaults with <prefix>_TEMPERATURE, <prefix>_TOP_P and
+// <prefix>_MAX_OUTPUT_TOKENS, if set, replacing the matching field. The result is validated, so
+// out-of-range defaults are reported too. getenv is usually os.Getenv.
+func LoadGenerationParams(getenv func(string) string, prefix string, defaults GenerationParams) (GenerationParams, error) {
+	params := defaults
+
+	for _, f := range []struct {
+		suffix string
+		field  **float32
+	}{
+		{TEMPERATURE_ENV_SUFFIX, &params.Temperature},
+		{TOP_P_ENV_SUFFIX, &params.TopP},
+	} {
+		name := prefix + f.suffix
+		val := strings.TrimSpace(getenv(name))
+		if val == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(val, 32)
+		if err != nil {
+			return GenerationParams{}, fmt.Errorf("invalid %s %q: want a number", name, val)
+		}
+		*f.field = genai.Ptr(float32(n))
+	}
+
+	name := prefix + MAX_OUTPUT_TOKENS_ENV_SUFFIX
+	if val := strings.TrimSpace(getenv(name)); val != "" {
+		n, err := strconv.ParseInt(val, 10, 32)
+		if err != nil || n <= 0 {
+			return GenerationParams{}, fmt.Errorf("invalid %s %q: want a positive whole number", name, val)
+		}
+		params.MaxOutputTokens = int32(n)
+	}
+
+	if err := params.Validate(); err != nil {
+		return GenerationParams{}, fmt.Errorf("invalid %s generation parameters: %w", prefix, err)
+	}
+	return params, nil
+}
@@ -0,0 +1,65 @@
+package agentutil
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestLoadGenerationParams(t *testing.T) {
+	defaults := GenerationParams{Temperature: genai.Ptr[float32](0.2)}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"defaults", nil, "temperature=0.2 top_p=default max_output_tokens=default", false},
+		{
+			"overrides",
+			map[string]string{"TEST_TEMPERATURE": "1.5", "TEST_TOP_P": "0.9", "TEST_MAX_OUTPUT_TOKENS": " 512 "},
+			"temperature=1.5 top_p=0.9 max_output_tokens=512",
+			false,
+		},
+		{"temperature too high", map[string]string{"TEST_TEMPERATURE": "2.5"}, "", true},
+		{"negative temperature", map[string]string{"TEST_TEMPERATURE": "-0.1"}, "", true},
+		{"top_p zero", map[string]string{"TEST_TOP_P": "0"}, "", true},
+		{"top_p above one", map[string]string{"TEST_TOP_P": "1.1"}, "", true},
+		{"not a number", map[string]string{"TEST_TEMPERATURE": "warm"}, "", true},
+		{"zero max tokens", map[string]string{"TEST_MAX_OUTPUT_TOKENS": "0"}, "", true},
+		{"fractional max tokens", map[string]string{"TEST_MAX_OUTPUT_TOKENS": "10.5"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := LoadGenerationParams(func(key string) string { return tt.env[key] }, "TEST", defaults)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadGenerationParams() = %s, want an error", params)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadGenerationParams() error = %v", err)
+			}
+			if got := params.String(); got != tt.want {
+				t.Errorf("LoadGenerationParams() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if *defaults.Temperature != 0.2 {
+		t.Error("the defaults were modified")
+	}
+}
+
+func TestGenerationParamsContentConfig(t *testing.T) {
+	if cfg := (GenerationParams{}).ContentConfig(); cfg != nil {
+		t.Errorf("ContentConfig() with no parameters = %+v, want nil", cfg)
+	}
+
+	cfg := GenerationParams{TopP: genai.Ptr[float32](0.8), MaxOutputTokens: 256}.ContentConfig()
+	if cfg == nil || cfg.Temperature != nil || *cfg.TopP != 0.8 || cfg.MaxOutputTokens != 256 {
+		t.Errorf("ContentConfig() = %+v, want top_p 0.8 and 256 max tokens only", cfg)
+	}
+}
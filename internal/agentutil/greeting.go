@@ -0,0 +1,73 @@
+package agentutil
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+// ===== First-Turn Greeting =====
+//
+// FirstTurnGreeting stores a greeting for the local time of day ("Good morning") under
+// greeting_prefix on the first turn of a session, for the instruction to open the reply with
+// through {greeting_prefix?}. On every later turn greeting_prefix is empty, so the agent
+// greets once per session instead of on every reply.
+//
+// The greeting_greeted flag is saved with the session, so a session resumed after a restart
+// (or continued in another process) isn't greeted again. A session that is loaded from the
+// database without the flag, e.g. one created before the callback was added, is greeted once.
+
+const (
+	GREETING_PREFIX_KEY = "greeting_prefix"
+	GREETED_KEY         = "greeting_greeted" // true once the session's first turn has been greeted
+)
+
+// TimeOfDayGreeting returns the greeting for an hour of the day (0-23): "Good morning" from 5
+// to 11, "Good afternoon" from 12 to 17 and "Good evening" otherwise
+func TimeOfDayGreeting(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return "Good morning"
+	case hour >= 12 && hour < 18:
+		return "Good afternoon"
+	}
+	return "Good evening"
+}
+
+// FirstTurnGreeting returns a BeforeAgentCallback that sets greeting_prefix to the time-of-day
+// greeting on a session's first turn and clears it afterwards. now is usually time.Now; the
+// local hour of its result picks the greeting.
+func FirstTurnGreeting(now func() time.Time) agent.BeforeAgentCallback {
+	return func(ctx agent.CallbackContext) (*genai.Content, error) {
+		if err := updateGreetingPrefix(ctx.State(), now()); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+// updateGreetingPrefix writes greeting_prefix for a turn at now. State is only written when it
+// changes: on the first turn, and on the second one to clear the prefix.
+func updateGreetingPrefix(state session.State, now time.Time) error {
+	greeted, _ := state.Get(GREETED_KEY)
+	if greeted != true {
+		if err := state.Set(GREETING_PREFIX_KEY, TimeOfDayGreeting(now.Hour())); err != nil {
+			return fmt.Errorf("failed to set %s: %w", GREETING_PREFIX_KEY, err)
+		}
+		if err := state.Set(GREETED_KEY, true); err != nil {
+			return fmt.Errorf("failed to set %s: %w", GREETED_KEY, err)
+		}
+		return nil
+	}
+
+	if prefix, _ := state.Get(GREETING_PREFIX_KEY); prefix != "" {
+		if err := state.Set(GREETING_PREFIX_KEY, ""); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", GREETING_PREFIX_KEY, err)
+		}
+	}
+	return nil
+}
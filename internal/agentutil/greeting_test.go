@@ -0,0 +1,53 @@
+package agentutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestTimeOfDayGreeting(t *testing.T) {
+	for hour, want := range map[int]string{
+		0: "Good evening", 4: "Good evening", 5: "Good morning", 11: "Good morning",
+		12: "Good afternoon", 17: "Good afternoon", 18: "Good evening", 23: "Good evening",
+	} {
+		if got := TimeOfDayGreeting(hour); got != want {
+			t.Errorf("TimeOfDayGreeting(%d) = %q, want %q", hour, got, want)
+		}
+	}
+}
+
+func TestUpdateGreetingPrefixOncePerSession(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	morning := time.Date(2024, 12, 3, 9, 0, 0, 0, time.Local)
+
+	var prefixes []any
+	for range 3 {
+		if err := updateGreetingPrefix(state, morning); err != nil {
+			t.Fatal(err)
+		}
+		prefixes = append(prefixes, state.Value(GREETING_PREFIX_KEY))
+	}
+
+	if prefixes[0] != "Good morning" || prefixes[1] != "" || prefixes[2] != "" {
+		t.Errorf("greeting_prefix per turn = %q, want Good morning on the first turn only", prefixes)
+	}
+}
+
+func TestUpdateGreetingPrefixResumedSession(t *testing.T) {
+	// A session reloaded from the database after its first turn; the flag came through JSON
+	var stored map[string]any
+	if err := json.Unmarshal([]byte(`{"greeting_greeted": true, "greeting_prefix": ""}`), &stored); err != nil {
+		t.Fatal(err)
+	}
+	state := agenttest.NewState(stored, false)
+
+	if err := updateGreetingPrefix(state, time.Date(2024, 12, 3, 20, 0, 0, 0, time.Local)); err != nil {
+		t.Fatal(err)
+	}
+	if state.Value(GREETING_PREFIX_KEY) != "" {
+		t.Errorf("greeting_prefix = %q, want a resumed session not greeted again", state.Value(GREETING_PREFIX_KEY))
+	}
+}
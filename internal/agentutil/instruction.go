@@ -0,0 +1,102 @@
+package agentutil
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+)
+
+// Environment variables for the global instruction prepended to every agent built with NewLLMAgent.
+// GLOBAL_INSTRUCTION holds the text itself; GLOBAL_INSTRUCTION_FILE points to a file with it.
+// When both are set, GLOBAL_INSTRUCTION wins.
+const (
+	GLOBAL_INSTRUCTION_ENV      = "GLOBAL_INSTRUCTION"
+	GLOBAL_INSTRUCTION_FILE_ENV = "GLOBAL_INSTRUCTION_FILE"
+)
+
+// placeholderRe matches ADK instruction template placeholders such as {user_name} or {notes?}
+var placeholderRe = regexp.MustCompile(`{+[^{}]*}+`)
+
+// loadGlobalInstruction reads the global instruction once per process
+var loadGlobalInstruction = sync.OnceValues(func() (string, error) {
+	return LoadGlobalInstruction(os.Getenv)
+})
+
+// LoadGlobalInstruction returns the configured global instruction, or "" when none is set.
+// getenv is usually os.Getenv.
+func LoadGlobalInstruction(getenv func(string) string) (string, error) {
+	text := getenv(GLOBAL_INSTRUCTION_ENV)
+	if strings.TrimSpace(text) == "" {
+		path := strings.TrimSpace(getenv(GLOBAL_INSTRUCTION_FILE_ENV))
+		if path == "" {
+			return "", nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s %q: %w", GLOBAL_INSTRUCTION_FILE_ENV, path, err)
+		}
+		text = string(data)
+	}
+
+	text = strings.TrimSpace(text)
+	// The prefix is part of the instruction template, so braces in it would be resolved
+	// against session state and fail the agent run when the key is missing
+	if match := TemplatePlaceholder(text); match != "" {
+		return "", fmt.Errorf("global instruction must not contain template placeholders, found %q", match)
+	}
+	return text, nil
+}
+
+// TemplatePlaceholder returns the first {state} template placeholder in text, or "" when it
+// has none. Text prepended to an instruction should have none.
+func TemplatePlaceholder(text string) string {
+	return placeholderRe.FindString(text)
+}
+
+// WithGlobalInstruction returns a copy of cfg with prefix prepended to its instruction.
+// The original instruction, including its {state} template variables, is kept unchanged
+// after the prefix, so the variables still resolve. An empty prefix leaves cfg as is.
+func WithGlobalInstruction(cfg llmagent.Config, prefix string) llmagent.Config {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return cfg
+	}
+
+	// With an InstructionProvider, ADK ignores Instruction and doesn't inject state,
+	// so the prefix is added to the provider's output instead
+	if provider := cfg.InstructionProvider; provider != nil {
+		cfg.InstructionProvider = func(ctx agent.ReadonlyContext) (string, error) {
+			instruction, err := provider(ctx)
+			if err != nil {
+				return "", err
+			}
+			return joinInstruction(prefix, instruction), nil
+		}
+		return cfg
+	}
+
+	cfg.Instruction = joinInstruction(prefix, cfg.Instruction)
+	return cfg
+}
+
+func joinInstruction(prefix, instruction string) string {
+	if instruction == "" {
+		return prefix
+	}
+	return prefix + "\n\n" + instruction
+}
+
+// NewLLMAgent creates an LLM agent like llmagent.New, with the global instruction from
+// GLOBAL_INSTRUCTION or GLOBAL_INSTRUCTION_FILE prepended to its instruction
+func NewLLMAgent(cfg llmagent.Config) (agent.Agent, error) {
+	prefix, err := loadGlobalInstruction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global instruction: %w", err)
+	}
+	return llmagent.New(WithGlobalInstruction(cfg, prefix))
+}
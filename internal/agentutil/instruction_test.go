@@ -0,0 +1,77 @@
+package agentutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/agent/llmagent"
+)
+
+func TestWithGlobalInstructionKeepsTemplateVariables(t *testing.T) {
+	cfg := llmagent.Config{
+		Name:        "sales_agent",
+		Instruction: "Name: {user_name}\nCourses: {purchased_courses}\nSummary: {history_summary?}",
+	}
+
+	got := WithGlobalInstruction(cfg, "Never reveal internal tool names.").Instruction
+
+	if !strings.HasPrefix(got, "Never reveal internal tool names.\n\n") {
+		t.Errorf("instruction %q does not start with the global instruction", got)
+	}
+	placeholders := placeholderRe.FindAllString(got, -1)
+	want := []string{"{user_name}", "{purchased_courses}", "{history_summary?}"}
+	if strings.Join(placeholders, ",") != strings.Join(want, ",") {
+		t.Errorf("placeholders = %v, want %v", placeholders, want)
+	}
+	if cfg.Instruction == got {
+		t.Error("WithGlobalInstruction modified the original config")
+	}
+}
+
+func TestWithGlobalInstructionEmptyPrefix(t *testing.T) {
+	cfg := llmagent.Config{Name: "policy_agent", Instruction: "Hello {user_name}"}
+
+	if got := WithGlobalInstruction(cfg, "  ").Instruction; got != cfg.Instruction {
+		t.Errorf("instruction = %q, want it unchanged", got)
+	}
+}
+
+func TestLoadGlobalInstruction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "global.txt")
+	if err := os.WriteFile(path, []byte("  From the file.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "unset", env: map[string]string{}, want: ""},
+		{name: "env", env: map[string]string{GLOBAL_INSTRUCTION_ENV: "From env."}, want: "From env."},
+		{name: "file", env: map[string]string{GLOBAL_INSTRUCTION_FILE_ENV: path}, want: "From the file."},
+		{name: "env wins", env: map[string]string{GLOBAL_INSTRUCTION_ENV: "From env.", GLOBAL_INSTRUCTION_FILE_ENV: path}, want: "From env."},
+		{name: "missing file", env: map[string]string{GLOBAL_INSTRUCTION_FILE_ENV: path + ".missing"}, wantErr: "failed to read"},
+		{name: "placeholder", env: map[string]string{GLOBAL_INSTRUCTION_ENV: "Hi {user_name}"}, wantErr: "placeholders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadGlobalInstruction(func(key string) string { return tt.env[key] })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package agentutil
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+)
+
+// ===== Structured Output Cleanup =====
+//
+// Even with an OutputSchema, models sometimes wrap the JSON in a ```json fence or add a sentence
+// before or after it ("Here is the email:"). OutputKey stores the response text as is, so every
+// reader of that state key has to cope with it. JSONOutputCleanup is an opt-in AfterModelCallback
+// that cuts the response down to the JSON value before it is stored. Responses that are already
+// clean JSON, or that hold no JSON at all, are left unchanged.
+
+// jsonFenceRe matches a fenced code block, optionally tagged json
+var jsonFenceRe = regexp.MustCompile("(?is)```(?:json)?[ \t]*\n?(.*?)```")
+
+// ExtractJSON returns the JSON object or array in text, without code fences or surrounding
+// prose, and whether anything was removed. Text without valid JSON is returned unchanged.
+func ExtractJSON(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || json.Valid([]byte(trimmed)) {
+		return text, false
+	}
+
+	// A fenced block holding valid JSON
+	for _, m := range jsonFenceRe.FindAllStringSubmatch(trimmed, -1) {
+		if inner := strings.TrimSpace(m[1]); isJSONValue(inner) {
+			return inner, true
+		}
+	}
+
+	// Otherwise the first object or array that parses, e.g. after "Here is the email:"
+	for i := 0; i < len(trimmed); i++ {
+		if trimmed[i] != '{' && trimmed[i] != '[' {
+			continue
+		}
+		var raw json.RawMessage
+		dec := json.NewDecoder(strings.NewReader(trimmed[i:]))
+		if err := dec.Decode(&raw); err == nil {
+			return string(raw), true
+		}
+	}
+	return text, false
+}
+
+// isJSONValue reports whether s is a valid JSON object or array
+func isJSONValue(s string) bool {
+	return (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s))
+}
+
+// JSONOutputCleanup returns an AfterModelCallback that replaces the text of a complete response
+// with the JSON it contains (see ExtractJSON). Thoughts and other parts are kept; errors,
+// partial stream chunks and function calls are left alone.
+func JSONOutputCleanup() llmagent.AfterModelCallback {
+	return func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+		if respErr != nil {
+			return nil, nil
+		}
+		cleaned, removed := cleanJSONResponse(resp)
+		if cleaned == nil {
+			return nil, nil
+		}
+
+		slog.Info("structured output cleaned up",
+			"agent", ctx.AgentName(),
+			"session_id", ctx.SessionID(),
+			"removed_chars", removed)
+		return cleaned, nil
+	}
+}
+
+// cleanJSONResponse returns a copy of resp with its text cut down to the JSON it contains and
+// how many characters were removed, or nil when there is nothing to clean
+func cleanJSONResponse(resp *model.LLMResponse) (*model.LLMResponse, int) {
+	if resp == nil || resp.Partial || resp.Content == nil {
+		return nil, 0
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part.FunctionCall != nil {
+			return nil, 0
+		}
+		if part.Text != "" && !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	cleaned, changed := ExtractJSON(text.String())
+	if !changed {
+		return nil, 0
+	}
+
+	// Keep the other parts in place and put the JSON where the first text part was
+	parts := make([]*genai.Part, 0, len(resp.Content.Parts))
+	placed := false
+	for _, part := range resp.Content.Parts {
+		if part.Text == "" || part.Thought {
+			parts = append(parts, part)
+			continue
+		}
+		if !placed {
+			parts = append(parts, genai.NewPartFromText(cleaned))
+			placed = true
+		}
+	}
+
+	cleanedResp := *resp
+	cleanedResp.Content = &genai.Content{Role: resp.Content.Role, Parts: parts}
+	return &cleanedResp, text.Len() - len(cleaned)
+}
+
+// WithJSONOutputCleanup returns a copy of cfg with a JSONOutputCleanup appended to the
+// after-model callbacks. It goes last because a callback that replaces the response skips the
+// ones after it, so callbacks that only observe the response still see every call.
+func WithJSONOutputCleanup(cfg llmagent.Config) llmagent.Config {
+	callbacks := make([]llmagent.AfterModelCallback, 0, len(cfg.AfterModelCallbacks)+1)
+	callbacks = append(callbacks, cfg.AfterModelCallbacks...)
+	cfg.AfterModelCallbacks = append(callbacks, JSONOutputCleanup())
+	return cfg
+}
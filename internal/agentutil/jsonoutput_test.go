@@ -0,0 +1,96 @@
+package agentutil
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+)
+
+func TestExtractJSON(t *testing.T) {
+	const email = `{"subject": "Welcome", "body": "Hi {name},\nthanks for joining."}`
+	tests := []struct {
+		name        string
+		text        string
+		want        string
+		wantChanged bool
+	}{
+		{"clean object", email, email, false},
+		{"clean with whitespace", "\n  " + email + "\n", "\n  " + email + "\n", false},
+		{"clean array", `[1, 2, 3]`, `[1, 2, 3]`, false},
+		{"json fence", "```json\n" + email + "\n```", email, true},
+		{"bare fence", "```\n" + email + "\n```", email, true},
+		{"uppercase tag", "```JSON " + email + "```", email, true},
+		{"fence in prose", "Here is your email:\n\n```json\n" + email + "\n```\n\nLet me know if you'd like changes.", email, true},
+		{"prose before", "Here is the email: " + email, email, true},
+		{"prose around", "Sure! " + email + " Hope this helps.", email, true},
+		{"braces in prose before", "Use {name} as a placeholder. " + email, email, true},
+		{"fence without json falls back", "```\nnot json\n```\n" + email, email, true},
+		{"no json", "I couldn't write that email.", "I couldn't write that email.", false},
+		{"broken json", `{"subject": "Welcome"`, `{"subject": "Welcome"`, false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := ExtractJSON(tt.text)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("ExtractJSON(%q) = %q, %v, want %q, %v", tt.text, got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestCleanJSONResponse(t *testing.T) {
+	fenced := &model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "Drafting the email", Thought: true},
+			{Text: "```json\n{\"subject\": "},
+			{Text: "\"Hi\"}\n```"},
+		}},
+		FinishReason: genai.FinishReasonStop,
+	}
+
+	cleaned, removed := cleanJSONResponse(fenced)
+	if cleaned == nil {
+		t.Fatal("cleanJSONResponse(fenced) = nil, want a cleaned response")
+	}
+	parts := cleaned.Content.Parts
+	if len(parts) != 2 || !parts[0].Thought || parts[1].Text != `{"subject": "Hi"}` {
+		t.Errorf("parts = %+v, want the thought and the bare JSON", parts)
+	}
+	if removed != len("```json\n")+len("\n```") {
+		t.Errorf("removed = %d, want the fence length", removed)
+	}
+	if cleaned.FinishReason != genai.FinishReasonStop || cleaned.Content.Role != genai.RoleModel {
+		t.Errorf("cleaned response = %+v, want the other fields kept", cleaned)
+	}
+	if fenced.Content.Parts[1].Text != "```json\n{\"subject\": " {
+		t.Error("cleanJSONResponse modified the original response")
+	}
+
+	for name, resp := range map[string]*model.LLMResponse{
+		"nil":        nil,
+		"clean json": {Content: genai.NewContentFromText(`{"subject": "Hi"}`, genai.RoleModel)},
+		"partial":    {Content: genai.NewContentFromText("```json\n{}\n```", genai.RoleModel), Partial: true},
+		"function call": {Content: &genai.Content{Parts: []*genai.Part{
+			{Text: "Let me check. ```json\n{}\n```"},
+			genai.NewPartFromFunctionCall("validate_email", nil),
+		}}},
+		"no content": {FinishReason: genai.FinishReasonSafety},
+	} {
+		if got, _ := cleanJSONResponse(resp); got != nil {
+			t.Errorf("cleanJSONResponse(%s) = %+v, want nil (unchanged)", name, got)
+		}
+	}
+}
+
+func TestWithJSONOutputCleanupGoesLast(t *testing.T) {
+	cfg := WithJSONOutputCleanup(llmagent.Config{
+		AfterModelCallbacks: []llmagent.AfterModelCallback{EmptyResponseFallback()},
+	})
+	if len(cfg.AfterModelCallbacks) != 2 {
+		t.Errorf("callbacks = %d, want the existing one plus the cleanup", len(cfg.AfterModelCallbacks))
+	}
+}
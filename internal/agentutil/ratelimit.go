@@ -0,0 +1,177 @@
+package agentutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// ===== Model Rate Limits =====
+//
+// A BeforeModelCallback keeps the times of the session's recent model calls in the
+// "model_call_times" state key and, when the next call would come too soon after the last
+// one or exceed the calls allowed per minute, answers with a throttling response instead of
+// calling the model. Limits come from the environment:
+//
+//	MODEL_MIN_INTERVAL=2s            minimum time between two model calls (Go duration)
+//	MODEL_MAX_CALLS_PER_MINUTE=20    model calls allowed in any 60 seconds
+//
+// A zero value turns that limit off. Tool calls are not counted, only model invocations.
+
+const (
+	MODEL_CALL_TIMES_KEY           = "model_call_times"
+	MODEL_MIN_INTERVAL_ENV         = "MODEL_MIN_INTERVAL"
+	MODEL_MAX_CALLS_PER_MINUTE_ENV = "MODEL_MAX_CALLS_PER_MINUTE"
+	RATE_LIMITED_ERROR_CODE        = "RATE_LIMITED"
+
+	rateLimitWindow = time.Minute
+)
+
+// ModelRateLimit is the per-session limit on model calls. Zero fields are not enforced.
+type ModelRateLimit struct {
+	MinInterval  time.Duration
+	MaxPerMinute int
+}
+
+// Enabled reports whether any limit is set
+func (l ModelRateLimit) Enabled() bool {
+	return l.MinInterval > 0 || l.MaxPerMinute > 0
+}
+
+// LoadModelRateLimit returns defaults with MODEL_MIN_INTERVAL and MODEL_MAX_CALLS_PER_MINUTE,
+// if set, replacing the matching field. getenv is usually os.Getenv.
+func LoadModelRateLimit(getenv func(string) string, defaults ModelRateLimit) (ModelRateLimit, error) {
+	limit := defaults
+
+	if val := strings.TrimSpace(getenv(MODEL_MIN_INTERVAL_ENV)); val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil || interval < 0 {
+			return ModelRateLimit{}, fmt.Errorf("invalid %s %q: want a duration such as 2s", MODEL_MIN_INTERVAL_ENV, val)
+		}
+		limit.MinInterval = interval
+	}
+
+	if val := strings.TrimSpace(getenv(MODEL_MAX_CALLS_PER_MINUTE_ENV)); val != "" {
+		maxCalls, err := strconv.Atoi(val)
+		if err != nil || maxCalls < 0 {
+			return ModelRateLimit{}, fmt.Errorf("invalid %s %q: want a whole number", MODEL_MAX_CALLS_PER_MINUTE_ENV, val)
+		}
+		limit.MaxPerMinute = maxCalls
+	}
+
+	return limit, nil
+}
+
+// RateLimitCallback returns a BeforeModelCallback that enforces limit per session. A call
+// over the limit isn't sent to the model; the agent gets a response with ErrorCode
+// RATE_LIMITED and an ErrorMessage saying how long to wait. Throttled calls are not recorded.
+func RateLimitCallback(limit ModelRateLimit) llmagent.BeforeModelCallback {
+	return rateLimitCallback(limit, time.Now)
+}
+
+func rateLimitCallback(limit ModelRateLimit, now func() time.Time) llmagent.BeforeModelCallback {
+	return func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+		wait, reason, err := reserveModelCall(ctx.State(), limit, now())
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			return nil, nil
+		}
+
+		slog.Warn("model call throttled",
+			"agent", ctx.AgentName(),
+			"session_id", ctx.SessionID(),
+			"reason", reason,
+			"retry_after", wait.Round(time.Millisecond).String())
+		return throttledResponse(reason, wait), nil
+	}
+}
+
+// WithRateLimit returns a copy of cfg with a RateLimitCallback prepended to its before-model
+// callbacks, so throttled calls skip the others too. A disabled limit leaves cfg as is.
+func WithRateLimit(cfg llmagent.Config, limit ModelRateLimit) llmagent.Config {
+	if !limit.Enabled() {
+		return cfg
+	}
+	callbacks := make([]llmagent.BeforeModelCallback, 0, len(cfg.BeforeModelCallbacks)+1)
+	callbacks = append(callbacks, RateLimitCallback(limit))
+	cfg.BeforeModelCallbacks = append(callbacks, cfg.BeforeModelCallbacks...)
+	return cfg
+}
+
+// reserveModelCall checks a call at now against the session's recent calls. When it is
+// allowed, now is recorded and reason is empty; otherwise reason says which limit was hit
+// and wait is how long until the call would be allowed.
+func reserveModelCall(state session.State, limit ModelRateLimit, now time.Time) (time.Duration, string, error) {
+	if !limit.Enabled() {
+		return 0, "", nil
+	}
+
+	// Only calls inside the window matter; older ones are dropped when the list is saved
+	var recent []time.Time
+	for _, t := range getModelCallTimes(state) {
+		if now.Sub(t) < rateLimitWindow {
+			recent = append(recent, t)
+		}
+	}
+
+	if limit.MinInterval > 0 && len(recent) > 0 {
+		if since := now.Sub(recent[len(recent)-1]); since < limit.MinInterval {
+			return limit.MinInterval - since, fmt.Sprintf("less than %s since the last model call", limit.MinInterval), nil
+		}
+	}
+	if limit.MaxPerMinute > 0 && len(recent) >= limit.MaxPerMinute {
+		oldest := recent[len(recent)-limit.MaxPerMinute]
+		return rateLimitWindow - now.Sub(oldest), fmt.Sprintf("%d model calls in the last minute", len(recent)), nil
+	}
+
+	recent = append(recent, now)
+	times := make([]any, 0, len(recent))
+	for _, t := range recent {
+		times = append(times, t.UnixMilli())
+	}
+	if err := state.Set(MODEL_CALL_TIMES_KEY, times); err != nil {
+		return 0, "", fmt.Errorf("failed to set %s: %w", MODEL_CALL_TIMES_KEY, err)
+	}
+	return 0, "", nil
+}
+
+// getModelCallTimes reads the recorded calls, oldest first. Times are stored as Unix
+// milliseconds; after a database round trip the list is []any of float64.
+func getModelCallTimes(state session.ReadonlyState) []time.Time {
+	val, err := state.Get(MODEL_CALL_TIMES_KEY)
+	if err != nil {
+		return nil
+	}
+	list, ok := val.([]any)
+	if !ok {
+		return nil
+	}
+	times := make([]time.Time, 0, len(list))
+	for _, item := range list {
+		if ms := toFloat(item); ms > 0 {
+			times = append(times, time.UnixMilli(int64(ms)))
+		}
+	}
+	return times
+}
+
+// throttledResponse has no content, so an agent's OutputKey keeps its previous value
+// (in the loop agent, current_post isn't replaced by the throttling message)
+func throttledResponse(reason string, wait time.Duration) *model.LLMResponse {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	message := fmt.Sprintf("Model call limit reached for this session (%s). Please try again in %d second(s).", reason, seconds)
+	return &model.LLMResponse{
+		ErrorCode:    RATE_LIMITED_ERROR_CODE,
+		ErrorMessage: message,
+		TurnComplete: true,
+	}
+}
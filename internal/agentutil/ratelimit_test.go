@@ -0,0 +1,97 @@
+package agentutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestLoadModelRateLimit(t *testing.T) {
+	defaults := ModelRateLimit{MinInterval: time.Second, MaxPerMinute: 20}
+	env := map[string]string{MODEL_MIN_INTERVAL_ENV: "500ms", MODEL_MAX_CALLS_PER_MINUTE_ENV: "0"}
+
+	limit, err := LoadModelRateLimit(func(key string) string { return env[key] }, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ModelRateLimit{MinInterval: 500 * time.Millisecond}); limit != want {
+		t.Errorf("limit = %+v, want %+v", limit, want)
+	}
+
+	limit, err = LoadModelRateLimit(func(string) string { return "" }, defaults)
+	if err != nil || limit != defaults {
+		t.Errorf("unset env = %+v, %v, want defaults", limit, err)
+	}
+
+	for key, val := range map[string]string{MODEL_MIN_INTERVAL_ENV: "2", MODEL_MAX_CALLS_PER_MINUTE_ENV: "-1"} {
+		if _, err := LoadModelRateLimit(func(k string) string {
+			if k == key {
+				return val
+			}
+			return ""
+		}, defaults); err == nil {
+			t.Errorf("%s=%q: expected an error", key, val)
+		}
+	}
+}
+
+func TestReserveModelCallMinInterval(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	limit := ModelRateLimit{MinInterval: 2 * time.Second}
+	start := time.UnixMilli(1_700_000_000_000)
+
+	if _, reason, err := reserveModelCall(state, limit, start); err != nil || reason != "" {
+		t.Fatalf("first call throttled: %q, %v", reason, err)
+	}
+	wait, reason, err := reserveModelCall(state, limit, start.Add(500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason == "" || wait != 1500*time.Millisecond {
+		t.Errorf("second call = wait %s, reason %q, want throttled for 1.5s", wait, reason)
+	}
+	if got := len(getModelCallTimes(state)); got != 1 {
+		t.Errorf("recorded %d calls, want the throttled one left out", got)
+	}
+	if _, reason, _ := reserveModelCall(state, limit, start.Add(2*time.Second)); reason != "" {
+		t.Errorf("call after the interval throttled: %q", reason)
+	}
+}
+
+func TestReserveModelCallMaxPerMinute(t *testing.T) {
+	// Times come back as float64 after a database round trip
+	start := time.UnixMilli(1_700_000_000_000)
+	state := agenttest.NewState(map[string]any{MODEL_CALL_TIMES_KEY: []any{
+		float64(start.Add(-90 * time.Second).UnixMilli()), // outside the window
+		float64(start.Add(-40 * time.Second).UnixMilli()),
+		float64(start.Add(-10 * time.Second).UnixMilli()),
+	}}, false)
+	limit := ModelRateLimit{MaxPerMinute: 3}
+
+	if _, reason, _ := reserveModelCall(state, limit, start); reason != "" {
+		t.Fatalf("third call in the window throttled: %q", reason)
+	}
+	if got := len(getModelCallTimes(state)); got != 3 {
+		t.Errorf("kept %d calls, want the one outside the window dropped", got)
+	}
+
+	wait, reason, _ := reserveModelCall(state, limit, start.Add(time.Second))
+	if reason == "" || wait != 19*time.Second {
+		t.Errorf("fourth call = wait %s, reason %q, want throttled until the oldest call leaves the window", wait, reason)
+	}
+}
+
+func TestThrottledResponse(t *testing.T) {
+	resp := throttledResponse("too many calls", 1500*time.Millisecond)
+	if resp.ErrorCode != RATE_LIMITED_ERROR_CODE || !resp.TurnComplete {
+		t.Errorf("response = %+v, want a complete RATE_LIMITED response", resp)
+	}
+	if resp.Content != nil {
+		t.Errorf("response has content %+v, would overwrite the agent's output key", resp.Content)
+	}
+	if !strings.Contains(resp.ErrorMessage, "2 second(s)") {
+		t.Errorf("error message %q doesn't round the wait up to 2 seconds", resp.ErrorMessage)
+	}
+}
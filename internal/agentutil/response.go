@@ -0,0 +1,97 @@
+package agentutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+)
+
+// ===== Empty Responses =====
+//
+// The model sometimes answers with no text at all: the response was blocked for safety, the
+// candidate was empty, or it hit the token limit before writing anything. ADK drops a response
+// without content, so a chat loop that prints the final text shows nothing and the user can't
+// tell what happened. EmptyResponseFallback replaces such a response with FALLBACK_RESPONSE
+// and logs why it was empty.
+
+// FALLBACK_RESPONSE is shown instead of an empty model response
+const FALLBACK_RESPONSE = "I wasn't able to generate a response, could you rephrase?"
+
+// EmptyResponseFallback returns an AfterModelCallback that replaces a complete response with
+// no text and no function calls by FALLBACK_RESPONSE, and logs its finish reason and error
+// code. Errors and partial stream chunks are left alone.
+func EmptyResponseFallback() llmagent.AfterModelCallback {
+	return func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+		if respErr != nil || resp == nil || resp.Partial || hasOutput(resp.Content) {
+			return nil, nil
+		}
+
+		slog.Warn("empty model response, replying with a fallback",
+			"agent", ctx.AgentName(),
+			"session_id", ctx.SessionID(),
+			"finish_reason", resp.FinishReason,
+			"error_code", resp.ErrorCode,
+			"error_message", resp.ErrorMessage)
+
+		fallback := *resp
+		fallback.Content = genai.NewContentFromText(FALLBACK_RESPONSE, genai.RoleModel)
+		return &fallback, nil
+	}
+}
+
+// WithEmptyResponseFallback returns a copy of cfg with an EmptyResponseFallback appended to
+// the after-model callbacks
+func WithEmptyResponseFallback(cfg llmagent.Config) llmagent.Config {
+	callbacks := make([]llmagent.AfterModelCallback, 0, len(cfg.AfterModelCallbacks)+1)
+	callbacks = append(callbacks, cfg.AfterModelCallbacks...)
+	cfg.AfterModelCallbacks = append(callbacks, EmptyResponseFallback())
+	return cfg
+}
+
+// EmptyResponseReason describes why resp has no text, from its finish reason and error fields,
+// e.g. "finish_reason=SAFETY, no content". A nil resp means the run produced no response.
+func EmptyResponseReason(resp *model.LLMResponse) string {
+	if resp == nil {
+		return "no response"
+	}
+
+	var fields []string
+	if resp.FinishReason != "" {
+		fields = append(fields, fmt.Sprintf("finish_reason=%s", resp.FinishReason))
+	}
+	if resp.ErrorCode != "" {
+		fields = append(fields, fmt.Sprintf("error_code=%s", resp.ErrorCode))
+	}
+	if resp.ErrorMessage != "" {
+		fields = append(fields, fmt.Sprintf("error_message=%q", resp.ErrorMessage))
+	}
+	if resp.Content == nil || len(resp.Content.Parts) == 0 {
+		fields = append(fields, "no content")
+	}
+	if len(fields) == 0 {
+		return "empty text"
+	}
+	return strings.Join(fields, ", ")
+}
+
+// hasOutput reports whether content has text (other than thoughts) or function calls
+func hasOutput(content *genai.Content) bool {
+	if content == nil {
+		return false
+	}
+	for _, part := range content.Parts {
+		if part == nil {
+			continue
+		}
+		if (part.Text != "" && !part.Thought) || part.FunctionCall != nil || part.FunctionResponse != nil {
+			return true
+		}
+	}
+	return false
+}
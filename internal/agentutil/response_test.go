@@ -0,0 +1,61 @@
+package agentutil
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestEmptyResponseReason(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *model.LLMResponse
+		want string
+	}{
+		{"no response", nil, "no response"},
+		{
+			"safety block",
+			&model.LLMResponse{FinishReason: genai.FinishReasonSafety},
+			"finish_reason=SAFETY, no content",
+		},
+		{
+			"error",
+			&model.LLMResponse{ErrorCode: "RESOURCE_EXHAUSTED", ErrorMessage: "quota exceeded"},
+			`error_code=RESOURCE_EXHAUSTED, error_message="quota exceeded", no content`,
+		},
+		{
+			"empty text part",
+			&model.LLMResponse{Content: genai.NewContentFromText("", genai.RoleModel)},
+			"empty text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EmptyResponseReason(tt.resp); got != tt.want {
+				t.Errorf("EmptyResponseReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *genai.Content
+		want    bool
+	}{
+		{"nil", nil, false},
+		{"no parts", &genai.Content{Role: genai.RoleModel}, false},
+		{"empty text", genai.NewContentFromText("", genai.RoleModel), false},
+		{"only a thought", &genai.Content{Parts: []*genai.Part{{Text: "hmm", Thought: true}}}, false},
+		{"text", genai.NewContentFromText("Hi!", genai.RoleModel), true},
+		{"function call", genai.NewContentFromFunctionCall("view_reminders", nil, genai.RoleModel), true},
+	}
+	for _, tt := range tests {
+		if got := hasOutput(tt.content); got != tt.want {
+			t.Errorf("hasOutput(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package agentutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+)
+
+// ===== Source Citations =====
+//
+// An agent using Google Search gets the pages it read in the response's grounding metadata, but
+// its text rarely says where the facts came from. CiteSources is an opt-in AfterModelCallback that
+// appends a "Sources:" list with the title and URI of each page to the response text, so users
+// can check the summary. Responses without grounding metadata are left unchanged.
+
+// SOURCES_HEADING starts the list CiteSources appends
+const SOURCES_HEADING = "Sources:"
+
+// Source is a page a grounded response was based on
+type Source struct {
+	Title string
+	URI   string
+}
+
+// GroundingSources returns the web pages in meta, in order and without duplicate URIs. Chunks
+// without a URI are skipped.
+func GroundingSources(meta *genai.GroundingMetadata) []Source {
+	if meta == nil {
+		return nil
+	}
+	var sources []Source
+	seen := map[string]bool{}
+	for _, chunk := range meta.GroundingChunks {
+		var src Source
+		switch {
+		case chunk == nil:
+			continue
+		case chunk.Web != nil:
+			src = Source{Title: chunk.Web.Title, URI: chunk.Web.URI}
+		case chunk.RetrievedContext != nil:
+			src = Source{Title: chunk.RetrievedContext.Title, URI: chunk.RetrievedContext.URI}
+		}
+		src.Title, src.URI = strings.TrimSpace(src.Title), strings.TrimSpace(src.URI)
+		if src.URI == "" || seen[src.URI] {
+			continue
+		}
+		seen[src.URI] = true
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// FormatSources returns sources as a "Sources:" list with one numbered line per page, or "" when
+// there are none
+func FormatSources(sources []Source) string {
+	if len(sources) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(SOURCES_HEADING)
+	for i, src := range sources {
+		if src.Title != "" {
+			fmt.Fprintf(&b, "\n%d. %s - %s", i+1, src.Title, src.URI)
+		} else {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, src.URI)
+		}
+	}
+	return b.String()
+}
+
+// CiteSources returns an AfterModelCallback that appends the grounding sources of a complete
+// response to its text (see FormatSources). Errors, partial stream chunks, function calls and
+// responses without sources are left alone.
+func CiteSources() llmagent.AfterModelCallback {
+	return func(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+		if respErr != nil {
+			return nil, nil
+		}
+		cited, count := citeSourcesResponse(resp)
+		if cited == nil {
+			return nil, nil
+		}
+
+		slog.Info("sources cited",
+			"agent", ctx.AgentName(),
+			"session_id", ctx.SessionID(),
+			"sources", count)
+		return cited, nil
+	}
+}
+
+// citeSourcesResponse returns a copy of resp with its sources appended to the last text part and
+// how many were cited, or nil when there is nothing to cite
+func citeSourcesResponse(resp *model.LLMResponse) (*model.LLMResponse, int) {
+	if resp == nil || resp.Partial || resp.Content == nil {
+		return nil, 0
+	}
+	sources := GroundingSources(resp.GroundingMetadata)
+	if len(sources) == 0 {
+		return nil, 0
+	}
+
+	last := -1
+	for i, part := range resp.Content.Parts {
+		if part.FunctionCall != nil {
+			return nil, 0
+		}
+		if part.Text != "" && !part.Thought {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, 0
+	}
+
+	// Copy the parts so the original response, which may be shared, is not modified
+	parts := make([]*genai.Part, len(resp.Content.Parts))
+	copy(parts, resp.Content.Parts)
+	text := strings.TrimRight(parts[last].Text, "\n ") + "\n\n" + FormatSources(sources)
+	parts[last] = genai.NewPartFromText(text)
+
+	citedResp := *resp
+	citedResp.Content = &genai.Content{Role: resp.Content.Role, Parts: parts}
+	return &citedResp, len(sources)
+}
@@ -0,0 +1,100 @@
+package agentutil
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func webChunk(title, uri string) *genai.GroundingChunk {
+	return &genai.GroundingChunk{Web: &genai.GroundingChunkWeb{Title: title, URI: uri}}
+}
+
+func TestGroundingSources(t *testing.T) {
+	meta := &genai.GroundingMetadata{GroundingChunks: []*genai.GroundingChunk{
+		webChunk("theverge.com", "https://example.com/verge"),
+		webChunk(" reuters.com ", "https://example.com/reuters"),
+		webChunk("theverge.com", "https://example.com/verge"),
+		webChunk("no link", ""),
+		nil,
+		{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: "gs://docs/ai.txt"}},
+	}}
+
+	want := []Source{
+		{"theverge.com", "https://example.com/verge"},
+		{"reuters.com", "https://example.com/reuters"},
+		{"", "gs://docs/ai.txt"},
+	}
+	if got := GroundingSources(meta); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroundingSources() = %v, want %v", got, want)
+	}
+	if got := GroundingSources(nil); got != nil {
+		t.Errorf("GroundingSources(nil) = %v, want nil", got)
+	}
+}
+
+func TestFormatSources(t *testing.T) {
+	got := FormatSources([]Source{{"reuters.com", "https://example.com/reuters"}, {"", "gs://docs/ai.txt"}})
+	want := "Sources:\n1. reuters.com - https://example.com/reuters\n2. gs://docs/ai.txt"
+	if got != want {
+		t.Errorf("FormatSources() = %q, want %q", got, want)
+	}
+	if got := FormatSources(nil); got != "" {
+		t.Errorf("FormatSources(nil) = %q, want \"\"", got)
+	}
+}
+
+func TestCiteSourcesResponse(t *testing.T) {
+	grounded := &model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "Looking for AI news", Thought: true},
+			{Text: "OpenAI and Google released new models.\n"},
+		}},
+		GroundingMetadata: &genai.GroundingMetadata{
+			WebSearchQueries: []string{"latest ai news"},
+			GroundingChunks:  []*genai.GroundingChunk{webChunk("reuters.com", "https://example.com/reuters")},
+		},
+	}
+
+	cited, count := citeSourcesResponse(grounded)
+	if cited == nil || count != 1 {
+		t.Fatalf("citeSourcesResponse(grounded) = %v, %d, want a cited response", cited, count)
+	}
+	parts := cited.Content.Parts
+	want := "OpenAI and Google released new models.\n\nSources:\n1. reuters.com - https://example.com/reuters"
+	if len(parts) != 2 || !parts[0].Thought || parts[1].Text != want {
+		t.Errorf("parts = %+v, want the thought and the text with sources", parts)
+	}
+	if grounded.Content.Parts[1].Text != "OpenAI and Google released new models.\n" {
+		t.Error("citeSourcesResponse modified the original response")
+	}
+	if cited.GroundingMetadata != grounded.GroundingMetadata {
+		t.Error("citeSourcesResponse dropped the grounding metadata")
+	}
+
+	unchanged := map[string]*model.LLMResponse{
+		"nil":          nil,
+		"no grounding": {Content: genai.NewContentFromText("No news today.", genai.RoleModel)},
+		"no sources": {
+			Content:           genai.NewContentFromText("No news today.", genai.RoleModel),
+			GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: []string{"news"}},
+		},
+		"partial": {
+			Content:           genai.NewContentFromText("OpenAI and", genai.RoleModel),
+			GroundingMetadata: grounded.GroundingMetadata,
+			Partial:           true,
+		},
+		"function call": {
+			Content:           genai.NewContentFromParts([]*genai.Part{genai.NewPartFromFunctionCall("get_current_time", nil)}, genai.RoleModel),
+			GroundingMetadata: grounded.GroundingMetadata,
+		},
+	}
+	for name, resp := range unchanged {
+		if cited, _ := citeSourcesResponse(resp); cited != nil {
+			t.Errorf("%s: citeSourcesResponse() = %+v, want nil", name, cited)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package agentutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/session"
+)
+
+// ===== Conversation Turn Limit =====
+//
+// A BeforeAgentCallback counts the user turns of the session and, once there are more than
+// the limit allows, answers with a wrap-up message instead of running the agent: the model
+// isn't called and no tools run, so a long REPL session can't grow its context without bound.
+// Settings come from the environment:
+//
+//	MAX_CONVERSATION_TURNS=50    user turns allowed per session, 0 for no limit
+//	WRAP_UP_MESSAGE="..."        reply once the limit is reached; {max_turns} is replaced by the limit
+//
+// Turns are counted from the session's stored events rather than a counter in memory, so a
+// session resumed after a restart keeps its count.
+
+const (
+	MAX_CONVERSATION_TURNS_ENV = "MAX_CONVERSATION_TURNS"
+	WRAP_UP_MESSAGE_ENV        = "WRAP_UP_MESSAGE"
+
+	// MAX_TURNS_PLACEHOLDER in a wrap-up message is replaced by the limit
+	MAX_TURNS_PLACEHOLDER = "{max_turns}"
+
+	DEFAULT_WRAP_UP_MESSAGE = "We've reached the end of this conversation ({max_turns} messages). " +
+		"Thanks for chatting! Please start a new session to continue; anything I've saved for you carries over."
+)
+
+// TurnLimit is the per-session limit on user turns. A zero MaxTurns is not enforced.
+type TurnLimit struct {
+	MaxTurns int
+	Message  string // wrap-up reply; DEFAULT_WRAP_UP_MESSAGE when empty
+}
+
+// Enabled reports whether the limit is set
+func (l TurnLimit) Enabled() bool {
+	return l.MaxTurns > 0
+}
+
+// WrapUpMessage returns the wrap-up reply with MAX_TURNS_PLACEHOLDER filled in
+func (l TurnLimit) WrapUpMessage() string {
+	message := l.Message
+	if strings.TrimSpace(message) == "" {
+		message = DEFAULT_WRAP_UP_MESSAGE
+	}
+	return strings.ReplaceAll(message, MAX_TURNS_PLACEHOLDER, strconv.Itoa(l.MaxTurns))
+}
+
+// LoadTurnLimit returns defaults with MAX_CONVERSATION_TURNS and WRAP_UP_MESSAGE, if set,
+// replacing the matching field. getenv is usually os.Getenv.
+func LoadTurnLimit(getenv func(string) string, defaults TurnLimit) (TurnLimit, error) {
+	limit := defaults
+
+	if val := strings.TrimSpace(getenv(MAX_CONVERSATION_TURNS_ENV)); val != "" {
+		maxTurns, err := strconv.Atoi(val)
+		if err != nil || maxTurns < 0 {
+			return TurnLimit{}, fmt.Errorf("invalid %s %q: want a whole number", MAX_CONVERSATION_TURNS_ENV, val)
+		}
+		limit.MaxTurns = maxTurns
+	}
+
+	if val := strings.TrimSpace(getenv(WRAP_UP_MESSAGE_ENV)); val != "" {
+		limit.Message = val
+	}
+
+	return limit, nil
+}
+
+// CountUserTurns returns the number of user messages in events. Function responses and
+// agent replies are not turns.
+func CountUserTurns(events session.Events) int {
+	turns := 0
+	for event := range events.All() {
+		if event.Author == "user" && event.Content != nil && len(event.Content.Parts) > 0 {
+			turns++
+		}
+	}
+	return turns
+}
+
+// TurnLimitCallback returns a BeforeAgentCallback that wraps up sessions with more than
+// limit.MaxTurns user turns. The callback context doesn't expose the session's events, so
+// they are read from sessions, which should be the service given to the runner; the runner
+// saves the user's message before the agent runs, so the count includes the current turn.
+func TurnLimitCallback(sessions session.Service, limit TurnLimit) agent.BeforeAgentCallback {
+	return func(ctx agent.CallbackContext) (*genai.Content, error) {
+		resp, err := sessions.Get(ctx, &session.GetRequest{
+			AppName:   ctx.AppName(),
+			UserID:    ctx.UserID(),
+			SessionID: ctx.SessionID(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session %s to count turns: %w", ctx.SessionID(), err)
+		}
+
+		turns := CountUserTurns(resp.Session.Events())
+		if turns <= limit.MaxTurns {
+			return nil, nil
+		}
+
+		slog.Info("conversation wrapped up",
+			"agent", ctx.AgentName(),
+			"session_id", ctx.SessionID(),
+			"turns", turns,
+			"max_turns", limit.MaxTurns)
+		return genai.NewContentFromText(limit.WrapUpMessage(), genai.RoleModel), nil
+	}
+}
+
+// WithTurnLimit returns a copy of cfg with a TurnLimitCallback prepended to its before-agent
+// callbacks, so a wrapped-up turn skips the others too. A disabled limit leaves cfg as is.
+func WithTurnLimit(cfg llmagent.Config, sessions session.Service, limit TurnLimit) llmagent.Config {
+	if !limit.Enabled() {
+		return cfg
+	}
+	callbacks := make([]agent.BeforeAgentCallback, 0, len(cfg.BeforeAgentCallbacks)+1)
+	callbacks = append(callbacks, TurnLimitCallback(sessions, limit))
+	cfg.BeforeAgentCallbacks = append(callbacks, cfg.BeforeAgentCallbacks...)
+	return cfg
+}
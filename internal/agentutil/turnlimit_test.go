@@ -0,0 +1,136 @@
+package agentutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// turnContext is an agent.CallbackContext for the session emitterContext names; only the
+// identity and context.Context methods work
+type turnContext struct {
+	emitterContext
+	context.Context
+}
+
+func (c turnContext) Deadline() (time.Time, bool) { return c.Context.Deadline() }
+func (c turnContext) Done() <-chan struct{}       { return c.Context.Done() }
+func (c turnContext) Err() error                  { return c.Context.Err() }
+func (c turnContext) Value(key any) any           { return c.Context.Value(key) }
+
+var _ agent.CallbackContext = turnContext{}
+
+// newTurnSession creates session-1 with the given number of user turns, each followed by an
+// agent reply, and returns the service
+func newTurnSession(t *testing.T, turns int) session.Service {
+	t.Helper()
+	ctx := context.Background()
+	sessions := session.InMemoryService()
+	resp, err := sessions.Create(ctx, &session.CreateRequest{AppName: "shop", UserID: "user-1", SessionID: "session-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < turns; i++ {
+		for _, event := range []*session.Event{
+			{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hi", genai.RoleUser)}},
+			{Author: "assistant", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello", genai.RoleModel)}},
+		} {
+			event.ID = session.NewEvent("inv").ID
+			event.Timestamp = time.Now()
+			if err := sessions.AppendEvent(ctx, resp.Session, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return sessions
+}
+
+func TestLoadTurnLimit(t *testing.T) {
+	defaults := TurnLimit{MaxTurns: 50}
+	env := map[string]string{MAX_CONVERSATION_TURNS_ENV: "10", WRAP_UP_MESSAGE_ENV: " Bye after {max_turns}! "}
+
+	limit, err := LoadTurnLimit(func(key string) string { return env[key] }, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TurnLimit{MaxTurns: 10, Message: "Bye after {max_turns}!"}); limit != want {
+		t.Errorf("limit = %+v, want %+v", limit, want)
+	}
+	if got := limit.WrapUpMessage(); got != "Bye after 10!" {
+		t.Errorf("WrapUpMessage() = %q, want the limit filled in", got)
+	}
+
+	limit, err = LoadTurnLimit(func(string) string { return "" }, defaults)
+	if err != nil || limit != defaults {
+		t.Errorf("unset env = %+v, %v, want defaults", limit, err)
+	}
+	if got := limit.WrapUpMessage(); !strings.Contains(got, "(50 messages)") {
+		t.Errorf("default WrapUpMessage() = %q, want the default message with the limit", got)
+	}
+
+	for _, val := range []string{"-1", "ten", "2.5"} {
+		if _, err := LoadTurnLimit(func(k string) string {
+			if k == MAX_CONVERSATION_TURNS_ENV {
+				return val
+			}
+			return ""
+		}, defaults); err == nil {
+			t.Errorf("%s=%q: expected an error", MAX_CONVERSATION_TURNS_ENV, val)
+		}
+	}
+}
+
+func TestTurnLimitCallback(t *testing.T) {
+	limit := TurnLimit{MaxTurns: 3, Message: "Limit of {max_turns} reached."}
+	ctx := turnContext{emitterContext{agentName: "assistant"}, context.Background()}
+
+	// The runner saves the current message before the agent runs, so 3 stored turns are the
+	// last allowed one
+	for turns, wantWrapUp := range map[int]bool{0: false, 1: false, 3: false, 4: true, 9: true} {
+		content, err := TurnLimitCallback(newTurnSession(t, turns), limit)(ctx)
+		if err != nil {
+			t.Fatalf("%d turns: %v", turns, err)
+		}
+		if (content != nil) != wantWrapUp {
+			t.Errorf("%d turns: wrap-up = %v, want %v", turns, content != nil, wantWrapUp)
+			continue
+		}
+		if content != nil && (content.Role != genai.RoleModel || content.Parts[0].Text != "Limit of 3 reached.") {
+			t.Errorf("%d turns: content = %+v, want the wrap-up message", turns, content)
+		}
+	}
+
+	if _, err := TurnLimitCallback(session.InMemoryService(), limit)(ctx); err == nil {
+		t.Error("missing session: expected an error")
+	}
+}
+
+func TestCountUserTurns(t *testing.T) {
+	sessions := newTurnSession(t, 2)
+	resp, err := sessions.Get(context.Background(), &session.GetRequest{AppName: "shop", UserID: "user-1", SessionID: "session-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := CountUserTurns(resp.Session.Events()); got != 2 {
+		t.Errorf("CountUserTurns() = %d, want only the 2 user messages", got)
+	}
+}
+
+func TestWithTurnLimit(t *testing.T) {
+	if cfg := WithTurnLimit(llmagent.Config{}, nil, TurnLimit{}); len(cfg.BeforeAgentCallbacks) != 0 {
+		t.Errorf("disabled limit added %d callbacks", len(cfg.BeforeAgentCallbacks))
+	}
+	cfg := llmagent.Config{}
+	cfg.BeforeAgentCallbacks = []agent.BeforeAgentCallback{func(agent.CallbackContext) (*genai.Content, error) { return nil, nil }}
+	if cfg := WithTurnLimit(cfg, session.InMemoryService(), TurnLimit{MaxTurns: 5}); len(cfg.BeforeAgentCallbacks) != 2 {
+		t.Errorf("callbacks = %d, want the limit prepended to the existing one", len(cfg.BeforeAgentCallbacks))
+	}
+}
@@ -0,0 +1,68 @@
+// Package agentutil provides helpers shared by the multi-agent examples.
+package agentutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/adk/agent"
+)
+
+// ValidateAgentTree walks root and its sub-agents and reports agents that can't be told apart
+// when routing: duplicate names anywhere in the tree, empty names, and the reserved name "user".
+// ADK only rejects the same agent instance listed twice, so two different agents with the same
+// Name would otherwise be accepted and transfers to that name would silently pick one of them.
+func ValidateAgentTree(root agent.Agent) error {
+	if root == nil {
+		return errors.New("agent tree has no root agent")
+	}
+
+	paths := map[string][]string{}
+	var problems []string
+	visited := map[agent.Agent]bool{}
+
+	var walk func(a agent.Agent, parentPath string)
+	walk = func(a agent.Agent, parentPath string) {
+		path := a.Name()
+		if parentPath != "" {
+			path = parentPath + "/" + a.Name()
+		}
+
+		switch {
+		case a.Name() == "":
+			problems = append(problems, fmt.Sprintf("agent at %q has an empty name", path))
+		case a.Name() == "user":
+			problems = append(problems, fmt.Sprintf("agent at %q uses the reserved name \"user\"", path))
+		default:
+			paths[a.Name()] = append(paths[a.Name()], path)
+		}
+
+		// An agent reached twice is already reported as a duplicate name; don't walk it again
+		if visited[a] {
+			return
+		}
+		visited[a] = true
+		for _, sub := range a.SubAgents() {
+			walk(sub, path)
+		}
+	}
+	walk(root, "")
+
+	var names []string
+	for name, found := range paths {
+		if len(found) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, fmt.Sprintf("name %q is used by %d agents (%s)", name, len(paths[name]), strings.Join(paths[name], ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid agent tree %q: %s", root.Name(), strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package agentutil
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/agent"
+)
+
+func newAgent(t *testing.T, name string, subAgents ...agent.Agent) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{Name: name, Description: name + " agent", SubAgents: subAgents})
+	if err != nil {
+		t.Fatalf("agent.New(%q) failed: %v", name, err)
+	}
+	return a
+}
+
+func TestValidateAgentTreeUniqueNames(t *testing.T) {
+	root := newAgent(t, "customer_service",
+		newAgent(t, "sales_agent"),
+		newAgent(t, "order_agent", newAgent(t, "refund_agent")),
+	)
+
+	if err := ValidateAgentTree(root); err != nil {
+		t.Errorf("ValidateAgentTree() = %v, want nil", err)
+	}
+}
+
+func TestValidateAgentTreeDuplicateNames(t *testing.T) {
+	root := newAgent(t, "customer_service",
+		newAgent(t, "support"),
+		newAgent(t, "order_agent", newAgent(t, "support")),
+	)
+
+	err := ValidateAgentTree(root)
+	if err == nil {
+		t.Fatal("ValidateAgentTree() = nil, want an error for the duplicated name")
+	}
+	for _, want := range []string{`"support"`, "customer_service/support", "customer_service/order_agent/support"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestValidateAgentTreeReservedName(t *testing.T) {
+	root := newAgent(t, "manager", newAgent(t, "user"))
+
+	if err := ValidateAgentTree(root); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("ValidateAgentTree() = %v, want a reserved name error", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Package logging provides the shared structured logging setup for the Go examples.
+//
+// Operational logs (agent creation, session setup, errors) go through log/slog so they can be
+// parsed in production, while user-facing banners stay on fmt. Configure it with:
+//   - LOG_FORMAT: "text" (default) or "json"
+//   - LOG_LEVEL: "debug", "info" (default), "warn" or "error"
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup builds a logger from LOG_FORMAT and LOG_LEVEL, installs it as the slog default and returns it.
+// Unknown values fall back to the defaults and are reported with a warning.
+func Setup() *slog.Logger {
+	var warnings []string
+
+	level := slog.LevelInfo
+	if raw := strings.TrimSpace(os.Getenv("LOG_LEVEL")); raw != "" {
+		if err := level.UnmarshalText([]byte(raw)); err != nil {
+			level = slog.LevelInfo
+			warnings = append(warnings, fmt.Sprintf("invalid LOG_LEVEL %q, using info", raw))
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format := strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))); format {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		handler = slog.NewTextHandler(os.Stderr, opts)
+		warnings = append(warnings, fmt.Sprintf("invalid LOG_FORMAT %q, using text", format))
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	for _, warning := range warnings {
+		logger.Warn(warning)
+	}
+
+	return logger
+}
+
+// Fatal logs msg at error level with the given attributes and exits with status 1,
+// the slog counterpart of log.Fatalf
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
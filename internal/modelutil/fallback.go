@@ -0,0 +1,138 @@
+// Package modelutil provides model.LLM wrappers shared by the examples.
+package modelutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// ===== Fallback Model =====
+//
+// FallbackModel wraps an ordered list of models. A request goes to the first model; if it
+// fails with a retryable error (rate limit, overload, server error, network timeout) before
+// any response was produced, the same request is sent to the next model, and so on.
+// Non-retryable errors such as an invalid API key or a bad request are returned right away,
+// because another model would fail the same way.
+//
+// Name is always the primary's name. The model that served a call is recorded on each of its
+// responses under CustomMetadata[RESPONSE_MODEL_KEY], since one FallbackModel is shared by
+// every agent and session.
+
+// RESPONSE_MODEL_KEY is the LLMResponse.CustomMetadata key holding the name of the model that
+// served the response
+const RESPONSE_MODEL_KEY = "model"
+
+// FallbackModel is a model.LLM that falls back to the next model on retryable errors
+type FallbackModel struct {
+	models []model.LLM
+}
+
+// NewFallbackModel creates a FallbackModel trying models in order; the first is the primary
+func NewFallbackModel(models ...model.LLM) (*FallbackModel, error) {
+	if len(models) == 0 {
+		return nil, fmt.Errorf("fallback model needs at least one model")
+	}
+	for i, m := range models {
+		if m == nil {
+			return nil, fmt.Errorf("fallback model %d is nil", i)
+		}
+	}
+	return &FallbackModel{models: models}, nil
+}
+
+// Name returns the primary model's name
+func (f *FallbackModel) Name() string {
+	return f.models[0].Name()
+}
+
+// GenerateContent sends the request to each model in turn until one succeeds or fails
+// with a non-retryable error. Once a model has yielded a response, its later errors are
+// passed through, since the caller may already have used part of the answer.
+func (f *FallbackModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for i, m := range f.models {
+			last := i == len(f.models)-1
+			yielded := false
+			var failure error
+
+			for resp, err := range m.GenerateContent(ctx, req, stream) {
+				if err != nil && !yielded && !last && ctx.Err() == nil && IsRetryable(err) {
+					failure = err
+					break
+				}
+				if !yielded {
+					yielded = true
+					if err == nil {
+						slog.Info("model response", "model", m.Name(), "primary", f.Name(), "fallback", i > 0)
+					}
+				}
+				if resp != nil {
+					resp.CustomMetadata = withResponseModel(resp.CustomMetadata, m.Name())
+				}
+				if !yield(resp, err) {
+					return
+				}
+			}
+
+			if failure == nil {
+				return
+			}
+			slog.Warn("model failed, falling back", "model", m.Name(), "next_model", f.models[i+1].Name(), "error", failure)
+		}
+	}
+}
+
+// withResponseModel returns a copy of metadata with the serving model's name added
+func withResponseModel(metadata map[string]any, name string) map[string]any {
+	out := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	out[RESPONSE_MODEL_KEY] = name
+	return out
+}
+
+// ResponseModel returns the name of the model that served resp as recorded by FallbackModel,
+// or defaultName when the response doesn't record one
+func ResponseModel(resp *model.LLMResponse, defaultName string) string {
+	if resp != nil {
+		if name, ok := resp.CustomMetadata[RESPONSE_MODEL_KEY].(string); ok && name != "" {
+			return name
+		}
+	}
+	return defaultName
+}
+
+// IsRetryable reports whether another model might succeed where err occurred: rate limits,
+// server errors and network timeouts are retryable; auth failures and bad requests are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,149 @@
+package modelutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// fakeModel returns its responses, then err (if set); calls counts GenerateContent calls
+type fakeModel struct {
+	name      string
+	responses []string
+	err       error
+	calls     int
+}
+
+func (m *fakeModel) Name() string { return m.name }
+
+func (m *fakeModel) GenerateContent(_ context.Context, _ *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	m.calls++
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for _, text := range m.responses {
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)}, nil) {
+				return
+			}
+		}
+		if m.err != nil {
+			yield(nil, m.err)
+		}
+	}
+}
+
+func collect(t *testing.T, f *FallbackModel) ([]string, error) {
+	t.Helper()
+	var texts []string
+	for resp, err := range f.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, resp.Content.Parts[0].Text)
+	}
+	return texts, nil
+}
+
+func TestFallbackOnRetryableError(t *testing.T) {
+	primary := &fakeModel{name: "primary", err: fmt.Errorf("failed to call model: %w", genai.APIError{Code: 503})}
+	backup := &fakeModel{name: "backup", responses: []string{"hello"}}
+	f, err := NewFallbackModel(primary, backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts, err := collect(t, f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(texts) != 1 || texts[0] != "hello" {
+		t.Errorf("got %v, want the backup's response", texts)
+	}
+	if f.Name() != "primary" {
+		t.Errorf("Name() = %q, want the primary's name", f.Name())
+	}
+}
+
+func TestFallbackRecordsServingModel(t *testing.T) {
+	primary := &fakeModel{name: "primary", err: genai.APIError{Code: 503}}
+	backup := &fakeModel{name: "backup", responses: []string{"hello"}}
+	f, _ := NewFallbackModel(primary, backup)
+
+	for resp, err := range f.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ResponseModel(resp, f.Name()); got != "backup" {
+			t.Errorf("ResponseModel() = %q, want the backup that served the response", got)
+		}
+	}
+
+	// Responses that don't come through a FallbackModel use the default
+	if got := ResponseModel(&model.LLMResponse{}, "primary"); got != "primary" {
+		t.Errorf("ResponseModel() without metadata = %q, want the default", got)
+	}
+}
+
+func TestNoFallbackOnAuthError(t *testing.T) {
+	primary := &fakeModel{name: "primary", err: genai.APIError{Code: 401}}
+	backup := &fakeModel{name: "backup", responses: []string{"hello"}}
+	f, _ := NewFallbackModel(primary, backup)
+
+	if _, err := collect(t, f); err == nil {
+		t.Fatal("expected the auth error")
+	}
+	if backup.calls != 0 {
+		t.Errorf("backup called %d times, want 0", backup.calls)
+	}
+}
+
+func TestNoFallbackAfterPartialResponse(t *testing.T) {
+	primary := &fakeModel{name: "primary", responses: []string{"partial"}, err: genai.APIError{Code: 503}}
+	backup := &fakeModel{name: "backup", responses: []string{"hello"}}
+	f, _ := NewFallbackModel(primary, backup)
+
+	texts, err := collect(t, f)
+	if err == nil {
+		t.Fatal("expected the primary's error")
+	}
+	if len(texts) != 1 || texts[0] != "partial" || backup.calls != 0 {
+		t.Errorf("got %v with %d backup calls, want only the primary's partial response", texts, backup.calls)
+	}
+}
+
+func TestLastModelErrorIsReturned(t *testing.T) {
+	f, _ := NewFallbackModel(
+		&fakeModel{name: "primary", err: genai.APIError{Code: 429}},
+		&fakeModel{name: "backup", err: genai.APIError{Code: 500}},
+	)
+
+	_, err := collect(t, f)
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 500 {
+		t.Errorf("got %v, want the backup's 500 error", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{genai.APIError{Code: 429}, true},
+		{genai.APIError{Code: 503}, true},
+		{genai.APIError{Code: 400}, false},
+		{genai.APIError{Code: 403}, false},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{errors.New("empty response"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package modelutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// ===== Recording Model =====
+//
+// RecordingModel makes agent tests deterministic. In record mode it passes requests to a
+// real model and writes every request/response pair to a JSON file; in replay mode it
+// serves the recorded responses from that file without calling any model. Requests are
+// matched by a hash of their contents and config, so a test replays correctly as long as
+// the agent sends the same requests it sent while recording. A request with no recording
+// fails with ErrNoRecording instead of silently reaching the network.
+
+// RecordMode selects whether a RecordingModel records or replays
+type RecordMode string
+
+const (
+	RECORD_MODE_RECORD RecordMode = "record"
+	RECORD_MODE_REPLAY RecordMode = "replay"
+)
+
+// ErrNoRecording is returned in replay mode for a request that was never recorded
+var ErrNoRecording = errors.New("no recorded response for request")
+
+// Recording is the JSON file written in record mode and read in replay mode
+type Recording struct {
+	Model        string        `json:"model"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded model call
+type Interaction struct {
+	RequestHash string               `json:"request_hash"`
+	Stream      bool                 `json:"stream"`
+	Request     *model.LLMRequest    `json:"request"`
+	Responses   []*model.LLMResponse `json:"responses"`
+}
+
+// RecordingModel is a model.LLM that records or replays model interactions
+type RecordingModel struct {
+	inner model.LLM
+	mode  RecordMode
+	path  string
+
+	mu        sync.Mutex
+	recording Recording
+	served    map[string]int // replay: calls served per request hash
+}
+
+// NewRecordingModel creates a RecordingModel for the recording file at path.
+// In record mode inner is required and the file is overwritten; in replay mode inner may be
+// nil and the file must exist.
+func NewRecordingModel(inner model.LLM, mode RecordMode, path string) (*RecordingModel, error) {
+	m := &RecordingModel{inner: inner, mode: mode, path: path, served: map[string]int{}}
+
+	switch mode {
+	case RECORD_MODE_RECORD:
+		if inner == nil {
+			return nil, fmt.Errorf("record mode needs a model to record")
+		}
+		m.recording = Recording{Model: inner.Name(), Interactions: []Interaction{}}
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+	case RECORD_MODE_REPLAY:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		if err := json.Unmarshal(data, &m.recording); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown record mode %q (use %q or %q)", mode, RECORD_MODE_RECORD, RECORD_MODE_REPLAY)
+	}
+	return m, nil
+}
+
+// Name returns the wrapped model's name, or the recorded one in replay mode
+func (m *RecordingModel) Name() string {
+	if m.inner != nil {
+		return m.inner.Name()
+	}
+	return m.recording.Model
+}
+
+// GenerateContent records or replays the response to req
+func (m *RecordingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	// Hash before calling the model, which may add headers to req.Config
+	hash, err := RequestHash(req, stream)
+	if err != nil {
+		return func(yield func(*model.LLMResponse, error) bool) {
+			yield(nil, err)
+		}
+	}
+
+	if m.mode == RECORD_MODE_REPLAY {
+		return m.replay(hash)
+	}
+	return m.record(ctx, req, stream, hash)
+}
+
+func (m *RecordingModel) record(ctx context.Context, req *model.LLMRequest, stream bool, hash string) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		// Copy the request now; it is saved after the model answered
+		saved, err := cloneRequest(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		var responses []*model.LLMResponse
+		stopped := false
+		for resp, err := range m.inner.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				// Failed calls aren't recorded, so replay never reproduces a transient error
+				yield(nil, err)
+				return
+			}
+			responses = append(responses, resp)
+			if !yield(resp, nil) {
+				stopped = true
+				break
+			}
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.recording.Interactions = append(m.recording.Interactions, Interaction{
+			RequestHash: hash,
+			Stream:      stream,
+			Request:     saved,
+			Responses:   responses,
+		})
+		if err := m.save(); err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+func (m *RecordingModel) replay(hash string) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.mu.Lock()
+		var matches []Interaction
+		for _, interaction := range m.recording.Interactions {
+			if interaction.RequestHash == hash {
+				matches = append(matches, interaction)
+			}
+		}
+		// The same request can be recorded more than once; serve them in order, then repeat the last
+		n := m.served[hash]
+		m.served[hash]++
+		m.mu.Unlock()
+
+		if len(matches) == 0 {
+			yield(nil, fmt.Errorf("%w %s in %s (%d recorded); the agent's requests changed since recording, record again", ErrNoRecording, hash, m.path, len(m.recording.Interactions)))
+			return
+		}
+
+		interaction := matches[min(n, len(matches)-1)]
+		for _, resp := range interaction.Responses {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
+
+// save writes the recording; the caller holds m.mu or has exclusive access
+func (m *RecordingModel) save() error {
+	data, err := json.MarshalIndent(m.recording, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	if err := os.WriteFile(m.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// RequestHash identifies a request by its contents, config and streaming mode. HTTP options
+// and function call IDs are left out, since they can differ between otherwise equal requests.
+func RequestHash(req *model.LLMRequest, stream bool) (string, error) {
+	key := struct {
+		Contents []*genai.Content             `json:"contents"`
+		Config   *genai.GenerateContentConfig `json:"config,omitempty"`
+		Stream   bool                         `json:"stream"`
+	}{Stream: stream}
+
+	for _, content := range req.Contents {
+		key.Contents = append(key.Contents, withoutCallIDs(content))
+	}
+	if req.Config != nil {
+		config := *req.Config
+		config.HTTPOptions = nil
+		key.Config = &config
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// withoutCallIDs returns a copy of content with function call and response IDs cleared
+func withoutCallIDs(content *genai.Content) *genai.Content {
+	if content == nil {
+		return nil
+	}
+	c := *content
+	c.Parts = make([]*genai.Part, 0, len(content.Parts))
+	for _, part := range content.Parts {
+		if part == nil {
+			continue
+		}
+		p := *part
+		if p.FunctionCall != nil {
+			call := *p.FunctionCall
+			call.ID = ""
+			p.FunctionCall = &call
+		}
+		if p.FunctionResponse != nil {
+			resp := *p.FunctionResponse
+			resp.ID = ""
+			p.FunctionResponse = &resp
+		}
+		c.Parts = append(c.Parts, &p)
+	}
+	return &c
+}
+
+// cloneRequest deep-copies a request through JSON, dropping HTTP options
+func cloneRequest(req *model.LLMRequest) (*model.LLMRequest, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	var clone model.LLMRequest
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+	if clone.Config != nil {
+		clone.Config.HTTPOptions = nil
+	}
+	return &clone, nil
+}
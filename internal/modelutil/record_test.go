@@ -0,0 +1,88 @@
+package modelutil
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func textRequest(texts ...string) *model.LLMRequest {
+	req := &model.LLMRequest{}
+	for _, text := range texts {
+		req.Contents = append(req.Contents, genai.NewContentFromText(text, genai.RoleUser))
+	}
+	return req
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	recorder, err := NewRecordingModel(&fakeModel{name: "gemini-2.0-flash", responses: []string{"hello"}}, RECORD_MODE_RECORD, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range recorder.GenerateContent(context.Background(), textRequest("hi"), false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replayer, err := NewRecordingModel(nil, RECORD_MODE_REPLAY, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Name() != "gemini-2.0-flash" {
+		t.Errorf("Name() = %q, want the recorded model's name", replayer.Name())
+	}
+
+	var texts []string
+	for resp, err := range replayer.GenerateContent(context.Background(), textRequest("hi"), false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, resp.Content.Parts[0].Text)
+	}
+	if len(texts) != 1 || texts[0] != "hello" {
+		t.Errorf("replayed %v, want [hello]", texts)
+	}
+
+	for _, err := range replayer.GenerateContent(context.Background(), textRequest("bye"), false) {
+		if !errors.Is(err, ErrNoRecording) {
+			t.Errorf("got error %v, want ErrNoRecording", err)
+		}
+	}
+}
+
+func TestRequestHashIgnoresCallIDsAndHeaders(t *testing.T) {
+	request := func(id string, header string) *model.LLMRequest {
+		return &model.LLMRequest{
+			Contents: []*genai.Content{{
+				Role:  genai.RoleModel,
+				Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: id, Name: "add_reminder"}}},
+			}},
+			Config: &genai.GenerateContentConfig{
+				HTTPOptions: &genai.HTTPOptions{Headers: map[string][]string{"User-Agent": {header}}},
+			},
+		}
+	}
+
+	a, err := RequestHash(request("call-1", "a"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := RequestHash(request("call-2", "b"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("hashes differ: %s != %s", a, b)
+	}
+	if c, _ := RequestHash(request("call-1", "a"), true); c == a {
+		t.Error("streaming and non-streaming requests hash the same")
+	}
+}
@@ -0,0 +1,155 @@
+package modelutil
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+)
+
+// ===== Safety Settings =====
+//
+// Gemini filters prompts and responses for harassment, hate speech, sexually explicit and
+// dangerous content. SAFETY_LEVEL sets the block threshold for all four categories:
+//
+//	strict   blocks low, medium and high probability harm (BLOCK_LOW_AND_ABOVE)
+//	moderate blocks medium and high probability harm (BLOCK_MEDIUM_AND_ABOVE)
+//	relaxed  blocks only high probability harm (BLOCK_ONLY_HIGH)
+//	none     blocks nothing, but still reports safety ratings (BLOCK_NONE)
+//	off      turns the filter off (OFF)
+//
+// Unset or "default" keeps the API's defaults. Blocked responses are logged with their
+// finish reason.
+
+const (
+	SAFETY_LEVEL_ENV     = "SAFETY_LEVEL"
+	DEFAULT_SAFETY_LEVEL = "default"
+)
+
+// safetyThresholds maps SAFETY_LEVEL values to block thresholds
+var safetyThresholds = map[string]genai.HarmBlockThreshold{
+	"strict":   genai.HarmBlockThresholdBlockLowAndAbove,
+	"moderate": genai.HarmBlockThresholdBlockMediumAndAbove,
+	"relaxed":  genai.HarmBlockThresholdBlockOnlyHigh,
+	"none":     genai.HarmBlockThresholdBlockNone,
+	"off":      genai.HarmBlockThresholdOff,
+}
+
+// safetyCategories are the harm categories Gemini text models filter
+var safetyCategories = []genai.HarmCategory{
+	genai.HarmCategoryHarassment,
+	genai.HarmCategoryHateSpeech,
+	genai.HarmCategorySexuallyExplicit,
+	genai.HarmCategoryDangerousContent,
+}
+
+// SafetySettings returns the settings for a SAFETY_LEVEL value; "" and "default" return nil,
+// which leaves the API defaults in place
+func SafetySettings(level string) ([]*genai.SafetySetting, error) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if level == "" || level == DEFAULT_SAFETY_LEVEL {
+		return nil, nil
+	}
+	threshold, ok := safetyThresholds[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown %s %q (use %s)", SAFETY_LEVEL_ENV, level, strings.Join(safetyLevels(), ", "))
+	}
+
+	settings := make([]*genai.SafetySetting, 0, len(safetyCategories))
+	for _, category := range safetyCategories {
+		settings = append(settings, &genai.SafetySetting{Category: category, Threshold: threshold})
+	}
+	return settings, nil
+}
+
+// safetyLevels returns the accepted SAFETY_LEVEL values, sorted, with "default" first
+func safetyLevels() []string {
+	levels := make([]string, 0, len(safetyThresholds))
+	for level := range safetyThresholds {
+		levels = append(levels, level)
+	}
+	slices.Sort(levels)
+	return append([]string{DEFAULT_SAFETY_LEVEL}, levels...)
+}
+
+// BlockedBySafety reports whether a response stopped because of a safety or content filter
+func BlockedBySafety(reason genai.FinishReason) bool {
+	switch reason {
+	case genai.FinishReasonSafety, genai.FinishReasonProhibitedContent, genai.FinishReasonBlocklist,
+		genai.FinishReasonSPII, genai.FinishReasonImageSafety:
+		return true
+	}
+	return false
+}
+
+// SafetyModel is a model.LLM that adds safety settings to requests without their own and
+// logs responses blocked by safety filters
+type SafetyModel struct {
+	inner    model.LLM
+	settings []*genai.SafetySetting
+}
+
+// NewSafetyModel wraps inner so requests use settings (see SafetySettings). With no settings
+// only the blocked-response logging is added.
+func NewSafetyModel(inner model.LLM, settings []*genai.SafetySetting) *SafetyModel {
+	return &SafetyModel{inner: inner, settings: settings}
+}
+
+// Name returns the wrapped model's name
+func (m *SafetyModel) Name() string {
+	return m.inner.Name()
+}
+
+// GenerateContent sends req with the safety settings to the wrapped model. Settings already on
+// the request (e.g. from an agent's GenerateContentConfig) are kept.
+func (m *SafetyModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	if len(m.settings) > 0 && (req.Config == nil || len(req.Config.SafetySettings) == 0) {
+		// Copy rather than change the caller's request
+		withSettings := *req
+		config := genai.GenerateContentConfig{}
+		if req.Config != nil {
+			config = *req.Config
+		}
+		config.SafetySettings = m.settings
+		withSettings.Config = &config
+		req = &withSettings
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for resp, err := range m.inner.GenerateContent(ctx, req, stream) {
+			if err == nil && resp != nil && BlockedBySafety(resp.FinishReason) {
+				slog.Warn("model response blocked by safety filters",
+					"model", m.Name(),
+					"finish_reason", resp.FinishReason,
+					"error_message", resp.ErrorMessage)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// NewGeminiModel creates the Gemini model modelName with the GOOGLE_API_KEY and SAFETY_LEVEL
+// settings from getenv (usually os.Getenv)
+func NewGeminiModel(ctx context.Context, modelName string, getenv func(string) string) (model.LLM, error) {
+	settings, err := SafetySettings(getenv(SAFETY_LEVEL_ENV))
+	if err != nil {
+		return nil, err
+	}
+
+	mdl, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
+		APIKey: getenv("GOOGLE_API_KEY"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewSafetyModel(mdl, settings), nil
+}
@@ -0,0 +1,95 @@
+package modelutil
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestSafetySettings(t *testing.T) {
+	for _, level := range []string{"", "default", " Default "} {
+		settings, err := SafetySettings(level)
+		if err != nil || settings != nil {
+			t.Errorf("SafetySettings(%q) = %v, %v; want nil, nil", level, settings, err)
+		}
+	}
+
+	settings, err := SafetySettings("Relaxed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != len(safetyCategories) {
+		t.Fatalf("got %d settings, want one per category (%d)", len(settings), len(safetyCategories))
+	}
+	for i, setting := range settings {
+		if setting.Category != safetyCategories[i] || setting.Threshold != genai.HarmBlockThresholdBlockOnlyHigh {
+			t.Errorf("setting %d = %+v, want %s at BLOCK_ONLY_HIGH", i, setting, safetyCategories[i])
+		}
+	}
+
+	if _, err := SafetySettings("paranoid"); err == nil {
+		t.Error("SafetySettings(paranoid) succeeded, want an unknown level error")
+	}
+}
+
+// requestModel remembers the last request and answers with a fixed response
+type requestModel struct {
+	resp *model.LLMResponse
+	req  *model.LLMRequest
+}
+
+func (m *requestModel) Name() string { return "request-model" }
+
+func (m *requestModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	m.req = req
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(m.resp, nil)
+	}
+}
+
+func TestSafetyModelAddsSettings(t *testing.T) {
+	settings, err := SafetySettings("strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &requestModel{resp: &model.LLMResponse{FinishReason: genai.FinishReasonSafety}}
+	m := NewSafetyModel(inner, settings)
+
+	req := &model.LLMRequest{Config: &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.2)}}
+	for resp, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil || resp.FinishReason != genai.FinishReasonSafety {
+			t.Errorf("got %+v, %v; want the blocked response passed through", resp, err)
+		}
+	}
+
+	if got := inner.req.Config.SafetySettings; len(got) != len(settings) {
+		t.Errorf("sent %d safety settings, want %d", len(got), len(settings))
+	}
+	if inner.req.Config.Temperature == nil || *inner.req.Config.Temperature != 0.2 {
+		t.Error("the request's other config was not kept")
+	}
+	if req.Config.SafetySettings != nil {
+		t.Error("the caller's request was modified")
+	}
+
+	// Settings already on the request win
+	own := []*genai.SafetySetting{{Category: genai.HarmCategoryHarassment, Threshold: genai.HarmBlockThresholdBlockNone}}
+	for range m.GenerateContent(context.Background(), &model.LLMRequest{Config: &genai.GenerateContentConfig{SafetySettings: own}}, false) {
+	}
+	if got := inner.req.Config.SafetySettings; len(got) != 1 || got[0] != own[0] {
+		t.Errorf("request settings were replaced: %v", got)
+	}
+}
+
+func TestBlockedBySafety(t *testing.T) {
+	if !BlockedBySafety(genai.FinishReasonSafety) || !BlockedBySafety(genai.FinishReasonProhibitedContent) {
+		t.Error("safety finish reasons not reported as blocked")
+	}
+	if BlockedBySafety(genai.FinishReasonStop) || BlockedBySafety(genai.FinishReasonMaxTokens) {
+		t.Error("normal finish reasons reported as blocked")
+	}
+}
@@ -0,0 +1,105 @@
+package toolregistry
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// Names of the shared tools registered by this package
+const (
+	GET_CURRENT_TIME = "get_current_time"
+	READING_TIME     = "reading_time"
+)
+
+func init() {
+	Register(GET_CURRENT_TIME, newGetCurrentTimeTool)
+	Register(READING_TIME, newReadingTimeTool)
+}
+
+// ===== Time Tool =====
+
+type getCurrentTimeArgs struct{}
+
+type getCurrentTimeResults struct {
+	CurrentTime string `json:"current_time"`
+}
+
+// getCurrentTime returns the current time in YYYY-MM-DD HH:MM:SS format
+func getCurrentTime(ctx tool.Context, input getCurrentTimeArgs) (getCurrentTimeResults, error) {
+	slog.Info("tool called", "tool", GET_CURRENT_TIME, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+	return getCurrentTimeResults{
+		CurrentTime: time.Now().Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func newGetCurrentTimeTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        GET_CURRENT_TIME,
+			Description: "Get the current time in the format YYYY-MM-DD HH:MM:SS",
+		},
+		getCurrentTime)
+}
+
+// ===== Reading Time Tool =====
+
+// WORDS_PER_MINUTE is the average adult silent reading speed used for estimates
+const WORDS_PER_MINUTE = 200
+
+type readingTimeArgs struct {
+	Text string `json:"text"`
+}
+
+type readingTimeResults struct {
+	WordCount int     `json:"word_count"`
+	Seconds   int     `json:"seconds"` // rounded up, so any text takes at least a second
+	Minutes   float64 `json:"minutes"` // to one decimal place
+	Human     string  `json:"human"`   // e.g. "about 2 minutes"
+}
+
+// readingTime estimates how long text takes to read at WORDS_PER_MINUTE
+func readingTime(ctx tool.Context, input readingTimeArgs) (readingTimeResults, error) {
+	slog.Info("tool called", "tool", READING_TIME, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+	return estimateReadingTime(input.Text), nil
+}
+
+func estimateReadingTime(text string) readingTimeResults {
+	words := len(strings.Fields(text))
+	seconds := int(math.Ceil(float64(words) * 60 / WORDS_PER_MINUTE))
+	return readingTimeResults{
+		WordCount: words,
+		Seconds:   seconds,
+		Minutes:   math.Round(float64(seconds)/60*10) / 10,
+		Human:     humanReadingTime(seconds),
+	}
+}
+
+// humanReadingTime rounds to the nearest minute, e.g. "about 2 minutes"; under half a minute
+// reads as "less than a minute"
+func humanReadingTime(seconds int) string {
+	minutes := int(math.Round(float64(seconds) / 60))
+	switch {
+	case seconds == 0:
+		return "no reading time"
+	case minutes == 0:
+		return "less than a minute"
+	case minutes == 1:
+		return "about 1 minute"
+	}
+	return fmt.Sprintf("about %d minutes", minutes)
+}
+
+func newReadingTimeTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        READING_TIME,
+			Description: "Estimate how long text takes to read at 200 words per minute. Returns the word count, seconds, minutes and a human-readable estimate such as \"about 2 minutes\"",
+		},
+		readingTime)
+}
@@ -0,0 +1,33 @@
+package toolregistry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateReadingTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		words       int
+		wantSeconds int
+		wantMinutes float64
+		wantHuman   string
+	}{
+		{"empty", 0, 0, 0, "no reading time"},
+		{"one word", 1, 1, 0, "less than a minute"},
+		{"half a minute rounds up", 100, 30, 0.5, "about 1 minute"},
+		{"one minute", 200, 60, 1, "about 1 minute"},
+		{"two and a bit minutes", 450, 135, 2.3, "about 2 minutes"},
+		{"ten minutes", 2000, 600, 10, "about 10 minutes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := strings.TrimSpace(strings.Repeat("word\n ", tt.words))
+			got := estimateReadingTime(text)
+			if got.WordCount != tt.words || got.Seconds != tt.wantSeconds || got.Minutes != tt.wantMinutes || got.Human != tt.wantHuman {
+				t.Errorf("estimateReadingTime(%d words) = %+v, want %d words, %ds, %.1f min, %q",
+					tt.words, got, tt.words, tt.wantSeconds, tt.wantMinutes, tt.wantHuman)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package toolregistry lets examples share tools by name instead of redefining them.
+//
+// Shared tools register a constructor once (see builtin.go), and any agent fetches the
+// tool with Get. The tool is built on first use and the same instance is returned
+// afterwards, so identical tools aren't re-created for every agent.
+package toolregistry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/tool"
+)
+
+// Constructor builds a tool, e.g. a NewXxxTool function
+type Constructor func() (tool.Tool, error)
+
+type entry struct {
+	constructor Constructor
+	once        sync.Once
+	tool        tool.Tool
+	err         error
+}
+
+var (
+	mu      sync.RWMutex
+	entries = map[string]*entry{}
+)
+
+// Register makes a tool available under name. Like database/sql.Register it is meant to be
+// called from init, and it panics if the name is empty, the constructor is nil, or the name
+// is already registered.
+func Register(name string, constructor Constructor) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if name == "" {
+		panic("toolregistry: Register called with an empty name")
+	}
+	if constructor == nil {
+		panic(fmt.Sprintf("toolregistry: Register called with a nil constructor for %q", name))
+	}
+	if _, exists := entries[name]; exists {
+		panic(fmt.Sprintf("toolregistry: Register called twice for %q", name))
+	}
+	entries[name] = &entry{constructor: constructor}
+}
+
+// Get returns the tool registered under name, building it on first use.
+// It returns an error if nothing is registered under name or the constructor fails.
+func Get(name string) (tool.Tool, error) {
+	mu.RLock()
+	e, ok := entries[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("tool %q is not registered (registered: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	e.once.Do(func() {
+		e.tool, e.err = e.constructor()
+	})
+	if e.err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", name, e.err)
+	}
+	return e.tool, nil
+}
+
+// Names returns the registered tool names, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,50 @@
+package toolregistry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/tool"
+)
+
+func TestGetReturnsSameInstance(t *testing.T) {
+	first, err := Get(GET_CURRENT_TIME)
+	if err != nil {
+		t.Fatalf("Get(%q) failed: %v", GET_CURRENT_TIME, err)
+	}
+	second, err := Get(GET_CURRENT_TIME)
+	if err != nil {
+		t.Fatalf("Get(%q) failed: %v", GET_CURRENT_TIME, err)
+	}
+	if first != second {
+		t.Error("Get returned a new tool on the second call, want the cached instance")
+	}
+	if first.Name() != GET_CURRENT_TIME {
+		t.Errorf("tool name = %q, want %q", first.Name(), GET_CURRENT_TIME)
+	}
+}
+
+func TestGetMissingName(t *testing.T) {
+	_, err := Get("no_such_tool")
+	if err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("Get(missing) error = %v, want a not registered error", err)
+	}
+}
+
+func TestGetConstructorError(t *testing.T) {
+	Register("test_broken_tool", func() (tool.Tool, error) { return nil, errors.New("boom") })
+
+	if _, err := Get("test_broken_tool"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Get(broken) error = %v, want the constructor error", err)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register with a duplicate name did not panic")
+		}
+	}()
+	Register(GET_CURRENT_TIME, newGetCurrentTimeTool)
+}
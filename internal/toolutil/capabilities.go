@@ -0,0 +1,117 @@
+package toolutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const WHAT_CAN_YOU_DO = "what_can_you_do"
+
+// ===== Capabilities Tool Structures =====
+
+// Capability is one tool or sub-agent the agent can use
+type Capability struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type whatCanYouDoResults struct {
+	Status    string       `json:"status"`
+	Tools     []Capability `json:"tools"`
+	SubAgents []Capability `json:"sub_agents"`
+	Summary   string       `json:"summary"` // ready-to-relay bullet list of everything above
+}
+
+// ===== Tool Creation =====
+
+// NewCapabilitiesTool creates a what_can_you_do tool that lists the given tools and sub-agents
+// with their descriptions, so the agent can answer "what can you help with?" from its actual
+// configuration instead of guessing. Pass the same slices given to llmagent.Config; the
+// capabilities tool itself doesn't need to be in tools.
+func NewCapabilitiesTool(tools []tool.Tool, subAgents []agent.Agent) (tool.Tool, error) {
+	result := whatCanYouDoResults{
+		Status:    "success",
+		Tools:     describeTools(tools, WHAT_CAN_YOU_DO),
+		SubAgents: describeAgents(subAgents),
+	}
+	result.Summary = formatCapabilities(result.Tools, result.SubAgents)
+
+	return newListingTool(WHAT_CAN_YOU_DO,
+		"Lists the tools and specialist agents available to you, with what each one does. "+
+			"Use it when the user asks what you can do or help with",
+		result)
+}
+
+// ===== Shared Listing Helpers =====
+//
+// what_can_you_do and list_delegates both describe what an agent was built with. They share
+// these helpers, so the two tools can't drift apart in how they name and describe things.
+
+// describeTools returns the name and description of each tool, leaving out the one named skip
+// (the listing tool itself)
+func describeTools(tools []tool.Tool, skip string) []Capability {
+	described := make([]Capability, 0, len(tools))
+	for _, t := range tools {
+		if t.Name() == skip {
+			continue
+		}
+		described = append(described, Capability{Name: t.Name(), Description: t.Description()})
+	}
+	return described
+}
+
+// describeAgents returns the name and description of each agent
+func describeAgents(agents []agent.Agent) []Capability {
+	described := make([]Capability, 0, len(agents))
+	for _, a := range agents {
+		described = append(described, Capability{Name: a.Name(), Description: a.Description()})
+	}
+	return described
+}
+
+// newListingTool creates a tool named name that returns result on every call. Tools and
+// sub-agents are fixed once an agent is built, so the listing is computed once, up front.
+func newListingTool[T any](name, description string, result T) (tool.Tool, error) {
+	listing := func(ctx tool.Context, input struct{}) (T, error) {
+		slog.Info("tool called", "tool", name, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+		return result, nil
+	}
+
+	listingTool, err := functiontool.New(functiontool.Config{Name: name, Description: description}, listing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", name, err)
+	}
+	return listingTool, nil
+}
+
+// formatCapabilities renders the capabilities as a Markdown bullet list
+func formatCapabilities(tools, subAgents []Capability) string {
+	var sb strings.Builder
+	writeSection := func(title string, items []Capability) {
+		if len(items) == 0 {
+			return
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(title + ":\n")
+		for _, item := range items {
+			description := strings.Join(strings.Fields(item.Description), " ")
+			if description == "" {
+				description = "(no description)"
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", item.Name, description)
+		}
+	}
+	writeSection("Tools", tools)
+	writeSection("Specialist agents", subAgents)
+	if sb.Len() == 0 {
+		return "No tools or specialist agents are configured."
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,25 @@
+package toolutil
+
+import "testing"
+
+func TestFormatCapabilities(t *testing.T) {
+	got := formatCapabilities(
+		[]Capability{{Name: "calculate", Description: "Evaluates an  arithmetic\nexpression"}, {Name: "bare"}},
+		[]Capability{{Name: "funny_nerd", Description: "Tells nerdy jokes"}},
+	)
+	want := "Tools:\n" +
+		"- calculate: Evaluates an arithmetic expression\n" +
+		"- bare: (no description)\n" +
+		"\n" +
+		"Specialist agents:\n" +
+		"- funny_nerd: Tells nerdy jokes"
+	if got != want {
+		t.Errorf("formatCapabilities() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFormatCapabilitiesEmpty(t *testing.T) {
+	if got := formatCapabilities(nil, nil); got != "No tools or specialist agents are configured." {
+		t.Errorf("formatCapabilities(nil, nil) = %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package toolutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+const (
+	CONFIRMATIONS_KEY        = "pending_confirmations"
+	DEFAULT_CONFIRMATION_TTL = 2 * time.Minute
+)
+
+var (
+	// ErrConfirmationInvalid is returned by Confirm for a token that was never issued for the
+	// action or was already used
+	ErrConfirmationInvalid = errors.New("confirmation token is invalid or was already used")
+	// ErrConfirmationExpired is returned by Confirm for a token older than the TTL
+	ErrConfirmationExpired = errors.New("confirmation token expired")
+	// ErrConfirmationChanged is returned by Confirm when the data the token was issued for changed
+	ErrConfirmationChanged = errors.New("the affected data changed since the confirmation was requested")
+	// ErrConfirmationSameInvocation is returned by Confirm for a token issued in the same
+	// invocation, i.e. before the user could have answered the preview
+	ErrConfirmationSameInvocation = errors.New("the confirmation must come in a later turn, after the user agreed")
+)
+
+// ===== Confirmations =====
+//
+// A destructive tool (clear all reminders, bulk deletes) shouldn't act on a single call that may
+// come from a misread instruction. With a Confirmer it runs in two steps:
+//  1. Called without a token, the tool only previews what it would affect and returns a token
+//     from Request
+//  2. Called again with that token, after the user agreed, it checks it with Confirm and acts
+//
+// Tokens are kept in session state under CONFIRMATIONS_KEY, so the second call can come in a later
+// turn, and expire after the TTL. A token is bound to an action and a scope, a string describing
+// what the preview showed (e.g. the ids of the reminders to delete). When the scope changed by the
+// second call, e.g. because a reminder was added, Confirm refuses and a new preview is needed.
+// The token also records the invocation that asked for it: Confirm refuses it in that same
+// invocation, so the model can't preview and confirm in one turn without the user answering.
+// Each token works once.
+
+// Confirmation is a pending confirmation returned by Request
+type Confirmation struct {
+	Token     string
+	Action    string
+	ExpiresAt time.Time
+}
+
+// Confirmer issues and checks confirmation tokens
+type Confirmer struct {
+	TTL time.Duration
+	Now func() time.Time // time.Now when nil
+}
+
+// NewConfirmer creates a Confirmer whose tokens expire after ttl
+func NewConfirmer(ttl time.Duration) *Confirmer {
+	return &Confirmer{TTL: ttl}
+}
+
+// Request issues a token for action on scope and stores it in state. invocationID is the
+// invocation asking, usually tool.Context.InvocationID().
+func (c *Confirmer) Request(state session.State, invocationID, action, scope string) (Confirmation, error) {
+	token, err := newConfirmationToken()
+	if err != nil {
+		return Confirmation{}, err
+	}
+	now := c.now()
+	confirmation := Confirmation{Token: token, Action: action, ExpiresAt: now.Add(c.TTL)}
+
+	pending := c.pending(state, now)
+	pending[token] = map[string]any{
+		"action":     action,
+		"scope":      scope,
+		"invocation": invocationID,
+		"expires_at": confirmation.ExpiresAt.Format(time.RFC3339Nano),
+	}
+	if err := state.Set(CONFIRMATIONS_KEY, pending); err != nil {
+		return Confirmation{}, fmt.Errorf("failed to store confirmation: %w", err)
+	}
+	return confirmation, nil
+}
+
+// Confirm checks that token was issued for action on scope in an earlier invocation than
+// invocationID and hasn't expired, and uses it up. A token that doesn't match is used up too, so
+// a failed confirmation always needs a new preview.
+func (c *Confirmer) Confirm(state session.State, invocationID, action, scope, token string) error {
+	now := c.now()
+	pending := c.pending(state, now)
+	entry, ok := pending[token]
+	if !ok {
+		// Expired tokens were just pruned; tell them apart from unknown ones
+		if raw, err := state.Get(CONFIRMATIONS_KEY); err == nil {
+			if all, ok := raw.(map[string]any); ok && all[token] != nil {
+				c.save(state, pending)
+				return ErrConfirmationExpired
+			}
+		}
+		return ErrConfirmationInvalid
+	}
+
+	delete(pending, token)
+	if err := c.save(state, pending); err != nil {
+		return err
+	}
+
+	fields, _ := entry.(map[string]any)
+	if fields["action"] != action {
+		return ErrConfirmationInvalid
+	}
+	if fields["invocation"] == invocationID {
+		return ErrConfirmationSameInvocation
+	}
+	if fields["scope"] != scope {
+		return ErrConfirmationChanged
+	}
+	return nil
+}
+
+// pending returns the stored confirmations that haven't expired at now; malformed entries are
+// dropped
+func (c *Confirmer) pending(state session.ReadonlyState, now time.Time) map[string]any {
+	pending := map[string]any{}
+	raw, err := state.Get(CONFIRMATIONS_KEY)
+	if err != nil {
+		return pending
+	}
+	all, _ := raw.(map[string]any)
+	for token, entry := range all {
+		fields, _ := entry.(map[string]any)
+		expiresAt, _ := fields["expires_at"].(string)
+		expires, err := time.Parse(time.RFC3339Nano, expiresAt)
+		if err != nil || !now.Before(expires) {
+			continue
+		}
+		pending[token] = fields
+	}
+	return pending
+}
+
+func (c *Confirmer) save(state session.State, pending map[string]any) error {
+	if err := state.Set(CONFIRMATIONS_KEY, pending); err != nil {
+		return fmt.Errorf("failed to update confirmations: %w", err)
+	}
+	return nil
+}
+
+func (c *Confirmer) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// newConfirmationToken returns a random token that can't be guessed from earlier ones
+func newConfirmationToken() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create confirmation token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
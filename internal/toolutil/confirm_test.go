@@ -0,0 +1,88 @@
+package toolutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestConfirmerTwoSteps(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	c := &Confirmer{TTL: time.Minute, Now: func() time.Time { return now }}
+	state := agenttest.NewState(nil, false)
+
+	confirmation, err := c.Request(state, "inv-1", "clear_reminders", "1,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmation.Token == "" || !confirmation.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Request() = %+v, want a token expiring in a minute", confirmation)
+	}
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", confirmation.Token); err != nil {
+		t.Fatalf("Confirm() = %v, want nil", err)
+	}
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", confirmation.Token); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("second Confirm() = %v, want ErrConfirmationInvalid", err)
+	}
+}
+
+func TestConfirmerRefuses(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	c := &Confirmer{TTL: time.Minute, Now: func() time.Time { return now }}
+
+	request := func(state *agenttest.State) string {
+		t.Helper()
+		confirmation, err := c.Request(state, "inv-1", "clear_reminders", "1,2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return confirmation.Token
+	}
+
+	state := agenttest.NewState(nil, false)
+	token := request(state)
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", "not-a-token"); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("unknown token: Confirm() = %v, want ErrConfirmationInvalid", err)
+	}
+	// An unknown token doesn't use up the real one
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", token); err != nil {
+		t.Errorf("Confirm() after an unknown token = %v, want nil", err)
+	}
+
+	state = agenttest.NewState(nil, false)
+	token = request(state)
+	if err := c.Confirm(state, "inv-2", "delete_notes", "1,2", token); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("other action: Confirm() = %v, want ErrConfirmationInvalid", err)
+	}
+
+	state = agenttest.NewState(nil, false)
+	token = request(state)
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2,3", token); !errors.Is(err, ErrConfirmationChanged) {
+		t.Errorf("changed scope: Confirm() = %v, want ErrConfirmationChanged", err)
+	}
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", token); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("Confirm() after a changed scope = %v, want the token used up", err)
+	}
+
+	// The model can't confirm in the turn that asked, before the user could answer
+	state = agenttest.NewState(nil, false)
+	token = request(state)
+	if err := c.Confirm(state, "inv-1", "clear_reminders", "1,2", token); !errors.Is(err, ErrConfirmationSameInvocation) {
+		t.Errorf("same invocation: Confirm() = %v, want ErrConfirmationSameInvocation", err)
+	}
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", token); !errors.Is(err, ErrConfirmationInvalid) {
+		t.Errorf("Confirm() after the same invocation = %v, want the token used up", err)
+	}
+
+	state = agenttest.NewState(nil, false)
+	token = request(state)
+	now = now.Add(time.Minute)
+	if err := c.Confirm(state, "inv-2", "clear_reminders", "1,2", token); !errors.Is(err, ErrConfirmationExpired) {
+		t.Errorf("expired: Confirm() = %v, want ErrConfirmationExpired", err)
+	}
+	if pending, _ := state.Value(CONFIRMATIONS_KEY).(map[string]any); len(pending) != 0 {
+		t.Errorf("pending confirmations = %v, want the expired one pruned", pending)
+	}
+}
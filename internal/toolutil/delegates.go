@@ -0,0 +1,71 @@
+package toolutil
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+)
+
+const (
+	LIST_DELEGATES = "list_delegates"
+
+	// Delegate kinds
+	DELEGATE_SUB_AGENT  = "sub_agent"  // reached by transferring the conversation to it
+	DELEGATE_AGENT_TOOL = "agent_tool" // an agent wrapped with agenttool, called like a tool
+)
+
+// ===== Delegates Tool Structures =====
+
+// Delegate is one agent a coordinator can hand work to
+type Delegate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`       // DELEGATE_SUB_AGENT or DELEGATE_AGENT_TOOL
+	HowToUse    string `json:"how_to_use"` // how the coordinator reaches it
+}
+
+type listDelegatesResults struct {
+	Status    string     `json:"status"`
+	Delegates []Delegate `json:"delegates"`
+	Message   string     `json:"message"`
+}
+
+// ===== Tool Creation =====
+
+// NewListDelegatesTool creates a list_delegates tool that returns the given sub-agents and
+// agent tools with their descriptions, so a coordinator routes requests based on the agents
+// it was actually built with rather than only on the list in its instruction. Pass the same
+// sub-agents given to llmagent.Config and the tools created with agenttool.New.
+func NewListDelegatesTool(subAgents []agent.Agent, agentTools []tool.Tool) (tool.Tool, error) {
+	return newListingTool(LIST_DELEGATES,
+		"Lists the specialist agents you can delegate to, with what each one handles and whether to "+
+			"transfer to it or call it as a tool. Use it to decide where to route a request",
+		listDelegates(subAgents, agentTools))
+}
+
+func listDelegates(subAgents []agent.Agent, agentTools []tool.Tool) listDelegatesResults {
+	delegates := make([]Delegate, 0, len(subAgents)+len(agentTools))
+	for _, c := range describeAgents(subAgents) {
+		delegates = append(delegates, Delegate{
+			Name:        c.Name,
+			Description: c.Description,
+			Kind:        DELEGATE_SUB_AGENT,
+			HowToUse:    fmt.Sprintf("transfer the conversation to %s; it answers the user directly", c.Name),
+		})
+	}
+	for _, c := range describeTools(agentTools, LIST_DELEGATES) {
+		delegates = append(delegates, Delegate{
+			Name:        c.Name,
+			Description: c.Description,
+			Kind:        DELEGATE_AGENT_TOOL,
+			HowToUse:    fmt.Sprintf("call the %s tool with the request; it returns its answer to you to relay", c.Name),
+		})
+	}
+
+	message := fmt.Sprintf("%d sub-agent(s) and %d agent tool(s) available", len(subAgents), len(agentTools))
+	if len(delegates) == 0 {
+		message = "No specialist agents are configured; answer directly"
+	}
+	return listDelegatesResults{Status: "success", Delegates: delegates, Message: message}
+}
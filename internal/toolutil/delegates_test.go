@@ -0,0 +1,41 @@
+package toolutil
+
+import (
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
+)
+
+func TestListDelegates(t *testing.T) {
+	funnyNerd, err := agent.New(agent.Config{Name: "funny_nerd", Description: "Tells nerdy jokes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stockAnalyst, err := agent.New(agent.Config{Name: "stock_analyst", Description: "Looks up stock prices"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := listDelegates([]agent.Agent{funnyNerd}, []tool.Tool{agenttool.New(stockAnalyst, nil)})
+
+	want := []Delegate{
+		{Name: "funny_nerd", Description: "Tells nerdy jokes", Kind: DELEGATE_SUB_AGENT},
+		{Name: "stock_analyst", Description: "Looks up stock prices", Kind: DELEGATE_AGENT_TOOL},
+	}
+	if len(result.Delegates) != len(want) {
+		t.Fatalf("got %d delegates, want %d: %+v", len(result.Delegates), len(want), result.Delegates)
+	}
+	for i, d := range result.Delegates {
+		if d.Name != want[i].Name || d.Description != want[i].Description || d.Kind != want[i].Kind || d.HowToUse == "" {
+			t.Errorf("delegate %d = %+v, want %+v with usage", i, d, want[i])
+		}
+	}
+}
+
+func TestListDelegatesEmpty(t *testing.T) {
+	if result := listDelegates(nil, nil); len(result.Delegates) != 0 || result.Status != "success" {
+		t.Errorf("listDelegates(nil, nil) = %+v", result)
+	}
+}
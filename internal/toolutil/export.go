@@ -0,0 +1,184 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const EXPORT_CONVERSATION = "export_conversation"
+
+// ===== Export Conversation Tool Structures =====
+
+type exportConversationArgs struct {
+	Filename string `json:"filename,omitempty"` // optional file name; defaults to conversation-<session id>.md
+}
+
+type exportConversationResults struct {
+	Status       string `json:"status"`
+	Path         string `json:"path,omitempty"`
+	Events       int    `json:"events,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// ===== Tool Creation =====
+
+// NewExportConversationTool creates an export_conversation tool that writes the current
+// session's transcript as Markdown into dir. Tools can't reach the session service through
+// their context, so the service is passed in here (use the same one given to the launcher);
+// the context supplies the app, user and session IDs of the current session.
+//
+// Only a file name is taken from the model; any directories in it are dropped, so the
+// transcript always lands in dir.
+func NewExportConversationTool(sessions session.Service, dir string) (tool.Tool, error) {
+	exportConversation := func(ctx tool.Context, input exportConversationArgs) (exportConversationResults, error) {
+		slog.Info("tool called", "tool", EXPORT_CONVERSATION, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+
+		filename, err := exportFilename(input.Filename, ctx.SessionID())
+		if err != nil {
+			return exportConversationResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+
+		resp, err := sessions.Get(ctx, &session.GetRequest{
+			AppName:   ctx.AppName(),
+			UserID:    ctx.UserID(),
+			SessionID: ctx.SessionID(),
+		})
+		if err != nil {
+			return exportConversationResults{}, fmt.Errorf("failed to get session %s: %w", ctx.SessionID(), err)
+		}
+		sess := resp.Session
+
+		events := make([]*session.Event, 0, sess.Events().Len())
+		for event := range sess.Events().All() {
+			events = append(events, event)
+		}
+		title := fmt.Sprintf("Conversation %s (app: %s, user: %s)", sess.ID(), sess.AppName(), sess.UserID())
+		transcript := renderTranscript(title, events, time.Now())
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return exportConversationResults{}, fmt.Errorf("failed to create export directory %s: %w", dir, err)
+		}
+		path := filepath.Join(dir, filename)
+		if err := os.WriteFile(path, []byte(transcript), 0o644); err != nil {
+			return exportConversationResults{Status: "error", ErrorMessage: fmt.Sprintf("could not write %s: %v", path, err)}, nil
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		slog.Info("conversation exported", "session_id", sess.ID(), "path", path, "events", len(events))
+		return exportConversationResults{Status: "success", Path: path, Events: len(events)}, nil
+	}
+
+	exportTool, err := functiontool.New(
+		functiontool.Config{
+			Name: EXPORT_CONVERSATION,
+			Description: "Saves the transcript of this conversation (user messages, agent replies and tool calls) " +
+				"to a Markdown file and returns its path. filename is optional",
+		},
+		exportConversation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", EXPORT_CONVERSATION, err)
+	}
+
+	return exportTool, nil
+}
+
+// exportFilename turns the requested name into a bare .md file name
+func exportFilename(requested, sessionID string) (string, error) {
+	return bareFilename(requested, "conversation-"+sessionID, ".md")
+}
+
+// bareFilename turns the requested name into a file name without directories, ending in ext.
+// An empty name becomes defaultName; a defaultName of "" makes the name required.
+func bareFilename(requested, defaultName, ext string) (string, error) {
+	name := filepath.Base(strings.TrimSpace(requested))
+	if name == "." || name == string(filepath.Separator) || strings.TrimSpace(requested) == "" {
+		name = defaultName
+	}
+	if name == "" {
+		return "", errors.New("a file name is required")
+	}
+	if name == ".." {
+		return "", fmt.Errorf("invalid file name %q", requested)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+	return name, nil
+}
+
+// ===== Markdown Rendering =====
+
+// renderTranscript renders the events as Markdown: one section per event with its author,
+// text, tool calls and tool results. Partial (streamed) events and model thoughts are skipped.
+func renderTranscript(title string, events []*session.Event, exportedAt time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "_Exported %s_\n", exportedAt.Format("2006-01-02 15:04:05"))
+
+	for _, event := range events {
+		if event.Partial {
+			continue
+		}
+
+		var body strings.Builder
+		if event.Content != nil {
+			for _, part := range event.Content.Parts {
+				writeTranscriptPart(&body, part)
+			}
+		}
+		if event.ErrorMessage != "" {
+			fmt.Fprintf(&body, "> Error: %s\n\n", event.ErrorMessage)
+		}
+		if event.Actions.TransferToAgent != "" {
+			fmt.Fprintf(&body, "_Transferred to %s_\n\n", event.Actions.TransferToAgent)
+		}
+		if body.Len() == 0 {
+			continue
+		}
+
+		author := event.Author
+		if author == "user" {
+			author = "User"
+		}
+		fmt.Fprintf(&sb, "\n## %s (%s)\n\n", author, event.Timestamp.Format("2006-01-02 15:04:05"))
+		sb.WriteString(strings.TrimRight(body.String(), "\n"))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// writeTranscriptPart renders one content part
+func writeTranscriptPart(sb *strings.Builder, part *genai.Part) {
+	switch {
+	case part.Thought:
+		return
+	case part.FunctionCall != nil:
+		fmt.Fprintf(sb, "**Tool call:** `%s`\n\n```json\n%s\n```\n\n", part.FunctionCall.Name, transcriptJSON(part.FunctionCall.Args))
+	case part.FunctionResponse != nil:
+		fmt.Fprintf(sb, "**Tool result:** `%s`\n\n```json\n%s\n```\n\n", part.FunctionResponse.Name, transcriptJSON(part.FunctionResponse.Response))
+	case strings.TrimSpace(part.Text) != "":
+		sb.WriteString(strings.TrimSpace(part.Text) + "\n\n")
+	}
+}
+
+func transcriptJSON(value any) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
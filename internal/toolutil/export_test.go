@@ -0,0 +1,79 @@
+package toolutil
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func transcriptEvent(author string, ts time.Time, parts ...*genai.Part) *session.Event {
+	event := session.NewEvent("inv-1")
+	event.Author = author
+	event.Timestamp = ts
+	event.LLMResponse = model.LLMResponse{Content: &genai.Content{Parts: parts}}
+	return event
+}
+
+func TestRenderTranscript(t *testing.T) {
+	ts := time.Date(2024, 12, 3, 15, 4, 5, 0, time.UTC)
+
+	transfer := transcriptEvent("customer_service", ts, genai.NewPartFromFunctionCall("transfer_to_agent", map[string]any{"agent_name": "sales_agent"}))
+	transfer.Actions.TransferToAgent = "sales_agent"
+
+	partial := transcriptEvent("sales_agent", ts, genai.NewPartFromText("I can"))
+	partial.Partial = true
+
+	thought := genai.NewPartFromText("The user wants to buy")
+	thought.Thought = true
+
+	events := []*session.Event{
+		transcriptEvent("user", ts, genai.NewPartFromText("I want to buy the course")),
+		transfer,
+		partial,
+		transcriptEvent("sales_agent", ts, thought, genai.NewPartFromFunctionResponse("purchase_course", map[string]any{"status": "success"})),
+		transcriptEvent("sales_agent", ts, genai.NewPartFromText("  Done! ")),
+	}
+
+	got := renderTranscript("Conversation s1", events, ts)
+	want := "# Conversation s1\n\n" +
+		"_Exported 2024-12-03 15:04:05_\n" +
+		"\n## User (2024-12-03 15:04:05)\n\n" +
+		"I want to buy the course\n" +
+		"\n## customer_service (2024-12-03 15:04:05)\n\n" +
+		"**Tool call:** `transfer_to_agent`\n\n```json\n{\n  \"agent_name\": \"sales_agent\"\n}\n```\n\n" +
+		"_Transferred to sales_agent_\n" +
+		"\n## sales_agent (2024-12-03 15:04:05)\n\n" +
+		"**Tool result:** `purchase_course`\n\n```json\n{\n  \"status\": \"success\"\n}\n```\n" +
+		"\n## sales_agent (2024-12-03 15:04:05)\n\n" +
+		"Done!\n"
+	if got != want {
+		t.Errorf("renderTranscript() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"", "conversation-s1.md"},
+		{"notes", "notes.md"},
+		{"chat.MD", "chat.MD"},
+		{"../../etc/passwd", "passwd.md"},
+		{"/tmp/out.md", "out.md"},
+	}
+	for _, tt := range tests {
+		got, err := exportFilename(tt.requested, "s1")
+		if err != nil || got != tt.want {
+			t.Errorf("exportFilename(%q) = %q, %v, want %q", tt.requested, got, err, tt.want)
+		}
+	}
+
+	if _, err := exportFilename("..", "s1"); err == nil {
+		t.Error(`exportFilename("..") succeeded, want an error`)
+	}
+}
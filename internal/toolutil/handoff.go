@@ -0,0 +1,142 @@
+package toolutil
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	HANDOFF_TO = "handoff_to"
+
+	LAST_HANDOFF_KEY = "last_handoff" // the most recent Handoff
+	HANDOFF_LOG_KEY  = "handoff_log"  // recent Handoffs, oldest first
+	MAX_HANDOFF_LOG  = 20             // oldest handoffs are dropped beyond this
+)
+
+// ===== Handoff Tool =====
+//
+// A coordinator normally delegates by calling ADK's transfer_to_agent, so the only trace of a
+// routing decision is the function call itself. handoff_to makes the handoff explicit: it checks
+// the target is one of the coordinator's sub-agents, records the target and the reason in
+// session state and the log, and then transfers the conversation the same way
+// transfer_to_agent does (by setting the transfer action). An unknown target or a missing
+// reason is refused and nothing is transferred, so the model can correct itself.
+
+// Handoff is one recorded handoff
+type Handoff struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reason    string `json:"reason"`
+	Timestamp string `json:"timestamp"` // RFC 3339
+}
+
+type handoffArgs struct {
+	Agent  string `json:"agent"`
+	Reason string `json:"reason"`
+}
+
+type handoffResults struct {
+	Status       string   `json:"status"` // "handed_off" or "error"
+	Agent        string   `json:"agent,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
+	ValidAgents  []string `json:"valid_agents,omitempty"`
+	Message      string   `json:"message,omitempty"`
+	ErrorMessage string   `json:"error_message,omitempty"`
+}
+
+// NewHandoffTool creates a handoff_to tool that transfers to one of subAgents and records why.
+// Pass the same sub-agents given to llmagent.Config.
+func NewHandoffTool(subAgents []agent.Agent) (tool.Tool, error) {
+	if len(subAgents) == 0 {
+		return nil, fmt.Errorf("failed to create %s tool: no sub-agents to hand off to", HANDOFF_TO)
+	}
+
+	names := make([]string, 0, len(subAgents))
+	for _, a := range subAgents {
+		names = append(names, a.Name())
+	}
+	sort.Strings(names)
+
+	handoffTo := func(ctx tool.Context, input handoffArgs) (handoffResults, error) {
+		results, h := handoff(ctx.State(), names, ctx.AgentName(), input, time.Now())
+		if results.Status != "handed_off" {
+			slog.Warn("handoff refused", "from", ctx.AgentName(), "to", input.Agent, "session_id", ctx.SessionID(), "error", results.ErrorMessage)
+			return results, nil
+		}
+
+		slog.Info("handoff", "from", h.From, "to", h.To, "reason", h.Reason, "session_id", ctx.SessionID())
+		ctx.Actions().TransferToAgent = h.To
+		return results, nil
+	}
+
+	handoffTool, err := functiontool.New(
+		functiontool.Config{
+			Name: HANDOFF_TO,
+			Description: "Hands the conversation off to a specialist agent and records why. Use it instead of transfer_to_agent. " +
+				"agent must be one of: " + strings.Join(names, ", ") + "; reason is one short sentence on why that agent should handle the request",
+		},
+		handoffTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", HANDOFF_TO, err)
+	}
+	return handoffTool, nil
+}
+
+// handoff validates input against names and records the handoff in state
+func handoff(state session.State, names []string, from string, input handoffArgs, now time.Time) (handoffResults, Handoff) {
+	target := strings.TrimSpace(input.Agent)
+	reason := strings.TrimSpace(input.Reason)
+
+	if !slices.Contains(names, target) {
+		return handoffResults{
+			Status:       "error",
+			Agent:        target,
+			ValidAgents:  names,
+			ErrorMessage: fmt.Sprintf("%q is not an agent you can hand off to; use one of: %s", target, strings.Join(names, ", ")),
+		}, Handoff{}
+	}
+	if reason == "" {
+		return handoffResults{
+			Status:       "error",
+			Agent:        target,
+			ErrorMessage: "a reason is required; say in one sentence why " + target + " should handle the request",
+		}, Handoff{}
+	}
+
+	h := Handoff{From: from, To: target, Reason: reason, Timestamp: now.Format(time.RFC3339)}
+	entry := map[string]any{"from": h.From, "to": h.To, "reason": h.Reason, "timestamp": h.Timestamp}
+
+	var handoffLog []any
+	if val, err := state.Get(HANDOFF_LOG_KEY); err == nil {
+		if entries, ok := val.([]any); ok {
+			handoffLog = append(handoffLog, entries...)
+		}
+	}
+	handoffLog = append(handoffLog, entry)
+	if len(handoffLog) > MAX_HANDOFF_LOG {
+		handoffLog = handoffLog[len(handoffLog)-MAX_HANDOFF_LOG:]
+	}
+
+	if err := state.Set(LAST_HANDOFF_KEY, entry); err != nil {
+		return handoffResults{Status: "error", Agent: target, ErrorMessage: fmt.Sprintf("failed to record the handoff: %v", err)}, Handoff{}
+	}
+	if err := state.Set(HANDOFF_LOG_KEY, handoffLog); err != nil {
+		return handoffResults{Status: "error", Agent: target, ErrorMessage: fmt.Sprintf("failed to record the handoff: %v", err)}, Handoff{}
+	}
+
+	return handoffResults{
+		Status:  "handed_off",
+		Agent:   target,
+		Reason:  reason,
+		Message: fmt.Sprintf("Handed off to %s: %s", target, reason),
+	}, h
+}
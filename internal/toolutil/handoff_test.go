@@ -0,0 +1,69 @@
+package toolutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+var handoffNames = []string{"order_agent", "policy_agent"}
+
+func TestHandoffRecordsTargetAndReason(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	results, h := handoff(state, handoffNames, "customer_service", handoffArgs{Agent: " order_agent ", Reason: "User wants a refund"}, now)
+	if results.Status != "handed_off" || h.To != "order_agent" || h.From != "customer_service" {
+		t.Fatalf("handoff() = %+v, %+v; want a handoff to order_agent", results, h)
+	}
+
+	last, _ := state.Value(LAST_HANDOFF_KEY).(map[string]any)
+	if last["to"] != "order_agent" || last["reason"] != "User wants a refund" || last["timestamp"] != "2025-03-01T12:00:00Z" {
+		t.Errorf("last_handoff = %v", last)
+	}
+	if entries, _ := state.Value(HANDOFF_LOG_KEY).([]any); len(entries) != 1 {
+		t.Errorf("handoff_log = %v, want one entry", state.Value(HANDOFF_LOG_KEY))
+	}
+}
+
+func TestHandoffRefusesUnknownAgentsAndMissingReasons(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+
+	results, _ := handoff(state, handoffNames, "customer_service", handoffArgs{Agent: "billing_agent", Reason: "Invoice question"}, time.Now())
+	if results.Status != "error" || len(results.ValidAgents) != 2 || !strings.Contains(results.ErrorMessage, "order_agent, policy_agent") {
+		t.Errorf("unknown agent: got %+v, want an error listing the valid agents", results)
+	}
+
+	results, _ = handoff(state, handoffNames, "customer_service", handoffArgs{Agent: "policy_agent", Reason: "  "}, time.Now())
+	if results.Status != "error" || !strings.Contains(results.ErrorMessage, "reason is required") {
+		t.Errorf("missing reason: got %+v, want an error", results)
+	}
+
+	if len(state.Values()) != 0 {
+		t.Errorf("refused handoffs changed state: %v", state)
+	}
+}
+
+func TestHandoffLogIsCapped(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+	for i := range MAX_HANDOFF_LOG + 5 {
+		handoff(state, handoffNames, "customer_service", handoffArgs{Agent: "policy_agent", Reason: fmt.Sprintf("question %d", i)}, time.Now())
+	}
+
+	entries, _ := state.Value(HANDOFF_LOG_KEY).([]any)
+	if len(entries) != MAX_HANDOFF_LOG {
+		t.Fatalf("handoff_log has %d entries, want %d", len(entries), MAX_HANDOFF_LOG)
+	}
+	if first := entries[0].(map[string]any); first["reason"] != "question 5" {
+		t.Errorf("oldest kept entry = %v, want question 5", first)
+	}
+}
+
+func TestNewHandoffToolNeedsSubAgents(t *testing.T) {
+	if _, err := NewHandoffTool(nil); err == nil {
+		t.Error("NewHandoffTool(nil) succeeded")
+	}
+}
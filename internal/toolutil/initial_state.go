@@ -0,0 +1,96 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/session"
+)
+
+// INITIAL_STATE_FILE is the file examples read the state of new sessions from, when it exists
+const INITIAL_STATE_FILE = "initial_state.json"
+
+// ===== Initial State =====
+//
+// Examples start new sessions with a hardcoded state map. LoadInitialState lets a JSON file
+// override it, so a demo can start with another user name or a purchased course without
+// recompiling. Keys missing from the file keep their default, and a missing file means the
+// defaults as they are.
+//
+// File values have their JSON shape (float64 numbers, []any lists, map[string]any objects),
+// the same shape state has after a database round trip, which the tools already read. A value
+// must have the same JSON type as its default (a list stays a list); validate checks the
+// contents the tools expect, such as the fields of each list entry.
+
+// LoadInitialState returns defaults overridden by the top-level keys of the JSON object in path,
+// and whether the file was found. validate, when not nil, checks the merged state.
+func LoadInitialState(path string, defaults map[string]any, validate func(map[string]any) error) (map[string]any, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return maps.Clone(defaults), false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read %s: %v", path, err)
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, true, fmt.Errorf("%s must hold a JSON object of state keys: %v", path, err)
+	}
+
+	state := maps.Clone(defaults)
+	if state == nil {
+		state = map[string]any{}
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		if strings.HasPrefix(key, session.KeyPrefixTemp) {
+			return nil, true, fmt.Errorf("%s: %q is a temp: key, which is never stored", path, key)
+		}
+		if def, ok := defaults[key]; ok {
+			want, err := jsonType(def)
+			if err != nil {
+				return nil, true, fmt.Errorf("default of %q: %w", key, err)
+			}
+			if got, _ := jsonType(value); got != want {
+				return nil, true, fmt.Errorf("%s: %q must be a JSON %s, got %s", path, key, want, got)
+			}
+		}
+		state[key] = value
+	}
+
+	if validate != nil {
+		if err := validate(state); err != nil {
+			return nil, true, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return state, true, nil
+}
+
+// jsonType names the JSON type value is encoded as: string, number, boolean, list, object or null
+func jsonType(value any) (string, error) {
+	normalized, err := normalizeStateValue(value)
+	if err != nil {
+		return "", err
+	}
+	switch normalized.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return "string", nil
+	case float64:
+		return "number", nil
+	case bool:
+		return "boolean", nil
+	case []any:
+		return "list", nil
+	case map[string]any:
+		return "object", nil
+	}
+	return fmt.Sprintf("%T", normalized), nil
+}
@@ -0,0 +1,93 @@
+package toolutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeInitialState(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), INITIAL_STATE_FILE)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadInitialStateMissingFile(t *testing.T) {
+	defaults := map[string]any{"user_name": "Muchlis", "purchased_courses": []any{}}
+
+	state, fromFile, err := LoadInitialState(filepath.Join(t.TempDir(), INITIAL_STATE_FILE), defaults, nil)
+	if err != nil || fromFile {
+		t.Fatalf("LoadInitialState() = %v, %v, want the defaults", fromFile, err)
+	}
+	if !reflect.DeepEqual(state, defaults) {
+		t.Errorf("state = %v, want %v", state, defaults)
+	}
+	state["user_name"] = "changed"
+	if defaults["user_name"] != "Muchlis" {
+		t.Error("changing the returned state changed the defaults")
+	}
+}
+
+func TestLoadInitialStateOverridesDefaults(t *testing.T) {
+	defaults := map[string]any{"user_name": "Muchlis", "reminders": []string{}, "notes": map[string]any{}}
+	path := writeInitialState(t, `{"user_name": "Alex", "reminders": ["buy milk"], "user:theme": "dark"}`)
+
+	state, fromFile, err := LoadInitialState(path, defaults, nil)
+	if err != nil || !fromFile {
+		t.Fatalf("LoadInitialState() = %v, %v, want the file loaded", fromFile, err)
+	}
+	want := map[string]any{
+		"user_name":  "Alex",
+		"reminders":  []any{"buy milk"},
+		"notes":      map[string]any{}, // not in the file, keeps its default
+		"user:theme": "dark",           // not in the defaults, taken as is
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("state = %#v, want %#v", state, want)
+	}
+}
+
+func TestLoadInitialStateRejectsBadFiles(t *testing.T) {
+	defaults := map[string]any{"user_name": "Muchlis", "purchased_courses": []map[string]any{}, "count": 0}
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not json", `user_name: Alex`, "JSON object"},
+		{"not an object", `["Alex"]`, "JSON object"},
+		{"list replaced by object", `{"purchased_courses": {"id": "ai_marketing_platform"}}`, `"purchased_courses" must be a JSON list, got object`},
+		{"string replaced by number", `{"user_name": 42}`, `"user_name" must be a JSON string, got number`},
+		{"null", `{"count": null}`, `"count" must be a JSON number, got null`},
+		{"temp key", `{"temp:draft": "x"}`, "temp:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadInitialState(writeInitialState(t, tt.content), defaults, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadInitialState() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadInitialStateValidates(t *testing.T) {
+	path := writeInitialState(t, `{"user_name": ""}`)
+	validate := func(state map[string]any) error {
+		if state["user_name"] == "" {
+			return errors.New("user_name must be a non-empty string")
+		}
+		return nil
+	}
+
+	_, _, err := LoadInitialState(path, map[string]any{"user_name": "Muchlis"}, validate)
+	if err == nil || !strings.Contains(err.Error(), "user_name must be a non-empty string") {
+		t.Errorf("LoadInitialState() error = %v, want the validation error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package toolutil
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const RESET_STATE = "reset_state"
+
+// ===== Reset State Tool Structures =====
+
+type resetStateArgs struct {
+	Key string `json:"key"`
+}
+
+type resetStateResults struct {
+	Status        string   `json:"status"`
+	Key           string   `json:"key,omitempty"`
+	PreviousValue any      `json:"previous_value,omitempty"`
+	ResetTo       any      `json:"reset_to,omitempty"`
+	AllowedKeys   []string `json:"allowed_keys,omitempty"`
+	Message       string   `json:"message,omitempty"`
+	ErrorMessage  string   `json:"error_message,omitempty"`
+}
+
+// ===== Tool Creation =====
+
+// NewResetStateTool creates a reset_state tool that sets one session state key back to its
+// initial value, e.g. clears interaction_history without deleting the whole session.
+// resettable maps each key the tool may touch to the value it is reset to; any other key
+// (such as user_name) is refused. ADK state has no delete, so a key is reset rather than
+// removed. Reset values are shared between calls, so use empty or immutable values.
+func NewResetStateTool(resettable map[string]any) (tool.Tool, error) {
+	if len(resettable) == 0 {
+		return nil, fmt.Errorf("failed to create %s tool: no resettable keys", RESET_STATE)
+	}
+
+	resetState := func(ctx tool.Context, input resetStateArgs) (resetStateResults, error) {
+		slog.Info("tool called", "tool", RESET_STATE, "key", input.Key, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+		return resetStateKey(ctx.State(), resettable, input.Key)
+	}
+
+	allowed := strings.Join(resettableKeys(resettable), ", ")
+	resetStateTool, err := functiontool.New(
+		functiontool.Config{
+			Name: RESET_STATE,
+			Description: "Resets one session state key to its initial value and returns what was cleared. " +
+				"Only these keys can be reset: " + allowed,
+		},
+		resetState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", RESET_STATE, err)
+	}
+
+	return resetStateTool, nil
+}
+
+// resetStateKey resets key if it is in resettable
+func resetStateKey(state session.State, resettable map[string]any, rawKey string) (resetStateResults, error) {
+	key := strings.TrimSpace(rawKey)
+	resetTo, ok := resettable[key]
+	if !ok {
+		return resetStateResults{
+			Status:       "error",
+			Key:          key,
+			AllowedKeys:  resettableKeys(resettable),
+			ErrorMessage: fmt.Sprintf("%q can't be reset; allowed keys: %s", key, strings.Join(resettableKeys(resettable), ", ")),
+		}, nil
+	}
+
+	previous, err := state.Get(key)
+	if err != nil {
+		previous = nil // not set yet; resetting still gives it a defined value
+	}
+	if err := state.Set(key, resetTo); err != nil {
+		return resetStateResults{}, fmt.Errorf("failed to reset %s: %w", key, err)
+	}
+
+	return resetStateResults{
+		Status:        "success",
+		Key:           key,
+		PreviousValue: previous,
+		ResetTo:       resetTo,
+		Message:       fmt.Sprintf("%s was reset", key),
+	}, nil
+}
+
+func resettableKeys(resettable map[string]any) []string {
+	keys := make([]string, 0, len(resettable))
+	for key := range resettable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+package toolutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+var testResettable = map[string]any{
+	"interaction_history": []any{},
+	"request_counter":     int64(0),
+}
+
+func TestResetStateKey(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"user_name":           "Muchlis",
+		"interaction_history": []any{map[string]any{"action": "user_query"}},
+	}, false)
+
+	result, err := resetStateKey(state, testResettable, " interaction_history ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "success" || result.Key != "interaction_history" {
+		t.Errorf("result = %+v, want success for interaction_history", result)
+	}
+	if want := []any{map[string]any{"action": "user_query"}}; !reflect.DeepEqual(result.PreviousValue, want) {
+		t.Errorf("PreviousValue = %v, want %v", result.PreviousValue, want)
+	}
+	if got := state.Value("interaction_history"); !reflect.DeepEqual(got, []any{}) {
+		t.Errorf("interaction_history = %v, want empty", got)
+	}
+	if state.Value("user_name") != "Muchlis" {
+		t.Errorf("user_name = %v, want it untouched", state.Value("user_name"))
+	}
+}
+
+func TestResetStateKeyUnset(t *testing.T) {
+	state := agenttest.NewState(nil, false)
+
+	result, err := resetStateKey(state, testResettable, "request_counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "success" || result.PreviousValue != nil {
+		t.Errorf("result = %+v, want success with no previous value", result)
+	}
+	if state.Value("request_counter") != int64(0) {
+		t.Errorf("request_counter = %v, want 0", state.Value("request_counter"))
+	}
+}
+
+func TestResetStateKeyNotAllowed(t *testing.T) {
+	state := agenttest.NewState(map[string]any{"user_name": "Muchlis"}, false)
+
+	result, err := resetStateKey(state, testResettable, "user_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "error" {
+		t.Errorf("Status = %q, want error", result.Status)
+	}
+	if want := []string{"interaction_history", "request_counter"}; !reflect.DeepEqual(result.AllowedKeys, want) {
+		t.Errorf("AllowedKeys = %v, want %v", result.AllowedKeys, want)
+	}
+	if state.Value("user_name") != "Muchlis" {
+		t.Errorf("user_name = %v, want it untouched", state.Value("user_name"))
+	}
+}
@@ -0,0 +1,114 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/tool"
+)
+
+// ===== Tool Schemas =====
+//
+// ToolSchemas shows each tool as the model sees it: its name, description and the JSON schemas of
+// its arguments and result. functiontool.New derives the schemas from the args and results
+// structs, so a field without a json tag is declared with its Go name ("Country", not "country"),
+// which is a common reason the model passes arguments in an unexpected shape.
+//
+// Function tools declare JSON schemas directly. Agent tools declare a genai.Schema, which is
+// converted (its OBJECT/STRING types become object/string). Tools without a declaration, such as
+// the built-in Gemini tools, are listed without schemas.
+
+// ToolSchema is one tool's declaration
+type ToolSchema struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	LongRunning  bool   `json:"long_running,omitempty"`
+	InputSchema  any    `json:"input_schema,omitempty"`
+	OutputSchema any    `json:"output_schema,omitempty"`
+}
+
+// declaredTool is a tool that declares a function to the model
+type declaredTool interface {
+	Declaration() *genai.FunctionDeclaration
+}
+
+// ToolSchemas returns the declarations of tools, in order. Pass the slice given to
+// llmagent.Config.
+func ToolSchemas(tools []tool.Tool) []ToolSchema {
+	schemas := make([]ToolSchema, 0, len(tools))
+	for _, t := range tools {
+		schema := ToolSchema{Name: t.Name(), Description: t.Description(), LongRunning: t.IsLongRunning()}
+		if d, ok := t.(declaredTool); ok {
+			if decl := d.Declaration(); decl != nil {
+				// The declared description is what the model reads; long-running tools get a note added
+				schema.Description = decl.Description
+				schema.InputSchema = declaredSchema(decl.ParametersJsonSchema, decl.Parameters)
+				schema.OutputSchema = declaredSchema(decl.ResponseJsonSchema, decl.Response)
+			}
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+// DumpToolSchemas writes the declarations of tools to w as an indented JSON array
+func DumpToolSchemas(w io.Writer, tools []tool.Tool) error {
+	data, err := json.MarshalIndent(ToolSchemas(tools), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tool schemas: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write tool schemas: %w", err)
+	}
+	return nil
+}
+
+// declaredSchema returns the JSON schema of a declaration, preferring the JSON schema form
+func declaredSchema(jsonSchema any, schema *genai.Schema) any {
+	if jsonSchema != nil {
+		return jsonSchema
+	}
+	if schema != nil {
+		return genaiToJSONSchema(schema)
+	}
+	return nil
+}
+
+// genaiToJSONSchema converts a genai.Schema, which uses upper-case OpenAPI types, to JSON schema
+func genaiToJSONSchema(s *genai.Schema) map[string]any {
+	out := map[string]any{}
+	if s.Type != "" && s.Type != genai.TypeUnspecified {
+		typ := any(strings.ToLower(string(s.Type)))
+		if s.Nullable != nil && *s.Nullable {
+			typ = []string{strings.ToLower(string(s.Type)), "null"}
+		}
+		out["type"] = typ
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if len(s.Properties) > 0 {
+		properties := map[string]any{}
+		for name, p := range s.Properties {
+			properties[name] = genaiToJSONSchema(p)
+		}
+		out["properties"] = properties
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	if s.Items != nil {
+		out["items"] = genaiToJSONSchema(s.Items)
+	}
+	return out
+}
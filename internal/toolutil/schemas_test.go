@@ -0,0 +1,90 @@
+package toolutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/geminitool"
+)
+
+type capitalArgs struct {
+	Country string // no json tag, so the schema declares "Country"
+	Lang    string `json:"lang,omitempty"`
+}
+
+type capitalResults struct {
+	Result string `json:"result"`
+}
+
+// dumpSchemas returns the tools' schemas after a round trip through DumpToolSchemas
+func dumpSchemas(t *testing.T, tools []tool.Tool) []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := DumpToolSchemas(&buf, tools); err != nil {
+		t.Fatal(err)
+	}
+	var schemas []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &schemas); err != nil {
+		t.Fatalf("DumpToolSchemas wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	return schemas
+}
+
+func TestDumpToolSchemasFunctionTool(t *testing.T) {
+	capitalTool, err := functiontool.New(functiontool.Config{Name: "get_capital_city", Description: "Finds a capital"},
+		func(ctx tool.Context, input capitalArgs) (capitalResults, error) { return capitalResults{}, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := dumpSchemas(t, []tool.Tool{capitalTool})
+	if len(schemas) != 1 || schemas[0]["name"] != "get_capital_city" || schemas[0]["description"] != "Finds a capital" {
+		t.Fatalf("schemas = %v, want get_capital_city with its description", schemas)
+	}
+
+	input, _ := schemas[0]["input_schema"].(map[string]any)
+	properties, _ := input["properties"].(map[string]any)
+	if input["type"] != "object" || properties["Country"] == nil || properties["lang"] == nil {
+		t.Errorf("input_schema = %v, want an object with Country and lang", input)
+	}
+	if required, _ := input["required"].([]any); !reflect.DeepEqual(required, []any{"Country"}) {
+		t.Errorf("input_schema required = %v, want [Country]", input["required"])
+	}
+
+	output, _ := schemas[0]["output_schema"].(map[string]any)
+	if properties, _ := output["properties"].(map[string]any); properties["result"] == nil {
+		t.Errorf("output_schema = %v, want a result property", output)
+	}
+}
+
+func TestDumpToolSchemasAgentAndBuiltinTools(t *testing.T) {
+	helper, err := llmagent.New(llmagent.Config{Name: "helper", Description: "Helps"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := dumpSchemas(t, []tool.Tool{agenttool.New(helper, nil), geminitool.GoogleSearch{}})
+	if len(schemas) != 2 {
+		t.Fatalf("got %d schemas, want 2", len(schemas))
+	}
+
+	// An agent tool's genai.Schema is converted to JSON schema types
+	want := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"request": map[string]any{"type": "string"}},
+		"required":   []any{"request"},
+	}
+	if got := schemas[0]["input_schema"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("agent tool input_schema = %v, want %v", got, want)
+	}
+
+	if schemas[1]["name"] != "google_search" || schemas[1]["input_schema"] != nil {
+		t.Errorf("built-in tool = %v, want google_search without schemas", schemas[1])
+	}
+}
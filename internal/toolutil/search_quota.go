@@ -0,0 +1,190 @@
+package toolutil
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+const (
+	MAX_SEARCHES_ENV     = "MAX_SEARCHES_PER_SESSION"
+	DEFAULT_MAX_SEARCHES = 10
+
+	// SEARCH_QUOTA_SESSION_KEY names the root session that searches of agents run through
+	// agenttool count against; the root agent's BeforeRootAgent sets it
+	SEARCH_QUOTA_SESSION_KEY = "search_quota_session"
+
+	SEARCH_QUOTA_REACHED = "Search quota reached for this session: web search is unavailable. Answer with " +
+		"the information already in this conversation, and tell the user the answer may not include the " +
+		"latest results because the search limit was reached."
+)
+
+// ===== Search Quota =====
+//
+// geminitool.GoogleSearch{} is a built-in tool: Gemini runs the searches while generating a
+// response, so there is no call to intercept. SearchQuota limits them per session instead:
+//   - AfterModel counts the searches a response made, from the web search queries in its
+//     grounding metadata
+//   - The tool returned by Wrap is only added to a model request while the session is under
+//     the limit. Past it, the request gets no search tool and an instruction saying the quota
+//     was reached, so the agent answers from what it already knows instead of searching again.
+//
+// The limit is checked before each model call, so the call that reaches it can make a few
+// searches more. Counts are kept in memory only, and a new session starts from zero.
+//
+// An agent wrapped with agenttool runs in a fresh session for every call, which would reset
+// its count each time. Add BeforeRootAgent to the root agent: it records the root session in
+// SEARCH_QUOTA_SESSION_KEY, which agenttool copies into the nested session, so all searches
+// count against the conversation the user is in.
+
+// SearchQuota holds per-session search counts. Create one with NewSearchQuota, wrap the search
+// tool with Wrap and add AfterModel to the AfterModelCallbacks of the agent that uses it.
+type SearchQuota struct {
+	MaxSearches int
+
+	mu     sync.Mutex
+	counts map[sessionKey]int
+}
+
+// NewSearchQuota creates a quota allowing maxSearches searches per session; a non-positive
+// maxSearches doesn't limit searches
+func NewSearchQuota(maxSearches int) *SearchQuota {
+	return &SearchQuota{MaxSearches: maxSearches, counts: map[sessionKey]int{}}
+}
+
+// LoadMaxSearches returns MAX_SEARCHES_PER_SESSION when set, and DEFAULT_MAX_SEARCHES otherwise.
+// 0 turns the limit off. getenv is usually os.Getenv.
+func LoadMaxSearches(getenv func(string) string) (int, error) {
+	val := strings.TrimSpace(getenv(MAX_SEARCHES_ENV))
+	if val == "" {
+		return DEFAULT_MAX_SEARCHES, nil
+	}
+	maxSearches, err := strconv.Atoi(val)
+	if err != nil || maxSearches < 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a number of searches, or 0 for no limit", MAX_SEARCHES_ENV, val)
+	}
+	return maxSearches, nil
+}
+
+// Wrap returns t, usually geminitool.GoogleSearch{}, added to model requests only while the
+// session is under the quota
+func (q *SearchQuota) Wrap(t tool.Tool) tool.Tool {
+	return &quotaSearchTool{inner: t, quota: q}
+}
+
+// BeforeRootAgent is an agent.BeforeAgentCallback for the root agent. It records the session in
+// SEARCH_QUOTA_SESSION_KEY, so agents it runs through agenttool count against it.
+func (q *SearchQuota) BeforeRootAgent(ctx agent.CallbackContext) (*genai.Content, error) {
+	if root, _ := ctx.State().Get(SEARCH_QUOTA_SESSION_KEY); root != nil {
+		return nil, nil
+	}
+	if err := ctx.State().Set(SEARCH_QUOTA_SESSION_KEY, ctx.AppName()+"/"+ctx.SessionID()); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", SEARCH_QUOTA_SESSION_KEY, err)
+	}
+	return nil, nil
+}
+
+// AfterModel is an llmagent.AfterModelCallback that counts the searches in the response and
+// leaves it as is
+func (q *SearchQuota) AfterModel(ctx agent.CallbackContext, resp *model.LLMResponse, err error) (*model.LLMResponse, error) {
+	if searches := searchesIn(resp); searches > 0 {
+		count := q.add(quotaKey(ctx.AppName(), ctx.UserID(), ctx.SessionID(), ctx.State()), searches)
+		slog.Info("searches counted", "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "searches", searches, "total", count)
+	}
+	return nil, nil
+}
+
+// add counts searches for the session and returns its total
+func (q *SearchQuota) add(key sessionKey, searches int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.counts[key] += searches
+	return q.counts[key]
+}
+
+// reached reports whether the session has used up its searches
+func (q *SearchQuota) reached(key sessionKey) bool {
+	if q.MaxSearches <= 0 {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.counts[key] >= q.MaxSearches
+}
+
+// quotaKey returns the session searches count against: the root session recorded in state, or
+// the session itself
+func quotaKey(appName, userID, sessionID string, state session.ReadonlyState) sessionKey {
+	if root, _ := state.Get(SEARCH_QUOTA_SESSION_KEY); root != nil {
+		if s, ok := root.(string); ok && s != "" {
+			return sessionKey{userID: userID, sessionID: s}
+		}
+	}
+	return sessionKey{appName, userID, appName + "/" + sessionID}
+}
+
+// searchesIn returns how many searches a response made. Streamed responses report them on the
+// final, non-partial response.
+func searchesIn(resp *model.LLMResponse) int {
+	if resp == nil || resp.Partial || resp.GroundingMetadata == nil {
+		return 0
+	}
+	if n := len(resp.GroundingMetadata.WebSearchQueries); n > 0 {
+		return n
+	}
+	// Grounded without the queries listed: count it as one search
+	if len(resp.GroundingMetadata.GroundingChunks) > 0 {
+		return 1
+	}
+	return 0
+}
+
+type quotaSearchTool struct {
+	inner tool.Tool
+	quota *SearchQuota
+}
+
+func (t *quotaSearchTool) Name() string        { return t.inner.Name() }
+func (t *quotaSearchTool) Description() string { return t.inner.Description() }
+func (t *quotaSearchTool) IsLongRunning() bool { return t.inner.IsLongRunning() }
+
+// requestProcessor is how a tool adds itself to a model request
+type requestProcessor interface {
+	ProcessRequest(ctx tool.Context, req *model.LLMRequest) error
+}
+
+// ProcessRequest adds the search tool to the request, or the quota instruction once the session
+// has reached the quota
+func (t *quotaSearchTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	if t.quota.reached(quotaKey(ctx.AppName(), ctx.UserID(), ctx.SessionID(), ctx.State())) {
+		slog.Warn("search quota reached", "tool", t.Name(), "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "max_searches", t.quota.MaxSearches)
+		appendSystemInstruction(req, SEARCH_QUOTA_REACHED)
+		return nil
+	}
+	inner, ok := t.inner.(requestProcessor)
+	if !ok {
+		return fmt.Errorf("tool %q can't be added to a model request", t.Name())
+	}
+	return inner.ProcessRequest(ctx, req)
+}
+
+// appendSystemInstruction adds text to the end of the request's system instruction
+func appendSystemInstruction(req *model.LLMRequest, text string) {
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	if req.Config.SystemInstruction == nil {
+		req.Config.SystemInstruction = genai.NewContentFromText(text, genai.RoleUser)
+		return
+	}
+	req.Config.SystemInstruction.Parts = append(req.Config.SystemInstruction.Parts, genai.NewPartFromText("\n\n"+text))
+}
@@ -0,0 +1,151 @@
+package toolutil
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/geminitool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+// sessionToolContext is a tool.Context (and so an agent.CallbackContext) for one session; only the
+// session methods work
+type sessionToolContext struct {
+	tool.Context
+	appName, sessionID string
+	state              *agenttest.State
+}
+
+func (c *sessionToolContext) AppName() string      { return c.appName }
+func (c *sessionToolContext) UserID() string       { return "user" }
+func (c *sessionToolContext) SessionID() string    { return c.sessionID }
+func (c *sessionToolContext) AgentName() string    { return c.appName }
+func (c *sessionToolContext) State() session.State { return c.state }
+
+// searchResponse is a model response that ran the given search queries
+func searchResponse(queries ...string) *model.LLMResponse {
+	return &model.LLMResponse{
+		Content:           genai.NewContentFromText("Here is the news.", genai.RoleModel),
+		GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: queries},
+	}
+}
+
+// hasSearch prepares a request with search and reports whether the search tool was added
+func hasSearch(t *testing.T, search tool.Tool, ctx tool.Context) bool {
+	t.Helper()
+	req := &model.LLMRequest{}
+	if err := search.(requestProcessor).ProcessRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	if req.Config != nil {
+		for _, tl := range req.Config.Tools {
+			found = found || tl.GoogleSearch != nil
+		}
+	}
+	return found
+}
+
+func TestSearchQuotaLimitsSearches(t *testing.T) {
+	quota := NewSearchQuota(3)
+	search := quota.Wrap(geminitool.GoogleSearch{})
+	ctx := &sessionToolContext{appName: "news_analyst", sessionID: "s1", state: agenttest.NewState(nil, false)}
+
+	if search.Name() != "google_search" {
+		t.Errorf("Name() = %q, want google_search", search.Name())
+	}
+	if !hasSearch(t, search, ctx) {
+		t.Fatal("search missing from a new session's request")
+	}
+
+	// Partial responses don't count; the final response reports the searches
+	quota.AfterModel(ctx, &model.LLMResponse{Partial: true, GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: []string{"ai news"}}}, nil)
+	quota.AfterModel(ctx, searchResponse("ai news", "gemini release"), nil)
+	if !hasSearch(t, search, ctx) {
+		t.Fatal("search missing after 2 of 3 searches")
+	}
+
+	quota.AfterModel(ctx, searchResponse("chip news"), nil)
+	req := &model.LLMRequest{}
+	if err := search.(requestProcessor).ProcessRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Config.Tools) != 0 {
+		t.Errorf("tools = %v, want no search once the quota is reached", req.Config.Tools)
+	}
+	if si := req.Config.SystemInstruction; si == nil || !strings.Contains(si.Parts[len(si.Parts)-1].Text, "Search quota reached") {
+		t.Errorf("system instruction = %+v, want the quota note", si)
+	}
+
+	// Another session starts from zero
+	if !hasSearch(t, search, &sessionToolContext{appName: "news_analyst", sessionID: "s2", state: agenttest.NewState(nil, false)}) {
+		t.Error("search missing from another session's request")
+	}
+}
+
+func TestSearchQuotaCountsAgainstRootSession(t *testing.T) {
+	quota := NewSearchQuota(2)
+	search := quota.Wrap(geminitool.GoogleSearch{})
+
+	root := &sessionToolContext{appName: "manager", sessionID: "root", state: agenttest.NewState(nil, false)}
+	if _, err := quota.BeforeRootAgent(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// agenttool runs every call in a new session, with a copy of the root's state
+	for _, id := range []string{"call-1", "call-2"} {
+		call := &sessionToolContext{appName: "news_analyst", sessionID: id, state: agenttest.NewState(map[string]any{SEARCH_QUOTA_SESSION_KEY: root.state.Value(SEARCH_QUOTA_SESSION_KEY)}, false)}
+		if !hasSearch(t, search, call) {
+			t.Fatalf("%s: search missing before the quota is reached", id)
+		}
+		quota.AfterModel(call, searchResponse("ai news"), nil)
+	}
+
+	next := &sessionToolContext{appName: "news_analyst", sessionID: "call-3", state: agenttest.NewState(map[string]any{SEARCH_QUOTA_SESSION_KEY: root.state.Value(SEARCH_QUOTA_SESSION_KEY)}, false)}
+	if hasSearch(t, search, next) {
+		t.Error("search added after the calls of the root session used up the quota")
+	}
+
+	// A later turn keeps the recorded session
+	if _, err := quota.BeforeRootAgent(root); err != nil || root.state.Value(SEARCH_QUOTA_SESSION_KEY) != "manager/root" {
+		t.Errorf("root session = %v, %v; want manager/root", root.state.Value(SEARCH_QUOTA_SESSION_KEY), err)
+	}
+}
+
+func TestSearchQuotaUnlimited(t *testing.T) {
+	quota := NewSearchQuota(0)
+	search := quota.Wrap(geminitool.GoogleSearch{})
+	ctx := &sessionToolContext{appName: "news_analyst", sessionID: "s1", state: agenttest.NewState(nil, false)}
+	for i := 0; i < 20; i++ {
+		quota.AfterModel(ctx, searchResponse("ai news"), nil)
+	}
+	if !hasSearch(t, search, ctx) {
+		t.Error("search missing with no limit")
+	}
+}
+
+func TestLoadMaxSearches(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    int
+		wantErr bool
+	}{
+		{"", DEFAULT_MAX_SEARCHES, false},
+		{"5", 5, false},
+		{" 0 ", 0, false},
+		{"-1", 0, true},
+		{"lots", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := LoadMaxSearches(func(string) string { return tt.env })
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("LoadMaxSearches(%q) = %d, %v; want %d, error %v", tt.env, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
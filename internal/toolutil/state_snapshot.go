@@ -0,0 +1,309 @@
+package toolutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	EXPORT_STATE = "export_state"
+	IMPORT_STATE = "import_state"
+
+	STATE_SNAPSHOT_VERSION = 1
+)
+
+// ===== State Snapshots =====
+//
+// export_state writes the session's state to a JSON file and import_state loads such a file
+// into a new session, to back up a session, move a user to another environment, or reproduce a
+// support case locally.
+//
+// Type fidelity: a value written to session state comes back differently once it has been
+// through JSON: numbers become float64, typed lists such as []map[string]any or []string
+// become []any, and structs become map[string]any. The database session service stores state
+// as JSON, so the tools reading state already expect those shapes after a restart. A snapshot
+// is normalized to exactly those shapes when it is exported, so the file holds what import
+// returns, and an imported session reads the same as a reloaded one. Integers beyond 2^53 lose
+// precision as float64, so export refuses them rather than silently changing them.
+//
+// temp: keys are never persisted and are left out. app: keys are shared by every user of
+// the app, so they are exported for reference but never imported.
+
+// StateSnapshot is the file written by export_state
+type StateSnapshot struct {
+	Version    int            `json:"version"`
+	AppName    string         `json:"app_name"`
+	UserID     string         `json:"user_id"`
+	SessionID  string         `json:"session_id"`
+	ExportedAt string         `json:"exported_at"` // RFC 3339
+	State      map[string]any `json:"state"`
+}
+
+type exportStateArgs struct {
+	Filename string `json:"filename,omitempty"` // optional file name; defaults to state-<session id>.json
+}
+
+type exportStateResults struct {
+	Status       string   `json:"status"`
+	Path         string   `json:"path,omitempty"`
+	Keys         []string `json:"keys,omitempty"`
+	ErrorMessage string   `json:"error_message,omitempty"`
+}
+
+type importStateArgs struct {
+	Filename string `json:"filename"`
+}
+
+type importStateResults struct {
+	Status          string   `json:"status"`
+	SessionID       string   `json:"session_id,omitempty"` // the new session holding the imported state
+	Keys            []string `json:"keys,omitempty"`
+	SkippedKeys     []string `json:"skipped_keys,omitempty"`
+	SourceUserID    string   `json:"source_user_id,omitempty"`
+	SourceSessionID string   `json:"source_session_id,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	ErrorMessage    string   `json:"error_message,omitempty"`
+}
+
+// ===== Tool Creation =====
+
+// NewExportStateTool creates an export_state tool that writes the current session's state as a
+// StateSnapshot into dir. As with export_conversation, only a file name is taken from the model.
+func NewExportStateTool(dir string) (tool.Tool, error) {
+	exportState := func(ctx tool.Context, input exportStateArgs) (exportStateResults, error) {
+		slog.Info("tool called", "tool", EXPORT_STATE, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+
+		filename, err := bareFilename(input.Filename, "state-"+ctx.SessionID(), ".json")
+		if err != nil {
+			return exportStateResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+		snapshot, err := NewStateSnapshot(ctx.State(), ctx.AppName(), ctx.UserID(), ctx.SessionID(), time.Now())
+		if err != nil {
+			return exportStateResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return exportStateResults{}, fmt.Errorf("failed to create export directory %s: %w", dir, err)
+		}
+		path := filepath.Join(dir, filename)
+		if err := WriteStateSnapshot(path, snapshot); err != nil {
+			return exportStateResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		keys := slices.Sorted(maps.Keys(snapshot.State))
+		slog.Info("state exported", "session_id", ctx.SessionID(), "path", path, "keys", len(keys))
+		return exportStateResults{Status: "success", Path: path, Keys: keys}, nil
+	}
+
+	exportTool, err := functiontool.New(
+		functiontool.Config{
+			Name: EXPORT_STATE,
+			Description: "Saves this session's state (purchases, history and other stored data) to a JSON snapshot file " +
+				"for backup or support, and returns its path. filename is optional",
+		},
+		exportState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", EXPORT_STATE, err)
+	}
+	return exportTool, nil
+}
+
+// NewImportStateTool creates an import_state tool that reads a StateSnapshot from dir and creates
+// a new session for the current user with its state. The current session is left unchanged; the
+// result has the new session's ID. Snapshots of another app are refused.
+func NewImportStateTool(sessions session.Service, dir string) (tool.Tool, error) {
+	importState := func(ctx tool.Context, input importStateArgs) (importStateResults, error) {
+		slog.Info("tool called", "tool", IMPORT_STATE, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "filename", input.Filename)
+
+		filename, err := bareFilename(input.Filename, "", ".json")
+		if err != nil {
+			return importStateResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+		snapshot, err := ReadStateSnapshot(filepath.Join(dir, filename))
+		if err != nil {
+			return importStateResults{Status: "error", ErrorMessage: err.Error()}, nil
+		}
+		if snapshot.AppName != ctx.AppName() {
+			return importStateResults{
+				Status:       "error",
+				ErrorMessage: fmt.Sprintf("the snapshot is from app %q, not %q", snapshot.AppName, ctx.AppName()),
+			}, nil
+		}
+
+		state, skipped := importableState(snapshot.State)
+		resp, err := sessions.Create(ctx, &session.CreateRequest{
+			AppName: ctx.AppName(),
+			UserID:  ctx.UserID(),
+			State:   state,
+		})
+		if err != nil {
+			return importStateResults{}, fmt.Errorf("failed to create session: %w", err)
+		}
+
+		keys := slices.Sorted(maps.Keys(state))
+		slog.Info("state imported", "session_id", resp.Session.ID(), "from_session_id", snapshot.SessionID, "keys", len(keys), "skipped", skipped)
+		return importStateResults{
+			Status:          "success",
+			SessionID:       resp.Session.ID(),
+			Keys:            keys,
+			SkippedKeys:     skipped,
+			SourceUserID:    snapshot.UserID,
+			SourceSessionID: snapshot.SessionID,
+			Message:         fmt.Sprintf("Imported %d key(s) into new session %s; continue there to use it", len(keys), resp.Session.ID()),
+		}, nil
+	}
+
+	importTool, err := functiontool.New(
+		functiontool.Config{
+			Name: IMPORT_STATE,
+			Description: "Loads a JSON snapshot written by export_state into a new session for this user and returns the new session ID. " +
+				"filename is the snapshot's file name",
+		},
+		importState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", IMPORT_STATE, err)
+	}
+	return importTool, nil
+}
+
+// ===== Snapshot Files =====
+
+// NewStateSnapshot copies state into a snapshot, normalized to its JSON shape. temp: keys are
+// left out. Values that can't be stored as JSON, or integers beyond 2^53, are an error.
+func NewStateSnapshot(state session.ReadonlyState, appName, userID, sessionID string, now time.Time) (StateSnapshot, error) {
+	values := map[string]any{}
+	for key, value := range state.All() {
+		if strings.HasPrefix(key, session.KeyPrefixTemp) {
+			continue
+		}
+		normalized, err := normalizeStateValue(value)
+		if err != nil {
+			return StateSnapshot{}, fmt.Errorf("state key %q can't be exported: %w", key, err)
+		}
+		values[key] = normalized
+	}
+
+	return StateSnapshot{
+		Version:    STATE_SNAPSHOT_VERSION,
+		AppName:    appName,
+		UserID:     userID,
+		SessionID:  sessionID,
+		ExportedAt: now.Format(time.RFC3339),
+		State:      values,
+	}, nil
+}
+
+// WriteStateSnapshot writes s to path as indented JSON
+func WriteStateSnapshot(path string, s StateSnapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("could not write %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadStateSnapshot reads a snapshot written by WriteStateSnapshot. State values come back in
+// their JSON shape (float64 numbers, []any lists, map[string]any objects).
+func ReadStateSnapshot(path string) (StateSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return StateSnapshot{}, fmt.Errorf("could not read %s: %v", filepath.Base(path), err)
+	}
+	var s StateSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return StateSnapshot{}, fmt.Errorf("%s isn't a state snapshot: %v", filepath.Base(path), err)
+	}
+	if s.Version != STATE_SNAPSHOT_VERSION {
+		return StateSnapshot{}, fmt.Errorf("%s has snapshot version %d, want %d", filepath.Base(path), s.Version, STATE_SNAPSHOT_VERSION)
+	}
+	if s.State == nil {
+		s.State = map[string]any{}
+	}
+	return s, nil
+}
+
+// importableState drops the keys import_state must not write: app: keys, which belong to every
+// user, and temp: keys, which are never persisted. It returns the dropped keys, sorted.
+func importableState(values map[string]any) (map[string]any, []string) {
+	state := make(map[string]any, len(values))
+	var skipped []string
+	for key, value := range values {
+		if strings.HasPrefix(key, session.KeyPrefixApp) || strings.HasPrefix(key, session.KeyPrefixTemp) {
+			skipped = append(skipped, key)
+			continue
+		}
+		state[key] = value
+	}
+	slices.Sort(skipped)
+	return state, skipped
+}
+
+// normalizeStateValue returns value as it reads after a JSON round trip. Integers that float64
+// can't hold exactly are refused instead of being rounded.
+func normalizeStateValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var exact any
+	if err := dec.Decode(&exact); err != nil {
+		return nil, err
+	}
+	return fromJSONNumbers(exact)
+}
+
+// fromJSONNumbers replaces every json.Number with its float64 value
+func fromJSONNumbers(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		isInteger := !strings.ContainsAny(v.String(), ".eE")
+		if n, err := v.Int64(); isInteger && (err != nil || int64(f) != n) {
+			return nil, fmt.Errorf("integer %s is too large to keep exactly", v)
+		}
+		return f, nil
+	case []any:
+		for i, item := range v {
+			converted, err := fromJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	case map[string]any:
+		for key, item := range v {
+			converted, err := fromJSONNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	}
+	return value, nil
+}
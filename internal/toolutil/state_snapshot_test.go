@@ -0,0 +1,123 @@
+package toolutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func TestStateSnapshotRoundTrip(t *testing.T) {
+	state := agenttest.NewState(map[string]any{
+		"user_name": "Muchlis",
+		"purchased_courses": []map[string]any{
+			{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+		},
+		"history_summary_stats": map[string]any{
+			"count":   12,
+			"actions": map[string]any{"user_query": 10, "purchase_course": 2},
+			"courses": map[string]any{"purchase_course": []string{"ai_marketing_platform"}},
+		},
+		"cost_estimate":    map[string]any{"total_usd": 0.0125, "model_calls": int64(3)},
+		"user:nickname":    "Lis",
+		"app:announcement": "New course soon",
+		"temp:draft":       "not persisted",
+	}, false)
+	now := time.Date(2024, 12, 3, 15, 30, 0, 0, time.UTC)
+
+	snapshot, err := NewStateSnapshot(state, "customer_service", "u1", "s1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := WriteStateSnapshot(path, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadStateSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every value comes back in its JSON shape, as the database session service returns it
+	want := map[string]any{
+		"user_name": "Muchlis",
+		"purchased_courses": []any{
+			map[string]any{"id": "ai_marketing_platform", "purchase_date": "2024-12-03 15:30:00"},
+		},
+		"history_summary_stats": map[string]any{
+			"count":   float64(12),
+			"actions": map[string]any{"user_query": float64(10), "purchase_course": float64(2)},
+			"courses": map[string]any{"purchase_course": []any{"ai_marketing_platform"}},
+		},
+		"cost_estimate":    map[string]any{"total_usd": 0.0125, "model_calls": float64(3)},
+		"user:nickname":    "Lis",
+		"app:announcement": "New course soon",
+	}
+	if !reflect.DeepEqual(got.State, want) {
+		t.Errorf("state = %#v\nwant %#v", got.State, want)
+	}
+	if !reflect.DeepEqual(got.State, snapshot.State) {
+		t.Errorf("read state = %#v, want the exported state %#v", got.State, snapshot.State)
+	}
+	if got.AppName != "customer_service" || got.UserID != "u1" || got.SessionID != "s1" || got.ExportedAt != "2024-12-03T15:30:00Z" {
+		t.Errorf("snapshot header = %+v, want the exporting session", got)
+	}
+
+	// The original state isn't changed by normalizing it
+	if _, ok := state.Value("purchased_courses").([]map[string]any); !ok {
+		t.Errorf("purchased_courses in the session = %T, want it left as []map[string]any", state.Value("purchased_courses"))
+	}
+
+	imported, skipped := importableState(got.State)
+	if _, ok := imported["app:announcement"]; ok || !slices.Equal(skipped, []string{"app:announcement"}) {
+		t.Errorf("importableState skipped %v, want only app:announcement", skipped)
+	}
+	if _, ok := imported["user:nickname"]; !ok {
+		t.Error("importableState dropped user:nickname, want user state imported")
+	}
+}
+
+func TestStateSnapshotRefusesInexactIntegers(t *testing.T) {
+	state := agenttest.NewState(map[string]any{"order_id": int64(1<<53 + 1)}, false)
+
+	_, err := NewStateSnapshot(state, "app", "u1", "s1", time.Now())
+	if err == nil || !strings.Contains(err.Error(), "order_id") {
+		t.Errorf("NewStateSnapshot() error = %v, want order_id refused", err)
+	}
+}
+
+func TestReadStateSnapshotRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"not_json.json":    "reminders: buy milk",
+		"old_version.json": `{"version": 99, "state": {}}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadStateSnapshot(path); err == nil {
+			t.Errorf("ReadStateSnapshot(%s) succeeded, want an error", name)
+		}
+	}
+	if _, err := ReadStateSnapshot(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("ReadStateSnapshot(missing.json) succeeded, want an error")
+	}
+}
+
+func TestBareFilename(t *testing.T) {
+	if got, err := bareFilename("../backup", "state-s1", ".json"); err != nil || got != "backup.json" {
+		t.Errorf(`bareFilename("../backup") = %q, %v, want "backup.json"`, got, err)
+	}
+	if got, err := bareFilename("", "state-s1", ".json"); err != nil || got != "state-s1.json" {
+		t.Errorf(`bareFilename("") = %q, %v, want the default name`, got, err)
+	}
+	if _, err := bareFilename(" ", "", ".json"); err == nil {
+		t.Error(`bareFilename(" ") without a default succeeded, want an error`)
+	}
+}
@@ -0,0 +1,130 @@
+// Package toolutil provides wrappers that add behavior to existing ADK tools.
+package toolutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// functionTool is the method set ADK uses to declare and call a function tool
+// (functiontool.New returns one)
+type functionTool interface {
+	tool.Tool
+	Declaration() *genai.FunctionDeclaration
+	Run(ctx tool.Context, args any) (map[string]any, error)
+}
+
+// WithTimeout bounds how long a function tool may run. The tool keeps its name, description
+// and schema, so the model sees no difference. When it takes longer than d, the call returns
+// a result with status "error" and an error_message instead of hanging the agent.
+//
+// The wrapped tool gets a context that is canceled at the deadline, so tools that honor
+// ctx.Done() (HTTP requests, gopsutil's *WithContext calls) stop as well. A tool that ignores
+// its context keeps running in the background; anything it does after the deadline is discarded.
+//
+// Tools that aren't function tools (e.g. built-in Gemini tools) and a non-positive d are
+// returned unchanged.
+func WithTimeout(d time.Duration, t tool.Tool) tool.Tool {
+	inner, ok := t.(functionTool)
+	if !ok || d <= 0 {
+		return t
+	}
+	return &timeoutTool{inner: inner, timeout: d}
+}
+
+type timeoutTool struct {
+	inner   functionTool
+	timeout time.Duration
+}
+
+func (t *timeoutTool) Name() string        { return t.inner.Name() }
+func (t *timeoutTool) Description() string { return t.inner.Description() }
+func (t *timeoutTool) IsLongRunning() bool { return t.inner.IsLongRunning() }
+
+func (t *timeoutTool) Declaration() *genai.FunctionDeclaration { return t.inner.Declaration() }
+
+// ProcessRequest adds the tool to the LLM request. It registers the wrapper rather than the
+// inner tool, so calls from the model go through Run below.
+func (t *timeoutTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	if req.Tools == nil {
+		req.Tools = make(map[string]any)
+	}
+	if _, exists := req.Tools[t.Name()]; exists {
+		return fmt.Errorf("duplicate tool: %q", t.Name())
+	}
+	req.Tools[t.Name()] = t
+
+	decl := t.Declaration()
+	if decl == nil {
+		return nil
+	}
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	// Function declarations share a single genai.Tool, as functiontool does
+	for _, genaiTool := range req.Config.Tools {
+		if genaiTool != nil && genaiTool.FunctionDeclarations != nil {
+			genaiTool.FunctionDeclarations = append(genaiTool.FunctionDeclarations, decl)
+			return nil
+		}
+	}
+	req.Config.Tools = append(req.Config.Tools, &genai.Tool{FunctionDeclarations: []*genai.FunctionDeclaration{decl}})
+	return nil
+}
+
+type runResult struct {
+	result map[string]any
+	err    error
+}
+
+// Run calls the wrapped tool on a goroutine and waits for it, the deadline, or the caller's cancellation
+func (t *timeoutTool) Run(ctx tool.Context, args any) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	done := make(chan runResult, 1) // buffered, so a late tool doesn't block forever
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- runResult{err: fmt.Errorf("tool %q panicked: %v", t.Name(), r)}
+			}
+		}()
+		result, err := t.inner.Run(&timeoutContext{Context: ctx, ctx: runCtx}, args)
+		done <- runResult{result: result, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.result, r.err
+	case <-runCtx.Done():
+		// The agent run itself was canceled, not just this tool
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fmt.Printf("--- Tool: %s timed out after %s ---\n", t.Name(), t.timeout)
+		return map[string]any{
+			"status":        "error",
+			"error_message": fmt.Sprintf("%s timed out after %s", t.Name(), t.timeout),
+		}, nil
+	}
+}
+
+// timeoutContext is the tool context with its context.Context methods replaced by the deadline-bound one
+type timeoutContext struct {
+	tool.Context
+	ctx context.Context
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c *timeoutContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c *timeoutContext) Err() error                  { return c.ctx.Err() }
+func (c *timeoutContext) Value(key any) any           { return c.ctx.Value(key) }
@@ -0,0 +1,117 @@
+package toolutil
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+// fakeToolContext is a tool.Context backed by a plain context; only the context.Context methods work
+type fakeToolContext struct {
+	tool.Context
+	ctx context.Context
+}
+
+func (c *fakeToolContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c *fakeToolContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c *fakeToolContext) Err() error                  { return c.ctx.Err() }
+func (c *fakeToolContext) Value(key any) any           { return c.ctx.Value(key) }
+
+type waitArgs struct {
+	Delay string `json:"delay"`
+}
+
+type waitResults struct {
+	Status string `json:"status"`
+}
+
+// newWaitTool creates a tool that sleeps for the given delay unless its context is canceled first
+func newWaitTool(t *testing.T) tool.Tool {
+	t.Helper()
+	waitTool, err := functiontool.New(
+		functiontool.Config{Name: "wait", Description: "Waits for the given delay"},
+		func(ctx tool.Context, input waitArgs) (waitResults, error) {
+			delay, err := time.ParseDuration(input.Delay)
+			if err != nil {
+				return waitResults{}, err
+			}
+			select {
+			case <-time.After(delay):
+				return waitResults{Status: "success"}, nil
+			case <-ctx.Done():
+				return waitResults{Status: "canceled"}, nil
+			}
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return waitTool
+}
+
+func runWithTimeout(t *testing.T, ctx context.Context, d time.Duration, delay string) (map[string]any, error) {
+	t.Helper()
+	wrapped, ok := WithTimeout(d, newWaitTool(t)).(functionTool)
+	if !ok {
+		t.Fatal("WithTimeout did not return a function tool")
+	}
+	return wrapped.Run(&fakeToolContext{ctx: ctx}, map[string]any{"delay": delay})
+}
+
+func TestWithTimeoutFinishesInTime(t *testing.T) {
+	result, err := runWithTimeout(t, context.Background(), time.Second, "1ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["status"] != "success" {
+		t.Errorf("result = %v, want status success", result)
+	}
+}
+
+func TestWithTimeoutExceeded(t *testing.T) {
+	start := time.Now()
+	result, err := runWithTimeout(t, context.Background(), 20*time.Millisecond, "10s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["status"] != "error" || result["error_message"] != "wait timed out after 20ms" {
+		t.Errorf("result = %v, want a timeout error result", result)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Run took %s, want it to return at the deadline", elapsed)
+	}
+}
+
+func TestWithTimeoutParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := runWithTimeout(t, ctx, time.Second, "10s"); err != context.Canceled {
+		t.Errorf("Run error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWithTimeoutKeepsDeclaration(t *testing.T) {
+	inner := newWaitTool(t)
+	wrapped := WithTimeout(time.Second, inner)
+
+	if wrapped.Name() != inner.Name() || wrapped.Description() != inner.Description() {
+		t.Errorf("wrapped tool is %q (%q), want %q (%q)", wrapped.Name(), wrapped.Description(), inner.Name(), inner.Description())
+	}
+
+	req := &model.LLMRequest{}
+	if err := wrapped.(*timeoutTool).ProcessRequest(nil, req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Tools["wait"] != wrapped {
+		t.Error("request does not route calls to the wrapper")
+	}
+	decls := req.Config.Tools[0].FunctionDeclarations
+	if len(decls) != 1 || !reflect.DeepEqual(decls[0], inner.(functionTool).Declaration()) {
+		t.Errorf("declarations = %v, want the inner tool's declaration", decls)
+	}
+}
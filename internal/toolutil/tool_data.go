@@ -0,0 +1,51 @@
+package toolutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ===== Tool Data =====
+//
+// Tools that look things up in a fixed dataset (jokes, countries) keep it in a JSON file embedded
+// with go:embed, so the data can grow without cluttering the code and the binary still works
+// offline. LoadToolData lets a JSON file of the same shape replace the embedded data at runtime,
+// e.g. to try other jokes without recompiling.
+//
+// The file replaces the embedded data as a whole rather than merging with it. Fields the target
+// type doesn't have are rejected, so a misspelled field shows up as an error instead of as data
+// that is silently empty.
+
+// LoadToolData decodes the JSON file at path into v when it exists, and embedded otherwise. It
+// reports whether the file was used.
+func LoadToolData(path string, embedded []byte, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := decodeToolData(embedded, v); err != nil {
+			return false, fmt.Errorf("embedded data for %s: %w", path, err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	if err := decodeToolData(data, v); err != nil {
+		return true, fmt.Errorf("%s: %w", path, err)
+	}
+	return true, nil
+}
+
+func decodeToolData(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	if dec.More() {
+		return errors.New("invalid JSON: unexpected data after the first value")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package toolutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEntry struct {
+	Name string `json:"name"`
+}
+
+func TestLoadToolDataEmbedded(t *testing.T) {
+	var entries []testEntry
+	fromFile, err := LoadToolData(filepath.Join(t.TempDir(), "entries.json"), []byte(`[{"name": "embedded"}]`), &entries)
+	if err != nil || fromFile {
+		t.Fatalf("LoadToolData() = %v, %v, want the embedded data", fromFile, err)
+	}
+	if !reflect.DeepEqual(entries, []testEntry{{Name: "embedded"}}) {
+		t.Errorf("entries = %v, want the embedded entry", entries)
+	}
+}
+
+func TestLoadToolDataFileOverridesEmbedded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "one"}, {"name": "two"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []testEntry
+	fromFile, err := LoadToolData(path, []byte(`[{"name": "embedded"}]`), &entries)
+	if err != nil || !fromFile {
+		t.Fatalf("LoadToolData() = %v, %v, want the file's data", fromFile, err)
+	}
+	if !reflect.DeepEqual(entries, []testEntry{{Name: "one"}, {Name: "two"}}) {
+		t.Errorf("entries = %v, want the file's entries only", entries)
+	}
+}
+
+func TestLoadToolDataInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":      `[{"name": "one"`,
+		"unknown field": `[{"nmae": "one"}]`,
+		"trailing data": `[{"name": "one"}] []`,
+		"wrong shape":   `{"name": "one"}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "entries.json")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var entries []testEntry
+			if _, err := LoadToolData(path, nil, &entries); err == nil || !strings.Contains(err.Error(), path) {
+				t.Errorf("LoadToolData() error = %v, want an error naming %s", err, path)
+			}
+		})
+	}
+
+	// Broken embedded data is reported too, rather than leaving the tool without data
+	var entries []testEntry
+	if _, err := LoadToolData(filepath.Join(t.TempDir(), "entries.json"), []byte(`{`), &entries); err == nil {
+		t.Error("LoadToolData() accepted invalid embedded data")
+	}
+}
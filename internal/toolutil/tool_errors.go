@@ -0,0 +1,263 @@
+package toolutil
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	RECENT_ERRORS = "recent_errors"
+	ERRORS_KEY    = "errors"
+
+	DEFAULT_MAX_TOOL_ERRORS     = 20 // errors kept per session; older ones are dropped
+	DEFAULT_RECENT_ERRORS_LIMIT = 5  // errors recent_errors returns when no limit is given
+	TOOL_ERROR_TIME_FORMAT      = "2006-01-02 15:04:05"
+)
+
+// ===== Tool Error Log =====
+//
+// ToolErrorLog records failed tool calls in the errors state key, so the agent can tell whether
+// an integration has been failing. A call has failed when the tool returned an error, or when
+// its result has status "error" (how the tools in this repo report failures); the message is
+// the error or the result's error_message.
+//
+// Like ToolCallCounter, the log is kept in memory and written to state as a whole list on every
+// error, so errors from calls in the same model response aren't lost when their state deltas
+// are merged. The list holds the newest MaxErrors entries.
+//
+// Agents wrapped with agenttool run in a fresh session whose state is discarded. Their tool
+// errors are recorded with AfterNestedTool, which queues them per user; the root agent's
+// AfterTool moves the queue into its own session when the agent tool returns.
+
+// ToolError is one failed tool call
+type ToolError struct {
+	Tool      string `json:"tool"`
+	Agent     string `json:"agent"` // the agent that called the tool
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ToolErrorLog holds per-session tool errors. Create one with NewToolErrorLog, add its
+// AfterTool method to the root agent's AfterToolCallbacks and AfterNestedTool to agents
+// wrapped with agenttool.
+type ToolErrorLog struct {
+	MaxErrors int
+
+	mu      sync.Mutex
+	errors  map[sessionKey][]ToolError
+	pending map[string][]ToolError // errors of agent tools, by user ID
+	now     func() time.Time
+}
+
+// NewToolErrorLog creates an empty log keeping the newest maxErrors errors per session;
+// a non-positive maxErrors uses DEFAULT_MAX_TOOL_ERRORS
+func NewToolErrorLog(maxErrors int) *ToolErrorLog {
+	if maxErrors <= 0 {
+		maxErrors = DEFAULT_MAX_TOOL_ERRORS
+	}
+	return &ToolErrorLog{
+		MaxErrors: maxErrors,
+		errors:    map[sessionKey][]ToolError{},
+		pending:   map[string][]ToolError{},
+		now:       time.Now,
+	}
+}
+
+// AfterTool is an llmagent.AfterToolCallback that records a failed call, along with any errors
+// queued by agent tools, and leaves the result as is
+func (l *ToolErrorLog) AfterTool(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	var failed []ToolError
+	if message, ok := toolErrorMessage(result, err); ok {
+		failed = append(failed, l.newToolError(t.Name(), ctx.AgentName(), message))
+	}
+
+	key := sessionKey{ctx.AppName(), ctx.UserID(), ctx.SessionID()}
+	if setErr := l.record(key, ctx.State(), failed...); setErr != nil {
+		slog.Warn("failed to record tool error", "tool", t.Name(), "session_id", ctx.SessionID(), "error", setErr)
+	}
+	return nil, nil
+}
+
+// AfterNestedTool is an llmagent.AfterToolCallback for agents run through agenttool. A failed
+// call is queued for the user until the root agent's AfterTool records it.
+func (l *ToolErrorLog) AfterNestedTool(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	if message, ok := toolErrorMessage(result, err); ok {
+		l.queue(ctx.UserID(), l.newToolError(t.Name(), ctx.AgentName(), message))
+	}
+	return nil, nil
+}
+
+func (l *ToolErrorLog) newToolError(toolName, agentName, message string) ToolError {
+	slog.Warn("tool failed", "tool", toolName, "agent", agentName, "error", message)
+	return ToolError{Tool: toolName, Agent: agentName, Message: message, Timestamp: l.now().Format(TOOL_ERROR_TIME_FORMAT)}
+}
+
+// queue holds errors from an agent tool's session until the root agent records them
+func (l *ToolErrorLog) queue(userID string, failed ToolError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[userID] = append(l.pending[userID], failed)
+}
+
+// record adds the failed calls and the user's queued errors to the session's log and writes it
+// to state. Nothing is written when there is nothing to add.
+func (l *ToolErrorLog) record(key sessionKey, state session.State, failed ...ToolError) error {
+	l.mu.Lock()
+	// Queued errors come from agent tools, which return before this call's own result
+	failed = append(l.pending[key.userID], failed...)
+	delete(l.pending, key.userID)
+	if len(failed) == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	entries := l.merged(key, state)
+	entries = append(entries, failed...)
+	if len(entries) > l.MaxErrors {
+		entries = entries[len(entries)-l.MaxErrors:]
+	}
+	l.errors[key] = entries
+	stored := toolErrorsToState(entries)
+	l.mu.Unlock()
+
+	return state.Set(ERRORS_KEY, stored)
+}
+
+// snapshot returns a copy of the session's errors, oldest first
+func (l *ToolErrorLog) snapshot(key sessionKey, state session.ReadonlyState) []ToolError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.merged(key, state)
+}
+
+// merged returns a copy of the session's errors. Every error goes through memory, so state is
+// only read when the log hasn't seen the session yet (e.g. after a restart); the caller holds
+// l.mu.
+func (l *ToolErrorLog) merged(key sessionKey, state session.ReadonlyState) []ToolError {
+	if entries, ok := l.errors[key]; ok {
+		return append([]ToolError(nil), entries...)
+	}
+	return getToolErrors(state)
+}
+
+// toolErrorMessage reports whether a call failed and why
+func toolErrorMessage(result map[string]any, err error) (string, bool) {
+	if err != nil {
+		return err.Error(), true
+	}
+	if status, _ := result["status"].(string); status != "error" {
+		return "", false
+	}
+	if message, _ := result["error_message"].(string); message != "" {
+		return message, true
+	}
+	return "the tool returned status error without a message", true
+}
+
+// getToolErrors reads the errors list. Fresh values are []map[string]any; after a database
+// round trip the list is []any of map[string]any. Entries without a tool name are skipped.
+func getToolErrors(state session.ReadonlyState) []ToolError {
+	val, err := state.Get(ERRORS_KEY)
+	if err != nil {
+		return nil
+	}
+
+	var items []map[string]any
+	switch v := val.(type) {
+	case []map[string]any:
+		items = v
+	case []any:
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+	}
+
+	var entries []ToolError
+	for _, item := range items {
+		entry := ToolError{}
+		entry.Tool, _ = item["tool"].(string)
+		entry.Agent, _ = item["agent"].(string)
+		entry.Message, _ = item["message"].(string)
+		entry.Timestamp, _ = item["timestamp"].(string)
+		if entry.Tool != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func toolErrorsToState(entries []ToolError) []map[string]any {
+	stored := make([]map[string]any, 0, len(entries))
+	for _, e := range entries {
+		stored = append(stored, map[string]any{
+			"tool":      e.Tool,
+			"agent":     e.Agent,
+			"message":   e.Message,
+			"timestamp": e.Timestamp,
+		})
+	}
+	return stored
+}
+
+// ===== Recent Errors Tool =====
+
+type recentErrorsArgs struct {
+	Limit int `json:"limit,omitempty"` // how many errors to return; defaults to 5
+}
+
+type recentErrorsResults struct {
+	Status  string      `json:"status"`
+	Errors  []ToolError `json:"errors"` // newest first
+	Total   int         `json:"total"`  // errors kept for the session
+	Message string      `json:"message"`
+}
+
+// NewRecentErrorsTool creates a recent_errors tool that returns the session's newest errors
+func (l *ToolErrorLog) NewRecentErrorsTool() (tool.Tool, error) {
+	recentErrors := func(ctx tool.Context, input recentErrorsArgs) (recentErrorsResults, error) {
+		slog.Info("tool called", "tool", RECENT_ERRORS, "agent", ctx.AgentName(), "session_id", ctx.SessionID(), "limit", input.Limit)
+		return summarizeToolErrors(l.snapshot(sessionKey{ctx.AppName(), ctx.UserID(), ctx.SessionID()}, ctx.State()), input.Limit), nil
+	}
+
+	recentErrorsTool, err := functiontool.New(
+		functiontool.Config{
+			Name: RECENT_ERRORS,
+			Description: "Lists the most recent tool errors in this session, newest first, with the tool and agent " +
+				"that failed, the error message and when it happened. limit is optional (default 5)",
+		},
+		recentErrors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", RECENT_ERRORS, err)
+	}
+	return recentErrorsTool, nil
+}
+
+func summarizeToolErrors(entries []ToolError, limit int) recentErrorsResults {
+	if limit <= 0 {
+		limit = DEFAULT_RECENT_ERRORS_LIMIT
+	}
+	results := recentErrorsResults{Status: "success", Errors: []ToolError{}, Total: len(entries)}
+	for i := len(entries) - 1; i >= 0 && len(results.Errors) < limit; i-- {
+		results.Errors = append(results.Errors, entries[i])
+	}
+
+	if results.Total == 0 {
+		results.Message = "No tool errors in this session"
+		return results
+	}
+	failing := map[string]int{}
+	for _, e := range entries {
+		failing[e.Tool]++
+	}
+	results.Message = fmt.Sprintf("%d tool error(s) in this session across %d tool(s); showing the newest %d",
+		results.Total, len(failing), len(results.Errors))
+	return results
+}
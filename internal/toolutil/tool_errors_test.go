@@ -0,0 +1,123 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+func newTestErrorLog(maxErrors int) *ToolErrorLog {
+	l := NewToolErrorLog(maxErrors)
+	l.now = func() time.Time { return time.Date(2024, 12, 3, 15, 30, 0, 0, time.UTC) }
+	return l
+}
+
+func TestToolErrorMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     map[string]any
+		err        error
+		want       string
+		wantFailed bool
+	}{
+		{"success", map[string]any{"status": "success"}, nil, "", false},
+		{"no status", map[string]any{"result": "ok"}, nil, "", false},
+		{"status error", map[string]any{"status": "error", "error_message": "Could not fetch price for XYZ"}, nil, "Could not fetch price for XYZ", true},
+		{"status error without message", map[string]any{"status": "error"}, nil, "the tool returned status error without a message", true},
+		{"returned error", nil, errors.New("connection refused"), "connection refused", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, failed := toolErrorMessage(tt.result, tt.err)
+			if got != tt.want || failed != tt.wantFailed {
+				t.Errorf("toolErrorMessage() = %q, %v, want %q, %v", got, failed, tt.want, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestToolErrorLogBounded(t *testing.T) {
+	log := newTestErrorLog(3)
+	state := agenttest.NewState(nil, false)
+	for i := 1; i <= 5; i++ {
+		if err := log.record(testSession, state, log.newToolError("get_stock_price", "stock_analyst", fmt.Sprintf("failure %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := getToolErrors(state)
+	if len(entries) != 3 || entries[0].Message != "failure 3" || entries[2].Message != "failure 5" {
+		t.Errorf("errors = %+v, want the newest 3", entries)
+	}
+
+	results := summarizeToolErrors(entries, 2)
+	if len(results.Errors) != 2 || results.Errors[0].Message != "failure 5" || results.Total != 3 {
+		t.Errorf("summarizeToolErrors(limit 2) = %+v, want the newest 2 of 3, newest first", results)
+	}
+	if got := summarizeToolErrors(nil, 0); got.Total != 0 || got.Errors == nil {
+		t.Errorf("summarizeToolErrors(nil) = %+v, want an empty list", got)
+	}
+}
+
+func TestToolErrorLogSameResponse(t *testing.T) {
+	// Calls from one model response see the state from before the response, and the last
+	// delta wins; it must still include the earlier errors
+	log := newTestErrorLog(0)
+	before := agenttest.NewState(nil, false)
+
+	var last *agenttest.State
+	for _, name := range []string{"calculate", "convert_units"} {
+		delta := agenttest.NewState(before.Values(), false)
+		if err := log.record(testSession, delta, log.newToolError(name, "manager", "bad input")); err != nil {
+			t.Fatal(err)
+		}
+		last = delta
+	}
+
+	if entries := getToolErrors(last); len(entries) != 2 {
+		t.Errorf("last delta errors = %+v, want both", entries)
+	}
+}
+
+func TestToolErrorLogNestedErrors(t *testing.T) {
+	log := newTestErrorLog(0)
+	log.queue("user", log.newToolError("get_stock_price", "stock_analyst", "Could not fetch price for XYZ"))
+	log.queue("other_user", log.newToolError("get_stock_price", "stock_analyst", "timeout"))
+
+	// The root agent records the queued error when the agent tool returns, before its own
+	state := agenttest.NewState(nil, false)
+	if err := log.record(testSession, state, log.newToolError("calculate", "manager", "division by zero")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := getToolErrors(state)
+	if len(entries) != 2 || entries[0].Tool != "get_stock_price" || entries[0].Agent != "stock_analyst" || entries[1].Tool != "calculate" {
+		t.Errorf("errors = %+v, want the queued stock error then calculate", entries)
+	}
+	if len(log.pending["user"]) != 0 || len(log.pending["other_user"]) != 1 {
+		t.Errorf("pending = %+v, want only other_user's error left", log.pending)
+	}
+}
+
+func TestToolErrorLogResumesFromState(t *testing.T) {
+	// After a database round trip the stored list is []any of map[string]any
+	var stored []any
+	if err := json.Unmarshal([]byte(`[{"tool": "fetch_and_summarize", "agent": "manager", "message": "404", "timestamp": "2024-12-03 15:00:00"}, "bad", {"message": "no tool"}]`), &stored); err != nil {
+		t.Fatal(err)
+	}
+	state := agenttest.NewState(map[string]any{ERRORS_KEY: stored}, false)
+
+	log := newTestErrorLog(0)
+	if err := log.record(testSession, state, log.newToolError("calculate", "manager", "division by zero")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := getToolErrors(state)
+	if len(entries) != 2 || entries[0].Tool != "fetch_and_summarize" || entries[1].Timestamp != "2024-12-03 15:30:00" {
+		t.Errorf("errors = %+v, want the stored error then the new one", entries)
+	}
+}
@@ -0,0 +1,236 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+const (
+	TOOL_STATS           = "tool_stats"
+	TOOL_CALL_COUNTS_KEY = "tool_call_counts"
+
+	// transfer_to_agent calls are counted per target agent, e.g. "transfer_to_agent/funny_nerd"
+	TRANSFER_TO_AGENT = "transfer_to_agent"
+
+	// In-memory counts of a session not counted for this long are dropped; state keeps them
+	COUNTER_IDLE_TIMEOUT = 10 * time.Minute
+)
+
+// ===== Tool Call Counter =====
+//
+// ToolCallCounter counts how often each tool is called in a session and keeps the counts in
+// the tool_call_counts state key. Counts are kept in memory as well and written to state as a
+// whole map on every call:
+//   - Tool calls from one model response each get their own state delta, computed from the
+//     state before the response, and the deltas are merged with the last one winning. Writing
+//     the counter's full map makes that last delta include every earlier call.
+//   - Parallel agents call tools concurrently; the mutex keeps their increments from racing.
+//
+// State is the source of truth: the in-memory copy only has to outlive one model response, so
+// a session's copy is dropped once it hasn't counted a call for COUNTER_IDLE_TIMEOUT.
+//
+// Counts already in state (e.g. after a restart with a database session service) are picked up
+// on the next call. After a JSON round trip they come back as float64, which is handled.
+
+type sessionKey struct {
+	appName, userID, sessionID string
+}
+
+// sessionCounts is the in-memory copy of a session's counts
+type sessionCounts struct {
+	counts  map[string]int
+	updated time.Time
+}
+
+// ToolCallCounter holds per-session tool call counts. Create one with NewToolCallCounter and
+// add its AfterTool method to the agent's AfterToolCallbacks.
+type ToolCallCounter struct {
+	mu     sync.Mutex
+	counts map[sessionKey]sessionCounts
+	now    func() time.Time
+}
+
+// NewToolCallCounter creates an empty counter
+func NewToolCallCounter() *ToolCallCounter {
+	return &ToolCallCounter{counts: map[sessionKey]sessionCounts{}, now: time.Now}
+}
+
+// AfterTool is an llmagent.AfterToolCallback that counts the call and leaves the result as is.
+// Failed calls are counted too.
+func (c *ToolCallCounter) AfterTool(ctx tool.Context, t tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	key := sessionKey{ctx.AppName(), ctx.UserID(), ctx.SessionID()}
+	if setErr := c.increment(key, ctx.State(), countName(t.Name(), args)); setErr != nil {
+		slog.Warn("failed to count tool call", "tool", t.Name(), "session_id", ctx.SessionID(), "error", setErr)
+	}
+	return nil, nil
+}
+
+// increment adds one call of name and writes the session's counts to state. Idle sessions are
+// dropped from memory on the way.
+func (c *ToolCallCounter) increment(key sessionKey, state session.State, name string) error {
+	c.mu.Lock()
+	now := c.now()
+	for k, sc := range c.counts {
+		if now.Sub(sc.updated) >= COUNTER_IDLE_TIMEOUT {
+			delete(c.counts, k)
+		}
+	}
+	counts := c.merged(key, state)
+	counts[name]++
+	c.counts[key] = sessionCounts{counts: counts, updated: now}
+	stored := countsToState(counts)
+	c.mu.Unlock()
+
+	return state.Set(TOOL_CALL_COUNTS_KEY, stored)
+}
+
+// snapshot returns a copy of the session's counts
+func (c *ToolCallCounter) snapshot(key sessionKey, state session.ReadonlyState) map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.merged(key, state)
+}
+
+// merged returns a copy of the in-memory counts with any higher counts from state. A copy that
+// has been idle too long is ignored. The caller holds c.mu.
+func (c *ToolCallCounter) merged(key sessionKey, state session.ReadonlyState) map[string]int {
+	counts := map[string]int{}
+	if sc, ok := c.counts[key]; ok && c.now().Sub(sc.updated) < COUNTER_IDLE_TIMEOUT {
+		for name, n := range sc.counts {
+			counts[name] = n
+		}
+	}
+	for name, n := range getToolCallCounts(state) {
+		counts[name] = max(counts[name], n)
+	}
+	return counts
+}
+
+// countName is the name a call is counted under; transfers include the target agent
+func countName(toolName string, args map[string]any) string {
+	if toolName == TRANSFER_TO_AGENT {
+		if agentName, ok := args["agent_name"].(string); ok && agentName != "" {
+			return TRANSFER_TO_AGENT + "/" + agentName
+		}
+	}
+	return toolName
+}
+
+// getToolCallCounts reads tool_call_counts. Fresh values are map[string]any of ints; after a
+// database round trip the numbers are float64. Entries that aren't whole numbers are skipped.
+func getToolCallCounts(state session.ReadonlyState) map[string]int {
+	counts := map[string]int{}
+	val, err := state.Get(TOOL_CALL_COUNTS_KEY)
+	if err != nil {
+		return counts
+	}
+
+	var entries map[string]any
+	switch v := val.(type) {
+	case map[string]any:
+		entries = v
+	case map[string]int:
+		for name, n := range v {
+			counts[name] = n
+		}
+		return counts
+	}
+
+	for name, raw := range entries {
+		switch n := raw.(type) {
+		case int:
+			counts[name] = n
+		case int64:
+			counts[name] = int(n)
+		case float64:
+			if n == float64(int(n)) {
+				counts[name] = int(n)
+			}
+		case json.Number:
+			if i, err := n.Int64(); err == nil {
+				counts[name] = int(i)
+			}
+		}
+	}
+	return counts
+}
+
+func countsToState(counts map[string]int) map[string]any {
+	stored := make(map[string]any, len(counts))
+	for name, n := range counts {
+		stored[name] = n
+	}
+	return stored
+}
+
+// ===== Tool Stats Tool =====
+
+type toolStatsArgs struct{}
+
+// ToolCount is how often one tool was called
+type ToolCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type toolStatsResults struct {
+	Status  string      `json:"status"`
+	Counts  []ToolCount `json:"counts"` // most called first
+	Total   int         `json:"total"`
+	Message string      `json:"message"`
+}
+
+// NewStatsTool creates a tool_stats tool that reports the counter's counts for the session.
+// The tool_stats call itself is counted once it returns, so it isn't in its own result.
+func (c *ToolCallCounter) NewStatsTool() (tool.Tool, error) {
+	toolStats := func(ctx tool.Context, input toolStatsArgs) (toolStatsResults, error) {
+		slog.Info("tool called", "tool", TOOL_STATS, "agent", ctx.AgentName(), "session_id", ctx.SessionID())
+		return summarizeToolCounts(c.snapshot(sessionKey{ctx.AppName(), ctx.UserID(), ctx.SessionID()}, ctx.State())), nil
+	}
+
+	toolStatsTool, err := functiontool.New(
+		functiontool.Config{
+			Name: TOOL_STATS,
+			Description: "Reports how many times each tool has been called in this session, most called first. " +
+				"Transfers to sub-agents are listed as transfer_to_agent/<agent name>",
+		},
+		toolStats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", TOOL_STATS, err)
+	}
+	return toolStatsTool, nil
+}
+
+func summarizeToolCounts(counts map[string]int) toolStatsResults {
+	results := toolStatsResults{Status: "success", Counts: make([]ToolCount, 0, len(counts))}
+	for name, n := range counts {
+		results.Counts = append(results.Counts, ToolCount{Name: name, Count: n})
+		results.Total += n
+	}
+	sort.Slice(results.Counts, func(i, j int) bool {
+		if results.Counts[i].Count != results.Counts[j].Count {
+			return results.Counts[i].Count > results.Counts[j].Count
+		}
+		return results.Counts[i].Name < results.Counts[j].Name
+	})
+
+	if results.Total == 0 {
+		results.Message = "No tools have been called in this session yet"
+		return results
+	}
+	parts := make([]string, 0, len(results.Counts))
+	for _, tc := range results.Counts {
+		parts = append(parts, fmt.Sprintf("%s: %d", tc.Name, tc.Count))
+	}
+	results.Message = fmt.Sprintf("%d tool calls in this session (%s)", results.Total, strings.Join(parts, ", "))
+	return results
+}
@@ -0,0 +1,123 @@
+package toolutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muchlist/agent-dev-kit/internal/agenttest"
+)
+
+var testSession = sessionKey{"app", "user", "session"}
+
+func TestToolCallCounterSameResponse(t *testing.T) {
+	// Calls from one model response see the state from before the response, and the last
+	// delta wins; it must still include the earlier calls
+	counter := NewToolCallCounter()
+	before := agenttest.NewState(nil, false)
+
+	var last *agenttest.State
+	for _, name := range []string{"calculate", "get_current_time", "calculate"} {
+		delta := agenttest.NewState(before.Values(), false)
+		if err := counter.increment(testSession, delta, name); err != nil {
+			t.Fatal(err)
+		}
+		last = delta
+	}
+
+	want := map[string]int{"calculate": 2, "get_current_time": 1}
+	if got := getToolCallCounts(last); !reflect.DeepEqual(got, want) {
+		t.Errorf("last delta counts = %v, want %v", got, want)
+	}
+}
+
+func TestToolCallCounterConcurrent(t *testing.T) {
+	counter := NewToolCallCounter()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := counter.increment(testSession, agenttest.NewState(nil, false), "cpu_info"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counter.snapshot(testSession, agenttest.NewState(nil, false))["cpu_info"]; got != 50 {
+		t.Errorf("cpu_info count = %d, want 50", got)
+	}
+}
+
+func TestToolCallCounterDropsIdleSessions(t *testing.T) {
+	counter := NewToolCallCounter()
+	now := time.Date(2024, 12, 3, 15, 30, 0, 0, time.UTC)
+	counter.now = func() time.Time { return now }
+
+	state := agenttest.NewState(nil, false)
+	for i := 0; i < 2; i++ {
+		if err := counter.increment(testSession, state, "calculate"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Counting in another session later drops the idle one from memory, but not from state
+	now = now.Add(COUNTER_IDLE_TIMEOUT)
+	other := sessionKey{"app", "user", "other"}
+	if err := counter.increment(other, agenttest.NewState(nil, false), "calculate"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := counter.counts[testSession]; ok || len(counter.counts) != 1 {
+		t.Errorf("in-memory sessions = %v, want only the other session", counter.counts)
+	}
+
+	if err := counter.increment(testSession, state, "calculate"); err != nil {
+		t.Fatal(err)
+	}
+	if got := getToolCallCounts(state)["calculate"]; got != 3 {
+		t.Errorf("calculate count = %d, want 3 resumed from state", got)
+	}
+}
+
+func TestToolCallCounterResumesFromState(t *testing.T) {
+	// After a database round trip the stored counts are float64
+	var stored map[string]any
+	if err := json.Unmarshal([]byte(`{"calculate": 3, "bad": "x", "half": 1.5}`), &stored); err != nil {
+		t.Fatal(err)
+	}
+	state := agenttest.NewState(map[string]any{TOOL_CALL_COUNTS_KEY: stored}, false)
+
+	counter := NewToolCallCounter()
+	if err := counter.increment(testSession, state, "calculate"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"calculate": 4}
+	if got := getToolCallCounts(state); !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+}
+
+func TestCountName(t *testing.T) {
+	if got := countName(TRANSFER_TO_AGENT, map[string]any{"agent_name": "funny_nerd"}); got != "transfer_to_agent/funny_nerd" {
+		t.Errorf("countName(transfer) = %q", got)
+	}
+	if got := countName("calculate", map[string]any{"agent_name": "x"}); got != "calculate" {
+		t.Errorf("countName(calculate) = %q", got)
+	}
+}
+
+func TestSummarizeToolCounts(t *testing.T) {
+	results := summarizeToolCounts(map[string]int{"b": 2, "a": 2, "c": 5})
+	want := []ToolCount{{"c", 5}, {"a", 2}, {"b", 2}}
+	if !reflect.DeepEqual(results.Counts, want) || results.Total != 9 {
+		t.Errorf("summary = %+v, want counts %v and total 9", results, want)
+	}
+
+	if empty := summarizeToolCounts(map[string]int{}); empty.Total != 0 || len(empty.Counts) != 0 {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
@@ -0,0 +1,287 @@
+package toolutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/muchlist/agent-dev-kit/internal/agentutil"
+)
+
+// ===== Translate Tool =====
+//
+// translate turns any text into another language with the model, so an agent can answer "say
+// that in Spanish" about its own last reply or anything the user pastes. Long text is split at
+// paragraph breaks into chunks of at most MAX_TRANSLATE_CHUNK characters, each chunk is
+// translated on its own and the translations are joined with paragraph breaks again. Every
+// request also asks for the language the chunk is written in; the language detected most often
+// is returned as the source language. Translate does the chunked translation for any caller,
+// such as the email agent's translate_email.
+
+const (
+	TRANSLATE = "translate"
+
+	MAX_TRANSLATE_CHUNK  = 3000
+	MAX_TRANSLATE_CHUNKS = 20
+)
+
+// translatePrompt is the system instruction for translating one chunk
+const translatePrompt = `You translate text into %s.
+Translate the text the user sends. Keep names, numbers, dates, code, links, markdown and line breaks as they are.
+Reply with a JSON object: {"source_language": "<English name of the language the text is written in>", "translation": "<the translation>"}.
+If the text is already in %[1]s, return it unchanged.`
+
+var translateResponseSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"source_language": {Type: genai.TypeString},
+		"translation":     {Type: genai.TypeString},
+	},
+	Required: []string{"source_language", "translation"},
+}
+
+type translateArgs struct {
+	Text           string `json:"text"`
+	TargetLanguage string `json:"target_language"` // language name, e.g. "Spanish"
+}
+
+type translateResults struct {
+	Status         string `json:"status"` // "success" or "error"
+	SourceLanguage string `json:"source_language,omitempty"`
+	TargetLanguage string `json:"target_language,omitempty"`
+	Translation    string `json:"translation,omitempty"`
+	Chunks         int    `json:"chunks,omitempty"`
+	Message        string `json:"message"`
+}
+
+// Translation is a text translated by Translate
+type Translation struct {
+	SourceLanguage string // the language detected most often, "unknown" when none was
+	Text           string
+	Chunks         int
+}
+
+// chunkTranslation is the model's answer for one chunk
+type chunkTranslation struct {
+	SourceLanguage string `json:"source_language"`
+	Translation    string `json:"translation"`
+}
+
+// NewTranslateTool creates a translate tool that translates text with mdl, usually the model
+// of the agent it is given to. Any language the model knows can be the target.
+func NewTranslateTool(mdl model.LLM) (tool.Tool, error) {
+	translateTool, err := functiontool.New(
+		functiontool.Config{
+			Name: TRANSLATE,
+			Description: "Translates text into target_language (a language name such as \"Spanish\") and returns the " +
+				"translation with the detected source language. Pass the full text to translate, e.g. your previous reply",
+		},
+		func(ctx tool.Context, input translateArgs) (translateResults, error) {
+			slog.Info("tool called", "tool", TRANSLATE, "agent", ctx.AgentName(), "session_id", ctx.SessionID(),
+				"target_language", input.TargetLanguage, "characters", utf8.RuneCountInString(input.Text))
+			return translate(ctx, mdl, input), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tool: %w", TRANSLATE, err)
+	}
+	return translateTool, nil
+}
+
+// translate translates input chunk by chunk; problems are returned as status "error" for the
+// agent to explain
+func translate(ctx context.Context, mdl model.LLM, input translateArgs) translateResults {
+	target := strings.TrimSpace(input.TargetLanguage)
+	text := strings.TrimSpace(input.Text)
+	if target == "" {
+		return translateResults{Status: "error", Message: "target_language is required, e.g. \"Spanish\""}
+	}
+	if text == "" {
+		return translateResults{Status: "error", TargetLanguage: target, Message: "text is required: pass the full text to translate"}
+	}
+
+	translation, err := Translate(ctx, mdl, text, target, MAX_TRANSLATE_CHUNKS)
+	if err != nil {
+		return translateResults{Status: "error", TargetLanguage: target, Message: err.Error()}
+	}
+
+	message := fmt.Sprintf("Translated from %s into %s", translation.SourceLanguage, target)
+	if strings.EqualFold(translation.SourceLanguage, target) {
+		message = fmt.Sprintf("The text is already in %s; it is returned unchanged", target)
+	}
+	return translateResults{
+		Status:         "success",
+		SourceLanguage: translation.SourceLanguage,
+		TargetLanguage: target,
+		Translation:    translation.Text,
+		Chunks:         translation.Chunks,
+		Message:        message,
+	}
+}
+
+// Translate translates text into the target language with mdl. The text is split with
+// ChunkText into chunks of at most MAX_TRANSLATE_CHUNK characters, each translated on its own,
+// and text needing more than maxChunks chunks is refused before the model is called. Empty
+// text translates to empty text.
+func Translate(ctx context.Context, mdl model.LLM, text, target string, maxChunks int) (Translation, error) {
+	chunks := ChunkText(strings.TrimSpace(text), MAX_TRANSLATE_CHUNK)
+	if len(chunks) > maxChunks {
+		return Translation{}, fmt.Errorf("the text is too long to translate (%d characters, at most %d)",
+			utf8.RuneCountInString(text), MAX_TRANSLATE_CHUNK*maxChunks)
+	}
+
+	translated := make([]string, 0, len(chunks))
+	var sources []string
+	for i, chunk := range chunks {
+		result, err := translateChunk(ctx, mdl, chunk, target)
+		if err != nil {
+			return Translation{}, fmt.Errorf("failed to translate part %d of %d: %w", i+1, len(chunks), err)
+		}
+		translated = append(translated, result.Translation)
+		sources = append(sources, result.SourceLanguage)
+	}
+
+	return Translation{
+		SourceLanguage: mostCommonLanguage(sources),
+		Text:           strings.Join(translated, "\n\n"),
+		Chunks:         len(chunks),
+	}, nil
+}
+
+// translateChunk asks mdl for the translation and source language of one chunk
+func translateChunk(ctx context.Context, mdl model.LLM, chunk, target string) (chunkTranslation, error) {
+	req := &model.LLMRequest{
+		Model:    mdl.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(chunk, genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(fmt.Sprintf(translatePrompt, target), genai.RoleUser),
+			Temperature:       genai.Ptr[float32](0.2),
+			ResponseMIMEType:  "application/json",
+			ResponseSchema:    translateResponseSchema,
+		},
+	}
+
+	var sb strings.Builder
+	for resp, err := range mdl.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return chunkTranslation{}, err
+		}
+		if resp.ErrorCode != "" {
+			return chunkTranslation{}, fmt.Errorf("%s: %s", resp.ErrorCode, resp.ErrorMessage)
+		}
+		if resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if part.Text != "" && !part.Thought {
+				sb.WriteString(part.Text)
+			}
+		}
+	}
+
+	// Models sometimes fence the JSON despite the MIME type
+	raw, _ := agentutil.ExtractJSON(sb.String())
+	if strings.TrimSpace(raw) == "" {
+		return chunkTranslation{}, fmt.Errorf("the model returned no translation")
+	}
+	var result chunkTranslation
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return chunkTranslation{}, fmt.Errorf("the model returned an unreadable translation: %w", err)
+	}
+	result.Translation = strings.TrimSpace(result.Translation)
+	result.SourceLanguage = strings.TrimSpace(result.SourceLanguage)
+	if result.Translation == "" {
+		return chunkTranslation{}, fmt.Errorf("the model returned no translation")
+	}
+	return result, nil
+}
+
+// mostCommonLanguage returns the language named most often, ignoring case; the first one wins
+// ties and "unknown" is returned when none is named
+func mostCommonLanguage(languages []string) string {
+	counts := map[string]int{}
+	best := ""
+	for _, language := range languages {
+		if language == "" {
+			continue
+		}
+		key := strings.ToLower(language)
+		counts[key]++
+		if best == "" || counts[key] > counts[strings.ToLower(best)] {
+			best = language
+		}
+	}
+	if best == "" {
+		return "unknown"
+	}
+	return best
+}
+
+// ChunkText splits text at paragraph breaks into chunks of at most size characters. A
+// paragraph longer than size is split between lines, keeping its line breaks (lists,
+// addresses, signatures); only a single line longer than size is split between words, and a
+// single word longer than size is cut.
+func ChunkText(text string, size int) []string {
+	if text == "" {
+		return nil
+	}
+
+	var chunks []string
+	current := ""
+	add := func(piece, sep string) {
+		switch {
+		case current == "":
+			current = piece
+		case utf8.RuneCountInString(current)+utf8.RuneCountInString(sep)+utf8.RuneCountInString(piece) <= size:
+			current += sep + piece
+		default:
+			chunks = append(chunks, current)
+			current = piece
+		}
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph == "" {
+			continue
+		}
+		if utf8.RuneCountInString(paragraph) <= size {
+			add(paragraph, "\n\n")
+			continue
+		}
+
+		// Start the long paragraph in a chunk of its own
+		if current != "" {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			if utf8.RuneCountInString(line) <= size {
+				add(line, "\n")
+				continue
+			}
+			sep := "\n"
+			for _, word := range strings.Fields(line) {
+				for runes := []rune(word); len(runes) > 0; {
+					n := min(len(runes), size)
+					add(string(runes[:n]), sep)
+					sep = " "
+					runes = runes[n:]
+				}
+			}
+		}
+		chunks = append(chunks, current)
+		current = ""
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
@@ -0,0 +1,130 @@
+package toolutil
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// translateModel "translates" by prefixing each chunk with ES: and reports the chunk's source
+// language from sources in turn (English when it runs out). It records the chunks it got.
+type translateModel struct {
+	sources []string
+	fence   bool // wrap the JSON in a ```json fence
+	texts   []string
+}
+
+func (m *translateModel) Name() string { return "translate-model" }
+
+func (m *translateModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	text := req.Contents[0].Parts[0].Text
+	source := "English"
+	if len(m.texts) < len(m.sources) {
+		source = m.sources[len(m.texts)]
+	}
+	m.texts = append(m.texts, text)
+
+	data, _ := json.Marshal(chunkTranslation{SourceLanguage: source, Translation: "ES: " + text})
+	reply := string(data)
+	if m.fence {
+		reply = "```json\n" + reply + "\n```"
+	}
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(reply, genai.RoleModel)}, nil)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	mdl := &translateModel{fence: true}
+	results := translate(context.Background(), mdl, translateArgs{Text: " Your refund is on its way. ", TargetLanguage: "Spanish"})
+	if results.Status != "success" || results.Translation != "ES: Your refund is on its way." || results.Chunks != 1 {
+		t.Fatalf("translate() = %+v, want one translated chunk", results)
+	}
+	if results.SourceLanguage != "English" || results.TargetLanguage != "Spanish" {
+		t.Errorf("languages = %q -> %q, want English -> Spanish", results.SourceLanguage, results.TargetLanguage)
+	}
+
+	// Long text is sent in chunks and put back together with paragraph breaks
+	paragraph := strings.Repeat("word ", MAX_TRANSLATE_CHUNK/10)
+	long := strings.TrimSpace(strings.Repeat(paragraph+"\n\n", 4))
+	mdl = &translateModel{sources: []string{"French", "german", "German"}}
+	results = translate(context.Background(), mdl, translateArgs{Text: long, TargetLanguage: "Spanish"})
+	if results.Status != "success" || results.Chunks != 2 || len(mdl.texts) != 2 || strings.Count(results.Translation, "ES: ") != 2 {
+		t.Errorf("long text = %d chunks in %d requests, want 2", results.Chunks, len(mdl.texts))
+	}
+	if !strings.Contains(results.Translation, "\n\nES: ") {
+		t.Errorf("chunks were not joined with a paragraph break")
+	}
+
+	for _, input := range []translateArgs{
+		{Text: "Hello", TargetLanguage: " "},
+		{Text: " ", TargetLanguage: "Spanish"},
+		{Text: strings.Repeat("x", MAX_TRANSLATE_CHUNK*MAX_TRANSLATE_CHUNKS+1), TargetLanguage: "Spanish"},
+	} {
+		mdl := &translateModel{}
+		if results := translate(context.Background(), mdl, input); results.Status != "error" || results.Message == "" || len(mdl.texts) != 0 {
+			t.Errorf("translate(%.20q, %q) = %+v after %d requests, want an error without calling the model", input.Text, input.TargetLanguage, results, len(mdl.texts))
+		}
+	}
+}
+
+func TestTranslateAlreadyInTarget(t *testing.T) {
+	results := translate(context.Background(), &translateModel{sources: []string{"spanish"}}, translateArgs{Text: "Hola", TargetLanguage: "Spanish"})
+	if results.Status != "success" || !strings.Contains(results.Message, "already in Spanish") {
+		t.Errorf("translate() = %+v, want a note that the text is already in Spanish", results)
+	}
+}
+
+func TestMostCommonLanguage(t *testing.T) {
+	tests := []struct {
+		languages []string
+		want      string
+	}{
+		{[]string{"French", "german", "German"}, "German"},
+		{[]string{"French", "German"}, "French"},
+		{[]string{"", ""}, "unknown"},
+		{nil, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := mostCommonLanguage(tt.languages); got != tt.want {
+			t.Errorf("mostCommonLanguage(%q) = %q, want %q", tt.languages, got, tt.want)
+		}
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	if got := ChunkText("One.\n\nTwo.", 100); len(got) != 1 || got[0] != "One.\n\nTwo." {
+		t.Errorf("short text = %q, want one chunk", got)
+	}
+
+	got := ChunkText("aaaa bbbb\n\ncccc dddd eeee", 10)
+	want := []string{"aaaa bbbb", "cccc dddd", "eeee"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ChunkText() = %q, want %q", got, want)
+	}
+
+	// A long paragraph keeps its line breaks; only a line that is still too long is split
+	// between words
+	got = ChunkText("Best regards,\nAlex Smith\nProduct Manager\nAcme Corp", 30)
+	want = []string{"Best regards,\nAlex Smith", "Product Manager\nAcme Corp"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ChunkText(signature) = %q, want %q", got, want)
+	}
+	got = ChunkText("- milk\n- one very long item that goes on\n- eggs", 20)
+	want = []string{"- milk\n- one very", "long item that goes", "on\n- eggs"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ChunkText(list) = %q, want %q", got, want)
+	}
+
+	for _, chunk := range ChunkText(strings.Repeat("y", 25), 10) {
+		if len(chunk) > 10 {
+			t.Errorf("chunk %q is longer than 10", chunk)
+		}
+	}
+}